@@ -13,6 +13,8 @@ SCHEDULER_CONFIG := "configs/scheduler.yaml"
 SENDER_BIN := "bin/sender"
 SENDER_CONFIG := "configs/sender.yaml"
 
+CALENDARCTL_BIN := "bin/calendarctl"
+
 DOCKER_IMG_PG = "calendar-pg:$(VERSION)"
 DOCKER_IMG_AMQP = "calendar-amqp:$(VERSION)"
 DOCKER_IMG_APP := "calendar-app:$(VERSION)"
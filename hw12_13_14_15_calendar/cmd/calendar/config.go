@@ -1,22 +1,45 @@
 package main
 
 import (
+	"errors"
 	"io"
 	"log/slog"
 	"time"
 
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
 )
 
 type Config struct {
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"CALENDAR_SHUTDOWN_TIMEOUT" env-default:"5s"`
 
+	// DrainDelay - сколько ждать после перевода сервиса в неготовое состояние перед остановом серверов.
+	DrainDelay time.Duration `yaml:"drain_delay" env:"CALENDAR_DRAIN_DELAY" env-default:"0s"`
+
 	HTTP HTTPConfig   `yaml:"http"   env-prefix:"CALENDAR_HTTP_"`
 	GRPC GRPCConfig   `yaml:"grpc"   env-prefix:"CALENDAR_GRPC_"`
 	Log  LoggerConfig `yaml:"logger" env-prefix:"CANELDAR_LOG_"`
 
-	EventStorageType config.EventStorageType `yaml:"event_storage"    env:"CALENDAR_EVENT_STORAGE" env-default:"memory"`
-	EventStoragePg   config.EventStoragePg   `yaml:"event_storage_pg"                                                   env-prefix:"CALENDAR_EVENT_STORAGE_PG_"` //nolint:lll
+	Tracing config.Tracing `yaml:"tracing" env-prefix:"CALENDAR_TRACING_"`
+
+	RateLimit config.RateLimit `yaml:"rate_limit" env-prefix:"CALENDAR_RATE_LIMIT_"`
+
+	// Quotas - ограничения количества событий в рабочих пространствах.
+	Quotas config.TenantQuotas `yaml:"quotas" env-prefix:"CALENDAR_QUOTAS_"`
+
+	// Attachments - хранилище и ограничения вложений событий.
+	Attachments config.Attachments `yaml:"attachments" env-prefix:"CALENDAR_ATTACHMENTS_"`
+
+	// Holidays - календари праздников, на которые могут подписаться владельцы.
+	Holidays config.Holidays `yaml:"holidays" env-prefix:"CALENDAR_HOLIDAYS_"`
+
+	EventStorageType   config.EventStorageType   `yaml:"event_storage"      env:"CALENDAR_EVENT_STORAGE" env-default:"memory"`                                             //nolint:lll
+	EventStoragePg     config.EventStoragePg     `yaml:"event_storage_pg"                                                     env-prefix:"CALENDAR_EVENT_STORAGE_PG_"`     //nolint:lll
+	EventStorageFile   config.EventStorageFile   `yaml:"event_storage_file"                                                   env-prefix:"CALENDAR_EVENT_STORAGE_FILE_"`   //nolint:lll
+	EventStorageSqlite config.EventStorageSqlite `yaml:"event_storage_sqlite"                                                 env-prefix:"CALENDAR_EVENT_STORAGE_SQLITE_"` //nolint:lll
+
+	// MigrateOnStart - применять миграции хранилища (pg, sqlite) при запуске сервиса.
+	MigrateOnStart bool `yaml:"migrate_on_start" env:"CALENDAR_MIGRATE_ON_START" env-default:"false"`
 }
 
 type HTTPConfig struct {
@@ -24,15 +47,28 @@ type HTTPConfig struct {
 	Port         string        `yaml:"port"          env:"PORT"          env-default:"8081"`
 	ReadTimeout  time.Duration `yaml:"read_timeout"  env:"READ_TIMEOUT"  env-default:"5s"`
 	WriteTimeout time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" env-default:"15s"`
+
+	TLS config.TLSServer `yaml:"tls" env-prefix:"TLS_"`
 }
 
 type GRPCConfig struct {
 	Host string `yaml:"host" env:"HOST" env-default:"localhost"`
 	Port string `yaml:"port" env:"PORT" env-default:"50051"`
+
+	TLS config.TLSServer `yaml:"tls" env-prefix:"TLS_"`
+
+	// GatewayTLS - настройки TLS клиента grpc-gateway к GRPC-серверу (если TLS включён).
+	// Клиентский сертификат позволяет grpc-gateway аутентифицироваться как сервис при mTLS.
+	GatewayTLS config.TLSClient `yaml:"gateway_tls" env-prefix:"GATEWAY_TLS_"`
+
+	// AdminServices - CN клиентских сертификатов сервисов, которым доступен TenantAdminService (например, calendarctl).
+	// Без mTLS административный сервис недоступен.
+	AdminServices []string `yaml:"admin_services" env:"ADMIN_SERVICES"`
 }
 
 type LoggerConfig struct {
-	Level slog.Level `yaml:"level" env:"LEVEL" env-default:"info"`
+	Level  slog.Level    `yaml:"level"  env:"LEVEL"  env-default:"info"`
+	Format logger.Format `yaml:"format" env:"FORMAT" env-default:"text"`
 }
 
 func ReadConfig(path string) (Config, error) {
@@ -42,3 +78,19 @@ func ReadConfig(path string) (Config, error) {
 func ParseConfig(r io.Reader) (Config, error) {
 	return config.ParseConfig[Config](r)
 }
+
+// Validate проверяет значения конфигурации, которые не может проверить разбор yaml.
+func (c Config) Validate() error {
+	return errors.Join(
+		config.NonNegative("shutdown_timeout", c.ShutdownTimeout),
+		config.NonNegative("drain_delay", c.DrainDelay),
+		config.NonNegative("http.read_timeout", c.HTTP.ReadTimeout),
+		config.NonNegative("http.write_timeout", c.HTTP.WriteTimeout),
+		c.RateLimit.Validate(),
+		c.Quotas.Validate(),
+		c.Attachments.Validate(),
+		c.Holidays.Validate(),
+		c.EventStoragePg.Validate(),
+		c.EventStorageFile.Validate(),
+	)
+}
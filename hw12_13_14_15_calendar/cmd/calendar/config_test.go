@@ -11,10 +11,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
 )
 
 func unsetEnv() {
 	os.Unsetenv("CALENDAR_SHUTDOWN_TIMEOUT")
+	os.Unsetenv("CALENDAR_DRAIN_DELAY")
 
 	os.Unsetenv("CALENDAR_HTTP_PORT")
 	os.Unsetenv("CALENDAR_HTTP_HOST")
@@ -23,11 +25,42 @@ func unsetEnv() {
 
 	os.Unsetenv("CALENDAR_GRPC_PORT")
 	os.Unsetenv("CALENDAR_GRPC_HOST")
+	os.Unsetenv("CALENDAR_GRPC_TLS_ENABLED")
+	os.Unsetenv("CALENDAR_GRPC_TLS_CERT_FILE")
+	os.Unsetenv("CALENDAR_GRPC_TLS_KEY_FILE")
+	os.Unsetenv("CALENDAR_GRPC_GATEWAY_TLS_CA_FILE")
 
 	os.Unsetenv("CANELDAR_LOG_LEVEL")
+	os.Unsetenv("CANELDAR_LOG_FORMAT")
+
+	os.Unsetenv("CALENDAR_TRACING_EXPORTER")
+	os.Unsetenv("CALENDAR_TRACING_SAMPLE_RATIO")
+
+	os.Unsetenv("CALENDAR_RATE_LIMIT_ENABLED")
+	os.Unsetenv("CALENDAR_RATE_LIMIT_RATE")
+	os.Unsetenv("CALENDAR_RATE_LIMIT_BURST")
+
+	os.Unsetenv("CALENDAR_ATTACHMENTS_DIR")
+	os.Unsetenv("CALENDAR_ATTACHMENTS_MAX_FILE_SIZE")
+
+	os.Unsetenv("CALENDAR_HOLIDAYS_DIR")
+	os.Unsetenv("CALENDAR_HOLIDAYS_WATCH_INTERVAL")
 
 	os.Unsetenv("CALENDAR_EVENT_STORAGE")
 	os.Unsetenv("CALENDAR_EVENT_STORAGE_PG_DATASOURCE")
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_PG_REPLICAS")
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_PG_MAX_OPEN_CONNS")
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_PG_STATEMENT_TIMEOUT")
+
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_FILE_DIR")
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_FILE_SYNC")
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_FILE_SYNC_INTERVAL")
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_FILE_SNAPSHOT_INTERVAL")
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_FILE_SNAPSHOT_THRESHOLD")
+
+	os.Unsetenv("CALENDAR_EVENT_STORAGE_SQLITE_DATASOURCE")
+
+	os.Unsetenv("CALENDAR_MIGRATE_ON_START")
 }
 
 func Test_ParseConfig(t *testing.T) {
@@ -41,44 +74,161 @@ func Test_ParseConfig(t *testing.T) {
 			name: "full config",
 			cfg: `
   shutdown_timeout: 1s
+  drain_delay: 3s
 
   http:
     port: "12345"
     host: lolo
     read_timeout: 1s
     write_timeout: 1m
+    tls:
+      enabled: true
+      cert_file: /etc/calendar/http.crt
+      key_file: /etc/calendar/http.key
 
   grpc:
     port: "54321"
     host: lolo
+    tls:
+      enabled: true
+      cert_file: /etc/calendar/grpc.crt
+      key_file: /etc/calendar/grpc.key
+      client_ca_file: /etc/calendar/ca.crt
+      require_client_cert: true
+    gateway_tls:
+      ca_file: /etc/calendar/ca.crt
+      cert_file: /etc/calendar/gw.crt
+      key_file: /etc/calendar/gw.key
+      server_name: calendar
 
   logger:
     level: debug
+    format: json
+
+  tracing:
+    exporter: file
+    file: /var/log/traces.json
+    sample_ratio: 0.5
+
+  rate_limit:
+    enabled: true
+    rate: 20
+    burst: 40
+    methods:
+      GetMonthEvents:
+        rate: 1
+        burst: 2
+
+  attachments:
+    dir: /var/lib/calendar/attachments
+    max_file_size: 2048
+    max_per_event: 5
+
+  holidays:
+    dir: /var/lib/calendar/holidays
+    watch_interval: 5m
 
   event_storage: pg
   event_storage_pg:
     data_source: pg://data?source
+    replicas:
+      - pg://replica1
+      - pg://replica2
+    max_open_conns: 20
+    max_idle_conns: 5
+    conn_max_lifetime: 1h
+    conn_max_idle_time: 5m
+    statement_timeout: 3s
+  event_storage_file:
+    dir: /var/lib/calendar
+    sync: interval
+    sync_interval: 5s
+    snapshot_interval: 1h
+    snapshot_threshold: 100
+  event_storage_sqlite:
+    data_source: /var/lib/calendar.db
+
+  migrate_on_start: true
       `,
 			want: Config{
 				ShutdownTimeout: time.Second,
+				DrainDelay:      3 * time.Second,
 
 				HTTP: HTTPConfig{
 					Host:         "lolo",
 					Port:         "12345",
 					ReadTimeout:  time.Second,
 					WriteTimeout: time.Minute,
+					TLS: config.TLSServer{
+						Enabled:  true,
+						CertFile: "/etc/calendar/http.crt",
+						KeyFile:  "/etc/calendar/http.key",
+					},
 				},
 				GRPC: GRPCConfig{
 					Host: "lolo",
 					Port: "54321",
+					TLS: config.TLSServer{
+						Enabled:           true,
+						CertFile:          "/etc/calendar/grpc.crt",
+						KeyFile:           "/etc/calendar/grpc.key",
+						ClientCAFile:      "/etc/calendar/ca.crt",
+						RequireClientCert: true,
+					},
+					GatewayTLS: config.TLSClient{
+						CAFile:     "/etc/calendar/ca.crt",
+						CertFile:   "/etc/calendar/gw.crt",
+						KeyFile:    "/etc/calendar/gw.key",
+						ServerName: "calendar",
+					},
 				},
 				Log: LoggerConfig{
-					Level: slog.LevelDebug,
+					Level:  slog.LevelDebug,
+					Format: logger.FormatJSON,
+				},
+				Tracing: config.Tracing{
+					Exporter:    config.TracingExporterFile,
+					File:        "/var/log/traces.json",
+					SampleRatio: 0.5,
+				},
+				RateLimit: config.RateLimit{
+					Enabled: true,
+					Rate:    20,
+					Burst:   40,
+					Methods: map[string]config.RateLimitRule{
+						"GetMonthEvents": {Rate: 1, Burst: 2},
+					},
+				},
+				Attachments: config.Attachments{
+					Dir:         "/var/lib/calendar/attachments",
+					MaxFileSize: 2048,
+					MaxPerEvent: 5,
+				},
+				Holidays: config.Holidays{
+					Dir:           "/var/lib/calendar/holidays",
+					WatchInterval: 5 * time.Minute,
 				},
 				EventStorageType: "pg",
 				EventStoragePg: config.EventStoragePg{
-					DataSource: "pg://data?source",
+					DataSource:       "pg://data?source",
+					Replicas:         []string{"pg://replica1", "pg://replica2"},
+					MaxOpenConns:     20,
+					MaxIdleConns:     5,
+					ConnMaxLifetime:  time.Hour,
+					ConnMaxIdleTime:  5 * time.Minute,
+					StatementTimeout: 3 * time.Second,
+				},
+				EventStorageFile: config.EventStorageFile{
+					Dir:               "/var/lib/calendar",
+					Sync:              config.FileSyncInterval,
+					SyncInterval:      5 * time.Second,
+					SnapshotInterval:  time.Hour,
+					SnapshotThreshold: 100,
+				},
+				EventStorageSqlite: config.EventStorageSqlite{
+					DataSource: "/var/lib/calendar.db",
 				},
+				MigrateOnStart: true,
 			},
 		},
 		{
@@ -102,9 +252,13 @@ func Test_ParseConfig(t *testing.T) {
   event_storage: memory
   event_storage_pg:
     data_source: unknown
+  event_storage_file:
+    dir: unknown
+    sync: always
       `,
 			init: func() {
 				os.Setenv("CALENDAR_SHUTDOWN_TIMEOUT", "1s")
+				os.Setenv("CALENDAR_DRAIN_DELAY", "10s")
 
 				os.Setenv("CALENDAR_HTTP_HOST", "some.http.host")
 				os.Setenv("CALENDAR_HTTP_PORT", "54321")
@@ -113,14 +267,42 @@ func Test_ParseConfig(t *testing.T) {
 
 				os.Setenv("CALENDAR_GRPC_HOST", "some.grpc.host")
 				os.Setenv("CALENDAR_GRPC_PORT", "12345")
+				os.Setenv("CALENDAR_GRPC_TLS_ENABLED", "true")
+				os.Setenv("CALENDAR_GRPC_TLS_CERT_FILE", "grpc.crt")
+				os.Setenv("CALENDAR_GRPC_TLS_KEY_FILE", "grpc.key")
+				os.Setenv("CALENDAR_GRPC_GATEWAY_TLS_CA_FILE", "ca.crt")
 
 				os.Setenv("CANELDAR_LOG_LEVEL", "error")
+				os.Setenv("CANELDAR_LOG_FORMAT", "json")
+
+				os.Setenv("CALENDAR_TRACING_EXPORTER", "stdout")
+				os.Setenv("CALENDAR_TRACING_SAMPLE_RATIO", "0.1")
+
+				os.Setenv("CALENDAR_RATE_LIMIT_ENABLED", "true")
+				os.Setenv("CALENDAR_RATE_LIMIT_RATE", "5")
+				os.Setenv("CALENDAR_RATE_LIMIT_BURST", "7")
+
+				os.Setenv("CALENDAR_ATTACHMENTS_DIR", "attachments")
+				os.Setenv("CALENDAR_ATTACHMENTS_MAX_FILE_SIZE", "4096")
+
+				os.Setenv("CALENDAR_HOLIDAYS_DIR", "holidays")
 
 				os.Setenv("CALENDAR_EVENT_STORAGE", "pg")
 				os.Setenv("CALENDAR_EVENT_STORAGE_PG_DATASOURCE", "pg://data?source")
+				os.Setenv("CALENDAR_EVENT_STORAGE_PG_REPLICAS", "pg://replica1;pg://replica2")
+				os.Setenv("CALENDAR_EVENT_STORAGE_PG_MAX_OPEN_CONNS", "10")
+				os.Setenv("CALENDAR_EVENT_STORAGE_PG_STATEMENT_TIMEOUT", "2s")
+
+				os.Setenv("CALENDAR_EVENT_STORAGE_FILE_DIR", "/var/lib/calendar")
+				os.Setenv("CALENDAR_EVENT_STORAGE_FILE_SYNC", "never")
+
+				os.Setenv("CALENDAR_EVENT_STORAGE_SQLITE_DATASOURCE", "file:calendar.db")
+
+				os.Setenv("CALENDAR_MIGRATE_ON_START", "true")
 			},
 			want: Config{
 				ShutdownTimeout: time.Second,
+				DrainDelay:      10 * time.Second,
 
 				HTTP: HTTPConfig{
 					Host:         "some.http.host",
@@ -131,15 +313,58 @@ func Test_ParseConfig(t *testing.T) {
 				GRPC: GRPCConfig{
 					Host: "some.grpc.host",
 					Port: "12345",
+					TLS: config.TLSServer{
+						Enabled:  true,
+						CertFile: "grpc.crt",
+						KeyFile:  "grpc.key",
+					},
+					GatewayTLS: config.TLSClient{
+						CAFile: "ca.crt",
+					},
 				},
 
 				Log: LoggerConfig{
-					Level: slog.LevelError,
+					Level:  slog.LevelError,
+					Format: logger.FormatJSON,
+				},
+				Tracing: config.Tracing{
+					Exporter:    config.TracingExporterStdout,
+					File:        "traces.json",
+					SampleRatio: 0.1,
+				},
+				RateLimit: config.RateLimit{
+					Enabled: true,
+					Rate:    5,
+					Burst:   7,
+				},
+				Attachments: config.Attachments{
+					Dir:         "attachments",
+					MaxFileSize: 4096,
+					MaxPerEvent: 10,
+				},
+				Holidays: config.Holidays{
+					Dir:           "holidays",
+					WatchInterval: time.Minute,
 				},
 				EventStorageType: "pg",
 				EventStoragePg: config.EventStoragePg{
-					DataSource: "pg://data?source",
+					DataSource:       "pg://data?source",
+					Replicas:         []string{"pg://replica1", "pg://replica2"},
+					MaxOpenConns:     10,
+					MaxIdleConns:     2,
+					StatementTimeout: 2 * time.Second,
+				},
+				EventStorageFile: config.EventStorageFile{
+					Dir:               "/var/lib/calendar",
+					Sync:              config.FileSyncNever,
+					SyncInterval:      time.Second,
+					SnapshotInterval:  10 * time.Minute,
+					SnapshotThreshold: 10000,
 				},
+				EventStorageSqlite: config.EventStorageSqlite{
+					DataSource: "file:calendar.db",
+				},
+				MigrateOnStart: true,
 			},
 		},
 		{
@@ -159,10 +384,39 @@ func Test_ParseConfig(t *testing.T) {
 					Port: "50051",
 				},
 				Log: LoggerConfig{
-					Level: slog.LevelInfo,
+					Level:  slog.LevelInfo,
+					Format: logger.FormatText,
+				},
+				Tracing: config.Tracing{
+					Exporter:    config.TracingExporterNone,
+					File:        "traces.json",
+					SampleRatio: 1,
+				},
+				RateLimit: config.RateLimit{
+					Rate:  50,
+					Burst: 100,
+				},
+				Attachments: config.Attachments{
+					MaxFileSize: 1048576,
+					MaxPerEvent: 10,
+				},
+				Holidays: config.Holidays{
+					WatchInterval: time.Minute,
 				},
 				EventStorageType: "memory",
-				EventStoragePg:   config.EventStoragePg{},
+				EventStoragePg: config.EventStoragePg{
+					MaxIdleConns: 2,
+				},
+				EventStorageFile: config.EventStorageFile{
+					Dir:               "data/events",
+					Sync:              config.FileSyncAlways,
+					SyncInterval:      time.Second,
+					SnapshotInterval:  10 * time.Minute,
+					SnapshotThreshold: 10000,
+				},
+				EventStorageSqlite: config.EventStorageSqlite{
+					DataSource: "data/calendar.db",
+				},
 			},
 		},
 	}
@@ -232,6 +486,22 @@ func Test_ParseConfigError(t *testing.T) {
 		    `,
 			wantError: true,
 		},
+		{
+			name: "invalid log format",
+			cfg: `
+      logger:
+        format: xml
+          `,
+			wantError: true,
+		},
+		{
+			name: "invalid tracing exporter",
+			cfg: `
+      tracing:
+        exporter: jaeger
+          `,
+			wantError: true,
+		},
 		{
 			name: "invalid event storage env",
 			cfg:  `default: true`,
@@ -240,6 +510,15 @@ func Test_ParseConfigError(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "invalid event storage file sync",
+			cfg: `
+      event_storage: file
+      event_storage_file:
+        sync: sometimes
+          `,
+			wantError: true,
+		},
 	}
 
 	for i, tt := range tests {
@@ -2,42 +2,69 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"time"
+	_ "time/tzdata" // часовые пояса профилей владельцев, если в системе нет базы часовых поясов
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/ilyakaznacheev/cleanenv"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
+	attachmentAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/attachment"
+	caldavAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/caldav"
 	calendarAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/calendar"
+	feedAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/feed"
 	helloAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/hello"
 	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
 	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
 	helloBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/hello"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/gw"
+	grpcInterceptor "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/interceptor"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/health"
 	internalhttp "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http"
 	httpMiddleware "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/middleware"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/metrics"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/migrate"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+	localBlob "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob/local"
+	eventStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+	fileStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/file"
+	instrumentedStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/instrumented"
 	memoryStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
 	pgStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/pg"
+	sqliteStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/sqlite"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/holiday"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
 )
 
 const serviceName = "calendar"
 
+// healthSyncInterval - как часто обновлять статус grpc health-сервиса.
+const healthSyncInterval = 5 * time.Second
+
 func main() {
 	initFlag()
 
-	logger, levelVar := logger.New(os.Stdout, slog.LevelInfo, serviceName)
+	logger, levelVar, formatVar := logger.New(os.Stdout, slog.LevelInfo, serviceName)
 
 	ctx := context.Background()
-	if err := run(ctx, logger, levelVar); err != nil {
+	if err := run(ctx, logger, levelVar, formatVar); err != nil {
 		logger.Error(
 			"failed to run",
 			slog.String("error", err.Error()),
@@ -52,6 +79,9 @@ func initFlag() {
 
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s [flags]\t\t\t\tstart service\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s [flags] migrate %s\tmigrate storage\n", os.Args[0], migrateCommands)
+		fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
 		flag.PrintDefaults()
 
 		var cfg Config
@@ -62,7 +92,12 @@ func initFlag() {
 	flag.Parse()
 }
 
-func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) error {
+func run(
+	ctx context.Context,
+	logger *slog.Logger,
+	levelVar *slog.LevelVar,
+	formatVar *logger.FormatVar,
+) error {
 	logger.Info(
 		"starting service",
 		slog.Group(
@@ -82,6 +117,10 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 		return err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
 	logger.Info(
 		"set logger level",
 		slog.String("from", levelVar.Level().String()),
@@ -89,37 +128,149 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 	)
 	levelVar.Set(cfg.Log.Level)
 
+	logger.Info(
+		"set logger format",
+		slog.String("from", string(formatVar.Format())),
+		slog.String("to", string(cfg.Log.Format)),
+	)
+	formatVar.Set(cfg.Log.Format)
+
+	logger.Info(
+		"init tracing",
+		slog.String("exporter", string(cfg.Tracing.Exporter)),
+	)
+	shutdownTracing, err := tracing.Init(cfg.Tracing, serviceName)
+	if err != nil {
+		return fmt.Errorf("can't init tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			return fmt.Errorf("unknown command '%s'", args[0])
+		}
+
+		return runMigrate(ctx, logger, cfg, args[1:])
+	}
+
+	logger.Info("init metrics")
+	serviceMetrics, err := initMetrics()
+	if err != nil {
+		return fmt.Errorf("can't init metrics: %w", err)
+	}
+
 	logger.Info(
 		"init storage",
 		slog.String("storage", cfg.EventStorageType.String()),
 	)
-	storage, storageDoneFn, err := initStorage(cfg)
+	storage, storageDoneFn, err := initStorage(ctx, logger, cfg)
 	if err != nil {
 		return err
 	}
 	defer storageDoneFn()
 
+	checker := health.NewChecker(health.DefaultTimeout)
+	if pinger, ok := storage.(health.Pinger); ok {
+		checker.AddReadiness("storage", pinger.Ping)
+	}
+
+	if pools, ok := storage.(metrics.DBPools); ok {
+		if err := metrics.NewDBStats(serviceMetrics.registry, pools); err != nil {
+			return fmt.Errorf("can't init metrics: %w", err)
+		}
+	}
+
+	storage = instrumentedStorage.New(storage, serviceMetrics.storage)
+
+	logger.Info(
+		"init rate limiter",
+		slog.Bool("enabled", cfg.RateLimit.Enabled),
+	)
+	limiter := ratelimit.New(clock.Real{}, rateLimitRules(cfg.RateLimit))
+
 	logger.Info("init app")
 
 	helloBusinessApp := helloBusiness.NewApp(logger.With(slog.String("comp", "business-hello")))
-	calendarBusinessApp := calendarBusiness.NewApp(logger.With(slog.String("comp", "business-calendar")), storage)
+	calendarBusinessApp := calendarBusiness.NewApp(
+		logger.With(slog.String("comp", "business-calendar")),
+		clock.Real{},
+		storage,
+	)
+	calendarBusinessApp.SetQuotas(tenantQuotas(cfg.Quotas))
+
+	blobs, err := initBlobStore(logger, cfg.Attachments)
+	if err != nil {
+		return err
+	}
+	calendarBusinessApp.SetAttachments(blobs, calendarBusiness.AttachmentLimits{
+		MaxFileSize: cfg.Attachments.MaxFileSize,
+		MaxPerEvent: cfg.Attachments.MaxPerEvent,
+	})
+
+	holidays, err := initHolidays(logger, cfg.Holidays)
+	if err != nil {
+		return err
+	}
+	if holidays != nil {
+		calendarBusinessApp.SetHolidays(holidays)
+
+		holidaysCtx, holidaysCancel := context.WithCancel(ctx)
+		defer holidaysCancel()
+
+		go holidays.Watch(holidaysCtx, clock.Real{}, cfg.Holidays.WatchInterval)
+	}
 
 	helloAPIApp := helloAPI.NewApp(helloBusinessApp, logger.With(slog.String("comp", "api-hello")))
 	calendarAPIApp := calendarAPI.NewApp(calendarBusinessApp, logger.With(slog.String("comp", "api-calendar")))
+	adminAPIApp := calendarAPI.NewAdminApp(calendarBusinessApp, logger.With(slog.String("comp", "api-admin")))
+	attachmentAPIApp := attachmentAPI.NewApp(calendarBusinessApp, logger.With(slog.String("comp", "api-attachment")))
+	feedAPIApp := feedAPI.NewApp(calendarBusinessApp, clock.Real{}, logger.With(slog.String("comp", "api-feed")))
+	caldavAPIApp := caldavAPI.NewApp(calendarBusinessApp, logger.With(slog.String("comp", "api-caldav")))
 
-	// Создаём webmux для hello-app
+	// Создаём webmux для hello-app, скачивания вложений, календарей по ссылкам для подписки и CalDAV
 	webMux, err := web.NewMux(
 		logger.With(slog.String("comp", "web-mux")),
 		helloAPIApp,
+		attachmentAPIApp,
+		feedAPIApp,
+		caldavAPIApp,
 	)
 	if err != nil {
 		return fmt.Errorf("can't create web-mux: %w", err)
 	}
 
+	// TLS серверов и клиента grpc-gateway
+	tlsCtx, tlsCancel := context.WithCancel(ctx)
+	defer tlsCancel()
+
+	httpTLS, err := initServerTLS(tlsCtx, logger, "http", cfg.HTTP.TLS)
+	if err != nil {
+		return err
+	}
+
+	grpcTLS, err := initServerTLS(tlsCtx, logger, "grpc", cfg.GRPC.TLS)
+	if err != nil {
+		return err
+	}
+
+	grpcGWCredentials, err := gatewayCredentials(tlsCtx, logger, cfg.GRPC)
+	if err != nil {
+		return err
+	}
+
+	// Секрет, по которому GRPC-сервер отличает запросы grpc-gateway: они уже ограничены http-middleware
+	gatewaySecret, err := newGatewaySecret()
+	if err != nil {
+		return fmt.Errorf("can't init GRPC-gw secret: %w", err)
+	}
+
 	// Создаём клиента для grpc-gateway
+	grpcGWClientInterceptors := grpcInterceptor.GatewayClient(gatewaySecret)
 	grpcGWConn, err := grpc.NewClient(
 		net.JoinHostPort(cfg.GRPC.Host, cfg.GRPC.Port),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(grpcGWCredentials),
+		grpc.WithChainUnaryInterceptor(grpcInterceptor.TraceClient(), grpcGWClientInterceptors.UnaryInterceptor),
+		grpc.WithChainStreamInterceptor(grpcGWClientInterceptors.StreamInterceptor),
 	)
 	if err != nil {
 		return fmt.Errorf("can't init GRPC-gw client: %w", err)
@@ -131,8 +282,10 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 			gw.HeaderMatchers(
 				gw.NoGRPCHeaders,
 				gw.OwnerID,
+				gw.TenantID,
 			),
 		),
+		runtime.WithErrorHandler(gw.ProblemErrorHandler),
 	)
 
 	// http-хендлер для всех запросов - будет настроен как роутер для webMux и gwMux
@@ -143,23 +296,57 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 		"/api/",
 		internalhttp.ApplyMiddlewares(
 			gwMux,
+			httpMiddleware.Trace("grpc-gw"),
 			httpMiddleware.URLPathPrefixReplace("/api/", "/"),
 			httpMiddleware.LogRequest(logger.WithGroup("http-grpc-gw-request")),
+			httpMiddleware.Metrics(serviceMetrics.http, "grpc-gw"),
 		),
 	)
 
+	// Метрики сервиса
+	httpMux.Handle("/metrics", metrics.Handler(serviceMetrics.registry))
+
+	// Проверки состояния сервиса: /healthz, /readyz
+	checker.Register(httpMux)
+
+	// grpc health-сервис, статус обновляется по проверке готовности
+	healthServer := grpchealth.NewServer()
+
+	healthCtx, healthCancel := context.WithCancel(ctx)
+	defer healthCancel()
+
+	go checker.SyncGRPC(healthCtx, healthServer, healthSyncInterval)
+
 	// По умолчинаю все запросы будут идти на webMux
 	httpMux.Handle(
 		"/",
 		internalhttp.ApplyMiddlewares(
 			webMux,
+			httpMiddleware.Trace("web"),
+			httpMiddleware.LogRequest(logger.WithGroup("http-request")),
+			httpMiddleware.Metrics(serviceMetrics.http, "web"),
+			httpMiddleware.RateLimit(limiter, "web"),
+		),
+	)
+
+	// Календари по ссылкам для подписки: секрет ссылки в пути не должен попадать в логи и трассировку
+	httpMux.Handle(
+		feedAPI.PathPrefix,
+		internalhttp.ApplyMiddlewares(
+			webMux,
+			httpMiddleware.RedactPath(feedAPI.PathPrefix, feedAPI.PathSuffix),
+			httpMiddleware.Trace("web"),
 			httpMiddleware.LogRequest(logger.WithGroup("http-request")),
+			httpMiddleware.Metrics(serviceMetrics.http, "web"),
+			httpMiddleware.RateLimit(limiter, "web"),
 		),
 	)
 
 	// Регистратор grpc сервиса:
 	//   - регистрирует EventService-хендлер для grpc-gw
 	//   - регистрирует EventService
+	//   - регистрирует TenantAdminService (только grpc, без grpc-gw)
+	//   - регистрирует grpc health-сервис
 	grpcRegisterFn := grpcServiceRegisterFunc(func(s *grpc.Server) error {
 		err := pbEventV1.RegisterEventServiceHandler(context.Background(), gwMux, grpcGWConn)
 		if err != nil {
@@ -167,38 +354,199 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 		}
 
 		pbEventV1.RegisterEventServiceServer(s, calendarAPIApp)
+		pbEventV1.RegisterTenantAdminServiceServer(s, adminAPIApp)
+		healthpb.RegisterHealthServer(s, healthServer)
 
 		return nil
 	})
 
-	httpStart, httpStop := createHTTPServer(logger, cfg, httpMux)
-	grpcStart, grpcStop, err := createGRPCServer(logger, cfg, grpcRegisterFn)
+	httpStart, httpStop := createHTTPServer(logger, cfg, httpTLS, httpMux)
+	grpcStart, grpcStop, err := createGRPCServer(
+		logger,
+		cfg,
+		grpcTLS,
+		serviceMetrics.grpc,
+		limiter,
+		gatewaySecret,
+		grpcRegisterFn,
+	)
 	if err != nil {
 		return fmt.Errorf("can't create GRPC server: %w", err)
 	}
 
+	// Перечитывание конфигурации по SIGHUP
+	liveCfg := config.NewLive(cfg)
+
+	reloadCtx, reloadCancel := context.WithCancel(ctx)
+	defer reloadCancel()
+
+	reload := reloadConfig(logger, liveCfg, levelVar, formatVar, limiter, calendarBusinessApp)
+	go config.WatchReload(reloadCtx, logger, configFile, reload)
+
+	// При завершении работы сервис перестаёт быть готовым к приёму запросов
+	drain := func() {
+		checker.Drain()
+		healthServer.Shutdown()
+	}
+
 	return startAndShutdown(
 		ctx,
 		logger,
-		cfg,
+		liveCfg,
+		drain,
 		[]startServerFunc{httpStart, grpcStart},
 		[]stopServerFunc{httpStop, grpcStop},
 	)
 }
 
-func initStorage(cfg Config) (calendarBusiness.EventStorage, func() error, error) {
+// initBlobStore создаёт хранилище файлов вложений.
+// Возвращает nil, если директория хранилища не задана: вложениями могут быть только ссылки.
+func initBlobStore(logger *slog.Logger, cfg config.Attachments) (blob.Store, error) {
+	if cfg.Dir == "" {
+		logger.Info("file attachments disabled")
+
+		return nil, nil
+	}
+
+	logger.Info("init blob store", slog.String("dir", cfg.Dir))
+
+	store, err := localBlob.NewStore(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't init blob store: %w", err)
+	}
+
+	return store, nil
+}
+
+// initHolidays создаёт хранилище календарей праздников.
+// Возвращает nil, если директория календарей не задана: календари праздников недоступны.
+func initHolidays(logger *slog.Logger, cfg config.Holidays) (*holiday.Store, error) {
+	if cfg.Dir == "" {
+		logger.Info("holiday calendars disabled")
+
+		return nil, nil
+	}
+
+	if cfg.WatchInterval <= 0 {
+		return nil, fmt.Errorf("invalid holiday calendars watch interval %s", cfg.WatchInterval)
+	}
+
+	logger.Info("init holiday calendars", slog.String("dir", cfg.Dir))
+
+	store, err := holiday.NewStore(logger.With(slog.String("comp", "holidays")), cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't init holiday calendars: %w", err)
+	}
+
+	return store, nil
+}
+
+func initStorage(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg Config,
+) (eventStorage.Storage, func() error, error) {
 	switch cfg.EventStorageType {
 	case config.EventStorageTypeMemory:
 		storage := memoryStorage.NewStorage()
 		return storage, func() error { return nil }, nil
 	case config.EventStorageTypePg:
-		storage, err := pgStorage.NewStorage(cfg.EventStoragePg.DataSource)
+		storage, err := pgStorage.NewStorage(pgStorageOptions(cfg.EventStoragePg))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if cfg.MigrateOnStart {
+			if err := migrateOnStart(ctx, logger, storage.DB.DB, migrate.DialectPostgres); err != nil {
+				return nil, nil, errors.Join(err, storage.Close())
+			}
+		}
+
+		return storage, storage.Close, nil
+	case config.EventStorageTypeFile:
+		storage, err := fileStorage.NewStorage(fileStorage.Options{
+			Dir:               cfg.EventStorageFile.Dir,
+			Sync:              fileStorage.SyncPolicy(cfg.EventStorageFile.Sync),
+			SyncInterval:      cfg.EventStorageFile.SyncInterval,
+			SnapshotInterval:  cfg.EventStorageFile.SnapshotInterval,
+			SnapshotThreshold: cfg.EventStorageFile.SnapshotThreshold,
+			Logger:            logger.With(slog.String("comp", "storage-file")),
+		})
 		if err != nil {
 			return nil, nil, err
 		}
 
+		return storage, storage.Close, nil
+	case config.EventStorageTypeSqlite:
+		storage, err := sqliteStorage.NewStorage(cfg.EventStorageSqlite.DataSource)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if cfg.MigrateOnStart {
+			if err := migrateOnStart(ctx, logger, storage.DB.DB, migrate.DialectSqlite); err != nil {
+				return nil, nil, errors.Join(err, storage.Close())
+			}
+		}
+
 		return storage, storage.Close, nil
 	default:
 		return nil, nil, fmt.Errorf("storage '%s' is not supported", cfg.EventStorageType)
 	}
 }
+
+// pgStorageOptions возвращает параметры подключения к postgres из конфигурации.
+func pgStorageOptions(cfg config.EventStoragePg) pgStorage.Options {
+	return pgStorage.Options{
+		DataSource:       cfg.DataSource,
+		Replicas:         cfg.Replicas,
+		MaxOpenConns:     cfg.MaxOpenConns,
+		MaxIdleConns:     cfg.MaxIdleConns,
+		ConnMaxLifetime:  cfg.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.ConnMaxIdleTime,
+		StatementTimeout: cfg.StatementTimeout,
+	}
+}
+
+// tenantQuotas возвращает ограничения количества событий в рабочих пространствах из конфигурации.
+func tenantQuotas(cfg config.TenantQuotas) calendarBusiness.Quotas {
+	quotas := calendarBusiness.Quotas{
+		MaxEvents: cfg.MaxEvents,
+		Tenants:   make(map[model.TenantID]int, len(cfg.Tenants)),
+	}
+
+	for tenant, maxEvents := range cfg.Tenants {
+		quotas.Tenants[model.TenantID(tenant)] = maxEvents
+	}
+
+	return quotas
+}
+
+// newGatewaySecret возвращает случайный секрет grpc-gateway на время работы сервиса.
+func newGatewaySecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// rateLimitRules возвращает ограничения частоты запросов из конфигурации.
+// Если ограничение выключено, возвращаются пустые правила (без ограничений).
+func rateLimitRules(cfg config.RateLimit) ratelimit.Rules {
+	if !cfg.Enabled {
+		return ratelimit.Rules{}
+	}
+
+	rules := ratelimit.Rules{
+		Default: ratelimit.Rule{Rate: cfg.Rate, Burst: cfg.Burst},
+		Methods: make(map[string]ratelimit.Rule, len(cfg.Methods)),
+	}
+
+	for method, rule := range cfg.Methods {
+		rules.Methods[method] = ratelimit.Rule{Rate: rule.Rate, Burst: rule.Burst}
+	}
+
+	return rules
+}
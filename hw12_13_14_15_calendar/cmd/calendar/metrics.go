@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/metrics"
+)
+
+// serviceMetrics - метрики сервиса календаря.
+type serviceMetrics struct {
+	registry *prometheus.Registry
+
+	grpc    *metrics.GRPC
+	http    *metrics.HTTP
+	storage *metrics.Storage
+}
+
+// initMetrics создаёт реестр и регистрирует в нём метрики сервиса.
+func initMetrics() (serviceMetrics, error) {
+	var (
+		m   = serviceMetrics{registry: metrics.NewRegistry()}
+		err error
+	)
+
+	if m.grpc, err = metrics.NewGRPC(m.registry); err != nil {
+		return serviceMetrics{}, err
+	}
+
+	if m.http, err = metrics.NewHTTP(m.registry); err != nil {
+		return serviceMetrics{}, err
+	}
+
+	if m.storage, err = metrics.NewStorage(m.registry); err != nil {
+		return serviceMetrics{}, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/migrate"
+	pgStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/pg"
+	sqliteStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/sqlite"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/migrations"
+)
+
+const migrateCommands = "up|down|status|redo"
+
+// runMigrate выполняет команду миграции хранилища: migrate up|down|status|redo.
+func runMigrate(ctx context.Context, logger *slog.Logger, cfg Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: migrate %s", migrateCommands)
+	}
+
+	logger = logger.With(slog.String("comp", "migrate"))
+
+	migrator, closeFn, err := newMigrator(logger, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			logger.Info("no migrations to apply")
+		}
+	case "down":
+		if _, err := migrator.Down(ctx); err != nil {
+			return err
+		}
+	case "redo":
+		if _, err := migrator.Redo(ctx); err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		return printMigrateStatus(statuses)
+	default:
+		return fmt.Errorf("unknown migrate command '%s', must be one of %s", args[0], migrateCommands)
+	}
+
+	return nil
+}
+
+// newMigrator создаёт Migrator для хранилища из конфигурации.
+// Возвращает Migrator и функцию закрытия соединения с базой.
+func newMigrator(logger *slog.Logger, cfg Config) (*migrate.Migrator, func() error, error) {
+	var (
+		db      *sql.DB
+		closeFn func() error
+		dialect migrate.Dialect
+		fsys    fs.FS
+	)
+
+	switch cfg.EventStorageType {
+	case config.EventStorageTypePg:
+		storage, err := pgStorage.NewStorage(pgStorageOptions(cfg.EventStoragePg))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		db, closeFn, dialect, fsys = storage.DB.DB, storage.Close, migrate.DialectPostgres, migrations.Pg
+	case config.EventStorageTypeSqlite:
+		storage, err := sqliteStorage.NewStorage(cfg.EventStorageSqlite.DataSource)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		db, closeFn, dialect, fsys = storage.DB.DB, storage.Close, migrate.DialectSqlite, migrations.Sqlite
+	default:
+		return nil, nil, fmt.Errorf("storage '%s' has no migrations", cfg.EventStorageType)
+	}
+
+	migrator, err := migrate.New(db, dialect, fsys, logger)
+	if err != nil {
+		return nil, nil, errors.Join(err, closeFn())
+	}
+
+	return migrator, closeFn, nil
+}
+
+// migrateOnStart применяет встроенные миграции к базе db при запуске сервиса.
+func migrateOnStart(ctx context.Context, logger *slog.Logger, db *sql.DB, dialect migrate.Dialect) error {
+	fsys := migrations.Pg
+	if dialect == migrate.DialectSqlite {
+		fsys = migrations.Sqlite
+	}
+
+	logger = logger.With(slog.String("comp", "migrate"))
+
+	migrator, err := migrate.New(db, dialect, fsys, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("migrate storage")
+
+	if _, err := migrator.Up(ctx); err != nil {
+		return fmt.Errorf("can't migrate storage: %w", err)
+	}
+
+	return nil
+}
+
+// printMigrateStatus выводит состояние миграций в формате goose status.
+func printMigrateStatus(statuses []migrate.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+
+	fmt.Fprintln(w, "Applied At\tMigration")
+	fmt.Fprintln(w, "==========\t=========")
+
+	for _, status := range statuses {
+		appliedAt := "Pending"
+		if status.Applied {
+			appliedAt = status.AppliedAt.UTC().Format(time.ANSIC)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\n", appliedAt, status.Migration.Name)
+	}
+
+	return w.Flush()
+}
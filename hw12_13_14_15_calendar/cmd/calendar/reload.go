@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log/slog"
+
+	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
+)
+
+// liveFields - поля конфигурации, изменения которых применяются без перезапуска сервиса.
+// Изменения остальных полей (адреса, TLS, хранилище и т.п.) требуют перезапуска и не применяются.
+var liveFields = []string{
+	"shutdown_timeout",
+	"drain_delay",
+	"logger.level",
+	"logger.format",
+	"rate_limit",
+	"quotas",
+}
+
+// reloadConfig возвращает функцию, применяющую перечитанную конфигурацию next к текущей конфигурации cfg,
+// уровню логирования levelVar, формату логов formatVar, ограничениям частоты запросов limiter
+// и квотам рабочих пространств calendarApp.
+// Конфигурация, не прошедшая проверку Config.Validate, не применяется.
+func reloadConfig(
+	logger *slog.Logger,
+	cfg *config.Live[Config],
+	levelVar *slog.LevelVar,
+	formatVar *logger.FormatVar,
+	limiter *ratelimit.Limiter,
+	calendarApp *calendarBusiness.App,
+) func(next Config) {
+	return func(next Config) {
+		if err := next.Validate(); err != nil {
+			logger.Error(
+				"invalid config, changes are not applied",
+				slog.String("error", err.Error()),
+			)
+
+			return
+		}
+
+		current := cfg.Load()
+
+		applied, restart := config.Split(config.Diff(current, next), liveFields)
+		if len(restart) > 0 {
+			logger.Warn(
+				"config changes require restart and are not applied",
+				slog.Any("fields", restart),
+			)
+		}
+
+		if len(applied) == 0 {
+			logger.Info("no config changes to apply")
+			return
+		}
+
+		current.ShutdownTimeout = next.ShutdownTimeout
+		current.DrainDelay = next.DrainDelay
+		current.Log = next.Log
+		current.RateLimit = next.RateLimit
+		current.Quotas = next.Quotas
+
+		levelVar.Set(current.Log.Level)
+		formatVar.Set(current.Log.Format)
+
+		if config.HasChange(applied, "rate_limit") {
+			limiter.SetRules(rateLimitRules(current.RateLimit))
+		}
+
+		if config.HasChange(applied, "quotas") {
+			calendarApp.SetQuotas(tenantQuotas(current.Quotas))
+		}
+
+		cfg.Store(current)
+
+		logger.Info(
+			"config changes applied",
+			slog.Any("fields", applied),
+		)
+	}
+}
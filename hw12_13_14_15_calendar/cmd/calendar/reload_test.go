@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
+	memoryStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
+)
+
+func Test_ReloadConfig(t *testing.T) {
+	cfg, err := ParseConfig(strings.NewReader(`default: true`))
+	require.NoError(t, err, "must parse default config")
+
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(cfg.Log.Level)
+
+	formatVar := &logger.FormatVar{}
+
+	limiter := ratelimit.New(clock.Real{}, rateLimitRules(cfg.RateLimit))
+	liveCfg := config.NewLive(cfg)
+
+	calendarApp := calendarBusiness.NewApp(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		clock.Real{},
+		memoryStorage.NewStorage(),
+	)
+
+	reload := reloadConfig(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		liveCfg,
+		levelVar,
+		formatVar,
+		limiter,
+		calendarApp,
+	)
+
+	next := cfg
+	next.ShutdownTimeout = time.Minute
+	next.Log.Level = slog.LevelDebug
+	next.Log.Format = logger.FormatJSON
+	next.RateLimit = config.RateLimit{Enabled: true, Rate: 1, Burst: 1}
+	next.Quotas = config.TenantQuotas{Tenants: map[string]int{"default": 1}}
+	next.GRPC.Port = "12345"
+	next.EventStorageType = config.EventStorageTypePg
+
+	reload(next)
+
+	current := liveCfg.Load()
+	require.Equal(t, time.Minute, current.ShutdownTimeout, "must apply shutdown timeout")
+	require.Equal(t, slog.LevelDebug, levelVar.Level(), "must apply log level")
+	require.Equal(t, logger.FormatJSON, formatVar.Format(), "must apply log format")
+	require.Equal(t, next.RateLimit, current.RateLimit, "must apply rate limit")
+	require.Equal(t, next.Quotas, current.Quotas, "must apply quotas")
+
+	require.Equal(t, cfg.GRPC.Port, current.GRPC.Port, "must not apply listen address")
+	require.Equal(t, cfg.EventStorageType, current.EventStorageType, "must not apply storage type")
+
+	ok, _ := limiter.Allow("/event.v1.EventService/GetDayEvents", "owner:1")
+	require.True(t, ok, "must allow first request")
+
+	ok, _ = limiter.Allow("/event.v1.EventService/GetDayEvents", "owner:1")
+	require.False(t, ok, "must apply new rate limit rules")
+
+	newEvent := func(startAt time.Time) model.Event {
+		event, err := model.NewEvent(
+			model.DefaultTenantID, model.NewID(), model.NewOwnerID(), "quota", startAt, startAt.Add(time.Hour),
+		)
+		require.NoError(t, err, "must create event")
+
+		return event
+	}
+
+	ctx := context.Background()
+	startAt := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+	_, err = calendarApp.CreateEvent(ctx, newEvent(startAt))
+	require.NoError(t, err, "must create event within quota")
+
+	_, err = calendarApp.CreateEvent(ctx, newEvent(startAt))
+	require.ErrorIs(t, err, calendarBusiness.ErrTenantQuotaExceeded, "must apply new quotas")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -11,10 +12,15 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
 	grpcInterceptor "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/interceptor"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/metrics"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
 )
 
 type (
@@ -25,9 +31,11 @@ type (
 )
 
 // createHTTPServer создаёт новый HTTP-сервер и возвращает функцию старта и останова сервера.
+// Если задан tlsConfig, сервер принимает соединения по TLS.
 func createHTTPServer(
 	logger *slog.Logger,
 	cfg Config,
+	tlsConfig *tls.Config,
 	handler http.Handler,
 ) (startServerFunc, stopServerFunc) {
 	listenAddr := net.JoinHostPort(cfg.HTTP.Host, cfg.HTTP.Port)
@@ -38,14 +46,20 @@ func createHTTPServer(
 		ErrorLog:     slog.NewLogLogger(logger.Handler(), cfg.Log.Level),
 		ReadTimeout:  cfg.HTTP.ReadTimeout,
 		WriteTimeout: cfg.HTTP.WriteTimeout,
+		TLSConfig:    tlsConfig,
 	}
 
 	start := func() error {
 		logger.Info(
 			"starting HTTP server",
 			slog.String("listen", listenAddr),
+			slog.Bool("tls", tlsConfig != nil),
 		)
 
+		if tlsConfig != nil {
+			return server.ListenAndServeTLS("", "")
+		}
+
 		return server.ListenAndServe()
 	}
 
@@ -62,29 +76,57 @@ func createHTTPServer(
 }
 
 // createGRPCServer создаёт GRPC-сервер и возвращает функции старта и останова сервера.
+// Если задан tlsConfig, сервер принимает соединения по TLS.
+// Запросы grpc-gateway с секретом gatewaySecret не ограничиваются limiter (см. grpcInterceptor.RateLimit).
 //
 // Возвращает ошибку, случившуюся при регистрации сервиса.
 func createGRPCServer(
 	logger *slog.Logger,
 	cfg Config,
+	tlsConfig *tls.Config,
+	grpcMetrics *metrics.GRPC,
+	limiter *ratelimit.Limiter,
+	gatewaySecret string,
 	register grpcServiceRegisterFunc,
 ) (startServerFunc, stopServerFunc, error) {
+	grpcTraceInterceptors := grpcInterceptor.Trace()
 	grpcLogInterceptors := grpcInterceptor.LogRequest(logger.WithGroup("grpc-request"))
-	grpcAuthInterceptors := grpcInterceptor.Auth(logger.WithGroup("grpc-auth"))
+	grpcMetricsInterceptors := grpcInterceptor.Metrics(grpcMetrics)
+	grpcAuthInterceptors := grpcInterceptor.Auth(logger.WithGroup("grpc-auth"), cfg.GRPC.AdminServices)
+	grpcRateLimitInterceptors := grpcInterceptor.RateLimit(
+		logger.WithGroup("grpc-rate-limit"),
+		limiter,
+		gatewaySecret,
+	)
+	grpcReadYourWritesInterceptors := grpcInterceptor.ReadYourWrites()
 
-	server := grpc.NewServer(
+	serverOptions := []grpc.ServerOption{
 		grpcLogInterceptors.UnknownServiceHandler,
 
 		grpc.ChainUnaryInterceptor(
+			grpcTraceInterceptors.UnaryInterceptor,
 			grpcLogInterceptors.UnaryInterceptor,
+			grpcMetricsInterceptors.UnaryInterceptor,
 			grpcAuthInterceptors.UnaryInterceptor,
+			grpcRateLimitInterceptors.UnaryInterceptor,
+			grpcReadYourWritesInterceptors.UnaryInterceptor,
 		),
 
 		grpc.ChainStreamInterceptor(
+			grpcTraceInterceptors.StreamInterceptor,
 			grpcLogInterceptors.StreamInterceptor,
+			grpcMetricsInterceptors.StreamInterceptor,
 			grpcAuthInterceptors.StreamInterceptor,
+			grpcRateLimitInterceptors.StreamInterceptor,
+			grpcReadYourWritesInterceptors.StreamInterceptor,
 		),
-	)
+	}
+
+	if tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(serverOptions...)
 
 	if err := register(server); err != nil {
 		return nil, nil, err
@@ -101,6 +143,7 @@ func createGRPCServer(
 		logger.Info(
 			"start GRPC server",
 			slog.String("listen", listenAddr),
+			slog.Bool("tls", tlsConfig != nil),
 		)
 
 		return server.Serve(lstn)
@@ -129,11 +172,15 @@ func createGRPCServer(
 
 // startAndShutdown - функция старта и завершения работы серверов.
 //
+// При завершении работы сначала вызывается drain (сервис перестаёт быть готовым к приёму запросов),
+// затем, спустя DrainDelay, серверы останавливаются. Используются значения текущей конфигурации cfg.
+//
 // Будет возвращена первая ошибка при запуске сервера или все ошибки при останове.
 func startAndShutdown(
 	ctx context.Context,
 	logger *slog.Logger,
-	cfg Config,
+	cfg *config.Live[Config],
+	drain func(),
 	starters []startServerFunc,
 	stoppers []stopServerFunc,
 ) error {
@@ -160,7 +207,19 @@ func startAndShutdown(
 			slog.String("signal", sig.String()),
 		)
 
-		ctx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+		currentCfg := cfg.Load()
+
+		// балансировщики должны успеть увидеть, что сервис не готов, и перестать направлять запросы
+		drain()
+		if currentCfg.DrainDelay > 0 {
+			logger.Info(
+				"drain",
+				slog.Duration("delay", currentCfg.DrainDelay),
+			)
+			time.Sleep(currentCfg.DrainDelay)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, currentCfg.ShutdownTimeout)
 		defer cancel()
 
 		errsCh := make(chan error, len(stoppers))
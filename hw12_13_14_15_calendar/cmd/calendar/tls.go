@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tlsconfig"
+)
+
+// initServerTLS создаёт настройки TLS сервера server, если TLS включён.
+// Сертификаты перечитываются при изменении, пока не отменён контекст ctx.
+//
+// Возвращает nil, если TLS выключен.
+func initServerTLS(ctx context.Context, logger *slog.Logger, server string, cfg config.TLSServer) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig, store, err := tlsconfig.NewServer(logger.With(slog.String("comp", server+"-tls")), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can't init %s TLS: %w", server, err)
+	}
+
+	go store.Watch(ctx, clock.Real{}, tlsconfig.DefaultWatchInterval)
+
+	return tlsConfig, nil
+}
+
+// gatewayCredentials возвращает транспортные credentials клиента grpc-gateway к GRPC-серверу:
+// TLS с настройками cfg.GatewayTLS, если на GRPC-сервере включён TLS, иначе - без шифрования.
+// Сертификаты перечитываются при изменении, пока не отменён контекст ctx.
+func gatewayCredentials(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg GRPCConfig,
+) (credentials.TransportCredentials, error) {
+	if !cfg.TLS.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, store, err := tlsconfig.NewClient(logger.With(slog.String("comp", "grpc-gw-tls")), cfg.GatewayTLS)
+	if err != nil {
+		return nil, fmt.Errorf("can't init GRPC-gw TLS: %w", err)
+	}
+
+	go store.Watch(ctx, clock.Real{}, tlsconfig.DefaultWatchInterval)
+
+	return credentials.NewTLS(tlsConfig), nil
+}
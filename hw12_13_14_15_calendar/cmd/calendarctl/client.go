@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tlsconfig"
+)
+
+const (
+	// ownerMetadataKey - ключ метаданных с OwnerID, см. interceptor.Auth.
+	ownerMetadataKey = "x-owner-id"
+
+	// tenantMetadataKey - ключ метаданных с TenantID, см. interceptor.Auth.
+	tenantMetadataKey = "x-tenant-id"
+)
+
+// dialCalendar создаёт подключение к GRPC-серверу календаря: по TLS, если он включён в cfg, иначе - без шифрования.
+func dialCalendar(logger *slog.Logger, cfg GRPCConfig) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+
+	if cfg.TLSEnabled {
+		tlsConfig, _, err := tlsconfig.NewClient(logger.With(slog.String("comp", "grpc-tls")), cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("can't init GRPC TLS: %w", err)
+		}
+
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(
+		net.JoinHostPort(cfg.Host, cfg.Port),
+		grpc.WithTransportCredentials(creds),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to calendar: %w", err)
+	}
+
+	return conn, nil
+}
+
+// withOwner добавляет в исходящие метаданные OwnerID, если он задан.
+func withOwner(ctx context.Context, ownerID string) context.Context {
+	if ownerID == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, ownerMetadataKey, ownerID)
+}
+
+// withTenant добавляет в исходящие метаданные TenantID, если он задан.
+func withTenant(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, tenantMetadataKey, tenantID)
+}
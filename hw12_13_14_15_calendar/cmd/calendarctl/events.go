@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+)
+
+const (
+	eventsCommands = "create|update|delete|list|export|import"
+	periods        = "day|week|month"
+)
+
+// inputTimeLayouts - допустимые форматы времени начала и окончания события.
+var inputTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"}
+
+// runEvents выполняет команду управления событиями через GRPC-сервер календаря:
+// events create|update|delete|list|export|import.
+func runEvents(
+	ctx context.Context,
+	logger *slog.Logger,
+	clock clock.Clock,
+	cfg Config,
+	p printer,
+	args []string,
+) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: events %s", eventsCommands)
+	}
+
+	conn, err := dialCalendar(logger, cfg.GRPC)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	cmd := eventsCommand{
+		client:  pbEventV1.NewEventServiceClient(conn),
+		timeout: cfg.GRPC.Timeout,
+		printer: p,
+		clock:   clock,
+	}
+
+	ctx = withOwner(ctx, cfg.OwnerID)
+	ctx = withTenant(ctx, cfg.TenantID)
+
+	switch args[0] {
+	case "create":
+		return cmd.create(ctx, args[1:])
+	case "update":
+		return cmd.update(ctx, args[1:])
+	case "delete":
+		return cmd.delete(ctx, args[1:])
+	case "list":
+		return cmd.list(ctx, args[1:])
+	case "export":
+		return cmd.export(ctx, args[1:])
+	case "import":
+		return cmd.importEvents(ctx, os.Stdin, args[1:])
+	default:
+		return fmt.Errorf("unknown events command '%s', must be one of %s", args[0], eventsCommands)
+	}
+}
+
+// eventsCommand - команды управления событиями.
+type eventsCommand struct {
+	client  pbEventV1.EventServiceClient
+	timeout time.Duration
+	printer printer
+
+	// clock - часы, по которым определяется период по умолчанию.
+	clock clock.Clock
+}
+
+// call выполняет запрос fn к серверу с ограничением времени timeout.
+func (c eventsCommand) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	return fn(ctx)
+}
+
+// create создаёт событие:
+// events create -title ... -start ... -end ... [-id ...] [-description ...] [-notify-before ...]
+// [-labels ...] [-color ...].
+func (c eventsCommand) create(ctx context.Context, args []string) error {
+	event, err := parseEventFlags("events create", args, false)
+	if err != nil {
+		return err
+	}
+
+	var resp *pbEventV1.CreateEventResponse
+	err = c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.CreateEvent(ctx, &pbEventV1.CreateEventRequest{Event: event})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("can't create event: %w", err)
+	}
+
+	return c.printer.events([]*pbEventV1.Event{resp.Event})
+}
+
+// update заменяет событие:
+// events update -id ... -title ... -start ... -end ... [-description ...] [-notify-before ...]
+// [-labels ...] [-color ...].
+// Незаданные необязательные поля события сбрасываются.
+func (c eventsCommand) update(ctx context.Context, args []string) error {
+	event, err := parseEventFlags("events update", args, true)
+	if err != nil {
+		return err
+	}
+
+	var resp *pbEventV1.UpdateEventResponse
+	err = c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.UpdateEvent(ctx, &pbEventV1.UpdateEventRequest{Event: event})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("can't update event: %w", err)
+	}
+
+	return c.printer.events([]*pbEventV1.Event{resp.Event})
+}
+
+// delete удаляет события: events delete ID [ID...].
+func (c eventsCommand) delete(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: events delete ID [ID...]")
+	}
+
+	for _, eventID := range args {
+		err := c.call(ctx, func(ctx context.Context) error {
+			_, err := c.client.DeleteEvent(ctx, &pbEventV1.DeleteEventRequest{EventID: eventID})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("can't delete event %s: %w", eventID, err)
+		}
+	}
+
+	return nil
+}
+
+// list выводит события за период: events list [-labels ...] day|week|month [DATE].
+func (c eventsCommand) list(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("events list", flag.ContinueOnError)
+	labels := fs.String("labels", "", "Comma-separated labels, list events with any of them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	events, err := c.query(ctx, "events list", splitLabels(*labels), fs.Args())
+	if err != nil {
+		return err
+	}
+
+	return c.printer.events(events)
+}
+
+// export выгружает события за период в файл: events export [-file FILE] [-labels ...] day|week|month [DATE].
+// По умолчанию события выводятся в stdout.
+func (c eventsCommand) export(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("events export", flag.ContinueOnError)
+	file := fs.String("file", "", "File to export events to (default stdout)")
+	labels := fs.String("labels", "", "Comma-separated labels, export events with any of them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	events, err := c.query(ctx, "events export", splitLabels(*labels), fs.Args())
+	if err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return writeEvents(c.printer.w, events)
+	}
+
+	f, err := os.Create(*file)
+	if err != nil {
+		return fmt.Errorf("can't create file: %w", err)
+	}
+
+	return errors.Join(writeEvents(f, events), f.Close())
+}
+
+// importEvents создаёт события из файла, выгруженного export: events import [-file FILE] [-new-ids].
+// По умолчанию события читаются из stdin.
+func (c eventsCommand) importEvents(ctx context.Context, stdin io.Reader, args []string) error {
+	fs := flag.NewFlagSet("events import", flag.ContinueOnError)
+	file := fs.String("file", "", "File to import events from (default stdin)")
+	newIDs := fs.Bool("new-ids", false, "Generate new IDs for imported events")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r := stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("can't open file: %w", err)
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	events, err := readEvents(r)
+	if err != nil {
+		return err
+	}
+
+	created := make([]*pbEventV1.Event, 0, len(events))
+	for i, event := range events {
+		if *newIDs || event.EventID == "" {
+			event.EventID = uuid.NewString()
+		}
+
+		err := c.call(ctx, func(ctx context.Context) error {
+			resp, err := c.client.CreateEvent(ctx, &pbEventV1.CreateEventRequest{Event: event})
+			if err != nil {
+				return err
+			}
+
+			created = append(created, resp.Event)
+
+			return nil
+		})
+		if err != nil {
+			// выводим уже созданные события, чтобы было понятно, с какого продолжить
+			return errors.Join(
+				c.printer.events(created),
+				fmt.Errorf("can't import event #%d %s: %w", i+1, event.EventID, err),
+			)
+		}
+	}
+
+	return c.printer.events(created)
+}
+
+// query возвращает события за период из аргументов args: day|week|month [DATE].
+// DATE - YYYY-MM-DD для day и week (первый день недели), YYYY-MM для month. По умолчанию - сегодня по часам clock.
+// Если заданы метки labels, то возвращаются только события с любой из них.
+func (c eventsCommand) query(
+	ctx context.Context,
+	command string,
+	labels []string,
+	args []string,
+) ([]*pbEventV1.Event, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("usage: %s %s [DATE]", command, periods)
+	}
+
+	date := c.clock.Now()
+	if len(args) == 2 {
+		var err error
+		date, err = parseDate(args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var events []*pbEventV1.Event
+
+	err := c.call(ctx, func(ctx context.Context) error {
+		day := &pbEventV1.Date{Year: int32(date.Year()), Month: int32(date.Month()), Day: int32(date.Day())}
+
+		switch args[0] {
+		case "day":
+			resp, err := c.client.GetDayEvents(ctx, &pbEventV1.GetDayEventsRequest{Day: day, Labels: labels})
+			events = resp.GetEvents()
+			return err
+		case "week":
+			req := &pbEventV1.GetWeekEventsRequest{StartDay: day, Labels: labels}
+			resp, err := c.client.GetWeekEvents(ctx, req)
+			events = resp.GetEvents()
+			return err
+		case "month":
+			month := &pbEventV1.Month{Year: day.Year, Month: day.Month}
+			resp, err := c.client.GetMonthEvents(ctx, &pbEventV1.GetMonthEventsRequest{Month: month, Labels: labels})
+			events = resp.GetEvents()
+			return err
+		default:
+			return fmt.Errorf("unknown period '%s', must be one of %s", args[0], periods)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't query events: %w", err)
+	}
+
+	return events, nil
+}
+
+// parseDate разбирает дату date для периода period: YYYY-MM для month, иначе YYYY-MM-DD.
+func parseDate(period string, date string) (time.Time, error) {
+	layout := time.DateOnly
+	if period == "month" {
+		layout = "2006-01"
+	}
+
+	t, err := time.ParseInLocation(layout, date, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s', must be %s", date, layout)
+	}
+
+	return t, nil
+}
+
+// parseTime разбирает время в одном из форматов inputTimeLayouts, без зоны - в местном времени.
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range inputTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s', must be one of %s", value, strings.Join(inputTimeLayouts, ", "))
+}
+
+// parseEventFlags разбирает флаги события команды command.
+// Если requireID, то ID события обязателен, иначе генерируется при отсутствии.
+func parseEventFlags(command string, args []string, requireID bool) (*pbEventV1.Event, error) {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	eventID := fs.String("id", "", "Event ID (UUID)")
+	title := fs.String("title", "", "Event title")
+	description := fs.String("description", "", "Event description")
+	start := fs.String("start", "", "Event start time (RFC3339 or YYYY-MM-DD HH:MM in local time)")
+	end := fs.String("end", "", "Event end time (RFC3339 or YYYY-MM-DD HH:MM in local time)")
+	notifyBefore := fs.Uint("notify-before", 0, "Notify before event, days (0 - do not notify)")
+	labels := fs.String("labels", "", "Comma-separated event labels from owner's labels")
+	color := fs.String("color", "", "Event color, #rrggbb")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if *eventID == "" {
+		if requireID {
+			return nil, errors.New("event id is required")
+		}
+
+		*eventID = uuid.NewString()
+	}
+
+	if *start == "" || *end == "" {
+		return nil, errors.New("event start and end are required")
+	}
+
+	startAt, err := parseTime(*start)
+	if err != nil {
+		return nil, err
+	}
+
+	endAt, err := parseTime(*end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbEventV1.Event{
+		EventID:      *eventID,
+		StartAt:      timestamppb.New(startAt),
+		EndAt:        timestamppb.New(endAt),
+		Title:        *title,
+		Description:  *description,
+		NotifyBefore: uint32(*notifyBefore),
+		Labels:       splitLabels(*labels),
+		Color:        *color,
+	}, nil
+}
+
+// splitLabels разбирает метки, перечисленные через запятую.
+func splitLabels(labels string) []string {
+	var list []string
+	for _, label := range strings.Split(labels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			list = append(list, label)
+		}
+	}
+
+	return list
+}
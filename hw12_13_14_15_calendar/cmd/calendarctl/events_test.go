@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	calendarAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/calendar"
+	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/interceptor"
+	memoryStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
+)
+
+// newTestCommand запускает GRPC-сервер календаря с хранилищем в памяти
+// и возвращает команды событий от имени нового владельца с выводом в out.
+func newTestCommand(t *testing.T, output Output, out io.Writer) eventsCommand {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	auth := interceptor.Auth(logger, nil)
+	server := grpc.NewServer(grpc.UnaryInterceptor(auth.UnaryInterceptor))
+	pbEventV1.RegisterEventServiceServer(
+		server,
+		calendarAPI.NewApp(calendarBusiness.NewApp(logger, clock.Real{}, memoryStorage.NewStorage()), logger),
+	)
+
+	lis := bufconn.Listen(1024 * 1024)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err, "must connect")
+	t.Cleanup(func() { conn.Close() })
+
+	return eventsCommand{
+		client:  pbEventV1.NewEventServiceClient(conn),
+		timeout: time.Second,
+		printer: printer{w: out, output: output},
+		clock:   clock.NewFake(time.Date(2024, time.July, 10, 12, 0, 0, 0, time.Local)),
+	}
+}
+
+func Test_EventsCommand(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := newTestCommand(t, OutputJSON, out)
+	ctx := withOwner(context.Background(), uuid.NewString())
+
+	eventID := uuid.NewString()
+	err := cmd.create(ctx, []string{
+		"-id", eventID,
+		"-title", "meeting",
+		"-start", "2024-07-10 10:00",
+		"-end", "2024-07-10 11:00",
+		"-notify-before", "1",
+	})
+	require.NoError(t, err, "must create event")
+
+	created, err := readEvents(out)
+	require.NoError(t, err, "must output created event as json")
+	require.Len(t, created, 1)
+	require.Equal(t, eventID, created[0].EventID)
+	require.Equal(t, "meeting", created[0].Title)
+
+	err = cmd.update(ctx, []string{
+		"-id", eventID,
+		"-title", "daily meeting",
+		"-start", "2024-07-10 10:00",
+		"-end", "2024-07-10 10:30",
+	})
+	require.NoError(t, err, "must update event")
+	out.Reset()
+
+	// по умолчанию - сегодня по часам команды
+	err = cmd.list(ctx, []string{"day"})
+	require.NoError(t, err, "must list events")
+
+	events, err := readEvents(out)
+	require.NoError(t, err, "must output events as json")
+	require.Len(t, events, 1, "must list today events")
+	require.Equal(t, eventID, events[0].EventID)
+	out.Reset()
+
+	// экспорт в файл и импорт от имени другого владельца
+	file := filepath.Join(t.TempDir(), "events.json")
+	err = cmd.export(ctx, []string{"-file", file, "month", "2024-07"})
+	require.NoError(t, err, "must export events")
+
+	otherCtx := withOwner(context.Background(), uuid.NewString())
+	err = cmd.importEvents(otherCtx, nil, []string{"-file", file, "-new-ids"})
+	require.NoError(t, err, "must import events")
+
+	imported, err := readEvents(out)
+	require.NoError(t, err, "must output imported events as json")
+	require.Len(t, imported, 1)
+	require.NotEqual(t, eventID, imported[0].EventID, "must generate new id")
+	require.Equal(t, "daily meeting", imported[0].Title)
+	out.Reset()
+
+	err = cmd.delete(ctx, []string{eventID})
+	require.NoError(t, err, "must delete event")
+
+	err = cmd.list(ctx, []string{"day", "2024-07-10"})
+	require.NoError(t, err, "must list events")
+
+	events, err = readEvents(out)
+	require.NoError(t, err, "must output events as json")
+	require.Empty(t, events, "event must be deleted")
+
+	err = cmd.delete(ctx, []string{eventID})
+	require.Error(t, err, "must not delete missed event")
+}
+
+func Test_EventsCommand_Tenant(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := newTestCommand(t, OutputJSON, out)
+	ctx := withOwner(context.Background(), uuid.NewString())
+
+	err := cmd.create(withTenant(ctx, "hr"), []string{
+		"-title", "meeting",
+		"-start", "2024-07-10 10:00",
+		"-end", "2024-07-10 11:00",
+	})
+	require.NoError(t, err, "must create event in tenant")
+	out.Reset()
+
+	err = cmd.list(ctx, []string{"day", "2024-07-10"})
+	require.NoError(t, err, "must list events")
+
+	events, err := readEvents(out)
+	require.NoError(t, err, "must output events as json")
+	require.Empty(t, events, "must not list events of other tenant")
+
+	err = cmd.list(withTenant(ctx, "Invalid Tenant"), []string{"day", "2024-07-10"})
+	require.Error(t, err, "must not accept invalid tenant")
+}
+
+func Test_EventsCommand_Labels(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := newTestCommand(t, OutputJSON, out)
+	ctx := withOwner(context.Background(), uuid.NewString())
+
+	require.NoError(t, cmd.createLabel(ctx, []string{"-color", "#ff0000", "work"}), "must create label")
+	require.NoError(t, cmd.createLabel(ctx, []string{"home"}), "must create label")
+	require.Error(t, cmd.createLabel(ctx, []string{"work"}), "must not create duplicate label")
+	require.Error(t, cmd.createLabel(ctx, nil), "must require label name")
+	out.Reset()
+
+	require.NoError(t, cmd.listLabels(ctx), "must list labels")
+	require.JSONEq(t, `[{"name": "home", "color": ""}, {"name": "work", "color": "#ff0000"}]`, out.String())
+	out.Reset()
+
+	for i, labels := range []string{"work, home", "home"} {
+		err := cmd.create(ctx, []string{
+			"-title", "meeting " + labels,
+			"-start", fmt.Sprintf("2024-07-10 1%d:00", i),
+			"-end", fmt.Sprintf("2024-07-10 1%d:30", i),
+			"-labels", labels,
+			"-color", "#00ff00",
+		})
+		require.NoError(t, err, "must create event with labels")
+	}
+
+	err := cmd.create(ctx, []string{"-title", "t", "-start", "2024-07-10", "-end", "2024-07-11", "-labels", "unknown"})
+	require.Error(t, err, "must not create event with unknown label")
+	out.Reset()
+
+	require.NoError(t, cmd.list(ctx, []string{"-labels", "work", "day", "2024-07-10"}), "must list events")
+
+	events, err := readEvents(out)
+	require.NoError(t, err, "must output events as json")
+	require.Len(t, events, 1, "must filter events by label")
+	require.Equal(t, []string{"home", "work"}, events[0].Labels)
+	require.Equal(t, "#00ff00", events[0].Color)
+	out.Reset()
+
+	require.NoError(t, cmd.deleteLabels(ctx, []string{"home"}), "must delete label")
+	require.Error(t, cmd.deleteLabels(ctx, []string{"home"}), "must not delete missed label")
+
+	require.NoError(t, cmd.list(ctx, []string{"day", "2024-07-10"}), "must list events")
+
+	events, err = readEvents(out)
+	require.NoError(t, err, "must output events as json")
+	require.Len(t, events, 2)
+	require.Equal(t, []string{"work"}, events[0].Labels, "must remove deleted label from event")
+	require.Empty(t, events[1].Labels, "must remove deleted label from event")
+}
+
+func Test_EventsCommand_Table(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := newTestCommand(t, OutputTable, out)
+	ctx := withOwner(context.Background(), uuid.NewString())
+
+	err := cmd.importEvents(ctx, strings.NewReader(`[
+		{"title": "first", "startAt": "2024-07-10T10:00:00Z", "endAt": "2024-07-10T11:00:00Z"},
+		{"title": "second", "startAt": "2024-07-11T10:00:00Z", "endAt": "2024-07-11T11:00:00Z", "notifyBefore": 2}
+	]`), nil)
+	require.NoError(t, err, "must import events from stdin")
+	out.Reset()
+
+	err = cmd.list(ctx, []string{"week", "2024-07-08"})
+	require.NoError(t, err, "must list events")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3, "must have header and two events")
+	require.Contains(t, lines[0], "TITLE")
+	require.Contains(t, lines[1], "first")
+	require.Contains(t, lines[2], "second")
+
+	err = cmd.importEvents(ctx, strings.NewReader(`[{"title": ""}]`), nil)
+	require.Error(t, err, "must not import invalid event")
+
+	err = cmd.list(context.Background(), []string{"day"})
+	require.Error(t, err, "must require owner")
+}
+
+func Test_ParseArgs(t *testing.T) {
+	date, err := parseDate("month", "2024-07")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.July, 1, 0, 0, 0, 0, time.Local), date)
+
+	_, err = parseDate("day", "2024-07")
+	require.Error(t, err, "day must be YYYY-MM-DD")
+
+	tm, err := parseTime("2024-07-10T10:00:00+03:00")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.July, 10, 7, 0, 0, 0, time.UTC), tm.UTC())
+
+	_, err = parseTime("10:00")
+	require.Error(t, err)
+
+	_, err = parseEventFlags("events update", []string{"-start", "2024-07-10", "-end", "2024-07-11"}, true)
+	require.Error(t, err, "must require id")
+
+	event, err := parseEventFlags("events create", []string{"-start", "2024-07-10", "-end", "2024-07-11"}, false)
+	require.NoError(t, err)
+	require.NoError(t, uuid.Validate(event.EventID), "must generate id")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+)
+
+const labelsCommands = "create|update|delete|list"
+
+// runLabels выполняет команду управления каталогом меток владельца через GRPC-сервер календаря:
+// labels create|update|delete|list.
+func runLabels(ctx context.Context, logger *slog.Logger, cfg Config, p printer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: labels %s", labelsCommands)
+	}
+
+	conn, err := dialCalendar(logger, cfg.GRPC)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	cmd := eventsCommand{
+		client:  pbEventV1.NewEventServiceClient(conn),
+		timeout: cfg.GRPC.Timeout,
+		printer: p,
+	}
+
+	ctx = withOwner(ctx, cfg.OwnerID)
+	ctx = withTenant(ctx, cfg.TenantID)
+
+	switch args[0] {
+	case "create":
+		return cmd.createLabel(ctx, args[1:])
+	case "update":
+		return cmd.updateLabel(ctx, args[1:])
+	case "delete":
+		return cmd.deleteLabels(ctx, args[1:])
+	case "list":
+		return cmd.listLabels(ctx)
+	default:
+		return fmt.Errorf("unknown labels command '%s', must be one of %s", args[0], labelsCommands)
+	}
+}
+
+// createLabel добавляет метку в каталог: labels create [-color ...] NAME.
+func (c eventsCommand) createLabel(ctx context.Context, args []string) error {
+	label, err := parseLabelFlags("labels create", args)
+	if err != nil {
+		return err
+	}
+
+	var resp *pbEventV1.CreateLabelResponse
+	err = c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.CreateLabel(ctx, &pbEventV1.CreateLabelRequest{Label: label})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("can't create label: %w", err)
+	}
+
+	return c.printer.labels([]*pbEventV1.Label{resp.Label})
+}
+
+// updateLabel заменяет цвет метки: labels update [-color ...] NAME.
+// Если цвет не задан, он сбрасывается.
+func (c eventsCommand) updateLabel(ctx context.Context, args []string) error {
+	label, err := parseLabelFlags("labels update", args)
+	if err != nil {
+		return err
+	}
+
+	var resp *pbEventV1.UpdateLabelResponse
+	err = c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.UpdateLabel(ctx, &pbEventV1.UpdateLabelRequest{Label: label})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("can't update label: %w", err)
+	}
+
+	return c.printer.labels([]*pbEventV1.Label{resp.Label})
+}
+
+// deleteLabels удаляет метки из каталога и снимает их с событий: labels delete NAME [NAME...].
+func (c eventsCommand) deleteLabels(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: labels delete NAME [NAME...]")
+	}
+
+	for _, name := range args {
+		err := c.call(ctx, func(ctx context.Context) error {
+			_, err := c.client.DeleteLabel(ctx, &pbEventV1.DeleteLabelRequest{Name: name})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("can't delete label %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// listLabels выводит каталог меток: labels list.
+func (c eventsCommand) listLabels(ctx context.Context) error {
+	var resp *pbEventV1.ListLabelsResponse
+	err := c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.ListLabels(ctx, &pbEventV1.ListLabelsRequest{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("can't list labels: %w", err)
+	}
+
+	return c.printer.labels(resp.Labels)
+}
+
+// parseLabelFlags разбирает флаги и имя метки команды command.
+func parseLabelFlags(command string, args []string) (*pbEventV1.Label, error) {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	color := fs.String("color", "", "Label color, #rrggbb")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("usage: %s [-color #rrggbb] NAME", command)
+	}
+
+	return &pbEventV1.Label{Name: fs.Arg(0), Color: *color}, nil
+}
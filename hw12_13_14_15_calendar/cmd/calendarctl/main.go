@@ -0,0 +1,130 @@
+// calendarctl - утилита администрирования сервиса календаря:
+// управление событиями и метками через GRPC, импорт и экспорт событий, очистка хранилища,
+// просмотр очереди уведомлений и администрирование рабочих пространств
+// (статистика, выгрузка и удаление всех событий пространства).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ilyakaznacheev/cleanenv"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+)
+
+const serviceName = "calendarctl"
+
+func main() {
+	initFlag()
+
+	logger, levelVar, formatVar := logger.New(os.Stderr, slog.LevelWarn, serviceName)
+
+	ctx := context.Background()
+	if err := run(ctx, logger, levelVar, formatVar); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", serviceName, err)
+		os.Exit(1)
+	}
+}
+
+var (
+	configFile string
+	output     string
+	ownerID    string
+	tenantID   string
+)
+
+func initFlag() {
+	flag.StringVar(&configFile, "config", "", "Path to configuration file")
+	flag.StringVar(&output, "output", "", "Output format: table or json (overrides config)")
+	flag.StringVar(&ownerID, "owner", "", "Owner ID to run requests as (overrides config)")
+	flag.StringVar(&tenantID, "tenant", "", "Tenant ID to run requests in (overrides config)")
+
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+
+		fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(out, "  %s [flags] events %s [args]\tmanage events\n", os.Args[0], eventsCommands)
+		fmt.Fprintf(out, "  %s [flags] labels %s [args]\t\tmanage labels\n", os.Args[0], labelsCommands)
+		fmt.Fprintf(out, "  %s [flags] purge [-older-than duration]\t\tpurge old events in storage\n", os.Args[0])
+		fmt.Fprintf(out, "  %s [flags] queue %s [args]\t\t\tinspect notification queue\n", os.Args[0], queueCommands)
+		fmt.Fprintf(out, "  %s [flags] tenant %s [args]\t\tadminister tenant via admin API\n", os.Args[0], tenantCommands)
+		fmt.Fprintf(out, "  %s version\t\t\t\t\tprint version\n", os.Args[0])
+		fmt.Fprintf(out, "\nFlags:\n")
+		flag.PrintDefaults()
+
+		var cfg Config
+		help, _ := cleanenv.GetDescription(&cfg, nil)
+		fmt.Fprintf(out, "\n%s\n", help)
+	}
+
+	flag.Parse()
+}
+
+func run(
+	ctx context.Context,
+	logger *slog.Logger,
+	levelVar *slog.LevelVar,
+	formatVar *logger.FormatVar,
+) error {
+	// прерывание команды по Ctrl+C
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		return errors.New("command is required")
+	}
+
+	if args[0] == "version" {
+		fmt.Printf("release: %s\ndate: %s\ngitHash: %s\n", release, buildDate, gitHash)
+		return nil
+	}
+
+	cfg, err := ReadConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	levelVar.Set(cfg.Log.Level)
+	formatVar.Set(cfg.Log.Format)
+
+	if output != "" {
+		if err := cfg.Output.UnmarshalText([]byte(output)); err != nil {
+			return err
+		}
+	}
+
+	if ownerID != "" {
+		cfg.OwnerID = ownerID
+	}
+
+	if tenantID != "" {
+		cfg.TenantID = tenantID
+	}
+
+	p := printer{w: os.Stdout, output: cfg.Output}
+
+	switch args[0] {
+	case "events":
+		return runEvents(ctx, logger, clock.Real{}, cfg, p, args[1:])
+	case "labels":
+		return runLabels(ctx, logger, cfg, p, args[1:])
+	case "purge":
+		return runPurge(ctx, logger, clock.Real{}, cfg, p, args[1:])
+	case "queue":
+		return runQueue(logger, cfg, p, args[1:])
+	case "tenant":
+		return runTenant(ctx, logger, cfg, p, args[1:])
+	default:
+		return fmt.Errorf("unknown command '%s'", args[0])
+	}
+}
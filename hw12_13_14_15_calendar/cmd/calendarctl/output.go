@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	queue "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/queue/notify/rabbit"
+)
+
+// timeLayout - формат времени в табличном выводе.
+const timeLayout = "2006-01-02 15:04"
+
+// printer выводит результаты команд в w в формате output.
+type printer struct {
+	w      io.Writer
+	output Output
+}
+
+// events выводит список событий.
+// В формате json события выводятся так же, как в файле экспорта (см. writeEvents).
+func (p printer) events(events []*pbEventV1.Event) error {
+	if p.output == OutputJSON {
+		return writeEvents(p.w, events)
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ID\tSTART\tEND\tTITLE\tLABELS\tNOTIFY BEFORE (DAYS)")
+	for _, e := range events {
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\t%d\n",
+			e.EventID,
+			e.StartAt.AsTime().Local().Format(timeLayout),
+			e.EndAt.AsTime().Local().Format(timeLayout),
+			e.Title,
+			strings.Join(e.Labels, ","),
+			e.NotifyBefore,
+		)
+	}
+
+	return w.Flush()
+}
+
+// labels выводит каталог меток владельца.
+func (p printer) labels(labels []*pbEventV1.Label) error {
+	if p.output == OutputJSON {
+		opts := protojson.MarshalOptions{EmitUnpopulated: true}
+
+		list := make([]json.RawMessage, len(labels))
+		for i, l := range labels {
+			data, err := opts.Marshal(l)
+			if err != nil {
+				return fmt.Errorf("can't marshal label %s: %w", l.Name, err)
+			}
+
+			list[i] = data
+		}
+
+		return p.json(list)
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "NAME\tCOLOR")
+	for _, l := range labels {
+		fmt.Fprintf(w, "%s\t%s\n", l.Name, l.Color)
+	}
+
+	return w.Flush()
+}
+
+// purged выводит результат очистки хранилища от событий старше olderThan.
+func (p printer) purged(olderThan time.Time) error {
+	if p.output == OutputJSON {
+		return p.json(struct {
+			PurgedBefore time.Time `json:"purgedBefore"`
+		}{olderThan})
+	}
+
+	_, err := fmt.Fprintf(p.w, "purged events older than %s\n", olderThan.Local().Format(timeLayout))
+
+	return err
+}
+
+// queueStats выводит состояние очереди уведомлений.
+func (p printer) queueStats(stats queue.Stats) error {
+	if p.output == OutputJSON {
+		return p.json(stats)
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "QUEUE\tMESSAGES\tCONSUMERS")
+	fmt.Fprintf(w, "%s\t%d\t%d\n", stats.Queue, stats.Messages, stats.Consumers)
+
+	return w.Flush()
+}
+
+// notifications выводит уведомления из очереди.
+func (p printer) notifications(notifications []queue.Notification) error {
+	if p.output == OutputJSON {
+		if notifications == nil {
+			notifications = []queue.Notification{}
+		}
+
+		return p.json(notifications)
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TENANT\tEVENT ID\tOWNER ID\tSTART\tTITLE")
+	for _, n := range notifications {
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\n",
+			n.TenantID,
+			n.EventID,
+			n.OwnerID,
+			n.Date.Local().Format(timeLayout),
+			n.Title,
+		)
+	}
+
+	return w.Flush()
+}
+
+// tenantStats выводит количество событий count рабочего пространства tenantID.
+func (p printer) tenantStats(tenantID model.TenantID, count int) error {
+	if p.output == OutputJSON {
+		return p.json(struct {
+			TenantID model.TenantID `json:"tenantId"`
+			Events   int            `json:"events"`
+		}{tenantID, count})
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TENANT\tEVENTS")
+	fmt.Fprintf(w, "%s\t%d\n", tenantID, count)
+
+	return w.Flush()
+}
+
+// tenantWiped выводит результат удаления событий рабочего пространства tenantID.
+func (p printer) tenantWiped(tenantID model.TenantID, deleted int) error {
+	if p.output == OutputJSON {
+		return p.json(struct {
+			TenantID model.TenantID `json:"tenantId"`
+			Deleted  int            `json:"deleted"`
+		}{tenantID, deleted})
+	}
+
+	_, err := fmt.Fprintf(p.w, "deleted %d events of tenant %s\n", deleted, tenantID)
+
+	return err
+}
+
+func (p printer) json(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// writeEvents записывает события в w как JSON-массив.
+// Событие представлено так же, как в HTTP API календаря.
+func writeEvents(w io.Writer, events []*pbEventV1.Event) error {
+	opts := protojson.MarshalOptions{EmitUnpopulated: true}
+
+	list := make([]json.RawMessage, len(events))
+	for i, e := range events {
+		data, err := opts.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("can't marshal event %s: %w", e.EventID, err)
+		}
+
+		list[i] = data
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+
+	return err
+}
+
+// tenantEvent - событие рабочего пространства в файле выгрузки tenant export.
+type tenantEvent struct {
+	OwnerID string          `json:"ownerId"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// writeTenantEvents записывает события рабочего пространства в w как JSON-массив.
+// Событие представлено так же, как в HTTP API календаря, вместе с владельцем.
+func writeTenantEvents(w io.Writer, events []*pbEventV1.ExportTenantEventsResponse) error {
+	opts := protojson.MarshalOptions{EmitUnpopulated: true}
+
+	list := make([]tenantEvent, len(events))
+	for i, e := range events {
+		data, err := opts.Marshal(e.Event)
+		if err != nil {
+			return fmt.Errorf("can't marshal event %s: %w", e.Event.GetEventID(), err)
+		}
+
+		list[i] = tenantEvent{OwnerID: e.OwnerID, Event: data}
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+
+	return err
+}
+
+// readEvents читает события из r, записанные writeEvents.
+func readEvents(r io.Reader) ([]*pbEventV1.Event, error) {
+	var list []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("can't decode events: %w", err)
+	}
+
+	events := make([]*pbEventV1.Event, len(list))
+	for i, data := range list {
+		events[i] = &pbEventV1.Event{}
+		if err := protojson.Unmarshal(data, events[i]); err != nil {
+			return nil, fmt.Errorf("can't decode event #%d: %w", i+1, err)
+		}
+	}
+
+	return events, nil
+}
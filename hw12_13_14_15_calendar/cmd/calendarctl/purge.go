@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+	localBlob "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob/local"
+	eventStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+	fileStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/file"
+	pgStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/pg"
+	sqliteStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/sqlite"
+)
+
+// runPurge удаляет из хранилища события старше -older-than (по умолчанию - PurgeOlderThan из конфигурации)
+// вместе с файлами их вложений, не дожидаясь планировщика: purge [-older-than duration].
+// Возраст событий отсчитывается от текущего времени часов clock.
+func runPurge(
+	ctx context.Context,
+	logger *slog.Logger,
+	clock clock.Clock,
+	cfg Config,
+	p printer,
+	args []string,
+) error {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	olderThan := fs.Duration("older-than", cfg.PurgeOlderThan, "Purge events older than duration")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// иначе будут удалены завершившиеся или даже будущие события
+	if *olderThan <= 0 {
+		return fmt.Errorf("usage: purge [-older-than duration]: duration must be positive, got %s", *olderThan)
+	}
+
+	storage, closeFn, err := initStorage(logger, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	blobs, err := initBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	before := clock.Now().Add(-*olderThan)
+	events, err := storage.PurgeOldEvents(ctx, before)
+	if err != nil {
+		return fmt.Errorf("can't purge events: %w", err)
+	}
+
+	if blobs != nil {
+		if err := blob.DeleteEvents(ctx, blobs, events); err != nil {
+			return fmt.Errorf("can't delete attachments of purged events: %w", err)
+		}
+	}
+
+	return p.purged(before)
+}
+
+// initBlobStore открывает хранилище файлов вложений из конфигурации, nil - если оно не задано.
+func initBlobStore(cfg Config) (blob.Store, error) {
+	if cfg.Attachments.Dir == "" {
+		return nil, nil
+	}
+
+	store, err := localBlob.NewStore(cfg.Attachments.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't init attachments store: %w", err)
+	}
+
+	return store, nil
+}
+
+// initStorage подключается к хранилищу из конфигурации.
+// Хранилище memory принадлежит процессу календаря, поэтому не поддерживается.
+func initStorage(logger *slog.Logger, cfg Config) (eventStorage.Storage, func() error, error) {
+	switch cfg.EventStorageType {
+	case config.EventStorageTypePg:
+		storage, err := pgStorage.NewStorage(pgStorageOptions(cfg.EventStoragePg))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return storage, storage.Close, nil
+	case config.EventStorageTypeFile:
+		// хранилище заблокировано, пока запущен сервис календаря
+		storage, err := fileStorage.NewStorage(fileStorage.Options{
+			Dir:               cfg.EventStorageFile.Dir,
+			Sync:              fileStorage.SyncPolicy(cfg.EventStorageFile.Sync),
+			SyncInterval:      cfg.EventStorageFile.SyncInterval,
+			SnapshotInterval:  cfg.EventStorageFile.SnapshotInterval,
+			SnapshotThreshold: cfg.EventStorageFile.SnapshotThreshold,
+			Logger:            logger.With(slog.String("comp", "storage-file")),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return storage, storage.Close, nil
+	case config.EventStorageTypeSqlite:
+		storage, err := sqliteStorage.NewStorage(cfg.EventStorageSqlite.DataSource)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return storage, storage.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("storage '%s' is not supported by calendarctl", cfg.EventStorageType)
+	}
+}
+
+// pgStorageOptions возвращает параметры подключения к postgres из конфигурации.
+func pgStorageOptions(cfg config.EventStoragePg) pgStorage.Options {
+	return pgStorage.Options{
+		DataSource:       cfg.DataSource,
+		Replicas:         cfg.Replicas,
+		MaxOpenConns:     cfg.MaxOpenConns,
+		MaxIdleConns:     cfg.MaxIdleConns,
+		ConnMaxLifetime:  cfg.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.ConnMaxIdleTime,
+		StatementTimeout: cfg.StatementTimeout,
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	eventStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+func Test_PurgeCommand(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	now := time.Date(2030, time.January, 2, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+
+	cfg := Config{
+		Output:           OutputJSON,
+		PurgeOlderThan:   24 * time.Hour,
+		EventStorageType: config.EventStorageTypeFile,
+		EventStorageFile: config.EventStorageFile{
+			Dir:  t.TempDir(),
+			Sync: config.FileSyncAlways,
+		},
+	}
+
+	t.Run("invalid older-than", func(t *testing.T) {
+		for _, args := range [][]string{{"-older-than", "0s"}, {"-older-than", "-1h"}} {
+			err := runPurge(ctx, logger, clk, cfg, printer{w: io.Discard, output: OutputJSON}, args)
+			require.ErrorContains(t, err, "duration must be positive", "must reject %v", args)
+		}
+
+		entries, err := os.ReadDir(cfg.EventStorageFile.Dir)
+		require.NoError(t, err)
+		require.Empty(t, entries, "must not open storage")
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		storage, closeFn, err := initStorage(logger, cfg)
+		require.NoError(t, err, "must open storage")
+
+		ownerID := model.NewOwnerID()
+
+		ended, err := model.NewEvent(
+			model.DefaultTenantID, model.NewID(), ownerID, "ended", now.Add(-50*time.Hour), now.Add(-49*time.Hour),
+		)
+		require.NoError(t, err)
+		require.NoError(t, storage.AddEvent(ctx, ended))
+
+		recent, err := model.NewEvent(
+			model.DefaultTenantID, model.NewID(), ownerID, "recent", now.Add(-2*time.Hour), now.Add(-time.Hour),
+		)
+		require.NoError(t, err)
+		require.NoError(t, storage.AddEvent(ctx, recent))
+		require.NoError(t, closeFn())
+
+		require.NoError(t, runPurge(ctx, logger, clk, cfg, printer{w: io.Discard, output: OutputJSON}, nil), "must purge")
+
+		storage, closeFn, err = initStorage(logger, cfg)
+		require.NoError(t, err, "must open storage")
+		defer closeFn()
+
+		_, err = storage.FindEvent(ctx, model.DefaultTenantID, ownerID, ended.EventID())
+		require.ErrorIs(t, err, eventStorage.ErrEventNotFound, "must purge ended event")
+
+		_, err = storage.FindEvent(ctx, model.DefaultTenantID, ownerID, recent.EventID())
+		require.NoError(t, err, "must keep recent event")
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+
+	queue "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/queue/notify/rabbit"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tlsconfig"
+)
+
+const queueCommands = "stats|peek"
+
+// runQueue выполняет команду просмотра очереди уведомлений: queue stats|peek [-limit N].
+func runQueue(logger *slog.Logger, cfg Config, p printer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: queue %s", queueCommands)
+	}
+
+	q := queue.NewNotifyQueue(logger.With(slog.String("comp", "queue")), cfg.AMQPConnect)
+	if cfg.AMQPTLS.Configured() {
+		tlsConfig, _, err := tlsconfig.NewClient(logger.With(slog.String("comp", "amqp-tls")), cfg.AMQPTLS)
+		if err != nil {
+			return fmt.Errorf("can't init AMQP TLS: %w", err)
+		}
+
+		q.TLSConfig = tlsConfig
+	}
+
+	if err := q.Init(); err != nil {
+		return err
+	}
+	defer q.Done()
+
+	switch args[0] {
+	case "stats":
+		stats, err := q.Stats()
+		if err != nil {
+			return err
+		}
+
+		return p.queueStats(stats)
+	case "peek":
+		fs := flag.NewFlagSet("queue peek", flag.ContinueOnError)
+		limit := fs.Int("limit", 10, "Max number of notifications to show")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		notifications, err := q.Peek(*limit)
+		if err != nil {
+			return err
+		}
+
+		return p.notifications(notifications)
+	default:
+		return fmt.Errorf("unknown queue command '%s', must be one of %s", args[0], queueCommands)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+const tenantCommands = "stats|export|wipe"
+
+// runTenant выполняет команду администрирования рабочего пространства -tenant
+// через административный GRPC-сервис календаря: tenant stats|export|wipe.
+// Сервер допускает к сервису только клиентов с сертификатом из grpc.admin_services, поэтому нужен mTLS.
+func runTenant(ctx context.Context, logger *slog.Logger, cfg Config, p printer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: tenant %s", tenantCommands)
+	}
+
+	// пустое пространство на сервере означает model.DefaultTenantID,
+	// но для административных операций пространство должно быть указано явно
+	if cfg.TenantID == "" {
+		return errors.New("tenant is required for tenant commands")
+	}
+
+	tenantID, err := model.NewTenantIDFromString(cfg.TenantID)
+	if err != nil {
+		return err
+	}
+
+	conn, err := dialCalendar(logger, cfg.GRPC)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	cmd := tenantCommand{
+		client:   pbEventV1.NewTenantAdminServiceClient(conn),
+		timeout:  cfg.GRPC.Timeout,
+		tenantID: tenantID,
+		printer:  p,
+	}
+
+	switch args[0] {
+	case "stats":
+		return cmd.stats(ctx)
+	case "export":
+		return cmd.export(ctx, args[1:])
+	case "wipe":
+		return cmd.wipe(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown tenant command '%s', must be one of %s", args[0], tenantCommands)
+	}
+}
+
+// tenantCommand - команды администрирования рабочего пространства.
+type tenantCommand struct {
+	client   pbEventV1.TenantAdminServiceClient
+	timeout  time.Duration
+	tenantID model.TenantID
+	printer  printer
+}
+
+// call выполняет запрос fn к серверу с ограничением времени timeout.
+func (c tenantCommand) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	return fn(ctx)
+}
+
+// stats выводит количество событий пространства: tenant stats.
+func (c tenantCommand) stats(ctx context.Context) error {
+	var resp *pbEventV1.GetTenantStatsResponse
+	err := c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.GetTenantStats(ctx, &pbEventV1.GetTenantStatsRequest{TenantID: string(c.tenantID)})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("can't count tenant events: %w", err)
+	}
+
+	return c.printer.tenantStats(c.tenantID, int(resp.Events))
+}
+
+// export выгружает все события пространства вместе с владельцами: tenant export [-file FILE].
+// По умолчанию события выводятся в stdout.
+func (c tenantCommand) export(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tenant export", flag.ContinueOnError)
+	file := fs.String("file", "", "File to export events to (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var events []*pbEventV1.ExportTenantEventsResponse
+	err := c.call(ctx, func(ctx context.Context) error {
+		stream, err := c.client.ExportTenantEvents(ctx, &pbEventV1.ExportTenantEventsRequest{TenantID: string(c.tenantID)})
+		if err != nil {
+			return err
+		}
+
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			events = append(events, resp)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("can't export tenant events: %w", err)
+	}
+
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return fmt.Errorf("can't create file: %w", err)
+		}
+
+		return errors.Join(writeTenantEvents(f, events), f.Close())
+	}
+
+	return writeTenantEvents(c.printer.w, events)
+}
+
+// wipe удаляет все события пространства и файлы их вложений: tenant wipe -yes.
+func (c tenantCommand) wipe(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tenant wipe", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "Confirm deletion of all tenant events")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !*yes {
+		return fmt.Errorf("tenant wipe deletes all events of tenant '%s', confirm with -yes", c.tenantID)
+	}
+
+	var resp *pbEventV1.WipeTenantResponse
+	err := c.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.WipeTenant(ctx, &pbEventV1.WipeTenantRequest{TenantID: string(c.tenantID)})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("can't wipe tenant: %w", err)
+	}
+
+	return c.printer.tenantWiped(c.tenantID, int(resp.Deleted))
+}
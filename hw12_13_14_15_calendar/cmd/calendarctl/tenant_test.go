@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/test/bufconn"
+
+	calendarAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/calendar"
+	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/interceptor"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+	localBlob "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob/local"
+	memoryStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
+)
+
+// withPeerCertificate подставляет в контекст запроса проверенный клиентский сертификат с CN commonName,
+// как это делает TLS-сервер при mTLS.
+func withPeerCertificate(commonName string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(certContext(ctx, commonName), req)
+	}
+}
+
+// withPeerCertificateStream - потоковый вариант withPeerCertificate.
+func withPeerCertificateStream(commonName string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &certServerStream{ServerStream: stream, ctx: certContext(stream.Context(), commonName)})
+	}
+}
+
+type certServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *certServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func certContext(ctx context.Context, commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+// newTestTenantCommand запускает административный GRPC-сервер календаря с хранилищем storage и файлами blobs,
+// к которому клиент подключается с сертификатом commonName, и возвращает команды пространства tenantID.
+func newTestTenantCommand(
+	t *testing.T,
+	storage *memoryStorage.Storage,
+	blobs blob.Store,
+	commonName string,
+	tenantID model.TenantID,
+	out io.Writer,
+) tenantCommand {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	business := calendarBusiness.NewApp(logger, clock.Real{}, storage)
+	business.SetAttachments(blobs, calendarBusiness.DefaultAttachmentLimits)
+
+	auth := interceptor.Auth(logger, []string{"calendarctl"})
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(withPeerCertificate(commonName), auth.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(withPeerCertificateStream(commonName), auth.StreamInterceptor),
+	)
+	pbEventV1.RegisterTenantAdminServiceServer(server, calendarAPI.NewAdminApp(business, logger))
+
+	lis := bufconn.Listen(1024 * 1024)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err, "must connect")
+	t.Cleanup(func() { conn.Close() })
+
+	return tenantCommand{
+		client:   pbEventV1.NewTenantAdminServiceClient(conn),
+		timeout:  time.Second,
+		tenantID: tenantID,
+		printer:  printer{w: out, output: OutputJSON},
+	}
+}
+
+func Test_TenantCommand(t *testing.T) {
+	ctx := context.Background()
+
+	// наполняем хранилище событиями двух пространств
+	storage := memoryStorage.NewStorage()
+
+	ownerID := model.NewOwnerID()
+	startAt := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, tenantID := range []model.TenantID{"hr", "hr", "sales"} {
+		event, err := model.NewEvent(tenantID, model.NewID(), ownerID, "meeting", startAt, startAt.Add(time.Hour))
+		require.NoError(t, err, "must create event")
+		require.NoError(t, storage.AddEvent(ctx, event), "must add event")
+
+		startAt = startAt.Add(time.Hour)
+	}
+
+	// файлы вложений двух пространств
+	blobs, err := localBlob.NewStore(t.TempDir())
+	require.NoError(t, err, "must open attachments store")
+
+	keys := map[model.TenantID]string{}
+	for _, tenantID := range []model.TenantID{"hr", "sales"} {
+		keys[tenantID] = blob.AttachmentKey(tenantID, ownerID, model.NewID(), model.NewAttachmentID())
+		_, err := blobs.Put(ctx, keys[tenantID], strings.NewReader("agenda"), 1024)
+		require.NoError(t, err, "must put file")
+	}
+
+	out := &bytes.Buffer{}
+	cmd := newTestTenantCommand(t, storage, blobs, "calendarctl", "hr", out)
+
+	run := func(cmd tenantCommand, fn func(tenantCommand) error) []byte {
+		t.Helper()
+
+		out.Reset()
+		require.NoError(t, fn(cmd), "must run command")
+
+		return out.Bytes()
+	}
+
+	stats := func(c tenantCommand) error { return c.stats(ctx) }
+
+	var statsOut struct {
+		Events int `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(run(cmd, stats), &statsOut), "must output stats as json")
+	require.Equal(t, 2, statsOut.Events, "must count events of tenant")
+
+	var exported []tenantEvent
+	export := func(c tenantCommand) error { return c.export(ctx, nil) }
+	require.NoError(t, json.Unmarshal(run(cmd, export), &exported), "must output events as json")
+	require.Len(t, exported, 2, "must export events of tenant")
+	require.Equal(t, string(ownerID), exported[0].OwnerID, "must export owner")
+
+	require.Error(t, cmd.wipe(ctx, nil), "wipe must require confirmation")
+
+	var wiped struct {
+		Deleted int `json:"deleted"`
+	}
+	wipe := func(c tenantCommand) error { return c.wipe(ctx, []string{"-yes"}) }
+	require.NoError(t, json.Unmarshal(run(cmd, wipe), &wiped), "must output result as json")
+	require.Equal(t, 2, wiped.Deleted, "must delete events of tenant")
+
+	_, err = blobs.Open(ctx, keys["hr"])
+	require.ErrorIs(t, err, blob.ErrBlobNotFound, "must delete files of tenant")
+
+	r, err := blobs.Open(ctx, keys["sales"])
+	require.NoError(t, err, "must keep files of other tenant")
+	r.Close()
+
+	require.NoError(t, json.Unmarshal(run(cmd, stats), &statsOut), "must output stats as json")
+	require.Zero(t, statsOut.Events, "tenant must be empty")
+
+	cmd.tenantID = "sales"
+	require.NoError(t, json.Unmarshal(run(cmd, stats), &statsOut), "must output stats as json")
+	require.Equal(t, 1, statsOut.Events, "must keep events of other tenant")
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	err = runTenant(ctx, logger, Config{}, printer{w: io.Discard}, []string{"stats"})
+	require.Error(t, err, "tenant must be required")
+}
+
+func Test_TenantCommand_NotAdmin(t *testing.T) {
+	storage := memoryStorage.NewStorage()
+	cmd := newTestTenantCommand(t, storage, nil, "gateway", "hr", io.Discard)
+
+	require.Error(t, cmd.stats(context.Background()), "must deny service not listed as admin")
+	require.Error(t, cmd.wipe(context.Background(), []string{"-yes"}), "must deny service not listed as admin")
+}
@@ -12,11 +12,21 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 
 	schedulerBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/scheduler"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/health"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/metrics"
 	queue "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/queue/notify/rabbit"
+	localBlob "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob/local"
+	eventStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+	fileStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/file"
+	instrumentedStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/instrumented"
 	memoryStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
 	pgStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/pg"
+	sqliteStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/sqlite"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tlsconfig"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
 )
 
 const serviceName = "scheduler"
@@ -24,10 +34,10 @@ const serviceName = "scheduler"
 func main() {
 	initFlag()
 
-	logger, levelVar := logger.New(os.Stdout, slog.LevelInfo, serviceName)
+	logger, levelVar, formatVar := logger.New(os.Stdout, slog.LevelInfo, serviceName)
 
 	ctx := context.Background()
-	if err := run(ctx, logger, levelVar); err != nil {
+	if err := run(ctx, logger, levelVar, formatVar); err != nil {
 		logger.Error(
 			"failed to run",
 			slog.String("error", err.Error()),
@@ -52,7 +62,12 @@ func initFlag() {
 	flag.Parse()
 }
 
-func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) error {
+func run(
+	ctx context.Context,
+	logger *slog.Logger,
+	levelVar *slog.LevelVar,
+	formatVar *logger.FormatVar,
+) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -75,6 +90,10 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 		return err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
 	logger.Info(
 		"set logger level",
 		slog.String("from", levelVar.Level().String()),
@@ -82,31 +101,110 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 	)
 	levelVar.Set(cfg.Log.Level)
 
+	logger.Info(
+		"set logger format",
+		slog.String("from", string(formatVar.Format())),
+		slog.String("to", string(cfg.Log.Format)),
+	)
+	formatVar.Set(cfg.Log.Format)
+
+	logger.Info(
+		"init tracing",
+		slog.String("exporter", string(cfg.Tracing.Exporter)),
+	)
+	shutdownTracing, err := tracing.Init(cfg.Tracing, serviceName)
+	if err != nil {
+		return fmt.Errorf("can't init tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	logger.Info("init metrics")
+	registry := metrics.NewRegistry()
+
+	storageMetrics, err := metrics.NewStorage(registry)
+	if err != nil {
+		return fmt.Errorf("can't init metrics: %w", err)
+	}
+
+	queueMetrics, err := metrics.NewQueue(registry)
+	if err != nil {
+		return fmt.Errorf("can't init metrics: %w", err)
+	}
+
+	schedulerMetrics, err := metrics.NewScheduler(registry)
+	if err != nil {
+		return fmt.Errorf("can't init metrics: %w", err)
+	}
+
+	checker := health.NewChecker(health.DefaultTimeout)
+
+	stopMetricsServer := startMetricsServer(logger, cfg.Metrics, registry, checker)
+	defer stopMetricsServer()
+
 	logger.Info(
 		"init storage",
 		slog.String("storage", cfg.EventStorageType.String()),
 	)
-	storage, storageDoneFn, err := initStorage(cfg)
+	storage, storageDoneFn, err := initStorage(logger, cfg)
 	if err != nil {
 		return err
 	}
 	defer storageDoneFn()
 
+	if pinger, ok := storage.(health.Pinger); ok {
+		checker.AddReadiness("storage", pinger.Ping)
+	}
+
+	if pools, ok := storage.(metrics.DBPools); ok {
+		if err := metrics.NewDBStats(registry, pools); err != nil {
+			return fmt.Errorf("can't init metrics: %w", err)
+		}
+	}
+
 	logger.Info("init notifier queue")
-	notifier, notifierDoneFn, err := initNotifier(logger, cfg)
+	notifier, err := initNotifier(ctx, logger, cfg, queueMetrics)
 	if err != nil {
 		return err
 	}
-	defer notifierDoneFn()
+	defer notifier.Done()
+
+	checker.AddReadiness("queue", notifier.Ping)
 
 	logger.Info("init app")
-	schedulerBusinessApp := schedulerBusiness.NewApp(logger, notifier, storage)
+	schedulerBusinessApp := schedulerBusiness.NewApp(
+		logger,
+		clock.Real{},
+		notifier,
+		instrumentedStorage.New(storage, storageMetrics),
+	)
 	schedulerBusinessApp.PurgeOlderThan = cfg.PurgeOlderThan
 	schedulerBusinessApp.NotifyInterval = cfg.NotifyInterval
+	schedulerBusinessApp.Metrics = schedulerMetrics
+
+	if cfg.Attachments.Dir != "" {
+		logger.Info("init attachments store", slog.String("dir", cfg.Attachments.Dir))
+		blobStore, err := localBlob.NewStore(cfg.Attachments.Dir)
+		if err != nil {
+			return fmt.Errorf("can't init attachments store: %w", err)
+		}
+
+		schedulerBusinessApp.Blobs = blobStore
+	}
 
 	logger.Info("start app")
 	schedulerBusinessApp.Schedule(ctx)
 
+	checker.AddLiveness("scheduler", schedulerBusinessApp.Check)
+
+	// Перечитывание конфигурации по SIGHUP
+	liveCfg := config.NewLive(cfg)
+	go config.WatchReload(
+		ctx,
+		logger,
+		configFile,
+		reloadConfig(logger, liveCfg, levelVar, formatVar, schedulerBusinessApp),
+	)
+
 	logger.Info("register shutdown")
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
@@ -119,6 +217,9 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 		slog.String("signal", sig.String()),
 	)
 
+	// сервис больше не готов к работе
+	checker.Drain()
+
 	// отменяем контекст, должен завершить schedulerBusinessApp.Schedule
 	cancel()
 
@@ -128,13 +229,34 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 	return nil
 }
 
-func initStorage(cfg Config) (schedulerBusiness.EventStorage, func() error, error) {
+func initStorage(logger *slog.Logger, cfg Config) (eventStorage.Storage, func() error, error) {
 	switch cfg.EventStorageType {
 	case config.EventStorageTypeMemory:
 		storage := memoryStorage.NewStorage()
 		return storage, func() error { return nil }, nil
 	case config.EventStorageTypePg:
-		storage, err := pgStorage.NewStorage(cfg.EventStoragePg.DataSource)
+		storage, err := pgStorage.NewStorage(pgStorageOptions(cfg.EventStoragePg))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return storage, storage.Close, nil
+	case config.EventStorageTypeFile:
+		storage, err := fileStorage.NewStorage(fileStorage.Options{
+			Dir:               cfg.EventStorageFile.Dir,
+			Sync:              fileStorage.SyncPolicy(cfg.EventStorageFile.Sync),
+			SyncInterval:      cfg.EventStorageFile.SyncInterval,
+			SnapshotInterval:  cfg.EventStorageFile.SnapshotInterval,
+			SnapshotThreshold: cfg.EventStorageFile.SnapshotThreshold,
+			Logger:            logger.With(slog.String("comp", "storage-file")),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return storage, storage.Close, nil
+	case config.EventStorageTypeSqlite:
+		storage, err := sqliteStorage.NewStorage(cfg.EventStorageSqlite.DataSource)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -145,12 +267,53 @@ func initStorage(cfg Config) (schedulerBusiness.EventStorage, func() error, erro
 	}
 }
 
-func initNotifier(logger *slog.Logger, cfg Config) (schedulerBusiness.Notifier, func(), error) {
+func initNotifier(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg Config,
+	queueMetrics queue.Metrics,
+) (*queue.NotifyQueue, error) {
 	q := queue.NewNotifyQueue(logger, cfg.AMQPConnect)
+	q.Metrics = queueMetrics
+	if err := initAMQPTLS(ctx, logger, q, cfg.AMQPTLS); err != nil {
+		return nil, err
+	}
+
 	err := q.Init()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// initAMQPTLS настраивает TLS подключения к брокеру, если заданы собственные настройки.
+// Сертификаты перечитываются при изменении, пока не отменён контекст ctx.
+func initAMQPTLS(ctx context.Context, logger *slog.Logger, q *queue.NotifyQueue, cfg config.TLSClient) error {
+	if !cfg.Configured() {
+		return nil
 	}
 
-	return q, func() { q.Done() }, nil
+	tlsConfig, store, err := tlsconfig.NewClient(logger.With(slog.String("comp", "amqp-tls")), cfg)
+	if err != nil {
+		return fmt.Errorf("can't init AMQP TLS: %w", err)
+	}
+
+	q.TLSConfig = tlsConfig
+	go store.Watch(ctx, clock.Real{}, tlsconfig.DefaultWatchInterval)
+
+	return nil
+}
+
+// pgStorageOptions возвращает параметры подключения к postgres из конфигурации.
+func pgStorageOptions(cfg config.EventStoragePg) pgStorage.Options {
+	return pgStorage.Options{
+		DataSource:       cfg.DataSource,
+		Replicas:         cfg.Replicas,
+		MaxOpenConns:     cfg.MaxOpenConns,
+		MaxIdleConns:     cfg.MaxIdleConns,
+		ConnMaxLifetime:  cfg.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.ConnMaxIdleTime,
+		StatementTimeout: cfg.StatementTimeout,
+	}
 }
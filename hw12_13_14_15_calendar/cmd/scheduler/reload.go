@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log/slog"
+
+	schedulerBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/scheduler"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+)
+
+// liveFields - поля конфигурации, изменения которых применяются без перезапуска сервиса.
+// Изменения остальных полей (адреса, хранилище и т.п.) требуют перезапуска и не применяются.
+var liveFields = []string{
+	"logger.level",
+	"logger.format",
+	"notify_interval",
+	"purge_older_than",
+}
+
+// reloadConfig возвращает функцию, применяющую перечитанную конфигурацию next к текущей конфигурации cfg,
+// уровню логирования levelVar, формату логов formatVar и интервалам планировщика app.
+// Конфигурация, не прошедшая проверку Config.Validate, не применяется.
+func reloadConfig(
+	logger *slog.Logger,
+	cfg *config.Live[Config],
+	levelVar *slog.LevelVar,
+	formatVar *logger.FormatVar,
+	app *schedulerBusiness.App,
+) func(next Config) {
+	return func(next Config) {
+		if err := next.Validate(); err != nil {
+			logger.Error(
+				"invalid config, changes are not applied",
+				slog.String("error", err.Error()),
+			)
+
+			return
+		}
+
+		current := cfg.Load()
+
+		applied, restart := config.Split(config.Diff(current, next), liveFields)
+		if len(restart) > 0 {
+			logger.Warn(
+				"config changes require restart and are not applied",
+				slog.Any("fields", restart),
+			)
+		}
+
+		if len(applied) == 0 {
+			logger.Info("no config changes to apply")
+			return
+		}
+
+		current.Log = next.Log
+		current.NotifyInterval = next.NotifyInterval
+		current.PurgeOlderThan = next.PurgeOlderThan
+
+		levelVar.Set(current.Log.Level)
+		formatVar.Set(current.Log.Format)
+		app.SetIntervals(current.NotifyInterval, current.PurgeOlderThan)
+
+		cfg.Store(current)
+
+		logger.Info(
+			"config changes applied",
+			slog.Any("fields", applied),
+		)
+	}
+}
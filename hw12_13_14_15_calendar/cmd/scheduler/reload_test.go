@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	schedulerBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/scheduler"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	memoryStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
+)
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(context.Context, model.Event) error { return nil }
+
+func Test_ReloadConfig(t *testing.T) {
+	cfg, err := ParseConfig(strings.NewReader(`default: true`))
+	require.NoError(t, err, "must parse default config")
+	require.NoError(t, cfg.Validate(), "default config must be valid")
+
+	newReload := func() (*config.Live[Config], *slog.LevelVar, *schedulerBusiness.App, func(Config)) {
+		levelVar := &slog.LevelVar{}
+		levelVar.Set(cfg.Log.Level)
+
+		app := schedulerBusiness.NewApp(
+			slog.New(slog.NewTextHandler(io.Discard, nil)),
+			clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+			nopNotifier{},
+			memoryStorage.NewStorage(),
+		)
+		app.NotifyInterval = cfg.NotifyInterval
+		app.PurgeOlderThan = cfg.PurgeOlderThan
+
+		liveCfg := config.NewLive(cfg)
+		reload := reloadConfig(
+			slog.New(slog.NewTextHandler(io.Discard, nil)),
+			liveCfg,
+			levelVar,
+			&logger.FormatVar{},
+			app,
+		)
+
+		return liveCfg, levelVar, app, reload
+	}
+
+	t.Run("apply", func(t *testing.T) {
+		liveCfg, levelVar, app, reload := newReload()
+
+		next := cfg
+		next.Log.Level = slog.LevelDebug
+		next.NotifyInterval = 10 * time.Second
+		next.PurgeOlderThan = 24 * time.Hour
+		next.AMQPConnect = "amqp://other:5672/"
+
+		reload(next)
+
+		current := liveCfg.Load()
+		require.Equal(t, slog.LevelDebug, levelVar.Level(), "must apply log level")
+		require.Equal(t, next.NotifyInterval, current.NotifyInterval, "must apply notify interval")
+		require.Equal(t, next.PurgeOlderThan, current.PurgeOlderThan, "must apply purge period")
+		require.Equal(t, next.NotifyInterval, app.NotifyInterval, "must set notify interval")
+		require.Equal(t, next.PurgeOlderThan, app.PurgeOlderThan, "must set purge period")
+		require.Equal(t, cfg.AMQPConnect, current.AMQPConnect, "must not apply queue connection")
+	})
+
+	t.Run("reject invalid", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(cfg *Config)
+		}{
+			{
+				name:   "zero notify interval",
+				modify: func(cfg *Config) { cfg.NotifyInterval = 0 },
+			},
+			{
+				name:   "negative notify interval",
+				modify: func(cfg *Config) { cfg.NotifyInterval = -time.Second },
+			},
+			{
+				name:   "negative purge period",
+				modify: func(cfg *Config) { cfg.PurgeOlderThan = -time.Hour },
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				liveCfg, levelVar, app, reload := newReload()
+
+				next := cfg
+				next.Log.Level = slog.LevelDebug
+				tt.modify(&next)
+
+				require.ErrorIs(t, next.Validate(), config.ErrInvalidValue, "config must be invalid")
+
+				reload(next)
+
+				require.Equal(t, cfg, liveCfg.Load(), "must keep current config")
+				require.Equal(t, cfg.Log.Level, levelVar.Level(), "must keep log level")
+				require.Equal(t, cfg.NotifyInterval, app.NotifyInterval, "must keep notify interval")
+				require.Equal(t, cfg.PurgeOlderThan, app.PurgeOlderThan, "must keep purge period")
+			})
+		}
+	})
+}
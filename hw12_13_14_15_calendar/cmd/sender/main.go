@@ -14,19 +14,27 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/sender"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/health"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/metrics"
 	queue "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/queue/notify/rabbit"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tlsconfig"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
 )
 
 const serviceName = "sender"
 
+var errSenderStopped = errors.New("sender is stopped")
+
 func main() {
 	initFlag()
 
-	logger, levelVar := logger.New(os.Stdout, slog.LevelInfo, serviceName)
+	logger, levelVar, formatVar := logger.New(os.Stdout, slog.LevelInfo, serviceName)
 
 	ctx := context.Background()
-	if err := run(ctx, logger, levelVar); err != nil {
+	if err := run(ctx, logger, levelVar, formatVar); err != nil {
 		logger.Error(
 			"failed to run",
 			slog.String("error", err.Error()),
@@ -51,7 +59,12 @@ func initFlag() {
 	flag.Parse()
 }
 
-func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) error {
+func run(
+	ctx context.Context,
+	logger *slog.Logger,
+	levelVar *slog.LevelVar,
+	formatVar *logger.FormatVar,
+) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -74,6 +87,10 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 		return err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
 	logger.Info(
 		"set logger level",
 		slog.String("from", levelVar.Level().String()),
@@ -81,21 +98,66 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 	)
 	levelVar.Set(cfg.Log.Level)
 
+	logger.Info(
+		"set logger format",
+		slog.String("from", string(formatVar.Format())),
+		slog.String("to", string(cfg.Log.Format)),
+	)
+	formatVar.Set(cfg.Log.Format)
+
+	logger.Info(
+		"init tracing",
+		slog.String("exporter", string(cfg.Tracing.Exporter)),
+	)
+	shutdownTracing, err := tracing.Init(cfg.Tracing, serviceName)
+	if err != nil {
+		return fmt.Errorf("can't init tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	logger.Info("init metrics")
+	registry := metrics.NewRegistry()
+
+	senderMetrics, err := metrics.NewSender(registry)
+	if err != nil {
+		return fmt.Errorf("can't init metrics: %w", err)
+	}
+
+	checker := health.NewChecker(health.DefaultTimeout)
+
+	stopMetricsServer := startMetricsServer(logger, cfg.Metrics, registry, checker)
+	defer stopMetricsServer()
+
 	logger.Info("init notifier queue")
-	notificationCh, notifierDoneFn, err := initNotificationQueue(ctx, logger, cfg)
+	notificationCh, notifier, err := initNotificationQueue(ctx, logger, cfg)
 	if err != nil {
 		return err
 	}
-	defer notifierDoneFn()
+	defer notifier.Done()
+
+	checker.AddReadiness("queue", notifier.Ping)
 
 	logger.Info("init app")
 	senderBusinessApp := sender.NewApp(logger, notificationCh, os.Stdout)
+	senderBusinessApp.Metrics = senderMetrics
 
 	logger.Info("start app")
 	if !senderBusinessApp.Send(ctx) {
 		return errors.New("can't start sender")
 	}
 
+	checker.AddLiveness("sender", func(context.Context) error {
+		if !senderBusinessApp.IsReady() {
+			return errSenderStopped
+		}
+
+		return nil
+	})
+
+	// Перечитывание конфигурации по SIGHUP
+	liveCfg := config.NewLive(cfg)
+	go config.WatchReload(ctx, logger, configFile, reloadConfig(logger, liveCfg, levelVar, formatVar))
+
 	logger.Info("register shutdown")
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
@@ -108,6 +170,9 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 		slog.String("signal", sig.String()),
 	)
 
+	// сервис больше не готов к работе
+	checker.Drain()
+
 	// отменяем контекст, должен завершить senderBusinessApp.Send
 	cancel()
 
@@ -115,7 +180,7 @@ func run(ctx context.Context, logger *slog.Logger, levelVar *slog.LevelVar) erro
 	senderBusinessApp.Wait()
 
 	go func() {
-		<-time.After(cfg.ShutdownTimeout)
+		<-time.After(liveCfg.Load().ShutdownTimeout)
 		logger.Error("can't stop sender in time")
 		os.Exit(1)
 	}()
@@ -127,8 +192,12 @@ func initNotificationQueue(
 	ctx context.Context,
 	logger *slog.Logger,
 	cfg Config,
-) (<-chan sender.NotificationMessage, func(), error) {
+) (<-chan sender.NotificationMessage, *queue.NotifyQueue, error) {
 	q := queue.NewNotifyQueue(logger, cfg.AMQPConnect)
+	if err := initAMQPTLS(ctx, logger, q, cfg.AMQPTLS); err != nil {
+		return nil, nil, err
+	}
+
 	err := q.Init()
 	if err != nil {
 		return nil, nil, err
@@ -152,5 +221,23 @@ func initNotificationQueue(
 		}
 	}()
 
-	return out, func() { q.Done() }, nil
+	return out, q, nil
+}
+
+// initAMQPTLS настраивает TLS подключения к брокеру, если заданы собственные настройки.
+// Сертификаты перечитываются при изменении, пока не отменён контекст ctx.
+func initAMQPTLS(ctx context.Context, logger *slog.Logger, q *queue.NotifyQueue, cfg config.TLSClient) error {
+	if !cfg.Configured() {
+		return nil
+	}
+
+	tlsConfig, store, err := tlsconfig.NewClient(logger.With(slog.String("comp", "amqp-tls")), cfg)
+	if err != nil {
+		return fmt.Errorf("can't init AMQP TLS: %w", err)
+	}
+
+	q.TLSConfig = tlsConfig
+	go store.Watch(ctx, clock.Real{}, tlsconfig.DefaultWatchInterval)
+
+	return nil
 }
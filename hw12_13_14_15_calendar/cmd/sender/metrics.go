@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/health"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/metrics"
+)
+
+// startMetricsServer запускает http-сервер метрик из реестра reg и проверок состояния checker (/healthz, /readyz).
+// Возвращает функцию останова сервера.
+func startMetricsServer(
+	logger *slog.Logger,
+	cfg MetricsConfig,
+	reg *prometheus.Registry,
+	checker *health.Checker,
+) func() {
+	server := metrics.NewServer(cfg.Host, cfg.Port, reg, checker.Register)
+
+	logger.Info("start metrics server", slog.String("addr", server.Addr))
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server failed", slog.String("error", err.Error()))
+		}
+	}()
+
+	return func() { server.Close() }
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/logger"
+)
+
+// liveFields - поля конфигурации, изменения которых применяются без перезапуска сервиса.
+// Изменения остальных полей (подключение к очереди и т.п.) требуют перезапуска и не применяются.
+var liveFields = []string{
+	"shutdown_timeout",
+	"logger.level",
+	"logger.format",
+}
+
+// reloadConfig возвращает функцию, применяющую перечитанную конфигурацию next к текущей конфигурации cfg,
+// уровню логирования levelVar и формату логов formatVar.
+// Конфигурация, не прошедшая проверку Config.Validate, не применяется.
+func reloadConfig(
+	logger *slog.Logger,
+	cfg *config.Live[Config],
+	levelVar *slog.LevelVar,
+	formatVar *logger.FormatVar,
+) func(next Config) {
+	return func(next Config) {
+		if err := next.Validate(); err != nil {
+			logger.Error(
+				"invalid config, changes are not applied",
+				slog.String("error", err.Error()),
+			)
+
+			return
+		}
+
+		current := cfg.Load()
+
+		applied, restart := config.Split(config.Diff(current, next), liveFields)
+		if len(restart) > 0 {
+			logger.Warn(
+				"config changes require restart and are not applied",
+				slog.Any("fields", restart),
+			)
+		}
+
+		if len(applied) == 0 {
+			logger.Info("no config changes to apply")
+			return
+		}
+
+		current.ShutdownTimeout = next.ShutdownTimeout
+		current.Log = next.Log
+
+		levelVar.Set(current.Log.Level)
+		formatVar.Set(current.Log.Format)
+
+		cfg.Store(current)
+
+		logger.Info(
+			"config changes applied",
+			slog.Any("fields", applied),
+		)
+	}
+}
@@ -0,0 +1,52 @@
+// api/attachment - приложение с контроллером attachment.
+// Представляет собой пакет с обработчиками для http-сервера основанного на http/web/mux мультиплексоре.
+//
+// Основная задача - выдача содержимого файловых вложений событий бизнес-логики business/calendar по http.
+// Владелец и рабочее пространство передаются в заголовках X-Owner-ID и X-Tenant-ID, как и для grpc-gateway.
+package attachment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+type Business interface {
+	OpenAttachment(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		eventID model.ID,
+		attachmentID model.AttachmentID,
+	) (model.Attachment, io.ReadCloser, error)
+}
+
+type App struct {
+	business Business
+	logger   *slog.Logger
+}
+
+func NewApp(business Business, logger *slog.Logger) *App {
+	return &App{
+		business: business,
+		logger:   logger,
+	}
+}
+
+func (a *App) AddRoutes(mux *web.Mux) error {
+	a.logger.Debug("add routes")
+
+	const path = "/events/{eventID}/attachments/{attachmentID}"
+
+	err := mux.Handle(http.MethodGet, "v1", path, a.HandleDownload)
+	if err != nil {
+		return fmt.Errorf("mux.Handle method=%s version=%s path=%s", http.MethodGet, "v1", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// DownloadResponse - содержимое файлового вложения, передаётся клиенту потоком.
+type DownloadResponse struct {
+	attachment model.Attachment
+	content    io.ReadCloser
+}
+
+func (r DownloadResponse) Data() (data []byte, contentType string, err error) {
+	return nil, r.attachment.ContentType, nil
+}
+
+func (r DownloadResponse) Header() http.Header {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": r.attachment.FileName})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	return http.Header{
+		"Content-Disposition":    {disposition},
+		"Content-Length":         {strconv.FormatInt(r.attachment.Size, 10)},
+		"X-Content-Type-Options": {"nosniff"},
+	}
+}
+
+func (r DownloadResponse) Stream(w io.Writer) error {
+	_, err := io.Copy(w, r.content)
+
+	return err
+}
+
+func (r DownloadResponse) Close() error {
+	return r.content.Close()
+}
+
+// HandleDownload отдаёт содержимое файлового вложения события:
+// GET /v1/events/{eventID}/attachments/{attachmentID}.
+func (a *App) HandleDownload(ctx context.Context, r *http.Request) web.DataResponder {
+	ownerID, err := auth.ValidateOwner(r.Header.Get("X-Owner-ID"))
+	if err != nil {
+		return web.StatusErrorResponse{Code: http.StatusUnauthorized, Err: err}
+	}
+
+	tenantID := model.DefaultTenantID
+	if tenant := r.Header.Get("X-Tenant-ID"); tenant != "" {
+		tenantID, err = auth.ValidateTenant(tenant)
+		if err != nil {
+			return a.errorResponse(ctx, err)
+		}
+	}
+
+	eventID, err := model.NewIDFromString(r.PathValue("eventID"))
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	attachmentID, err := model.NewAttachmentIDFromString(r.PathValue("attachmentID"))
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	attachment, content, err := a.business.OpenAttachment(ctx, tenantID, ownerID, eventID, attachmentID)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	return DownloadResponse{
+		attachment: attachment,
+		content:    content,
+	}
+}
+
+// errorResponse возвращает ответ с кодом, соответствующим ошибке предметной области err.
+// Любая другая ошибка добавляется в лог, клиенту возвращается http.StatusInternalServerError.
+func (a *App) errorResponse(ctx context.Context, err error) web.DataResponder {
+	if resp, ok := web.DomainErrorResponse(err); ok {
+		return resp
+	}
+
+	a.logger.ErrorContext(
+		ctx,
+		"error occurred",
+		slog.String("handle", "HandleDownload"),
+		slog.String("error", err.Error()),
+	)
+
+	return web.ErrorResponse{Err: errors.New("some error")}
+}
@@ -0,0 +1,128 @@
+// api/caldav - приложение с контроллером caldav.
+// Представляет собой пакет с обработчиками для http-сервера основанного на http/web/mux мультиплексоре.
+//
+// Основная задача - двусторонняя синхронизация событий бизнес-логики business/calendar с приложениями
+// календарей по протоколу CalDAV (RFC 4791). Поддерживается подмножество протокола:
+//   - PROPFIND свойств владельца (principal), его календаря и событий календаря;
+//   - REPORT calendar-query (фильтр по промежутку времени) и calendar-multiget;
+//   - GET, PUT и DELETE событий календаря (ресурсов VEVENT) с ETag и условными запросами.
+//
+// У владельца один календарь PathPrefix+"events/", события календаря - ресурсы "{eventID}.ics".
+// Владелец и рабочее пространство передаются в заголовках X-Owner-ID и X-Tenant-ID, как и для grpc-gateway.
+package caldav
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+const (
+	// PathPrefix - префикс пути CalDAV на http-сервере, он же путь владельца (principal) и его календарей.
+	PathPrefix = "/v1/caldav/"
+
+	// CalendarPath - путь календаря владельца.
+	CalendarPath = PathPrefix + "events/"
+
+	// WellKnownPath - путь для обнаружения CalDAV сервера приложениями календарей (RFC 6764).
+	WellKnownPath = "/.well-known/caldav"
+
+	// objectExt - расширение ресурса события в календаре.
+	objectExt = ".ics"
+
+	// maxObjectSize - допустимый размер ресурса события в запросе PUT.
+	maxObjectSize = 1 << 20
+
+	// maxRequestSize - допустимый размер тела запросов PROPFIND и REPORT.
+	maxRequestSize = 1 << 20
+)
+
+var (
+	// calendarFrom, calendarTill - промежуток, события которого составляют календарь владельца.
+	// Хранилище выбирает события только за промежуток, поэтому календарь ограничен "всеми разумными" датами.
+	calendarFrom = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
+	calendarTill = time.Date(2200, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type Business interface {
+	CreateEvent(ctx context.Context, event model.Event) (warnings []error, err error)
+	FindEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) (model.Event, error)
+	UpdateEvent(ctx context.Context, event model.Event) error
+	DeleteEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) error
+	GetEvents(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		from time.Time,
+		to time.Time,
+	) ([]model.Event, error)
+	ListLabels(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) ([]model.OwnerLabel, error)
+}
+
+type App struct {
+	business Business
+	logger   *slog.Logger
+}
+
+func NewApp(business Business, logger *slog.Logger) *App {
+	return &App{
+		business: business,
+		logger:   logger,
+	}
+}
+
+func (a *App) AddRoutes(mux *web.Mux) error {
+	a.logger.Debug("add routes")
+
+	routes := []struct {
+		method  string
+		version string
+		path    string
+		handler web.HandlerFunc
+	}{
+		{http.MethodGet, "", WellKnownPath, a.HandleWellKnown},
+		{"PROPFIND", "", WellKnownPath, a.HandleWellKnown},
+		{http.MethodOptions, "v1", "/caldav/{path...}", a.HandleOptions},
+		{"PROPFIND", "v1", "/caldav/{$}", a.HandlePropfindPrincipal},
+		{"PROPFIND", "v1", "/caldav/events/{$}", a.HandlePropfindCalendar},
+		{"REPORT", "v1", "/caldav/events/{$}", a.HandleReport},
+		{"PROPFIND", "v1", "/caldav/events/{name}", a.HandlePropfindObject},
+		{http.MethodGet, "v1", "/caldav/events/{name}", a.HandleGet},
+		{http.MethodPut, "v1", "/caldav/events/{name}", a.HandlePut},
+		{http.MethodDelete, "v1", "/caldav/events/{name}", a.HandleDelete},
+	}
+
+	for _, route := range routes {
+		err := mux.Handle(route.method, route.version, route.path, route.handler)
+		if err != nil {
+			return fmt.Errorf("mux.Handle method=%s version=%s path=%s", route.method, route.version, route.path)
+		}
+	}
+
+	return nil
+}
+
+// objectHref возвращает путь ресурса события eventID в календаре.
+func objectHref(eventID model.ID) string {
+	return CalendarPath + string(eventID) + objectExt
+}
+
+// readBody читает тело запроса r не больше limit байт.
+func readBody(r *http.Request, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("can't read request: %w", err)
+	}
+
+	if int64(len(data)) > limit {
+		return nil, errRequestTooLarge
+	}
+
+	return data, nil
+}
@@ -0,0 +1,217 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical/vevent"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// HandleGet отдаёт событие календаря в формате iCalendar: GET /v1/caldav/events/{eventID}.ics.
+// Поддерживается условный запрос с If-None-Match.
+func (a *App) HandleGet(ctx context.Context, r *http.Request) web.DataResponder {
+	tenantID, ownerID, resp := a.owner(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	eventID, err := eventIDFromName(r.PathValue("name"))
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	event, err := a.business.FindEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	obj, err := newEventObject(event)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" && matchETag(inm, obj.etag) {
+		return ObjectResponse{etag: obj.etag, notModified: true}
+	}
+
+	return ObjectResponse{data: obj.data, etag: obj.etag}
+}
+
+// HandlePut создаёт или заменяет событие календаря: PUT /v1/caldav/events/{eventID}.ics.
+// Имя ресурса - UID события. Условные запросы If-Match и If-None-Match: * защищают от потери изменений,
+// сделанных другими клиентами; проверка условия не атомарна с изменением события.
+//
+// Свойства события, которых нет в iCalendar (цвет, адрес места проведения, вложения-файлы), сохраняются.
+// Сервер изменяет содержимое ресурса (например, пропускает неизвестные свойства), поэтому ETag
+// в ответе не возвращается (RFC 4791, 5.3.4): клиент получает ресурс заново.
+// Предупреждения о созданном событии (например, вне рабочего времени) возвращаются в заголовках WarningHeader.
+func (a *App) HandlePut(ctx context.Context, r *http.Request) web.DataResponder {
+	tenantID, ownerID, resp := a.owner(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	eventID, err := eventIDFromName(r.PathValue("name"))
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	data, err := readBody(r, maxObjectSize)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	calendar, err := ical.Parse(bytes.NewReader(data))
+	if err != nil {
+		return a.errorResponse(ctx, fmt.Errorf("%w: %w", vevent.ErrInvalidCalendarData, err))
+	}
+
+	event, err := vevent.ToEvent(calendar, tenantID, ownerID)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	if event.EventID() != eventID {
+		return a.errorResponse(ctx, ErrUIDMismatch)
+	}
+
+	current, exists, err := a.findObject(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	if !checkPreconditions(r, current, exists) {
+		return errPreconditionFailed
+	}
+
+	event.Labels, err = a.knownLabels(ctx, tenantID, ownerID, event.Labels)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	if exists {
+		keepHidden(&event, current.event)
+
+		err = a.business.UpdateEvent(ctx, event)
+		if err != nil {
+			return a.errorResponse(ctx, err)
+		}
+
+		return nil
+	}
+
+	warnings, err := a.business.CreateEvent(ctx, event)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	return StatusResponse{code: http.StatusCreated, header: warningsHeader(warnings)}
+}
+
+// HandleDelete удаляет событие календаря: DELETE /v1/caldav/events/{eventID}.ics.
+// Поддерживается условный запрос с If-Match.
+func (a *App) HandleDelete(ctx context.Context, r *http.Request) web.DataResponder {
+	tenantID, ownerID, resp := a.owner(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	eventID, err := eventIDFromName(r.PathValue("name"))
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	if r.Header.Get("If-Match") != "" {
+		current, exists, err := a.findObject(ctx, tenantID, ownerID, eventID)
+		if err != nil {
+			return a.errorResponse(ctx, err)
+		}
+
+		if !checkPreconditions(r, current, exists) {
+			return errPreconditionFailed
+		}
+	}
+
+	err = a.business.DeleteEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	return nil
+}
+
+// checkPreconditions проверяет условия запроса на изменение ресурса current (RFC 9110, 13.1):
+// If-Match - ресурс есть и его ETag подходит, If-None-Match - ресурса нет или его ETag не подходит.
+func checkPreconditions(r *http.Request, current eventObject, exists bool) bool {
+	if im := r.Header.Get("If-Match"); im != "" && (!exists || !matchETag(im, current.etag)) {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" && exists && matchETag(inm, current.etag) {
+		return false
+	}
+
+	return true
+}
+
+// matchETag сообщает, что значение заголовка If-Match или If-None-Match header подходит под etag.
+func matchETag(header string, etag string) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || strings.TrimPrefix(value, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// knownLabels возвращает метки labels, которые есть в каталоге владельца: категории событий
+// из приложений календарей не обязаны совпадать с метками, неизвестные категории пропускаются.
+func (a *App) knownLabels(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	labels []model.Label,
+) ([]model.Label, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	catalog, err := a.business.ListLabels(ctx, tenantID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[model.Label]struct{}, len(catalog))
+	for _, label := range catalog {
+		known[label.Name()] = struct{}{}
+	}
+
+	var result []model.Label
+	for _, label := range labels {
+		if _, ok := known[label]; ok {
+			result = append(result, label)
+		}
+	}
+
+	return result, nil
+}
+
+// keepHidden переносит в событие event из текущего события current свойства, которых нет в iCalendar:
+// цвет, а также название и адрес места проведения, если место в ресурсе не изменилось
+// (в ресурсе они записаны одной строкой, см. vevent.LocationText).
+func keepHidden(event *model.Event, current model.Event) {
+	event.Color = current.Color
+
+	if vevent.LocationText(event.Location) == vevent.LocationText(current.Location) {
+		event.Location.Name = current.Location.Name
+		event.Location.Address = current.Location.Address
+	}
+}
@@ -0,0 +1,369 @@
+package caldav
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	modelStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// fakeBusiness - бизнес-логика календаря для тестов: события владельцев в памяти.
+type fakeBusiness struct {
+	events map[model.ID]model.Event
+	labels []model.OwnerLabel
+
+	// warnings - предупреждения, которые возвращает CreateEvent.
+	warnings []error
+
+	// err - ошибка всех операций, nil - операции выполняются.
+	err error
+}
+
+func newFakeBusiness() *fakeBusiness {
+	return &fakeBusiness{events: map[model.ID]model.Event{}}
+}
+
+func (b *fakeBusiness) CreateEvent(_ context.Context, event model.Event) ([]error, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	b.events[event.EventID()] = event
+
+	return b.warnings, nil
+}
+
+func (b *fakeBusiness) FindEvent(
+	_ context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (model.Event, error) {
+	if b.err != nil {
+		return model.Event{}, b.err
+	}
+
+	event, ok := b.events[eventID]
+	if !ok || event.TenantID() != tenantID || event.OwnerID() != ownerID {
+		return model.Event{}, modelStorage.ErrEventNotFound
+	}
+
+	return event, nil
+}
+
+func (b *fakeBusiness) UpdateEvent(_ context.Context, event model.Event) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	b.events[event.EventID()] = event
+
+	return nil
+}
+
+func (b *fakeBusiness) DeleteEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) error {
+	if _, err := b.FindEvent(ctx, tenantID, ownerID, eventID); err != nil {
+		return err
+	}
+
+	delete(b.events, eventID)
+
+	return nil
+}
+
+func (b *fakeBusiness) GetEvents(
+	_ context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+) ([]model.Event, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	var events []model.Event
+	for _, event := range b.events {
+		if event.TenantID() == tenantID && event.OwnerID() == ownerID &&
+			event.StartAt().Before(to) && event.EndAt().After(from) {
+			events = append(events, event)
+		}
+	}
+
+	slices.SortFunc(events, func(a, b model.Event) int {
+		return a.StartAt().Compare(b.StartAt())
+	})
+
+	return events, nil
+}
+
+func (b *fakeBusiness) ListLabels(context.Context, model.TenantID, model.OwnerID) ([]model.OwnerLabel, error) {
+	return b.labels, b.err
+}
+
+// newTestServer возвращает http-обработчик CalDAV с бизнес-логикой business.
+func newTestServer(t *testing.T, business Business) http.Handler {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux, err := web.NewMux(logger, NewApp(business, logger))
+	require.NoError(t, err, "must create mux")
+
+	return mux
+}
+
+// do выполняет запрос method к path от имени владельца ownerID с телом body и заголовками header (пары ключ-значение).
+func do(
+	t *testing.T,
+	handler http.Handler,
+	ownerID model.OwnerID,
+	method string,
+	path string,
+	body string,
+	header ...string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("X-Owner-ID", string(ownerID))
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+// vcalendar возвращает ресурс события uid с заголовком summary и дополнительными свойствами props.
+func vcalendar(uid string, summary string, startAt time.Time, props ...string) string {
+	var b strings.Builder
+
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\nBEGIN:VEVENT\r\n")
+	b.WriteString("UID:" + uid + "\r\n")
+	b.WriteString("DTSTART:" + startAt.UTC().Format("20060102T150405Z") + "\r\n")
+	b.WriteString("DURATION:PT1H\r\n")
+	b.WriteString("SUMMARY:" + summary + "\r\n")
+	for _, prop := range props {
+		b.WriteString(prop + "\r\n")
+	}
+	b.WriteString("END:VEVENT\r\nEND:VCALENDAR\r\n")
+
+	return b.String()
+}
+
+var testStartAt = time.Date(2030, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+func TestHandlePut_Preconditions(t *testing.T) {
+	business := newFakeBusiness()
+	handler := newTestServer(t, business)
+	ownerID := model.NewOwnerID()
+
+	eventID := uuid.NewString()
+	path := CalendarPath + eventID + objectExt
+
+	w := do(t, handler, ownerID, http.MethodPut, path, vcalendar(eventID, "meeting", testStartAt), "If-Match", "*")
+	require.Equal(t, http.StatusPreconditionFailed, w.Code, "If-Match must fail for missed resource")
+
+	w = do(t, handler, ownerID, http.MethodPut, path, vcalendar(eventID, "meeting", testStartAt), "If-None-Match", "*")
+	require.Equal(t, http.StatusCreated, w.Code, "If-None-Match: * must create missed resource")
+
+	w = do(t, handler, ownerID, http.MethodPut, path, vcalendar(eventID, "other", testStartAt), "If-None-Match", "*")
+	require.Equal(t, http.StatusPreconditionFailed, w.Code, "If-None-Match: * must not replace existing resource")
+
+	w = do(t, handler, ownerID, http.MethodGet, path, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("Etag")
+	require.NotEmpty(t, etag, "must return etag")
+
+	w = do(t, handler, ownerID, http.MethodPut, path, vcalendar(eventID, "other", testStartAt), "If-Match", `"stale"`)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code, "If-Match must fail for stale etag")
+
+	w = do(t, handler, ownerID, http.MethodPut, path, vcalendar(eventID, "updated", testStartAt), "If-Match", etag)
+	require.Equal(t, http.StatusNoContent, w.Code, "If-Match must replace resource with current etag")
+	require.Equal(t, model.Title("updated"), business.events[model.ID(eventID)].Title)
+
+	w = do(t, handler, ownerID, http.MethodPut, path, vcalendar(eventID, "again", testStartAt), "If-Match", etag)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code, "If-Match must fail after resource changed")
+
+	w = do(t, handler, ownerID, http.MethodGet, path, "", "If-None-Match", etag)
+	require.Equal(t, http.StatusOK, w.Code, "must return changed resource")
+
+	w = do(t, handler, ownerID, http.MethodGet, path, "", "If-None-Match", w.Header().Get("Etag"))
+	require.Equal(t, http.StatusNotModified, w.Code, "must not return unchanged resource")
+}
+
+func TestHandlePut_UID(t *testing.T) {
+	business := newFakeBusiness()
+	handler := newTestServer(t, business)
+	ownerID := model.NewOwnerID()
+
+	uid := strings.ToUpper(uuid.NewString())
+
+	w := do(t, handler, ownerID, http.MethodPut, CalendarPath+uid+objectExt, vcalendar(uid, "meeting", testStartAt))
+	require.Equal(t, http.StatusCreated, w.Code, "must create event from upper case UID")
+	require.Contains(t, business.events, model.ID(strings.ToLower(uid)), "must store lower case event ID")
+
+	w = do(t, handler, ownerID, http.MethodGet, CalendarPath+strings.ToLower(uid)+objectExt, "")
+	require.Equal(t, http.StatusOK, w.Code, "must find event by lower case name")
+
+	other := uuid.NewString()
+	w = do(t, handler, ownerID, http.MethodPut, CalendarPath+other+objectExt, vcalendar(uid, "meeting", testStartAt))
+	require.Equal(t, http.StatusBadRequest, w.Code, "UID must match resource name")
+	require.Contains(t, w.Body.String(), ErrUIDMismatch.Error())
+	require.NotContains(t, business.events, model.ID(other), "must not create event")
+}
+
+func TestHandlePut_KeepHidden(t *testing.T) {
+	business := newFakeBusiness()
+	handler := newTestServer(t, business)
+	ownerID := model.NewOwnerID()
+
+	label, err := model.NewLabel("work")
+	require.NoError(t, err)
+	business.labels = []model.OwnerLabel{model.NewOwnerLabel(model.DefaultTenantID, ownerID, label, "")}
+
+	eventID := model.NewID()
+	event, err := model.NewEvent(
+		model.DefaultTenantID, eventID, ownerID, "meeting", testStartAt, testStartAt.Add(time.Hour),
+	)
+	require.NoError(t, err)
+	event.Color = "#ff0000"
+	event.Location = model.Location{Name: "room 3", Address: "Main st. 1"}
+	business.events[eventID] = event
+
+	path := CalendarPath + string(eventID) + objectExt
+
+	w := do(t, handler, ownerID, http.MethodPut, path, vcalendar(
+		string(eventID), "meeting", testStartAt,
+		"LOCATION:room 3\\, Main st. 1",
+		"CATEGORIES:work,personal",
+	))
+	require.Equal(t, http.StatusNoContent, w.Code, "must update event")
+
+	updated := business.events[eventID]
+	require.Equal(t, model.Color("#ff0000"), updated.Color, "must keep color")
+	require.Equal(t, event.Location, updated.Location, "must keep location name and address")
+	require.Equal(t, []model.Label{"work"}, updated.Labels, "must skip unknown labels")
+
+	body := vcalendar(string(eventID), "meeting", testStartAt, "LOCATION:room 5")
+	w = do(t, handler, ownerID, http.MethodPut, path, body)
+	require.Equal(t, http.StatusNoContent, w.Code, "must update event")
+
+	updated = business.events[eventID]
+	require.Equal(t, model.Color("#ff0000"), updated.Color, "must keep color")
+	require.Equal(t, model.Location{Name: "room 5"}, updated.Location, "must replace changed location")
+}
+
+func TestHandlePut_Warnings(t *testing.T) {
+	business := newFakeBusiness()
+	handler := newTestServer(t, business)
+	ownerID := model.NewOwnerID()
+
+	business.warnings = []error{model.ErrOutsideWorkingHours}
+
+	eventID := uuid.NewString()
+	w := do(t, handler, ownerID, http.MethodPut, CalendarPath+eventID+objectExt, vcalendar(eventID, "late", testStartAt))
+	require.Equal(t, http.StatusCreated, w.Code, "must create event")
+	require.Equal(t, []string{"OUTSIDE_WORKING_HOURS"}, w.Header().Values(WarningHeader), "must return warnings")
+
+	business.warnings = nil
+
+	eventID = uuid.NewString()
+	w = do(t, handler, ownerID, http.MethodPut, CalendarPath+eventID+objectExt, vcalendar(eventID, "early", testStartAt))
+	require.Equal(t, http.StatusCreated, w.Code, "must create event")
+	require.Empty(t, w.Header().Values(WarningHeader), "must not return warnings")
+}
+
+func TestHandleObject_Errors(t *testing.T) {
+	business := newFakeBusiness()
+	handler := newTestServer(t, business)
+	ownerID := model.NewOwnerID()
+
+	eventID := uuid.NewString()
+	path := CalendarPath + eventID + objectExt
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		ownerID model.OwnerID
+		err     error
+		code    int
+	}{
+		{name: "no owner", method: http.MethodGet, path: path, code: http.StatusUnauthorized},
+		{name: "not found", method: http.MethodGet, path: path, ownerID: ownerID, code: http.StatusNotFound},
+		{
+			name:    "invalid name",
+			method:  http.MethodGet,
+			path:    CalendarPath + "event" + objectExt,
+			ownerID: ownerID,
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "invalid calendar data",
+			method:  http.MethodPut,
+			path:    path,
+			body:    "BEGIN:VCALENDAR\r\n",
+			ownerID: ownerID,
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "too large",
+			method:  http.MethodPut,
+			path:    path,
+			body:    strings.Repeat("x", maxObjectSize+1),
+			ownerID: ownerID,
+			code:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:    "time is busy",
+			method:  http.MethodPut,
+			path:    path,
+			body:    vcalendar(eventID, "meeting", testStartAt),
+			ownerID: ownerID,
+			err:     modelStorage.ErrTimeIsBusy,
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "internal error",
+			method:  http.MethodGet,
+			path:    path,
+			ownerID: ownerID,
+			err:     errors.New("storage is down"),
+			code:    http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			business.err = tt.err
+			t.Cleanup(func() { business.err = nil })
+
+			w := do(t, handler, tt.ownerID, tt.method, tt.path, tt.body)
+			require.Equal(t, tt.code, w.Code, "must map error to status")
+			require.NotContains(t, w.Body.String(), "storage is down", "must not expose internal error")
+		})
+	}
+}
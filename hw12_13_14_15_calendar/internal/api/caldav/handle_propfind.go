@@ -0,0 +1,141 @@
+package caldav
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+)
+
+// allowedMethods - методы, поддерживаемые ресурсами CalDAV.
+const allowedMethods = "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT"
+
+// HandleWellKnown перенаправляет приложения календарей на путь CalDAV: GET|PROPFIND /.well-known/caldav.
+func (a *App) HandleWellKnown(_ context.Context, _ *http.Request) web.DataResponder {
+	return StatusResponse{
+		code:   http.StatusMovedPermanently,
+		header: http.Header{"Location": {PathPrefix}},
+	}
+}
+
+// HandleOptions сообщает поддерживаемые методы и классы соответствия (RFC 4791, 5.1): OPTIONS /v1/caldav/....
+func (a *App) HandleOptions(_ context.Context, _ *http.Request) web.DataResponder {
+	return StatusResponse{
+		code: http.StatusOK,
+		header: http.Header{
+			"Dav":   {"1, 3, calendar-access"},
+			"Allow": {allowedMethods},
+		},
+	}
+}
+
+// HandlePropfindPrincipal отдаёт свойства владельца: PROPFIND /v1/caldav/.
+// С заголовком Depth: 1 (и infinity) - вместе со свойствами календаря владельца.
+func (a *App) HandlePropfindPrincipal(ctx context.Context, r *http.Request) web.DataResponder {
+	tenantID, ownerID, resp := a.owner(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	selector, err := parsePropfind(r)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	responses := []response{principalResource().response(selector)}
+
+	if depth(r) > 0 {
+		objects, err := a.calendarObjects(ctx, tenantID, ownerID, calendarFrom, calendarTill)
+		if err != nil {
+			return a.errorResponse(ctx, err)
+		}
+
+		responses = append(responses, calendarResource(objects).response(selector))
+	}
+
+	return MultistatusResponse{Responses: responses}
+}
+
+// HandlePropfindCalendar отдаёт свойства календаря владельца: PROPFIND /v1/caldav/events/.
+// С заголовком Depth: 1 (и infinity) - вместе со свойствами всех событий календаря.
+func (a *App) HandlePropfindCalendar(ctx context.Context, r *http.Request) web.DataResponder {
+	tenantID, ownerID, resp := a.owner(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	selector, err := parsePropfind(r)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	objects, err := a.calendarObjects(ctx, tenantID, ownerID, calendarFrom, calendarTill)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	responses := []response{calendarResource(objects).response(selector)}
+
+	if depth(r) > 0 {
+		for _, obj := range objects {
+			responses = append(responses, obj.resource(false).response(selector))
+		}
+	}
+
+	return MultistatusResponse{Responses: responses}
+}
+
+// HandlePropfindObject отдаёт свойства события календаря: PROPFIND /v1/caldav/events/{eventID}.ics.
+func (a *App) HandlePropfindObject(ctx context.Context, r *http.Request) web.DataResponder {
+	tenantID, ownerID, resp := a.owner(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	eventID, err := eventIDFromName(r.PathValue("name"))
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	selector, err := parsePropfind(r)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	event, err := a.business.FindEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	obj, err := newEventObject(event)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	return MultistatusResponse{Responses: []response{obj.resource(false).response(selector)}}
+}
+
+// parsePropfind разбирает тело запроса PROPFIND и возвращает запрошенные свойства.
+func parsePropfind(r *http.Request) (propSelector, error) {
+	data, err := readBody(r, maxRequestSize)
+	if err != nil {
+		return propSelector{}, err
+	}
+
+	var req propfindRequest
+	if err := parseXML(data, &req); err != nil {
+		return propSelector{}, err
+	}
+
+	return newPropSelector(req.AllProp, req.PropName, req.Prop), nil
+}
+
+// depth возвращает глубину запроса PROPFIND из заголовка Depth: 0 или 1.
+// Ресурсы CalDAV вложены не глубже одного уровня, поэтому infinity (и отсутствие заголовка) - 1.
+func depth(r *http.Request) int {
+	if r.Header.Get("Depth") == "0" {
+		return 0
+	}
+
+	return 1
+}
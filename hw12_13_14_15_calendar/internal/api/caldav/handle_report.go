@@ -0,0 +1,184 @@
+package caldav
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// HandleReport выполняет отчёт по календарю владельца: REPORT /v1/caldav/events/.
+// Поддерживаются отчёты calendar-query и calendar-multiget, в ответе - свойства и содержимое событий.
+func (a *App) HandleReport(ctx context.Context, r *http.Request) web.DataResponder {
+	tenantID, ownerID, resp := a.owner(ctx, r)
+	if resp != nil {
+		return resp
+	}
+
+	data, err := readBody(r, maxRequestSize)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	var req reportRequest
+	if err := parseXML(data, &req); err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	selector := newPropSelector(req.AllProp, req.PropName, req.Prop)
+
+	var responses []response
+
+	switch req.XMLName {
+	case reportCalendarQuery:
+		responses, err = a.calendarQuery(ctx, tenantID, ownerID, req, selector)
+	case reportCalendarMultiget:
+		responses, err = a.calendarMultiget(ctx, tenantID, ownerID, req.Hrefs, selector)
+	default:
+		err = errUnsupportedReport
+	}
+
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	return MultistatusResponse{Responses: responses}
+}
+
+// calendarQuery возвращает ресурсы событий, подходящих под фильтр отчёта calendar-query.
+func (a *App) calendarQuery(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	req reportRequest,
+	selector propSelector,
+) ([]response, error) {
+	from, to := calendarFrom, calendarTill
+
+	if req.Filter != nil {
+		var (
+			match bool
+			err   error
+		)
+
+		from, to, match, err = queryPeriod(req.Filter.CompFilter)
+		if err != nil {
+			return nil, err
+		}
+
+		if !match {
+			return nil, nil
+		}
+	}
+
+	objects, err := a.calendarObjects(ctx, tenantID, ownerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]response, len(objects))
+	for i, obj := range objects {
+		responses[i] = obj.resource(true).response(selector)
+	}
+
+	return responses, nil
+}
+
+// queryPeriod возвращает промежуток времени фильтра filter календаря VCALENDAR.
+// match=false - под фильтр не подходит ни одно событие, например, фильтр задач VTODO.
+func queryPeriod(filter compFilter) (from time.Time, to time.Time, match bool, err error) {
+	from, to = calendarFrom, calendarTill
+
+	if filter.Name != "VCALENDAR" {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("%w: %s", errUnsupportedFilter, filter.Name)
+	}
+
+	for _, f := range filter.CompFilters {
+		if f.Name != "VEVENT" {
+			return time.Time{}, time.Time{}, false, nil
+		}
+
+		if f.TimeRange == nil {
+			continue
+		}
+
+		if f.TimeRange.Start != "" {
+			from, err = ical.ParseDateTime(f.TimeRange.Start, nil)
+			if err != nil {
+				return time.Time{}, time.Time{}, false, fmt.Errorf("%w: time-range: %w", errInvalidXML, err)
+			}
+		}
+
+		if f.TimeRange.End != "" {
+			to, err = ical.ParseDateTime(f.TimeRange.End, nil)
+			if err != nil {
+				return time.Time{}, time.Time{}, false, fmt.Errorf("%w: time-range: %w", errInvalidXML, err)
+			}
+		}
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, false, nil
+	}
+
+	return from, to, true, nil
+}
+
+// calendarMultiget возвращает ресурсы событий по их путям hrefs, для отсутствующих событий - ответ 404.
+func (a *App) calendarMultiget(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	hrefs []string,
+	selector propSelector,
+) ([]response, error) {
+	responses := make([]response, 0, len(hrefs))
+
+	for _, href := range hrefs {
+		eventID, ok := hrefEventID(href)
+		if !ok {
+			responses = append(responses, notFoundResponse(href))
+			continue
+		}
+
+		obj, exists, err := a.findObject(ctx, tenantID, ownerID, eventID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			responses = append(responses, notFoundResponse(href))
+			continue
+		}
+
+		responses = append(responses, obj.resource(true).response(selector))
+	}
+
+	return responses, nil
+}
+
+// hrefEventID возвращает идентификатор события по пути (или ссылке) href ресурса события календаря.
+func hrefEventID(href string) (model.ID, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+
+	name, ok := strings.CutPrefix(u.Path, CalendarPath)
+	if !ok || strings.Contains(name, "/") {
+		return "", false
+	}
+
+	eventID, err := eventIDFromName(name)
+	if err != nil {
+		return "", false
+	}
+
+	return eventID, true
+}
@@ -0,0 +1,105 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// testMultistatus - ответ 207 Multi-Status для разбора в тестах.
+type testMultistatus struct {
+	Responses []struct {
+		Href   string `xml:"DAV: href"`
+		Status string `xml:"DAV: status"`
+	} `xml:"DAV: response"`
+}
+
+// calendarQueryReport возвращает отчёт calendar-query с фильтром компонента comp за промежуток [start, end).
+func calendarQueryReport(comp string, start string, end string) string {
+	return `<?xml version="1.0" encoding="utf-8" ?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/></D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="` + comp + `">
+        <C:time-range start="` + start + `" end="` + end + `"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+}
+
+// report выполняет отчёт body и возвращает пути ресурсов из ответа.
+func report(t *testing.T, handler http.Handler, ownerID model.OwnerID, body string) []string {
+	t.Helper()
+
+	w := do(t, handler, ownerID, "REPORT", CalendarPath, body, "Depth", "1")
+	require.Equal(t, http.StatusMultiStatus, w.Code, "must return multistatus")
+
+	var ms testMultistatus
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &ms), "must parse multistatus")
+
+	hrefs := make([]string, len(ms.Responses))
+	for i, resp := range ms.Responses {
+		hrefs[i] = resp.Href
+	}
+
+	return hrefs
+}
+
+func TestHandleReport(t *testing.T) {
+	business := newFakeBusiness()
+	handler := newTestServer(t, business)
+	ownerID := model.NewOwnerID()
+
+	var hrefs []string
+	for _, startAt := range []time.Time{testStartAt, testStartAt.Add(24 * time.Hour)} {
+		eventID := model.NewID()
+		event, err := model.NewEvent(model.DefaultTenantID, eventID, ownerID, "meeting", startAt, startAt.Add(time.Hour))
+		require.NoError(t, err)
+		business.events[eventID] = event
+
+		hrefs = append(hrefs, CalendarPath+string(eventID)+objectExt)
+	}
+
+	// событие другого владельца
+	eventID := model.NewID()
+	event, err := model.NewEvent(
+		model.DefaultTenantID, eventID, model.NewOwnerID(), "meeting", testStartAt, testStartAt.Add(time.Hour),
+	)
+	require.NoError(t, err)
+	business.events[eventID] = event
+
+	t.Run("time range", func(t *testing.T) {
+		got := report(t, handler, ownerID, calendarQueryReport("VEVENT", "20300102T000000Z", "20300103T000000Z"))
+		require.Equal(t, hrefs[:1], got, "must return events of period")
+
+		got = report(t, handler, ownerID, calendarQueryReport("VEVENT", "20300101T000000Z", "20300110T000000Z"))
+		require.Equal(t, hrefs, got, "must return events of period")
+
+		got = report(t, handler, ownerID, calendarQueryReport("VEVENT", "20300103T000000Z", "20300102T000000Z"))
+		require.Empty(t, got, "must return nothing for empty period")
+	})
+
+	t.Run("todo filter", func(t *testing.T) {
+		got := report(t, handler, ownerID, calendarQueryReport("VTODO", "20300101T000000Z", "20300110T000000Z"))
+		require.Empty(t, got, "must not return events for VTODO filter")
+	})
+
+	t.Run("invalid time range", func(t *testing.T) {
+		w := do(t, handler, ownerID, "REPORT", CalendarPath, calendarQueryReport("VEVENT", "tomorrow", ""))
+		require.Equal(t, http.StatusBadRequest, w.Code, "must reject invalid time-range")
+	})
+
+	t.Run("unsupported report", func(t *testing.T) {
+		body := `<?xml version="1.0"?><D:sync-collection xmlns:D="DAV:"/>`
+
+		w := do(t, handler, ownerID, "REPORT", CalendarPath, body)
+		require.Equal(t, http.StatusForbidden, w.Code, "must reject unsupported report")
+	})
+}
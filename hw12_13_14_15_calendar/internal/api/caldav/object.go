@@ -0,0 +1,181 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical/vevent"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// defaultCalendarName - название календаря владельца.
+const defaultCalendarName = "Календарь"
+
+// objectStamp - время изменения событий (DTSTAMP) в ресурсах. События не хранят время изменения,
+// а ресурс с одним и тем же ETag должен выдаваться одинаковым, поэтому время фиксировано.
+var objectStamp = time.Unix(0, 0).UTC()
+
+// eventObject - ресурс события календаря: событие, его представление в формате iCalendar и ETag.
+type eventObject struct {
+	event model.Event
+	data  []byte
+	etag  string
+}
+
+// newEventObject возвращает ресурс события event.
+func newEventObject(event model.Event) (eventObject, error) {
+	calendar := vevent.NewCalendar()
+	calendar.Children = append(calendar.Children, vevent.FromEvent(event, objectStamp))
+
+	var b bytes.Buffer
+	if err := ical.Encode(&b, calendar); err != nil {
+		return eventObject{}, err
+	}
+
+	sum := sha256.Sum256(b.Bytes())
+
+	return eventObject{
+		event: event,
+		data:  b.Bytes(),
+		etag:  `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}, nil
+}
+
+func (o eventObject) href() string {
+	return objectHref(o.event.EventID())
+}
+
+// resource возвращает свойства ресурса события, withData - вместе с содержимым (calendar-data):
+// содержимое возвращается только в ответах REPORT.
+func (o eventObject) resource(withData bool) resource {
+	props := []property{
+		{XMLName: propResourceType},
+		textProp(propGetETag, o.etag),
+		textProp(propGetContentType, ContentType),
+	}
+
+	if withData {
+		props = append(props, textProp(propCalendarData, string(o.data)))
+	}
+
+	return resource{href: o.href(), props: props}
+}
+
+// eventIDFromName возвращает идентификатор события по имени ресурса "{eventID}.ics".
+func eventIDFromName(name string) (model.ID, error) {
+	return model.NewIDFromString(strings.ToLower(strings.TrimSuffix(name, objectExt)))
+}
+
+// findObject находит ресурс события eventID владельца ownerID, exists=false - события нет.
+func (a *App) findObject(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (obj eventObject, exists bool, err error) {
+	event, err := a.business.FindEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		if derr, ok := domainerr.From(err); ok && derr.Kind == domainerr.KindNotFound {
+			return eventObject{}, false, nil
+		}
+
+		return eventObject{}, false, err
+	}
+
+	obj, err = newEventObject(event)
+	if err != nil {
+		return eventObject{}, false, err
+	}
+
+	return obj, true, nil
+}
+
+// calendarObjects возвращает ресурсы всех событий календаря владельца ownerID.
+func (a *App) calendarObjects(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+) ([]eventObject, error) {
+	events, err := a.business.GetEvents(ctx, tenantID, ownerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]eventObject, len(events))
+	for i, event := range events {
+		objects[i], err = newEventObject(event)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
+// principalResource возвращает свойства владельца (principal), он же - коллекция календарей владельца.
+func principalResource() resource {
+	return resource{
+		href: PathPrefix,
+		props: []property{
+			{XMLName: propResourceType, Inner: `<collection xmlns="DAV:"/><principal xmlns="DAV:"/>`},
+			hrefProp(propCurrentPrincipal, PathPrefix),
+			hrefProp(propPrincipalURL, PathPrefix),
+			hrefProp(propCalendarHomeSet, PathPrefix),
+		},
+	}
+}
+
+// calendarResource возвращает свойства календаря владельца с событиями objects.
+// ETag календаря (getctag) изменяется при изменении любого события календаря.
+func calendarResource(objects []eventObject) resource {
+	etags := make([]string, len(objects))
+	for i, obj := range objects {
+		etags[i] = obj.href() + " " + obj.etag
+	}
+
+	slices.Sort(etags)
+
+	sum := sha256.Sum256([]byte(strings.Join(etags, "\n")))
+	ctag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	return resource{
+		href: CalendarPath,
+		props: []property{
+			{
+				XMLName: propResourceType,
+				Inner:   `<collection xmlns="DAV:"/><calendar xmlns="` + nsCalDAV + `"/>`,
+			},
+			textProp(propDisplayName, defaultCalendarName),
+			hrefProp(propCurrentPrincipal, PathPrefix),
+			{
+				XMLName: propComponentSet,
+				Inner:   `<comp xmlns="` + nsCalDAV + `" name="VEVENT"/>`,
+			},
+			{
+				XMLName: propSupportedReportSet,
+				Inner:   supportedReport(reportCalendarQuery) + supportedReport(reportCalendarMultiget),
+			},
+			{
+				XMLName: propPrivilegeSet,
+				Inner:   `<privilege xmlns="DAV:"><read/></privilege><privilege xmlns="DAV:"><write/></privilege>`,
+			},
+			textProp(propGetCTag, ctag),
+			textProp(propGetETag, ctag),
+		},
+	}
+}
+
+func supportedReport(report xml.Name) string {
+	return `<supported-report xmlns="DAV:"><report><` + report.Local + ` xmlns="` + report.Space + `"/></report>` +
+		`</supported-report>`
+}
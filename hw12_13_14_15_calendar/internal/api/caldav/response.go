@@ -0,0 +1,185 @@
+package caldav
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+const (
+	// ContentType - тип содержимого ресурса события.
+	ContentType = "text/calendar; charset=utf-8"
+
+	// xmlContentType - тип содержимого ответов multistatus.
+	xmlContentType = "application/xml; charset=utf-8"
+
+	// WarningHeader - заголовок ответа PUT с предупреждениями о созданном событии, например OUTSIDE_WORKING_HOURS:
+	// по одному значению на предупреждение.
+	WarningHeader = "X-Calendar-Warning"
+)
+
+var (
+	errInvalidXML = web.StatusErrorResponse{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("invalid request body"),
+	}
+	errRequestTooLarge = web.StatusErrorResponse{
+		Code: http.StatusRequestEntityTooLarge,
+		Err:  errors.New("request is too large"),
+	}
+	errPreconditionFailed = web.StatusErrorResponse{
+		Code: http.StatusPreconditionFailed,
+		Err:  errors.New("precondition failed"),
+	}
+	errUnsupportedReport = web.StatusErrorResponse{
+		Code: http.StatusForbidden,
+		Err:  errors.New("unsupported report"),
+	}
+	errUnsupportedFilter = web.StatusErrorResponse{
+		Code: http.StatusForbidden,
+		Err:  errors.New("unsupported filter"),
+	}
+
+	ErrUIDMismatch = domainerr.New(
+		domainerr.KindInvalidArgument,
+		"UID_MISMATCH",
+		"event UID doesn't match resource name",
+	)
+)
+
+// MultistatusResponse - ответ 207 Multi-Status на запросы PROPFIND и REPORT.
+type MultistatusResponse struct {
+	Responses []response
+}
+
+func (r MultistatusResponse) Data() (data []byte, contentType string, err error) {
+	data, err = xml.Marshal(multistatus{Responses: r.Responses})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return append([]byte(xml.Header), data...), xmlContentType, nil
+}
+
+func (r MultistatusResponse) StatusCode() int {
+	return http.StatusMultiStatus
+}
+
+// ObjectResponse - ресурс события в формате iCalendar.
+type ObjectResponse struct {
+	data []byte
+	etag string
+
+	// notModified - у клиента уже есть эта версия ресурса, отвечаем 304 без содержимого.
+	notModified bool
+}
+
+func (r ObjectResponse) Data() (data []byte, contentType string, err error) {
+	if r.notModified {
+		return nil, ContentType, nil
+	}
+
+	return r.data, ContentType, nil
+}
+
+func (r ObjectResponse) StatusCode() int {
+	if r.notModified {
+		return http.StatusNotModified
+	}
+
+	return http.StatusOK
+}
+
+func (r ObjectResponse) Header() http.Header {
+	return http.Header{
+		"Etag":                   {r.etag},
+		"Cache-Control":          {"private, no-cache"},
+		"X-Content-Type-Options": {"nosniff"},
+	}
+}
+
+// StatusResponse - ответ без содержимого с кодом code и заголовками header.
+type StatusResponse struct {
+	code   int
+	header http.Header
+}
+
+func (r StatusResponse) Data() (data []byte, contentType string, err error) {
+	return nil, "text/plain", nil
+}
+
+func (r StatusResponse) StatusCode() int {
+	return r.code
+}
+
+func (r StatusResponse) Header() http.Header {
+	return r.header
+}
+
+// warningsHeader возвращает заголовки ответа с предупреждениями warnings: причина (reason) ошибки
+// предметной области или текст ошибки. Без предупреждений - nil.
+func warningsHeader(warnings []error) http.Header {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	header := http.Header{}
+	for _, w := range warnings {
+		value := w.Error()
+		if derr, ok := domainerr.From(w); ok {
+			value = derr.Reason
+		}
+
+		header.Add(WarningHeader, value)
+	}
+
+	return header
+}
+
+// owner возвращает рабочее пространство и владельца из заголовков X-Tenant-ID и X-Owner-ID.
+// Если владелец не передан, возвращает ответ с ошибкой http.StatusUnauthorized.
+func (a *App) owner(ctx context.Context, r *http.Request) (model.TenantID, model.OwnerID, web.DataResponder) {
+	ownerID, err := auth.ValidateOwner(r.Header.Get("X-Owner-ID"))
+	if err != nil {
+		return "", "", web.StatusErrorResponse{Code: http.StatusUnauthorized, Err: err}
+	}
+
+	tenantID := model.DefaultTenantID
+	if tenant := r.Header.Get("X-Tenant-ID"); tenant != "" {
+		tenantID, err = auth.ValidateTenant(tenant)
+		if err != nil {
+			return "", "", a.errorResponse(ctx, err)
+		}
+	}
+
+	return tenantID, ownerID, nil
+}
+
+// errorResponse возвращает ответ с кодом, соответствующим ошибке err:
+// ответ web.StatusErrorResponse возвращается как есть, для ошибки предметной области - код её вида.
+// Любая другая ошибка добавляется в лог, клиенту возвращается http.StatusInternalServerError.
+func (a *App) errorResponse(ctx context.Context, err error) web.DataResponder {
+	var statusErr web.StatusErrorResponse
+	if errors.As(err, &statusErr) {
+		return statusErr
+	}
+
+	if resp, ok := web.DomainErrorResponse(err); ok {
+		return resp
+	}
+
+	a.logger.ErrorContext(
+		ctx,
+		"error occurred",
+		slog.String("error", err.Error()),
+	)
+
+	return web.ErrorResponse{Err: errors.New("some error")}
+}
@@ -0,0 +1,239 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Пространства имён XML свойств WebDAV и CalDAV.
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	// nsCS - расширения Apple Calendar Server, из них используется getctag.
+	nsCS = "http://calendarserver.org/ns/"
+)
+
+var (
+	propResourceType       = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName        = xml.Name{Space: nsDAV, Local: "displayname"}
+	propCurrentPrincipal   = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL       = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propPrivilegeSet       = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propSupportedReportSet = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propGetETag            = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType     = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propCalendarHomeSet    = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propComponentSet       = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData       = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propGetCTag            = xml.Name{Space: nsCS, Local: "getctag"}
+
+	reportCalendarQuery    = xml.Name{Space: nsCalDAV, Local: "calendar-query"}
+	reportCalendarMultiget = xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}
+)
+
+// propNames - имена запрошенных свойств: содержимое элемента DAV:prop.
+type propNames []xml.Name
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+
+			// значения запрошенных свойств (например, фильтры calendar-data) не поддерживаются
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// propfindRequest - тело запроса PROPFIND (RFC 4918, 14.20). Пустое тело - запрос всех свойств.
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     propNames `xml:"DAV: prop"`
+}
+
+// reportRequest - тело запроса REPORT calendar-query или calendar-multiget (RFC 4791, 9.5 и 9.10).
+type reportRequest struct {
+	XMLName  xml.Name
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     propNames `xml:"DAV: prop"`
+	Hrefs    []string  `xml:"DAV: href"`
+	Filter   *struct {
+		CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// compFilter - фильтр компонентов календаря. Из условий фильтра поддерживается только time-range,
+// остальные условия (prop-filter, is-not-defined) не проверяются: в ответ попадает больше событий.
+type compFilter struct {
+	Name      string `xml:"name,attr"`
+	TimeRange *struct {
+		Start string `xml:"start,attr"`
+		End   string `xml:"end,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	CompFilters []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// propSelector - какие свойства ресурсов возвращать в ответе.
+type propSelector struct {
+	names []xml.Name // запрошенные свойства, nil - все свойства
+	only  bool       // только имена свойств без значений (DAV:propname)
+}
+
+func newPropSelector(allProp *struct{}, propName *struct{}, prop propNames) propSelector {
+	switch {
+	case propName != nil:
+		return propSelector{only: true}
+	case allProp != nil || len(prop) == 0:
+		return propSelector{}
+	default:
+		return propSelector{names: prop}
+	}
+}
+
+// parseXML разбирает тело запроса data в v, пустое тело оставляет v без изменений.
+func parseXML(data []byte, v any) error {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidXML, err)
+	}
+
+	return nil
+}
+
+// property - свойство ресурса: имя и содержимое элемента в виде XML.
+type property struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+// textProp возвращает свойство name с текстовым значением text.
+func textProp(name xml.Name, text string) property {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+
+	return property{XMLName: name, Inner: b.String()}
+}
+
+// hrefProp возвращает свойство name со ссылкой href.
+func hrefProp(name xml.Name, href string) property {
+	return property{XMLName: name, Inner: hrefXML(href)}
+}
+
+func hrefXML(href string) string {
+	var b strings.Builder
+	b.WriteString(`<href xmlns="DAV:">`)
+	_ = xml.EscapeText(&b, []byte(href))
+	b.WriteString(`</href>`)
+
+	return b.String()
+}
+
+// resource - ресурс CalDAV с путём href и его свойствами.
+type resource struct {
+	href  string
+	props []property
+}
+
+// response возвращает ответ multistatus с запрошенными свойствами ресурса:
+// найденные свойства - с кодом 200, неизвестные - с кодом 404.
+func (r resource) response(selector propSelector) response {
+	resp := response{Href: r.href}
+
+	if selector.names == nil {
+		found := make([]property, len(r.props))
+		for i, p := range r.props {
+			if selector.only {
+				p.Inner = ""
+			}
+
+			found[i] = p
+		}
+
+		resp.PropStats = append(resp.PropStats, newPropStat(found, http.StatusOK))
+
+		return resp
+	}
+
+	var found, missing []property
+
+	for _, name := range selector.names {
+		if p, ok := r.prop(name); ok {
+			found = append(found, p)
+		} else {
+			missing = append(missing, property{XMLName: name})
+		}
+	}
+
+	if len(found) > 0 {
+		resp.PropStats = append(resp.PropStats, newPropStat(found, http.StatusOK))
+	}
+
+	if len(missing) > 0 {
+		resp.PropStats = append(resp.PropStats, newPropStat(missing, http.StatusNotFound))
+	}
+
+	return resp
+}
+
+func (r resource) prop(name xml.Name) (property, bool) {
+	for _, p := range r.props {
+		if p.XMLName == name {
+			return p, true
+		}
+	}
+
+	return property{}, false
+}
+
+// multistatus - тело ответа 207 Multi-Status (RFC 4918, 14.16).
+type multistatus struct {
+	XMLName   xml.Name   `xml:"DAV: multistatus"`
+	Responses []response `xml:"response"`
+}
+
+type response struct {
+	Href      string     `xml:"href"`
+	Status    string     `xml:"status,omitempty"`
+	PropStats []propStat `xml:"propstat"`
+}
+
+type propStat struct {
+	Prop struct {
+		Props []property
+	} `xml:"prop"`
+	Status string `xml:"status"`
+}
+
+func newPropStat(props []property, code int) propStat {
+	ps := propStat{Status: statusLine(code)}
+	ps.Prop.Props = props
+
+	return ps
+}
+
+// notFoundResponse возвращает ответ multistatus для отсутствующего ресурса href.
+func notFoundResponse(href string) response {
+	return response{Href: href, Status: statusLine(http.StatusNotFound)}
+}
+
+func statusLine(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
@@ -0,0 +1,94 @@
+package calendar
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+
+	proto "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+type AdminBusiness interface {
+	TenantStats(ctx context.Context, tenantID model.TenantID) (int, error)
+	ExportTenant(ctx context.Context, tenantID model.TenantID) ([]model.Event, error)
+	WipeTenant(ctx context.Context, tenantID model.TenantID) (int, error)
+}
+
+// AdminApp - обработчики grpc-сервера TenantAdminServiceServer.
+// Рабочее пространство передаётся в запросе: доступ к сервису проверяет interceptor.Auth
+// по сертификату сервиса-администратора.
+type AdminApp struct {
+	business AdminBusiness
+	logger   *slog.Logger
+
+	proto.UnimplementedTenantAdminServiceServer
+}
+
+func NewAdminApp(business AdminBusiness, logger *slog.Logger) *AdminApp {
+	return &AdminApp{
+		business: business,
+		logger:   logger,
+	}
+}
+
+func (a *AdminApp) GetTenantStats(
+	ctx context.Context,
+	req *proto.GetTenantStatsRequest,
+) (*proto.GetTenantStatsResponse, error) {
+	tenantID, err := model.NewTenantIDFromString(req.TenantID)
+	if err != nil {
+		return nil, statusError(ctx, a.logger, err, "GetTenantStats", whereAttr("model.NewTenantIDFromString"))
+	}
+
+	count, err := a.business.TenantStats(ctx, tenantID)
+	if err != nil {
+		return nil, statusError(ctx, a.logger, err, "GetTenantStats", whereAttr("business.TenantStats"))
+	}
+
+	return &proto.GetTenantStatsResponse{Events: int64(count)}, nil
+}
+
+func (a *AdminApp) ExportTenantEvents(
+	req *proto.ExportTenantEventsRequest,
+	stream grpc.ServerStreamingServer[proto.ExportTenantEventsResponse],
+) error {
+	ctx := stream.Context()
+
+	tenantID, err := model.NewTenantIDFromString(req.TenantID)
+	if err != nil {
+		return statusError(ctx, a.logger, err, "ExportTenantEvents", whereAttr("model.NewTenantIDFromString"))
+	}
+
+	events, err := a.business.ExportTenant(ctx, tenantID)
+	if err != nil {
+		return statusError(ctx, a.logger, err, "ExportTenantEvents", whereAttr("business.ExportTenant"))
+	}
+
+	for _, event := range events {
+		err := stream.Send(&proto.ExportTenantEventsResponse{
+			OwnerID: string(event.OwnerID()),
+			Event:   modelToProto(event),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *AdminApp) WipeTenant(ctx context.Context, req *proto.WipeTenantRequest) (*proto.WipeTenantResponse, error) {
+	tenantID, err := model.NewTenantIDFromString(req.TenantID)
+	if err != nil {
+		return nil, statusError(ctx, a.logger, err, "WipeTenant", whereAttr("model.NewTenantIDFromString"))
+	}
+
+	n, err := a.business.WipeTenant(ctx, tenantID)
+	if err != nil {
+		return nil, statusError(ctx, a.logger, err, "WipeTenant", whereAttr("business.WipeTenant"))
+	}
+
+	return &proto.WipeTenantResponse{Deleted: int64(n)}, nil
+}
@@ -7,24 +7,112 @@ package calendar
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 
 	proto "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
-	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
 )
 
 type Business interface {
-	CreateEvent(ctx context.Context, event model.Event) error
-	FindEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) (model.Event, error)
+	CreateEvent(ctx context.Context, event model.Event) (warnings []error, err error)
+	FindEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) (model.Event, error)
 	UpdateEvent(ctx context.Context, event model.Event) error
-	DeleteEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) error
-	GetDayEvents(ctx context.Context, ownerID model.OwnerID, year int, month int, day int) ([]model.Event, error)
-	GetWeekEvents(ctx context.Context, ownerID model.OwnerID, year int, month int, day int) ([]model.Event, error)
-	GetMonthEvents(ctx context.Context, ownerID model.OwnerID, year int, month int) ([]model.Event, error)
+	DeleteEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) error
+	GetDayEvents(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		year int,
+		month int,
+		day int,
+		filter model.Filter,
+	) ([]model.Event, error)
+	GetWeekEvents(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		year int,
+		month int,
+		day int,
+		filter model.Filter,
+	) (events []model.Event, holidays []model.Holiday, err error)
+	GetMonthEvents(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		year int,
+		month int,
+		filter model.Filter,
+	) (events []model.Event, holidays []model.Holiday, err error)
+	GetEventsNear(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		from time.Time,
+		to time.Time,
+		area model.Area,
+	) ([]model.Event, error)
+
+	CreateLabel(ctx context.Context, label model.OwnerLabel) error
+	UpdateLabel(ctx context.Context, label model.OwnerLabel) error
+	DeleteLabel(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, name model.Label) error
+	ListLabels(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) ([]model.OwnerLabel, error)
+
+	GetProfile(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) (model.Profile, error)
+	UpdateProfile(ctx context.Context, profile model.Profile) error
+	ListHolidayCalendars(ctx context.Context) []model.HolidayCalendarInfo
+
+	CreateFeedToken(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		name string,
+	) (model.FeedToken, model.FeedSecret, error)
+	ListFeedTokens(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) ([]model.FeedToken, error)
+	RevokeFeedToken(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		tokenID model.FeedTokenID,
+	) error
+
+	AddLink(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		eventID model.ID,
+		attachment model.Attachment,
+	) error
+	UploadFile(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		eventID model.ID,
+		attachment model.Attachment,
+		content io.Reader,
+	) (model.Attachment, error)
+	DeleteAttachment(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		eventID model.ID,
+		attachmentID model.AttachmentID,
+	) error
+	OpenAttachment(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		eventID model.ID,
+		attachmentID model.AttachmentID,
+	) (model.Attachment, io.ReadCloser, error)
 }
 
 type App struct {
@@ -41,27 +129,83 @@ func NewApp(business Business, logger *slog.Logger) *App {
 	}
 }
 
-// handleError проверяет, если ошибка - не ошибка модели, то добавляет ошибку в лог.
-// Возвращает grpc-ошибку со статусом.
+// errorDomain - домен ошибок сервиса для google.rpc.ErrorInfo.
+const errorDomain = "calendar.otus"
+
+// handleError преобразует ошибку err в grpc-ошибку со статусом:
+//   - ошибка предметной области (domainerr.Error) - соответствующий код и детали ErrorInfo (и BadRequest для поля);
+//   - отмена или таймаут контекста - Canceled или DeadlineExceeded;
+//   - любая другая ошибка добавляется в лог, клиенту возвращается Internal.
 func (a *App) handleError(ctx context.Context, err error, handle string, attrs ...any) error {
+	return statusError(ctx, a.logger, err, handle, attrs...)
+}
+
+// statusError преобразует ошибку err в grpc-ошибку, см. App.handleError.
+func statusError(ctx context.Context, logger *slog.Logger, err error, handle string, attrs ...any) error {
+	if derr, ok := domainerr.From(err); ok {
+		return domainStatus(derr, err.Error()).Err()
+	}
+
 	switch {
-	case errors.Is(err, model.ErrInvalidEventID):
-	case errors.Is(err, model.ErrInvalidOwnerID):
-	case errors.Is(err, model.ErrEmptyTitle):
-	case errors.Is(err, model.ErrMaxTitleLen):
-	case errors.Is(err, model.ErrTimeEndBeforeStart):
-	case errors.Is(err, storage.ErrTimeIsBusy):
-	case errors.Is(err, storage.ErrEventAlreadyExists):
-	case errors.Is(err, storage.ErrEventNotFound):
-	default:
-		a.logger.
-			With(append([]any{slog.String("handle", handle)}, attrs...)...).
-			ErrorContext(ctx, "error occurred", slog.String("error", err.Error()))
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	logger.
+		With(append([]any{slog.String("handle", handle)}, attrs...)...).
+		ErrorContext(ctx, "error occurred", slog.String("error", err.Error()))
 
-		return status.Error(codes.Internal, "some error")
+	return status.Error(codes.Internal, "some error")
+}
+
+// domainStatus возвращает grpc-статус с сообщением msg для ошибки предметной области derr.
+func domainStatus(derr *domainerr.Error, msg string) *status.Status {
+	st := status.New(domainCode(derr.Kind), msg)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: derr.Reason,
+			Domain: errorDomain,
+		},
 	}
 
-	return status.Error(codes.InvalidArgument, err.Error())
+	if derr.Field != "" {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{
+					Field:       derr.Field,
+					Description: derr.Error(),
+				},
+			},
+		})
+	}
+
+	stWithDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+
+	return stWithDetails
+}
+
+// domainCode возвращает grpc-код для вида ошибки предметной области.
+func domainCode(kind domainerr.Kind) codes.Code {
+	switch kind {
+	case domainerr.KindInvalidArgument:
+		return codes.InvalidArgument
+	case domainerr.KindNotFound:
+		return codes.NotFound
+	case domainerr.KindAlreadyExists:
+		return codes.AlreadyExists
+	case domainerr.KindFailedPrecondition:
+		return codes.FailedPrecondition
+	case domainerr.KindResourceExhausted:
+		return codes.ResourceExhausted
+	default:
+		return codes.Unknown
+	}
 }
 
 func whereAttr(where string) slog.Attr {
@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/feed"
+	proto "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+func (a *App) CreateFeedToken(
+	ctx context.Context,
+	req *proto.CreateFeedTokenRequest,
+) (*proto.CreateFeedTokenResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateFeedToken", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateFeedToken", whereAttr("OwnerIDFromContext"))
+	}
+
+	name, err := model.NewFeedTokenName(req.Name)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateFeedToken", whereAttr("model.NewFeedTokenName"))
+	}
+
+	token, secret, err := a.business.CreateFeedToken(ctx, tenantID, ownerID, name)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateFeedToken", whereAttr("business.CreateFeedToken"))
+	}
+
+	return &proto.CreateFeedTokenResponse{
+		Token:  feedTokenToProto(token),
+		Secret: string(secret),
+		Path:   feed.URLPath(secret),
+	}, nil
+}
+
+func (a *App) ListFeedTokens(
+	ctx context.Context,
+	_ *proto.ListFeedTokensRequest,
+) (*proto.ListFeedTokensResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "ListFeedTokens", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "ListFeedTokens", whereAttr("OwnerIDFromContext"))
+	}
+
+	tokens, err := a.business.ListFeedTokens(ctx, tenantID, ownerID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "ListFeedTokens", whereAttr("business.ListFeedTokens"))
+	}
+
+	resp := &proto.ListFeedTokensResponse{
+		Tokens: make([]*proto.FeedToken, len(tokens)),
+	}
+
+	for i, token := range tokens {
+		resp.Tokens[i] = feedTokenToProto(token)
+	}
+
+	return resp, nil
+}
+
+func (a *App) RevokeFeedToken(
+	ctx context.Context,
+	req *proto.RevokeFeedTokenRequest,
+) (*proto.RevokeFeedTokenResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "RevokeFeedToken", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "RevokeFeedToken", whereAttr("OwnerIDFromContext"))
+	}
+
+	tokenID, err := model.NewFeedTokenIDFromString(req.TokenID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "RevokeFeedToken", whereAttr("model.NewFeedTokenIDFromString"))
+	}
+
+	err = a.business.RevokeFeedToken(ctx, tenantID, ownerID, tokenID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "RevokeFeedToken", whereAttr("business.RevokeFeedToken"))
+	}
+
+	return &proto.RevokeFeedTokenResponse{}, nil
+}
+
+func feedTokenToProto(token model.FeedToken) *proto.FeedToken {
+	return &proto.FeedToken{
+		TokenID:   string(token.TokenID()),
+		Name:      token.Name,
+		CreatedAt: timestamppb.New(token.CreatedAt),
+	}
+}
@@ -1,8 +1,12 @@
 package calendar
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
 
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	proto "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
@@ -11,38 +15,49 @@ import (
 )
 
 func (a *App) CreateEvent(ctx context.Context, req *proto.CreateEventRequest) (*proto.CreateEventResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateEvent", whereAttr("TenantIDFromContext"))
+	}
+
 	ownerID, err := auth.OwnerIDFromContext(ctx)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "CreateEvent", whereAttr("OwnerIDFromContext"))
 	}
 
-	event, err := protoToModel(req.Event, ownerID)
+	event, err := protoToModel(req.Event, tenantID, ownerID)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "CreateEvent", whereAttr("protoToModel"))
 	}
 
-	err = a.business.CreateEvent(ctx, event)
+	warnings, err := a.business.CreateEvent(ctx, event)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "CreateEvent", whereAttr("business.CreateEvent"))
 	}
 
-	event, err = a.business.FindEvent(ctx, event.OwnerID(), event.EventID())
+	event, err = a.business.FindEvent(ctx, event.TenantID(), event.OwnerID(), event.EventID())
 	if err != nil {
 		return nil, a.handleError(ctx, err, "CreateEvent", whereAttr("business.FindEvent"))
 	}
 
 	return &proto.CreateEventResponse{
-		Event: modelToProto(event),
+		Event:    modelToProto(event),
+		Warnings: warningsToProto(warnings),
 	}, nil
 }
 
 func (a *App) UpdateEvent(ctx context.Context, req *proto.UpdateEventRequest) (*proto.UpdateEventResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateEvent", whereAttr("TenantIDFromContext"))
+	}
+
 	ownerID, err := auth.OwnerIDFromContext(ctx)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "UpdateEvent", whereAttr("OwnerIDFromContext"))
 	}
 
-	event, err := protoToModel(req.Event, ownerID)
+	event, err := protoToModel(req.Event, tenantID, ownerID)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "UpdateEvent", whereAttr("protoToModel"))
 	}
@@ -52,7 +67,7 @@ func (a *App) UpdateEvent(ctx context.Context, req *proto.UpdateEventRequest) (*
 		return nil, a.handleError(ctx, err, "UpdateEvent", whereAttr("business.UpdateEvent"))
 	}
 
-	event, err = a.business.FindEvent(ctx, event.OwnerID(), event.EventID())
+	event, err = a.business.FindEvent(ctx, event.TenantID(), event.OwnerID(), event.EventID())
 	if err != nil {
 		return nil, a.handleError(ctx, err, "UpdateEvent", whereAttr("business.FindEvent"))
 	}
@@ -63,6 +78,11 @@ func (a *App) UpdateEvent(ctx context.Context, req *proto.UpdateEventRequest) (*
 }
 
 func (a *App) DeleteEvent(ctx context.Context, req *proto.DeleteEventRequest) (*proto.DeleteEventResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteEvent", whereAttr("TenantIDFromContext"))
+	}
+
 	ownerID, err := auth.OwnerIDFromContext(ctx)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "DeleteEvent", whereAttr("OwnerIDFromContext"))
@@ -73,7 +93,7 @@ func (a *App) DeleteEvent(ctx context.Context, req *proto.DeleteEventRequest) (*
 		return nil, a.handleError(ctx, err, "DeleteEvent", whereAttr("model.NewIDFromString"))
 	}
 
-	err = a.business.DeleteEvent(ctx, ownerID, eventID)
+	err = a.business.DeleteEvent(ctx, tenantID, ownerID, eventID)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "DeleteEvent", whereAttr("business.DeleteEvent"))
 	}
@@ -82,17 +102,29 @@ func (a *App) DeleteEvent(ctx context.Context, req *proto.DeleteEventRequest) (*
 }
 
 func (a *App) GetDayEvents(ctx context.Context, req *proto.GetDayEventsRequest) (*proto.GetDayEventsResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetDayEvents", whereAttr("TenantIDFromContext"))
+	}
+
 	ownerID, err := auth.OwnerIDFromContext(ctx)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "GetDayEvents", whereAttr("OwnerIDFromContext"))
 	}
 
+	filter, err := protoToFilter(req.Labels)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetDayEvents", whereAttr("protoToFilter"))
+	}
+
 	events, err := a.business.GetDayEvents(
 		ctx,
+		tenantID,
 		ownerID,
 		int(req.Day.Year),
 		int(req.Day.Month),
 		int(req.Day.Day),
+		filter,
 	)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "GetDayEvents", whereAttr("business.GetDayEvents"))
@@ -105,48 +137,369 @@ func (a *App) GetWeekEvents(
 	ctx context.Context,
 	req *proto.GetWeekEventsRequest,
 ) (*proto.GetWeekEventsResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetWeekEvents", whereAttr("TenantIDFromContext"))
+	}
+
 	ownerID, err := auth.OwnerIDFromContext(ctx)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "GetWeekEvents", whereAttr("OwnerIDFromContext"))
 	}
 
-	events, err := a.business.GetWeekEvents(
+	filter, err := protoToFilter(req.Labels)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetWeekEvents", whereAttr("protoToFilter"))
+	}
+
+	events, holidays, err := a.business.GetWeekEvents(
 		ctx,
+		tenantID,
 		ownerID,
 		int(req.StartDay.Year),
 		int(req.StartDay.Month),
 		int(req.StartDay.Day),
+		filter,
 	)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "GetWeekEvents", whereAttr("business.GetWeekEvents"))
 	}
 
-	return &proto.GetWeekEventsResponse{Events: modelsToProto(events)}, nil
+	return &proto.GetWeekEventsResponse{Events: modelsToProto(events), Holidays: holidaysToProto(holidays)}, nil
 }
 
 func (a *App) GetMonthEvents(
 	ctx context.Context,
 	req *proto.GetMonthEventsRequest,
 ) (*proto.GetMonthEventsResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetMonthEvents", whereAttr("TenantIDFromContext"))
+	}
+
 	ownerID, err := auth.OwnerIDFromContext(ctx)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "GetMonthEvents", whereAttr("OwnerIDFromContext"))
 	}
 
-	events, err := a.business.GetMonthEvents(
+	filter, err := protoToFilter(req.Labels)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetMonthEvents", whereAttr("protoToFilter"))
+	}
+
+	events, holidays, err := a.business.GetMonthEvents(
 		ctx,
+		tenantID,
 		ownerID,
 		int(req.Month.Year),
 		int(req.Month.Month),
+		filter,
 	)
 	if err != nil {
 		return nil, a.handleError(ctx, err, "GetMonthEvents", whereAttr("business.GetMonthEvents"))
 	}
 
-	return &proto.GetMonthEventsResponse{Events: modelsToProto(events)}, nil
+	return &proto.GetMonthEventsResponse{Events: modelsToProto(events), Holidays: holidaysToProto(holidays)}, nil
+}
+
+func (a *App) GetEventsNear(
+	ctx context.Context,
+	req *proto.GetEventsNearRequest,
+) (*proto.GetEventsNearResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetEventsNear", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetEventsNear", whereAttr("OwnerIDFromContext"))
+	}
+
+	center, err := model.NewPoint(req.GetCenter().GetLatitude(), req.GetCenter().GetLongitude())
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetEventsNear", whereAttr("NewPoint"))
+	}
+
+	area, err := model.NewArea(center, req.GetRadius())
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetEventsNear", whereAttr("NewArea"))
+	}
+
+	events, err := a.business.GetEventsNear(
+		ctx,
+		tenantID,
+		ownerID,
+		req.GetFrom().AsTime(),
+		req.GetTo().AsTime(),
+		area,
+	)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetEventsNear", whereAttr("business.GetEventsNear"))
+	}
+
+	return &proto.GetEventsNearResponse{Events: modelsToProto(events)}, nil
+}
+
+func (a *App) CreateLabel(ctx context.Context, req *proto.CreateLabelRequest) (*proto.CreateLabelResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateLabel", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateLabel", whereAttr("OwnerIDFromContext"))
+	}
+
+	label, err := protoToLabel(req.Label, tenantID, ownerID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateLabel", whereAttr("protoToLabel"))
+	}
+
+	err = a.business.CreateLabel(ctx, label)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "CreateLabel", whereAttr("business.CreateLabel"))
+	}
+
+	return &proto.CreateLabelResponse{
+		Label: labelToProto(label),
+	}, nil
+}
+
+func (a *App) UpdateLabel(ctx context.Context, req *proto.UpdateLabelRequest) (*proto.UpdateLabelResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateLabel", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateLabel", whereAttr("OwnerIDFromContext"))
+	}
+
+	label, err := protoToLabel(req.Label, tenantID, ownerID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateLabel", whereAttr("protoToLabel"))
+	}
+
+	err = a.business.UpdateLabel(ctx, label)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateLabel", whereAttr("business.UpdateLabel"))
+	}
+
+	return &proto.UpdateLabelResponse{
+		Label: labelToProto(label),
+	}, nil
+}
+
+func (a *App) DeleteLabel(ctx context.Context, req *proto.DeleteLabelRequest) (*proto.DeleteLabelResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteLabel", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteLabel", whereAttr("OwnerIDFromContext"))
+	}
+
+	name, err := model.NewLabel(req.Name)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteLabel", whereAttr("model.NewLabel"))
+	}
+
+	err = a.business.DeleteLabel(ctx, tenantID, ownerID, name)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteLabel", whereAttr("business.DeleteLabel"))
+	}
+
+	return &proto.DeleteLabelResponse{}, nil
+}
+
+func (a *App) ListLabels(ctx context.Context, _ *proto.ListLabelsRequest) (*proto.ListLabelsResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "ListLabels", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "ListLabels", whereAttr("OwnerIDFromContext"))
+	}
+
+	labels, err := a.business.ListLabels(ctx, tenantID, ownerID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "ListLabels", whereAttr("business.ListLabels"))
+	}
+
+	protoLabels := make([]*proto.Label, len(labels))
+	for i := range labels {
+		protoLabels[i] = labelToProto(labels[i])
+	}
+
+	return &proto.ListLabelsResponse{Labels: protoLabels}, nil
+}
+
+func (a *App) AddLink(ctx context.Context, req *proto.AddLinkRequest) (*proto.AddLinkResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "AddLink", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "AddLink", whereAttr("OwnerIDFromContext"))
+	}
+
+	eventID, err := model.NewIDFromString(req.EventID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "AddLink", whereAttr("model.NewIDFromString"))
+	}
+
+	attachment, err := model.NewLinkAttachment(req.Title, req.URL)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "AddLink", whereAttr("model.NewLinkAttachment"))
+	}
+
+	err = a.business.AddLink(ctx, tenantID, ownerID, eventID, attachment)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "AddLink", whereAttr("business.AddLink"))
+	}
+
+	return &proto.AddLinkResponse{
+		Attachment: attachmentToProto(attachment),
+	}, nil
 }
 
-func protoToModel(p *proto.Event, ownerID model.OwnerID) (model.Event, error) {
+func (a *App) UploadFile(ctx context.Context, req *proto.UploadFileRequest) (*proto.UploadFileResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UploadFile", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UploadFile", whereAttr("OwnerIDFromContext"))
+	}
+
+	eventID, err := model.NewIDFromString(req.EventID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UploadFile", whereAttr("model.NewIDFromString"))
+	}
+
+	attachment, err := model.NewFileAttachment(req.Title, req.FileName, req.ContentType)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UploadFile", whereAttr("model.NewFileAttachment"))
+	}
+
+	attachment, err = a.business.UploadFile(ctx, tenantID, ownerID, eventID, attachment, bytes.NewReader(req.Content))
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UploadFile", whereAttr("business.UploadFile"))
+	}
+
+	return &proto.UploadFileResponse{
+		Attachment: attachmentToProto(attachment),
+	}, nil
+}
+
+func (a *App) DeleteAttachment(
+	ctx context.Context,
+	req *proto.DeleteAttachmentRequest,
+) (*proto.DeleteAttachmentResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteAttachment", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteAttachment", whereAttr("OwnerIDFromContext"))
+	}
+
+	eventID, err := model.NewIDFromString(req.EventID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteAttachment", whereAttr("model.NewIDFromString"))
+	}
+
+	attachmentID, err := model.NewAttachmentIDFromString(req.AttachmentID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteAttachment", whereAttr("model.NewAttachmentIDFromString"))
+	}
+
+	err = a.business.DeleteAttachment(ctx, tenantID, ownerID, eventID, attachmentID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "DeleteAttachment", whereAttr("business.DeleteAttachment"))
+	}
+
+	return &proto.DeleteAttachmentResponse{}, nil
+}
+
+// downloadChunkSize - размер части содержимого файла в одном сообщении потока DownloadAttachment.
+const downloadChunkSize = 32 * 1024
+
+func (a *App) DownloadAttachment(
+	req *proto.DownloadAttachmentRequest,
+	stream grpc.ServerStreamingServer[proto.DownloadAttachmentResponse],
+) error {
+	ctx := stream.Context()
+
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return a.handleError(ctx, err, "DownloadAttachment", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return a.handleError(ctx, err, "DownloadAttachment", whereAttr("OwnerIDFromContext"))
+	}
+
+	eventID, err := model.NewIDFromString(req.EventID)
+	if err != nil {
+		return a.handleError(ctx, err, "DownloadAttachment", whereAttr("model.NewIDFromString"))
+	}
+
+	attachmentID, err := model.NewAttachmentIDFromString(req.AttachmentID)
+	if err != nil {
+		return a.handleError(ctx, err, "DownloadAttachment", whereAttr("model.NewAttachmentIDFromString"))
+	}
+
+	attachment, content, err := a.business.OpenAttachment(ctx, tenantID, ownerID, eventID, attachmentID)
+	if err != nil {
+		return a.handleError(ctx, err, "DownloadAttachment", whereAttr("business.OpenAttachment"))
+	}
+	defer content.Close()
+
+	err = stream.Send(&proto.DownloadAttachmentResponse{
+		Data: &proto.DownloadAttachmentResponse_Attachment{Attachment: attachmentToProto(attachment)},
+	})
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, downloadChunkSize)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			sendErr := stream.Send(&proto.DownloadAttachmentResponse{
+				Data: &proto.DownloadAttachmentResponse_Chunk{Chunk: buf[:n]},
+			})
+			if sendErr != nil {
+				return sendErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return a.handleError(ctx, err, "DownloadAttachment", whereAttr("content.Read"))
+		}
+	}
+}
+
+func protoToModel(p *proto.Event, tenantID model.TenantID, ownerID model.OwnerID) (model.Event, error) {
 	eventID, err := model.NewIDFromString(p.EventID)
 	if err != nil {
 		return model.Event{}, err
@@ -157,7 +510,7 @@ func protoToModel(p *proto.Event, ownerID model.OwnerID) (model.Event, error) {
 		return model.Event{}, err
 	}
 
-	ev, err := model.NewEvent(eventID, ownerID, title, p.StartAt.AsTime(), p.EndAt.AsTime())
+	ev, err := model.NewEvent(tenantID, eventID, ownerID, title, p.StartAt.AsTime(), p.EndAt.AsTime())
 	if err != nil {
 		return model.Event{}, err
 	}
@@ -165,17 +518,125 @@ func protoToModel(p *proto.Event, ownerID model.OwnerID) (model.Event, error) {
 	ev.Description = p.Description
 	ev.NotifyBefore = uint(p.NotifyBefore)
 
+	ev.Labels, err = model.NewLabels(p.Labels)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	ev.Color, err = model.NewColor(p.Color)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	ev.Location, err = protoToLocation(p.Location)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	ev.ConferenceURL, err = model.NewConferenceURL(p.ConferenceURL)
+	if err != nil {
+		return model.Event{}, err
+	}
+
 	return ev, nil
 }
 
 func modelToProto(event model.Event) *proto.Event {
 	return &proto.Event{
-		EventID:      string(event.EventID()),
-		StartAt:      timestamppb.New(event.StartAt()),
-		EndAt:        timestamppb.New(event.EndAt()),
-		Title:        string(event.Title),
-		Description:  event.Description,
-		NotifyBefore: uint32(event.NotifyBefore),
+		EventID:       string(event.EventID()),
+		StartAt:       timestamppb.New(event.StartAt()),
+		EndAt:         timestamppb.New(event.EndAt()),
+		Title:         string(event.Title),
+		Description:   event.Description,
+		NotifyBefore:  uint32(event.NotifyBefore),
+		Labels:        labelsToProto(event.Labels),
+		Color:         string(event.Color),
+		Attachments:   attachmentsToProto(event.Attachments),
+		Location:      locationToProto(event.Location),
+		ConferenceURL: string(event.ConferenceURL),
+	}
+}
+
+// protoToLocation возвращает место проведения события из запроса, nil - место не задано.
+func protoToLocation(p *proto.Location) (model.Location, error) {
+	if p == nil {
+		return model.Location{}, nil
+	}
+
+	var point *model.Point
+	if p.Point != nil {
+		pt, err := model.NewPoint(p.Point.Latitude, p.Point.Longitude)
+		if err != nil {
+			return model.Location{}, err
+		}
+
+		point = &pt
+	}
+
+	return model.NewLocation(p.Name, p.Address, point)
+}
+
+func locationToProto(location model.Location) *proto.Location {
+	if location.IsZero() {
+		return nil
+	}
+
+	p := &proto.Location{
+		Name:    location.Name,
+		Address: location.Address,
+	}
+
+	if location.Point != nil {
+		p.Point = &proto.GeoPoint{
+			Latitude:  location.Point.Latitude,
+			Longitude: location.Point.Longitude,
+		}
+	}
+
+	return p
+}
+
+func labelsToProto(labels []model.Label) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(labels))
+	for i, label := range labels {
+		names[i] = string(label)
+	}
+
+	return names
+}
+
+// protoToFilter возвращает фильтр событий по меткам labels из запроса.
+func protoToFilter(labels []string) (model.Filter, error) {
+	filterLabels, err := model.NewLabels(labels)
+	if err != nil {
+		return model.Filter{}, err
+	}
+
+	return model.Filter{Labels: filterLabels}, nil
+}
+
+func protoToLabel(p *proto.Label, tenantID model.TenantID, ownerID model.OwnerID) (model.OwnerLabel, error) {
+	name, err := model.NewLabel(p.GetName())
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	color, err := model.NewColor(p.GetColor())
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	return model.NewOwnerLabel(tenantID, ownerID, name, color), nil
+}
+
+func labelToProto(label model.OwnerLabel) *proto.Label {
+	return &proto.Label{
+		Name:  string(label.Name()),
+		Color: string(label.Color),
 	}
 }
 
@@ -187,3 +648,27 @@ func modelsToProto(events []model.Event) []*proto.Event {
 
 	return protoEvents
 }
+
+func attachmentToProto(attachment model.Attachment) *proto.Attachment {
+	return &proto.Attachment{
+		AttachmentID: string(attachment.ID),
+		Title:        attachment.Title,
+		URL:          attachment.URL,
+		FileName:     attachment.FileName,
+		ContentType:  attachment.ContentType,
+		Size:         attachment.Size,
+	}
+}
+
+func attachmentsToProto(attachments []model.Attachment) []*proto.Attachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	protoAttachments := make([]*proto.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		protoAttachments[i] = attachmentToProto(attachment)
+	}
+
+	return protoAttachments
+}
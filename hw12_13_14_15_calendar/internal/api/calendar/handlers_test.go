@@ -5,6 +5,8 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -12,15 +14,22 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	proto "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
 	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	localBlob "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob/local"
 	modelStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
 	memoryStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
 	pgStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/pg"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/holiday"
 )
 
 const envVarName = "TEST_STORAGE_PG"
@@ -48,14 +57,19 @@ func (s *APITestSuite) SetupSuite() {
 
 	if dataSource != "" {
 		var err error
-		storage, err = pgStorage.NewStorage(dataSource)
+		storage, err = pgStorage.NewStorage(pgStorage.Options{DataSource: dataSource})
 		s.Require().NoError(err, "must connect")
 	} else {
 		storage = memoryStorage.NewStorage()
 	}
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	business := calendarBusiness.NewApp(logger, storage)
+	business := calendarBusiness.NewApp(logger, clock.Real{}, storage)
+
+	blobs, err := localBlob.NewStore(s.T().TempDir())
+	s.Require().NoError(err, "must create blob store")
+	business.SetAttachments(blobs, calendarBusiness.AttachmentLimits{MaxFileSize: 16, MaxPerEvent: 3})
+
 	app := NewApp(business, logger)
 
 	s.app = app
@@ -66,8 +80,7 @@ func (s *APITestSuite) SetupSuite() {
 func (s *APITestSuite) CreateEvent(startAt time.Time, endAt time.Time) (*proto.Event, model.Event) {
 	eventStr := uuid.NewString()
 
-	ctx, err := auth.WithOwnerID(context.Background(), string(s.ownerID))
-	s.Require().NoError(err, "auth.WithOwnerID must not have error")
+	ctx := s.authContext(model.DefaultTenantID)
 
 	ownerID, err := auth.OwnerIDFromContext(ctx)
 	s.Require().NoError(err, "auth.OwnerIDFromContext must not have error")
@@ -92,7 +105,7 @@ func (s *APITestSuite) CreateEvent(startAt time.Time, endAt time.Time) (*proto.E
 	_, err = s.app.CreateEvent(ctx, req)
 	s.Require().NoError(err, "app.CreateEvent must not have error")
 
-	event, err := s.storage.FindEvent(ctx, ownerID, model.ID(eventStr))
+	event, err := s.storage.FindEvent(ctx, model.DefaultTenantID, ownerID, model.ID(eventStr))
 	s.Require().NoError(err, "must not have error")
 
 	s.Require().True(event.StartAt().Equal(startAt), "event.StartAt must equal")
@@ -122,15 +135,14 @@ func (s *APITestSuite) Test_UpdateEvent() {
 
 	s.Require().NotNil(protoEvent, "protoEvent must not be nil")
 
-	ctx, err := auth.WithOwnerID(context.Background(), string(s.ownerID))
-	s.Require().NoError(err, "auth.WithOwnerID must not have error")
+	ctx := s.authContext(model.DefaultTenantID)
 
 	protoEvent.Title = "changed title"
 	req := &proto.UpdateEventRequest{Event: protoEvent}
-	_, err = s.app.UpdateEvent(ctx, req)
+	_, err := s.app.UpdateEvent(ctx, req)
 	s.Require().NoError(err, "app.UpdateEvent must not have error")
 
-	storageEvent, err := s.storage.FindEvent(ctx, s.ownerID, event.EventID())
+	storageEvent, err := s.storage.FindEvent(ctx, model.DefaultTenantID, s.ownerID, event.EventID())
 	s.Require().NoError(err, "must not have error")
 
 	event.Title = model.Title("changed title")
@@ -145,8 +157,7 @@ func (s *APITestSuite) Test_DeleteEvent() {
 	})
 	s.Require().NotNil(protoEvent, "protoEvent must not be nil")
 
-	ctx, err := auth.WithOwnerID(context.Background(), string(s.ownerID))
-	s.Require().NoError(err, "auth.WithOwnerID must not have error")
+	ctx := s.authContext(model.DefaultTenantID)
 
 	{
 		s.mx.Lock()
@@ -156,7 +167,7 @@ func (s *APITestSuite) Test_DeleteEvent() {
 			_, err := s.app.DeleteEvent(ctx, req)
 			s.Require().NoError(err, "app.DeleteEvent must not have error")
 
-			_, err = s.storage.FindEvent(ctx, s.ownerID, eventID)
+			_, err = s.storage.FindEvent(ctx, model.DefaultTenantID, s.ownerID, eventID)
 			s.Require().Error(err, "must have error")
 			s.Require().ErrorIs(err, modelStorage.ErrEventNotFound, "must have model.ErrEventNotFound error")
 		}
@@ -166,6 +177,7 @@ func (s *APITestSuite) Test_DeleteEvent() {
 
 			_, err := s.app.DeleteEvent(ctx, req)
 			s.Require().Error(err, "app.DeleteEvent must have error after second delete")
+			s.requireStatus(err, codes.NotFound, "EVENT_NOT_FOUND", "")
 		}
 
 		s.eventIDs = []model.ID{}
@@ -185,8 +197,7 @@ func (s *APITestSuite) Test_GetDayEvents() {
 	})
 	s.Require().Len(events, 60, "must be created 60 events")
 
-	ctx, err := auth.WithOwnerID(context.Background(), string(s.ownerID))
-	s.Require().NoError(err, "auth.WithOwnerID must not have error")
+	ctx := s.authContext(model.DefaultTenantID)
 
 	for _, event := range events {
 		req := &proto.GetDayEventsRequest{
@@ -202,7 +213,7 @@ func (s *APITestSuite) Test_GetDayEvents() {
 		s.Require().Len(resp.Events, 1, "must be 1 event in day")
 
 		for _, e := range resp.Events {
-			respEvent, err := protoToModel(e, s.ownerID)
+			respEvent, err := protoToModel(e, model.DefaultTenantID, s.ownerID)
 			s.Require().NoError(err, "protoToModel must not have error")
 
 			s.Require().Equal(event, respEvent, "event from response must be equal")
@@ -222,8 +233,7 @@ func (s *APITestSuite) Test_GetWeekEvents() {
 	})
 	s.Require().Len(events, 60, "must be created 60 events")
 
-	ctx, err := auth.WithOwnerID(context.Background(), string(s.ownerID))
-	s.Require().NoError(err, "auth.WithOwnerID must not have error")
+	ctx := s.authContext(model.DefaultTenantID)
 
 	for i, event := range events {
 		req := &proto.GetWeekEventsRequest{
@@ -241,7 +251,7 @@ func (s *APITestSuite) Test_GetWeekEvents() {
 		s.Require().Lenf(resp.Events, n, "must be %d events in week", n)
 
 		for j, re := range resp.Events {
-			respEvent, err := protoToModel(re, s.ownerID)
+			respEvent, err := protoToModel(re, model.DefaultTenantID, s.ownerID)
 			s.Require().NoError(err, "protoToModel must not have error")
 
 			s.Require().Equal(events[i+j], respEvent, "event from response must be equal")
@@ -264,8 +274,7 @@ func (s *APITestSuite) Test_GetMonthEvents() {
 	})
 	s.Require().Len(events, 60, "must be created 60 events")
 
-	ctx, err := auth.WithOwnerID(context.Background(), string(s.ownerID))
-	s.Require().NoError(err, "auth.WithOwnerID must not have error")
+	ctx := s.authContext(model.DefaultTenantID)
 
 	for _, event := range events {
 		req := &proto.GetMonthEventsRequest{
@@ -286,3 +295,738 @@ func (s *APITestSuite) Test_GetMonthEvents() {
 		)
 	}
 }
+
+func (s *APITestSuite) Test_Errors() {
+	ctx := s.authContext(model.DefaultTenantID)
+
+	s.Run("invalid event id", func() {
+		_, err := s.app.DeleteEvent(ctx, &proto.DeleteEventRequest{EventID: "invalid"})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_EVENT_ID", "event_id")
+	})
+
+	s.Run("empty title", func() {
+		when := time.Now().Add(time.Hour * 240)
+		req := &proto.CreateEventRequest{
+			Event: &proto.Event{
+				EventID: uuid.NewString(),
+				StartAt: timestamppb.New(when),
+				EndAt:   timestamppb.New(when.Add(time.Hour)),
+			},
+		}
+
+		_, err := s.app.CreateEvent(ctx, req)
+		s.requireStatus(err, codes.InvalidArgument, "EMPTY_TITLE", "title")
+	})
+
+	s.Run("time is busy", func() {
+		when := time.Now().Add(time.Hour * 264)
+		protoEvent, _ := s.CreateEvent(when, when.Add(2*time.Hour))
+
+		req := &proto.CreateEventRequest{
+			Event: &proto.Event{
+				EventID: uuid.NewString(),
+				StartAt: timestamppb.New(when.Add(time.Hour)),
+				EndAt:   timestamppb.New(when.Add(3 * time.Hour)),
+				Title:   "busy",
+			},
+		}
+
+		_, err := s.app.CreateEvent(ctx, req)
+		s.requireStatus(err, codes.FailedPrecondition, "TIME_IS_BUSY", "")
+
+		req.Event = protoEvent
+		_, err = s.app.CreateEvent(ctx, req)
+		s.requireStatus(err, codes.AlreadyExists, "EVENT_ALREADY_EXISTS", "")
+	})
+}
+
+func (s *APITestSuite) Test_Tenants() {
+	when := time.Now().Add(time.Hour * 288)
+	protoEvent, event := s.CreateEvent(when, when.Add(2*time.Hour))
+
+	ctx := s.authContext("hr")
+
+	s.Run("isolated", func() {
+		resp, err := s.app.GetDayEvents(ctx, &proto.GetDayEventsRequest{
+			Day: &proto.Date{
+				Year:  int32(when.Year()),
+				Month: int32(when.Month()),
+				Day:   int32(when.Day()),
+			},
+		})
+		s.Require().NoError(err, "app.GetDayEvents must not have error")
+		s.Require().Empty(resp.Events, "must not have events of other tenant")
+
+		_, err = s.app.DeleteEvent(ctx, &proto.DeleteEventRequest{EventID: protoEvent.EventID})
+		s.requireStatus(err, codes.NotFound, "EVENT_NOT_FOUND", "")
+	})
+
+	s.Run("same event in other tenant", func() {
+		resp, err := s.app.CreateEvent(ctx, &proto.CreateEventRequest{Event: protoEvent})
+		s.Require().NoError(err, "app.CreateEvent must not have error")
+		s.Require().Equal(protoEvent.EventID, resp.Event.EventID, "must create event with same id")
+
+		_, err = s.storage.FindEvent(ctx, "hr", s.ownerID, event.EventID())
+		s.Require().NoError(err, "must find event in tenant")
+	})
+
+	s.Run("missed tenant", func() {
+		ctx, err := auth.WithOwnerID(context.Background(), string(s.ownerID))
+		s.Require().NoError(err, "auth.WithOwnerID must not have error")
+
+		_, err = s.app.DeleteEvent(ctx, &proto.DeleteEventRequest{EventID: protoEvent.EventID})
+		s.Require().Equal(codes.Internal, status.Code(err), "must fail without tenant")
+	})
+}
+
+func (s *APITestSuite) Test_TenantQuota() {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	business := calendarBusiness.NewApp(logger, clock.Real{}, memoryStorage.NewStorage())
+	business.SetQuotas(calendarBusiness.Quotas{
+		MaxEvents: 1,
+		Tenants:   map[model.TenantID]int{"hr": 0},
+	})
+	app := NewApp(business, logger)
+
+	when := time.Now().Add(time.Hour * 312)
+	createEvent := func(ctx context.Context, startAt time.Time) error {
+		_, err := app.CreateEvent(ctx, &proto.CreateEventRequest{
+			Event: &proto.Event{
+				EventID: uuid.NewString(),
+				StartAt: timestamppb.New(startAt),
+				EndAt:   timestamppb.New(startAt.Add(time.Hour)),
+				Title:   "quota",
+			},
+		})
+
+		return err
+	}
+
+	ctx := s.authContext(model.DefaultTenantID)
+	s.Require().NoError(createEvent(ctx, when), "must create event within quota")
+	s.requireStatus(createEvent(ctx, when.Add(2*time.Hour)), codes.ResourceExhausted, "TENANT_QUOTA_EXCEEDED", "")
+
+	ctx = s.authContext("hr")
+	s.Require().NoError(createEvent(ctx, when), "must create event in unlimited tenant")
+	s.Require().NoError(createEvent(ctx, when.Add(2*time.Hour)), "must create event in unlimited tenant")
+}
+
+func (s *APITestSuite) Test_Labels() {
+	// отдельное рабочее пространство, чтобы метки не влияли на другие тесты
+	ctx := s.authContext("design")
+	when := time.Now().Add(time.Hour * 336)
+
+	s.Run("catalog", func() {
+		for _, label := range []*proto.Label{{Name: "work", Color: "#FF0000"}, {Name: "home"}} {
+			resp, err := s.app.CreateLabel(ctx, &proto.CreateLabelRequest{Label: label})
+			s.Require().NoError(err, "app.CreateLabel must not have error")
+			s.Require().Equal(strings.ToLower(label.Color), resp.Label.Color, "must normalize color")
+		}
+
+		_, err := s.app.CreateLabel(ctx, &proto.CreateLabelRequest{Label: &proto.Label{Name: "work"}})
+		s.requireStatus(err, codes.AlreadyExists, "LABEL_ALREADY_EXISTS", "")
+
+		_, err = s.app.CreateLabel(ctx, &proto.CreateLabelRequest{Label: &proto.Label{Name: "Work"}})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_LABEL", "labels")
+
+		_, err = s.app.CreateLabel(ctx, &proto.CreateLabelRequest{Label: &proto.Label{Name: "red", Color: "red"}})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_COLOR", "color")
+
+		_, err = s.app.UpdateLabel(ctx, &proto.UpdateLabelRequest{Label: &proto.Label{Name: "home", Color: "#00ff00"}})
+		s.Require().NoError(err, "app.UpdateLabel must not have error")
+
+		_, err = s.app.UpdateLabel(ctx, &proto.UpdateLabelRequest{Label: &proto.Label{Name: "unknown"}})
+		s.requireStatus(err, codes.NotFound, "LABEL_NOT_FOUND", "")
+
+		resp, err := s.app.ListLabels(ctx, &proto.ListLabelsRequest{})
+		s.Require().NoError(err, "app.ListLabels must not have error")
+		s.Require().Len(resp.Labels, 2, "must list labels")
+		s.Require().Equal("home", resp.Labels[0].Name, "must order labels by name")
+		s.Require().Equal("#00ff00", resp.Labels[0].Color, "must update label")
+	})
+
+	day := &proto.Date{
+		Year:  int32(when.Year()),
+		Month: int32(when.Month()),
+		Day:   int32(when.Day()),
+	}
+
+	s.Run("events", func() {
+		for i, labels := range [][]string{{"work", "home"}, {"home"}, nil} {
+			startAt := when.Add(time.Duration(i) * time.Minute)
+			resp, err := s.app.CreateEvent(ctx, &proto.CreateEventRequest{
+				Event: &proto.Event{
+					EventID: uuid.NewString(),
+					StartAt: timestamppb.New(startAt),
+					EndAt:   timestamppb.New(startAt.Add(time.Minute)),
+					Title:   "labeled",
+					Labels:  labels,
+					Color:   "#0000FF",
+				},
+			})
+			s.Require().NoError(err, "app.CreateEvent must not have error")
+			s.Require().Equal("#0000ff", resp.Event.Color, "must keep color")
+		}
+
+		_, err := s.app.CreateEvent(ctx, &proto.CreateEventRequest{
+			Event: &proto.Event{
+				EventID: uuid.NewString(),
+				StartAt: timestamppb.New(when.Add(time.Hour)),
+				EndAt:   timestamppb.New(when.Add(2 * time.Hour)),
+				Title:   "unknown label",
+				Labels:  []string{"unknown"},
+			},
+		})
+		s.requireStatus(err, codes.InvalidArgument, "UNKNOWN_LABEL", "labels")
+
+		resp, err := s.app.GetDayEvents(ctx, &proto.GetDayEventsRequest{Day: day, Labels: []string{"work"}})
+		s.Require().NoError(err, "app.GetDayEvents must not have error")
+		s.Require().Len(resp.Events, 1, "must filter events by label")
+		s.Require().Equal([]string{"home", "work"}, resp.Events[0].Labels, "must return sorted labels")
+
+		resp, err = s.app.GetDayEvents(ctx, &proto.GetDayEventsRequest{Day: day, Labels: []string{"home"}})
+		s.Require().NoError(err, "app.GetDayEvents must not have error")
+		s.Require().Len(resp.Events, 2, "must filter events by label")
+
+		_, err = s.app.GetDayEvents(ctx, &proto.GetDayEventsRequest{Day: day, Labels: []string{"Home"}})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_LABEL", "labels")
+	})
+
+	s.Run("delete", func() {
+		_, err := s.app.DeleteLabel(ctx, &proto.DeleteLabelRequest{Name: "work"})
+		s.Require().NoError(err, "app.DeleteLabel must not have error")
+
+		_, err = s.app.DeleteLabel(ctx, &proto.DeleteLabelRequest{Name: "work"})
+		s.requireStatus(err, codes.NotFound, "LABEL_NOT_FOUND", "")
+
+		resp, err := s.app.GetDayEvents(ctx, &proto.GetDayEventsRequest{Day: day, Labels: []string{"home"}})
+		s.Require().NoError(err, "app.GetDayEvents must not have error")
+		s.Require().Len(resp.Events, 2, "must keep other labels")
+		s.Require().Equal([]string{"home"}, resp.Events[0].Labels, "must remove deleted label from events")
+	})
+}
+
+func (s *APITestSuite) Test_Location() {
+	// отдельное рабочее пространство, чтобы события не влияли на другие тесты
+	ctx := s.authContext("geo")
+	when := time.Now().Add(time.Hour * 504)
+
+	office := &proto.GeoPoint{Latitude: 55.7558, Longitude: 37.6173}
+
+	var created *proto.Event
+	s.Run("create", func() {
+		resp, err := s.app.CreateEvent(ctx, &proto.CreateEventRequest{
+			Event: &proto.Event{
+				EventID: uuid.NewString(),
+				StartAt: timestamppb.New(when),
+				EndAt:   timestamppb.New(when.Add(time.Hour)),
+				Title:   "meeting",
+				Location: &proto.Location{
+					Name:    "room 3",
+					Address: "Tverskaya 1",
+					Point:   office,
+				},
+				ConferenceURL: "https://meet.example.com/abc",
+			},
+		})
+		s.Require().NoError(err, "app.CreateEvent must not have error")
+		s.Require().Equal("room 3", resp.Event.Location.Name, "must keep location")
+		s.Require().Equal("https://meet.example.com/abc", resp.Event.ConferenceURL, "must keep conference URL")
+
+		created = resp.Event
+
+		resp, err = s.app.CreateEvent(ctx, &proto.CreateEventRequest{
+			Event: &proto.Event{
+				EventID:  uuid.NewString(),
+				StartAt:  timestamppb.New(when.Add(time.Hour)),
+				EndAt:    timestamppb.New(when.Add(2 * time.Hour)),
+				Title:    "far away",
+				Location: &proto.Location{Point: &proto.GeoPoint{Latitude: 59.9343, Longitude: 30.3351}},
+			},
+		})
+		s.Require().NoError(err, "app.CreateEvent must not have error")
+		s.Require().Empty(resp.Event.Location.Name, "must not have location name")
+	})
+
+	s.Run("errors", func() {
+		event := &proto.Event{
+			EventID:  uuid.NewString(),
+			StartAt:  timestamppb.New(when.Add(3 * time.Hour)),
+			EndAt:    timestamppb.New(when.Add(4 * time.Hour)),
+			Title:    "invalid",
+			Location: &proto.Location{Point: &proto.GeoPoint{Latitude: 91}},
+		}
+		_, err := s.app.CreateEvent(ctx, &proto.CreateEventRequest{Event: event})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_LATITUDE", "latitude")
+
+		event.Location = nil
+		event.ConferenceURL = "ftp://meet.example.com"
+		_, err = s.app.CreateEvent(ctx, &proto.CreateEventRequest{Event: event})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_CONFERENCE_URL", "conference_url")
+
+		_, err = s.app.GetEventsNear(ctx, &proto.GetEventsNearRequest{
+			Center: office,
+			Radius: 0,
+			From:   timestamppb.New(when),
+			To:     timestamppb.New(when.Add(time.Hour)),
+		})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_RADIUS", "radius")
+
+		_, err = s.app.GetEventsNear(ctx, &proto.GetEventsNearRequest{
+			Center: office,
+			Radius: 1000,
+			From:   timestamppb.New(when),
+			To:     timestamppb.New(when),
+		})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_PERIOD", "to")
+	})
+
+	s.Run("near", func() {
+		req := &proto.GetEventsNearRequest{
+			Center: &proto.GeoPoint{Latitude: 55.76, Longitude: 37.62},
+			Radius: 5000,
+			From:   timestamppb.New(when.Add(-time.Hour)),
+			To:     timestamppb.New(when.Add(24 * time.Hour)),
+		}
+
+		resp, err := s.app.GetEventsNear(ctx, req)
+		s.Require().NoError(err, "app.GetEventsNear must not have error")
+		s.Require().Len(resp.Events, 1, "must find event in area")
+		s.Require().Equal(created.EventID, resp.Events[0].EventID, "must find event in area")
+		s.Require().Equal(office.Latitude, resp.Events[0].Location.Point.Latitude, "must return location")
+
+		req.Radius = 1_000_000
+		resp, err = s.app.GetEventsNear(ctx, req)
+		s.Require().NoError(err, "app.GetEventsNear must not have error")
+		s.Require().Len(resp.Events, 2, "must find events in large area")
+	})
+}
+
+func (s *APITestSuite) Test_Profile() {
+	// отдельное рабочее пространство, чтобы проверка рабочего времени не влияла на другие тесты
+	ctx := s.authContext("office")
+
+	// 2030-01-07 - понедельник
+	monday := time.Date(2030, time.January, 7, 0, 0, 0, 0, time.UTC)
+
+	createEvent := func(startAt time.Time, endAt time.Time) (*proto.CreateEventResponse, error) {
+		return s.app.CreateEvent(ctx, &proto.CreateEventRequest{
+			Event: &proto.Event{
+				EventID: uuid.NewString(),
+				StartAt: timestamppb.New(startAt),
+				EndAt:   timestamppb.New(endAt),
+				Title:   "meeting",
+			},
+		})
+	}
+
+	s.Run("default", func() {
+		resp, err := s.app.GetProfile(ctx, &proto.GetProfileRequest{})
+		s.Require().NoError(err, "app.GetProfile must not have error")
+		s.Require().Equal("UTC", resp.Profile.TimeZone, "must be UTC by default")
+		s.Require().Equal("off", resp.Profile.AvailabilityCheck, "must not check by default")
+		s.Require().Empty(resp.Profile.WorkingDays, "must not have working hours by default")
+	})
+
+	profile := &proto.Profile{
+		TimeZone: "Europe/Berlin",
+		WorkingDays: []*proto.WorkingDay{
+			{Weekday: 1, Start: "09:00", End: "18:00"},
+			{Weekday: 7, Start: "10:00", End: "12:00"},
+		},
+		Holidays:          []*proto.Date{{Year: 2030, Month: 1, Day: 8}},
+		AvailabilityCheck: "warn",
+	}
+
+	s.Run("update", func() {
+		resp, err := s.app.UpdateProfile(ctx, &proto.UpdateProfileRequest{Profile: profile})
+		s.Require().NoError(err, "app.UpdateProfile must not have error")
+		s.Require().Equal(profile.WorkingDays, resp.Profile.WorkingDays, "must keep working days")
+
+		found, err := s.app.GetProfile(ctx, &proto.GetProfileRequest{})
+		s.Require().NoError(err, "app.GetProfile must not have error")
+		s.Require().Equal("Europe/Berlin", found.Profile.TimeZone, "must keep time zone")
+		s.Require().Equal(profile.Holidays, found.Profile.Holidays, "must keep holidays")
+		s.Require().Equal("warn", found.Profile.AvailabilityCheck, "must keep check")
+	})
+
+	s.Run("errors", func() {
+		invalid := []struct {
+			profile *proto.Profile
+			reason  string
+			field   string
+		}{
+			{&proto.Profile{TimeZone: "Mars/Olympus"}, "INVALID_TIME_ZONE", "time_zone"},
+			{&proto.Profile{AvailabilityCheck: "deny"}, "INVALID_AVAILABILITY_CHECK", "availability_check"},
+			{
+				&proto.Profile{WorkingDays: []*proto.WorkingDay{{Weekday: 8, Start: "09:00", End: "18:00"}}},
+				"INVALID_WORKING_HOURS",
+				"working_days",
+			},
+			{
+				&proto.Profile{WorkingDays: []*proto.WorkingDay{{Weekday: 1, Start: "18:00", End: "09:00"}}},
+				"INVALID_WORKING_HOURS",
+				"working_days",
+			},
+			{
+				&proto.Profile{WorkingDays: []*proto.WorkingDay{{Weekday: 1, Start: "9am", End: "18:00"}}},
+				"INVALID_TIME_OF_DAY",
+				"working_days",
+			},
+			{&proto.Profile{Holidays: []*proto.Date{{Year: 2030, Month: 2, Day: 30}}}, "INVALID_HOLIDAY", "holidays"},
+		}
+
+		for _, tt := range invalid {
+			_, err := s.app.UpdateProfile(ctx, &proto.UpdateProfileRequest{Profile: tt.profile})
+			s.requireStatus(err, codes.InvalidArgument, tt.reason, tt.field)
+		}
+	})
+
+	s.Run("warn", func() {
+		// 10:00-11:00 по Берлину
+		resp, err := createEvent(monday.Add(9*time.Hour), monday.Add(10*time.Hour))
+		s.Require().NoError(err, "app.CreateEvent must not have error")
+		s.Require().Empty(resp.Warnings, "must not warn inside working hours")
+
+		resp, err = createEvent(monday.Add(17*time.Hour), monday.Add(18*time.Hour))
+		s.Require().NoError(err, "must create event outside working hours")
+		s.Require().Len(resp.Warnings, 1, "must warn outside working hours")
+		s.Require().Equal("OUTSIDE_WORKING_HOURS", resp.Warnings[0].Reason, "must have proper reason")
+
+		resp, err = createEvent(monday.Add(33*time.Hour), monday.Add(34*time.Hour))
+		s.Require().NoError(err, "must create event on holiday")
+		s.Require().Len(resp.Warnings, 1, "must warn on holiday")
+		s.Require().Equal("ON_HOLIDAY", resp.Warnings[0].Reason, "must have proper reason")
+	})
+
+	s.Run("reject", func() {
+		profile.AvailabilityCheck = "reject"
+		_, err := s.app.UpdateProfile(ctx, &proto.UpdateProfileRequest{Profile: profile})
+		s.Require().NoError(err, "app.UpdateProfile must not have error")
+
+		_, err = createEvent(monday.Add(19*time.Hour), monday.Add(20*time.Hour))
+		s.requireStatus(err, codes.FailedPrecondition, "OUTSIDE_WORKING_HOURS", "")
+
+		// воскресенье 2030-01-13, 10:30-11:30 по Берлину
+		sunday := monday.AddDate(0, 0, 6)
+		resp, err := createEvent(sunday.Add(9*time.Hour+30*time.Minute), sunday.Add(10*time.Hour+30*time.Minute))
+		s.Require().NoError(err, "app.CreateEvent must not have error")
+		s.Require().Empty(resp.Warnings, "must not warn inside working hours")
+	})
+}
+
+func (s *APITestSuite) Test_HolidayCalendars() {
+	dir := s.T().TempDir()
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"X-WR-CALNAME:Russia\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20300101\r\nDTEND;VALUE=DATE:20300103\r\nSUMMARY:New Year\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20290223\r\nRRULE:FREQ=YEARLY\r\nSUMMARY:Feb 23\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "ru.ics"), []byte(ics), 0o600), "must write calendar")
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	holidays, err := holiday.NewStore(logger, dir)
+	s.Require().NoError(err, "must create holiday store")
+
+	business := calendarBusiness.NewApp(logger, clock.Real{}, memoryStorage.NewStorage())
+	business.SetHolidays(holidays)
+	app := NewApp(business, logger)
+
+	ctx := s.authContext(model.DefaultTenantID)
+
+	list, err := app.ListHolidayCalendars(ctx, &proto.ListHolidayCalendarsRequest{})
+	s.Require().NoError(err, "app.ListHolidayCalendars must not have error")
+	s.Require().Equal([]*proto.HolidayCalendar{{Name: "ru", Title: "Russia"}}, list.Calendars)
+
+	_, err = app.CreateEvent(ctx, &proto.CreateEventRequest{
+		Event: &proto.Event{
+			EventID: uuid.NewString(),
+			StartAt: timestamppb.New(time.Date(2030, time.January, 1, 10, 0, 0, 0, time.UTC)),
+			EndAt:   timestamppb.New(time.Date(2030, time.January, 1, 11, 0, 0, 0, time.UTC)),
+			Title:   "party",
+		},
+	})
+	s.Require().NoError(err, "must create event on holiday")
+
+	month := &proto.GetMonthEventsRequest{Month: &proto.Month{Year: 2030, Month: 1}}
+
+	s.Run("not subscribed", func() {
+		resp, err := app.GetMonthEvents(ctx, month)
+		s.Require().NoError(err, "app.GetMonthEvents must not have error")
+		s.Require().Len(resp.Events, 1)
+		s.Require().Empty(resp.Holidays, "must not have holidays without subscription")
+	})
+
+	s.Run("subscribe", func() {
+		_, err := app.UpdateProfile(ctx, &proto.UpdateProfileRequest{
+			Profile: &proto.Profile{HolidayCalendars: []string{"de"}},
+		})
+		s.requireStatus(err, codes.NotFound, "HOLIDAY_CALENDAR_NOT_FOUND", "")
+
+		_, err = app.UpdateProfile(ctx, &proto.UpdateProfileRequest{
+			Profile: &proto.Profile{HolidayCalendars: []string{"../ru"}},
+		})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_HOLIDAY_CALENDAR", "holiday_calendars")
+
+		resp, err := app.UpdateProfile(ctx, &proto.UpdateProfileRequest{
+			Profile: &proto.Profile{HolidayCalendars: []string{"ru"}, AvailabilityCheck: "reject"},
+		})
+		s.Require().NoError(err, "app.UpdateProfile must not have error")
+		s.Require().Equal([]string{"ru"}, resp.Profile.HolidayCalendars)
+	})
+
+	s.Run("month", func() {
+		resp, err := app.GetMonthEvents(ctx, month)
+		s.Require().NoError(err, "app.GetMonthEvents must not have error")
+		s.Require().Len(resp.Events, 1, "must keep events")
+		s.Require().Equal([]*proto.Holiday{
+			{Calendar: "ru", Date: &proto.Date{Year: 2030, Month: 1, Day: 1}, Title: "New Year"},
+			{Calendar: "ru", Date: &proto.Date{Year: 2030, Month: 1, Day: 2}, Title: "New Year"},
+		}, resp.Holidays, "must merge holidays")
+	})
+
+	s.Run("week", func() {
+		resp, err := app.GetWeekEvents(ctx, &proto.GetWeekEventsRequest{
+			StartDay: &proto.Date{Year: 2030, Month: 2, Day: 20},
+		})
+		s.Require().NoError(err, "app.GetWeekEvents must not have error")
+		s.Require().Empty(resp.Events)
+		s.Require().Len(resp.Holidays, 1, "must repeat yearly holidays")
+		s.Require().Equal("Feb 23", resp.Holidays[0].Title)
+	})
+
+	s.Run("non-blocking", func() {
+		_, err := app.CreateEvent(ctx, &proto.CreateEventRequest{
+			Event: &proto.Event{
+				EventID: uuid.NewString(),
+				StartAt: timestamppb.New(time.Date(2030, time.January, 2, 10, 0, 0, 0, time.UTC)),
+				EndAt:   timestamppb.New(time.Date(2030, time.January, 2, 11, 0, 0, 0, time.UTC)),
+				Title:   "party",
+			},
+		})
+		s.Require().NoError(err, "holidays from calendars must not block events")
+	})
+}
+
+func (s *APITestSuite) Test_Attachments() {
+	// отдельное рабочее пространство, чтобы вложения не влияли на другие тесты
+	ctx := s.authContext("attachments")
+	when := time.Now().Add(time.Hour * 504)
+
+	eventID := uuid.NewString()
+	_, err := s.app.CreateEvent(ctx, &proto.CreateEventRequest{
+		Event: &proto.Event{
+			EventID: eventID,
+			StartAt: timestamppb.New(when),
+			EndAt:   timestamppb.New(when.Add(time.Hour)),
+			Title:   "with attachments",
+		},
+	})
+	s.Require().NoError(err, "app.CreateEvent must not have error")
+
+	var link, file *proto.Attachment
+
+	s.Run("add", func() {
+		linkResp, err := s.app.AddLink(ctx, &proto.AddLinkRequest{
+			EventID: eventID,
+			Title:   "Agenda",
+			URL:     "https://docs.example.com/agenda",
+		})
+		s.Require().NoError(err, "app.AddLink must not have error")
+		link = linkResp.Attachment
+
+		_, err = s.app.AddLink(ctx, &proto.AddLinkRequest{EventID: eventID, URL: "ftp://example.com"})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_URL", "url")
+
+		fileResp, err := s.app.UploadFile(ctx, &proto.UploadFileRequest{
+			EventID:  eventID,
+			FileName: "notes.txt",
+			Content:  []byte("meeting notes"),
+		})
+		s.Require().NoError(err, "app.UploadFile must not have error")
+		file = fileResp.Attachment
+		s.Require().Equal(int64(len("meeting notes")), file.Size, "must return file size")
+		s.Require().Equal("notes.txt", file.Title, "must use file name as title")
+
+		_, err = s.app.UploadFile(ctx, &proto.UploadFileRequest{
+			EventID:  eventID,
+			FileName: "large.txt",
+			Content:  []byte(strings.Repeat("x", 17)),
+		})
+		s.requireStatus(err, codes.InvalidArgument, "FILE_TOO_LARGE", "content")
+
+		_, err = s.app.AddLink(ctx, &proto.AddLinkRequest{EventID: eventID, URL: "https://example.com/3"})
+		s.Require().NoError(err, "app.AddLink must not have error")
+
+		_, err = s.app.AddLink(ctx, &proto.AddLinkRequest{EventID: eventID, URL: "https://example.com/4"})
+		s.requireStatus(err, codes.ResourceExhausted, "TOO_MANY_ATTACHMENTS", "")
+
+		resp, err := s.app.UpdateEvent(ctx, &proto.UpdateEventRequest{
+			Event: &proto.Event{
+				EventID: eventID,
+				StartAt: timestamppb.New(when),
+				EndAt:   timestamppb.New(when.Add(time.Hour)),
+				Title:   "changed title",
+			},
+		})
+		s.Require().NoError(err, "app.UpdateEvent must not have error")
+		s.Require().Len(resp.Event.Attachments, 3, "must keep attachments on update")
+	})
+
+	s.Require().NotNil(link, "link must not be nil")
+	s.Require().NotNil(file, "file must not be nil")
+
+	s.Run("download", func() {
+		stream := &downloadStream{ctx: ctx}
+		err := s.app.DownloadAttachment(
+			&proto.DownloadAttachmentRequest{EventID: eventID, AttachmentID: file.AttachmentID},
+			stream,
+		)
+		s.Require().NoError(err, "app.DownloadAttachment must not have error")
+		s.Require().Equal(file.AttachmentID, stream.attachment.GetAttachmentID(), "must send attachment first")
+		s.Require().Equal("meeting notes", stream.content.String(), "must send file content")
+
+		err = s.app.DownloadAttachment(
+			&proto.DownloadAttachmentRequest{EventID: eventID, AttachmentID: link.AttachmentID},
+			&downloadStream{ctx: ctx},
+		)
+		s.requireStatus(err, codes.FailedPrecondition, "NOT_FILE_ATTACHMENT", "")
+	})
+
+	s.Run("delete", func() {
+		req := &proto.DeleteAttachmentRequest{EventID: eventID, AttachmentID: file.AttachmentID}
+		_, err := s.app.DeleteAttachment(ctx, req)
+		s.Require().NoError(err, "app.DeleteAttachment must not have error")
+
+		_, err = s.app.DeleteAttachment(ctx, req)
+		s.requireStatus(err, codes.NotFound, "ATTACHMENT_NOT_FOUND", "")
+
+		err = s.app.DownloadAttachment(
+			&proto.DownloadAttachmentRequest{EventID: eventID, AttachmentID: file.AttachmentID},
+			&downloadStream{ctx: ctx},
+		)
+		s.requireStatus(err, codes.NotFound, "ATTACHMENT_NOT_FOUND", "")
+	})
+}
+
+func (s *APITestSuite) Test_FeedTokens() {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2030, 1, 2, 3, 4, 5, 600, time.UTC)
+	app := NewApp(calendarBusiness.NewApp(logger, clock.NewFake(now), memoryStorage.NewStorage()), logger)
+
+	ctx := s.authContext(model.DefaultTenantID)
+
+	var tokenID string
+
+	s.Run("create", func() {
+		_, err := app.CreateFeedToken(ctx, &proto.CreateFeedTokenRequest{Name: strings.Repeat("я", 65)})
+		s.requireStatus(err, codes.InvalidArgument, "NAME_TOO_LONG", "name")
+
+		resp, err := app.CreateFeedToken(ctx, &proto.CreateFeedTokenRequest{Name: "телефон"})
+		s.Require().NoError(err, "app.CreateFeedToken must not have error")
+		s.Require().Equal("телефон", resp.Token.Name)
+		s.Require().NotEmpty(resp.Token.TokenID)
+		s.Require().NotEmpty(resp.Secret, "must return secret")
+		s.Require().Contains(resp.Path, resp.Secret, "path must contain secret")
+		s.Require().Equal(now.Truncate(time.Second), resp.Token.CreatedAt.AsTime(), "must be created by clock")
+
+		tokenID = resp.Token.TokenID
+	})
+
+	s.Run("list", func() {
+		resp, err := app.ListFeedTokens(ctx, &proto.ListFeedTokensRequest{})
+		s.Require().NoError(err, "app.ListFeedTokens must not have error")
+		s.Require().Len(resp.Tokens, 1)
+		s.Require().Equal(tokenID, resp.Tokens[0].TokenID)
+
+		other := s.authContext(model.TenantID(uuid.NewString()))
+		resp, err = app.ListFeedTokens(other, &proto.ListFeedTokensRequest{})
+		s.Require().NoError(err, "app.ListFeedTokens must not have error")
+		s.Require().Empty(resp.Tokens, "must not list tokens of other tenant")
+	})
+
+	s.Run("revoke", func() {
+		_, err := app.RevokeFeedToken(ctx, &proto.RevokeFeedTokenRequest{TokenID: "token"})
+		s.requireStatus(err, codes.InvalidArgument, "INVALID_FEED_TOKEN_ID", "token_id")
+
+		_, err = app.RevokeFeedToken(ctx, &proto.RevokeFeedTokenRequest{TokenID: tokenID})
+		s.Require().NoError(err, "app.RevokeFeedToken must not have error")
+
+		_, err = app.RevokeFeedToken(ctx, &proto.RevokeFeedTokenRequest{TokenID: tokenID})
+		s.requireStatus(err, codes.NotFound, "FEED_TOKEN_NOT_FOUND", "")
+
+		resp, err := app.ListFeedTokens(ctx, &proto.ListFeedTokensRequest{})
+		s.Require().NoError(err, "app.ListFeedTokens must not have error")
+		s.Require().Empty(resp.Tokens, "must not list revoked token")
+	})
+}
+
+// downloadStream - поток DownloadAttachment, собирающий отправленные сообщения.
+type downloadStream struct {
+	grpc.ServerStream
+
+	ctx        context.Context
+	attachment *proto.Attachment
+	content    strings.Builder
+}
+
+func (s *downloadStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *downloadStream) Send(resp *proto.DownloadAttachmentResponse) error {
+	if attachment := resp.GetAttachment(); attachment != nil {
+		s.attachment = attachment
+	}
+
+	s.content.Write(resp.GetChunk())
+
+	return nil
+}
+
+// authContext возвращает контекст владельца s.ownerID в рабочем пространстве tenantID.
+func (s *APITestSuite) authContext(tenantID model.TenantID) context.Context {
+	ctx, err := auth.WithTenantID(context.Background(), string(tenantID))
+	s.Require().NoError(err, "auth.WithTenantID must not have error")
+
+	ctx, err = auth.WithOwnerID(ctx, string(s.ownerID))
+	s.Require().NoError(err, "auth.WithOwnerID must not have error")
+
+	return ctx
+}
+
+// requireStatus проверяет, что err - grpc-ошибка с кодом code, причиной reason в ErrorInfo
+// и, если field не пустой, нарушением поля field в BadRequest.
+func (s *APITestSuite) requireStatus(err error, code codes.Code, reason string, field string) {
+	s.T().Helper()
+
+	st, ok := status.FromError(err)
+	s.Require().True(ok, "must be grpc status")
+	s.Require().Equal(code, st.Code(), "must have proper code")
+
+	var (
+		info       *errdetails.ErrorInfo
+		badRequest *errdetails.BadRequest
+	)
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			badRequest = d
+		}
+	}
+
+	s.Require().NotNil(info, "must have ErrorInfo")
+	s.Require().Equal(reason, info.GetReason(), "must have proper reason")
+
+	if field == "" {
+		s.Require().Nil(badRequest, "must not have BadRequest")
+		return
+	}
+
+	s.Require().NotNil(badRequest, "must have BadRequest")
+	s.Require().Len(badRequest.GetFieldViolations(), 1, "must have field violation")
+	s.Require().Equal(field, badRequest.GetFieldViolations()[0].GetField(), "must have proper field")
+}
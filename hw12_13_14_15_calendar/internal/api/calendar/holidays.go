@@ -0,0 +1,46 @@
+package calendar
+
+import (
+	"context"
+
+	proto "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+func (a *App) ListHolidayCalendars(
+	ctx context.Context,
+	_ *proto.ListHolidayCalendarsRequest,
+) (*proto.ListHolidayCalendarsResponse, error) {
+	calendars := a.business.ListHolidayCalendars(ctx)
+
+	resp := &proto.ListHolidayCalendarsResponse{
+		Calendars: make([]*proto.HolidayCalendar, len(calendars)),
+	}
+
+	for i, c := range calendars {
+		resp.Calendars[i] = &proto.HolidayCalendar{Name: string(c.Name), Title: c.Title}
+	}
+
+	return resp, nil
+}
+
+func holidaysToProto(holidays []model.Holiday) []*proto.Holiday {
+	if len(holidays) == 0 {
+		return nil
+	}
+
+	result := make([]*proto.Holiday, len(holidays))
+	for i, h := range holidays {
+		result[i] = &proto.Holiday{
+			Calendar: string(h.Calendar),
+			Date: &proto.Date{
+				Year:  int32(h.Date.Year),
+				Month: int32(h.Date.Month),
+				Day:   int32(h.Date.Day),
+			},
+			Title: h.Title,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,175 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	proto "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+func (a *App) GetProfile(ctx context.Context, _ *proto.GetProfileRequest) (*proto.GetProfileResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetProfile", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetProfile", whereAttr("OwnerIDFromContext"))
+	}
+
+	profile, err := a.business.GetProfile(ctx, tenantID, ownerID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "GetProfile", whereAttr("business.GetProfile"))
+	}
+
+	return &proto.GetProfileResponse{Profile: profileToProto(profile)}, nil
+}
+
+func (a *App) UpdateProfile(
+	ctx context.Context,
+	req *proto.UpdateProfileRequest,
+) (*proto.UpdateProfileResponse, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateProfile", whereAttr("TenantIDFromContext"))
+	}
+
+	ownerID, err := auth.OwnerIDFromContext(ctx)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateProfile", whereAttr("OwnerIDFromContext"))
+	}
+
+	profile, err := protoToProfile(req.GetProfile(), tenantID, ownerID)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateProfile", whereAttr("protoToProfile"))
+	}
+
+	err = a.business.UpdateProfile(ctx, profile)
+	if err != nil {
+		return nil, a.handleError(ctx, err, "UpdateProfile", whereAttr("business.UpdateProfile"))
+	}
+
+	return &proto.UpdateProfileResponse{Profile: profileToProto(profile)}, nil
+}
+
+// protoToProfile возвращает профиль доступности владельца из запроса, nil - профиль по умолчанию.
+func protoToProfile(p *proto.Profile, tenantID model.TenantID, ownerID model.OwnerID) (model.Profile, error) {
+	profile := model.NewProfile(tenantID, ownerID)
+
+	var err error
+
+	profile.TimeZone, err = model.NewTimeZone(p.GetTimeZone())
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	profile.Check, err = model.NewAvailabilityCheck(p.GetAvailabilityCheck())
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	for _, day := range p.GetWorkingDays() {
+		if day.Weekday < 1 || day.Weekday > 7 {
+			return model.Profile{}, fmt.Errorf("%w: weekday must be in [1, 7]", model.ErrInvalidWorkingHours)
+		}
+
+		weekday := time.Weekday(day.Weekday % 7)
+		if !profile.WorkingHours[weekday].IsZero() {
+			return model.Profile{}, fmt.Errorf("%w: duplicate weekday %d", model.ErrInvalidWorkingHours, day.Weekday)
+		}
+
+		start, err := model.NewTimeOfDay(day.Start)
+		if err != nil {
+			return model.Profile{}, err
+		}
+
+		end, err := model.NewTimeOfDay(day.End)
+		if err != nil {
+			return model.Profile{}, err
+		}
+
+		profile.WorkingHours[weekday], err = model.NewWorkingHours(start, end)
+		if err != nil {
+			return model.Profile{}, err
+		}
+	}
+
+	dates := make([]model.Date, 0, len(p.GetHolidays()))
+	for _, d := range p.GetHolidays() {
+		date, err := model.NewDate(int(d.Year), int(d.Month), int(d.Day))
+		if err != nil {
+			return model.Profile{}, err
+		}
+
+		dates = append(dates, date)
+	}
+
+	profile.Holidays, err = model.NewHolidays(dates)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	profile.HolidayCalendars, err = model.NewHolidayCalendars(p.GetHolidayCalendars())
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	return profile, nil
+}
+
+func profileToProto(profile model.Profile) *proto.Profile {
+	p := &proto.Profile{
+		TimeZone:          profile.TimeZone.String(),
+		AvailabilityCheck: string(profile.Check),
+	}
+
+	// дни недели с понедельника, как в запросе
+	for i := 1; i <= 7; i++ {
+		hours := profile.WorkingHours[time.Weekday(i%7)]
+		if hours.IsZero() {
+			continue
+		}
+
+		p.WorkingDays = append(p.WorkingDays, &proto.WorkingDay{
+			Weekday: int32(i),
+			Start:   hours.Start.String(),
+			End:     hours.End.String(),
+		})
+	}
+
+	for _, date := range profile.Holidays {
+		p.Holidays = append(p.Holidays, &proto.Date{
+			Year:  int32(date.Year),
+			Month: int32(date.Month),
+			Day:   int32(date.Day),
+		})
+	}
+
+	for _, calendar := range profile.HolidayCalendars {
+		p.HolidayCalendars = append(p.HolidayCalendars, string(calendar))
+	}
+
+	return p
+}
+
+// warningsToProto возвращает предупреждения warnings - ошибки предметной области.
+func warningsToProto(warnings []error) []*proto.Warning {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	result := make([]*proto.Warning, len(warnings))
+	for i, w := range warnings {
+		result[i] = &proto.Warning{Message: w.Error()}
+		if derr, ok := domainerr.From(w); ok {
+			result[i].Reason = derr.Reason
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,100 @@
+// api/feed - приложение с контроллером feed.
+// Представляет собой пакет с обработчиками для http-сервера основанного на http/web/mux мультиплексоре.
+//
+// Основная задача - выдача календаря владельца в формате iCalendar по ссылке для подписки (webcal).
+// Ссылка содержит секрет и не требует аутентификации: владелец и рабочее пространство определяются по секрету.
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+const (
+	// PathPrefix - префикс пути календарей на http-сервере, за ним следует секрет ссылки.
+	PathPrefix = "/v1/feed/"
+
+	// PathSuffix - расширение файла календаря в пути после секрета,
+	// чтобы приложения календарей распознавали формат.
+	PathSuffix = ".ics"
+
+	// maxVersions - сколько версий календарей запоминается для Last-Modified, см. App.version.
+	maxVersions = 10_000
+)
+
+// URLPath возвращает путь календаря по ссылке с секретом secret.
+func URLPath(secret model.FeedSecret) string {
+	return PathPrefix + string(secret) + PathSuffix
+}
+
+type Business interface {
+	Feed(ctx context.Context, secret model.FeedSecret, now time.Time) (model.FeedToken, []model.Event, error)
+}
+
+// feedVersion - версия календаря по ссылке: ETag содержимого и время, когда содержимое стало таким.
+type feedVersion struct {
+	etag    string
+	modTime time.Time
+}
+
+type App struct {
+	business Business
+	clock    clock.Clock
+	logger   *slog.Logger
+
+	// versions - последние версии календарей по ссылкам. События не хранят время изменения,
+	// поэтому временем изменения календаря считается время, когда сервис впервые выдал его содержимое.
+	versions   map[model.FeedTokenID]feedVersion
+	versionsMx sync.Mutex
+}
+
+func NewApp(business Business, clock clock.Clock, logger *slog.Logger) *App {
+	return &App{
+		business: business,
+		clock:    clock,
+		logger:   logger,
+		versions: map[model.FeedTokenID]feedVersion{},
+	}
+}
+
+func (a *App) AddRoutes(mux *web.Mux) error {
+	a.logger.Debug("add routes")
+
+	const path = "/feed/{secret}"
+
+	err := mux.Handle(http.MethodGet, "v1", path, a.HandleFeed)
+	if err != nil {
+		return fmt.Errorf("mux.Handle method=%s version=%s path=%s", http.MethodGet, "v1", path)
+	}
+
+	return nil
+}
+
+// version возвращает версию календаря по ссылке tokenID с содержимым etag.
+// Если содержимое изменилось (или календарь ещё не выдавался), временем изменения становится now.
+func (a *App) version(tokenID model.FeedTokenID, etag string, now time.Time) feedVersion {
+	a.versionsMx.Lock()
+	defer a.versionsMx.Unlock()
+
+	if v, ok := a.versions[tokenID]; ok && v.etag == etag {
+		return v
+	}
+
+	// при переполнении версии забываются: клиенты один раз получат календарь целиком
+	if len(a.versions) >= maxVersions {
+		clear(a.versions)
+	}
+
+	v := feedVersion{etag: etag, modTime: now.UTC().Truncate(time.Second)}
+	a.versions[tokenID] = v
+
+	return v
+}
@@ -0,0 +1,172 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical/vevent"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+const (
+	// ContentType - тип содержимого календаря.
+	ContentType = "text/calendar; charset=utf-8"
+
+	// refreshInterval - как часто приложениям календарей предлагается обновлять подписку.
+	refreshInterval = "PT15M"
+
+	// defaultCalendarName - название календаря, если у ссылки нет названия.
+	defaultCalendarName = "Календарь"
+)
+
+// FeedResponse - календарь по ссылке в формате iCalendar.
+type FeedResponse struct {
+	data    []byte
+	version feedVersion
+
+	// notModified - клиент уже получал эту версию календаря, отвечаем 304 без содержимого.
+	notModified bool
+}
+
+func (r FeedResponse) Data() (data []byte, contentType string, err error) {
+	if r.notModified {
+		return nil, ContentType, nil
+	}
+
+	return r.data, ContentType, nil
+}
+
+func (r FeedResponse) StatusCode() int {
+	if r.notModified {
+		return http.StatusNotModified
+	}
+
+	return http.StatusOK
+}
+
+func (r FeedResponse) Header() http.Header {
+	return http.Header{
+		"Etag":          {r.version.etag},
+		"Last-Modified": {r.version.modTime.Format(http.TimeFormat)},
+		// календарь может измениться в любой момент: клиент должен проверять актуальность при каждом запросе
+		"Cache-Control":          {"private, no-cache"},
+		"X-Content-Type-Options": {"nosniff"},
+	}
+}
+
+// HandleFeed отдаёт календарь владельца по ссылке с секретом: GET /v1/feed/{secret}.ics.
+// Поддерживаются условные запросы с If-None-Match и If-Modified-Since.
+func (a *App) HandleFeed(ctx context.Context, r *http.Request) web.DataResponder {
+	secret, err := model.NewFeedSecretFromString(strings.TrimSuffix(r.PathValue("secret"), PathSuffix))
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	now := a.clock.Now()
+
+	token, events, err := a.business.Feed(ctx, secret, now)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	// DTSTAMP зависит от времени изменения, поэтому ETag считается по содержимому без него
+	fingerprint, err := encode(token, events, time.Time{})
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	sum := sha256.Sum256(fingerprint)
+	version := a.version(token.TokenID(), `W/"`+hex.EncodeToString(sum[:16])+`"`, now)
+
+	if notModified(r, version) {
+		return FeedResponse{version: version, notModified: true}
+	}
+
+	data, err := encode(token, events, version.modTime)
+	if err != nil {
+		return a.errorResponse(ctx, err)
+	}
+
+	return FeedResponse{data: data, version: version}
+}
+
+// encode возвращает календарь ссылки token с событиями events, stamp - время изменения событий.
+func encode(token model.FeedToken, events []model.Event, stamp time.Time) ([]byte, error) {
+	name := token.Name
+	if name == "" {
+		name = defaultCalendarName
+	}
+
+	calendar := vevent.NewCalendar()
+	calendar.Add("METHOD", "PUBLISH")
+	calendar.AddText("X-WR-CALNAME", name)
+	calendar.Properties = append(calendar.Properties, ical.Property{
+		Name:   "REFRESH-INTERVAL",
+		Params: map[string]string{"VALUE": "DURATION"},
+		Value:  refreshInterval,
+	})
+	calendar.Add("X-PUBLISHED-TTL", refreshInterval)
+
+	for _, event := range events {
+		calendar.Children = append(calendar.Children, vevent.FromEvent(event, stamp))
+	}
+
+	var b bytes.Buffer
+	if err := ical.Encode(&b, calendar); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// notModified сообщает, что у клиента уже есть версия календаря version (RFC 9110, 13.2.2):
+// If-None-Match проверяется первым, If-Modified-Since - только без него.
+func notModified(r *http.Request, version feedVersion) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, etag := range strings.Split(inm, ",") {
+			etag = strings.TrimSpace(etag)
+			if etag == "*" || strings.TrimPrefix(etag, "W/") == strings.TrimPrefix(version.etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+
+		return !version.modTime.After(t)
+	}
+
+	return false
+}
+
+// errorResponse возвращает ответ с кодом, соответствующим ошибке предметной области err.
+// Любая другая ошибка добавляется в лог, клиенту возвращается http.StatusInternalServerError.
+func (a *App) errorResponse(ctx context.Context, err error) web.DataResponder {
+	if resp, ok := web.DomainErrorResponse(err); ok {
+		return resp
+	}
+
+	a.logger.ErrorContext(
+		ctx,
+		"error occurred",
+		slog.String("handle", "HandleFeed"),
+		slog.String("error", err.Error()),
+	)
+
+	return web.ErrorResponse{Err: errors.New("some error")}
+}
@@ -19,12 +19,27 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	EventService_CreateEvent_FullMethodName    = "/event.v1.EventService/CreateEvent"
-	EventService_UpdateEvent_FullMethodName    = "/event.v1.EventService/UpdateEvent"
-	EventService_DeleteEvent_FullMethodName    = "/event.v1.EventService/DeleteEvent"
-	EventService_GetDayEvents_FullMethodName   = "/event.v1.EventService/GetDayEvents"
-	EventService_GetWeekEvents_FullMethodName  = "/event.v1.EventService/GetWeekEvents"
-	EventService_GetMonthEvents_FullMethodName = "/event.v1.EventService/GetMonthEvents"
+	EventService_CreateEvent_FullMethodName          = "/event.v1.EventService/CreateEvent"
+	EventService_UpdateEvent_FullMethodName          = "/event.v1.EventService/UpdateEvent"
+	EventService_DeleteEvent_FullMethodName          = "/event.v1.EventService/DeleteEvent"
+	EventService_GetDayEvents_FullMethodName         = "/event.v1.EventService/GetDayEvents"
+	EventService_GetWeekEvents_FullMethodName        = "/event.v1.EventService/GetWeekEvents"
+	EventService_GetMonthEvents_FullMethodName       = "/event.v1.EventService/GetMonthEvents"
+	EventService_GetEventsNear_FullMethodName        = "/event.v1.EventService/GetEventsNear"
+	EventService_CreateLabel_FullMethodName          = "/event.v1.EventService/CreateLabel"
+	EventService_UpdateLabel_FullMethodName          = "/event.v1.EventService/UpdateLabel"
+	EventService_DeleteLabel_FullMethodName          = "/event.v1.EventService/DeleteLabel"
+	EventService_ListLabels_FullMethodName           = "/event.v1.EventService/ListLabels"
+	EventService_AddLink_FullMethodName              = "/event.v1.EventService/AddLink"
+	EventService_UploadFile_FullMethodName           = "/event.v1.EventService/UploadFile"
+	EventService_DeleteAttachment_FullMethodName     = "/event.v1.EventService/DeleteAttachment"
+	EventService_GetProfile_FullMethodName           = "/event.v1.EventService/GetProfile"
+	EventService_UpdateProfile_FullMethodName        = "/event.v1.EventService/UpdateProfile"
+	EventService_ListHolidayCalendars_FullMethodName = "/event.v1.EventService/ListHolidayCalendars"
+	EventService_CreateFeedToken_FullMethodName      = "/event.v1.EventService/CreateFeedToken"
+	EventService_ListFeedTokens_FullMethodName       = "/event.v1.EventService/ListFeedTokens"
+	EventService_RevokeFeedToken_FullMethodName      = "/event.v1.EventService/RevokeFeedToken"
+	EventService_DownloadAttachment_FullMethodName   = "/event.v1.EventService/DownloadAttachment"
 )
 
 // EventServiceClient is the client API for EventService service.
@@ -37,6 +52,26 @@ type EventServiceClient interface {
 	GetDayEvents(ctx context.Context, in *GetDayEventsRequest, opts ...grpc.CallOption) (*GetDayEventsResponse, error)
 	GetWeekEvents(ctx context.Context, in *GetWeekEventsRequest, opts ...grpc.CallOption) (*GetWeekEventsResponse, error)
 	GetMonthEvents(ctx context.Context, in *GetMonthEventsRequest, opts ...grpc.CallOption) (*GetMonthEventsResponse, error)
+	GetEventsNear(ctx context.Context, in *GetEventsNearRequest, opts ...grpc.CallOption) (*GetEventsNearResponse, error)
+	CreateLabel(ctx context.Context, in *CreateLabelRequest, opts ...grpc.CallOption) (*CreateLabelResponse, error)
+	UpdateLabel(ctx context.Context, in *UpdateLabelRequest, opts ...grpc.CallOption) (*UpdateLabelResponse, error)
+	DeleteLabel(ctx context.Context, in *DeleteLabelRequest, opts ...grpc.CallOption) (*DeleteLabelResponse, error)
+	ListLabels(ctx context.Context, in *ListLabelsRequest, opts ...grpc.CallOption) (*ListLabelsResponse, error)
+	AddLink(ctx context.Context, in *AddLinkRequest, opts ...grpc.CallOption) (*AddLinkResponse, error)
+	UploadFile(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*UploadFileResponse, error)
+	DeleteAttachment(ctx context.Context, in *DeleteAttachmentRequest, opts ...grpc.CallOption) (*DeleteAttachmentResponse, error)
+	GetProfile(ctx context.Context, in *GetProfileRequest, opts ...grpc.CallOption) (*GetProfileResponse, error)
+	UpdateProfile(ctx context.Context, in *UpdateProfileRequest, opts ...grpc.CallOption) (*UpdateProfileResponse, error)
+	// ListHolidayCalendars возвращает календари праздников, на которые можно подписаться в профиле.
+	ListHolidayCalendars(ctx context.Context, in *ListHolidayCalendarsRequest, opts ...grpc.CallOption) (*ListHolidayCalendarsResponse, error)
+	// CreateFeedToken создаёт ссылку на календарь владельца для подписки (webcal).
+	CreateFeedToken(ctx context.Context, in *CreateFeedTokenRequest, opts ...grpc.CallOption) (*CreateFeedTokenResponse, error)
+	ListFeedTokens(ctx context.Context, in *ListFeedTokensRequest, opts ...grpc.CallOption) (*ListFeedTokensResponse, error)
+	// RevokeFeedToken отзывает ссылку на календарь: календарь по ней больше не доступен.
+	RevokeFeedToken(ctx context.Context, in *RevokeFeedTokenRequest, opts ...grpc.CallOption) (*RevokeFeedTokenResponse, error)
+	// DownloadAttachment передаёт сначала описание вложения-файла, затем его содержимое частями.
+	// По HTTP файл доступен по GET /v1/events/{event_id}/attachments/{attachment_id}.
+	DownloadAttachment(ctx context.Context, in *DownloadAttachmentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadAttachmentResponse], error)
 }
 
 type eventServiceClient struct {
@@ -107,6 +142,165 @@ func (c *eventServiceClient) GetMonthEvents(ctx context.Context, in *GetMonthEve
 	return out, nil
 }
 
+func (c *eventServiceClient) GetEventsNear(ctx context.Context, in *GetEventsNearRequest, opts ...grpc.CallOption) (*GetEventsNearResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetEventsNearResponse)
+	err := c.cc.Invoke(ctx, EventService_GetEventsNear_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) CreateLabel(ctx context.Context, in *CreateLabelRequest, opts ...grpc.CallOption) (*CreateLabelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateLabelResponse)
+	err := c.cc.Invoke(ctx, EventService_CreateLabel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) UpdateLabel(ctx context.Context, in *UpdateLabelRequest, opts ...grpc.CallOption) (*UpdateLabelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateLabelResponse)
+	err := c.cc.Invoke(ctx, EventService_UpdateLabel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) DeleteLabel(ctx context.Context, in *DeleteLabelRequest, opts ...grpc.CallOption) (*DeleteLabelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteLabelResponse)
+	err := c.cc.Invoke(ctx, EventService_DeleteLabel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) ListLabels(ctx context.Context, in *ListLabelsRequest, opts ...grpc.CallOption) (*ListLabelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLabelsResponse)
+	err := c.cc.Invoke(ctx, EventService_ListLabels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) AddLink(ctx context.Context, in *AddLinkRequest, opts ...grpc.CallOption) (*AddLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddLinkResponse)
+	err := c.cc.Invoke(ctx, EventService_AddLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) UploadFile(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*UploadFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadFileResponse)
+	err := c.cc.Invoke(ctx, EventService_UploadFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) DeleteAttachment(ctx context.Context, in *DeleteAttachmentRequest, opts ...grpc.CallOption) (*DeleteAttachmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAttachmentResponse)
+	err := c.cc.Invoke(ctx, EventService_DeleteAttachment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) GetProfile(ctx context.Context, in *GetProfileRequest, opts ...grpc.CallOption) (*GetProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProfileResponse)
+	err := c.cc.Invoke(ctx, EventService_GetProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) UpdateProfile(ctx context.Context, in *UpdateProfileRequest, opts ...grpc.CallOption) (*UpdateProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateProfileResponse)
+	err := c.cc.Invoke(ctx, EventService_UpdateProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) ListHolidayCalendars(ctx context.Context, in *ListHolidayCalendarsRequest, opts ...grpc.CallOption) (*ListHolidayCalendarsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListHolidayCalendarsResponse)
+	err := c.cc.Invoke(ctx, EventService_ListHolidayCalendars_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) CreateFeedToken(ctx context.Context, in *CreateFeedTokenRequest, opts ...grpc.CallOption) (*CreateFeedTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateFeedTokenResponse)
+	err := c.cc.Invoke(ctx, EventService_CreateFeedToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) ListFeedTokens(ctx context.Context, in *ListFeedTokensRequest, opts ...grpc.CallOption) (*ListFeedTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFeedTokensResponse)
+	err := c.cc.Invoke(ctx, EventService_ListFeedTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) RevokeFeedToken(ctx context.Context, in *RevokeFeedTokenRequest, opts ...grpc.CallOption) (*RevokeFeedTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeFeedTokenResponse)
+	err := c.cc.Invoke(ctx, EventService_RevokeFeedToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) DownloadAttachment(ctx context.Context, in *DownloadAttachmentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadAttachmentResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[0], EventService_DownloadAttachment_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadAttachmentRequest, DownloadAttachmentResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_DownloadAttachmentClient = grpc.ServerStreamingClient[DownloadAttachmentResponse]
+
 // EventServiceServer is the server API for EventService service.
 // All implementations must embed UnimplementedEventServiceServer
 // for forward compatibility.
@@ -117,6 +311,26 @@ type EventServiceServer interface {
 	GetDayEvents(context.Context, *GetDayEventsRequest) (*GetDayEventsResponse, error)
 	GetWeekEvents(context.Context, *GetWeekEventsRequest) (*GetWeekEventsResponse, error)
 	GetMonthEvents(context.Context, *GetMonthEventsRequest) (*GetMonthEventsResponse, error)
+	GetEventsNear(context.Context, *GetEventsNearRequest) (*GetEventsNearResponse, error)
+	CreateLabel(context.Context, *CreateLabelRequest) (*CreateLabelResponse, error)
+	UpdateLabel(context.Context, *UpdateLabelRequest) (*UpdateLabelResponse, error)
+	DeleteLabel(context.Context, *DeleteLabelRequest) (*DeleteLabelResponse, error)
+	ListLabels(context.Context, *ListLabelsRequest) (*ListLabelsResponse, error)
+	AddLink(context.Context, *AddLinkRequest) (*AddLinkResponse, error)
+	UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error)
+	DeleteAttachment(context.Context, *DeleteAttachmentRequest) (*DeleteAttachmentResponse, error)
+	GetProfile(context.Context, *GetProfileRequest) (*GetProfileResponse, error)
+	UpdateProfile(context.Context, *UpdateProfileRequest) (*UpdateProfileResponse, error)
+	// ListHolidayCalendars возвращает календари праздников, на которые можно подписаться в профиле.
+	ListHolidayCalendars(context.Context, *ListHolidayCalendarsRequest) (*ListHolidayCalendarsResponse, error)
+	// CreateFeedToken создаёт ссылку на календарь владельца для подписки (webcal).
+	CreateFeedToken(context.Context, *CreateFeedTokenRequest) (*CreateFeedTokenResponse, error)
+	ListFeedTokens(context.Context, *ListFeedTokensRequest) (*ListFeedTokensResponse, error)
+	// RevokeFeedToken отзывает ссылку на календарь: календарь по ней больше не доступен.
+	RevokeFeedToken(context.Context, *RevokeFeedTokenRequest) (*RevokeFeedTokenResponse, error)
+	// DownloadAttachment передаёт сначала описание вложения-файла, затем его содержимое частями.
+	// По HTTP файл доступен по GET /v1/events/{event_id}/attachments/{attachment_id}.
+	DownloadAttachment(*DownloadAttachmentRequest, grpc.ServerStreamingServer[DownloadAttachmentResponse]) error
 	mustEmbedUnimplementedEventServiceServer()
 }
 
@@ -145,6 +359,51 @@ func (UnimplementedEventServiceServer) GetWeekEvents(context.Context, *GetWeekEv
 func (UnimplementedEventServiceServer) GetMonthEvents(context.Context, *GetMonthEventsRequest) (*GetMonthEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMonthEvents not implemented")
 }
+func (UnimplementedEventServiceServer) GetEventsNear(context.Context, *GetEventsNearRequest) (*GetEventsNearResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEventsNear not implemented")
+}
+func (UnimplementedEventServiceServer) CreateLabel(context.Context, *CreateLabelRequest) (*CreateLabelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateLabel not implemented")
+}
+func (UnimplementedEventServiceServer) UpdateLabel(context.Context, *UpdateLabelRequest) (*UpdateLabelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLabel not implemented")
+}
+func (UnimplementedEventServiceServer) DeleteLabel(context.Context, *DeleteLabelRequest) (*DeleteLabelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLabel not implemented")
+}
+func (UnimplementedEventServiceServer) ListLabels(context.Context, *ListLabelsRequest) (*ListLabelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLabels not implemented")
+}
+func (UnimplementedEventServiceServer) AddLink(context.Context, *AddLinkRequest) (*AddLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddLink not implemented")
+}
+func (UnimplementedEventServiceServer) UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
+}
+func (UnimplementedEventServiceServer) DeleteAttachment(context.Context, *DeleteAttachmentRequest) (*DeleteAttachmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAttachment not implemented")
+}
+func (UnimplementedEventServiceServer) GetProfile(context.Context, *GetProfileRequest) (*GetProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProfile not implemented")
+}
+func (UnimplementedEventServiceServer) UpdateProfile(context.Context, *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateProfile not implemented")
+}
+func (UnimplementedEventServiceServer) ListHolidayCalendars(context.Context, *ListHolidayCalendarsRequest) (*ListHolidayCalendarsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHolidayCalendars not implemented")
+}
+func (UnimplementedEventServiceServer) CreateFeedToken(context.Context, *CreateFeedTokenRequest) (*CreateFeedTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFeedToken not implemented")
+}
+func (UnimplementedEventServiceServer) ListFeedTokens(context.Context, *ListFeedTokensRequest) (*ListFeedTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFeedTokens not implemented")
+}
+func (UnimplementedEventServiceServer) RevokeFeedToken(context.Context, *RevokeFeedTokenRequest) (*RevokeFeedTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeFeedToken not implemented")
+}
+func (UnimplementedEventServiceServer) DownloadAttachment(*DownloadAttachmentRequest, grpc.ServerStreamingServer[DownloadAttachmentResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadAttachment not implemented")
+}
 func (UnimplementedEventServiceServer) mustEmbedUnimplementedEventServiceServer() {}
 func (UnimplementedEventServiceServer) testEmbeddedByValue()                      {}
 
@@ -274,6 +533,269 @@ func _EventService_GetMonthEvents_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventService_GetEventsNear_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventsNearRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).GetEventsNear(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_GetEventsNear_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).GetEventsNear(ctx, req.(*GetEventsNearRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_CreateLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).CreateLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_CreateLabel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).CreateLabel(ctx, req.(*CreateLabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_UpdateLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).UpdateLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_UpdateLabel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).UpdateLabel(ctx, req.(*UpdateLabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_DeleteLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).DeleteLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_DeleteLabel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).DeleteLabel(ctx, req.(*DeleteLabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_ListLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).ListLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_ListLabels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).ListLabels(ctx, req.(*ListLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_AddLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).AddLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_AddLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).AddLink(ctx, req.(*AddLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_UploadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).UploadFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_UploadFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).UploadFile(ctx, req.(*UploadFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_DeleteAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).DeleteAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_DeleteAttachment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).DeleteAttachment(ctx, req.(*DeleteAttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_GetProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).GetProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_GetProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).GetProfile(ctx, req.(*GetProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_UpdateProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).UpdateProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_UpdateProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).UpdateProfile(ctx, req.(*UpdateProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_ListHolidayCalendars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHolidayCalendarsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).ListHolidayCalendars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_ListHolidayCalendars_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).ListHolidayCalendars(ctx, req.(*ListHolidayCalendarsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_CreateFeedToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFeedTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).CreateFeedToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_CreateFeedToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).CreateFeedToken(ctx, req.(*CreateFeedTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_ListFeedTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFeedTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).ListFeedTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_ListFeedTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).ListFeedTokens(ctx, req.(*ListFeedTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_RevokeFeedToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeFeedTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).RevokeFeedToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_RevokeFeedToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).RevokeFeedToken(ctx, req.(*RevokeFeedTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_DownloadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadAttachmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventServiceServer).DownloadAttachment(m, &grpc.GenericServerStream[DownloadAttachmentRequest, DownloadAttachmentResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_DownloadAttachmentServer = grpc.ServerStreamingServer[DownloadAttachmentResponse]
+
 // EventService_ServiceDesc is the grpc.ServiceDesc for EventService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -305,7 +827,69 @@ var EventService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMonthEvents",
 			Handler:    _EventService_GetMonthEvents_Handler,
 		},
+		{
+			MethodName: "GetEventsNear",
+			Handler:    _EventService_GetEventsNear_Handler,
+		},
+		{
+			MethodName: "CreateLabel",
+			Handler:    _EventService_CreateLabel_Handler,
+		},
+		{
+			MethodName: "UpdateLabel",
+			Handler:    _EventService_UpdateLabel_Handler,
+		},
+		{
+			MethodName: "DeleteLabel",
+			Handler:    _EventService_DeleteLabel_Handler,
+		},
+		{
+			MethodName: "ListLabels",
+			Handler:    _EventService_ListLabels_Handler,
+		},
+		{
+			MethodName: "AddLink",
+			Handler:    _EventService_AddLink_Handler,
+		},
+		{
+			MethodName: "UploadFile",
+			Handler:    _EventService_UploadFile_Handler,
+		},
+		{
+			MethodName: "DeleteAttachment",
+			Handler:    _EventService_DeleteAttachment_Handler,
+		},
+		{
+			MethodName: "GetProfile",
+			Handler:    _EventService_GetProfile_Handler,
+		},
+		{
+			MethodName: "UpdateProfile",
+			Handler:    _EventService_UpdateProfile_Handler,
+		},
+		{
+			MethodName: "ListHolidayCalendars",
+			Handler:    _EventService_ListHolidayCalendars_Handler,
+		},
+		{
+			MethodName: "CreateFeedToken",
+			Handler:    _EventService_CreateFeedToken_Handler,
+		},
+		{
+			MethodName: "ListFeedTokens",
+			Handler:    _EventService_ListFeedTokens_Handler,
+		},
+		{
+			MethodName: "RevokeFeedToken",
+			Handler:    _EventService_RevokeFeedToken_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DownloadAttachment",
+			Handler:       _EventService_DownloadAttachment_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "event/v1/event_service.proto",
 }
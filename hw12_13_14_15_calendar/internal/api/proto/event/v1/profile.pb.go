@@ -0,0 +1,319 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        v4.25.2
+// source: event/v1/profile.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Профиль доступности владельца: часовой пояс, рабочие часы, выходные дни и календари праздников.
+type Profile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Часовой пояс IANA, например Europe/Moscow, пустой - UTC.
+	TimeZone string `protobuf:"bytes,1,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	// Рабочие часы по дням недели, дни без рабочих часов - нерабочие.
+	// Если рабочие часы не заданы ни для одного дня, рабочее время не ограничено.
+	WorkingDays []*WorkingDay `protobuf:"bytes,2,rep,name=working_days,json=workingDays,proto3" json:"working_days,omitempty"`
+	// Выходные дни в часовом поясе профиля.
+	Holidays []*Date `protobuf:"bytes,3,rep,name=holidays,proto3" json:"holidays,omitempty"`
+	// Проверка новых событий на попадание в рабочее время:
+	// off (по умолчанию) - не проверять, warn - создать с предупреждением, reject - не создавать.
+	AvailabilityCheck string `protobuf:"bytes,4,opt,name=availability_check,json=availabilityCheck,proto3" json:"availability_check,omitempty"`
+	// Календари праздников, на которые подписан владелец (см. ListHolidayCalendars).
+	// Праздники возвращаются в запросах событий за неделю и месяц и не влияют на проверку рабочего времени.
+	HolidayCalendars []string `protobuf:"bytes,5,rep,name=holiday_calendars,json=holidayCalendars,proto3" json:"holiday_calendars,omitempty"`
+}
+
+func (x *Profile) Reset() {
+	*x = Profile{}
+	mi := &file_event_v1_profile_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Profile) ProtoMessage() {}
+
+func (x *Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_event_v1_profile_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Profile.ProtoReflect.Descriptor instead.
+func (*Profile) Descriptor() ([]byte, []int) {
+	return file_event_v1_profile_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Profile) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *Profile) GetWorkingDays() []*WorkingDay {
+	if x != nil {
+		return x.WorkingDays
+	}
+	return nil
+}
+
+func (x *Profile) GetHolidays() []*Date {
+	if x != nil {
+		return x.Holidays
+	}
+	return nil
+}
+
+func (x *Profile) GetAvailabilityCheck() string {
+	if x != nil {
+		return x.AvailabilityCheck
+	}
+	return ""
+}
+
+func (x *Profile) GetHolidayCalendars() []string {
+	if x != nil {
+		return x.HolidayCalendars
+	}
+	return nil
+}
+
+// Рабочие часы дня недели.
+type WorkingDay struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// День недели: 1 - понедельник, ..., 7 - воскресенье.
+	Weekday int32 `protobuf:"varint,1,opt,name=weekday,proto3" json:"weekday,omitempty"`
+	// Начало и конец рабочих часов в формате HH:MM, 24:00 - конец суток.
+	Start string `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End   string `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *WorkingDay) Reset() {
+	*x = WorkingDay{}
+	mi := &file_event_v1_profile_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkingDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkingDay) ProtoMessage() {}
+
+func (x *WorkingDay) ProtoReflect() protoreflect.Message {
+	mi := &file_event_v1_profile_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkingDay.ProtoReflect.Descriptor instead.
+func (*WorkingDay) Descriptor() ([]byte, []int) {
+	return file_event_v1_profile_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WorkingDay) GetWeekday() int32 {
+	if x != nil {
+		return x.Weekday
+	}
+	return 0
+}
+
+func (x *WorkingDay) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *WorkingDay) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+// Предупреждение: операция выполнена, но нарушает настройки владельца.
+type Warning struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Машиночитаемая причина, например OUTSIDE_WORKING_HOURS.
+	Reason  string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *Warning) Reset() {
+	*x = Warning{}
+	mi := &file_event_v1_profile_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warning) ProtoMessage() {}
+
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_event_v1_profile_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warning.ProtoReflect.Descriptor instead.
+func (*Warning) Descriptor() ([]byte, []int) {
+	return file_event_v1_profile_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Warning) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Warning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_event_v1_profile_proto protoreflect.FileDescriptor
+
+var file_event_v1_profile_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e,
+	0x76, 0x31, 0x1a, 0x13, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x61, 0x74,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xe7, 0x01, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x7a, 0x6f, 0x6e, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x5a, 0x6f, 0x6e, 0x65,
+	0x12, 0x37, 0x0a, 0x0c, 0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x64, 0x61, 0x79, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x61, 0x79, 0x52, 0x0b, 0x77, 0x6f,
+	0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x61, 0x79, 0x73, 0x12, 0x2a, 0x0a, 0x08, 0x68, 0x6f, 0x6c,
+	0x69, 0x64, 0x61, 0x79, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x61, 0x74, 0x65, 0x52, 0x08, 0x68, 0x6f, 0x6c,
+	0x69, 0x64, 0x61, 0x79, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62,
+	0x69, 0x6c, 0x69, 0x74, 0x79, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x11, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x12, 0x2b, 0x0a, 0x11, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x5f,
+	0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x10, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72,
+	0x73, 0x22, 0x4e, 0x0a, 0x0a, 0x57, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x61, 0x79, 0x12,
+	0x18, 0x0a, 0x07, 0x77, 0x65, 0x65, 0x6b, 0x64, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x07, 0x77, 0x65, 0x65, 0x6b, 0x64, 0x61, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x6e,
+	0x64, 0x22, 0x3b, 0x0a, 0x07, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x4d,
+	0x5a, 0x4b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x69, 0x6d,
+	0x61, 0x2d, 0x73, 0x74, 0x75, 0x64, 0x79, 0x2f, 0x6f, 0x74, 0x75, 0x73, 0x32, 0x34, 0x30, 0x35,
+	0x2f, 0x68, 0x77, 0x31, 0x32, 0x5f, 0x31, 0x33, 0x5f, 0x31, 0x34, 0x5f, 0x31, 0x35, 0x5f, 0x63,
+	0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c,
+	0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_event_v1_profile_proto_rawDescOnce sync.Once
+	file_event_v1_profile_proto_rawDescData = file_event_v1_profile_proto_rawDesc
+)
+
+func file_event_v1_profile_proto_rawDescGZIP() []byte {
+	file_event_v1_profile_proto_rawDescOnce.Do(func() {
+		file_event_v1_profile_proto_rawDescData = protoimpl.X.CompressGZIP(file_event_v1_profile_proto_rawDescData)
+	})
+	return file_event_v1_profile_proto_rawDescData
+}
+
+var file_event_v1_profile_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_event_v1_profile_proto_goTypes = []any{
+	(*Profile)(nil),    // 0: event.v1.Profile
+	(*WorkingDay)(nil), // 1: event.v1.WorkingDay
+	(*Warning)(nil),    // 2: event.v1.Warning
+	(*Date)(nil),       // 3: event.v1.Date
+}
+var file_event_v1_profile_proto_depIdxs = []int32{
+	1, // 0: event.v1.Profile.working_days:type_name -> event.v1.WorkingDay
+	3, // 1: event.v1.Profile.holidays:type_name -> event.v1.Date
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_event_v1_profile_proto_init() }
+func file_event_v1_profile_proto_init() {
+	if File_event_v1_profile_proto != nil {
+		return
+	}
+	file_event_v1_date_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_event_v1_profile_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_event_v1_profile_proto_goTypes,
+		DependencyIndexes: file_event_v1_profile_proto_depIdxs,
+		MessageInfos:      file_event_v1_profile_proto_msgTypes,
+	}.Build()
+	File_event_v1_profile_proto = out.File
+	file_event_v1_profile_proto_rawDesc = nil
+	file_event_v1_profile_proto_goTypes = nil
+	file_event_v1_profile_proto_depIdxs = nil
+}
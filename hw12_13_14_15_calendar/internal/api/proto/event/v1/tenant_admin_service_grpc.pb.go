@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.2
+// source: event/v1/tenant_admin_service.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TenantAdminService_GetTenantStats_FullMethodName     = "/event.v1.TenantAdminService/GetTenantStats"
+	TenantAdminService_ExportTenantEvents_FullMethodName = "/event.v1.TenantAdminService/ExportTenantEvents"
+	TenantAdminService_WipeTenant_FullMethodName         = "/event.v1.TenantAdminService/WipeTenant"
+)
+
+// TenantAdminServiceClient is the client API for TenantAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Административные операции над данными тенанта.
+// Доступны только сервисам из списка grpc.admin_services (CN клиентского сертификата mTLS),
+// через http-шлюз не публикуются.
+type TenantAdminServiceClient interface {
+	// Количество событий тенанта.
+	GetTenantStats(ctx context.Context, in *GetTenantStatsRequest, opts ...grpc.CallOption) (*GetTenantStatsResponse, error)
+	// Выгрузка всех событий тенанта потоком, по одному событию в сообщении.
+	ExportTenantEvents(ctx context.Context, in *ExportTenantEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportTenantEventsResponse], error)
+	// Удаление всех событий и вложений тенанта.
+	WipeTenant(ctx context.Context, in *WipeTenantRequest, opts ...grpc.CallOption) (*WipeTenantResponse, error)
+}
+
+type tenantAdminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTenantAdminServiceClient(cc grpc.ClientConnInterface) TenantAdminServiceClient {
+	return &tenantAdminServiceClient{cc}
+}
+
+func (c *tenantAdminServiceClient) GetTenantStats(ctx context.Context, in *GetTenantStatsRequest, opts ...grpc.CallOption) (*GetTenantStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTenantStatsResponse)
+	err := c.cc.Invoke(ctx, TenantAdminService_GetTenantStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantAdminServiceClient) ExportTenantEvents(ctx context.Context, in *ExportTenantEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportTenantEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TenantAdminService_ServiceDesc.Streams[0], TenantAdminService_ExportTenantEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportTenantEventsRequest, ExportTenantEventsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TenantAdminService_ExportTenantEventsClient = grpc.ServerStreamingClient[ExportTenantEventsResponse]
+
+func (c *tenantAdminServiceClient) WipeTenant(ctx context.Context, in *WipeTenantRequest, opts ...grpc.CallOption) (*WipeTenantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WipeTenantResponse)
+	err := c.cc.Invoke(ctx, TenantAdminService_WipeTenant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TenantAdminServiceServer is the server API for TenantAdminService service.
+// All implementations must embed UnimplementedTenantAdminServiceServer
+// for forward compatibility.
+//
+// Административные операции над данными тенанта.
+// Доступны только сервисам из списка grpc.admin_services (CN клиентского сертификата mTLS),
+// через http-шлюз не публикуются.
+type TenantAdminServiceServer interface {
+	// Количество событий тенанта.
+	GetTenantStats(context.Context, *GetTenantStatsRequest) (*GetTenantStatsResponse, error)
+	// Выгрузка всех событий тенанта потоком, по одному событию в сообщении.
+	ExportTenantEvents(*ExportTenantEventsRequest, grpc.ServerStreamingServer[ExportTenantEventsResponse]) error
+	// Удаление всех событий и вложений тенанта.
+	WipeTenant(context.Context, *WipeTenantRequest) (*WipeTenantResponse, error)
+	mustEmbedUnimplementedTenantAdminServiceServer()
+}
+
+// UnimplementedTenantAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTenantAdminServiceServer struct{}
+
+func (UnimplementedTenantAdminServiceServer) GetTenantStats(context.Context, *GetTenantStatsRequest) (*GetTenantStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTenantStats not implemented")
+}
+func (UnimplementedTenantAdminServiceServer) ExportTenantEvents(*ExportTenantEventsRequest, grpc.ServerStreamingServer[ExportTenantEventsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportTenantEvents not implemented")
+}
+func (UnimplementedTenantAdminServiceServer) WipeTenant(context.Context, *WipeTenantRequest) (*WipeTenantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WipeTenant not implemented")
+}
+func (UnimplementedTenantAdminServiceServer) mustEmbedUnimplementedTenantAdminServiceServer() {}
+func (UnimplementedTenantAdminServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeTenantAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TenantAdminServiceServer will
+// result in compilation errors.
+type UnsafeTenantAdminServiceServer interface {
+	mustEmbedUnimplementedTenantAdminServiceServer()
+}
+
+func RegisterTenantAdminServiceServer(s grpc.ServiceRegistrar, srv TenantAdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTenantAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TenantAdminService_ServiceDesc, srv)
+}
+
+func _TenantAdminService_GetTenantStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTenantStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantAdminServiceServer).GetTenantStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantAdminService_GetTenantStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantAdminServiceServer).GetTenantStats(ctx, req.(*GetTenantStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TenantAdminService_ExportTenantEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportTenantEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TenantAdminServiceServer).ExportTenantEvents(m, &grpc.GenericServerStream[ExportTenantEventsRequest, ExportTenantEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TenantAdminService_ExportTenantEventsServer = grpc.ServerStreamingServer[ExportTenantEventsResponse]
+
+func _TenantAdminService_WipeTenant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WipeTenantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TenantAdminServiceServer).WipeTenant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TenantAdminService_WipeTenant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TenantAdminServiceServer).WipeTenant(ctx, req.(*WipeTenantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TenantAdminService_ServiceDesc is the grpc.ServiceDesc for TenantAdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TenantAdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "event.v1.TenantAdminService",
+	HandlerType: (*TenantAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTenantStats",
+			Handler:    _TenantAdminService_GetTenantStats_Handler,
+		},
+		{
+			MethodName: "WipeTenant",
+			Handler:    _TenantAdminService_WipeTenant_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportTenantEvents",
+			Handler:       _TenantAdminService_ExportTenantEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "event/v1/tenant_admin_service.proto",
+}
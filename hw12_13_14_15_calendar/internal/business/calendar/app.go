@@ -4,11 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
 )
 
+var ErrTenantQuotaExceeded = domainerr.New(
+	domainerr.KindResourceExhausted,
+	"TENANT_QUOTA_EXCEEDED",
+	"tenant event quota exceeded",
+)
+
+var ErrInvalidPeriod = domainerr.NewField("to", "INVALID_PERIOD", "the end of period must be after the start")
+
 type EventStorage interface {
 	// AddEvent добавляет событие в коллекцию.
 	AddEvent(ctx context.Context, event model.Event) error
@@ -16,39 +29,183 @@ type EventStorage interface {
 	// UpdateEvent обновляет событие в коллекции.
 	UpdateEvent(ctx context.Context, event model.Event) error
 
-	// FindEvent находит собитие в коллекции по ownerID и eventID.
-	FindEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) (model.Event, error)
+	// FindEvent находит собитие в коллекции рабочего пространства tenantID по ownerID и eventID.
+	FindEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) (model.Event, error)
+
+	// DeleteEvent удаляет событие из коллекции рабочего пространства tenantID по ownerID и eventID.
+	DeleteEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) error
+
+	// QueryEvents находит все события в коллекции рабочего пространства tenantID для ownerID,
+	// которые запланированы на указанный промежуток [from, to) и подходят под фильтр filter.
+	QueryEvents(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		from time.Time,
+		to time.Time,
+		filter model.Filter,
+	) ([]model.Event, error)
+
+	// QueryEventsNear находит все события в коллекции рабочего пространства tenantID для ownerID,
+	// которые запланированы на указанный промежуток [from, to) и проходят в области area.
+	QueryEventsNear(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		from time.Time,
+		to time.Time,
+		area model.Area,
+	) ([]model.Event, error)
+
+	// FindProfile находит профиль доступности владельца, если профиль не сохранялся - ErrProfileNotFound.
+	FindProfile(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) (model.Profile, error)
+
+	// SaveProfile сохраняет профиль доступности владельца.
+	SaveProfile(ctx context.Context, profile model.Profile) error
+
+	// CountTenantEvents возвращает количество событий рабочего пространства tenantID.
+	CountTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error)
+
+	// QueryTenantEvents находит все события рабочего пространства tenantID,
+	// упорядоченные по владельцу и времени начала.
+	QueryTenantEvents(ctx context.Context, tenantID model.TenantID) ([]model.Event, error)
+
+	// DeleteTenantEvents удаляет все данные рабочего пространства tenantID
+	// и возвращает количество удалённых событий.
+	DeleteTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error)
+
+	// AddLabel добавляет метку в каталог владельца.
+	AddLabel(ctx context.Context, label model.OwnerLabel) error
+
+	// UpdateLabel обновляет метку в каталоге владельца.
+	UpdateLabel(ctx context.Context, label model.OwnerLabel) error
+
+	// DeleteLabel удаляет метку name из каталога владельца и снимает её со всех событий владельца.
+	DeleteLabel(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, name model.Label) error
 
-	// DeleteEvent удаляет событие из коллекции по ownerID и eventID.
-	DeleteEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) error
+	// QueryLabels возвращает каталог меток владельца, упорядоченный по имени.
+	QueryLabels(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) ([]model.OwnerLabel, error)
 
-	// QueryEvents находит все события в коллекции для ownerID, которые запланированы на указанный промежуток [from, to).
-	QueryEvents(ctx context.Context, ownerID model.OwnerID, from time.Time, to time.Time) ([]model.Event, error)
+	// AddFeedToken добавляет ссылку на календарь владельца,
+	// если у него меньше maxTokens ссылок, иначе - model.ErrTooManyFeedTokens.
+	AddFeedToken(ctx context.Context, token model.FeedToken, maxTokens int) error
+
+	// QueryFeedTokens возвращает ссылки на календарь владельца, упорядоченные по времени создания.
+	QueryFeedTokens(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) ([]model.FeedToken, error)
+
+	// DeleteFeedToken удаляет ссылку tokenID на календарь владельца, если ссылки нет - ErrFeedTokenNotFound.
+	DeleteFeedToken(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		tokenID model.FeedTokenID,
+	) error
+
+	// FindFeedToken находит ссылку на календарь по хешу секрета, если ссылки нет - ErrFeedTokenNotFound.
+	FindFeedToken(ctx context.Context, hash model.FeedTokenHash) (model.FeedToken, error)
+}
+
+// Quotas - ограничения количества событий в рабочих пространствах.
+type Quotas struct {
+	// MaxEvents - ограничение для пространств, не указанных в Tenants, 0 - без ограничений.
+	MaxEvents int
+
+	// Tenants - ограничения для отдельных пространств, 0 - без ограничений.
+	Tenants map[model.TenantID]int
+}
+
+// MaxTenantEvents возвращает допустимое количество событий в рабочем пространстве tenantID, 0 - без ограничений.
+func (q Quotas) MaxTenantEvents(tenantID model.TenantID) int {
+	if maxEvents, ok := q.Tenants[tenantID]; ok {
+		return maxEvents
+	}
+
+	return q.MaxEvents
 }
 
 type App struct {
 	logger  *slog.Logger
+	clock   clock.Clock
 	storage EventStorage
+	quotas  atomic.Pointer[Quotas]
+
+	blobs            blob.Store
+	attachmentLimits AttachmentLimits
+
+	holidaySource HolidaySource
+
+	// attachmentsMx упорядочивает изменения вложений событий (чтение и обновление события) в рамках процесса.
+	attachmentsMx sync.Mutex
 }
 
-func NewApp(logger *slog.Logger, storage EventStorage) *App {
-	return &App{
-		logger:  logger,
-		storage: storage,
+func NewApp(logger *slog.Logger, clock clock.Clock, storage EventStorage) *App {
+	a := &App{
+		logger:           logger,
+		clock:            clock,
+		storage:          storage,
+		attachmentLimits: DefaultAttachmentLimits,
 	}
+	a.SetQuotas(Quotas{})
+
+	return a
 }
 
-func (a *App) CreateEvent(ctx context.Context, event model.Event) error {
-	err := a.storage.AddEvent(ctx, event)
+// SetQuotas заменяет ограничения количества событий в рабочих пространствах на quotas.
+// Уже созданные события не удаляются, даже если их больше нового ограничения.
+func (a *App) SetQuotas(quotas Quotas) {
+	a.quotas.Store(&quotas)
+}
+
+// CreateEvent создаёт событие event и возвращает предупреждения - ошибки предметной области,
+// не помешавшие созданию события.
+// Если владелец включил проверку рабочего времени (см. Profile.Check), то событие вне рабочего времени
+// не создаётся (model.AvailabilityCheckReject) или создаётся с предупреждением (model.AvailabilityCheckWarn).
+func (a *App) CreateEvent(ctx context.Context, event model.Event) (warnings []error, err error) {
+	err = a.checkQuota(ctx, event.TenantID())
+	if err != nil {
+		return nil, fmt.Errorf("can't create event: %w", err)
+	}
+
+	warnings, err = a.checkAvailability(ctx, event)
+	if err != nil {
+		return nil, fmt.Errorf("can't create event: %w", err)
+	}
+
+	err = a.storage.AddEvent(ctx, event)
 	if err != nil {
-		return fmt.Errorf("can't create event: %w", err)
+		return nil, fmt.Errorf("can't create event: %w", err)
+	}
+
+	return warnings, nil
+}
+
+// checkQuota проверяет, что в рабочем пространстве tenantID можно создать ещё одно событие.
+// Проверка не атомарна с добавлением: при одновременном создании событий квота может быть немного превышена.
+func (a *App) checkQuota(ctx context.Context, tenantID model.TenantID) error {
+	maxEvents := a.quotas.Load().MaxTenantEvents(tenantID)
+	if maxEvents <= 0 {
+		return nil
+	}
+
+	count, err := a.storage.CountTenantEvents(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("can't count tenant events: %w", err)
+	}
+
+	if count >= maxEvents {
+		return ErrTenantQuotaExceeded
 	}
 
 	return nil
 }
 
-func (a *App) FindEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) (model.Event, error) {
-	event, err := a.storage.FindEvent(ctx, ownerID, eventID)
+func (a *App) FindEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (model.Event, error) {
+	event, err := a.storage.FindEvent(ctx, tenantID, ownerID, eventID)
 	if err != nil {
 		return model.Event{}, fmt.Errorf("can't find event: %w", err)
 	}
@@ -56,8 +213,20 @@ func (a *App) FindEvent(ctx context.Context, ownerID model.OwnerID, eventID mode
 	return event, nil
 }
 
+// UpdateEvent обновляет событие event. Вложения события сохраняются:
+// они изменяются только через AddLink, UploadFile и DeleteAttachment.
 func (a *App) UpdateEvent(ctx context.Context, event model.Event) error {
-	err := a.storage.UpdateEvent(ctx, event)
+	a.attachmentsMx.Lock()
+	defer a.attachmentsMx.Unlock()
+
+	current, err := a.storage.FindEvent(ctx, event.TenantID(), event.OwnerID(), event.EventID())
+	if err != nil {
+		return fmt.Errorf("can't update event: %w", err)
+	}
+
+	event.Attachments = current.Attachments
+
+	err = a.storage.UpdateEvent(ctx, event)
 	if err != nil {
 		return fmt.Errorf("can't update event: %w", err)
 	}
@@ -65,26 +234,31 @@ func (a *App) UpdateEvent(ctx context.Context, event model.Event) error {
 	return nil
 }
 
-func (a *App) DeleteEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) error {
-	err := a.storage.DeleteEvent(ctx, ownerID, eventID)
+// DeleteEvent удаляет событие вместе с файлами его вложений.
+func (a *App) DeleteEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) error {
+	err := a.storage.DeleteEvent(ctx, tenantID, ownerID, eventID)
 	if err != nil {
 		return fmt.Errorf("can't delete event: %w", err)
 	}
 
+	a.deleteEventFiles(ctx, tenantID, ownerID, eventID)
+
 	return nil
 }
 
 func (a *App) GetDayEvents(
 	ctx context.Context,
+	tenantID model.TenantID,
 	ownerID model.OwnerID,
 	year int,
 	month int,
 	day int,
+	filter model.Filter,
 ) ([]model.Event, error) {
 	from := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
 	to := time.Date(year, time.Month(month), day+1, 0, 0, 0, 0, time.UTC)
 
-	events, err := a.storage.QueryEvents(ctx, ownerID, from, to)
+	events, err := a.storage.QueryEvents(ctx, tenantID, ownerID, from, to, filter)
 	if err != nil {
 		return nil, fmt.Errorf("can't get day events: %w", err)
 	}
@@ -92,37 +266,139 @@ func (a *App) GetDayEvents(
 	return events, nil
 }
 
+// GetWeekEvents возвращает события владельца ownerID за неделю, начиная с дня year-month-day,
+// и праздники календарей, на которые он подписан (см. model.Profile.HolidayCalendars).
 func (a *App) GetWeekEvents(
 	ctx context.Context,
+	tenantID model.TenantID,
 	ownerID model.OwnerID,
 	year int,
 	month int,
 	day int,
-) ([]model.Event, error) {
+	filter model.Filter,
+) (events []model.Event, holidays []model.Holiday, err error) {
 	from := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
 	to := time.Date(year, time.Month(month), day+7, 0, 0, 0, 0, time.UTC)
 
-	events, err := a.storage.QueryEvents(ctx, ownerID, from, to)
+	events, err = a.storage.QueryEvents(ctx, tenantID, ownerID, from, to, filter)
 	if err != nil {
-		return nil, fmt.Errorf("can't get week events: %w", err)
+		return nil, nil, fmt.Errorf("can't get week events: %w", err)
 	}
 
-	return events, nil
+	holidays, err = a.holidays(ctx, tenantID, ownerID, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get week holidays: %w", err)
+	}
+
+	return events, holidays, nil
 }
 
+// GetMonthEvents возвращает события владельца ownerID за месяц year-month
+// и праздники календарей, на которые он подписан (см. model.Profile.HolidayCalendars).
 func (a *App) GetMonthEvents(
 	ctx context.Context,
+	tenantID model.TenantID,
 	ownerID model.OwnerID,
 	year int,
 	month int,
-) ([]model.Event, error) {
+	filter model.Filter,
+) (events []model.Event, holidays []model.Holiday, err error) {
 	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	to := time.Date(year, time.Month(month+1), 1, 0, 0, 0, 0, time.UTC)
 
-	events, err := a.storage.QueryEvents(ctx, ownerID, from, to)
+	events, err = a.storage.QueryEvents(ctx, tenantID, ownerID, from, to, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get month events: %w", err)
+	}
+
+	holidays, err = a.holidays(ctx, tenantID, ownerID, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get month holidays: %w", err)
+	}
+
+	return events, holidays, nil
+}
+
+// GetEvents возвращает события владельца ownerID, запланированные на промежуток [from, to).
+// Возвращает ошибку валидации ErrInvalidPeriod, если to не после from.
+func (a *App) GetEvents(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+) ([]model.Event, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidPeriod
+	}
+
+	events, err := a.storage.QueryEvents(ctx, tenantID, ownerID, from, to, model.Filter{})
+	if err != nil {
+		return nil, fmt.Errorf("can't get events: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetEventsNear возвращает события владельца ownerID, запланированные на промежуток [from, to)
+// и проходящие в области area.
+// Возвращает ошибку валидации ErrInvalidPeriod, если to не после from.
+func (a *App) GetEventsNear(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	area model.Area,
+) ([]model.Event, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidPeriod
+	}
+
+	events, err := a.storage.QueryEventsNear(ctx, tenantID, ownerID, from, to, area)
 	if err != nil {
-		return nil, fmt.Errorf("can't get month events: %w", err)
+		return nil, fmt.Errorf("can't get events near: %w", err)
 	}
 
 	return events, nil
 }
+
+func (a *App) CreateLabel(ctx context.Context, label model.OwnerLabel) error {
+	err := a.storage.AddLabel(ctx, label)
+	if err != nil {
+		return fmt.Errorf("can't create label: %w", err)
+	}
+
+	return nil
+}
+
+func (a *App) UpdateLabel(ctx context.Context, label model.OwnerLabel) error {
+	err := a.storage.UpdateLabel(ctx, label)
+	if err != nil {
+		return fmt.Errorf("can't update label: %w", err)
+	}
+
+	return nil
+}
+
+func (a *App) DeleteLabel(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, name model.Label) error {
+	err := a.storage.DeleteLabel(ctx, tenantID, ownerID, name)
+	if err != nil {
+		return fmt.Errorf("can't delete label: %w", err)
+	}
+
+	return nil
+}
+
+func (a *App) ListLabels(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) ([]model.OwnerLabel, error) {
+	labels, err := a.storage.QueryLabels(ctx, tenantID, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("can't list labels: %w", err)
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,239 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+)
+
+var (
+	ErrAttachmentNotFound = domainerr.New(domainerr.KindNotFound, "ATTACHMENT_NOT_FOUND", "attachment not found")
+	ErrTooManyAttachments = domainerr.New(
+		domainerr.KindResourceExhausted,
+		"TOO_MANY_ATTACHMENTS",
+		"too many attachments",
+	)
+	ErrFilesDisabled = domainerr.New(
+		domainerr.KindFailedPrecondition,
+		"FILE_ATTACHMENTS_DISABLED",
+		"file attachments are disabled",
+	)
+	ErrNotFileAttachment = domainerr.New(
+		domainerr.KindFailedPrecondition,
+		"NOT_FILE_ATTACHMENT",
+		"attachment is not a file",
+	)
+)
+
+// AttachmentLimits - ограничения вложений событий.
+type AttachmentLimits struct {
+	// MaxFileSize - допустимый размер файла вложения в байтах.
+	MaxFileSize int64
+
+	// MaxPerEvent - допустимое количество вложений события, 0 - без ограничений.
+	MaxPerEvent int
+}
+
+// DefaultAttachmentLimits - ограничения вложений по умолчанию.
+var DefaultAttachmentLimits = AttachmentLimits{
+	MaxFileSize: 1 << 20,
+	MaxPerEvent: 10,
+}
+
+// SetAttachments задаёт хранилище файлов вложений blobs и ограничения вложений limits.
+// Без хранилища файлов (nil) вложениями событий могут быть только ссылки.
+// Должен вызываться до начала обработки запросов.
+func (a *App) SetAttachments(blobs blob.Store, limits AttachmentLimits) {
+	if limits.MaxFileSize <= 0 {
+		limits.MaxFileSize = DefaultAttachmentLimits.MaxFileSize
+	}
+
+	a.blobs = blobs
+	a.attachmentLimits = limits
+}
+
+// AddLink добавляет к событию вложение-ссылку attachment.
+func (a *App) AddLink(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+	attachment model.Attachment,
+) error {
+	if err := a.addAttachment(ctx, tenantID, ownerID, eventID, attachment); err != nil {
+		return fmt.Errorf("can't add link: %w", err)
+	}
+
+	return nil
+}
+
+// UploadFile сохраняет содержимое content в хранилище файлов и добавляет к событию вложение-файл attachment.
+// Возвращает вложение с размером сохранённого файла.
+func (a *App) UploadFile(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+	attachment model.Attachment,
+	content io.Reader,
+) (model.Attachment, error) {
+	if a.blobs == nil {
+		return model.Attachment{}, fmt.Errorf("can't upload file: %w", ErrFilesDisabled)
+	}
+
+	// проверяем событие до загрузки содержимого, чтобы не сохранять файл напрасно
+	event, err := a.storage.FindEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return model.Attachment{}, fmt.Errorf("can't upload file: %w", err)
+	}
+
+	if err := a.checkAttachmentsLimit(event); err != nil {
+		return model.Attachment{}, fmt.Errorf("can't upload file: %w", err)
+	}
+
+	key := blob.AttachmentKey(tenantID, ownerID, eventID, attachment.ID)
+
+	attachment.Size, err = a.blobs.Put(ctx, key, content, a.attachmentLimits.MaxFileSize)
+	if err != nil {
+		return model.Attachment{}, fmt.Errorf("can't upload file: %w", err)
+	}
+
+	if err := a.addAttachment(ctx, tenantID, ownerID, eventID, attachment); err != nil {
+		if deleteErr := a.blobs.Delete(context.WithoutCancel(ctx), key); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("can't delete uploaded file: %w", deleteErr))
+		}
+
+		return model.Attachment{}, fmt.Errorf("can't upload file: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// DeleteAttachment удаляет вложение attachmentID события и содержимое файла, если вложение - файл.
+func (a *App) DeleteAttachment(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+	attachmentID model.AttachmentID,
+) error {
+	a.attachmentsMx.Lock()
+	defer a.attachmentsMx.Unlock()
+
+	event, err := a.storage.FindEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return fmt.Errorf("can't delete attachment: %w", err)
+	}
+
+	i := slices.IndexFunc(event.Attachments, func(att model.Attachment) bool { return att.ID == attachmentID })
+	if i < 0 {
+		return fmt.Errorf("can't delete attachment: %w", ErrAttachmentNotFound)
+	}
+
+	attachment := event.Attachments[i]
+	event.Attachments = slices.Delete(slices.Clone(event.Attachments), i, i+1)
+
+	if err := a.storage.UpdateEvent(ctx, event); err != nil {
+		return fmt.Errorf("can't delete attachment: %w", err)
+	}
+
+	if attachment.IsFile() && a.blobs != nil {
+		// вложение уже удалено из события: файл без вложения не виден клиентам, поэтому ошибка только логируется
+		err := a.blobs.Delete(ctx, blob.AttachmentKey(tenantID, ownerID, eventID, attachmentID))
+		if err != nil {
+			a.logger.ErrorContext(ctx, "can't delete attachment file", slog.String("error", err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// OpenAttachment находит вложение-файл attachmentID события и открывает его содержимое для чтения.
+// Содержимое должно быть закрыто вызывающей стороной.
+func (a *App) OpenAttachment(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+	attachmentID model.AttachmentID,
+) (model.Attachment, io.ReadCloser, error) {
+	event, err := a.storage.FindEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return model.Attachment{}, nil, fmt.Errorf("can't open attachment: %w", err)
+	}
+
+	i := slices.IndexFunc(event.Attachments, func(att model.Attachment) bool { return att.ID == attachmentID })
+	if i < 0 {
+		return model.Attachment{}, nil, fmt.Errorf("can't open attachment: %w", ErrAttachmentNotFound)
+	}
+
+	attachment := event.Attachments[i]
+	if !attachment.IsFile() {
+		return model.Attachment{}, nil, fmt.Errorf("can't open attachment: %w", ErrNotFileAttachment)
+	}
+
+	if a.blobs == nil {
+		return model.Attachment{}, nil, fmt.Errorf("can't open attachment: %w", ErrFilesDisabled)
+	}
+
+	content, err := a.blobs.Open(ctx, blob.AttachmentKey(tenantID, ownerID, eventID, attachmentID))
+	if err != nil {
+		return model.Attachment{}, nil, fmt.Errorf("can't open attachment: %w", err)
+	}
+
+	return attachment, content, nil
+}
+
+// addAttachment добавляет вложение attachment к событию с учётом ограничения количества вложений.
+func (a *App) addAttachment(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+	attachment model.Attachment,
+) error {
+	a.attachmentsMx.Lock()
+	defer a.attachmentsMx.Unlock()
+
+	event, err := a.storage.FindEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.checkAttachmentsLimit(event); err != nil {
+		return err
+	}
+
+	event.Attachments = append(slices.Clone(event.Attachments), attachment)
+
+	return a.storage.UpdateEvent(ctx, event)
+}
+
+// checkAttachmentsLimit проверяет, что к событию event можно добавить ещё одно вложение.
+func (a *App) checkAttachmentsLimit(event model.Event) error {
+	maxAttachments := a.attachmentLimits.MaxPerEvent
+	if maxAttachments > 0 && len(event.Attachments) >= maxAttachments {
+		return ErrTooManyAttachments
+	}
+
+	return nil
+}
+
+// deleteEventFiles удаляет содержимое файлов вложений удалённого события.
+// Событие уже удалено, поэтому ошибка только логируется.
+func (a *App) deleteEventFiles(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) {
+	if a.blobs == nil {
+		return
+	}
+
+	if err := a.blobs.DeletePrefix(ctx, blob.EventPrefix(tenantID, ownerID, eventID)); err != nil {
+		a.logger.ErrorContext(ctx, "can't delete event attachment files", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+const (
+	// FeedPastPeriod - за какой прошедший период события попадают в ленту календаря.
+	FeedPastPeriod = 30 * 24 * time.Hour
+
+	// FeedFuturePeriod - на какой предстоящий период события попадают в ленту календаря.
+	FeedFuturePeriod = 365 * 24 * time.Hour
+)
+
+// CreateFeedToken создаёт ссылку name на календарь владельца ownerID в рабочем пространстве tenantID.
+// Возвращает ссылку и её секрет: секрет не хранится, поэтому получить его повторно нельзя.
+// Если у владельца уже model.MaxFeedTokens ссылок - model.ErrTooManyFeedTokens.
+func (a *App) CreateFeedToken(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name string,
+) (model.FeedToken, model.FeedSecret, error) {
+	secret, err := model.NewFeedSecret()
+	if err != nil {
+		return model.FeedToken{}, "", fmt.Errorf("can't create feed token: %w", err)
+	}
+
+	token := model.NewFeedToken(tenantID, ownerID, model.NewFeedTokenID(), secret.Hash())
+	token.Name = name
+	token.CreatedAt = a.clock.Now().UTC().Truncate(time.Second)
+
+	err = a.storage.AddFeedToken(ctx, token, model.MaxFeedTokens)
+	if err != nil {
+		return model.FeedToken{}, "", fmt.Errorf("can't create feed token: %w", err)
+	}
+
+	return token, secret, nil
+}
+
+// ListFeedTokens возвращает ссылки на календарь владельца ownerID, упорядоченные по времени создания.
+func (a *App) ListFeedTokens(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) ([]model.FeedToken, error) {
+	tokens, err := a.storage.QueryFeedTokens(ctx, tenantID, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("can't list feed tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RevokeFeedToken отзывает ссылку tokenID на календарь владельца ownerID:
+// календарь по этой ссылке больше не доступен.
+func (a *App) RevokeFeedToken(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	tokenID model.FeedTokenID,
+) error {
+	err := a.storage.DeleteFeedToken(ctx, tenantID, ownerID, tokenID)
+	if err != nil {
+		return fmt.Errorf("can't revoke feed token: %w", err)
+	}
+
+	return nil
+}
+
+// Feed находит ссылку на календарь по секрету secret и возвращает её вместе с событиями владельца
+// за промежуток [now-FeedPastPeriod, now+FeedFuturePeriod).
+// Для неизвестного или отозванного секрета возвращает model.ErrInvalidFeedSecret.
+func (a *App) Feed(
+	ctx context.Context,
+	secret model.FeedSecret,
+	now time.Time,
+) (model.FeedToken, []model.Event, error) {
+	token, err := a.storage.FindFeedToken(ctx, secret.Hash())
+	if err != nil {
+		if errors.Is(err, storage.ErrFeedTokenNotFound) {
+			return model.FeedToken{}, nil, model.ErrInvalidFeedSecret
+		}
+
+		return model.FeedToken{}, nil, fmt.Errorf("can't get feed: %w", err)
+	}
+
+	events, err := a.storage.QueryEvents(
+		ctx,
+		token.TenantID(),
+		token.OwnerID(),
+		now.Add(-FeedPastPeriod),
+		now.Add(FeedFuturePeriod),
+		model.Filter{},
+	)
+	if err != nil {
+		return model.FeedToken{}, nil, fmt.Errorf("can't get feed: %w", err)
+	}
+
+	return token, events, nil
+}
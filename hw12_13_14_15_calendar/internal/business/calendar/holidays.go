@@ -0,0 +1,81 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// HolidaySource - источник календарей праздников, доступных для подписки владельцам.
+type HolidaySource interface {
+	// Calendars возвращает доступные календари праздников, упорядоченные по имени.
+	Calendars() []model.HolidayCalendarInfo
+
+	// HasCalendar сообщает, что календарь праздников name доступен.
+	HasCalendar(name model.HolidayCalendar) bool
+
+	// Holidays возвращает праздники календарей calendars, приходящиеся на дни промежутка [from, to),
+	// упорядоченные по дате. Недоступные календари пропускаются.
+	Holidays(calendars []model.HolidayCalendar, from time.Time, to time.Time) []model.Holiday
+}
+
+// SetHolidays задаёт источник календарей праздников holidays.
+// Без источника (nil) календари праздников недоступны.
+// Должен вызываться до начала обработки запросов.
+func (a *App) SetHolidays(holidays HolidaySource) {
+	a.holidaySource = holidays
+}
+
+// ListHolidayCalendars возвращает календари праздников, доступные для подписки.
+func (a *App) ListHolidayCalendars(_ context.Context) []model.HolidayCalendarInfo {
+	if a.holidaySource == nil {
+		return nil
+	}
+
+	return a.holidaySource.Calendars()
+}
+
+// checkHolidayCalendars проверяет, что календари праздников calendars, на которые владелец
+// ещё не подписан в профиле current, доступны. Иначе возвращает ErrHolidayCalendarNotFound.
+// Ранее подписанные календари могут быть недоступны (например, файл календаря удалён).
+func (a *App) checkHolidayCalendars(current model.Profile, calendars []model.HolidayCalendar) error {
+	for _, name := range calendars {
+		if slices.Contains(current.HolidayCalendars, name) {
+			continue
+		}
+
+		if a.holidaySource == nil || !a.holidaySource.HasCalendar(name) {
+			return fmt.Errorf("%w: '%s'", model.ErrHolidayCalendarNotFound, name)
+		}
+	}
+
+	return nil
+}
+
+// holidays возвращает праздники календарей, на которые подписан владелец ownerID,
+// приходящиеся на промежуток [from, to).
+func (a *App) holidays(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+) ([]model.Holiday, error) {
+	if a.holidaySource == nil {
+		return nil, nil
+	}
+
+	profile, err := a.GetProfile(ctx, tenantID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(profile.HolidayCalendars) == 0 {
+		return nil, nil
+	}
+
+	return a.holidaySource.Holidays(profile.HolidayCalendars, from, to), nil
+}
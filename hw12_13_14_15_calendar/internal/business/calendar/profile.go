@@ -0,0 +1,72 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// GetProfile возвращает профиль доступности владельца ownerID в рабочем пространстве tenantID.
+// Если профиль не сохранялся, возвращается профиль по умолчанию (см. model.NewProfile).
+func (a *App) GetProfile(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) (model.Profile, error) {
+	profile, err := a.storage.FindProfile(ctx, tenantID, ownerID)
+	if err != nil {
+		if errors.Is(err, storage.ErrProfileNotFound) {
+			return model.NewProfile(tenantID, ownerID), nil
+		}
+
+		return model.Profile{}, fmt.Errorf("can't get profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateProfile заменяет профиль доступности владельца на profile.
+// Уже созданные события не проверяются.
+// Возвращает ErrHolidayCalendarNotFound, если владелец подписывается на недоступный календарь праздников.
+func (a *App) UpdateProfile(ctx context.Context, profile model.Profile) error {
+	current, err := a.GetProfile(ctx, profile.TenantID(), profile.OwnerID())
+	if err != nil {
+		return fmt.Errorf("can't update profile: %w", err)
+	}
+
+	err = a.checkHolidayCalendars(current, profile.HolidayCalendars)
+	if err != nil {
+		return fmt.Errorf("can't update profile: %w", err)
+	}
+
+	err = a.storage.SaveProfile(ctx, profile)
+	if err != nil {
+		return fmt.Errorf("can't update profile: %w", err)
+	}
+
+	return nil
+}
+
+// checkAvailability проверяет, что событие event попадает в рабочее время владельца.
+// Возвращает ошибку проверки при model.AvailabilityCheckReject
+// и её же как предупреждение при model.AvailabilityCheckWarn.
+func (a *App) checkAvailability(ctx context.Context, event model.Event) (warnings []error, err error) {
+	profile, err := a.GetProfile(ctx, event.TenantID(), event.OwnerID())
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.Check == model.AvailabilityCheckOff {
+		return nil, nil
+	}
+
+	err = profile.CheckAvailability(event.StartAt(), event.EndAt())
+	if err == nil {
+		return nil, nil
+	}
+
+	if profile.Check == model.AvailabilityCheckReject {
+		return nil, err
+	}
+
+	return []error{err}, nil
+}
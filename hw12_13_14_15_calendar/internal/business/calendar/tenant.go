@@ -0,0 +1,49 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+)
+
+// TenantStats возвращает количество событий рабочего пространства tenantID.
+func (a *App) TenantStats(ctx context.Context, tenantID model.TenantID) (int, error) {
+	count, err := a.storage.CountTenantEvents(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("can't count tenant events: %w", err)
+	}
+
+	return count, nil
+}
+
+// ExportTenant возвращает все события рабочего пространства tenantID, упорядоченные по владельцу и времени начала.
+func (a *App) ExportTenant(ctx context.Context, tenantID model.TenantID) ([]model.Event, error) {
+	events, err := a.storage.QueryTenantEvents(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("can't query tenant events: %w", err)
+	}
+
+	return events, nil
+}
+
+// WipeTenant удаляет все данные рабочего пространства tenantID вместе с файлами вложений.
+// Возвращает количество удалённых событий.
+func (a *App) WipeTenant(ctx context.Context, tenantID model.TenantID) (int, error) {
+	a.attachmentsMx.Lock()
+	defer a.attachmentsMx.Unlock()
+
+	n, err := a.storage.DeleteTenantEvents(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("can't wipe tenant: %w", err)
+	}
+
+	if a.blobs != nil {
+		if err := a.blobs.DeletePrefix(ctx, blob.TenantPrefix(tenantID)); err != nil {
+			return n, fmt.Errorf("can't delete tenant attachments: %w", err)
+		}
+	}
+
+	return n, nil
+}
@@ -2,54 +2,133 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
 )
 
+// ErrNotScheduled - планировщик не запущен.
+var ErrNotScheduled = errors.New("scheduler is not running")
+
+// livenessPeriods - через сколько пропущенных периодов задача считается зависшей.
+const livenessPeriods = 3
+
 type Notifier interface {
 	Notify(ctx context.Context, event model.Event) error
 }
 
 type EventStorage interface {
-	// PurgeOldEvents удаляет события из коллекции старше чем olderThan.
-	PurgeOldEvents(ctx context.Context, olderThan time.Time) error
+	// PurgeOldEvents удаляет события из коллекции старше чем olderThan и возвращает удалённые события.
+	PurgeOldEvents(ctx context.Context, olderThan time.Time) ([]model.Event, error)
 
 	// QueryEventsToNotify находит все события в коллекции, по которым необходимо отправить уведомление
 	// в указанный промежуток времени [from, to).
 	QueryEventsToNotify(ctx context.Context, from time.Time, to time.Time) ([]model.Event, error)
 }
 
+// Metrics - метрики планировщика.
+type Metrics interface {
+	// ObserveJob учитывает выполненную задачу job (notify, purge) длительностью d с ошибкой err.
+	ObserveJob(job string, d time.Duration, err error)
+
+	// NotificationQueued учитывает уведомление, отправленное в очередь.
+	NotificationQueued()
+
+	// NotificationFailed учитывает уведомление, которое не удалось отправить в очередь.
+	NotificationFailed()
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveJob(string, time.Duration, error) {}
+func (noopMetrics) NotificationQueued()                     {}
+func (noopMetrics) NotificationFailed()                     {}
+
 type App struct {
 	// PurgeOlderThan - сообщения старше чем PurgeOlderThan долждны быть удалены.
+	// После запуска планировщика изменяется только через SetIntervals.
 	PurgeOlderThan time.Duration
 
 	// NotifyInterval как часто уведомлять.
+	// После запуска планировщика изменяется только через SetIntervals.
 	NotifyInterval time.Duration
 
+	// Metrics - метрики планировщика, по умолчанию не учитываются.
+	Metrics Metrics
+
+	// Blobs - хранилище файлов вложений событий. Файлы вложений удаляются вместе со старыми событиями.
+	// Если не задано, файлы не удаляются.
+	Blobs blob.Store
+
 	logger   *slog.Logger
+	clock    clock.Clock
 	notifier Notifier
 	storage  EventStorage
 
 	done chan struct{}
 	mx   sync.Mutex
 	wg   sync.WaitGroup
+
+	// notifyIntervalChanged - сигнал задаче уведомления об изменении NotifyInterval
+	notifyIntervalChanged chan struct{}
+
+	// время последнего выполнения задач уведомления и очистки (unix nano)
+	notifiedAt atomic.Int64
+	purgedAt   atomic.Int64
 }
 
 // NewApp создаёт новое приложение-бизнес логику для планировщика уведомлений.
-func NewApp(logger *slog.Logger, notifier Notifier, storage EventStorage) *App {
+// Время и периодичность задач отсчитываются по часам clock.
+func NewApp(logger *slog.Logger, clock clock.Clock, notifier Notifier, storage EventStorage) *App {
 	return &App{
 		PurgeOlderThan: time.Hour * 24 * 365, // по умолчанию 365 дней
 		NotifyInterval: time.Minute,          // по умолчанию раз в минуту
+		Metrics:        noopMetrics{},
 
 		logger:   logger,
+		clock:    clock,
 		notifier: notifier,
 		storage:  storage,
+
+		notifyIntervalChanged: make(chan struct{}, 1),
+	}
+}
+
+// SetIntervals изменяет интервал уведомлений notifyInterval и возраст удаляемых событий purgeOlderThan,
+// в т.ч. у запущенного планировщика.
+func (a *App) SetIntervals(notifyInterval time.Duration, purgeOlderThan time.Duration) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	changed := a.NotifyInterval != notifyInterval
+
+	a.NotifyInterval = notifyInterval
+	a.PurgeOlderThan = purgeOlderThan
+
+	if changed {
+		select {
+		case a.notifyIntervalChanged <- struct{}{}:
+		default:
+		}
 	}
 }
 
+// intervals возвращает текущие интервал уведомлений и возраст удаляемых событий.
+func (a *App) intervals() (notifyInterval time.Duration, purgeOlderThan time.Duration) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	return a.NotifyInterval, a.PurgeOlderThan
+}
+
 // Schedule запускает планировщик.
 // Планировщик выполняет периодические задания. Остановить планировщик возможно отменой контекста ctx.
 // При повторном запуске работающего планировщика ничего не произойдёт.
@@ -64,6 +143,10 @@ func (a *App) Schedule(ctx context.Context) {
 
 	a.done = make(chan struct{})
 
+	now := a.clock.Now().UnixNano()
+	a.notifiedAt.Store(now)
+	a.purgedAt.Store(now)
+
 	// контролирует завершение задач уведомлений и очистки
 	a.wg.Add(2)
 
@@ -79,10 +162,10 @@ func (a *App) Schedule(ctx context.Context) {
 	}()
 
 	// задачи уведомления
-	go a.scheduleNotify(a.done, a.NotifyInterval)
+	go a.scheduleNotify(a.done)
 
 	// задачи очистки
-	go a.schedulePurgeEvents(a.done, a.PurgeOlderThan)
+	go a.schedulePurgeEvents(a.done)
 }
 
 // Wait ждёт завершения работы планировщика.
@@ -91,8 +174,38 @@ func (a *App) Wait() {
 	a.wg.Wait()
 }
 
+// Check проверяет, что планировщик запущен и его задачи выполняются периодически.
+// Возвращает ошибку, если задача не выполнялась дольше livenessPeriods своих периодов.
+func (a *App) Check(context.Context) error {
+	a.mx.Lock()
+	running := a.done != nil
+	notifyInterval := a.NotifyInterval
+	a.mx.Unlock()
+
+	if !running {
+		return ErrNotScheduled
+	}
+
+	if err := a.checkJob("notify", a.notifiedAt.Load(), notifyInterval); err != nil {
+		return err
+	}
+
+	return a.checkJob("purge", a.purgedAt.Load(), purgePeriod)
+}
+
+// checkJob проверяет, что задача job выполнялась (в последний раз в lastRun) не позже livenessPeriods периодов period.
+func (a *App) checkJob(job string, lastRun int64, period time.Duration) error {
+	since := a.clock.Since(time.Unix(0, lastRun))
+	if since > livenessPeriods*period {
+		return fmt.Errorf("job %s has not run for %s", job, since.Truncate(time.Second))
+	}
+
+	return nil
+}
+
 // scheduleNotify выполняет задачу рассылки уведомлений через Notifier.
-func (a *App) scheduleNotify(done chan struct{}, period time.Duration) {
+// Интервал уведомлений возможно изменить через SetIntervals.
+func (a *App) scheduleNotify(done chan struct{}) {
 	defer a.wg.Done()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -105,14 +218,25 @@ func (a *App) scheduleNotify(done chan struct{}, period time.Duration) {
 		var from time.Time
 
 		return func() {
+			ctx, span := tracing.Tracer().Start(ctx, "scheduler notify")
+
+			var (
+				events []model.Event
+				err    error
+			)
+			defer tracing.End(span, &err)
+
 			l := a.logger.WithGroup("notify")
 
 			if from.IsZero() {
-				from = time.Now()
+				from = a.clock.Now()
 			}
 
 			// ... по to
-			to := time.Now().Add(period)
+			period, _ := a.intervals()
+			to := a.clock.Now().Add(period)
+
+			startAt := a.clock.Now()
 			l.DebugContext(
 				ctx,
 				"retrieving events to notify",
@@ -120,8 +244,10 @@ func (a *App) scheduleNotify(done chan struct{}, period time.Duration) {
 				slog.String("to", to.String()),
 			)
 
-			events, err := a.storage.QueryEventsToNotify(ctx, from, to)
+			events, err = a.storage.QueryEventsToNotify(ctx, from, to)
 			if err != nil {
+				a.Metrics.ObserveJob("notify", a.clock.Since(startAt), err)
+				a.notifiedAt.Store(a.clock.Now().UnixNano())
 				l.ErrorContext(ctx, "can't query events to notify", slog.String("error", err.Error()))
 				return
 			}
@@ -138,26 +264,38 @@ func (a *App) scheduleNotify(done chan struct{}, period time.Duration) {
 			for _, ev := range events {
 				err := a.notifier.Notify(ctx, ev)
 				if err != nil {
+					a.Metrics.NotificationFailed()
 					l.ErrorContext(
 						ctx,
 						"can't send notification for the event",
 						slog.String("error", err.Error()),
 					)
+
+					continue
 				}
+
+				a.Metrics.NotificationQueued()
 			}
+
+			a.Metrics.ObserveJob("notify", a.clock.Since(startAt), nil)
+			a.notifiedAt.Store(a.clock.Now().UnixNano())
 		}
 	}()
 
 	// Уведомляем сразу при запуске и периодически
 	notify()
 
-	t := time.NewTicker(period)
+	period, _ := a.intervals()
+	t := a.clock.NewTicker(period)
 	defer t.Stop()
 
 	for {
 		select {
-		case <-t.C:
+		case <-t.Chan():
 			notify()
+		case <-a.notifyIntervalChanged:
+			period, _ = a.intervals()
+			t.Reset(period)
 		case <-done:
 			return
 		}
@@ -168,18 +306,29 @@ const purgePeriod = time.Hour
 
 // schedulePurgeEvents выполняет задачу удаления старых события.
 // События удаляются сразу после запуска и периодически раз в час.
-func (a *App) schedulePurgeEvents(done chan struct{}, olderThan time.Duration) {
+// Возраст удаляемых событий возможно изменить через SetIntervals.
+func (a *App) schedulePurgeEvents(done chan struct{}) {
 	defer a.wg.Done()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	purge := func() {
+		ctx, span := tracing.Tracer().Start(ctx, "scheduler purge")
+
+		var err error
+		defer tracing.End(span, &err)
+
 		l := a.logger.WithGroup("purge")
 
 		l.DebugContext(ctx, "purge old events")
 
-		err := a.storage.PurgeOldEvents(ctx, time.Now().Add(-olderThan))
+		_, olderThan := a.intervals()
+
+		startAt := a.clock.Now()
+		err = a.purgeEvents(ctx, a.clock.Now().Add(-olderThan))
+		a.Metrics.ObserveJob("purge", a.clock.Since(startAt), err)
+		a.purgedAt.Store(a.clock.Now().UnixNano())
 		if err != nil {
 			l.ErrorContext(ctx, "can't purge events", slog.String("error", err.Error()))
 		}
@@ -188,15 +337,33 @@ func (a *App) schedulePurgeEvents(done chan struct{}, olderThan time.Duration) {
 	// Удаляем сразу при запуске и периодически
 	purge()
 
-	t := time.NewTicker(purgePeriod)
+	t := a.clock.NewTicker(purgePeriod)
 	defer t.Stop()
 
 	for {
 		select {
-		case <-t.C:
+		case <-t.Chan():
 			purge()
 		case <-done:
 			return
 		}
 	}
 }
+
+// purgeEvents удаляет события старше чем olderThan и файлы их вложений.
+func (a *App) purgeEvents(ctx context.Context, olderThan time.Time) error {
+	events, err := a.storage.PurgeOldEvents(ctx, olderThan)
+	if err != nil {
+		return err
+	}
+
+	if a.Blobs == nil {
+		return nil
+	}
+
+	if err := blob.DeleteEvents(ctx, a.Blobs, events); err != nil {
+		return fmt.Errorf("can't delete attachments of purged events: %w", err)
+	}
+
+	return nil
+}
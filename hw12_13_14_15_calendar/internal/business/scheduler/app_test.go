@@ -0,0 +1,326 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+)
+
+// waitTimeout - сколько ждать выполнения задачи планировщика после срабатывания тикера.
+const waitTimeout = time.Second
+
+type notifyQuery struct {
+	from time.Time
+	to   time.Time
+}
+
+// stubStorage записывает запросы планировщика и возвращает события events для уведомления.
+type stubStorage struct {
+	mx      sync.Mutex
+	events  []model.Event
+	queries []notifyQuery
+	purges  []time.Time
+
+	// purged - события, которые вернёт следующее удаление старых событий
+	purged []model.Event
+
+	// block - если задан, запрос событий для уведомления ждёт его закрытия
+	block chan struct{}
+}
+
+func (s *stubStorage) PurgeOldEvents(_ context.Context, olderThan time.Time) ([]model.Event, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.purges = append(s.purges, olderThan)
+	purged := s.purged
+	s.purged = nil
+
+	return purged, nil
+}
+
+func (s *stubStorage) QueryEventsToNotify(_ context.Context, from time.Time, to time.Time) ([]model.Event, error) {
+	s.mx.Lock()
+	block := s.block
+	s.mx.Unlock()
+
+	if block != nil {
+		<-block
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.queries = append(s.queries, notifyQuery{from: from, to: to})
+	events := s.events
+	s.events = nil
+
+	return events, nil
+}
+
+func (s *stubStorage) Queries() []notifyQuery {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return append([]notifyQuery(nil), s.queries...)
+}
+
+func (s *stubStorage) Purges() []time.Time {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return append([]time.Time(nil), s.purges...)
+}
+
+// stubBlobs записывает удалённые префиксы файлов.
+type stubBlobs struct {
+	blob.Store
+
+	mx       sync.Mutex
+	prefixes []string
+}
+
+func (b *stubBlobs) DeletePrefix(_ context.Context, prefix string) error {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.prefixes = append(b.prefixes, prefix)
+
+	return nil
+}
+
+func (b *stubBlobs) Prefixes() []string {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	return append([]string(nil), b.prefixes...)
+}
+
+// stubNotifier записывает отправленные уведомления.
+type stubNotifier struct {
+	mx     sync.Mutex
+	events []model.Event
+}
+
+func (n *stubNotifier) Notify(_ context.Context, event model.Event) error {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	n.events = append(n.events, event)
+
+	return nil
+}
+
+func (n *stubNotifier) Events() []model.Event {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	return append([]model.Event(nil), n.events...)
+}
+
+var start = time.Date(2024, time.July, 10, 10, 0, 0, 0, time.UTC)
+
+// newTestApp запускает планировщик на часах clock и ждёт, когда задачи начнут ожидать срабатывания тикеров.
+func newTestApp(t *testing.T, clock *clock.Fake, storage *stubStorage, notifier *stubNotifier) *App {
+	t.Helper()
+
+	app := NewApp(slog.New(slog.NewTextHandler(io.Discard, nil)), clock, notifier, storage)
+	app.NotifyInterval = time.Minute
+	app.PurgeOlderThan = 24 * time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.Schedule(ctx)
+
+	t.Cleanup(func() {
+		cancel()
+		app.Wait()
+	})
+
+	clock.BlockUntil(2)
+
+	return app
+}
+
+func TestApp_Notify(t *testing.T) {
+	clock := clock.NewFake(start)
+	storage := &stubStorage{}
+	notifier := &stubNotifier{}
+
+	newTestApp(t, clock, storage, notifier)
+
+	require.Eventually(t, func() bool { return len(storage.Queries()) == 1 }, waitTimeout, time.Millisecond)
+	require.Equal(
+		t,
+		notifyQuery{from: start, to: start.Add(time.Minute)},
+		storage.Queries()[0],
+		"must query notifications for the first interval on start",
+	)
+
+	event, err := model.NewEvent(
+		model.DefaultTenantID,
+		model.NewID(),
+		model.NewOwnerID(),
+		"meeting",
+		start.Add(time.Hour),
+		start.Add(2*time.Hour),
+	)
+	require.NoError(t, err)
+	event.NotifyBefore = 1
+
+	storage.mx.Lock()
+	storage.events = []model.Event{event}
+	storage.mx.Unlock()
+
+	clock.Advance(time.Minute)
+
+	require.Eventually(t, func() bool { return len(notifier.Events()) == 1 }, waitTimeout, time.Millisecond)
+	require.Equal(t, event.EventID(), notifier.Events()[0].EventID(), "must notify about event")
+	require.Equal(
+		t,
+		notifyQuery{from: start.Add(time.Minute), to: start.Add(2 * time.Minute)},
+		storage.Queries()[1],
+		"must query next interval",
+	)
+
+	// пропущенные интервалы запрашиваются одним запросом
+	clock.Advance(time.Hour)
+
+	require.Eventually(t, func() bool { return len(storage.Queries()) == 3 }, waitTimeout, time.Millisecond)
+	require.Equal(
+		t,
+		notifyQuery{from: start.Add(2 * time.Minute), to: start.Add(62 * time.Minute)},
+		storage.Queries()[2],
+		"must query from the end of previous interval",
+	)
+}
+
+func TestApp_Purge(t *testing.T) {
+	clock := clock.NewFake(start)
+	storage := &stubStorage{}
+
+	app := newTestApp(t, clock, storage, &stubNotifier{})
+
+	require.Eventually(t, func() bool { return len(storage.Purges()) == 1 }, waitTimeout, time.Millisecond)
+	require.Equal(t, start.Add(-24*time.Hour), storage.Purges()[0], "must purge on start")
+
+	clock.Advance(purgePeriod - time.Minute)
+	require.Len(t, storage.Purges(), 1, "must not purge before period")
+
+	app.SetIntervals(time.Minute, time.Hour)
+	clock.Advance(time.Minute)
+
+	require.Eventually(t, func() bool { return len(storage.Purges()) == 2 }, waitTimeout, time.Millisecond)
+	require.Equal(t, start.Add(purgePeriod-time.Hour), storage.Purges()[1], "must purge with new age")
+}
+
+// newEvent создаёт часовое событие, начинающееся в startAt.
+func newEvent(t *testing.T, startAt time.Time) model.Event {
+	t.Helper()
+
+	event, err := model.NewEvent(
+		model.DefaultTenantID,
+		model.NewID(),
+		model.NewOwnerID(),
+		"old",
+		startAt,
+		startAt.Add(time.Hour),
+	)
+	require.NoError(t, err)
+
+	return event
+}
+
+func TestApp_PurgeAttachments(t *testing.T) {
+	clock := clock.NewFake(start)
+
+	withFile := newEvent(t, start.Add(-48*time.Hour))
+	file, err := model.NewFileAttachment("", "agenda.txt", "")
+	require.NoError(t, err)
+	withFile.Attachments = []model.Attachment{file}
+
+	withLink := newEvent(t, start.Add(-47*time.Hour))
+	link, err := model.NewLinkAttachment("", "https://example.com")
+	require.NoError(t, err)
+	withLink.Attachments = []model.Attachment{link}
+
+	storage := &stubStorage{purged: []model.Event{withFile, withLink, newEvent(t, start.Add(-46*time.Hour))}}
+	blobs := &stubBlobs{}
+
+	app := NewApp(slog.New(slog.NewTextHandler(io.Discard, nil)), clock, &stubNotifier{}, storage)
+	app.Blobs = blobs
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.Schedule(ctx)
+
+	t.Cleanup(func() {
+		cancel()
+		app.Wait()
+	})
+
+	require.Eventually(t, func() bool { return len(storage.Purges()) == 1 }, waitTimeout, time.Millisecond)
+	require.Equal(
+		t,
+		[]string{blob.EventPrefix(withFile.TenantID(), withFile.OwnerID(), withFile.EventID())},
+		blobs.Prefixes(),
+		"must delete files of purged events only",
+	)
+}
+
+func TestApp_SetIntervals(t *testing.T) {
+	clock := clock.NewFake(start)
+	storage := &stubStorage{}
+
+	app := newTestApp(t, clock, storage, &stubNotifier{})
+
+	require.Eventually(t, func() bool { return len(storage.Queries()) == 1 }, waitTimeout, time.Millisecond)
+
+	app.SetIntervals(10*time.Second, app.PurgeOlderThan)
+
+	// тикер перезапускается с новым интервалом асинхронно: продвигаем часы, пока задача не выполнится
+	var advanced time.Duration
+	require.Eventually(t, func() bool {
+		clock.Advance(10 * time.Second)
+		advanced += 10 * time.Second
+
+		return len(storage.Queries()) > 1
+	}, waitTimeout, time.Millisecond)
+
+	require.Less(t, advanced, time.Minute, "must notify with new interval")
+}
+
+func TestApp_Check(t *testing.T) {
+	clock := clock.NewFake(start)
+	storage := &stubStorage{}
+
+	app := NewApp(slog.New(slog.NewTextHandler(io.Discard, nil)), clock, &stubNotifier{}, storage)
+	require.ErrorIs(t, app.Check(context.Background()), ErrNotScheduled, "must not be live before schedule")
+
+	app = newTestApp(t, clock, storage, &stubNotifier{})
+	require.Eventually(t, func() bool { return len(storage.Queries()) == 1 }, waitTimeout, time.Millisecond)
+	require.NoError(t, app.Check(context.Background()), "must be live after start")
+
+	// задача уведомления зависла
+	block := make(chan struct{})
+	storage.mx.Lock()
+	storage.block = block
+	storage.mx.Unlock()
+
+	clock.Advance(time.Minute)
+	clock.Advance(livenessPeriods * time.Minute)
+	require.ErrorContains(t, app.Check(context.Background()), "notify", "must not be live while notify is stuck")
+
+	close(block)
+	clock.Advance(time.Minute)
+	require.Eventually(t, func() bool {
+		return app.Check(context.Background()) == nil
+	}, waitTimeout, time.Millisecond, "must be live after notify is done")
+}
@@ -8,14 +8,35 @@ import (
 	"sync"
 
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/notification"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
 )
 
 type NotificationMessage interface {
 	Model() (model.Notification, error)
 	Done() error
+
+	// Context возвращает контекст трассировки, с которым было отправлено уведомление.
+	Context() context.Context
+}
+
+// Metrics - метрики рассыльщика.
+type Metrics interface {
+	// NotificationSent учитывает разосланное уведомление.
+	NotificationSent()
+
+	// NotificationFailed учитывает уведомление, которое не удалось разослать.
+	NotificationFailed()
 }
 
+type noopMetrics struct{}
+
+func (noopMetrics) NotificationSent()   {}
+func (noopMetrics) NotificationFailed() {}
+
 type App struct {
+	// Metrics - метрики рассыльщика, по умолчанию не учитываются.
+	Metrics Metrics
+
 	logger   *slog.Logger
 	in       <-chan NotificationMessage
 	w        io.Writer
@@ -26,6 +47,8 @@ type App struct {
 // NewApp создаёт новое приложение-бизнес логику для рассылки уведомлений.
 func NewApp(logger *slog.Logger, in <-chan NotificationMessage, out io.Writer) *App {
 	return &App{
+		Metrics: noopMetrics{},
+
 		logger:   logger,
 		in:       in,
 		w:        out,
@@ -63,20 +86,12 @@ func (a *App) Send(ctx context.Context) bool {
 					return
 				}
 
-				notification, err := msg.Model()
-				if err != nil {
-					a.logger.ErrorContext(ctx, "can't convert message to model", slog.String("error", err.Error()))
+				if err := a.send(msg); err != nil {
+					a.Metrics.NotificationFailed()
 					continue
 				}
 
-				str := fmt.Sprintf(
-					"send notification for ownerID=%s eventID=%s: %s on %s",
-					notification.OwnerID,
-					notification.EventID,
-					notification.Title,
-					notification.Date.String(),
-				)
-				a.w.Write([]byte(str))
+				a.Metrics.NotificationSent()
 			}
 		}
 	}()
@@ -84,6 +99,35 @@ func (a *App) Send(ctx context.Context) bool {
 	return true
 }
 
+// send рассылает уведомление msg.
+func (a *App) send(msg NotificationMessage) (err error) {
+	ctx, span := tracing.Tracer().Start(msg.Context(), "sender send")
+	defer tracing.End(span, &err)
+
+	notification, err := msg.Model()
+	if err != nil {
+		a.logger.ErrorContext(ctx, "can't convert message to model", slog.String("error", err.Error()))
+		return err
+	}
+
+	str := fmt.Sprintf(
+		"send notification for tenantID=%s ownerID=%s eventID=%s: %s on %s",
+		notification.TenantID,
+		notification.OwnerID,
+		notification.EventID,
+		notification.Title,
+		notification.Date.String(),
+	)
+	if _, err := a.w.Write([]byte(str)); err != nil {
+		a.logger.ErrorContext(ctx, "can't send notification", slog.String("error", err.Error()))
+		return err
+	}
+
+	a.logger.DebugContext(ctx, "notification is sent", slog.String("eventID", string(notification.EventID)))
+
+	return nil
+}
+
 // IsReady показывает, готово ли приложение к запуску.
 func (a *App) IsReady() bool {
 	select {
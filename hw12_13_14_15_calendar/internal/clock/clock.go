@@ -0,0 +1,89 @@
+// clock - источник времени для бизнес-логики.
+// Позволяет подменить текущее время и тикеры в тестах на управляемые (см. Fake).
+package clock
+
+import (
+	"time"
+)
+
+// Clock - источник текущего времени и тикеров.
+type Clock interface {
+	// Now возвращает текущее время.
+	Now() time.Time
+
+	// Since возвращает время, прошедшее с t.
+	Since(t time.Time) time.Duration
+
+	// NewTicker создаёт тикер с периодом d, см. time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+
+	// NewTimer создаёт таймер, срабатывающий через d, см. time.NewTimer.
+	NewTimer(d time.Duration) Timer
+
+	// After возвращает канал, в который придёт время через d, см. time.After.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker - тикер, см. time.Ticker.
+type Ticker interface {
+	// Chan возвращает канал, в который приходит время срабатывания тикера.
+	Chan() <-chan time.Time
+
+	// Reset останавливает тикер и изменяет его период на d.
+	Reset(d time.Duration)
+
+	// Stop останавливает тикер.
+	Stop()
+}
+
+// Timer - таймер, см. time.Timer.
+type Timer interface {
+	// Chan возвращает канал, в который приходит время срабатывания таймера.
+	Chan() <-chan time.Time
+
+	// Reset перезапускает таймер на срабатывание через d.
+	// Возвращает true, если таймер был активен.
+	Reset(d time.Duration) bool
+
+	// Stop останавливает таймер. Возвращает true, если таймер был активен.
+	Stop() bool
+}
+
+// Real - системные часы.
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (Real) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (Real) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) Chan() <-chan time.Time {
+	return t.C
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) Chan() <-chan time.Time {
+	return t.C
+}
@@ -0,0 +1,247 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake - управляемые часы для тестов: время изменяется только через Advance.
+type Fake struct {
+	mx      sync.Mutex
+	now     time.Time
+	waiters map[waiter]struct{}
+
+	// changed закрывается и пересоздаётся при изменении набора ожидающих тикеров и таймеров
+	changed chan struct{}
+}
+
+var _ Clock = (*Fake)(nil)
+
+// waiter - тикер или таймер, ожидающий наступления времени.
+type waiter interface {
+	// fire срабатывает, если наступило время now.
+	// Возвращает false, если после срабатывания больше не ожидает.
+	fire(now time.Time) bool
+}
+
+// NewFake создаёт часы, показывающие время now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now:     now,
+		waiters: make(map[waiter]struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for clock.Fake.NewTicker")
+	}
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	t := &fakeTicker{
+		clock:  f,
+		c:      make(chan time.Time, 1),
+		period: d,
+		next:   f.now.Add(d),
+	}
+
+	f.add(t)
+
+	return t
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	t := &fakeTimer{
+		clock: f,
+		c:     make(chan time.Time, 1),
+		at:    f.now.Add(d),
+	}
+
+	if t.fire(f.now) {
+		f.add(t)
+	}
+
+	return t
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).Chan()
+}
+
+// Advance переводит часы вперёд на d и срабатывает тикеры и таймеры, время которых подошло.
+// Как и у time.Ticker, пропущенные срабатывания тикера отбрасываются, если получатель не успевает их читать.
+func (f *Fake) Advance(d time.Duration) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.now = f.now.Add(d)
+
+	for w := range f.waiters {
+		if !w.fire(f.now) {
+			f.remove(w)
+		}
+	}
+}
+
+// BlockUntil ждёт, пока не будет n ожидающих тикеров и таймеров.
+// Позволяет дождаться, когда тестируемый код начнёт ожидать срабатывания, прежде чем вызывать Advance.
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mx.Lock()
+		count, changed := len(f.waiters), f.changed
+		f.mx.Unlock()
+
+		if count >= n {
+			return
+		}
+
+		<-changed
+	}
+}
+
+// add добавляет ожидающего w. Вызывается под блокировкой.
+func (f *Fake) add(w waiter) {
+	if _, ok := f.waiters[w]; ok {
+		return
+	}
+
+	f.waiters[w] = struct{}{}
+	f.notifyChanged()
+}
+
+// remove удаляет ожидающего w. Вызывается под блокировкой.
+// Возвращает true, если w ожидал.
+func (f *Fake) remove(w waiter) bool {
+	if _, ok := f.waiters[w]; !ok {
+		return false
+	}
+
+	delete(f.waiters, w)
+	f.notifyChanged()
+
+	return true
+}
+
+// notifyChanged сообщает ожидающим BlockUntil об изменении набора тикеров и таймеров.
+func (f *Fake) notifyChanged() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+type fakeTicker struct {
+	clock  *Fake
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+func (t *fakeTicker) fire(now time.Time) bool {
+	if t.next.After(now) {
+		return true
+	}
+
+	select {
+	case t.c <- now:
+	default:
+	}
+
+	// следующее срабатывание - первое после текущего времени
+	periods := now.Sub(t.next)/t.period + 1
+	t.next = t.next.Add(periods * t.period)
+
+	return true
+}
+
+func (t *fakeTicker) Chan() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for clock.Fake ticker Reset")
+	}
+
+	f := t.clock
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	t.period = d
+	t.next = f.now.Add(d)
+
+	f.add(t)
+}
+
+func (t *fakeTicker) Stop() {
+	f := t.clock
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.remove(t)
+}
+
+type fakeTimer struct {
+	clock *Fake
+	c     chan time.Time
+	at    time.Time
+}
+
+func (t *fakeTimer) fire(now time.Time) bool {
+	if t.at.After(now) {
+		return true
+	}
+
+	select {
+	case t.c <- now:
+	default:
+	}
+
+	return false
+}
+
+func (t *fakeTimer) Chan() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	f := t.clock
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	active := f.remove(t)
+
+	t.at = f.now.Add(d)
+	if t.fire(f.now) {
+		f.add(t)
+	}
+
+	return active
+}
+
+func (t *fakeTimer) Stop() bool {
+	f := t.clock
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return f.remove(t)
+}
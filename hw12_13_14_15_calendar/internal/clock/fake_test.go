@@ -0,0 +1,132 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, time.July, 10, 10, 0, 0, 0, time.UTC)
+	clock := NewFake(start)
+
+	require.Equal(t, start, clock.Now())
+
+	clock.Advance(time.Minute)
+	require.Equal(t, start.Add(time.Minute), clock.Now(), "must advance")
+	require.Equal(t, time.Minute, clock.Since(start))
+}
+
+func TestFake_Ticker(t *testing.T) {
+	start := time.Date(2024, time.July, 10, 10, 0, 0, 0, time.UTC)
+	clock := NewFake(start)
+
+	ticker := clock.NewTicker(time.Minute)
+
+	clock.Advance(30 * time.Second)
+	requireNoTick(t, ticker)
+
+	clock.Advance(30 * time.Second)
+	require.Equal(t, start.Add(time.Minute), requireTick(t, ticker), "must tick after period")
+
+	// пропущенные срабатывания отбрасываются
+	clock.Advance(time.Hour)
+	require.Equal(t, start.Add(61*time.Minute), requireTick(t, ticker))
+	requireNoTick(t, ticker)
+
+	// следующее срабатывание - по сетке периода
+	clock.Advance(time.Minute)
+	requireTick(t, ticker)
+
+	ticker.Reset(time.Hour)
+	clock.Advance(time.Minute)
+	requireNoTick(t, ticker)
+	clock.Advance(time.Hour)
+	requireTick(t, ticker)
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	requireNoTick(t, ticker)
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	clock := NewFake(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		clock.BlockUntil(2)
+	}()
+
+	clock.NewTicker(time.Second)
+	select {
+	case <-done:
+		t.Fatal("must wait for two tickers")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.NewTicker(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("must not wait after two tickers are started")
+	}
+}
+
+func requireTick(t *testing.T, ticker interface{ Chan() <-chan time.Time }) time.Time {
+	t.Helper()
+
+	select {
+	case tm := <-ticker.Chan():
+		return tm
+	default:
+		t.Fatal("ticker must tick")
+		return time.Time{}
+	}
+}
+
+func requireNoTick(t *testing.T, ticker interface{ Chan() <-chan time.Time }) {
+	t.Helper()
+
+	select {
+	case <-ticker.Chan():
+		t.Fatal("ticker must not tick")
+	default:
+	}
+}
+
+func TestFake_Timer(t *testing.T) {
+	start := time.Date(2024, time.July, 10, 10, 0, 0, 0, time.UTC)
+	clock := NewFake(start)
+
+	timer := clock.NewTimer(time.Minute)
+
+	clock.Advance(30 * time.Second)
+	requireNoTick(t, timer)
+
+	clock.Advance(time.Hour)
+	require.Equal(t, start.Add(30*time.Second+time.Hour), requireTick(t, timer), "must fire once")
+
+	clock.Advance(time.Hour)
+	requireNoTick(t, timer)
+
+	require.False(t, timer.Stop(), "fired timer must not be active")
+	require.False(t, timer.Reset(time.Minute), "fired timer must not be active")
+
+	require.True(t, timer.Stop(), "reset timer must be active")
+	clock.Advance(time.Hour)
+	requireNoTick(t, timer)
+
+	// таймер с неположительной длительностью срабатывает сразу
+	requireTick(t, clock.NewTimer(0))
+
+	after := clock.After(time.Second)
+	clock.Advance(time.Second)
+	select {
+	case tm := <-after:
+		require.Equal(t, clock.Now(), tm)
+	default:
+		t.Fatal("after must fire")
+	}
+}
@@ -0,0 +1,13 @@
+package config
+
+// Attachments - настройки вложений событий.
+type Attachments struct {
+	// Dir - директория хранилища файлов вложений, пусто - файлы не поддерживаются (только ссылки).
+	Dir string `yaml:"dir" env:"DIR" env-default:""`
+
+	// MaxFileSize - допустимый размер файла вложения в байтах.
+	MaxFileSize int64 `yaml:"max_file_size" env:"MAX_FILE_SIZE" env-default:"1048576"`
+
+	// MaxPerEvent - допустимое количество вложений события.
+	MaxPerEvent int `yaml:"max_per_event" env:"MAX_PER_EVENT" env-default:"10"`
+}
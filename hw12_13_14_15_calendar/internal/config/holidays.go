@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+// Holidays - настройки календарей праздников.
+type Holidays struct {
+	// Dir - директория файлов календарей праздников (<name>.ics), пусто - календари праздников недоступны.
+	Dir string `yaml:"dir" env:"DIR" env-default:""`
+
+	// WatchInterval - как часто проверять изменение файлов календарей праздников.
+	WatchInterval time.Duration `yaml:"watch_interval" env:"WATCH_INTERVAL" env-default:"1m"`
+}
@@ -0,0 +1,9 @@
+package config
+
+type TenantQuotas struct {
+	// MaxEvents - допустимое количество событий в рабочем пространстве, 0 - без ограничений.
+	MaxEvents int `yaml:"max_events" env:"MAX_EVENTS" env-default:"0"`
+
+	// Tenants - допустимое количество событий для отдельных рабочих пространств, 0 - без ограничений.
+	Tenants map[string]int `yaml:"tenants"`
+}
@@ -0,0 +1,21 @@
+package config
+
+type RateLimitRule struct {
+	// Rate - допустимое количество запросов в секунду, 0 - без ограничений.
+	Rate float64 `yaml:"rate"`
+
+	// Burst - сколько запросов допустимо выполнить разом сверх Rate.
+	Burst int `yaml:"burst"`
+}
+
+type RateLimit struct {
+	// Enabled - включено ли ограничение частоты запросов.
+	Enabled bool `yaml:"enabled" env:"ENABLED" env-default:"false"`
+
+	// Rate и Burst - ограничение по умолчанию для методов, не указанных в Methods.
+	Rate  float64 `yaml:"rate"  env:"RATE"  env-default:"50"`
+	Burst int     `yaml:"burst" env:"BURST" env-default:"100"`
+
+	// Methods - ограничения для отдельных методов (например, GetMonthEvents).
+	Methods map[string]RateLimitRule `yaml:"methods"`
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+)
+
+// Live - текущая конфигурация сервиса, которая может быть изменена при перечитывании (см. WatchReload).
+type Live[T any] struct {
+	p atomic.Pointer[T]
+}
+
+// NewLive создаёт Live с конфигурацией cfg.
+func NewLive[T any](cfg T) *Live[T] {
+	l := &Live[T]{}
+	l.Store(cfg)
+
+	return l
+}
+
+// Load возвращает текущую конфигурацию.
+func (l *Live[T]) Load() T {
+	return *l.p.Load()
+}
+
+// Store заменяет текущую конфигурацию на cfg.
+func (l *Live[T]) Store(cfg T) {
+	l.p.Store(&cfg)
+}
+
+// Diff возвращает отсортированные пути (по yaml-тегам, через точку) полей, значения которых отличаются в a и b.
+// Вложенные структуры сравниваются по полям, остальные значения (в т.ч. map и slice) - целиком.
+func Diff[T any](a T, b T) []string {
+	var changes []string
+	diff(reflect.ValueOf(a), reflect.ValueOf(b), "", &changes)
+	slices.Sort(changes)
+
+	return changes
+}
+
+func diff(a reflect.Value, b reflect.Value, path string, changes *[]string) {
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changes = append(*changes, path)
+		}
+
+		return
+	}
+
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		if path != "" {
+			name = path + "." + name
+		}
+
+		diff(a.Field(i), b.Field(i), name, changes)
+	}
+}
+
+// HasChange сообщает, есть ли среди изменённых полей changes поле field или вложенные в него поля.
+func HasChange(changes []string, field string) bool {
+	return slices.ContainsFunc(changes, func(change string) bool {
+		return isField(change, field)
+	})
+}
+
+// Split делит изменённые поля changes на применяемые без перезапуска (поля из live и вложенные в них)
+// и требующие перезапуска сервиса.
+func Split(changes []string, live []string) (applied []string, restart []string) {
+	for _, change := range changes {
+		isLive := slices.ContainsFunc(live, func(field string) bool {
+			return isField(change, field)
+		})
+
+		if isLive {
+			applied = append(applied, change)
+		} else {
+			restart = append(restart, change)
+		}
+	}
+
+	return applied, restart
+}
+
+// isField сообщает, является ли путь path полем field или вложенным в него полем.
+func isField(path string, field string) bool {
+	return path == field || strings.HasPrefix(path, field+".")
+}
+
+// WatchReload перечитывает конфигурацию из файла path при получении сигнала SIGHUP и передаёт её в reload.
+// Если конфигурацию не удалось прочитать, ошибка логируется и текущая конфигурация остаётся без изменений.
+// Завершается при отмене контекста ctx.
+func WatchReload[T any](ctx context.Context, logger *slog.Logger, path string, reload func(cfg T)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.Info("reload config", slog.String("file", path))
+
+			cfg, err := ReadConfig[T](path)
+			if err != nil {
+				logger.Error("can't reload config", slog.String("error", err.Error()))
+				continue
+			}
+
+			reload(cfg)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Timeout time.Duration `yaml:"timeout" env-default:"1s"`
+	Log     struct {
+		Level slog.Level `yaml:"level" env-default:"info"`
+	} `yaml:"logger"`
+	Limits map[string]int `yaml:"limits"`
+	Hosts  []string       `yaml:"hosts"`
+}
+
+func TestDiff(t *testing.T) {
+	a := testConfig{Timeout: time.Second, Limits: map[string]int{"a": 1}, Hosts: []string{"a"}}
+	require.Empty(t, Diff(a, a), "must not have changes")
+
+	b := a
+	b.Timeout = time.Minute
+	b.Log.Level = slog.LevelDebug
+	b.Limits = map[string]int{"a": 2}
+	b.Hosts = []string{"a", "b"}
+
+	require.Equal(t, []string{"hosts", "limits", "logger.level", "timeout"}, Diff(a, b), "must find changes")
+}
+
+func TestSplit(t *testing.T) {
+	changes := []string{"grpc.port", "logger.level", "rate_limit.methods", "timeout"}
+
+	applied, restart := Split(changes, []string{"logger", "rate_limit", "time"})
+	require.Equal(t, []string{"logger.level", "rate_limit.methods"}, applied, "must apply live fields")
+	require.Equal(t, []string{"grpc.port", "timeout"}, restart, "must require restart for other fields")
+
+	require.True(t, HasChange(changes, "rate_limit"), "must have nested change")
+	require.True(t, HasChange(changes, "timeout"), "must have change")
+	require.False(t, HasChange(changes, "grpc.host"), "must not have change")
+}
+
+func TestWatchReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("timeout: 5s\n"), 0o600), "must write config")
+
+	// SIGHUP не должен завершить процесс теста, пока WatchReload не подписан на сигнал
+	ignored := make(chan os.Signal, 1)
+	signal.Notify(ignored, syscall.SIGHUP)
+	defer signal.Stop(ignored)
+
+	reloaded := make(chan testConfig, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		WatchReload(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), path, func(cfg testConfig) {
+			reloaded <- cfg
+		})
+	}()
+
+	// ждём, пока WatchReload подпишется на сигнал
+	require.Eventually(t, func() bool {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP), "must send signal")
+
+		select {
+		case cfg := <-reloaded:
+			require.Equal(t, 5*time.Second, cfg.Timeout, "must read new config")
+			return true
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, time.Second, 20*time.Millisecond, "must reload config")
+
+	cancel()
+	<-done
+}
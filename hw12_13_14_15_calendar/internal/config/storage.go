@@ -1,12 +1,17 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type EventStorageType string
 
 var (
 	EventStorageTypeMemory EventStorageType = "memory"
 	EventStorageTypePg     EventStorageType = "pg"
+	EventStorageTypeFile   EventStorageType = "file"
+	EventStorageTypeSqlite EventStorageType = "sqlite"
 )
 
 func (t *EventStorageType) UnmarshalText(s []byte) error {
@@ -15,6 +20,10 @@ func (t *EventStorageType) UnmarshalText(s []byte) error {
 		*t = EventStorageTypeMemory
 	case string(EventStorageTypePg):
 		*t = EventStorageTypePg
+	case string(EventStorageTypeFile):
+		*t = EventStorageTypeFile
+	case string(EventStorageTypeSqlite):
+		*t = EventStorageTypeSqlite
 	default:
 		return fmt.Errorf("invalid event storage type '%s'", s)
 	}
@@ -28,4 +37,62 @@ func (t *EventStorageType) String() string {
 
 type EventStoragePg struct {
 	DataSource string `yaml:"data_source" env:"DATASOURCE"`
+
+	// Replicas - строки подключения к репликам для чтения.
+	Replicas []string `yaml:"replicas" env:"REPLICAS" env-separator:";"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"     env:"MAX_OPEN_CONNS"     env-default:"0"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"     env:"MAX_IDLE_CONNS"     env-default:"2"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"  env:"CONN_MAX_LIFETIME"  env-default:"0"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"CONN_MAX_IDLE_TIME" env-default:"0"`
+
+	// StatementTimeout - ограничение времени выполнения запроса, 0 - без ограничения.
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"STATEMENT_TIMEOUT" env-default:"0"`
+}
+
+type EventStorageSqlite struct {
+	// DataSource - путь к файлу базы, возможно с параметрами подключения.
+	DataSource string `yaml:"data_source" env:"DATASOURCE" env-default:"data/calendar.db"`
+}
+
+type FileSyncPolicy string
+
+var (
+	FileSyncAlways   FileSyncPolicy = "always"
+	FileSyncInterval FileSyncPolicy = "interval"
+	FileSyncNever    FileSyncPolicy = "never"
+)
+
+func (p *FileSyncPolicy) UnmarshalText(s []byte) error {
+	switch string(s) {
+	case string(FileSyncAlways):
+		*p = FileSyncAlways
+	case string(FileSyncInterval):
+		*p = FileSyncInterval
+	case string(FileSyncNever):
+		*p = FileSyncNever
+	default:
+		return fmt.Errorf("invalid file sync policy '%s'", s)
+	}
+
+	return nil
+}
+
+func (p *FileSyncPolicy) String() string {
+	return string(*p)
+}
+
+type EventStorageFile struct {
+	// Dir - директория с файлами хранилища.
+	Dir string `yaml:"dir" env:"DIR" env-default:"data/events"`
+
+	// Sync - политика сброса журнала на диск: always, interval, never.
+	Sync         FileSyncPolicy `yaml:"sync"          env:"SYNC"          env-default:"always"`
+	SyncInterval time.Duration  `yaml:"sync_interval" env:"SYNC_INTERVAL" env-default:"1s"`
+
+	// SnapshotInterval - как часто сохранять снимок хранилища, 0 - не сохранять периодически.
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" env:"SNAPSHOT_INTERVAL" env-default:"10m"`
+
+	// SnapshotThreshold - после скольких записей в журнале сохранять снимок, 0 - без ограничения.
+	SnapshotThreshold int `yaml:"snapshot_threshold" env:"SNAPSHOT_THRESHOLD" env-default:"10000"`
 }
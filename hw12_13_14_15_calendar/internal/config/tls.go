@@ -0,0 +1,36 @@
+package config
+
+// TLSServer - настройки TLS сервера.
+type TLSServer struct {
+	// Enabled - включён ли TLS.
+	Enabled bool `yaml:"enabled" env:"ENABLED" env-default:"false"`
+
+	// CertFile и KeyFile - сертификат и ключ сервера (PEM).
+	CertFile string `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile  string `yaml:"key_file"  env:"KEY_FILE"`
+
+	// ClientCAFile - сертификаты CA (PEM) для проверки клиентских сертификатов (mTLS).
+	// Если не задан, клиентские сертификаты не запрашиваются.
+	ClientCAFile string `yaml:"client_ca_file" env:"CLIENT_CA_FILE"`
+
+	// RequireClientCert - требовать клиентский сертификат, иначе он проверяется только если передан.
+	RequireClientCert bool `yaml:"require_client_cert" env:"REQUIRE_CLIENT_CERT" env-default:"false"`
+}
+
+// TLSClient - настройки TLS клиента.
+type TLSClient struct {
+	// CAFile - сертификаты CA (PEM) для проверки сертификата сервера, по умолчанию - системные.
+	CAFile string `yaml:"ca_file" env:"CA_FILE"`
+
+	// CertFile и KeyFile - клиентский сертификат и ключ (PEM) для mTLS.
+	CertFile string `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile  string `yaml:"key_file"  env:"KEY_FILE"`
+
+	// ServerName - имя сервера для проверки сертификата, по умолчанию - из адреса подключения.
+	ServerName string `yaml:"server_name" env:"SERVER_NAME"`
+}
+
+// Configured сообщает, заданы ли собственные настройки TLS клиента.
+func (c TLSClient) Configured() bool {
+	return c.CAFile != "" || c.CertFile != "" || c.ServerName != ""
+}
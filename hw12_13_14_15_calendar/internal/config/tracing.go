@@ -0,0 +1,37 @@
+package config
+
+import "fmt"
+
+type TracingExporter string
+
+var (
+	TracingExporterNone   TracingExporter = "none"
+	TracingExporterStdout TracingExporter = "stdout"
+	TracingExporterFile   TracingExporter = "file"
+)
+
+func (e *TracingExporter) UnmarshalText(s []byte) error {
+	switch string(s) {
+	case string(TracingExporterNone):
+		*e = TracingExporterNone
+	case string(TracingExporterStdout):
+		*e = TracingExporterStdout
+	case string(TracingExporterFile):
+		*e = TracingExporterFile
+	default:
+		return fmt.Errorf("invalid tracing exporter '%s'", s)
+	}
+
+	return nil
+}
+
+type Tracing struct {
+	// Exporter - куда выгружать трейсы: none - не выгружать, stdout, file - в файл File (JSON по строке на span).
+	Exporter TracingExporter `yaml:"exporter" env:"EXPORTER" env-default:"none"`
+
+	// File - файл для выгрузки трейсов (для Exporter=file).
+	File string `yaml:"file" env:"FILE" env-default:"traces.json"`
+
+	// SampleRatio - доля трейсов (0..1), которые будут записаны, если нет родительского span.
+	SampleRatio float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" env-default:"1"`
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidValue - недопустимое значение в конфигурации.
+var ErrInvalidValue = errors.New("invalid config value")
+
+// number - типы числовых значений конфигурации, в т.ч. time.Duration.
+type number interface {
+	~int | ~int64 | ~float64
+}
+
+// Positive возвращает ошибку, если значение value поля field не больше нуля.
+func Positive[T number](field string, value T) error {
+	if value <= 0 {
+		return fmt.Errorf("%w: %s must be positive, got %v", ErrInvalidValue, field, value)
+	}
+
+	return nil
+}
+
+// NonNegative возвращает ошибку, если значение value поля field меньше нуля.
+func NonNegative[T number](field string, value T) error {
+	if value < 0 {
+		return fmt.Errorf("%w: %s must not be negative, got %v", ErrInvalidValue, field, value)
+	}
+
+	return nil
+}
+
+// Validate проверяет ограничения частоты запросов: значения не могут быть отрицательными.
+func (r RateLimit) Validate() error {
+	errs := []error{
+		NonNegative("rate_limit.rate", r.Rate),
+		NonNegative("rate_limit.burst", r.Burst),
+	}
+
+	for method, rule := range r.Methods {
+		errs = append(
+			errs,
+			NonNegative("rate_limit.methods."+method+".rate", rule.Rate),
+			NonNegative("rate_limit.methods."+method+".burst", rule.Burst),
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate проверяет квоты рабочих пространств: значения не могут быть отрицательными.
+func (q TenantQuotas) Validate() error {
+	errs := []error{NonNegative("quotas.max_events", q.MaxEvents)}
+
+	for tenant, maxEvents := range q.Tenants {
+		errs = append(errs, NonNegative("quotas.tenants."+tenant, maxEvents))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate проверяет настройки календарей праздников: интервал проверки файлов должен быть больше нуля.
+func (h Holidays) Validate() error {
+	if h.Dir == "" {
+		return nil
+	}
+
+	return Positive("holidays.watch_interval", h.WatchInterval)
+}
+
+// Validate проверяет настройки вложений: ограничения не могут быть отрицательными.
+func (a Attachments) Validate() error {
+	return errors.Join(
+		NonNegative("attachments.max_file_size", a.MaxFileSize),
+		NonNegative("attachments.max_per_event", a.MaxPerEvent),
+	)
+}
+
+// Validate проверяет настройки подключения к postgres: ограничения времени не могут быть отрицательными.
+func (p EventStoragePg) Validate() error {
+	return errors.Join(
+		NonNegative("event_storage_pg.statement_timeout", p.StatementTimeout),
+		NonNegative("event_storage_pg.conn_max_lifetime", p.ConnMaxLifetime),
+		NonNegative("event_storage_pg.conn_max_idle_time", p.ConnMaxIdleTime),
+	)
+}
+
+// Validate проверяет настройки файлового хранилища: интервал сброса журнала для политики interval
+// должен быть больше нуля, остальные значения не могут быть отрицательными.
+func (f EventStorageFile) Validate() error {
+	errs := []error{
+		NonNegative("event_storage_file.snapshot_interval", f.SnapshotInterval),
+		NonNegative("event_storage_file.snapshot_threshold", f.SnapshotThreshold),
+	}
+
+	if f.Sync == FileSyncInterval {
+		errs = append(errs, Positive("event_storage_file.sync_interval", f.SyncInterval))
+	}
+
+	return errors.Join(errs...)
+}
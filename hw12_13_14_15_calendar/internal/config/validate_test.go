@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "positive", err: Positive("interval", time.Second)},
+		{name: "zero positive", err: Positive("interval", time.Duration(0)), wantErr: true},
+		{name: "zero non-negative", err: NonNegative("burst", 0)},
+		{name: "negative non-negative", err: NonNegative("rate", -0.5), wantErr: true},
+		{name: "rate limit", err: RateLimit{Rate: 1, Methods: map[string]RateLimitRule{"Get": {Rate: 1}}}.Validate()},
+		{
+			name:    "rate limit method",
+			err:     RateLimit{Rate: 1, Methods: map[string]RateLimitRule{"Get": {Burst: -1}}}.Validate(),
+			wantErr: true,
+		},
+		{name: "quotas", err: TenantQuotas{Tenants: map[string]int{"a": -1}}.Validate(), wantErr: true},
+		{name: "attachments", err: Attachments{MaxPerEvent: -1}.Validate(), wantErr: true},
+		{name: "holidays disabled", err: Holidays{}.Validate()},
+		{name: "holidays", err: Holidays{Dir: "holidays"}.Validate(), wantErr: true},
+		{name: "pg timeout", err: EventStoragePg{StatementTimeout: -time.Second}.Validate(), wantErr: true},
+		{name: "file sync never", err: EventStorageFile{Sync: FileSyncNever}.Validate()},
+		{name: "file sync interval", err: EventStorageFile{Sync: FileSyncInterval}.Validate(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				require.ErrorIs(t, tt.err, ErrInvalidValue, "must be invalid")
+			} else {
+				require.NoError(t, tt.err, "must be valid")
+			}
+		})
+	}
+}
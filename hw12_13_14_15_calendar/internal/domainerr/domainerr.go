@@ -0,0 +1,90 @@
+// domainerr - типизированные ошибки предметной области.
+//
+// Ошибка несёт вид (Kind), машиночитаемую причину (Reason) и, опционально, поле запроса (Field),
+// к которому она относится. По этим данным транспортный слой формирует код и детали ответа,
+// не разбирая текст ошибки.
+package domainerr
+
+import "errors"
+
+// Kind - вид ошибки.
+type Kind int
+
+const (
+	// KindInvalidArgument - неверные входные данные.
+	KindInvalidArgument Kind = iota + 1
+
+	// KindNotFound - объект не найден.
+	KindNotFound
+
+	// KindAlreadyExists - объект уже существует.
+	KindAlreadyExists
+
+	// KindFailedPrecondition - операция невозможна в текущем состоянии (например, время занято).
+	KindFailedPrecondition
+
+	// KindResourceExhausted - исчерпан лимит (например, квота событий рабочего пространства).
+	KindResourceExhausted
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindInvalidArgument:
+		return "invalid argument"
+	case KindNotFound:
+		return "not found"
+	case KindAlreadyExists:
+		return "already exists"
+	case KindFailedPrecondition:
+		return "failed precondition"
+	case KindResourceExhausted:
+		return "resource exhausted"
+	default:
+		return "unknown"
+	}
+}
+
+// Error - ошибка предметной области.
+// Используется как sentinel-ошибка: сравнивается через errors.Is, извлекается через errors.As (или From).
+type Error struct {
+	Kind Kind
+
+	// Reason - машиночитаемая причина в формате UPPER_SNAKE_CASE, например EVENT_NOT_FOUND.
+	Reason string
+
+	// Field - поле запроса, к которому относится ошибка, если есть.
+	Field string
+
+	msg string
+}
+
+// New создаёт ошибку вида kind с причиной reason и сообщением msg.
+func New(kind Kind, reason string, msg string) *Error {
+	return &Error{
+		Kind:   kind,
+		Reason: reason,
+		msg:    msg,
+	}
+}
+
+// NewField создаёт ошибку неверного значения поля field.
+func NewField(field string, reason string, msg string) *Error {
+	err := New(KindInvalidArgument, reason, msg)
+	err.Field = field
+
+	return err
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// From извлекает из цепочки err ошибку предметной области.
+func From(err error) (*Error, bool) {
+	var derr *Error
+	if errors.As(err, &derr) {
+		return derr, true
+	}
+
+	return nil, false
+}
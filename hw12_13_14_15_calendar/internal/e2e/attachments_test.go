@@ -0,0 +1,196 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+)
+
+// attachment - вложение события в HTTP API.
+type attachment struct {
+	AttachmentID string `json:"attachmentId"`
+	Title        string `json:"title"`
+	URL          string `json:"url,omitempty"`
+	FileName     string `json:"fileName,omitempty"`
+	ContentType  string `json:"contentType,omitempty"`
+	Size         string `json:"size,omitempty"` // int64 в JSON - строка
+}
+
+// uploadFile - запрос загрузки файла в HTTP API.
+type uploadFile struct {
+	Title       string `json:"title,omitempty"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType,omitempty"`
+	Content     []byte `json:"content"`
+}
+
+type attachmentResponse struct {
+	Attachment attachment `json:"attachment"`
+}
+
+// attachmentPath возвращает путь HTTP-скачивания вложения attachmentID события eventID.
+func attachmentPath(eventID string, attachmentID string) string {
+	return fmt.Sprintf("/v1/events/%s/attachments/%s", eventID, attachmentID)
+}
+
+func Test_Attachments(t *testing.T) {
+	start := time.Date(2030, time.January, 10, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 24 * time.Hour,
+		Attachments:    calendarBusiness.AttachmentLimits{MaxFileSize: 64, MaxPerEvent: 3},
+	})
+
+	ownerID := uuid.NewString()
+
+	ev := event{
+		EventID: uuid.NewString(),
+		Title:   "review",
+		StartAt: start.Add(time.Hour),
+		EndAt:   start.Add(2 * time.Hour),
+	}
+	resp := h.do(http.MethodPost, "/api/v1/events", ownerID, ev, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+
+	eventPath := "/api/v1/events/" + ev.EventID
+
+	// ссылка
+	var link attachmentResponse
+	resp = h.do(
+		http.MethodPost,
+		eventPath+"/links",
+		ownerID,
+		map[string]string{"title": "Agenda", "url": "https://docs.example.com/agenda"},
+		&link,
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must add link")
+	require.Equal(t, "Agenda", link.Attachment.Title)
+
+	// файл
+	content := []byte("# Agenda\n1. Status\n2. Plans\n")
+	var file attachmentResponse
+	resp = h.do(
+		http.MethodPost,
+		eventPath+"/files",
+		ownerID,
+		uploadFile{FileName: "agenda.md", ContentType: "text/markdown", Content: content},
+		&file,
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must upload file")
+	require.Equal(t, "agenda.md", file.Attachment.Title, "must use file name as title")
+	require.Equal(t, fmt.Sprint(len(content)), file.Attachment.Size, "must return file size")
+
+	resp = h.do(
+		http.MethodPost,
+		eventPath+"/files",
+		ownerID,
+		uploadFile{FileName: "large.bin", Content: make([]byte, 65)},
+		nil,
+	)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject too large file")
+
+	resp = h.do(http.MethodPost, eventPath+"/files", ownerID, uploadFile{FileName: "../x", Content: content}, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject invalid file name")
+
+	// вложения возвращаются вместе с событием
+	var day eventsResponse
+	resp = h.do(http.MethodGet, dayPath(start), ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events")
+	require.Len(t, day.Events, 1)
+
+	var found struct {
+		Events []struct {
+			Attachments []attachment `json:"attachments"`
+		} `json:"events"`
+	}
+	resp = h.do(http.MethodGet, dayPath(start), ownerID, nil, &found)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events")
+	require.Equal(t, []attachment{link.Attachment, file.Attachment}, found.Events[0].Attachments)
+
+	// скачивание по HTTP
+	resp, data := h.get(attachmentPath(ev.EventID, file.Attachment.AttachmentID), ownerID)
+	t.Log(string(data), file.Attachment)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must download file")
+	require.Equal(t, content, data, "must download file content")
+	require.Equal(t, "text/markdown", resp.Header.Get("Content-Type"))
+	require.Equal(t, `attachment; filename=agenda.md`, resp.Header.Get("Content-Disposition"))
+
+	resp, _ = h.get(attachmentPath(ev.EventID, file.Attachment.AttachmentID), uuid.NewString())
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not download file of other owner")
+
+	resp, _ = h.get(attachmentPath(ev.EventID, file.Attachment.AttachmentID), "")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "must require owner")
+
+	resp, _ = h.get(attachmentPath(ev.EventID, link.Attachment.AttachmentID), ownerID)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must not download link")
+
+	// скачивание потоком GRPC
+	client := pbEventV1.NewEventServiceClient(h.Conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-owner-id", ownerID)
+	stream, err := client.DownloadAttachment(ctx, &pbEventV1.DownloadAttachmentRequest{
+		EventID:      ev.EventID,
+		AttachmentID: file.Attachment.AttachmentID,
+	})
+	require.NoError(t, err, "must start download")
+
+	var streamed []byte
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err, "must receive download")
+
+		if a := msg.GetAttachment(); a != nil {
+			require.Equal(t, "agenda.md", a.FileName, "must send attachment first")
+		}
+		streamed = append(streamed, msg.GetChunk()...)
+	}
+	require.Equal(t, content, streamed, "must stream file content")
+
+	// удаление вложения
+	resp = h.do(http.MethodDelete, eventPath+"/attachments/"+link.Attachment.AttachmentID, ownerID, nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must delete attachment")
+
+	resp = h.do(http.MethodDelete, eventPath+"/attachments/"+link.Attachment.AttachmentID, ownerID, nil, nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not delete attachment twice")
+
+	// событие закончилось больше суток назад - при очистке удаляются и файлы вложений
+	key := blob.AttachmentKey(
+		model.DefaultTenantID,
+		model.OwnerID(ownerID),
+		model.ID(ev.EventID),
+		model.AttachmentID(file.Attachment.AttachmentID),
+	)
+	_, err = h.Blobs.Open(context.Background(), key)
+	require.NoError(t, err, "file must be stored")
+
+	h.Advance(48 * time.Hour)
+	require.Eventually(
+		t,
+		func() bool {
+			_, err := h.Blobs.Open(context.Background(), key)
+			return errors.Is(err, blob.ErrBlobNotFound)
+		},
+		waitTimeout,
+		10*time.Millisecond,
+		"must delete files of purged event",
+	)
+
+	resp, _ = h.get(attachmentPath(ev.EventID, file.Attachment.AttachmentID), ownerID)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not download file of purged event")
+}
@@ -0,0 +1,362 @@
+package e2e
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+)
+
+// davMultistatus - ответ 207 Multi-Status на запросы PROPFIND и REPORT.
+type davMultistatus struct {
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href      string `xml:"DAV: href"`
+	Status    string `xml:"DAV: status"`
+	PropStats []struct {
+		Prop struct {
+			Inner        string `xml:",innerxml"`
+			ETag         string `xml:"DAV: getetag"`
+			CTag         string `xml:"http://calendarserver.org/ns/ getctag"`
+			CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+		} `xml:"DAV: prop"`
+		Status string `xml:"DAV: status"`
+	} `xml:"DAV: propstat"`
+}
+
+// found возвращает содержимое найденных свойств ресурса (propstat с кодом 200).
+func (r davResponse) found(t *testing.T) (inner string, etag string, ctag string, data string) {
+	t.Helper()
+
+	for _, ps := range r.PropStats {
+		if strings.Contains(ps.Status, " 200 ") {
+			return ps.Prop.Inner, ps.Prop.ETag, ps.Prop.CTag, ps.Prop.CalendarData
+		}
+	}
+
+	require.Failf(t, "no found properties", "response %s", r.Href)
+
+	return "", "", "", ""
+}
+
+// missing возвращает содержимое ненайденных свойств ресурса (propstat с кодом 404).
+func (r davResponse) missing() string {
+	for _, ps := range r.PropStats {
+		if strings.Contains(ps.Status, " 404 ") {
+			return ps.Prop.Inner
+		}
+	}
+
+	return ""
+}
+
+// davFixture возвращает записанный запрос клиента CalDAV из testdata/caldav/name
+// с подстановками replacements: пары "{{KEY}}", значение.
+func davFixture(t *testing.T, name string, replacements ...string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "caldav", name))
+	require.NoError(t, err, "must read fixture")
+
+	return strings.NewReplacer(replacements...).Replace(string(data))
+}
+
+// dav выполняет запрос CalDAV method к пути path от имени владельца ownerID (если задан)
+// с заголовками header и телом body. Перенаправления не выполняются.
+func dav(
+	t *testing.T,
+	h *harness,
+	method string,
+	path string,
+	ownerID string,
+	header http.Header,
+	body string,
+) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), method, h.URL+path, strings.NewReader(body))
+	require.NoError(t, err, "must create request")
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if ownerID != "" {
+		req.Header.Set("X-Owner-ID", ownerID)
+	}
+
+	client := http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err, "must do request")
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "must read response")
+
+	return resp, data
+}
+
+// davMulti выполняет запрос, как dav, и разбирает ответ 207 Multi-Status.
+func davMulti(
+	t *testing.T,
+	h *harness,
+	method string,
+	path string,
+	ownerID string,
+	header http.Header,
+	body string,
+) davMultistatus {
+	t.Helper()
+
+	resp, data := dav(t, h, method, path, ownerID, header, body)
+	require.Equal(t, http.StatusMultiStatus, resp.StatusCode, "must be multistatus: %s", data)
+	require.Contains(t, resp.Header.Get("Content-Type"), "application/xml")
+
+	var ms davMultistatus
+	require.NoError(t, xml.Unmarshal(data, &ms), "must parse multistatus")
+
+	return ms
+}
+
+func Test_CalDAV(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	h := newHarness(t, harnessOptions{
+		Start:          now,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 24 * time.Hour,
+	})
+
+	ownerID := uuid.NewString()
+	depth1 := http.Header{"Depth": {"1"}}
+
+	resp := h.do(http.MethodPost, "/api/v1/labels", ownerID, label{Name: "work"}, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create label")
+
+	// событие из API: цвет и адрес есть только в API, в iCalendar их нет
+	apiEvent := event{
+		EventID:  uuid.NewString(),
+		Title:    "review",
+		StartAt:  time.Date(2030, time.October, 20, 10, 0, 0, 0, time.UTC),
+		EndAt:    time.Date(2030, time.October, 20, 11, 0, 0, 0, time.UTC),
+		Color:    "#00ff00",
+		Location: &location{Name: "офис", Address: "Москва, Тверская 1"},
+	}
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, apiEvent, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+
+	apiHref := "/v1/caldav/events/" + apiEvent.EventID + ".ics"
+
+	uid := strings.ToUpper(uuid.NewString())
+	davHref := "/v1/caldav/events/" + strings.ToLower(uid) + ".ics"
+
+	var ctag string
+
+	t.Run("discovery", func(t *testing.T) {
+		resp, _ := dav(t, h, http.MethodOptions, "/v1/caldav/", "", nil, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("DAV"), "calendar-access", "must support CalDAV")
+		require.Contains(t, resp.Header.Get("Allow"), "REPORT")
+
+		resp, _ = dav(t, h, http.MethodGet, "/.well-known/caldav", "", nil, "")
+		require.Equal(t, http.StatusMovedPermanently, resp.StatusCode, "must redirect")
+		require.Equal(t, "/v1/caldav/", resp.Header.Get("Location"))
+
+		resp, _ = dav(t, h, "PROPFIND", "/v1/caldav/", "", nil, davFixture(t, "propfind_principal.xml"))
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "must require owner")
+
+		ms := davMulti(t, h, "PROPFIND", "/v1/caldav/", ownerID, http.Header{"Depth": {"0"}},
+			davFixture(t, "propfind_principal.xml"))
+		require.Len(t, ms.Responses, 1)
+		require.Equal(t, "/v1/caldav/", ms.Responses[0].Href)
+
+		inner, _, _, _ := ms.Responses[0].found(t)
+		require.Contains(t, inner, "current-user-principal")
+		require.Contains(t, inner, "calendar-home-set")
+		require.Contains(t, inner, "<href xmlns=\"DAV:\">/v1/caldav/</href>")
+		require.Contains(t, ms.Responses[0].missing(), "displayname", "must report unknown property")
+
+		ms = davMulti(t, h, "PROPFIND", "/v1/caldav/", ownerID, depth1, davFixture(t, "propfind_principal.xml"))
+		require.Len(t, ms.Responses, 2, "must list calendar")
+		require.Equal(t, "/v1/caldav/events/", ms.Responses[1].Href)
+	})
+
+	t.Run("propfind calendar", func(t *testing.T) {
+		ms := davMulti(t, h, "PROPFIND", "/v1/caldav/events/", ownerID, depth1, davFixture(t, "propfind_calendar.xml"))
+		require.Len(t, ms.Responses, 2, "must list calendar and its event")
+
+		calendar := ms.Responses[0]
+		require.Equal(t, "/v1/caldav/events/", calendar.Href)
+
+		inner, etag, cal, _ := calendar.found(t)
+		require.Contains(t, inner, "urn:ietf:params:xml:ns:caldav", "must be calendar")
+		require.Contains(t, inner, `name="VEVENT"`, "must support events")
+		require.NotEmpty(t, cal, "must have ctag")
+		require.Equal(t, cal, etag)
+		require.Contains(t, calendar.missing(), "calendar-color", "must report unknown property")
+
+		ctag = cal
+
+		require.Equal(t, apiHref, ms.Responses[1].Href)
+		_, etag, _, data := ms.Responses[1].found(t)
+		require.NotEmpty(t, etag, "must have event etag")
+		require.Empty(t, data, "PROPFIND must not return calendar data")
+
+		ms = davMulti(t, h, "PROPFIND", "/v1/caldav/events/", uuid.NewString(), depth1, "")
+		require.Len(t, ms.Responses, 1, "must not list events of other owner")
+	})
+
+	t.Run("put", func(t *testing.T) {
+		body := davFixture(t, "put_event.ics", "{{UID}}", uid)
+		header := http.Header{"Content-Type": {"text/calendar; charset=utf-8"}, "If-None-Match": {"*"}}
+
+		resp, data := dav(t, h, http.MethodPut, "/v1/caldav/events/"+uid+".ics", ownerID, header, body)
+		require.Equal(t, http.StatusCreated, resp.StatusCode, "must create event: %s", data)
+
+		resp, _ = dav(t, h, http.MethodPut, davHref, ownerID, header, body)
+		require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, "must not overwrite event")
+
+		var day eventsResponse
+		resp = h.do(http.MethodGet, dayPath(time.Date(2030, time.October, 15, 0, 0, 0, 0, time.UTC)), ownerID, nil, &day)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, day.Events, 1, "must create event")
+
+		ev := day.Events[0]
+		require.Equal(t, strings.ToLower(uid), ev.EventID)
+		require.Equal(t, "Планирование спринта", ev.Title)
+		require.Equal(t, "Обсудить задачи, сроки", ev.Description)
+		require.True(t, time.Date(2030, time.October, 15, 9, 0, 0, 0, time.UTC).Equal(ev.StartAt), "must convert TZID")
+		require.True(t, time.Date(2030, time.October, 15, 10, 30, 0, 0, time.UTC).Equal(ev.EndAt))
+		require.Equal(t, []string{"work"}, ev.Labels, "must keep only catalog labels")
+		require.Equal(t, uint32(1), ev.NotifyBefore, "must convert alarm")
+		require.Equal(t, "Переговорная 3", ev.Location.Name)
+
+		resp, data = dav(t, h, http.MethodPut, davHref, ownerID, nil,
+			davFixture(t, "put_event_recurring.ics", "{{UID}}", uid))
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject recurring event")
+		require.Contains(t, string(data), "recurring events are not supported")
+
+		resp, _ = dav(t, h, http.MethodPut, "/v1/caldav/events/"+uuid.NewString()+".ics", ownerID, nil, body)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject UID mismatch")
+
+		resp, _ = dav(t, h, http.MethodPut, davHref, ownerID, nil, "not a calendar")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject invalid calendar")
+	})
+
+	t.Run("get", func(t *testing.T) {
+		resp, data := dav(t, h, http.MethodGet, davHref, ownerID, nil, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+
+		etag := resp.Header.Get("ETag")
+		require.NotEmpty(t, etag, "must have ETag")
+		require.False(t, strings.HasPrefix(etag, "W/"), "must be strong ETag")
+
+		calendar, err := ical.Parse(strings.NewReader(string(data)))
+		require.NoError(t, err, "must be valid calendar")
+
+		vevents := calendar.Components("VEVENT")
+		require.Len(t, vevents, 1)
+		require.Equal(t, strings.ToLower(uid), vevents[0].Text("UID"))
+		require.Equal(t, "20301015T090000Z", vevents[0].Text("DTSTART"))
+
+		resp, _ = dav(t, h, http.MethodGet, davHref, ownerID, http.Header{"If-None-Match": {etag}}, "")
+		require.Equal(t, http.StatusNotModified, resp.StatusCode, "must not send the same version")
+
+		resp, _ = dav(t, h, http.MethodGet, davHref, uuid.NewString(), nil, "")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not get event of other owner")
+
+		ms := davMulti(t, h, "PROPFIND", "/v1/caldav/events/", ownerID, http.Header{"Depth": {"0"}},
+			davFixture(t, "propfind_calendar.xml"))
+		_, _, cal, _ := ms.Responses[0].found(t)
+		require.NotEqual(t, ctag, cal, "ctag must change")
+	})
+
+	t.Run("report", func(t *testing.T) {
+		ms := davMulti(t, h, "REPORT", "/v1/caldav/events/", ownerID, depth1, davFixture(t, "report_query.xml"))
+		require.Len(t, ms.Responses, 1, "must find events in time range")
+		require.Equal(t, davHref, ms.Responses[0].Href)
+
+		_, etag, _, data := ms.Responses[0].found(t)
+		require.NotEmpty(t, etag)
+		require.Contains(t, data, "SUMMARY:Планирование спринта", "must return calendar data")
+		require.Contains(t, data, "\r\n", "must keep CRLF")
+
+		ms = davMulti(t, h, "REPORT", "/v1/caldav/events/", ownerID, depth1, davFixture(t, "report_query_todo.xml"))
+		require.Empty(t, ms.Responses, "must not find tasks")
+
+		missing := "/v1/caldav/events/" + uuid.NewString() + ".ics"
+		ms = davMulti(t, h, "REPORT", "/v1/caldav/events/", ownerID, depth1,
+			davFixture(t, "report_multiget.xml", "{{HREF}}", h.URL+apiHref, "{{MISSING}}", missing))
+		require.Len(t, ms.Responses, 2)
+		require.Equal(t, apiHref, ms.Responses[0].Href)
+
+		_, _, _, data = ms.Responses[0].found(t)
+		require.Contains(t, data, "SUMMARY:review")
+		require.Equal(t, missing, ms.Responses[1].Href)
+		require.Contains(t, ms.Responses[1].Status, "404", "must report missing event")
+
+		resp, _ := dav(t, h, "REPORT", "/v1/caldav/events/", ownerID, depth1, davFixture(t, "report_sync_collection.xml"))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode, "must reject unsupported report")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		resp, data := dav(t, h, http.MethodGet, apiHref, ownerID, nil, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		etag := resp.Header.Get("ETag")
+		body := strings.Replace(string(data), "SUMMARY:review", "SUMMARY:code review", 1)
+
+		resp, _ = dav(t, h, http.MethodPut, apiHref, ownerID, http.Header{"If-Match": {etag}}, body)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode, "must update event")
+		require.Empty(t, resp.Header.Get("ETag"), "must not return ETag of changed resource")
+
+		resp, _ = dav(t, h, http.MethodPut, apiHref, ownerID, http.Header{"If-Match": {etag}}, body)
+		require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, "must reject stale ETag")
+
+		var day eventsResponse
+		resp = h.do(http.MethodGet, dayPath(apiEvent.StartAt), ownerID, nil, &day)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, day.Events, 1)
+		require.Equal(t, "code review", day.Events[0].Title, "must update title")
+		require.Equal(t, apiEvent.Color, day.Events[0].Color, "must keep color")
+		require.Equal(t, apiEvent.Location, day.Events[0].Location, "must keep location")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp, _ := dav(t, h, http.MethodDelete, davHref, ownerID, http.Header{"If-Match": {`"stale"`}}, "")
+		require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, "must reject stale ETag")
+
+		resp, _ = dav(t, h, http.MethodDelete, davHref, uuid.NewString(), nil, "")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not delete event of other owner")
+
+		resp, _ = dav(t, h, http.MethodDelete, davHref, ownerID, nil, "")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode, "must delete event")
+
+		resp, _ = dav(t, h, http.MethodGet, davHref, ownerID, nil, "")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not find deleted event")
+
+		resp, _ = dav(t, h, http.MethodDelete, davHref, ownerID, nil, "")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not delete twice")
+
+		ms := davMulti(t, h, "PROPFIND", "/v1/caldav/events/", ownerID, depth1, "")
+		require.Len(t, ms.Responses, 2, "must list remaining event")
+		require.Equal(t, apiHref, ms.Responses[1].Href)
+	})
+}
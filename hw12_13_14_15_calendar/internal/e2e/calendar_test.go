@@ -0,0 +1,359 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// event - событие в HTTP API.
+type event struct {
+	EventID      string    `json:"eventId"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	StartAt      time.Time `json:"startAt"`
+	EndAt        time.Time `json:"endAt"`
+	NotifyBefore uint32    `json:"notifyBefore"`
+	Labels       []string  `json:"labels,omitempty"`
+	Color        string    `json:"color,omitempty"`
+
+	Location      *location `json:"location,omitempty"`
+	ConferenceURL string    `json:"conferenceUrl,omitempty"`
+}
+
+// label - метка каталога в HTTP API.
+type label struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+type labelsResponse struct {
+	Labels []label `json:"labels"`
+}
+
+type eventResponse struct {
+	Event event `json:"event"`
+}
+
+type eventsResponse struct {
+	Events []event `json:"events"`
+}
+
+// dayPath возвращает путь запроса событий за день t.
+func dayPath(t time.Time) string {
+	return fmt.Sprintf("/api/v1/events/query/day/%d/%d/%d", t.Year(), t.Month(), t.Day())
+}
+
+func Test_EventLifecycle(t *testing.T) {
+	start := time.Date(2030, time.January, 10, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 24 * time.Hour,
+	})
+
+	ownerID := uuid.NewString()
+
+	// событие через двое суток с уведомлением за день
+	ev := event{
+		EventID:      uuid.NewString(),
+		Title:        "planning",
+		StartAt:      start.Add(48 * time.Hour),
+		EndAt:        start.Add(49 * time.Hour),
+		NotifyBefore: 1,
+		Labels:       []string{}, // API всегда возвращает список меток
+	}
+
+	var created eventResponse
+	resp := h.do(http.MethodPost, "/api/v1/events", ownerID, ev, &created)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+	require.NotEmpty(t, resp.Header.Get("X-Request-Id"), "must return request id")
+	require.Equal(t, ev, created.Event, "must return created event")
+
+	var day eventsResponse
+	resp = h.do(http.MethodGet, dayPath(ev.StartAt), ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events")
+	require.Equal(t, []event{ev}, day.Events, "must find created event")
+
+	// события другого владельца не видны
+	var other eventsResponse
+	resp = h.do(http.MethodGet, dayPath(ev.StartAt), uuid.NewString(), nil, &other)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events")
+	require.Empty(t, other.Events, "must not find events of other owner")
+
+	// до времени уведомления ничего не рассылается
+	h.Advance(23 * time.Hour)
+	require.Never(
+		t,
+		func() bool { return h.Sent() != "" },
+		100*time.Millisecond,
+		10*time.Millisecond,
+		"must not notify before time",
+	)
+
+	// наступило время уведомления
+	h.Advance(time.Hour)
+	require.Eventually(
+		t,
+		func() bool { return strings.Contains(h.Sent(), "eventID="+ev.EventID) },
+		waitTimeout,
+		10*time.Millisecond,
+		"must deliver reminder",
+	)
+	require.Contains(t, h.Sent(), "ownerID="+ownerID)
+	require.Contains(t, h.Sent(), ev.Title)
+
+	// событие закончилось больше суток назад - удаляется при очистке
+	h.Advance(50 * time.Hour)
+	require.Eventually(
+		t,
+		func() bool {
+			var day eventsResponse
+			resp := h.do(http.MethodGet, dayPath(ev.StartAt), ownerID, nil, &day)
+			return resp.StatusCode == http.StatusOK && len(day.Events) == 0
+		},
+		waitTimeout,
+		10*time.Millisecond,
+		"must purge old event",
+	)
+
+	require.Equal(t, 1, strings.Count(h.Sent(), "send notification"), "must deliver reminder once")
+}
+
+func Test_UpdateAndDelete(t *testing.T) {
+	start := time.Date(2030, time.January, 10, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 365 * 24 * time.Hour,
+	})
+
+	ownerID := uuid.NewString()
+
+	ev := event{
+		EventID: uuid.NewString(),
+		Title:   "review",
+		StartAt: start.Add(2 * time.Hour),
+		EndAt:   start.Add(3 * time.Hour),
+		Labels:  []string{}, // API всегда возвращает список меток
+	}
+
+	resp := h.do(http.MethodPost, "/api/v1/events", ownerID, ev, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+
+	// уведомление добавлено при изменении события
+	ev.Title = "design review"
+	ev.NotifyBefore = 1
+
+	var updated eventResponse
+	resp = h.do(http.MethodPut, "/api/v1/events/"+ev.EventID, ownerID, ev, &updated)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must update event")
+	require.Equal(t, ev, updated.Event, "must return updated event")
+
+	// время уведомления (за день до начала) уже прошло - уведомление не рассылается
+	h.Advance(time.Minute)
+	require.Never(
+		t,
+		func() bool { return h.Sent() != "" },
+		100*time.Millisecond,
+		10*time.Millisecond,
+		"must not notify about past reminders",
+	)
+
+	resp = h.do(http.MethodDelete, "/api/v1/events/"+ev.EventID, ownerID, nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must delete event")
+
+	var day eventsResponse
+	resp = h.do(http.MethodGet, dayPath(ev.StartAt), ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events")
+	require.Empty(t, day.Events, "event must be deleted")
+
+	resp = h.do(http.MethodDelete, "/api/v1/events/"+ev.EventID, ownerID, nil, nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not delete missed event")
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+}
+
+func Test_InvalidRequests(t *testing.T) {
+	start := time.Date(2030, time.January, 10, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 365 * 24 * time.Hour,
+	})
+
+	ev := event{
+		EventID: uuid.NewString(),
+		Title:   "sync",
+		StartAt: start.Add(time.Hour),
+		EndAt:   start.Add(2 * time.Hour),
+	}
+
+	resp := h.do(http.MethodPost, "/api/v1/events", "", ev, nil)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "must require owner")
+
+	resp = h.do(http.MethodPost, "/api/v1/events", "not-an-owner", ev, nil)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "must validate owner")
+
+	ownerID := uuid.NewString()
+
+	invalid := ev
+	invalid.EndAt = invalid.StartAt.Add(-time.Hour)
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, invalid, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject event ending before start")
+
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, ev, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+
+	// пересекающееся событие того же владельца
+	overlapping := ev
+	overlapping.EventID = uuid.NewString()
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, overlapping, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject overlapping event")
+
+	// событие с тем же ID
+	duplicate := ev
+	duplicate.StartAt = ev.EndAt
+	duplicate.EndAt = ev.EndAt.Add(time.Hour)
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, duplicate, nil)
+	require.Equal(t, http.StatusConflict, resp.StatusCode, "must reject duplicate event id")
+}
+
+func Test_Tenants(t *testing.T) {
+	start := time.Date(2030, time.January, 10, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 365 * 24 * time.Hour,
+		Quotas: calendarBusiness.Quotas{
+			Tenants: map[model.TenantID]int{"small": 1},
+		},
+	})
+
+	ownerID := uuid.NewString()
+	ev := event{
+		EventID: uuid.NewString(),
+		Title:   "standup",
+		StartAt: start.Add(time.Hour),
+		EndAt:   start.Add(2 * time.Hour),
+	}
+
+	// одно и то же событие владельца в разных пространствах
+	resp := h.do(http.MethodPost, "/api/v1/events", ownerID, ev, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event in default tenant")
+
+	resp = h.doTenant(http.MethodPost, "/api/v1/events", "hr", ownerID, ev, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create same event in other tenant")
+
+	resp = h.doTenant(http.MethodDelete, "/api/v1/events/"+ev.EventID, "hr", ownerID, nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must delete event in tenant")
+
+	var day eventsResponse
+	resp = h.do(http.MethodGet, dayPath(ev.StartAt), ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events")
+	require.Len(t, day.Events, 1, "must keep event in default tenant")
+
+	resp = h.doTenant(http.MethodGet, dayPath(ev.StartAt), "Not A Tenant", ownerID, nil, nil)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "must validate tenant")
+
+	// квота пространства
+	resp = h.doTenant(http.MethodPost, "/api/v1/events", "small", ownerID, ev, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event within quota")
+
+	next := ev
+	next.EventID = uuid.NewString()
+	next.StartAt = ev.EndAt
+	next.EndAt = ev.EndAt.Add(time.Hour)
+	resp = h.doTenant(http.MethodPost, "/api/v1/events", "small", ownerID, next, nil)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "must reject event over quota")
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+}
+
+func Test_Labels(t *testing.T) {
+	start := time.Date(2030, time.January, 10, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 365 * 24 * time.Hour,
+	})
+
+	ownerID := uuid.NewString()
+
+	for _, l := range []label{{Name: "work", Color: "#ff0000"}, {Name: "home"}} {
+		resp := h.do(http.MethodPost, "/api/v1/labels", ownerID, l, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode, "must create label")
+	}
+
+	resp := h.do(http.MethodPost, "/api/v1/labels", ownerID, label{Name: "work"}, nil)
+	require.Equal(t, http.StatusConflict, resp.StatusCode, "must reject duplicate label")
+
+	resp = h.do(http.MethodPut, "/api/v1/labels/home", ownerID, label{Color: "#00FF00"}, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must update label")
+
+	var labels labelsResponse
+	resp = h.do(http.MethodGet, "/api/v1/labels", ownerID, nil, &labels)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must list labels")
+	require.Equal(t, []label{{Name: "home", Color: "#00ff00"}, {Name: "work", Color: "#ff0000"}}, labels.Labels)
+
+	// события с метками и без
+	for i, eventLabels := range [][]string{{"work"}, {"home", "work"}, nil} {
+		ev := event{
+			EventID: uuid.NewString(),
+			Title:   fmt.Sprintf("event %d", i),
+			StartAt: start.Add(time.Duration(i+1) * time.Hour),
+			EndAt:   start.Add(time.Duration(i+2) * time.Hour),
+			Labels:  eventLabels,
+			Color:   "#0000ff",
+		}
+
+		resp = h.do(http.MethodPost, "/api/v1/events", ownerID, ev, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+	}
+
+	unknown := event{
+		EventID: uuid.NewString(),
+		Title:   "unknown",
+		StartAt: start.Add(10 * time.Hour),
+		EndAt:   start.Add(11 * time.Hour),
+		Labels:  []string{"unknown"},
+	}
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, unknown, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject unknown label")
+
+	var day eventsResponse
+	resp = h.do(http.MethodGet, dayPath(start)+"?labels=home", ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events by label")
+	require.Len(t, day.Events, 1, "must filter events by label")
+	require.Equal(t, []string{"home", "work"}, day.Events[0].Labels)
+	require.Equal(t, "#0000ff", day.Events[0].Color)
+
+	resp = h.do(http.MethodGet, dayPath(start)+"?labels=home&labels=work", ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events by labels")
+	require.Len(t, day.Events, 2, "must match any of labels")
+
+	// удаление метки снимает её с событий
+	resp = h.do(http.MethodDelete, "/api/v1/labels/work", ownerID, nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must delete label")
+
+	day = eventsResponse{}
+	resp = h.do(http.MethodGet, dayPath(start)+"?labels=work", ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events by deleted label")
+	require.Empty(t, day.Events, "deleted label matches nothing")
+
+	resp = h.do(http.MethodGet, dayPath(start)+"?labels=Work", ownerID, nil, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must validate labels filter")
+
+	day = eventsResponse{}
+	resp = h.do(http.MethodGet, dayPath(start), ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events")
+	require.Len(t, day.Events, 3)
+	require.Empty(t, day.Events[0].Labels, "must remove deleted label from event")
+	require.Equal(t, []string{"home"}, day.Events[1].Labels, "must keep other labels")
+}
@@ -0,0 +1,161 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+)
+
+// feedToken - ссылка на календарь в HTTP API.
+type feedToken struct {
+	TokenID   string    `json:"tokenId"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type createFeedTokenResponse struct {
+	Token  feedToken `json:"token"`
+	Secret string    `json:"secret"`
+	Path   string    `json:"path"`
+}
+
+type feedTokensResponse struct {
+	Tokens []feedToken `json:"tokens"`
+}
+
+// getFeed выполняет GET-запрос календаря по пути path с заголовками header, без аутентификации.
+func getFeed(t *testing.T, h *harness, path string, header http.Header) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, h.URL+path, nil)
+	require.NoError(t, err, "must create request")
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "must do request")
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "must read response")
+
+	return resp, data
+}
+
+func Test_Feed(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	h := newHarness(t, harnessOptions{
+		Start:          now,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 24 * time.Hour,
+	})
+
+	ownerID := uuid.NewString()
+
+	ev := event{
+		EventID: uuid.NewString(),
+		Title:   "planning",
+		StartAt: now.Add(48 * time.Hour),
+		EndAt:   now.Add(49 * time.Hour),
+	}
+	resp := h.do(http.MethodPost, "/api/v1/events", ownerID, ev, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+
+	var created createFeedTokenResponse
+	resp = h.do(http.MethodPost, "/api/v1/feed-tokens", ownerID, map[string]string{"name": "phone"}, &created)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create feed token")
+	require.NotEmpty(t, created.Secret, "must return secret")
+	require.Equal(t, "/v1/feed/"+created.Secret+".ics", created.Path, "must return feed path")
+	require.Equal(t, "phone", created.Token.Name)
+	require.True(t, now.Equal(created.Token.CreatedAt), "must be created by harness clock")
+
+	var tokens feedTokensResponse
+	resp = h.do(http.MethodGet, "/api/v1/feed-tokens", ownerID, nil, &tokens)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must list feed tokens")
+	require.Equal(t, []feedToken{created.Token}, tokens.Tokens, "must list created token")
+
+	resp = h.do(http.MethodGet, "/api/v1/feed-tokens", uuid.NewString(), nil, &tokens)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must list feed tokens")
+	require.Empty(t, tokens.Tokens, "must not list tokens of other owner")
+
+	resp, data := getFeed(t, h, created.Path, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must get feed without authentication: %s", data)
+	require.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	require.NotEmpty(t, etag, "must return ETag")
+	require.Equal(t, now.Format(http.TimeFormat), lastModified, "must be modified at first request")
+
+	calendar, err := ical.Parse(bytes.NewReader(data))
+	require.NoError(t, err, "must return iCalendar")
+	require.Equal(t, "phone", calendar.Text("X-WR-CALNAME"))
+
+	vevents := calendar.Components("VEVENT")
+	require.Len(t, vevents, 1, "must have event")
+	require.Equal(t, ev.EventID, vevents[0].Text("UID"))
+	require.Equal(t, "planning", vevents[0].Text("SUMMARY"))
+	require.Equal(t, ical.FormatDateTime(ev.StartAt), vevents[0].Text("DTSTART"))
+
+	t.Run("conditional", func(t *testing.T) {
+		resp, data := getFeed(t, h, created.Path, http.Header{"If-None-Match": {etag}})
+		require.Equal(t, http.StatusNotModified, resp.StatusCode, "must not be modified by ETag")
+		require.Empty(t, data, "must not return content")
+		require.Equal(t, etag, resp.Header.Get("ETag"))
+
+		resp, _ = getFeed(t, h, created.Path, http.Header{"If-Modified-Since": {lastModified}})
+		require.Equal(t, http.StatusNotModified, resp.StatusCode, "must not be modified by Last-Modified")
+
+		resp, _ = getFeed(t, h, created.Path, http.Header{
+			"If-None-Match":     {`W/"other"`},
+			"If-Modified-Since": {lastModified},
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode, "If-None-Match must take precedence")
+
+		updated := ev
+		updated.Title = "retro"
+		resp = h.do(http.MethodPut, "/api/v1/events/"+ev.EventID, ownerID, updated, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode, "must update event")
+
+		h.Advance(time.Hour)
+		modifiedAt := now.Add(time.Hour)
+
+		resp, data = getFeed(t, h, created.Path, http.Header{"If-None-Match": {etag}})
+		require.Equal(t, http.StatusOK, resp.StatusCode, "must be modified after event update")
+		require.NotEqual(t, etag, resp.Header.Get("ETag"), "must change ETag")
+		require.Equal(t, modifiedAt.Format(http.TimeFormat), resp.Header.Get("Last-Modified"), "must change Last-Modified")
+		require.Contains(t, string(data), "SUMMARY:retro")
+	})
+
+	t.Run("unknown secret", func(t *testing.T) {
+		resp, _ := getFeed(t, h, "/v1/feed/"+created.Secret[1:]+"x.ics", nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not find feed")
+
+		resp, _ = getFeed(t, h, "/v1/feed/secret.ics", nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "invalid secret must be indistinguishable")
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		resp := h.do(http.MethodDelete, "/api/v1/feed-tokens/"+created.Token.TokenID, uuid.NewString(), nil, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not revoke token of other owner")
+
+		resp = h.do(http.MethodDelete, "/api/v1/feed-tokens/"+created.Token.TokenID, ownerID, nil, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode, "must revoke token")
+
+		resp, _ = getFeed(t, h, created.Path, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not get feed by revoked token")
+
+		resp = h.do(http.MethodDelete, "/api/v1/feed-tokens/"+created.Token.TokenID, ownerID, nil, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not revoke token twice")
+	})
+}
@@ -0,0 +1,344 @@
+// e2e - интеграционные тесты сервиса календаря целиком:
+// сервер календаря (GRPC и grpc-gateway), планировщик и рассыльщик запускаются в одном процессе,
+// с хранилищем и очередью уведомлений в памяти, а время планировщика управляется тестом.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	attachmentAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/attachment"
+	caldavAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/caldav"
+	calendarAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/calendar"
+	feedAPI "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/feed"
+	pbEventV1 "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
+	calendarBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/calendar"
+	schedulerBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/scheduler"
+	senderBusiness "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/business/sender"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/gw"
+	grpcInterceptor "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/interceptor"
+	internalhttp "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http"
+	httpMiddleware "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/middleware"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http/web"
+	queue "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/queue/notify/memory"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+	localBlob "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob/local"
+	memoryStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
+)
+
+// waitTimeout - сколько ждать асинхронного результата (уведомления, очистки).
+const waitTimeout = 5 * time.Second
+
+// harnessOptions - параметры запуска сервисов.
+type harnessOptions struct {
+	// Start - начальное время часов планировщика.
+	Start time.Time
+
+	// NotifyInterval и PurgeOlderThan - параметры планировщика.
+	NotifyInterval time.Duration
+	PurgeOlderThan time.Duration
+
+	// Quotas - ограничения количества событий в рабочих пространствах.
+	Quotas calendarBusiness.Quotas
+
+	// Attachments - ограничения вложений событий.
+	Attachments calendarBusiness.AttachmentLimits
+
+	// Holidays - источник календарей праздников, nil - календари праздников недоступны.
+	Holidays calendarBusiness.HolidaySource
+}
+
+// harness - запущенные в процессе сервисы календаря.
+type harness struct {
+	t *testing.T
+
+	// URL - адрес HTTP-сервера календаря.
+	URL string
+
+	// Clock - часы планировщика, календаря и календарей по ссылкам.
+	Clock *clock.Fake
+
+	// Conn - GRPC-соединение с сервером календаря.
+	Conn *grpc.ClientConn
+
+	// Blobs - хранилище файлов вложений.
+	Blobs blob.Store
+
+	sent *syncBuffer
+}
+
+// newHarness запускает сервер календаря, планировщик и рассыльщик.
+// Сервисы останавливаются по завершении теста.
+func newHarness(t *testing.T, opts harnessOptions) *harness {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	storage := memoryStorage.NewStorage()
+
+	blobs, err := localBlob.NewStore(t.TempDir())
+	require.NoError(t, err, "must create blob store")
+
+	h := &harness{
+		t:     t,
+		Clock: clock.NewFake(opts.Start),
+		Blobs: blobs,
+		sent:  &syncBuffer{},
+	}
+
+	h.URL, h.Conn = startCalendar(t, logger, h.Clock, storage, blobs, opts)
+
+	notifyQueue := queue.NewNotifyQueue(100)
+
+	// рассыльщик
+	senderCtx, senderCancel := context.WithCancel(context.Background())
+	sender := senderBusiness.NewApp(logger, receive(senderCtx, notifyQueue), h.sent)
+	require.True(t, sender.Send(senderCtx), "sender must start")
+
+	// планировщик
+	schedulerCtx, schedulerCancel := context.WithCancel(context.Background())
+	scheduler := schedulerBusiness.NewApp(logger, h.Clock, notifyQueue, storage)
+	scheduler.NotifyInterval = opts.NotifyInterval
+	scheduler.PurgeOlderThan = opts.PurgeOlderThan
+	scheduler.Blobs = blobs
+	scheduler.Schedule(schedulerCtx)
+
+	// задачи уведомления и очистки ждут срабатывания своих тикеров
+	h.Clock.BlockUntil(2)
+
+	t.Cleanup(func() {
+		schedulerCancel()
+		scheduler.Wait()
+
+		notifyQueue.Close()
+		senderCancel()
+		sender.Wait()
+	})
+
+	return h
+}
+
+// startCalendar запускает GRPC-сервер и HTTP-сервер с grpc-gateway календаря на свободных портах.
+// Время сервиса календаря берётся из часов clk.
+// Возвращает адрес HTTP-сервера и GRPC-соединение с сервером.
+func startCalendar(
+	t *testing.T,
+	logger *slog.Logger,
+	clk clock.Clock,
+	storage *memoryStorage.Storage,
+	blobs blob.Store,
+	opts harnessOptions,
+) (string, *grpc.ClientConn) {
+	t.Helper()
+
+	calendarBusinessApp := calendarBusiness.NewApp(logger, clk, storage)
+	calendarBusinessApp.SetQuotas(opts.Quotas)
+	calendarBusinessApp.SetAttachments(blobs, opts.Attachments)
+	if opts.Holidays != nil {
+		calendarBusinessApp.SetHolidays(opts.Holidays)
+	}
+
+	calendarAPIApp := calendarAPI.NewApp(calendarBusinessApp, logger)
+	attachmentAPIApp := attachmentAPI.NewApp(calendarBusinessApp, logger)
+	feedAPIApp := feedAPI.NewApp(calendarBusinessApp, clk, logger)
+	caldavAPIApp := caldavAPI.NewApp(calendarBusinessApp, logger)
+
+	logInterceptors := grpcInterceptor.LogRequest(logger)
+	authInterceptors := grpcInterceptor.Auth(logger, nil)
+	readYourWritesInterceptors := grpcInterceptor.ReadYourWrites()
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			logInterceptors.UnaryInterceptor,
+			authInterceptors.UnaryInterceptor,
+			readYourWritesInterceptors.UnaryInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			logInterceptors.StreamInterceptor,
+			authInterceptors.StreamInterceptor,
+			readYourWritesInterceptors.StreamInterceptor,
+		),
+	)
+	pbEventV1.RegisterEventServiceServer(grpcServer, calendarAPIApp)
+
+	lstn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "must listen GRPC")
+
+	go grpcServer.Serve(lstn)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lstn.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err, "must create GRPC-gw client")
+	t.Cleanup(func() { conn.Close() })
+
+	gwMux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gw.HeaderMatchers(gw.NoGRPCHeaders, gw.OwnerID, gw.TenantID)),
+		runtime.WithErrorHandler(gw.ProblemErrorHandler),
+	)
+	err = pbEventV1.RegisterEventServiceHandler(context.Background(), gwMux, conn)
+	require.NoError(t, err, "must register GRPC-gw handler")
+
+	webMux, err := web.NewMux(logger, attachmentAPIApp, feedAPIApp, caldavAPIApp)
+	require.NoError(t, err, "must create web-mux")
+
+	httpMux := http.NewServeMux()
+	httpMux.Handle(
+		"/api/",
+		internalhttp.ApplyMiddlewares(
+			gwMux,
+			httpMiddleware.URLPathPrefixReplace("/api/", "/"),
+			httpMiddleware.LogRequest(logger),
+		),
+	)
+
+	httpMux.Handle(
+		feedAPI.PathPrefix,
+		internalhttp.ApplyMiddlewares(
+			webMux,
+			httpMiddleware.RedactPath(feedAPI.PathPrefix, feedAPI.PathSuffix),
+			httpMiddleware.LogRequest(logger),
+		),
+	)
+
+	httpMux.Handle("/", webMux)
+
+	httpServer := httptest.NewServer(httpMux)
+	t.Cleanup(httpServer.Close)
+
+	return httpServer.URL, conn
+}
+
+// receive возвращает канал уведомлений из очереди q для рассыльщика.
+func receive(ctx context.Context, q *queue.NotifyQueue) <-chan senderBusiness.NotificationMessage {
+	out := make(chan senderBusiness.NotificationMessage)
+	go func() {
+		defer close(out)
+		for n := range q.Receive() {
+			select {
+			case out <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// do выполняет HTTP-запрос method к path от имени ownerID (если задан) с телом body (если задано).
+// Ответ декодируется в out, если задан и запрос успешен.
+func (h *harness) do(method string, path string, ownerID string, body any, out any) *http.Response {
+	h.t.Helper()
+
+	return h.doTenant(method, path, "", ownerID, body, out)
+}
+
+// doTenant выполняет HTTP-запрос, как do, в рабочем пространстве tenantID (если задано).
+func (h *harness) doTenant(
+	method string,
+	path string,
+	tenantID string,
+	ownerID string,
+	body any,
+	out any,
+) *http.Response {
+	h.t.Helper()
+
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(h.t, err, "must marshal request")
+
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, h.URL+path, r)
+	require.NoError(h.t, err, "must create request")
+
+	if ownerID != "" {
+		req.Header.Set("X-Owner-ID", ownerID)
+	}
+
+	if tenantID != "" {
+		req.Header.Set("X-Tenant-ID", tenantID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(h.t, err, "must do request")
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(h.t, err, "must read response")
+
+	if out != nil && resp.StatusCode == http.StatusOK {
+		require.NoError(h.t, json.Unmarshal(data, out), "must decode response: %s", data)
+	}
+
+	return resp
+}
+
+// get выполняет GET-запрос к path от имени ownerID и возвращает ответ с прочитанным телом.
+func (h *harness) get(path string, ownerID string) (*http.Response, []byte) {
+	h.t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, h.URL+path, nil)
+	require.NoError(h.t, err, "must create request")
+
+	if ownerID != "" {
+		req.Header.Set("X-Owner-ID", ownerID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(h.t, err, "must do request")
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(h.t, err, "must read response")
+
+	return resp, data
+}
+
+// Sent возвращает разосланные уведомления.
+func (h *harness) Sent() string {
+	return h.sent.String()
+}
+
+// Advance переводит часы сервисов вперёд на d.
+func (h *harness) Advance(d time.Duration) {
+	h.Clock.Advance(d)
+}
+
+// syncBuffer - потокобезопасный буфер для вывода рассыльщика.
+type syncBuffer struct {
+	mx  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	return b.buf.String()
+}
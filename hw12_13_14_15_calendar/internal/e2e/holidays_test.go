@@ -0,0 +1,120 @@
+package e2e
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/holiday"
+)
+
+// holidayEntry - праздник в HTTP API.
+type holidayEntry struct {
+	Calendar string `json:"calendar"`
+	Date     date   `json:"date"`
+	Title    string `json:"title"`
+}
+
+type monthResponse struct {
+	Events   []event        `json:"events"`
+	Holidays []holidayEntry `json:"holidays"`
+}
+
+type holidayCalendarsResponse struct {
+	Calendars []struct {
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	} `json:"calendars"`
+}
+
+// writeHolidays записывает календарь праздников name с праздником title на дату day
+// и сдвигает время изменения файла на modTime.
+func writeHolidays(t *testing.T, dir string, name string, day string, title string, modTime time.Time) {
+	t.Helper()
+
+	data := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nX-WR-CALNAME:" + name + " holidays\r\n" +
+		"BEGIN:VEVENT\r\nUID:" + uuid.NewString() + "\r\nDTSTART;VALUE=DATE:" + day + "\r\n" +
+		"SUMMARY:" + title + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	file := filepath.Join(dir, name+".ics")
+	require.NoError(t, os.WriteFile(file, []byte(data), 0o600), "must write calendar")
+	require.NoError(t, os.Chtimes(file, modTime, modTime), "must set mod time")
+}
+
+func Test_Holidays(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Hour)
+
+	writeHolidays(t, dir, "ru", "20300101", "Новый год", modTime)
+	writeHolidays(t, dir, "de-by", "20300106", "Heilige Drei Könige", modTime)
+
+	holidays, err := holiday.NewStore(slog.New(slog.NewTextHandler(io.Discard, nil)), dir)
+	require.NoError(t, err, "must create holiday store")
+
+	start := time.Date(2029, time.December, 1, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 24 * time.Hour,
+		Holidays:       holidays,
+	})
+
+	ownerID := uuid.NewString()
+	monthPath := fmt.Sprintf("/api/v1/events/query/month/%d/%d", 2030, 1)
+
+	var calendars holidayCalendarsResponse
+	resp := h.do(http.MethodGet, "/api/v1/holiday-calendars", ownerID, nil, &calendars)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must list holiday calendars")
+	require.Len(t, calendars.Calendars, 2)
+	require.Equal(t, "de-by", calendars.Calendars[0].Name)
+	require.Equal(t, "de-by holidays", calendars.Calendars[0].Title)
+
+	ev := event{
+		EventID: uuid.NewString(),
+		Title:   "meeting",
+		StartAt: time.Date(2030, time.January, 6, 10, 0, 0, 0, time.UTC),
+		EndAt:   time.Date(2030, time.January, 6, 11, 0, 0, 0, time.UTC),
+	}
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, ev, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+
+	resp = h.do(http.MethodPut, "/api/v1/profile", ownerID, profile{HolidayCalendars: []string{"fr"}}, nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "must not subscribe to unknown calendar")
+
+	resp = h.do(http.MethodPut, "/api/v1/profile", ownerID, profile{HolidayCalendars: []string{"ru", "de-by"}}, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must subscribe to calendars")
+
+	var month monthResponse
+	resp = h.do(http.MethodGet, monthPath, ownerID, nil, &month)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must get month events")
+	require.Len(t, month.Events, 1, "must keep events")
+	require.Equal(t, []holidayEntry{
+		{Calendar: "ru", Date: date{Year: 2030, Month: 1, Day: 1}, Title: "Новый год"},
+		{Calendar: "de-by", Date: date{Year: 2030, Month: 1, Day: 6}, Title: "Heilige Drei Könige"},
+	}, month.Holidays, "must merge holidays of subscribed calendars")
+
+	resp = h.do(http.MethodGet, monthPath, uuid.NewString(), nil, &month)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must get month events")
+	require.Empty(t, month.Holidays, "must not show holidays to owner without subscription")
+
+	// календарь обновлён на диске
+	writeHolidays(t, dir, "ru", "20300102", "Новогодние каникулы", modTime.Add(time.Minute))
+
+	reloaded, err := holidays.Reload()
+	require.NoError(t, err, "must reload holiday calendars")
+	require.True(t, reloaded, "must reload changed calendar")
+
+	resp = h.do(http.MethodGet, monthPath, ownerID, nil, &month)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must get month events")
+	require.Len(t, month.Holidays, 2)
+	require.Equal(t, "Новогодние каникулы", month.Holidays[0].Title, "must return refreshed holidays")
+	require.Equal(t, 2, month.Holidays[0].Date.Day)
+}
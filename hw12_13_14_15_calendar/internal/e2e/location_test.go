@@ -0,0 +1,101 @@
+package e2e
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// location - место проведения события в HTTP API.
+type location struct {
+	Name    string    `json:"name,omitempty"`
+	Address string    `json:"address,omitempty"`
+	Point   *geoPoint `json:"point,omitempty"`
+}
+
+// geoPoint - координаты в HTTP API.
+type geoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// nearPath возвращает путь HTTP-запроса событий в радиусе radius метров от center на промежутке [from, to).
+func nearPath(center geoPoint, radius float64, from time.Time, to time.Time) string {
+	q := url.Values{}
+	q.Set("center.latitude", strconv.FormatFloat(center.Latitude, 'f', -1, 64))
+	q.Set("center.longitude", strconv.FormatFloat(center.Longitude, 'f', -1, 64))
+	q.Set("radius", strconv.FormatFloat(radius, 'f', -1, 64))
+	q.Set("from", from.Format(time.RFC3339))
+	q.Set("to", to.Format(time.RFC3339))
+
+	return "/api/v1/events/query/near?" + q.Encode()
+}
+
+func Test_Location(t *testing.T) {
+	start := time.Date(2030, time.February, 3, 9, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 24 * time.Hour,
+	})
+
+	ownerID := uuid.NewString()
+
+	office := geoPoint{Latitude: 55.7558, Longitude: 37.6173}
+	events := []event{
+		{
+			EventID:       uuid.NewString(),
+			Title:         "standup",
+			StartAt:       start.Add(time.Hour),
+			EndAt:         start.Add(2 * time.Hour),
+			Location:      &location{Name: "room 3", Address: "Tverskaya 1", Point: &office},
+			ConferenceURL: "https://meet.example.com/standup",
+		},
+		{
+			EventID:  uuid.NewString(),
+			Title:    "offsite",
+			StartAt:  start.Add(3 * time.Hour),
+			EndAt:    start.Add(4 * time.Hour),
+			Location: &location{Point: &geoPoint{Latitude: 59.9343, Longitude: 30.3351}},
+		},
+		{
+			EventID:       uuid.NewString(),
+			Title:         "call",
+			StartAt:       start.Add(5 * time.Hour),
+			EndAt:         start.Add(6 * time.Hour),
+			ConferenceURL: "https://meet.example.com/call",
+		},
+	}
+
+	for _, ev := range events {
+		var created eventResponse
+		resp := h.do(http.MethodPost, "/api/v1/events", ownerID, ev, &created)
+		require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+		require.Equal(t, ev.Location, created.Event.Location, "must keep location")
+		require.Equal(t, ev.ConferenceURL, created.Event.ConferenceURL, "must keep conference URL")
+	}
+
+	var near eventsResponse
+	resp := h.do(http.MethodGet, nearPath(office, 5000, start, start.Add(24*time.Hour)), ownerID, nil, &near)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events near")
+	require.Len(t, near.Events, 1, "must find event in area")
+	require.Equal(t, "standup", near.Events[0].Title)
+
+	resp = h.do(http.MethodGet, nearPath(office, 1_000_000, start, start.Add(24*time.Hour)), ownerID, nil, &near)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events near")
+	require.Len(t, near.Events, 2, "must find events in large area")
+	require.Equal(t, "offsite", near.Events[1].Title, "must order events by start")
+
+	resp = h.do(http.MethodGet, nearPath(office, 0, start, start.Add(24*time.Hour)), ownerID, nil, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject invalid radius")
+
+	ev := events[0]
+	ev.Location = &location{Point: &geoPoint{Latitude: 0, Longitude: 181}}
+	resp = h.do(http.MethodPut, "/api/v1/events/"+ev.EventID, ownerID, ev, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject invalid longitude")
+}
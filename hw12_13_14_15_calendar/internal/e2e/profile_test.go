@@ -0,0 +1,127 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// profile - профиль доступности владельца в HTTP API.
+type profile struct {
+	TimeZone          string       `json:"timeZone"`
+	WorkingDays       []workingDay `json:"workingDays,omitempty"`
+	Holidays          []date       `json:"holidays,omitempty"`
+	AvailabilityCheck string       `json:"availabilityCheck"`
+	HolidayCalendars  []string     `json:"holidayCalendars,omitempty"`
+}
+
+// workingDay - рабочие часы дня недели в HTTP API (1 - понедельник, 7 - воскресенье).
+type workingDay struct {
+	Weekday int    `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// date - календарная дата в HTTP API.
+type date struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+type profileResponse struct {
+	Profile profile `json:"profile"`
+}
+
+type createEventResponse struct {
+	Event    event     `json:"event"`
+	Warnings []warning `json:"warnings"`
+}
+
+type warning struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func Test_Profile(t *testing.T) {
+	// 2030-01-07 - понедельник
+	start := time.Date(2030, time.January, 7, 6, 0, 0, 0, time.UTC)
+	h := newHarness(t, harnessOptions{
+		Start:          start,
+		NotifyInterval: time.Minute,
+		PurgeOlderThan: 24 * time.Hour,
+	})
+
+	ownerID := uuid.NewString()
+
+	var found profileResponse
+	resp := h.do(http.MethodGet, "/api/v1/profile", ownerID, nil, &found)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must get default profile")
+	require.Equal(t, "UTC", found.Profile.TimeZone)
+	require.Equal(t, "off", found.Profile.AvailabilityCheck)
+
+	p := profile{
+		TimeZone: "Asia/Tokyo",
+		WorkingDays: []workingDay{
+			{Weekday: 1, Start: "09:00", End: "18:00"},
+			{Weekday: 2, Start: "09:00", End: "18:00"},
+		},
+		Holidays:          []date{{Year: 2030, Month: 1, Day: 8}},
+		AvailabilityCheck: "warn",
+		HolidayCalendars:  []string{},
+	}
+
+	var updated profileResponse
+	resp = h.do(http.MethodPut, "/api/v1/profile", ownerID, p, &updated)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must update profile")
+	require.Equal(t, p, updated.Profile, "must keep profile")
+
+	resp = h.do(http.MethodGet, "/api/v1/profile", ownerID, nil, &found)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must get profile")
+	require.Equal(t, p, found.Profile, "must return saved profile")
+
+	p.TimeZone = "Mars/Olympus"
+	resp = h.do(http.MethodPut, "/api/v1/profile", ownerID, p, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject invalid time zone")
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	newEvent := func(startAt time.Time) event {
+		return event{
+			EventID: uuid.NewString(),
+			Title:   "meeting",
+			StartAt: startAt,
+			EndAt:   startAt.Add(time.Hour),
+		}
+	}
+
+	var created createEventResponse
+	ev := newEvent(time.Date(2030, time.January, 7, 10, 0, 0, 0, tokyo))
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, ev, &created)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event")
+	require.Empty(t, created.Warnings, "must not warn inside working hours")
+
+	ev = newEvent(time.Date(2030, time.January, 7, 20, 0, 0, 0, tokyo))
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, ev, &created)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must create event outside working hours")
+	require.Len(t, created.Warnings, 1, "must warn outside working hours")
+	require.Equal(t, "OUTSIDE_WORKING_HOURS", created.Warnings[0].Reason)
+
+	p.TimeZone = "Asia/Tokyo"
+	p.AvailabilityCheck = "reject"
+	resp = h.do(http.MethodPut, "/api/v1/profile", ownerID, p, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must update profile")
+
+	ev = newEvent(time.Date(2030, time.January, 8, 10, 0, 0, 0, tokyo))
+	resp = h.do(http.MethodPost, "/api/v1/events", ownerID, ev, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "must reject event on holiday")
+
+	var day eventsResponse
+	resp = h.do(http.MethodGet, dayPath(ev.StartAt.UTC()), ownerID, nil, &day)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "must query events")
+	require.Empty(t, day.Events, "must not create rejected event")
+}
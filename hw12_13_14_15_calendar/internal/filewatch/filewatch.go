@@ -0,0 +1,76 @@
+// filewatch - отслеживание изменения файлов по времени их изменения.
+//
+// Используется хранилищами, которые читают данные из файлов и перечитывают их без перезапуска сервиса
+// (сертификаты TLS, календари праздников): хранилище запоминает ModTimes прочитанных файлов,
+// а Watch периодически вызывает его перечитывание, если файлы изменились.
+package filewatch
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+)
+
+// ModTimes - время изменения файлов по их путям.
+type ModTimes map[string]time.Time
+
+// Stat возвращает время изменения файлов files, пустые пути пропускаются.
+// Возвращает ошибку, если один из файлов недоступен.
+func Stat(files ...string) (ModTimes, error) {
+	modTimes := make(ModTimes, len(files))
+
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+
+		stat, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		modTimes[file] = stat.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+// Equal сообщает, что наборы файлов m и other совпадают и файлы не изменялись.
+func (m ModTimes) Equal(other ModTimes) bool {
+	if len(m) != len(other) {
+		return false
+	}
+
+	for file, t := range m {
+		ot, ok := other[file]
+		if !ok || !t.Equal(ot) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Watch раз в interval по часам clk вызывает reload, который перечитывает файлы, если они изменились,
+// и передаёт результат (перечитаны ли файлы и ошибку) в report. Завершается при отмене контекста ctx.
+func Watch(
+	ctx context.Context,
+	clk clock.Clock,
+	interval time.Duration,
+	reload func() (bool, error),
+	report func(reloaded bool, err error),
+) {
+	t := clk.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.Chan():
+			report(reload())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package filewatch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+)
+
+func TestStat(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(file, []byte("a"), 0o600))
+
+	modTimes, err := Stat(file, "")
+	require.NoError(t, err, "must stat file")
+	require.Len(t, modTimes, 1, "must skip empty path")
+
+	same, err := Stat(file)
+	require.NoError(t, err, "must stat file")
+	require.True(t, modTimes.Equal(same), "must be equal")
+
+	require.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Hour)))
+	changed, err := Stat(file)
+	require.NoError(t, err, "must stat file")
+	require.False(t, modTimes.Equal(changed), "must detect modification")
+
+	require.False(t, modTimes.Equal(ModTimes{}), "must detect removed file")
+	require.False(t, ModTimes{}.Equal(modTimes), "must detect new file")
+	require.False(t, modTimes.Equal(ModTimes{filepath.Join(dir, "b.txt"): modTimes[file]}), "must detect renamed file")
+
+	_, err = Stat(filepath.Join(dir, "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist, "must fail on missing file")
+}
+
+func TestWatch(t *testing.T) {
+	clk := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	errReload := errors.New("reload failed")
+	results := []error{nil, errReload}
+
+	type report struct {
+		reloaded bool
+		err      error
+	}
+	reports := make(chan report)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		Watch(
+			ctx,
+			clk,
+			time.Minute,
+			func() (bool, error) {
+				err := results[0]
+				results = results[1:]
+
+				return err == nil, err
+			},
+			func(reloaded bool, err error) {
+				reports <- report{reloaded: reloaded, err: err}
+			},
+		)
+	}()
+
+	clk.BlockUntil(1)
+
+	clk.Advance(30 * time.Second)
+	select {
+	case <-reports:
+		require.Fail(t, "must not reload before interval")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clk.Advance(30 * time.Second)
+	require.Equal(t, report{reloaded: true}, <-reports, "must reload after interval")
+
+	clk.Advance(time.Minute)
+	require.Equal(t, report{err: errReload}, <-reports, "must report reload error")
+
+	cancel()
+	<-done
+}
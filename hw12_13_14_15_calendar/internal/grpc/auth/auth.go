@@ -3,6 +3,7 @@
 // По факту - просто провряет корректность OwnerID, т.к. ДЗ не требует авторизации
 //
 // OwnerID нужен, чтобы корректно привязывать события в коллекциях событий.
+// TenantID - рабочее пространство (тенант), в рамках которого выполняются все операции с событиями владельца.
 package auth
 
 import (
@@ -12,11 +13,18 @@ import (
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
 )
 
-var ErrMissedOwnerID = errors.New("value of owner id is missed")
+var (
+	ErrMissedOwnerID  = errors.New("value of owner id is missed")
+	ErrMissedTenantID = errors.New("value of tenant id is missed")
+)
 
 type key int
 
-const AuthOwnerKey = key(1)
+const (
+	AuthOwnerKey   = key(1)
+	AuthServiceKey = key(2)
+	AuthTenantKey  = key(3)
+)
 
 func ValidateOwner(owner string) (model.OwnerID, error) {
 	ownerID, err := model.NewOwnerIDFromString(owner)
@@ -41,3 +49,41 @@ func OwnerIDFromContext(ctx context.Context) (model.OwnerID, error) {
 
 	return ownerID, nil
 }
+
+func ValidateTenant(tenant string) (model.TenantID, error) {
+	tenantID, err := model.NewTenantIDFromString(tenant)
+
+	return tenantID, err
+}
+
+// WithTenantID сохраняет в контексте рабочее пространство tenant.
+func WithTenantID(ctx context.Context, tenant string) (context.Context, error) {
+	tenantID, err := ValidateTenant(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	return context.WithValue(ctx, AuthTenantKey, tenantID), nil
+}
+
+// TenantIDFromContext возвращает рабочее пространство, сохранённое в контексте.
+func TenantIDFromContext(ctx context.Context) (model.TenantID, error) {
+	tenantID, ok := ctx.Value(AuthTenantKey).(model.TenantID)
+	if !ok {
+		return model.TenantID(""), ErrMissedTenantID
+	}
+
+	return tenantID, nil
+}
+
+// WithService сохраняет в контексте имя сервиса, аутентифицированного по клиентскому сертификату.
+func WithService(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, AuthServiceKey, service)
+}
+
+// ServiceFromContext возвращает имя сервиса, аутентифицированного по клиентскому сертификату, если есть.
+func ServiceFromContext(ctx context.Context) (string, bool) {
+	service, ok := ctx.Value(AuthServiceKey).(string)
+
+	return service, ok
+}
@@ -38,3 +38,12 @@ func OwnerID(key string) (string, bool) {
 
 	return key, true
 }
+
+// TenantID заменяет заголовок X-Tenant-ID на Grpc-Metadata-X-Tenant-ID.
+func TenantID(key string) (string, bool) {
+	if key == "X-Tenant-ID" {
+		key = "Grpc-Metadata-X-Tenant-ID"
+	}
+
+	return key, true
+}
@@ -0,0 +1,107 @@
+package gw
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
+)
+
+// Problem - описание ошибки в ответе grpc-gateway в формате application/problem+json (RFC 9457).
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+
+	// Code - grpc-код ошибки.
+	Code string `json:"code"`
+
+	// Reason и Domain - из google.rpc.ErrorInfo, если есть.
+	Reason string `json:"reason,omitempty"`
+	Domain string `json:"domain,omitempty"`
+
+	// Violations - из google.rpc.BadRequest, если есть.
+	Violations []ProblemViolation `json:"violations,omitempty"`
+}
+
+// ProblemViolation - ошибка значения поля запроса.
+type ProblemViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+const problemContentType = "application/problem+json"
+
+// ProblemErrorHandler - обработчик ошибок для мультиплексора grpc-gateway,
+// отдающий ошибки grpc в виде JSON Problem.
+func ProblemErrorHandler(
+	_ context.Context,
+	_ *runtime.ServeMux,
+	_ runtime.Marshaler,
+	w http.ResponseWriter,
+	_ *http.Request,
+	err error,
+) {
+	problem := NewProblem(err)
+
+	if retryAfter, ok := retryAfter(err); ok {
+		w.Header().Set("Retry-After", ratelimit.RetryAfter(retryAfter))
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+
+	json.NewEncoder(w).Encode(problem)
+}
+
+// NewProblem формирует Problem по grpc-ошибке err.
+func NewProblem(err error) Problem {
+	st := status.Convert(err)
+	httpStatus := runtime.HTTPStatusFromCode(st.Code())
+
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(httpStatus),
+		Status: httpStatus,
+		Detail: st.Message(),
+		Code:   st.Code().String(),
+	}
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			problem.Reason = d.GetReason()
+			problem.Domain = d.GetDomain()
+			problem.Type = "urn:problem:" + d.GetDomain() + ":" + d.GetReason()
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				problem.Violations = append(problem.Violations, ProblemViolation{
+					Field:       v.GetField(),
+					Description: v.GetDescription(),
+				})
+			}
+		}
+	}
+
+	return problem
+}
+
+// retryAfter возвращает задержку перед повторным запросом из google.rpc.RetryInfo ошибки err, если есть.
+func retryAfter(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if d, ok := detail.(*errdetails.RetryInfo); ok {
+			return d.GetRetryDelay().AsDuration(), true
+		}
+	}
+
+	return 0, false
+}
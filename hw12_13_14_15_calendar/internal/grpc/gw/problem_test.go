@@ -0,0 +1,86 @@
+package gw
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestProblemErrorHandler(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "empty title").WithDetails(
+		&errdetails.ErrorInfo{Reason: "EMPTY_TITLE", Domain: "calendar.otus"},
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "title", Description: "empty title"},
+			},
+		},
+	)
+	require.NoError(t, err, "must add details")
+
+	w := httptest.NewRecorder()
+	ProblemErrorHandler(context.Background(), nil, nil, w, nil, st.Err())
+
+	require.Equal(t, http.StatusBadRequest, w.Code, "must have proper status")
+	require.Equal(t, problemContentType, w.Header().Get("Content-Type"), "must have problem content type")
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem), "must be json")
+	require.Equal(t, Problem{
+		Type:   "urn:problem:calendar.otus:EMPTY_TITLE",
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Detail: "empty title",
+		Code:   "InvalidArgument",
+		Reason: "EMPTY_TITLE",
+		Domain: "calendar.otus",
+		Violations: []ProblemViolation{
+			{Field: "title", Description: "empty title"},
+		},
+	}, problem)
+}
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{name: "not found", err: status.Error(codes.NotFound, "event not found"), status: 404, code: "NotFound"},
+		{name: "exists", err: status.Error(codes.AlreadyExists, "exists"), status: 409, code: "AlreadyExists"},
+		{name: "busy", err: status.Error(codes.FailedPrecondition, "busy"), status: 400, code: "FailedPrecondition"},
+		{name: "internal", err: status.Error(codes.Internal, "some error"), status: 500, code: "Internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := NewProblem(tt.err)
+			require.Equal(t, "about:blank", problem.Type, "must have default type")
+			require.Equal(t, tt.status, problem.Status, "must have proper status")
+			require.Equal(t, tt.code, problem.Code, "must have proper code")
+			require.Empty(t, problem.Violations, "must have no violations")
+		})
+	}
+}
+
+func TestProblemErrorHandler_RetryAfter(t *testing.T) {
+	st, err := status.New(codes.ResourceExhausted, "rate limit exceeded").WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(1500 * time.Millisecond)},
+	)
+	require.NoError(t, err, "must add details")
+
+	w := httptest.NewRecorder()
+	ProblemErrorHandler(context.Background(), nil, nil, w, nil, st.Err())
+
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "must have proper status")
+	require.Equal(t, "2", w.Header().Get("Retry-After"), "must have Retry-After header")
+}
@@ -2,32 +2,62 @@ package interceptor
 
 import (
 	"context"
+	"crypto/x509"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
+	proto "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/api/proto/event/v1"
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
 )
 
+// publicServices - сервисы, не требующие авторизации.
+var publicServices = []string{
+	healthpb.Health_ServiceDesc.ServiceName,
+}
+
+// adminServices - сервисы, доступные только сервисам-администраторам.
+var adminServices = []string{
+	proto.TenantAdminService_ServiceDesc.ServiceName,
+}
+
 type AuthServerOptions struct {
 	UnaryInterceptor  grpc.UnaryServerInterceptor
 	StreamInterceptor grpc.StreamServerInterceptor
 }
 
 // Auth возвращает пару интерсепторов для авторизации.
-// Т.к. ДЗ не требует авторизации, то здесь лишь сохранении OwnerID в контексте выполнения.
-func Auth(logger *slog.Logger) AuthServerOptions {
+// Т.к. ДЗ не требует авторизации, то здесь лишь сохранении OwnerID и TenantID в контексте выполнения.
+// Рабочее пространство передаётся в метаданных x-tenant-id, без них используется model.DefaultTenantID.
+// Владелец, аутентифицированный по сертификату, привязан к пространству из сертификата (см. authTenant).
+// Методы публичных сервисов (например, grpc health) вызываются без авторизации.
+// Методы административных сервисов доступны только клиентам с сертификатом, CN которого есть в admins.
+func Auth(logger *slog.Logger, admins []string) AuthServerOptions {
 	opts := AuthServerOptions{}
 
 	opts.UnaryInterceptor = grpc.UnaryServerInterceptor(
-		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+			if isPublicMethod(info.FullMethod) {
+				return handler(ctx, req)
+			}
+
 			next := func(ctx context.Context) (any, error) {
 				return handler(ctx, req)
 			}
 
+			if isAdminMethod(info.FullMethod) {
+				return authAdmin(ctx, logger, admins, next)
+			}
+
 			resp, err = authOwner(ctx, logger, next)
 
 			return resp, err
@@ -35,9 +65,18 @@ func Auth(logger *slog.Logger) AuthServerOptions {
 	)
 
 	opts.StreamInterceptor = grpc.StreamServerInterceptor(
-		func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-			next := func(_ context.Context) (any, error) {
-				return nil, handler(srv, stream)
+		func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if isPublicMethod(info.FullMethod) {
+				return handler(srv, stream)
+			}
+
+			next := func(ctx context.Context) (any, error) {
+				return nil, handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+			}
+
+			if isAdminMethod(info.FullMethod) {
+				_, err := authAdmin(stream.Context(), logger, admins, next)
+				return err
 			}
 
 			_, err := authOwner(stream.Context(), logger, next)
@@ -49,11 +88,56 @@ func Auth(logger *slog.Logger) AuthServerOptions {
 	return opts
 }
 
+// authAdmin пропускает вызов административного сервиса, только если клиент предъявил сертификат,
+// CN которого есть в admins. Рабочее пространство передаётся в запросе, а не в метаданных.
+func authAdmin(
+	ctx context.Context,
+	logger *slog.Logger,
+	admins []string,
+	next func(ctx context.Context) (any, error),
+) (any, error) {
+	cert, ok := peerCertificate(ctx)
+	if !ok || cert.Subject.CommonName == "" {
+		return nil, status.Error(codes.Unauthenticated, "client certificate required")
+	}
+
+	commonName := cert.Subject.CommonName
+	if !slices.Contains(admins, commonName) {
+		logger.InfoContext(ctx, "admin access denied", slog.String("service", commonName))
+		return nil, status.Error(codes.PermissionDenied, "admin access denied")
+	}
+
+	return next(auth.WithService(ctx, commonName))
+}
+
 func authOwner(
 	ctx context.Context,
 	logger *slog.Logger,
 	next func(ctx context.Context) (any, error),
 ) (resp any, err error) {
+	// Клиент, предъявивший сертификат с OwnerID в CN, аутентифицирован как владелец
+	// рабочего пространства из сертификата.
+	// Иначе CN сертификата - имя сервиса, который передаёт OwnerID и TenantID в метаданных (например, grpc-gateway).
+	if cert, ok := peerCertificate(ctx); ok && cert.Subject.CommonName != "" {
+		commonName := cert.Subject.CommonName
+		if _, err := auth.ValidateOwner(commonName); err == nil {
+			ctx, err = authTenant(ctx, logger, certTenant(cert))
+			if err != nil {
+				return nil, err
+			}
+
+			ctx, _ = auth.WithOwnerID(ctx, commonName)
+			return next(ctx)
+		}
+
+		ctx = auth.WithService(ctx, commonName)
+	}
+
+	ctx, err = authTenant(ctx, logger, "")
+	if err != nil {
+		return nil, err
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "no auth data")
@@ -74,3 +158,80 @@ func authOwner(
 
 	return resp, err
 }
+
+// authTenant сохраняет в контексте рабочее пространство.
+// Если клиент привязан к пространству bound (владелец с сертификатом), то используется bound,
+// а другое пространство в метаданных x-tenant-id - ошибка PermissionDenied.
+// Иначе используется пространство из метаданных x-tenant-id, либо model.DefaultTenantID, если оно не указано:
+// метаданным доверяем, т.к. их передаёт сервис с сертификатом или, без mTLS, сам клиент.
+func authTenant(ctx context.Context, logger *slog.Logger, bound string) (context.Context, error) {
+	tenant := string(model.DefaultTenantID)
+	if bound != "" {
+		tenant = bound
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-tenant-id"); len(values) > 0 {
+		if bound != "" && values[0] != bound {
+			logger.InfoContext(ctx, "tenant mismatch", slog.String("tenant", values[0]), slog.String("bound", bound))
+			return nil, status.Error(codes.PermissionDenied, "tenant is not allowed")
+		}
+
+		tenant = values[0]
+	}
+
+	tenantCtx, err := auth.WithTenantID(ctx, tenant)
+	if err != nil {
+		logger.InfoContext(ctx, "invalid tenantID", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Unauthenticated, "invalid tenant token")
+	}
+
+	return tenantCtx, nil
+}
+
+// isPublicMethod сообщает, относится ли метод fullMethod к публичному сервису.
+func isPublicMethod(fullMethod string) bool {
+	for _, service := range publicServices {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isAdminMethod сообщает, относится ли метод fullMethod к административному сервису.
+func isAdminMethod(fullMethod string) bool {
+	for _, service := range adminServices {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// peerCertificate возвращает проверенный клиентский сертификат, если он был предъявлен.
+func peerCertificate(ctx context.Context) (*x509.Certificate, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return tlsInfo.State.VerifiedChains[0][0], true
+}
+
+// certTenant возвращает рабочее пространство владельца из сертификата: первая организация (O) субъекта,
+// либо model.DefaultTenantID, если организация не указана.
+func certTenant(cert *x509.Certificate) string {
+	if len(cert.Subject.Organization) == 0 {
+		return string(model.DefaultTenantID)
+	}
+
+	return cert.Subject.Organization[0]
+}
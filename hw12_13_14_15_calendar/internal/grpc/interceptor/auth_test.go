@@ -0,0 +1,163 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+const (
+	eventMethod = "/event.v1.EventService/GetDayEvents"
+	adminMethod = "/event.v1.TenantAdminService/WipeTenant"
+)
+
+// peerContext возвращает контекст соединения с проверенным клиентским сертификатом subject.
+func peerContext(subject pkix.Name) context.Context {
+	cert := &x509.Certificate{Subject: subject}
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func TestAuth(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	interceptors := Auth(logger, []string{"calendarctl"})
+
+	ownerID := uuid.NewString()
+
+	type result struct {
+		tenantID model.TenantID
+		ownerID  model.OwnerID
+		service  string
+	}
+
+	call := func(ctx context.Context, method string, md ...string) (result, error) {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(md...))
+
+		var res result
+		_, err := interceptors.UnaryInterceptor(
+			ctx,
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, _ any) (any, error) {
+				res.tenantID, _ = auth.TenantIDFromContext(ctx)
+				res.ownerID, _ = auth.OwnerIDFromContext(ctx)
+				res.service, _ = auth.ServiceFromContext(ctx)
+				return nil, nil
+			},
+		)
+
+		return res, err
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		md     []string
+		want   result
+		code   codes.Code
+	}{
+		{
+			name:   "owner without certificate",
+			ctx:    context.Background(),
+			method: eventMethod,
+			md:     []string{"x-owner-id", ownerID, "x-tenant-id", "hr"},
+			want:   result{tenantID: "hr", ownerID: model.OwnerID(ownerID)},
+		},
+		{
+			name:   "owner certificate with tenant",
+			ctx:    peerContext(pkix.Name{CommonName: ownerID, Organization: []string{"hr"}}),
+			method: eventMethod,
+			want:   result{tenantID: "hr", ownerID: model.OwnerID(ownerID)},
+		},
+		{
+			name:   "owner certificate without tenant",
+			ctx:    peerContext(pkix.Name{CommonName: ownerID}),
+			method: eventMethod,
+			want:   result{tenantID: model.DefaultTenantID, ownerID: model.OwnerID(ownerID)},
+		},
+		{
+			name:   "owner certificate with same tenant in metadata",
+			ctx:    peerContext(pkix.Name{CommonName: ownerID, Organization: []string{"hr"}}),
+			method: eventMethod,
+			md:     []string{"x-tenant-id", "hr"},
+			want:   result{tenantID: "hr", ownerID: model.OwnerID(ownerID)},
+		},
+		{
+			name:   "owner certificate with other tenant in metadata",
+			ctx:    peerContext(pkix.Name{CommonName: ownerID, Organization: []string{"hr"}}),
+			method: eventMethod,
+			md:     []string{"x-tenant-id", "sales"},
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "owner certificate without tenant and other tenant in metadata",
+			ctx:    peerContext(pkix.Name{CommonName: ownerID}),
+			method: eventMethod,
+			md:     []string{"x-tenant-id", "sales"},
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "service forwards owner and tenant",
+			ctx:    peerContext(pkix.Name{CommonName: "grpc-gateway"}),
+			method: eventMethod,
+			md:     []string{"x-owner-id", ownerID, "x-tenant-id", "sales"},
+			want:   result{tenantID: "sales", ownerID: model.OwnerID(ownerID), service: "grpc-gateway"},
+		},
+		{
+			name:   "admin service",
+			ctx:    peerContext(pkix.Name{CommonName: "calendarctl"}),
+			method: adminMethod,
+			want:   result{service: "calendarctl"},
+		},
+		{
+			name:   "admin without certificate",
+			ctx:    context.Background(),
+			method: adminMethod,
+			md:     []string{"x-owner-id", ownerID},
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "not admin service",
+			ctx:    peerContext(pkix.Name{CommonName: "grpc-gateway"}),
+			method: adminMethod,
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "owner calls admin service",
+			ctx:    peerContext(pkix.Name{CommonName: ownerID}),
+			method: adminMethod,
+			code:   codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := call(tt.ctx, tt.method, tt.md...)
+			if tt.code != codes.OK {
+				require.Equal(t, tt.code, status.Code(err), "must return error code")
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, res)
+		})
+	}
+}
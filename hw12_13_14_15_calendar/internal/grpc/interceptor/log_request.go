@@ -11,6 +11,8 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/requestid"
 )
 
 type LogRequestServerOptions struct {
@@ -20,6 +22,8 @@ type LogRequestServerOptions struct {
 }
 
 // LogRequest возвращает пару интерсепторов для логирования запросов (аналогично HTTP логгеру).
+// Идентификатор запроса берётся из метаданных x-request-id или генерируется, сохраняется в контексте
+// и возвращается в заголовке ответа.
 // Также возвращает хендлер для неизвестных методов-сервисов (для логирования).
 //
 // NOTE: вообще можно использовать grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging
@@ -30,6 +34,9 @@ func LogRequest(logger *slog.Logger) LogRequestServerOptions {
 
 	opts.UnaryInterceptor = grpc.UnaryServerInterceptor(
 		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+			ctx, id := requestID(ctx)
+			grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, id))
+
 			next := func() (any, error) {
 				return handler(ctx, req)
 			}
@@ -42,11 +49,14 @@ func LogRequest(logger *slog.Logger) LogRequestServerOptions {
 
 	opts.StreamInterceptor = grpc.StreamServerInterceptor(
 		func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, id := requestID(stream.Context())
+			stream.SetHeader(metadata.Pairs(requestid.MetadataKey, id))
+
 			next := func() (any, error) {
-				return nil, handler(srv, stream)
+				return nil, handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
 			}
 
-			_, err := logRequest(stream.Context(), logger, info.FullMethod, next)
+			_, err := logRequest(ctx, logger, info.FullMethod, next)
 
 			return err
 		},
@@ -60,6 +70,20 @@ func LogRequest(logger *slog.Logger) LogRequestServerOptions {
 	return opts
 }
 
+// requestID возвращает контекст с идентификатором запроса из метаданных ctx (или новым) и сам идентификатор.
+func requestID(ctx context.Context) (context.Context, string) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestid.MetadataKey); len(ids) > 0 {
+			id = ids[0]
+		}
+	}
+
+	id = requestid.Ensure(id)
+
+	return requestid.WithID(ctx, id), id
+}
+
 func logRequest(
 	ctx context.Context,
 	logger *slog.Logger,
@@ -90,7 +114,8 @@ func logRequest(
 		statusCode = status.Code(err)
 	}
 
-	logger.Info(
+	logger.InfoContext(
+		ctx,
 		"grpc request completed",
 		slog.String("statusCode", statusCode.String()),
 		slog.String("remoteAddr", remoteAddr),
@@ -0,0 +1,48 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// RequestObserver учитывает выполненные grpc-запросы, см. metrics.GRPC.
+type RequestObserver interface {
+	ObserveRequest(method string, code string, d time.Duration)
+}
+
+type MetricsServerOptions struct {
+	UnaryInterceptor  grpc.UnaryServerInterceptor
+	StreamInterceptor grpc.StreamServerInterceptor
+}
+
+// Metrics возвращает пару интерсепторов для учёта длительности и статус-кодов запросов в observer.
+func Metrics(observer RequestObserver) MetricsServerOptions {
+	opts := MetricsServerOptions{}
+
+	opts.UnaryInterceptor = grpc.UnaryServerInterceptor(
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			startAt := time.Now()
+
+			resp, err := handler(ctx, req)
+			observer.ObserveRequest(info.FullMethod, status.Code(err).String(), time.Since(startAt))
+
+			return resp, err
+		},
+	)
+
+	opts.StreamInterceptor = grpc.StreamServerInterceptor(
+		func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			startAt := time.Now()
+
+			err := handler(srv, stream)
+			observer.ObserveRequest(info.FullMethod, status.Code(err).String(), time.Since(startAt))
+
+			return err
+		},
+	)
+
+	return opts
+}
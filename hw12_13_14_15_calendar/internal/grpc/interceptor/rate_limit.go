@@ -0,0 +1,163 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
+)
+
+// GatewayMetadataKey - ключ метаданных запроса с секретом grpc-gateway, см. GatewayClient.
+const GatewayMetadataKey = "x-calendar-gateway"
+
+type RateLimitServerOptions struct {
+	UnaryInterceptor  grpc.UnaryServerInterceptor
+	StreamInterceptor grpc.StreamServerInterceptor
+}
+
+// RateLimit возвращает пару интерсепторов, ограничивающих частоту запросов через limiter.
+// Запросы учитываются по OwnerID (см. Auth) или по адресу клиента.
+// При превышении ограничения возвращается ошибка ResourceExhausted с google.rpc.RetryInfo
+// и заголовком retry-after.
+//
+// Запросы grpc-gateway (с секретом gatewaySecret в метаданных, см. GatewayClient) не ограничиваются:
+// они уже учтены http-middleware по адресу клиента или X-Owner-ID, а здесь все они пришли бы с адреса шлюза.
+// Пустой gatewaySecret - ограничиваются все запросы.
+func RateLimit(logger *slog.Logger, limiter *ratelimit.Limiter, gatewaySecret string) RateLimitServerOptions {
+	opts := RateLimitServerOptions{}
+
+	allow := func(ctx context.Context, method string) error {
+		if isPublicMethod(method) || isGateway(ctx, gatewaySecret) {
+			return nil
+		}
+
+		key := ratelimit.Key(ctx, peerAddr(ctx))
+
+		ok, retryAfter := limiter.Allow(method, key)
+		if ok {
+			return nil
+		}
+
+		logger.InfoContext(
+			ctx,
+			"rate limit exceeded",
+			slog.String("method", method),
+			slog.String("key", key),
+			slog.Duration("retryAfter", retryAfter),
+		)
+
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", ratelimit.RetryAfter(retryAfter)))
+
+		return rateLimitError(retryAfter)
+	}
+
+	opts.UnaryInterceptor = grpc.UnaryServerInterceptor(
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := allow(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+
+			return handler(ctx, req)
+		},
+	)
+
+	opts.StreamInterceptor = grpc.StreamServerInterceptor(
+		func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := allow(stream.Context(), info.FullMethod); err != nil {
+				return err
+			}
+
+			return handler(srv, stream)
+		},
+	)
+
+	return opts
+}
+
+// GatewayClientOptions - клиентские интерсепторы grpc-gateway.
+type GatewayClientOptions struct {
+	UnaryInterceptor  grpc.UnaryClientInterceptor
+	StreamInterceptor grpc.StreamClientInterceptor
+}
+
+// GatewayClient возвращает пару клиентских интерсепторов grpc-gateway,
+// передающих секрет secret в метаданных запроса (см. RateLimit).
+func GatewayClient(secret string) GatewayClientOptions {
+	opts := GatewayClientOptions{}
+
+	opts.UnaryInterceptor = grpc.UnaryClientInterceptor(
+		func(
+			ctx context.Context,
+			method string,
+			req, reply any,
+			cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker,
+			opts ...grpc.CallOption,
+		) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, GatewayMetadataKey, secret), method, req, reply, cc, opts...)
+		},
+	)
+
+	opts.StreamInterceptor = grpc.StreamClientInterceptor(
+		func(
+			ctx context.Context,
+			desc *grpc.StreamDesc,
+			cc *grpc.ClientConn,
+			method string,
+			streamer grpc.Streamer,
+			opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			return streamer(metadata.AppendToOutgoingContext(ctx, GatewayMetadataKey, secret), desc, cc, method, opts...)
+		},
+	)
+
+	return opts
+}
+
+// isGateway сообщает, что запрос пришёл от grpc-gateway с секретом secret.
+func isGateway(ctx context.Context, secret string) bool {
+	if secret == "" {
+		return false
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	values := md.Get(GatewayMetadataKey)
+
+	return len(values) == 1 && subtle.ConstantTimeCompare([]byte(values[0]), []byte(secret)) == 1
+}
+
+// rateLimitError возвращает ошибку превышения ограничения частоты запросов.
+func rateLimitError(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+
+	stDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return st.Err()
+	}
+
+	return stDetails.Err()
+}
+
+// peerAddr возвращает адрес клиента из контекста запроса.
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	return p.Addr.String()
+}
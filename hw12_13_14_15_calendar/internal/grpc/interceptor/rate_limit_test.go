@@ -0,0 +1,64 @@
+package interceptor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
+)
+
+func TestRateLimit_Gateway(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	limiter := ratelimit.New(clock.Real{}, ratelimit.Rules{Default: ratelimit.Rule{Rate: 0.001, Burst: 1}})
+	interceptors := RateLimit(logger, limiter, "secret")
+
+	// call выполняет запрос с секретом шлюза secret в метаданных (если задан).
+	call := func(secret string) error {
+		ctx := context.Background()
+		if secret != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(GatewayMetadataKey, secret))
+		}
+
+		_, err := interceptors.UnaryInterceptor(
+			ctx,
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: eventMethod},
+			func(context.Context, any) (any, error) { return nil, nil },
+		)
+
+		return err
+	}
+
+	require.NoError(t, call(""), "must allow first request")
+	require.Equal(t, codes.ResourceExhausted, status.Code(call("")), "must limit client")
+	require.Equal(t, codes.ResourceExhausted, status.Code(call("guess")), "must limit client with wrong secret")
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, call("secret"), "must not limit gateway")
+	}
+
+	// клиентский интерсептор шлюза передаёт секрет в метаданных запроса
+	var md metadata.MD
+	err := GatewayClient("secret").UnaryInterceptor(
+		context.Background(),
+		eventMethod,
+		nil,
+		nil,
+		nil,
+		func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"secret"}, md.Get(GatewayMetadataKey), "must pass secret")
+}
@@ -0,0 +1,47 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+type ReadYourWritesServerOptions struct {
+	UnaryInterceptor  grpc.UnaryServerInterceptor
+	StreamInterceptor grpc.StreamServerInterceptor
+}
+
+// ReadYourWrites возвращает пару интерсепторов, включающих для каждого запроса режим read-your-writes:
+// после записи в хранилище последующие чтения в рамках того же запроса выполняются из основной базы.
+func ReadYourWrites() ReadYourWritesServerOptions {
+	opts := ReadYourWritesServerOptions{}
+
+	opts.UnaryInterceptor = grpc.UnaryServerInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(storage.WithReadYourWrites(ctx), req)
+		},
+	)
+
+	opts.StreamInterceptor = grpc.StreamServerInterceptor(
+		func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &contextServerStream{
+				ServerStream: stream,
+				ctx:          storage.WithReadYourWrites(stream.Context()),
+			})
+		},
+	)
+
+	return opts
+}
+
+// contextServerStream - grpc.ServerStream с подменённым контекстом.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,125 @@
+package interceptor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelCodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
+)
+
+type TraceServerOptions struct {
+	UnaryInterceptor  grpc.UnaryServerInterceptor
+	StreamInterceptor grpc.StreamServerInterceptor
+}
+
+// Trace возвращает пару интерсепторов, извлекающих контекст трассировки из метаданных запроса
+// (W3C trace-context) и выполняющих запрос в новом span.
+func Trace() TraceServerOptions {
+	opts := TraceServerOptions{}
+
+	opts.UnaryInterceptor = grpc.UnaryServerInterceptor(
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, span := startServerSpan(ctx, info.FullMethod)
+
+			resp, err := handler(ctx, req)
+			endSpan(span, err)
+
+			return resp, err
+		},
+	)
+
+	opts.StreamInterceptor = grpc.StreamServerInterceptor(
+		func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, span := startServerSpan(stream.Context(), info.FullMethod)
+
+			err := handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+			endSpan(span, err)
+
+			return err
+		},
+	)
+
+	return opts
+}
+
+// TraceClient возвращает клиентский интерсептор, передающий контекст трассировки в метаданных запроса.
+func TraceClient() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if ok {
+			md = md.Copy()
+		} else {
+			md = metadata.MD{}
+		}
+
+		otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+
+		return invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+	}
+}
+
+func startServerSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+
+	return tracing.Tracer().Start(
+		ctx,
+		method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+
+	if err != nil {
+		span.SetStatus(otelCodes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// metadataCarrier - propagation.TextMapCarrier для grpc-метаданных.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
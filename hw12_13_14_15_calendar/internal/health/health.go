@@ -0,0 +1,216 @@
+// health - проверки состояния сервиса: живость (liveness) и готовность (readiness).
+//
+// Проверки отдаются через http (/healthz, /readyz) и синхронизируются со стандартным grpc health-сервисом.
+// При завершении работы сервис переводится в режим drain: готовность начинает возвращать ошибку,
+// чтобы балансировщики перестали направлять на него запросы до остановки серверов.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// ErrDraining - сервис завершает работу и не принимает новые запросы.
+var ErrDraining = errors.New("service is draining")
+
+// DefaultTimeout - время выполнения проверок по умолчанию.
+const DefaultTimeout = 3 * time.Second
+
+// Pinger - компонент, доступность которого возможно проверить (например, хранилище или очередь).
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Check - проверка состояния, возвращает ошибку, если проверка не пройдена.
+type Check func(ctx context.Context) error
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Result - результат проверок.
+type Result struct {
+	Status string `json:"status"`
+
+	// Checks - результаты отдельных проверок: "ok" или текст ошибки.
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// OK сообщает, пройдены ли все проверки.
+func (r Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+type Checker struct {
+	timeout time.Duration
+
+	mx        sync.RWMutex
+	liveness  map[string]Check
+	readiness map[string]Check
+
+	draining atomic.Bool
+}
+
+// NewChecker создаёт Checker, выполняющий проверки с ограничением времени timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout:   timeout,
+		liveness:  map[string]Check{},
+		readiness: map[string]Check{},
+	}
+}
+
+// AddLiveness добавляет проверку живости name: её провал означает, что сервис нужно перезапустить.
+// Проверки живости также входят в проверку готовности.
+func (c *Checker) AddLiveness(name string, check Check) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.liveness[name] = check
+}
+
+// AddReadiness добавляет проверку готовности name (например, доступность хранилища или очереди).
+func (c *Checker) AddReadiness(name string, check Check) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.readiness[name] = check
+}
+
+// Drain переводит сервис в режим завершения работы: проверка готовности перестаёт проходить.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Draining сообщает, находится ли сервис в режиме завершения работы.
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Live выполняет проверки живости.
+func (c *Checker) Live(ctx context.Context) Result {
+	c.mx.RLock()
+	checks := make(map[string]Check, len(c.liveness))
+	for name, check := range c.liveness {
+		checks[name] = check
+	}
+	c.mx.RUnlock()
+
+	return c.run(ctx, checks)
+}
+
+// Ready выполняет проверки живости и готовности.
+// Если сервис завершает работу, проверка не проходит с ошибкой ErrDraining.
+func (c *Checker) Ready(ctx context.Context) Result {
+	c.mx.RLock()
+	checks := make(map[string]Check, len(c.liveness)+len(c.readiness))
+	for name, check := range c.liveness {
+		checks[name] = check
+	}
+	for name, check := range c.readiness {
+		checks[name] = check
+	}
+	c.mx.RUnlock()
+
+	if c.Draining() {
+		checks["drain"] = func(context.Context) error { return ErrDraining }
+	}
+
+	return c.run(ctx, checks)
+}
+
+// run выполняет проверки checks параллельно.
+func (c *Checker) run(ctx context.Context, checks map[string]Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		mx     sync.Mutex
+		wg     sync.WaitGroup
+		result = Result{Status: StatusOK, Checks: make(map[string]string, len(checks))}
+	)
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			status := StatusOK
+			if err := check(ctx); err != nil {
+				status = err.Error()
+			}
+
+			mx.Lock()
+			defer mx.Unlock()
+
+			result.Checks[name] = status
+			if status != StatusOK {
+				result.Status = StatusFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return result
+}
+
+// Register регистрирует в mux обработчики /healthz (живость) и /readyz (готовность).
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.Handle("/healthz", c.handler(c.Live))
+	mux.Handle("/readyz", c.handler(c.Ready))
+}
+
+// handler возвращает http-обработчик, отдающий результат проверок check в JSON.
+// Если проверки не пройдены, возвращается статус 503.
+func (c *Checker) handler(check func(ctx context.Context) Result) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := check(r.Context())
+
+		statusCode := http.StatusOK
+		if !result.OK() {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(statusCode)
+
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// SyncGRPC периодически (раз в interval) выставляет статус сервера grpc health-сервиса по проверке готовности.
+// Завершается при отмене контекста ctx.
+func (c *Checker) SyncGRPC(ctx context.Context, server *grpchealth.Server, interval time.Duration) {
+	update := func() {
+		status := healthpb.HealthCheckResponse_SERVING
+		if !c.Ready(ctx).OK() {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+
+		server.SetServingStatus("", status)
+	}
+
+	update()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			update()
+		}
+	}
+}
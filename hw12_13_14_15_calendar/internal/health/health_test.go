@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func okCheck(context.Context) error {
+	return nil
+}
+
+func failCheck(context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestChecker(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.AddLiveness("app", okCheck)
+	c.AddReadiness("storage", okCheck)
+
+	live := c.Live(context.Background())
+	require.True(t, live.OK(), "must be live")
+	require.Equal(t, map[string]string{"app": StatusOK}, live.Checks, "must run liveness checks only")
+
+	ready := c.Ready(context.Background())
+	require.True(t, ready.OK(), "must be ready")
+	require.Equal(t, map[string]string{"app": StatusOK, "storage": StatusOK}, ready.Checks, "must run all checks")
+
+	c.AddReadiness("queue", failCheck)
+
+	require.True(t, c.Live(context.Background()).OK(), "readiness check must not fail liveness")
+
+	ready = c.Ready(context.Background())
+	require.False(t, ready.OK(), "must not be ready")
+	require.Equal(t, "connection refused", ready.Checks["queue"], "must report check error")
+}
+
+func TestChecker_Drain(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.AddReadiness("storage", okCheck)
+
+	c.Drain()
+	require.True(t, c.Draining(), "must be draining")
+
+	require.True(t, c.Live(context.Background()).OK(), "must be live while draining")
+
+	ready := c.Ready(context.Background())
+	require.False(t, ready.OK(), "must not be ready while draining")
+	require.Equal(t, ErrDraining.Error(), ready.Checks["drain"], "must report draining")
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	c := NewChecker(10 * time.Millisecond)
+	c.AddReadiness("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ready := c.Ready(context.Background())
+	require.False(t, ready.OK(), "slow check must fail")
+	require.Equal(t, context.DeadlineExceeded.Error(), ready.Checks["slow"], "must fail by timeout")
+}
+
+func TestChecker_Handlers(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.AddLiveness("app", okCheck)
+	c.AddReadiness("queue", failCheck)
+
+	mux := http.NewServeMux()
+	c.Register(mux)
+
+	tests := []struct {
+		path       string
+		wantCode   int
+		wantStatus string
+	}{
+		{path: "/healthz", wantCode: http.StatusOK, wantStatus: StatusOK},
+		{path: "/readyz", wantCode: http.StatusServiceUnavailable, wantStatus: StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			require.Equal(t, tt.wantCode, w.Code, "must have expected status code")
+			require.Equal(t, "application/json", w.Header().Get("Content-Type"), "must be json")
+
+			var result Result
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result), "must decode result")
+			require.Equal(t, tt.wantStatus, result.Status, "must have expected status")
+		})
+	}
+}
+
+func TestChecker_SyncGRPC(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.AddReadiness("storage", okCheck)
+
+	server := grpchealth.NewServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.SyncGRPC(ctx, server, 10*time.Millisecond)
+	}()
+
+	servingStatus := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err, "must check status")
+
+		return resp.GetStatus()
+	}
+
+	require.Eventually(t, func() bool {
+		return servingStatus() == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond, "must be serving")
+
+	c.Drain()
+
+	require.Eventually(t, func() bool {
+		return servingStatus() == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 10*time.Millisecond, "must not be serving while draining")
+
+	cancel()
+	<-done
+}
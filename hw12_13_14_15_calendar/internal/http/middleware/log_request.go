@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"time"
 
 	internalhttp "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/requestid"
 )
 
 // statusLogger реализует http.ResponseWriter, сохраняя у себя статус-код ответа.
@@ -29,9 +31,18 @@ func (s *statusLogger) WriteHeader(statusCode int) {
 // LogRequest - Middleware для http.Handler.
 //
 // Добавляет Info-лог в logger информацию об исполненном запросе.
+// Идентификатор запроса берётся из заголовка X-Request-Id или генерируется, сохраняется в контексте
+// и заголовке запроса (для передачи дальше, например в grpc-gateway) и возвращается в заголовке ответа.
+// Секрет в пути запроса скрывается, если перед LogRequest выполняется RedactPath.
 func LogRequest(logger *slog.Logger) internalhttp.Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := requestid.Ensure(r.Header.Get(requestid.Header))
+
+			r = r.WithContext(requestid.WithID(r.Context(), id))
+			r.Header.Set(requestid.Header, id)
+			w.Header().Set(requestid.Header, id)
+
 			nextFn := func() int {
 				sl := &statusLogger{ResponseWriter: w}
 				next.ServeHTTP(sl, r)
@@ -40,10 +51,11 @@ func LogRequest(logger *slog.Logger) internalhttp.Middleware {
 			}
 
 			logRequest(
+				r.Context(),
 				logger,
 				r.RemoteAddr,
 				r.Method,
-				r.URL.Path,
+				requestPath(r),
 				r.URL.RawQuery,
 				r.Proto,
 				r.UserAgent(),
@@ -54,6 +66,7 @@ func LogRequest(logger *slog.Logger) internalhttp.Middleware {
 }
 
 func logRequest(
+	ctx context.Context,
 	logger *slog.Logger,
 	remoteAddr string,
 	method string,
@@ -70,7 +83,8 @@ func logRequest(
 
 	statusCode := next()
 
-	logger.Info(
+	logger.InfoContext(
+		ctx,
 		"request completed",
 		slog.Int("statusCode", statusCode),
 		slog.String("remoteAddr", remoteAddr),
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	internalhttp "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http"
+)
+
+// RequestObserver учитывает выполненные http-запросы, см. metrics.HTTP.
+type RequestObserver interface {
+	ObserveRequest(handler string, method string, statusCode int, d time.Duration)
+}
+
+// Metrics - Middleware для http.Handler.
+//
+// Учитывает в observer длительность и статус-код запроса к обработчику handler.
+func Metrics(observer RequestObserver, handler string) internalhttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			startAt := time.Now()
+
+			sl := &statusLogger{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(sl, r)
+
+			observer.ObserveRequest(handler, r.Method, sl.statusCode, time.Since(startAt))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+	internalhttp "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
+)
+
+// RateLimit - Middleware для http.Handler.
+//
+// Ограничивает частоту запросов к обработчику handler через limiter по OwnerID из заголовка X-Owner-ID
+// или, если заголовка нет (или он некорректен), по адресу клиента.
+// При превышении ограничения отвечает 429 Too Many Requests с заголовком Retry-After.
+func RateLimit(limiter *ratelimit.Limiter, handler string) internalhttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, retryAfter := limiter.Allow(handler, rateLimitKey(r))
+			if !ok {
+				w.Header().Set("Retry-After", ratelimit.RetryAfter(retryAfter))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey возвращает ключ учёта запроса r: OwnerID из заголовка X-Owner-ID, иначе адрес клиента.
+// Владелец в контексте запроса появляется только в grpc, поэтому для http он берётся из заголовка.
+func rateLimitKey(r *http.Request) string {
+	ctx, err := auth.WithOwnerID(r.Context(), r.Header.Get("X-Owner-ID"))
+	if err != nil {
+		ctx = r.Context()
+	}
+
+	return ratelimit.Key(ctx, r.RemoteAddr)
+}
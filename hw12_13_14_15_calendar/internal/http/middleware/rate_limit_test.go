@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.New(clock.Real{}, ratelimit.Rules{Default: ratelimit.Rule{Rate: 0.001, Burst: 1}})
+
+	handler := RateLimit(limiter, "web")(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// все запросы приходят с одного адреса, например, через обратный прокси
+	request := func(owner string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/events", nil)
+		r.RemoteAddr = "10.0.0.1:12345"
+		if owner != "" {
+			r.Header.Set("X-Owner-ID", owner)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	owner1 := string(model.NewOwnerID())
+	owner2 := string(model.NewOwnerID())
+
+	require.Equal(t, http.StatusNoContent, request(owner1).Code, "must allow first owner")
+	require.Equal(t, http.StatusNoContent, request(owner2).Code, "must count second owner separately")
+
+	w := request(owner1)
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "must limit first owner")
+	require.NotEmpty(t, w.Header().Get("Retry-After"), "must set Retry-After")
+
+	require.Equal(t, http.StatusNoContent, request("").Code, "must count requests without owner by address")
+	require.Equal(t, http.StatusTooManyRequests, request("invalid").Code, "must count invalid owner by address")
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	internalhttp "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http"
+)
+
+// redactedPathKey - ключ контекста для пути запроса со скрытым секретом, см. RedactPath.
+type redactedPathKey struct{}
+
+// RedactPath - Middleware для http.Handler.
+//
+// Скрывает в логах и трассировке (LogRequest, Trace) секрет в пути запроса: всё, что следует за prefix
+// до суффикса suffix (например, /v1/feed/{secret}.ics -> /v1/feed/***.ics), заменяется на "***".
+// Сам запрос не изменяется. Должен выполняться до LogRequest и Trace.
+func RedactPath(prefix string, suffix string) internalhttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if !strings.HasPrefix(path, prefix) || len(path) == len(prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			redacted := prefix + "***"
+			if strings.HasSuffix(path[len(prefix):], suffix) {
+				redacted += suffix
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), redactedPathKey{}, redacted)))
+		})
+	}
+}
+
+// requestPath возвращает путь запроса r для логов и трассировки, секрет в пути скрыт RedactPath.
+func requestPath(r *http.Request) string {
+	if path, ok := r.Context().Value(redactedPathKey{}).(string); ok {
+		return path
+	}
+
+	return r.URL.Path
+}
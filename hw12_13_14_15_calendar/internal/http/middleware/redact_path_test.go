@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	internalhttp "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http"
+)
+
+func TestRedactPath(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	logs := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+
+	var handledPath string
+	handler := internalhttp.ApplyMiddlewares(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handledPath = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		}),
+		RedactPath("/v1/feed/", ".ics"),
+		Trace("web"),
+		LogRequest(logger),
+	)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "secret", path: "/v1/feed/s3cr3t.ics", want: "/v1/feed/***.ics"},
+		{name: "secret without suffix", path: "/v1/feed/s3cr3t", want: "/v1/feed/***"},
+		{name: "prefix", path: "/v1/feed/", want: "/v1/feed/"},
+		{name: "other path", path: "/v1/events/s3cr3t", want: "/v1/events/s3cr3t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			exporter.Reset()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path+"?x=1", nil))
+
+			require.Equal(t, http.StatusNoContent, w.Code)
+			require.Equal(t, tt.path, handledPath, "handler must get original path")
+
+			require.Contains(t, logs.String(), `"path":"`+tt.want+`?x=1"`, "must log redacted path")
+			if tt.path != tt.want {
+				require.NotContains(t, logs.String(), "s3cr3t", "secret must not be logged")
+			}
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+
+			var tracedPath string
+			for _, attr := range spans[0].Attributes {
+				if attr.Key == "url.path" {
+					tracedPath = attr.Value.AsString()
+				}
+			}
+			require.Equal(t, tt.want, tracedPath, "must trace redacted path")
+		})
+	}
+}
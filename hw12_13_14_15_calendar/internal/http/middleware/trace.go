@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	internalhttp "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/http"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
+)
+
+// Trace - Middleware для http.Handler.
+//
+// Извлекает контекст трассировки из заголовков запроса (W3C trace-context)
+// и выполняет запрос к обработчику handler в новом span.
+// Секрет в пути запроса скрывается, если перед Trace выполняется RedactPath.
+func Trace(handler string) internalhttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			ctx, span := tracing.Tracer().Start(
+				ctx,
+				r.Method+" "+handler,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", requestPath(r)),
+					attribute.String("user_agent.original", r.UserAgent()),
+				),
+			)
+			defer span.End()
+
+			sl := &statusLogger{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(sl, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", sl.statusCode))
+			if sl.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(sl.statusCode))
+			}
+		})
+	}
+}
@@ -1,5 +1,11 @@
 package web
 
+import (
+	"net/http"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+)
+
 type ErrorResponse struct {
 	Err error
 }
@@ -15,3 +21,52 @@ func (e ErrorResponse) Error() string {
 func (e ErrorResponse) Unwrap() error {
 	return e.Err
 }
+
+// StatusErrorResponse - ошибка с кодом ответа Code, например http.StatusNotFound.
+type StatusErrorResponse struct {
+	Code int
+	Err  error
+}
+
+func (e StatusErrorResponse) Data() (data []byte, contentType string, err error) {
+	return []byte(e.Err.Error()), "text/plain", nil
+}
+
+func (e StatusErrorResponse) StatusCode() int {
+	return e.Code
+}
+
+func (e StatusErrorResponse) Error() string {
+	return e.Err.Error()
+}
+
+func (e StatusErrorResponse) Unwrap() error {
+	return e.Err
+}
+
+// DomainErrorResponse возвращает ответ на ошибку предметной области err (см. domainerr)
+// с http-кодом её вида, как у grpc-gateway. ok=false, если err не является ошибкой предметной области.
+func DomainErrorResponse(err error) (resp StatusErrorResponse, ok bool) {
+	derr, ok := domainerr.From(err)
+	if !ok {
+		return StatusErrorResponse{}, false
+	}
+
+	return StatusErrorResponse{Code: domainStatusCode(derr.Kind), Err: err}, true
+}
+
+// domainStatusCode возвращает http-код ответа для вида ошибки kind.
+func domainStatusCode(kind domainerr.Kind) int {
+	switch kind {
+	case domainerr.KindInvalidArgument, domainerr.KindFailedPrecondition:
+		return http.StatusBadRequest
+	case domainerr.KindNotFound:
+		return http.StatusNotFound
+	case domainerr.KindAlreadyExists:
+		return http.StatusConflict
+	case domainerr.KindResourceExhausted:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 )
 
@@ -15,6 +16,18 @@ type StatusCodeResponder interface {
 	StatusCode() int
 }
 
+// HeaderResponder - ответ с дополнительными заголовками.
+type HeaderResponder interface {
+	Header() http.Header
+}
+
+// StreamResponder - ответ, содержимое которого передаётся клиенту потоком через Stream, а не из Data.
+// Data такого ответа возвращает только тип содержимого.
+// Если ответ реализует io.Closer, он закрывается после обработки.
+type StreamResponder interface {
+	Stream(w io.Writer) error
+}
+
 // HandlerFunc хендлер функция для обработки запросов внутри web.Mux.
 type HandlerFunc func(ctx context.Context, r *http.Request) DataResponder
 
@@ -35,6 +48,10 @@ func (m *Mux) Handler(fn HandlerFunc) http.HandlerFunc {
 // handleResponse обрабатывает результат выполнения web.HandlerFunc
 // и осуществляет непосредственно запись ответа клиенту.
 func handleResponse(ctx context.Context, resp DataResponder, w http.ResponseWriter) error {
+	if closer, ok := resp.(io.Closer); ok {
+		defer closer.Close()
+	}
+
 	if err := ctx.Err(); err != nil {
 		if errors.Is(err, context.Canceled) {
 			return errors.New("client disconnected, do not send response")
@@ -65,9 +82,23 @@ func handleResponse(ctx context.Context, resp DataResponder, w http.ResponseWrit
 		return fmt.Errorf("respose data: %w", err)
 	}
 
+	if v, ok := resp.(HeaderResponder); ok {
+		for key, values := range v.Header() {
+			w.Header()[key] = values
+		}
+	}
+
 	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(statusCode)
 
+	if v, ok := resp.(StreamResponder); ok {
+		if err := v.Stream(w); err != nil {
+			return fmt.Errorf("response stream: %w", err)
+		}
+
+		return nil
+	}
+
 	if _, err := w.Write(data); err != nil {
 		return fmt.Errorf("response write: %w", err)
 	}
@@ -1,10 +1,9 @@
 package web
 
 import (
-	"fmt"
 	"log/slog"
 	"net/http"
-	"net/url"
+	"path"
 )
 
 type RoutesAdder interface {
@@ -17,16 +16,18 @@ type Mux struct {
 	mux    *http.ServeMux
 }
 
-// NewMux создаёт новый web-мультиплексер.
-func NewMux(logger *slog.Logger, routesAdder RoutesAdder) (*Mux, error) {
+// NewMux создаёт новый web-мультиплексер с маршрутами routesAdders.
+func NewMux(logger *slog.Logger, routesAdders ...RoutesAdder) (*Mux, error) {
 	mux := Mux{
 		logger: logger,
 		mux:    http.NewServeMux(),
 	}
 
-	err := routesAdder.AddRoutes(&mux)
-	if err != nil {
-		return nil, err
+	for _, routesAdder := range routesAdders {
+		err := routesAdder.AddRoutes(&mux)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &mux, nil
@@ -37,16 +38,13 @@ func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // Handle создаёт http.Handle для определённого пути и метода запроса.
-func (m *Mux) Handle(method string, version string, path string, handlerFn HandlerFunc) error {
+// Путь может содержать шаблоны http.ServeMux, например {id}.
+func (m *Mux) Handle(method string, version string, pattern string, handlerFn HandlerFunc) error {
 	if version != "" {
-		var err error
-		path, err = url.JoinPath("/", version, path)
-		if err != nil {
-			return fmt.Errorf("url.JoinPath: %w", err)
-		}
+		pattern = path.Join("/", version, pattern)
 	}
 
-	m.mux.Handle(method+" "+path, m.Handler(handlerFn))
+	m.mux.Handle(method+" "+pattern, m.Handler(handlerFn))
 
 	return nil
 }
@@ -0,0 +1,162 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxFoldedLineLength - длина строки содержимого в октетах, после которой строка сворачивается при записи.
+const MaxFoldedLineLength = 75
+
+// Add добавляет компоненту свойство name со значением value как есть.
+func (c *Component) Add(name string, value string) {
+	c.Properties = append(c.Properties, Property{Name: name, Value: value})
+}
+
+// AddText добавляет компоненту текстовое свойство name, экранируя значение text.
+func (c *Component) AddText(name string, text string) {
+	c.Add(name, EscapeText(text))
+}
+
+// Encode записывает компонент c со всеми вложенными компонентами в w (RFC 5545):
+// строки завершаются CRLF, строки длиннее MaxFoldedLineLength октетов сворачиваются.
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+
+	if err := encodeComponent(bw, c); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func encodeComponent(w *bufio.Writer, c *Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+
+	for _, p := range c.Properties {
+		if err := writeLine(w, formatProperty(p)); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range c.Children {
+		if err := encodeComponent(w, child); err != nil {
+			return err
+		}
+	}
+
+	return writeLine(w, "END:"+c.Name)
+}
+
+// formatProperty возвращает строку содержимого NAME;PARAM=VALUE:value, параметры упорядочены по имени.
+func formatProperty(p Property) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		value := p.Params[name]
+
+		b.WriteByte(';')
+		b.WriteString(name)
+		b.WriteByte('=')
+
+		if strings.ContainsAny(value, ";:,") {
+			b.WriteByte('"')
+			b.WriteString(strings.ReplaceAll(value, `"`, "'"))
+			b.WriteByte('"')
+		} else {
+			b.WriteString(value)
+		}
+	}
+
+	b.WriteByte(':')
+	b.WriteString(p.Value)
+
+	return b.String()
+}
+
+// writeLine записывает строку содержимого line, сворачивая её (RFC 5545, 3.1):
+// продолжение начинается с пробела, многобайтовые символы UTF-8 не разрываются.
+func writeLine(w *bufio.Writer, line string) error {
+	limit := MaxFoldedLineLength
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		if _, err := w.WriteString(line[:cut]); err != nil {
+			return err
+		}
+
+		if _, err := w.WriteString("\r\n "); err != nil {
+			return err
+		}
+
+		line = line[cut:]
+
+		// пробел в начале строки продолжения входит в её длину
+		limit = MaxFoldedLineLength - 1
+	}
+
+	if _, err := w.WriteString(line); err != nil {
+		return err
+	}
+
+	_, err := w.WriteString("\r\n")
+
+	return err
+}
+
+// EscapeText экранирует текстовое значение (RFC 5545, 3.3.11): '\', ';', ',' и переводы строк.
+func EscapeText(text string) string {
+	if !strings.ContainsAny(text, "\\;,\r\n") {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text) + 8)
+
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\\', ';', ',':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				continue
+			}
+
+			b.WriteString(`\n`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// FormatDateTime возвращает значение даты и времени t в UTC: YYYYMMDDTHHMMSSZ.
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// FormatDate возвращает значение даты t (VALUE=DATE): YYYYMMDD.
+func FormatDate(t time.Time) string {
+	return t.Format("20060102")
+}
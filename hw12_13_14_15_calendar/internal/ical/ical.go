@@ -0,0 +1,405 @@
+// ical - разбор календарей в формате iCalendar (RFC 5545).
+//
+// Поддерживается подмножество формата, достаточное для обмена событиями: компоненты (BEGIN/END),
+// свойства с параметрами, свёрнутые строки и экранирование текстовых значений.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidLine     = errors.New("invalid content line")
+	ErrUnbalanced      = errors.New("unbalanced BEGIN/END")
+	ErrNoCalendar      = errors.New("no VCALENDAR component")
+	ErrInvalidDate     = errors.New("invalid date value")
+	ErrInvalidDuration = errors.New("invalid duration value")
+)
+
+// MaxLineLength - допустимая длина развёрнутой строки содержимого.
+const MaxLineLength = 64 * 1024
+
+// Property - свойство компонента: NAME;PARAM=VALUE:value.
+type Property struct {
+	Name   string            // имя свойства в верхнем регистре
+	Params map[string]string // параметры, имена в верхнем регистре
+	Value  string            // значение как есть, без снятия экранирования
+}
+
+// Param возвращает значение параметра name или пустую строку.
+func (p Property) Param(name string) string {
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Text возвращает значение текстового свойства без экранирования.
+func (p Property) Text() string {
+	return UnescapeText(p.Value)
+}
+
+// Texts возвращает значения текстового свойства со списком значений через запятую (например, CATEGORIES)
+// без экранирования. Экранированные запятые не разделяют значения.
+func (p Property) Texts() []string {
+	var (
+		values []string
+		start  int
+	)
+
+	for i := 0; i < len(p.Value); i++ {
+		switch p.Value[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, UnescapeText(p.Value[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(values, UnescapeText(p.Value[start:]))
+}
+
+// Component - компонент календаря (VCALENDAR, VEVENT, ...) с вложенными компонентами.
+type Component struct {
+	Name       string // имя компонента в верхнем регистре
+	Properties []Property
+	Children   []*Component
+}
+
+// Prop возвращает первое свойство name компонента.
+func (c *Component) Prop(name string) (Property, bool) {
+	name = strings.ToUpper(name)
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return Property{}, false
+}
+
+// Text возвращает текстовое значение первого свойства name или пустую строку.
+func (c *Component) Text(name string) string {
+	p, ok := c.Prop(name)
+	if !ok {
+		return ""
+	}
+
+	return p.Text()
+}
+
+// Components возвращает вложенные компоненты name.
+func (c *Component) Components(name string) []*Component {
+	name = strings.ToUpper(name)
+
+	var result []*Component
+	for _, child := range c.Children {
+		if child.Name == name {
+			result = append(result, child)
+		}
+	}
+
+	return result
+}
+
+// Parse читает из r календарь и возвращает компонент VCALENDAR.
+func Parse(r io.Reader) (*Component, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		root  *Component
+		stack []*Component
+	)
+
+	for i, line := range lines {
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			c := &Component{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, c)
+			} else if root == nil {
+				root = c
+			} else {
+				return nil, fmt.Errorf("line %d: %w: several top-level components", i+1, ErrUnbalanced)
+			}
+
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("line %d: %w: unexpected END:%s", i+1, ErrUnbalanced, prop.Value)
+			}
+
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: %w: property outside of component", i+1, ErrInvalidLine)
+			}
+
+			c := stack[len(stack)-1]
+			c.Properties = append(c.Properties, prop)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: %s is not closed", ErrUnbalanced, stack[len(stack)-1].Name)
+	}
+
+	if root == nil || root.Name != "VCALENDAR" {
+		return nil, ErrNoCalendar
+	}
+
+	return root, nil
+}
+
+// readLines читает строки содержимого из r, разворачивая свёрнутые строки (RFC 5545, 3.1).
+func readLines(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 4096), MaxLineLength)
+
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("%w: continuation without line", ErrInvalidLine)
+			}
+
+			last := lines[len(lines)-1] + line[1:]
+			if len(last) > MaxLineLength {
+				return nil, fmt.Errorf("%w: line is too long", ErrInvalidLine)
+			}
+
+			lines[len(lines)-1] = last
+
+			continue
+		}
+
+		if line == "" {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("can't read calendar: %w", err)
+	}
+
+	return lines, nil
+}
+
+// parseLine разбирает строку содержимого NAME;PARAM=VALUE;PARAM="VALUE":value.
+func parseLine(line string) (Property, error) {
+	prop := Property{}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return prop, fmt.Errorf("%w: '%s'", ErrInvalidLine, line)
+	}
+
+	prop.Name = strings.ToUpper(line[:i])
+	line = line[i:]
+
+	for line[0] == ';' {
+		line = line[1:]
+
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return prop, fmt.Errorf("%w: invalid parameter of %s", ErrInvalidLine, prop.Name)
+		}
+
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			end := strings.IndexByte(line[1:], '"')
+			if end < 0 {
+				return prop, fmt.Errorf("%w: unterminated quote in %s", ErrInvalidLine, prop.Name)
+			}
+
+			value = line[1 : end+1]
+			line = line[end+2:]
+		} else {
+			end := strings.IndexAny(line, ";:")
+			if end < 0 {
+				return prop, fmt.Errorf("%w: no value of %s", ErrInvalidLine, prop.Name)
+			}
+
+			value = line[:end]
+			line = line[end:]
+		}
+
+		if prop.Params == nil {
+			prop.Params = map[string]string{}
+		}
+		prop.Params[name] = value
+
+		if line == "" {
+			return prop, fmt.Errorf("%w: no value of %s", ErrInvalidLine, prop.Name)
+		}
+	}
+
+	if line[0] != ':' {
+		return prop, fmt.Errorf("%w: no value of %s", ErrInvalidLine, prop.Name)
+	}
+
+	prop.Value = line[1:]
+
+	return prop, nil
+}
+
+// UnescapeText снимает экранирование текстового значения (RFC 5545, 3.3.11).
+func UnescapeText(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	b.Grow(len(value))
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i+1 == len(value) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
+
+// ParseDate разбирает значение даты YYYYMMDD (VALUE=DATE) и возвращает полночь этой даты в UTC.
+func ParseDate(value string) (time.Time, error) {
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: '%s'", ErrInvalidDate, value)
+	}
+
+	return t, nil
+}
+
+// ParseDateTime разбирает значение даты и времени YYYYMMDDTHHMMSS[Z].
+// Время с суффиксом Z - в UTC, без него - в часовом поясе loc (nil - UTC).
+func ParseDateTime(value string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if utc, ok := strings.CutSuffix(value, "Z"); ok {
+		value = utc
+		loc = time.UTC
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: '%s'", ErrInvalidDate, value)
+	}
+
+	return t, nil
+}
+
+// ParseDuration разбирает значение продолжительности (RFC 5545, 3.3.6): [+-]PnW или [+-]P[nD][T[nH][nM][nS]].
+func ParseDuration(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("%w: '%s'", ErrInvalidDuration, value)
+
+	sign := time.Duration(1)
+	rest := value
+
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		sign = -1
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "+"):
+		rest = rest[1:]
+	}
+
+	rest, ok := strings.CutPrefix(rest, "P")
+	if !ok || rest == "" {
+		return 0, invalid
+	}
+
+	var (
+		d      time.Duration
+		inTime bool
+
+		// hasUnit - после P, а затем после T, есть хотя бы одна единица
+		hasUnit bool
+	)
+
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return 0, invalid
+			}
+
+			inTime = true
+			hasUnit = false
+			rest = rest[1:]
+
+			continue
+		}
+
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+
+		if i == 0 || i == len(rest) || i > 9 {
+			return 0, invalid
+		}
+
+		n := time.Duration(0)
+		for _, c := range rest[:i] {
+			n = n*10 + time.Duration(c-'0')
+		}
+
+		var unit time.Duration
+
+		switch {
+		case rest[i] == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case rest[i] == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case rest[i] == 'H' && inTime:
+			unit = time.Hour
+		case rest[i] == 'M' && inTime:
+			unit = time.Minute
+		case rest[i] == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, invalid
+		}
+
+		d += n * unit
+		hasUnit = true
+		rest = rest[i+1:]
+	}
+
+	if !hasUnit {
+		return 0, invalid
+	}
+
+	return sign * d, nil
+}
@@ -0,0 +1,183 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"X-WR-CALNAME:Праздники\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:new-year@example.com\r\n" +
+		"DTSTART;VALUE=DATE:20300101\r\n" +
+		"SUMMARY:New\\, shiny\r\n" +
+		"  year\\nparty\r\n" +
+		"ATTENDEE;CN=\"Doe; John\";ROLE=CHAIR:mailto:john@example.com\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"dtstart:20300102T090000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	root, err := Parse(strings.NewReader(data))
+	require.NoError(t, err, "must parse calendar")
+	require.Equal(t, "Праздники", root.Text("X-WR-CALNAME"))
+
+	vevents := root.Components("VEVENT")
+	require.Len(t, vevents, 2, "must have events")
+
+	start, ok := vevents[0].Prop("DTSTART")
+	require.True(t, ok, "must have DTSTART")
+	require.Equal(t, "DATE", start.Param("value"), "must parse parameter")
+	require.Equal(t, "20300101", start.Value)
+
+	require.Equal(t, "New, shiny year\nparty", vevents[0].Text("SUMMARY"), "must unfold and unescape text")
+
+	attendee, ok := vevents[0].Prop("ATTENDEE")
+	require.True(t, ok, "must have ATTENDEE")
+	require.Equal(t, "Doe; John", attendee.Param("CN"), "must parse quoted parameter")
+	require.Equal(t, "CHAIR", attendee.Param("ROLE"))
+	require.Equal(t, "mailto:john@example.com", attendee.Value, "must keep ':' in value")
+
+	start, ok = vevents[1].Prop("DTSTART")
+	require.True(t, ok, "property names must be case-insensitive")
+	require.Equal(t, "20300102T090000Z", start.Value)
+}
+
+func TestProperty_Texts(t *testing.T) {
+	p := Property{Name: "CATEGORIES", Value: `work,a\,b,,x\\`}
+	require.Equal(t, []string{"work", "a,b", "", `x\`}, p.Texts(), "must split by unescaped commas")
+
+	p = Property{Name: "CATEGORIES", Value: "work"}
+	require.Equal(t, []string{"work"}, p.Texts())
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		err  error
+	}{
+		"not closed":     {"BEGIN:VCALENDAR\nBEGIN:VEVENT\nEND:VCALENDAR\n", ErrUnbalanced},
+		"unexpected end": {"BEGIN:VCALENDAR\nEND:VEVENT\n", ErrUnbalanced},
+		"no calendar":    {"BEGIN:VEVENT\nEND:VEVENT\n", ErrNoCalendar},
+		"empty":          {"", ErrNoCalendar},
+		"no value":       {"BEGIN:VCALENDAR\nSUMMARY\nEND:VCALENDAR\n", ErrInvalidLine},
+		"bad parameter":  {"BEGIN:VCALENDAR\nDTSTART;VALUE:1\nEND:VCALENDAR\n", ErrInvalidLine},
+		"unclosed quote": {"BEGIN:VCALENDAR\nX;CN=\"a:b\nEND:VCALENDAR\n", ErrInvalidLine},
+		"outside":        {"VERSION:2.0\nBEGIN:VCALENDAR\nEND:VCALENDAR\n", ErrInvalidLine},
+		"leading fold":   {" VERSION:2.0\n", ErrInvalidLine},
+		"two calendars":  {"BEGIN:VCALENDAR\nEND:VCALENDAR\nBEGIN:VCALENDAR\nEND:VCALENDAR\n", ErrUnbalanced},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data))
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	d, err := ParseDate("20300228")
+	require.NoError(t, err, "must parse date")
+	require.Equal(t, time.Date(2030, time.February, 28, 0, 0, 0, 0, time.UTC), d)
+
+	for _, value := range []string{"", "2030-02-28", "20300230", "20300228T000000"} {
+		_, err := ParseDate(value)
+		require.ErrorIsf(t, err, ErrInvalidDate, "date '%s' must be invalid", value)
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	d, err := ParseDateTime("20300228T093000Z", moscow)
+	require.NoError(t, err, "must parse date-time")
+	require.Equal(t, time.Date(2030, time.February, 28, 9, 30, 0, 0, time.UTC), d, "must be UTC with Z")
+
+	d, err = ParseDateTime("20300228T093000", moscow)
+	require.NoError(t, err, "must parse local date-time")
+	require.True(t, time.Date(2030, time.February, 28, 6, 30, 0, 0, time.UTC).Equal(d), "must be in location")
+
+	d, err = ParseDateTime("20300228T093000", nil)
+	require.NoError(t, err, "must parse floating date-time")
+	require.Equal(t, time.Date(2030, time.February, 28, 9, 30, 0, 0, time.UTC), d, "must be UTC without location")
+
+	for _, value := range []string{"", "20300228", "20300228T0930", "2030-02-28T09:30:00Z", "20300228T093000ZZ"} {
+		_, err := ParseDateTime(value, nil)
+		require.ErrorIsf(t, err, ErrInvalidDate, "date-time '%s' must be invalid", value)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT15M":      15 * time.Minute,
+		"+PT1H30M":   90 * time.Minute,
+		"-P1D":       -24 * time.Hour,
+		"P2W":        14 * 24 * time.Hour,
+		"P1DT2H3M4S": 26*time.Hour + 3*time.Minute + 4*time.Second,
+	}
+
+	for value, expected := range tests {
+		d, err := ParseDuration(value)
+		require.NoErrorf(t, err, "duration '%s' must be valid", value)
+		require.Equalf(t, expected, d, "duration '%s'", value)
+	}
+
+	for _, value := range []string{"", "P", "PT", "1D", "P1H", "PT1D", "P1DT", "PTT1H", "P1", "P1234567890D"} {
+		_, err := ParseDuration(value)
+		require.ErrorIsf(t, err, ErrInvalidDuration, "duration '%s' must be invalid", value)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	summary := "Встреча; обсуждение, планы\\итоги\r\nвторая строка " + strings.Repeat("длинный текст ", 10)
+
+	vevent := &Component{Name: "VEVENT"}
+	vevent.Add("UID", "event-1")
+	vevent.Add("DTSTART", FormatDateTime(time.Date(2030, time.January, 2, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600))))
+	vevent.AddText("SUMMARY", summary)
+	vevent.Properties = append(vevent.Properties, Property{
+		Name:   "CONFERENCE",
+		Params: map[string]string{"VALUE": "URI", "LABEL": "Room: 1, 2"},
+		Value:  "https://example.com/room",
+	})
+
+	root := &Component{Name: "VCALENDAR", Children: []*Component{vevent}}
+	root.Add("VERSION", "2.0")
+
+	var b strings.Builder
+	require.NoError(t, Encode(&b, root), "must encode calendar")
+
+	data := b.String()
+	require.True(
+		t,
+		strings.HasPrefix(data, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\n"),
+		"must start with calendar",
+	)
+	require.True(t, strings.HasSuffix(data, "END:VEVENT\r\nEND:VCALENDAR\r\n"), "must end with calendar")
+	require.Contains(t, data, "DTSTART:20300102T090000Z\r\n", "must format time in UTC")
+	require.Contains(t, data, `CONFERENCE;LABEL="Room: 1, 2";VALUE=URI:https://example.com/room`, "must quote parameters")
+
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), MaxFoldedLineLength, "line must be folded: '%s'", line)
+		require.True(t, utf8.ValidString(line), "must not split UTF-8 sequences: '%s'", line)
+	}
+
+	parsed, err := Parse(strings.NewReader(data))
+	require.NoError(t, err, "must parse encoded calendar")
+
+	vevents := parsed.Components("VEVENT")
+	require.Len(t, vevents, 1, "must have event")
+	require.Equal(t, strings.ReplaceAll(summary, "\r\n", "\n"), vevents[0].Text("SUMMARY"), "must round-trip text")
+
+	conference, ok := vevents[0].Prop("CONFERENCE")
+	require.True(t, ok, "must have CONFERENCE")
+	require.Equal(t, "Room: 1, 2", conference.Param("LABEL"), "must round-trip parameter")
+}
@@ -0,0 +1,252 @@
+package vevent
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+var (
+	ErrInvalidCalendarData = domainerr.New(
+		domainerr.KindInvalidArgument,
+		"INVALID_CALENDAR_DATA",
+		"invalid calendar data",
+	)
+	ErrRecurrenceNotSupported = domainerr.New(
+		domainerr.KindInvalidArgument,
+		"RECURRENCE_NOT_SUPPORTED",
+		"recurring events are not supported",
+	)
+)
+
+// ToEvent возвращает событие владельца ownerID в рабочем пространстве tenantID
+// из календаря calendar с единственным компонентом VEVENT.
+//
+// Идентификатор события - UID компонента (uuid, приводится к нижнему регистру).
+// Место проведения события берётся из LOCATION (название или, если не помещается, адрес) и GEO,
+// ссылка на видеоконференцию - из CONFERENCE.
+// Категории, которые не являются допустимыми метками, пропускаются. Напоминание VALARM с TRIGGER
+// относительно начала события переводится в NotifyBefore целыми днями.
+// Повторяющиеся события (RRULE, RDATE, RECURRENCE-ID) не поддерживаются: ErrRecurrenceNotSupported.
+func ToEvent(calendar *ical.Component, tenantID model.TenantID, ownerID model.OwnerID) (model.Event, error) {
+	vevents := calendar.Components("VEVENT")
+	if len(vevents) != 1 {
+		for _, c := range vevents {
+			if isRecurrent(c) {
+				return model.Event{}, ErrRecurrenceNotSupported
+			}
+		}
+
+		return model.Event{}, fmt.Errorf("%w: must have exactly one VEVENT, got %d", ErrInvalidCalendarData, len(vevents))
+	}
+
+	c := vevents[0]
+	if isRecurrent(c) {
+		return model.Event{}, ErrRecurrenceNotSupported
+	}
+
+	eventID, err := model.NewIDFromString(strings.ToLower(c.Text("UID")))
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	title, err := model.NewTitle(c.Text("SUMMARY"))
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	startAt, endAt, err := eventPeriod(c)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event, err := model.NewEvent(tenantID, eventID, ownerID, title, startAt, endAt)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Description = c.Text("DESCRIPTION")
+	event.Labels = categoriesLabels(c)
+	event.NotifyBefore = notifyBefore(c)
+
+	event.Location, err = location(c)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	if p, ok := c.Prop("CONFERENCE"); ok {
+		event.ConferenceURL, err = model.NewConferenceURL(p.Value)
+		if err != nil {
+			return model.Event{}, err
+		}
+	}
+
+	return event, nil
+}
+
+func isRecurrent(c *ical.Component) bool {
+	for _, name := range []string{"RRULE", "RDATE", "RECURRENCE-ID"} {
+		if _, ok := c.Prop(name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// eventPeriod возвращает промежуток события [DTSTART, DTEND) или [DTSTART, DTSTART+DURATION).
+// Для события на весь день (DTSTART;VALUE=DATE) без окончания промежуток - сутки (RFC 5545, 3.6.1).
+func eventPeriod(c *ical.Component) (startAt time.Time, endAt time.Time, err error) {
+	start, ok := c.Prop("DTSTART")
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: no DTSTART", ErrInvalidCalendarData)
+	}
+
+	startAt, isDate, err := propTime(start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if end, ok := c.Prop("DTEND"); ok {
+		endAt, _, err = propTime(end)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+
+		return startAt, endAt, nil
+	}
+
+	if duration, ok := c.Prop("DURATION"); ok {
+		d, err := ical.ParseDuration(duration.Value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: %w", ErrInvalidCalendarData, err)
+		}
+
+		return startAt, startAt.Add(d), nil
+	}
+
+	if isDate {
+		return startAt, startAt.AddDate(0, 0, 1), nil
+	}
+
+	return startAt, startAt, nil
+}
+
+// propTime возвращает время свойства p: дату (VALUE=DATE) - полночь в UTC, дату и время - в UTC,
+// с учётом часового пояса TZID. Часовые пояса из VTIMEZONE не разбираются, TZID должен быть
+// идентификатором базы часовых поясов IANA, например Europe/Moscow.
+func propTime(p ical.Property) (t time.Time, isDate bool, err error) {
+	if p.Param("VALUE") == "DATE" || len(p.Value) == len("20060102") {
+		t, err = ical.ParseDate(p.Value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s: %w", ErrInvalidCalendarData, p.Name, err)
+		}
+
+		return t, true, nil
+	}
+
+	var loc *time.Location
+	if tzid := p.Param("TZID"); tzid != "" {
+		loc, err = time.LoadLocation(strings.TrimPrefix(tzid, "/"))
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s: unknown time zone '%s'", ErrInvalidCalendarData, p.Name, tzid)
+		}
+	}
+
+	t, err = ical.ParseDateTime(p.Value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %s: %w", ErrInvalidCalendarData, p.Name, err)
+	}
+
+	return t.UTC(), false, nil
+}
+
+// categoriesLabels возвращает метки события из всех свойств CATEGORIES, недопустимые метки пропускаются.
+func categoriesLabels(c *ical.Component) []model.Label {
+	var labels []string
+
+	for _, p := range c.Properties {
+		if p.Name != "CATEGORIES" {
+			continue
+		}
+
+		for _, category := range p.Texts() {
+			if _, err := model.NewLabel(category); err == nil {
+				labels = append(labels, category)
+			}
+		}
+	}
+
+	result, err := model.NewLabels(labels)
+	if errors.Is(err, model.ErrTooManyLabels) {
+		result, _ = model.NewLabels(labels[:model.MaxEventLabels])
+	}
+
+	return result
+}
+
+// notifyBefore возвращает, за сколько целых дней до начала события срабатывает самое раннее напоминание.
+// Напоминания менее чем за сутки и напоминания, заданные временем (TRIGGER;VALUE=DATE-TIME), не учитываются.
+func notifyBefore(c *ical.Component) uint {
+	var days uint
+
+	for _, alarm := range c.Components("VALARM") {
+		trigger, ok := alarm.Prop("TRIGGER")
+		if !ok || trigger.Param("VALUE") == "DATE-TIME" || trigger.Param("RELATED") == "END" {
+			continue
+		}
+
+		d, err := ical.ParseDuration(trigger.Value)
+		if err != nil || d >= 0 {
+			continue
+		}
+
+		days = max(days, uint(-d/(24*time.Hour)))
+	}
+
+	return days
+}
+
+// location возвращает место проведения события: LOCATION - название места, GEO - координаты.
+func location(c *ical.Component) (model.Location, error) {
+	var point *model.Point
+
+	if geo, ok := c.Prop("GEO"); ok {
+		lat, lon, found := strings.Cut(geo.Value, ";")
+		if !found {
+			return model.Location{}, fmt.Errorf("%w: GEO: '%s'", ErrInvalidCalendarData, geo.Value)
+		}
+
+		latitude, err := strconv.ParseFloat(lat, 64)
+		if err != nil {
+			return model.Location{}, fmt.Errorf("%w: GEO: '%s'", ErrInvalidCalendarData, geo.Value)
+		}
+
+		longitude, err := strconv.ParseFloat(lon, 64)
+		if err != nil {
+			return model.Location{}, fmt.Errorf("%w: GEO: '%s'", ErrInvalidCalendarData, geo.Value)
+		}
+
+		p, err := model.NewPoint(latitude, longitude)
+		if err != nil {
+			return model.Location{}, err
+		}
+
+		point = &p
+	}
+
+	// длинное место, например записанное вместе с адресом (см. LocationText), сохраняется как адрес
+	text := c.Text("LOCATION")
+	if utf8.RuneCountInString(text) > model.MaxLocationNameLen {
+		return model.NewLocation("", text, point)
+	}
+
+	return model.NewLocation(text, "", point)
+}
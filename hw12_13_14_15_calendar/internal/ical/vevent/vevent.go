@@ -0,0 +1,100 @@
+// ical/vevent - представление событий календаря в формате iCalendar (компоненты VEVENT).
+package vevent
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// ProdID - идентификатор продукта, создавшего календарь (свойство PRODID).
+const ProdID = "-//dima-study//calendar//RU"
+
+// NewCalendar возвращает пустой календарь VCALENDAR.
+func NewCalendar() *ical.Component {
+	c := &ical.Component{Name: "VCALENDAR"}
+	c.Add("VERSION", "2.0")
+	c.Add("PRODID", ProdID)
+	c.Add("CALSCALE", "GREGORIAN")
+
+	return c
+}
+
+// FromEvent возвращает компонент VEVENT события event. stamp - время изменения события (свойство DTSTAMP).
+//
+// В компонент попадают ссылки из вложений события, файлы - нет: их содержимое доступно
+// только владельцу. Уведомление о событии (NotifyBefore) передаётся напоминанием VALARM.
+func FromEvent(event model.Event, stamp time.Time) *ical.Component {
+	c := &ical.Component{Name: "VEVENT"}
+
+	c.Add("UID", string(event.EventID()))
+	c.Add("DTSTAMP", ical.FormatDateTime(stamp))
+	c.Add("DTSTART", ical.FormatDateTime(event.StartAt()))
+	c.Add("DTEND", ical.FormatDateTime(event.EndAt()))
+	c.AddText("SUMMARY", string(event.Title))
+
+	if event.Description != "" {
+		c.AddText("DESCRIPTION", event.Description)
+	}
+
+	if location := LocationText(event.Location); location != "" {
+		c.AddText("LOCATION", location)
+	}
+
+	if p := event.Location.Point; p != nil {
+		c.Add("GEO", formatFloat(p.Latitude)+";"+formatFloat(p.Longitude))
+	}
+
+	if event.ConferenceURL != "" {
+		c.Properties = append(c.Properties, ical.Property{
+			Name:   "CONFERENCE",
+			Params: map[string]string{"VALUE": "URI", "FEATURE": "VIDEO"},
+			Value:  string(event.ConferenceURL),
+		})
+	}
+
+	if len(event.Labels) > 0 {
+		categories := make([]string, len(event.Labels))
+		for i, label := range event.Labels {
+			categories[i] = ical.EscapeText(string(label))
+		}
+
+		c.Add("CATEGORIES", strings.Join(categories, ","))
+	}
+
+	for _, attachment := range event.Attachments {
+		if !attachment.IsFile() {
+			c.Add("ATTACH", attachment.URL)
+		}
+	}
+
+	if event.NotifyBefore > 0 {
+		alarm := &ical.Component{Name: "VALARM"}
+		alarm.Add("ACTION", "DISPLAY")
+		alarm.Add("TRIGGER", "-P"+strconv.FormatUint(uint64(event.NotifyBefore), 10)+"D")
+		alarm.AddText("DESCRIPTION", string(event.Title))
+
+		c.Children = append(c.Children, alarm)
+	}
+
+	return c
+}
+
+// LocationText возвращает место проведения события одной строкой: место и адрес через запятую.
+func LocationText(location model.Location) string {
+	switch {
+	case location.Name == "":
+		return location.Address
+	case location.Address == "":
+		return location.Name
+	default:
+		return location.Name + ", " + location.Address
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,193 @@
+package vevent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+func TestFromEvent(t *testing.T) {
+	start := time.Date(2030, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+	event, err := model.NewEvent(
+		model.DefaultTenantID,
+		model.NewID(),
+		model.NewOwnerID(),
+		"Планёрка",
+		start,
+		start.Add(time.Hour),
+	)
+	require.NoError(t, err, "must create event")
+
+	event.Description = "повестка:\nитоги; планы"
+	event.Labels = []model.Label{"meeting", "work"}
+	event.NotifyBefore = 1
+	event.Location = model.Location{
+		Name:    "переговорная 3",
+		Address: "Москва",
+		Point:   &model.Point{Latitude: 55.75, Longitude: 37.6},
+	}
+	event.ConferenceURL = "https://meet.example.com/abc"
+
+	link, err := model.NewLinkAttachment("", "https://example.com/agenda")
+	require.NoError(t, err, "must create link")
+
+	file, err := model.NewFileAttachment("", "agenda.pdf", "application/pdf")
+	require.NoError(t, err, "must create file")
+
+	event.Attachments = []model.Attachment{link, file}
+
+	calendar := NewCalendar()
+	calendar.Children = append(calendar.Children, FromEvent(event, start.Add(-time.Hour)))
+
+	var b strings.Builder
+	require.NoError(t, ical.Encode(&b, calendar), "must encode calendar")
+
+	parsed, err := ical.Parse(strings.NewReader(b.String()))
+	require.NoError(t, err, "must parse calendar")
+
+	vevents := parsed.Components("VEVENT")
+	require.Len(t, vevents, 1, "must have event")
+
+	c := vevents[0]
+	require.Equal(t, string(event.EventID()), c.Text("UID"))
+	require.Equal(t, "20300102T080000Z", c.Text("DTSTAMP"))
+	require.Equal(t, "20300102T090000Z", c.Text("DTSTART"))
+	require.Equal(t, "20300102T100000Z", c.Text("DTEND"))
+	require.Equal(t, "Планёрка", c.Text("SUMMARY"))
+	require.Equal(t, event.Description, c.Text("DESCRIPTION"))
+	require.Equal(t, "переговорная 3, Москва", c.Text("LOCATION"))
+	require.Equal(t, "55.75;37.6", c.Text("GEO"))
+	require.Equal(t, "meeting,work", c.Text("CATEGORIES"))
+	require.Equal(t, "https://example.com/agenda", c.Text("ATTACH"), "must have only links")
+	require.Len(t, c.Components("VALARM"), 1, "must have alarm")
+	require.Equal(t, "-P1D", c.Components("VALARM")[0].Text("TRIGGER"))
+
+	conference, ok := c.Prop("CONFERENCE")
+	require.True(t, ok, "must have CONFERENCE")
+	require.Equal(t, "https://meet.example.com/abc", conference.Value)
+	require.Equal(t, "URI", conference.Param("VALUE"))
+}
+
+func TestToEvent(t *testing.T) {
+	start := time.Date(2030, time.January, 2, 9, 0, 0, 0, time.UTC)
+	ownerID := model.NewOwnerID()
+
+	event, err := model.NewEvent(model.DefaultTenantID, model.NewID(), ownerID, "Планёрка", start, start.Add(time.Hour))
+	require.NoError(t, err, "must create event")
+
+	event.Description = "повестка:\nитоги; планы"
+	event.Labels = []model.Label{"meeting", "work"}
+	event.NotifyBefore = 2
+	event.Location = model.Location{Name: "переговорная 3", Point: &model.Point{Latitude: 55.75, Longitude: 37.6}}
+	event.ConferenceURL = "https://meet.example.com/abc"
+
+	t.Run("round trip", func(t *testing.T) {
+		calendar := NewCalendar()
+		calendar.Children = append(calendar.Children, FromEvent(event, start))
+
+		var b strings.Builder
+		require.NoError(t, ical.Encode(&b, calendar), "must encode calendar")
+
+		parsed, err := ical.Parse(strings.NewReader(b.String()))
+		require.NoError(t, err, "must parse calendar")
+
+		result, err := ToEvent(parsed, model.DefaultTenantID, ownerID)
+		require.NoError(t, err, "must convert calendar")
+		require.Equal(t, event, result, "must keep event")
+	})
+
+	parse := func(t *testing.T, vevent string) (model.Event, error) {
+		t.Helper()
+
+		data := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + vevent + "END:VCALENDAR\r\n"
+
+		calendar, err := ical.Parse(strings.NewReader(data))
+		require.NoError(t, err, "must parse calendar")
+
+		return ToEvent(calendar, model.DefaultTenantID, ownerID)
+	}
+
+	t.Run("client event", func(t *testing.T) {
+		result, err := parse(t, "BEGIN:VEVENT\r\n"+
+			"UID:0B5E8F4C-5C0A-4E7B-9B43-3F0C2A1D7E11\r\n"+
+			"DTSTART;TZID=Europe/Moscow:20300102T120000\r\n"+
+			"DURATION:PT1H30M\r\n"+
+			"SUMMARY:Обед\r\n"+
+			"CATEGORIES:Work,lunch\r\n"+
+			"CATEGORIES:food\r\n"+
+			"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER:-PT15M\r\nEND:VALARM\r\n"+
+			"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER;RELATED=START:-P1DT2H\r\nEND:VALARM\r\n"+
+			"END:VEVENT\r\n")
+		require.NoError(t, err, "must convert calendar")
+
+		require.Equal(t, model.ID("0b5e8f4c-5c0a-4e7b-9b43-3f0c2a1d7e11"), result.EventID(), "must lower UID")
+		require.Equal(t, time.Date(2030, time.January, 2, 9, 0, 0, 0, time.UTC), result.StartAt(), "must convert TZID")
+		require.Equal(t, time.Date(2030, time.January, 2, 10, 30, 0, 0, time.UTC), result.EndAt(), "must add DURATION")
+		require.Equal(t, []model.Label{"food", "lunch"}, result.Labels, "must skip invalid labels")
+		require.Equal(t, uint(1), result.NotifyBefore, "must take whole days of the earliest alarm")
+		require.True(t, result.Location.IsZero(), "must not have location")
+	})
+
+	t.Run("all day", func(t *testing.T) {
+		result, err := parse(t, "BEGIN:VEVENT\r\n"+
+			"UID:"+uuid.NewString()+"\r\n"+
+			"DTSTART;VALUE=DATE:20300102\r\n"+
+			"SUMMARY:Отпуск\r\n"+
+			"END:VEVENT\r\n")
+		require.NoError(t, err, "must convert calendar")
+		require.Equal(t, time.Date(2030, time.January, 2, 0, 0, 0, 0, time.UTC), result.StartAt())
+		require.Equal(t, time.Date(2030, time.January, 3, 0, 0, 0, 0, time.UTC), result.EndAt(), "must last one day")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		uid := "UID:" + uuid.NewString() + "\r\n"
+
+		tests := map[string]struct {
+			vevent string
+			err    error
+		}{
+			"no event": {"", ErrInvalidCalendarData},
+			"recurrence": {
+				"BEGIN:VEVENT\r\n" + uid + "DTSTART:20300102T090000Z\r\nDTEND:20300102T100000Z\r\n" +
+					"SUMMARY:x\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\n",
+				ErrRecurrenceNotSupported,
+			},
+			"several events": {
+				"BEGIN:VEVENT\r\n" + uid + "END:VEVENT\r\nBEGIN:VEVENT\r\n" + uid + "END:VEVENT\r\n",
+				ErrInvalidCalendarData,
+			},
+			"invalid UID": {
+				"BEGIN:VEVENT\r\nUID:event@example.com\r\nDTSTART:20300102T090000Z\r\nSUMMARY:x\r\nEND:VEVENT\r\n",
+				model.ErrInvalidEventID,
+			},
+			"no DTSTART": {"BEGIN:VEVENT\r\n" + uid + "SUMMARY:x\r\nEND:VEVENT\r\n", ErrInvalidCalendarData},
+			"no end": {
+				"BEGIN:VEVENT\r\n" + uid + "DTSTART:20300102T090000Z\r\nSUMMARY:x\r\nEND:VEVENT\r\n",
+				model.ErrTimeEndBeforeStart,
+			},
+			"unknown time zone": {
+				"BEGIN:VEVENT\r\n" + uid + "DTSTART;TZID=Custom:20300102T090000\r\nDURATION:PT1H\r\n" +
+					"SUMMARY:x\r\nEND:VEVENT\r\n",
+				ErrInvalidCalendarData,
+			},
+			"no title": {
+				"BEGIN:VEVENT\r\n" + uid + "DTSTART:20300102T090000Z\r\nDURATION:PT1H\r\nEND:VEVENT\r\n",
+				model.ErrEmptyTitle,
+			},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := parse(t, tt.vevent)
+				require.ErrorIs(t, err, tt.err)
+			})
+		}
+	})
+}
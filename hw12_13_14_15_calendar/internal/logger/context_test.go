@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/requestid"
+)
+
+func TestNew_TraceIDs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _, _ := New(buf, slog.LevelInfo, "test")
+
+	logger.InfoContext(context.Background(), "without span")
+	require.NotContains(t, buf.String(), "traceId", "must not have trace id")
+
+	provider := trace.NewTracerProvider(trace.WithSyncer(tracetest.NewInMemoryExporter()))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+
+	buf.Reset()
+	logger.WithGroup("group").InfoContext(ctx, "with span")
+	require.Contains(t, buf.String(), "traceId="+span.SpanContext().TraceID().String(), "must have trace id")
+	require.Contains(t, buf.String(), "spanId="+span.SpanContext().SpanID().String(), "must have span id")
+}
+
+func TestNew_RequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _, _ := New(buf, slog.LevelInfo, "test")
+
+	logger.InfoContext(context.Background(), "without request id")
+	require.NotContains(t, buf.String(), "requestId", "must not have request id")
+
+	buf.Reset()
+	logger.InfoContext(requestid.WithID(context.Background(), "req_123"), "with request id")
+	require.Contains(t, buf.String(), "requestId=req_123", "must have request id")
+}
+
+func TestNew_Format(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _, formatVar := New(buf, slog.LevelInfo, "test")
+	logger = logger.With(slog.String("comp", "logger"))
+
+	logger.Info("text")
+	require.Contains(t, buf.String(), "msg=text service=test comp=logger", "must be text")
+
+	formatVar.Set(FormatJSON)
+	require.Equal(t, FormatJSON, formatVar.Format(), "must be json format")
+
+	buf.Reset()
+	logger.InfoContext(requestid.WithID(context.Background(), "req_123"), "json")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record), "must be json")
+	require.Equal(t, "json", record["msg"], "must have message")
+	require.Equal(t, "test", record["service"], "must have service")
+	require.Equal(t, "logger", record["comp"], "must have attrs")
+	require.Equal(t, "req_123", record["requestId"], "must have request id")
+}
+
+func TestFormat_UnmarshalText(t *testing.T) {
+	var f Format
+	require.NoError(t, f.UnmarshalText([]byte("json")), "must parse json")
+	require.Equal(t, FormatJSON, f, "must be json")
+
+	require.Error(t, f.UnmarshalText([]byte("xml")), "must fail on unknown format")
+}
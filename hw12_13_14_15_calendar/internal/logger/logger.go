@@ -1,24 +1,117 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"log/slog"
+	"sync/atomic"
 )
 
+// Format - формат вывода логов.
+type Format string
+
+var (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+func (f *Format) UnmarshalText(s []byte) error {
+	switch string(s) {
+	case string(FormatText):
+		*f = FormatText
+	case string(FormatJSON):
+		*f = FormatJSON
+	default:
+		return fmt.Errorf("invalid log format '%s'", s)
+	}
+
+	return nil
+}
+
+// FormatVar - формат вывода логов, который возможно изменить динамически (аналогично slog.LevelVar).
+// Нулевое значение - FormatText.
+type FormatVar struct {
+	json atomic.Bool
+}
+
+// Set устанавливает формат вывода f.
+func (v *FormatVar) Set(f Format) {
+	v.json.Store(f == FormatJSON)
+}
+
+// Format возвращает текущий формат вывода.
+func (v *FormatVar) Format() Format {
+	if v.json.Load() {
+		return FormatJSON
+	}
+
+	return FormatText
+}
+
 // New создаёт новый логгер с заданным уровнем логирования и параметром serviceName.
-// Возвращает сам логгер и levelVar для динамического изменения уровня логирования логгера.
-func New(w io.Writer, level slog.Level, serviceName string) (*slog.Logger, *slog.LevelVar) {
+// В записи, сделанные с контекстом, добавляются идентификатор запроса (requestId), traceId и spanId.
+// Возвращает сам логгер, levelVar и formatVar для динамического изменения уровня логирования
+// и формата вывода (по умолчанию - text) логгера.
+func New(w io.Writer, level slog.Level, serviceName string) (*slog.Logger, *slog.LevelVar, *FormatVar) {
 	levelVar := &slog.LevelVar{}
 	levelVar.Set(level)
 
-	h := slog.NewTextHandler(w, &slog.HandlerOptions{
+	formatVar := &FormatVar{}
+
+	opts := &slog.HandlerOptions{
 		AddSource:   false,
 		Level:       levelVar,
 		ReplaceAttr: nil,
-	})
+	}
 
-	logger := slog.New(h)
+	h := formatHandler{
+		text:   slog.NewTextHandler(w, opts),
+		json:   slog.NewJSONHandler(w, opts),
+		format: formatVar,
+	}
+
+	logger := slog.New(contextHandler{h})
 	logger = logger.With(slog.String("service", serviceName))
 
-	return logger, levelVar
+	return logger, levelVar, formatVar
+}
+
+// formatHandler выводит записи в формате, заданном format.
+type formatHandler struct {
+	text   slog.Handler
+	json   slog.Handler
+	format *FormatVar
+}
+
+func (h formatHandler) current() slog.Handler {
+	if h.format.Format() == FormatJSON {
+		return h.json
+	}
+
+	return h.text
+}
+
+func (h formatHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.current().Enabled(ctx, level)
+}
+
+func (h formatHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.current().Handle(ctx, r)
+}
+
+func (h formatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return formatHandler{
+		text:   h.text.WithAttrs(attrs),
+		json:   h.json.WithAttrs(attrs),
+		format: h.format,
+	}
+}
+
+func (h formatHandler) WithGroup(name string) slog.Handler {
+	return formatHandler{
+		text:   h.text.WithGroup(name),
+		json:   h.json.WithGroup(name),
+		format: h.format,
+	}
 }
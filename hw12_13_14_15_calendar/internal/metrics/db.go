@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// DBPools - хранилище с пулами соединений с базами данных, см. pg.Storage.
+type DBPools interface {
+	// Pools возвращает пулы соединений по их именам.
+	Pools() map[string]*sql.DB
+}
+
+// NewDBStats регистрирует в reg статистику пулов соединений pools (метрики go_sql_*, метка db_name - имя пула).
+func NewDBStats(reg prometheus.Registerer, pools DBPools) error {
+	dbs := pools.Pools()
+
+	cs := make([]prometheus.Collector, 0, len(dbs))
+	for name, db := range dbs {
+		cs = append(cs, collectors.NewDBStatsCollector(db, name))
+	}
+
+	return register(reg, cs...)
+}
@@ -0,0 +1,76 @@
+// metrics - метрики сервисов в формате Prometheus.
+//
+// Каждый набор метрик создаётся конструктором, принимающим prometheus.Registerer,
+// поэтому регистрацию и значения метрик можно проверить в тестах на отдельном реестре без сети.
+package metrics
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace - общий префикс метрик сервисов календаря.
+const namespace = "calendar"
+
+const (
+	resultOK    = "ok"
+	resultError = "error"
+)
+
+// NewRegistry создаёт реестр метрик со стандартными метриками go-runtime и процесса.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return reg
+}
+
+// Handler возвращает http.Handler, отдающий метрики из реестра reg.
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg})
+}
+
+// NewServer создаёт http-сервер, отдающий метрики из реестра reg по адресу host:port/metrics.
+// Дополнительные обработчики (например, проверки состояния) возможно зарегистрировать через routes.
+func NewServer(host string, port string, reg *prometheus.Registry, routes ...func(mux *http.ServeMux)) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(reg))
+
+	for _, route := range routes {
+		route(mux)
+	}
+
+	return &http.Server{
+		Addr:              net.JoinHostPort(host, port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// register регистрирует коллекторы cs в reg.
+func register(reg prometheus.Registerer, cs ...prometheus.Collector) error {
+	errs := make([]error, 0, len(cs))
+	for _, c := range cs {
+		errs = append(errs, reg.Register(c))
+	}
+
+	return errors.Join(errs...)
+}
+
+// result возвращает значение метки result для ошибки err.
+func result(err error) string {
+	if err != nil {
+		return resultError
+	}
+
+	return resultOK
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	reg := NewRegistry()
+
+	_, err := NewGRPC(reg)
+	require.NoError(t, err, "must register grpc metrics")
+
+	_, err = NewHTTP(reg)
+	require.NoError(t, err, "must register http metrics")
+
+	_, err = NewStorage(reg)
+	require.NoError(t, err, "must register storage metrics")
+
+	_, err = NewScheduler(reg)
+	require.NoError(t, err, "must register scheduler metrics")
+
+	_, err = NewQueue(reg)
+	require.NoError(t, err, "must register queue metrics")
+
+	_, err = NewStorage(reg)
+	require.Error(t, err, "must not register storage metrics twice")
+
+	// метрики рассыльщика регистрируются в своём реестре
+	_, err = NewSender(prometheus.NewRegistry())
+	require.NoError(t, err, "must register sender metrics")
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry()
+
+	m, err := NewGRPC(reg)
+	require.NoError(t, err, "must register grpc metrics")
+	m.ObserveRequest("/event.v1.EventService/CreateEvent", "OK", time.Millisecond)
+
+	w := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code, "must be OK")
+	require.Contains(
+		t,
+		w.Body.String(),
+		`calendar_grpc_server_handling_seconds_count{code="OK",method="/event.v1.EventService/CreateEvent"} 1`,
+	)
+	require.Contains(t, w.Body.String(), "go_goroutines", "must have go metrics")
+}
+
+// stubPools - пулы соединений без подключения к базе: sql.Open не подключается до первого запроса.
+type stubPools map[string]*sql.DB
+
+func (p stubPools) Pools() map[string]*sql.DB {
+	return p
+}
+
+func TestDBStats(t *testing.T) {
+	pools := stubPools{}
+	for _, name := range []string{"primary", "replica_0"} {
+		db, err := sql.Open("pgx", "postgresql://localhost/calendar")
+		require.NoError(t, err, "must open db")
+		defer db.Close()
+
+		db.SetMaxOpenConns(4)
+		pools[name] = db
+	}
+
+	reg := NewRegistry()
+	require.NoError(t, NewDBStats(reg, pools), "must register db stats")
+
+	w := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code, "must be OK")
+	require.Contains(t, w.Body.String(), `go_sql_max_open_connections{db_name="primary"} 4`, "must have primary stats")
+	require.Contains(t, w.Body.String(), `go_sql_max_open_connections{db_name="replica_0"} 4`, "must have replica stats")
+	require.Contains(t, w.Body.String(), `go_sql_in_use_connections{db_name="primary"} 0`, "must have pool usage")
+}
+
+func TestObserve(t *testing.T) {
+	t.Run("http", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		m, err := NewHTTP(reg)
+		require.NoError(t, err, "must register")
+
+		m.ObserveRequest("web", http.MethodGet, http.StatusNotFound, time.Millisecond)
+		m.ObserveRequest("web", http.MethodGet, http.StatusNotFound, time.Millisecond)
+
+		require.Equal(t, 1, testutil.CollectAndCount(m.duration), "must have one series")
+	})
+
+	t.Run("storage", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		m, err := NewStorage(reg)
+		require.NoError(t, err, "must register")
+
+		m.ObserveOperation("AddEvent", time.Millisecond, nil)
+		m.ObserveOperation("AddEvent", time.Millisecond, errors.New("failed"))
+
+		require.Equal(t, 2, testutil.CollectAndCount(m.duration), "must have ok and error series")
+	})
+
+	t.Run("scheduler", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		m, err := NewScheduler(reg)
+		require.NoError(t, err, "must register")
+
+		m.NotificationQueued()
+		m.NotificationQueued()
+		m.NotificationFailed()
+		m.ObserveJob("purge", time.Second, nil)
+
+		require.InDelta(t, 2, testutil.ToFloat64(m.notifications.WithLabelValues(notificationQueued)), 0)
+		require.InDelta(t, 1, testutil.ToFloat64(m.notifications.WithLabelValues(notificationFailed)), 0)
+		require.Equal(t, 1, testutil.CollectAndCount(m.jobDuration), "must have job series")
+	})
+
+	t.Run("sender and queue", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		sender, err := NewSender(reg)
+		require.NoError(t, err, "must register")
+		queue, err := NewQueue(reg)
+		require.NoError(t, err, "must register")
+
+		sender.NotificationSent()
+		queue.Reconnected()
+
+		expected := `
+# HELP calendar_queue_reconnects_total Number of reconnects to the notification queue.
+# TYPE calendar_queue_reconnects_total counter
+calendar_queue_reconnects_total 1
+`
+		err = testutil.GatherAndCompare(reg, strings.NewReader(expected), "calendar_queue_reconnects_total")
+		require.NoError(t, err, "must have reconnects")
+		require.InDelta(t, 1, testutil.ToFloat64(sender.notifications.WithLabelValues(notificationSent)), 0)
+	})
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	notificationQueued = "queued"
+	notificationSent   = "sent"
+	notificationFailed = "failed"
+)
+
+func newNotificationsVec() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_total",
+			Help:      "Number of notifications by status: queued, sent, failed.",
+		},
+		[]string{"status"},
+	)
+}
+
+// Scheduler - метрики планировщика уведомлений.
+type Scheduler struct {
+	notifications *prometheus.CounterVec
+	jobDuration   *prometheus.HistogramVec
+}
+
+// NewScheduler создаёт и регистрирует в reg метрики планировщика уведомлений.
+func NewScheduler(reg prometheus.Registerer) (*Scheduler, error) {
+	m := &Scheduler{
+		notifications: newNotificationsVec(),
+		jobDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "scheduler",
+				Name:      "job_duration_seconds",
+				Help:      "Duration of scheduler jobs by job and result.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"job", "result"},
+		),
+	}
+
+	if err := register(reg, m.notifications, m.jobDuration); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ObserveJob учитывает выполненную задачу job длительностью d с ошибкой err.
+func (m *Scheduler) ObserveJob(job string, d time.Duration, err error) {
+	m.jobDuration.WithLabelValues(job, result(err)).Observe(d.Seconds())
+}
+
+// NotificationQueued учитывает уведомление, отправленное в очередь.
+func (m *Scheduler) NotificationQueued() {
+	m.notifications.WithLabelValues(notificationQueued).Inc()
+}
+
+// NotificationFailed учитывает уведомление, которое не удалось отправить в очередь.
+func (m *Scheduler) NotificationFailed() {
+	m.notifications.WithLabelValues(notificationFailed).Inc()
+}
+
+// Sender - метрики рассыльщика уведомлений.
+type Sender struct {
+	notifications *prometheus.CounterVec
+}
+
+// NewSender создаёт и регистрирует в reg метрики рассыльщика уведомлений.
+func NewSender(reg prometheus.Registerer) (*Sender, error) {
+	m := &Sender{
+		notifications: newNotificationsVec(),
+	}
+
+	if err := register(reg, m.notifications); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// NotificationSent учитывает разосланное уведомление.
+func (m *Sender) NotificationSent() {
+	m.notifications.WithLabelValues(notificationSent).Inc()
+}
+
+// NotificationFailed учитывает уведомление, которое не удалось разослать.
+func (m *Sender) NotificationFailed() {
+	m.notifications.WithLabelValues(notificationFailed).Inc()
+}
+
+// Queue - метрики подключения к очереди уведомлений.
+type Queue struct {
+	reconnects prometheus.Counter
+}
+
+// NewQueue создаёт и регистрирует в reg метрики подключения к очереди уведомлений.
+func NewQueue(reg prometheus.Registerer) (*Queue, error) {
+	m := &Queue{
+		reconnects: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "queue",
+				Name:      "reconnects_total",
+				Help:      "Number of reconnects to the notification queue.",
+			},
+		),
+	}
+
+	if err := register(reg, m.reconnects); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Reconnected учитывает переподключение к очереди.
+func (m *Queue) Reconnected() {
+	m.reconnects.Inc()
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// GRPC - метрики grpc-сервера.
+type GRPC struct {
+	handling *prometheus.HistogramVec
+}
+
+// NewGRPC создаёт и регистрирует в reg метрики grpc-сервера.
+func NewGRPC(reg prometheus.Registerer) (*GRPC, error) {
+	m := &GRPC{
+		handling: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "grpc_server",
+				Name:      "handling_seconds",
+				Help:      "Duration of gRPC requests by method and status code.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"method", "code"},
+		),
+	}
+
+	if err := register(reg, m.handling); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ObserveRequest учитывает выполненный grpc-запрос к методу method со статусом code длительностью d.
+func (m *GRPC) ObserveRequest(method string, code string, d time.Duration) {
+	m.handling.WithLabelValues(method, code).Observe(d.Seconds())
+}
+
+// HTTP - метрики http-сервера.
+type HTTP struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewHTTP создаёт и регистрирует в reg метрики http-сервера.
+func NewHTTP(reg prometheus.Registerer) (*HTTP, error) {
+	m := &HTTP{
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "http",
+				Name:      "request_duration_seconds",
+				Help:      "Duration of HTTP requests by handler, method and status code.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"handler", "method", "code"},
+		),
+	}
+
+	if err := register(reg, m.duration); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ObserveRequest учитывает выполненный http-запрос к обработчику handler с методом method
+// и статус-кодом statusCode длительностью d.
+func (m *HTTP) ObserveRequest(handler string, method string, statusCode int, d time.Duration) {
+	m.duration.WithLabelValues(handler, method, strconv.Itoa(statusCode)).Observe(d.Seconds())
+}
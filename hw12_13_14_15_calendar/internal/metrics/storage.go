@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Storage - метрики хранилища событий.
+type Storage struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewStorage создаёт и регистрирует в reg метрики хранилища событий.
+func NewStorage(reg prometheus.Registerer) (*Storage, error) {
+	m := &Storage{
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "storage",
+				Name:      "operation_duration_seconds",
+				Help:      "Duration of event storage operations by operation and result.",
+				Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+			},
+			[]string{"operation", "result"},
+		),
+	}
+
+	if err := register(reg, m.duration); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ObserveOperation учитывает выполненную операцию хранилища operation длительностью d с ошибкой err.
+func (m *Storage) ObserveOperation(operation string, d time.Duration, err error) {
+	m.duration.WithLabelValues(operation, result(err)).Observe(d.Seconds())
+}
@@ -0,0 +1,108 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// versionTable - таблица с версиями применённых миграций, совместимая с goose.
+const versionTable = "goose_db_version"
+
+// Dialect - диалект SQL базы данных.
+type Dialect string
+
+var (
+	DialectPostgres Dialect = "postgres"
+	DialectSqlite   Dialect = "sqlite3"
+)
+
+// dialect - особенности работы с таблицей версий и блокировкой для конкретной базы данных.
+type dialect interface {
+	// createVersionTable - запрос создания таблицы версий, если её нет.
+	createVersionTable() string
+
+	// insertVersion - запрос добавления записи о применении (откате) миграции: версия, признак применения.
+	insertVersion() string
+
+	// deleteVersion - запрос удаления записей о версии.
+	deleteVersion() string
+
+	// lock захватывает блокировку миграций в соединении conn.
+	// Возвращает функцию снятия блокировки.
+	lock(ctx context.Context, conn *sql.Conn) (func() error, error)
+}
+
+func newDialect(d Dialect) (dialect, error) {
+	switch d {
+	case DialectPostgres:
+		return postgresDialect{}, nil
+	case DialectSqlite:
+		return sqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported dialect '%s'", d)
+	}
+}
+
+// postgresLockID - ключ pg_advisory_lock, используемый goose.
+const postgresLockID = int64(5887940537704921958)
+
+type postgresDialect struct{}
+
+func (postgresDialect) createVersionTable() string {
+	return `
+CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+  id          serial     NOT NULL,
+  version_id  bigint     NOT NULL,
+  is_applied  boolean    NOT NULL,
+  tstamp      timestamp      NULL DEFAULT now(),
+
+  PRIMARY KEY(id)
+)`
+}
+
+func (postgresDialect) insertVersion() string {
+	return `INSERT INTO ` + versionTable + ` (version_id, is_applied) VALUES ($1, $2)`
+}
+
+func (postgresDialect) deleteVersion() string {
+	return `DELETE FROM ` + versionTable + ` WHERE version_id = $1`
+}
+
+// lock захватывает сессионную advisory-блокировку: она снимается явно или при закрытии соединения.
+func (postgresDialect) lock(ctx context.Context, conn *sql.Conn) (func() error, error) {
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, postgresLockID); err != nil {
+		return nil, fmt.Errorf("can't acquire migration lock: %w", err)
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, postgresLockID)
+		return err
+	}, nil
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) createVersionTable() string {
+	return `
+CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+  id          integer    PRIMARY KEY AUTOINCREMENT,
+  version_id  integer    NOT NULL,
+  is_applied  integer    NOT NULL,
+  tstamp      timestamp  DEFAULT (datetime('now'))
+)`
+}
+
+func (sqliteDialect) insertVersion() string {
+	return `INSERT INTO ` + versionTable + ` (version_id, is_applied) VALUES (?, ?)`
+}
+
+func (sqliteDialect) deleteVersion() string {
+	return `DELETE FROM ` + versionTable + ` WHERE version_id = ?`
+}
+
+// lock для SQLite не требуется: запись в базу возможна только из одной транзакции,
+// а каждая миграция выполняется в транзакции с повторной проверкой версии.
+func (sqliteDialect) lock(context.Context, *sql.Conn) (func() error, error) {
+	return func() error { return nil }, nil
+}
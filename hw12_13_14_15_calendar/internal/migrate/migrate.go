@@ -0,0 +1,398 @@
+// Package migrate применяет миграции базы данных в формате goose.
+//
+// Версии применённых миграций хранятся в таблице goose_db_version, поэтому базу, мигрированную
+// данным пакетом, можно обслуживать утилитой goose и наоборот.
+//
+// Одновременный запуск миграций из нескольких процессов безопасен: для postgres используется
+// advisory-блокировка, для SQLite - транзакции с блокировкой на запись (база должна быть открыта
+// с параметром _txlock=immediate, как это делает хранилище sqlite). Кроме того, каждая миграция
+// перед применением (откатом) повторно проверяет свою версию в транзакции.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrNoMigrations = errors.New("no migrations")
+	ErrNoApplied    = errors.New("no applied migrations")
+)
+
+// Status - состояние миграции.
+type Status struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator применяет миграции к базе данных.
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+// New создаёт Migrator для базы db с диалектом d и миграциями *.sql из fsys.
+func New(db *sql.DB, d Dialect, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	dialect, err := newDialect(d)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := parseMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(migrations) == 0 {
+		return nil, ErrNoMigrations
+	}
+
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+// Migrations возвращает все известные миграции в порядке возрастания версий.
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+// Up применяет все неприменённые миграции.
+// Возвращает применённые миграции.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			ok, err := m.apply(ctx, conn, migration, true)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				applied = append(applied, migration)
+			}
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down откатывает последнюю применённую миграцию.
+// Возвращает откаченную миграцию.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	var migration Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		var err error
+
+		migration, err = m.last(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		_, err = m.apply(ctx, conn, migration, false)
+
+		return err
+	})
+
+	return migration, err
+}
+
+// Redo откатывает и повторно применяет последнюю применённую миграцию.
+// Возвращает повторно применённую миграцию.
+func (m *Migrator) Redo(ctx context.Context) (Migration, error) {
+	var migration Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		var err error
+
+		migration, err = m.last(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if _, err := m.apply(ctx, conn, migration, false); err != nil {
+			return err
+		}
+
+		_, err = m.apply(ctx, conn, migration, true)
+
+		return err
+	})
+
+	return migration, err
+}
+
+// Status возвращает состояние всех известных миграций.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			appliedAt, applied := versions[migration.Version]
+			statuses = append(statuses, Status{
+				Migration: migration,
+				Applied:   applied,
+				AppliedAt: appliedAt,
+			})
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock выполняет fn в отдельном соединении под блокировкой миграций.
+// Перед вызовом fn создаёт таблицу версий, если её нет.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	unlock, err := m.dialect.lock(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil {
+			err = errors.Join(err, fmt.Errorf("can't release migration lock: %w", unlockErr))
+		}
+	}()
+
+	if err := m.ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// ensureVersionTable создаёт таблицу версий с начальной записью версии 0, как это делает goose.
+func (m *Migrator) ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		if _, err := tx.ExecContext(ctx, m.dialect.createVersionTable()); err != nil {
+			return fmt.Errorf("can't create version table: %w", err)
+		}
+
+		var n int
+		if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM `+versionTable).Scan(&n); err != nil {
+			return err
+		}
+
+		if n == 0 {
+			if _, err := tx.ExecContext(ctx, m.dialect.insertVersion(), 0, true); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}()
+
+	return finishTx(err, tx)
+}
+
+// appliedVersions возвращает применённые версии и время их применения.
+// Состояние версии определяется последней записью о ней в таблице версий.
+func (m *Migrator) appliedVersions(ctx context.Context, q querier) (map[int64]time.Time, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT version_id, is_applied, tstamp FROM `+versionTable+` ORDER BY id DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := map[int64]struct{}{}
+	versions := map[int64]time.Time{}
+
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+			tstamp  sql.NullTime
+		)
+
+		if err := rows.Scan(&version, &applied, &tstamp); err != nil {
+			return nil, err
+		}
+
+		if _, ok := seen[version]; ok || version == 0 {
+			continue
+		}
+		seen[version] = struct{}{}
+
+		if applied {
+			versions[version] = tstamp.Time
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// last возвращает последнюю применённую миграцию.
+func (m *Migrator) last(ctx context.Context, conn *sql.Conn) (Migration, error) {
+	versions, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	var last int64
+	for version := range versions {
+		last = max(last, version)
+	}
+
+	if last == 0 {
+		return Migration{}, ErrNoApplied
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version == last {
+			return migration, nil
+		}
+	}
+
+	return Migration{}, fmt.Errorf("applied migration %d not found", last)
+}
+
+// apply применяет (up=true) или откатывает (up=false) миграцию migration.
+// Возвращает false, если миграция уже была применена (откачена) другим процессом.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) (bool, error) {
+	direction := "up"
+	statements := migration.Up
+	if !up {
+		direction = "down"
+		statements = migration.Down
+	}
+
+	start := time.Now()
+
+	run := func(q querier) (bool, error) {
+		versions, err := m.appliedVersions(ctx, q)
+		if err != nil {
+			return false, err
+		}
+
+		if _, applied := versions[migration.Version]; applied == up {
+			return false, nil
+		}
+
+		for _, stmt := range statements {
+			if _, err := q.ExecContext(ctx, stmt); err != nil {
+				return false, fmt.Errorf("can't migrate %s %s: %w", direction, migration.Name, err)
+			}
+		}
+
+		if up {
+			_, err = q.ExecContext(ctx, m.dialect.insertVersion(), migration.Version, true)
+		} else {
+			_, err = q.ExecContext(ctx, m.dialect.deleteVersion(), migration.Version)
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("can't update version table: %w", err)
+		}
+
+		return true, nil
+	}
+
+	var (
+		ok  bool
+		err error
+	)
+
+	if migration.NoTransaction {
+		ok, err = run(conn)
+	} else {
+		var tx *sql.Tx
+
+		tx, err = conn.BeginTx(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+
+		ok, err = run(tx)
+		err = finishTx(err, tx)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if ok {
+		m.logger.Info(
+			"migrated",
+			slog.String("direction", direction),
+			slog.String("migration", migration.Name),
+			slog.Duration("duration", time.Since(start)),
+		)
+	}
+
+	return ok, nil
+}
+
+// querier - общий интерфейс *sql.Conn и *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// finishTx завершает транзакцию tx:
+//   - откатом, если есть ошибка err
+//   - фиксацией, если ошибка отсутствует.
+func finishTx(err error, tx *sql.Tx) error {
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,328 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite" // регистрация драйвера sqlite в database/sql
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/migrations"
+)
+
+var testMigrations = fstest.MapFS{
+	"1_create_a.sql": {Data: []byte(`
+-- +goose Up
+CREATE TABLE a (id integer);
+INSERT INTO a VALUES (1);
+
+-- +goose Down
+DROP TABLE a;
+`)},
+	"2_create_b.sql": {Data: []byte(`
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE b (id integer);
+CREATE INDEX b_id ON b (id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE b;
+-- +goose StatementEnd
+`)},
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open(
+		"sqlite",
+		filepath.Join(t.TempDir(), "test.db")+"?_txlock=immediate&_pragma=busy_timeout(5000)",
+	)
+	require.NoError(t, err, "must open db")
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func newMigrator(t *testing.T, db *sql.DB) *Migrator {
+	t.Helper()
+
+	m, err := New(db, DialectSqlite, testMigrations, nil)
+	require.NoError(t, err, "must create migrator")
+
+	return m
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var n int
+	err := db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type='table' AND name=?`, name).Scan(&n)
+	require.NoError(t, err, "must query sqlite_master")
+
+	return n > 0
+}
+
+func appliedNames(t *testing.T, m *Migrator) []string {
+	t.Helper()
+
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err, "must get status")
+
+	names := []string{}
+	for _, status := range statuses {
+		if status.Applied {
+			require.False(t, status.AppliedAt.IsZero(), "applied migration must have time")
+			names = append(names, status.Migration.Name)
+		}
+	}
+
+	return names
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	m := newMigrator(t, db)
+
+	t.Run("status before up", func(t *testing.T) {
+		require.Empty(t, appliedNames(t, m), "must have no applied migrations")
+	})
+
+	t.Run("down without applied", func(t *testing.T) {
+		_, err := m.Down(ctx)
+		require.ErrorIs(t, err, ErrNoApplied, "must be ErrNoApplied error")
+	})
+
+	t.Run("up", func(t *testing.T) {
+		applied, err := m.Up(ctx)
+		require.NoError(t, err, "must migrate up")
+		require.Len(t, applied, 2, "must apply all migrations")
+
+		require.True(t, tableExists(t, db, "a"), "table a must exist")
+		require.True(t, tableExists(t, db, "b"), "table b must exist")
+		require.Equal(t, []string{"1_create_a.sql", "2_create_b.sql"}, appliedNames(t, m))
+	})
+
+	t.Run("up again", func(t *testing.T) {
+		applied, err := m.Up(ctx)
+		require.NoError(t, err, "must migrate up")
+		require.Empty(t, applied, "must not apply migrations again")
+	})
+
+	t.Run("down", func(t *testing.T) {
+		migration, err := m.Down(ctx)
+		require.NoError(t, err, "must migrate down")
+		require.Equal(t, int64(2), migration.Version, "must rollback last migration")
+
+		require.True(t, tableExists(t, db, "a"), "table a must exist")
+		require.False(t, tableExists(t, db, "b"), "table b must not exist")
+		require.Equal(t, []string{"1_create_a.sql"}, appliedNames(t, m))
+	})
+
+	t.Run("redo", func(t *testing.T) {
+		migration, err := m.Redo(ctx)
+		require.NoError(t, err, "must redo")
+		require.Equal(t, int64(1), migration.Version, "must redo last migration")
+
+		var n int
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM a`).Scan(&n), "must query table a")
+		require.Equal(t, 1, n, "table a must be recreated")
+		require.Equal(t, []string{"1_create_a.sql"}, appliedNames(t, m))
+	})
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		broken := fstest.MapFS{
+			"1_create_a.sql": testMigrations["1_create_a.sql"],
+			"3_broken.sql": {Data: []byte(`
+-- +goose Up
+CREATE TABLE c (id integer);
+INSERT INTO unknown VALUES (1);
+
+-- +goose Down
+DROP TABLE c;
+`)},
+		}
+
+		bm, err := New(db, DialectSqlite, broken, nil)
+		require.NoError(t, err, "must create migrator")
+
+		_, err = bm.Up(ctx)
+		require.Error(t, err, "must fail")
+		require.False(t, tableExists(t, db, "c"), "table c must not exist")
+		require.Equal(t, []string{"1_create_a.sql"}, appliedNames(t, bm))
+	})
+}
+
+func TestMigrator_Concurrent(t *testing.T) {
+	db := openDB(t)
+
+	const n = 8
+
+	var (
+		wg      sync.WaitGroup
+		errs    = make([]error, n)
+		applied = make([]int, n)
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			m, err := New(db, DialectSqlite, testMigrations, nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			migrations, err := m.Up(context.Background())
+			errs[i] = err
+			applied[i] = len(migrations)
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i], "must migrate up")
+		total += applied[i]
+	}
+
+	require.Equal(t, 2, total, "each migration must be applied once")
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM a`).Scan(&count), "must query table a")
+	require.Equal(t, 1, count, "migration must be applied once")
+}
+
+func TestMigrator_Embedded(t *testing.T) {
+	t.Run("pg", func(t *testing.T) {
+		m, err := New(openDB(t), DialectPostgres, migrations.Pg, nil)
+		require.NoError(t, err, "must parse pg migrations")
+		require.NotEmpty(t, m.Migrations(), "must have pg migrations")
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		db := openDB(t)
+
+		m, err := New(db, DialectSqlite, migrations.Sqlite, nil)
+		require.NoError(t, err, "must parse sqlite migrations")
+
+		_, err = m.Up(context.Background())
+		require.NoError(t, err, "must migrate up")
+		require.True(t, tableExists(t, db, "events"), "table events must exist")
+
+		for range m.Migrations() {
+			_, err := m.Down(context.Background())
+			require.NoError(t, err, "must migrate down")
+		}
+		require.False(t, tableExists(t, db, "events"), "table events must not exist")
+	})
+}
+
+func Test_parseMigration(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Migration
+		wantErr bool
+	}{
+		{
+			name: "statements",
+			data: `
+-- comment
+-- +goose Up
+CREATE TABLE a (id integer);
+-- comment
+INSERT INTO a
+VALUES (1);
+
+-- +goose Down
+DROP TABLE a;
+`,
+			want: Migration{
+				Up:   []string{"CREATE TABLE a (id integer);", "-- comment\nINSERT INTO a\nVALUES (1);"},
+				Down: []string{"DROP TABLE a;"},
+			},
+		},
+		{
+			name: "statement block",
+			data: `
+-- +goose Up
+-- +goose StatementBegin
+CREATE FUNCTION f() RETURNS int AS $$
+BEGIN
+  RETURN 1;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+`,
+			want: Migration{
+				Up: []string{"CREATE FUNCTION f() RETURNS int AS $$\nBEGIN\n  RETURN 1;\nEND;\n$$ LANGUAGE plpgsql;"},
+			},
+		},
+		{
+			name: "no transaction",
+			data: `
+-- +goose NO TRANSACTION
+-- +goose Up
+CREATE INDEX CONCURRENTLY i ON a (id);
+`,
+			want: Migration{
+				Up:            []string{"CREATE INDEX CONCURRENTLY i ON a (id);"},
+				NoTransaction: true,
+			},
+		},
+		{
+			name:    "no up",
+			data:    "-- +goose Down\nDROP TABLE a;\n",
+			wantErr: true,
+		},
+		{
+			name:    "statement outside section",
+			data:    "CREATE TABLE a (id integer);\n-- +goose Up\nSELECT 1;\n",
+			wantErr: true,
+		},
+		{
+			name:    "unclosed block",
+			data:    "-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown annotation",
+			data:    "-- +goose Up\n-- +goose Envsub On\nSELECT 1;\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migration, err := parseMigration(tt.data)
+			if tt.wantErr {
+				require.Error(t, err, "must have error")
+				return
+			}
+
+			require.NoError(t, err, "must not have error")
+			require.Equal(t, tt.want, migration, "must be equal")
+		})
+	}
+}
+
+func Test_parseVersion(t *testing.T) {
+	version, err := parseVersion("20240918122721_create_events_table.sql")
+	require.NoError(t, err, "must parse version")
+	require.Equal(t, int64(20240918122721), version, "proper version")
+
+	for _, name := range []string{"create.sql", "abc_create.sql", "0_create.sql", "-1_create.sql"} {
+		_, err := parseVersion(name)
+		require.Errorf(t, err, "must have error for %s", name)
+	}
+}
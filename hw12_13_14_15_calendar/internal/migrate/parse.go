@@ -0,0 +1,196 @@
+package migrate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Аннотации goose в файлах миграций.
+const (
+	annotationPrefix         = "-- +goose "
+	annotationUp             = "Up"
+	annotationDown           = "Down"
+	annotationStatementBegin = "StatementBegin"
+	annotationStatementEnd   = "StatementEnd"
+	annotationNoTransaction  = "NO TRANSACTION"
+)
+
+// Migration - миграция базы данных.
+type Migration struct {
+	// Version - версия миграции: числовой префикс имени файла (например, 20240918122721).
+	Version int64
+
+	// Name - имя файла миграции.
+	Name string
+
+	// Up и Down - запросы применения и отката миграции.
+	Up   []string
+	Down []string
+
+	// NoTransaction - миграция выполняется вне транзакции.
+	NoTransaction bool
+}
+
+// parseMigrations читает все миграции *.sql из fsys и возвращает их в порядке возрастания версий.
+func parseMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(names))
+	versions := map[int64]string{}
+
+	for _, name := range names {
+		version, err := parseVersion(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, exists := versions[version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d: %s, %s", version, other, name)
+		}
+		versions[version] = name
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, err := parseMigration(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("can't parse migration %s: %w", name, err)
+		}
+
+		migration.Version = version
+		migration.Name = name
+
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// parseVersion возвращает версию миграции по имени файла вида <version>_<description>.sql.
+func parseVersion(name string) (int64, error) {
+	prefix, _, found := strings.Cut(path.Base(name), "_")
+	if !found {
+		return 0, fmt.Errorf("invalid migration name '%s': must be <version>_<description>.sql", name)
+	}
+
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid migration version in '%s'", name)
+	}
+
+	return version, nil
+}
+
+// parseMigration разбирает содержимое файла миграции в формате goose:
+//   - секции "-- +goose Up" и "-- +goose Down";
+//   - запросы внутри секции разделяются ";" в конце строки,
+//     либо заключаются между "-- +goose StatementBegin" и "-- +goose StatementEnd";
+//   - "-- +goose NO TRANSACTION" отключает выполнение миграции в транзакции.
+func parseMigration(data string) (Migration, error) {
+	var (
+		migration Migration
+		section   *[]string
+		inBlock   bool
+		buf       strings.Builder
+	)
+
+	flush := func() {
+		if stmt := strings.TrimSpace(buf.String()); hasStatement(stmt) && section != nil {
+			*section = append(*section, stmt)
+		}
+		buf.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if annotation, ok := strings.CutPrefix(trimmed, annotationPrefix); ok {
+			switch strings.TrimSpace(annotation) {
+			case annotationUp:
+				flush()
+				section = &migration.Up
+			case annotationDown:
+				flush()
+				section = &migration.Down
+			case annotationStatementBegin:
+				flush()
+				inBlock = true
+			case annotationStatementEnd:
+				if !inBlock {
+					return Migration{}, errors.New("StatementEnd without StatementBegin")
+				}
+				inBlock = false
+				flush()
+			case annotationNoTransaction:
+				migration.NoTransaction = true
+			default:
+				return Migration{}, fmt.Errorf("unknown annotation '%s'", trimmed)
+			}
+
+			continue
+		}
+
+		if section == nil {
+			// до секции Up допускаются только комментарии и пустые строки
+			if hasStatement(trimmed) {
+				return Migration{}, errors.New("statement outside of Up/Down section")
+			}
+
+			continue
+		}
+
+		buf.WriteString(line)
+		buf.WriteString("\n")
+
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Migration{}, err
+	}
+
+	if inBlock {
+		return Migration{}, errors.New("StatementBegin without StatementEnd")
+	}
+
+	flush()
+
+	if len(migration.Up) == 0 {
+		return Migration{}, errors.New("no Up statements")
+	}
+
+	return migration, nil
+}
+
+// hasStatement проверяет, что stmt содержит что-то кроме комментариев и пустых строк.
+func hasStatement(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return true
+		}
+	}
+
+	return false
+}
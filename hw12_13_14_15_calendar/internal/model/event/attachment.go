@@ -0,0 +1,141 @@
+package event
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+)
+
+var (
+	ErrInvalidAttachmentID = domainerr.NewField("attachment_id", "INVALID_ATTACHMENT_ID", "invalid attachment ID")
+	ErrMaxAttachmentTitle  = domainerr.NewField("title", "TITLE_TOO_LONG", "attachment title is too long")
+	ErrInvalidURL          = domainerr.NewField("url", "INVALID_URL", "invalid URL, must be absolute http(s) URL")
+	ErrInvalidFileName     = domainerr.NewField("file_name", "INVALID_FILE_NAME", "invalid file name")
+	ErrInvalidContentType  = domainerr.NewField("content_type", "INVALID_CONTENT_TYPE", "invalid content type")
+)
+
+const (
+	MaxAttachmentTitleLen = 128
+	MaxURLLen             = 2048
+	MaxFileNameLen        = 255
+
+	// DefaultContentType - тип содержимого файла, если он не указан.
+	DefaultContentType = "application/octet-stream"
+)
+
+// AttachmentID - uuid строка, идентификатор вложения события.
+type AttachmentID string
+
+// NewAttachmentID возвращает AttachmentID.
+func NewAttachmentID() AttachmentID {
+	return AttachmentID(uuid.NewString())
+}
+
+// NewAttachmentIDFromString проверяет, что строка attachmentID - валидный uuid.
+// Возвращает AttachmentID или ошибку валидации.
+func NewAttachmentIDFromString(attachmentID string) (AttachmentID, error) {
+	if err := uuid.Validate(attachmentID); err != nil {
+		return AttachmentID(""), fmt.Errorf("%w: %w", ErrInvalidAttachmentID, err)
+	}
+
+	return AttachmentID(attachmentID), nil
+}
+
+// Attachment - вложение события: ссылка (URL) или файл.
+// Содержимое файла хранится отдельно от события в хранилище файлов.
+type Attachment struct {
+	ID    AttachmentID // уникальный в рамках события идентификатор вложения
+	Title string       // заголовок вложения
+
+	URL string // адрес ссылки, пусто для файла
+
+	FileName    string // имя файла, пусто для ссылки
+	ContentType string // тип содержимого файла
+	Size        int64  // размер файла в байтах
+}
+
+// NewLinkAttachment создаёт вложение-ссылку на rawURL с заголовком title.
+// Если заголовок не указан, заголовком будет адрес ссылки.
+// Возвращает ошибку валидации (ErrInvalidURL, ErrMaxAttachmentTitle).
+func NewLinkAttachment(title string, rawURL string) (Attachment, error) {
+	if len(rawURL) > MaxURLLen {
+		return Attachment{}, ErrInvalidURL
+	}
+
+	if !isHTTPURL(rawURL) {
+		return Attachment{}, fmt.Errorf("%w: '%s'", ErrInvalidURL, rawURL)
+	}
+
+	if title == "" {
+		title = rawURL
+	}
+
+	if utf8.RuneCountInString(title) > MaxAttachmentTitleLen {
+		return Attachment{}, ErrMaxAttachmentTitle
+	}
+
+	return Attachment{
+		ID:    NewAttachmentID(),
+		Title: title,
+		URL:   rawURL,
+	}, nil
+}
+
+// NewFileAttachment создаёт вложение-файл fileName с заголовком title.
+// Если заголовок не указан, заголовком будет имя файла, если не указан тип содержимого - DefaultContentType.
+// Размер файла известен только после загрузки содержимого и задаётся отдельно.
+// Возвращает ошибку валидации (ErrInvalidFileName, ErrInvalidContentType, ErrMaxAttachmentTitle).
+func NewFileAttachment(title string, fileName string, contentType string) (Attachment, error) {
+	if fileName == "" ||
+		fileName == "." ||
+		fileName == ".." ||
+		utf8.RuneCountInString(fileName) > MaxFileNameLen ||
+		strings.ContainsAny(fileName, "/\\\x00") {
+		return Attachment{}, fmt.Errorf("%w: '%s'", ErrInvalidFileName, fileName)
+	}
+
+	if contentType == "" {
+		contentType = DefaultContentType
+	}
+
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		return Attachment{}, fmt.Errorf("%w: %w", ErrInvalidContentType, err)
+	}
+
+	if title == "" {
+		title = fileName
+	}
+
+	if utf8.RuneCountInString(title) > MaxAttachmentTitleLen {
+		return Attachment{}, ErrMaxAttachmentTitle
+	}
+
+	return Attachment{
+		ID:          NewAttachmentID(),
+		Title:       title,
+		FileName:    fileName,
+		ContentType: contentType,
+	}, nil
+}
+
+// IsFile сообщает, является ли вложение файлом.
+func (a Attachment) IsFile() bool {
+	return a.URL == ""
+}
+
+// isHTTPURL сообщает, что rawURL - абсолютный http(s) адрес не длиннее MaxURLLen.
+func isHTTPURL(rawURL string) bool {
+	if len(rawURL) > MaxURLLen {
+		return false
+	}
+
+	u, err := url.Parse(rawURL)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,44 @@
+package event
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLinkAttachment(t *testing.T) {
+	a, err := NewLinkAttachment("Agenda", "https://docs.example.com/agenda")
+	require.NoError(t, err, "must not have error")
+	require.NotEmpty(t, a.ID, "must have ID")
+	require.Equal(t, "Agenda", a.Title)
+	require.False(t, a.IsFile(), "must be link")
+
+	a, err = NewLinkAttachment("", "http://example.com")
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, "http://example.com", a.Title, "URL must be default title")
+
+	for _, u := range []string{"", "example.com", "ftp://example.com", "https://", "/agenda"} {
+		_, err = NewLinkAttachment("", u)
+		require.ErrorIsf(t, err, ErrInvalidURL, "URL '%s' must be invalid", u)
+	}
+
+	_, err = NewLinkAttachment(strings.Repeat("x", MaxAttachmentTitleLen+1), "https://example.com")
+	require.ErrorIs(t, err, ErrMaxAttachmentTitle, "must be ErrMaxAttachmentTitle error")
+}
+
+func TestNewFileAttachment(t *testing.T) {
+	a, err := NewFileAttachment("", "agenda.txt", "")
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, "agenda.txt", a.Title, "file name must be default title")
+	require.Equal(t, DefaultContentType, a.ContentType, "must have default content type")
+	require.True(t, a.IsFile(), "must be file")
+
+	for _, name := range []string{"", ".", "..", "a/b", "a\\b", strings.Repeat("x", MaxFileNameLen+1)} {
+		_, err = NewFileAttachment("", name, "")
+		require.ErrorIsf(t, err, ErrInvalidFileName, "file name '%s' must be invalid", name)
+	}
+
+	_, err = NewFileAttachment("", "agenda.txt", "text plain")
+	require.ErrorIs(t, err, ErrInvalidContentType, "must be ErrInvalidContentType error")
+}
@@ -1,19 +1,22 @@
 package event
 
 import (
-	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
 )
 
 var (
-	ErrInvalidEventID     = errors.New("invalid event ID")
-	ErrInvalidOwnerID     = errors.New("invalid owner ID")
-	ErrEmptyTitle         = errors.New("empty title")
-	ErrMaxTitleLen        = errors.New("title is too long")
-	ErrTimeEndBeforeStart = errors.New("the EndAt is before StartAt")
+	ErrInvalidEventID     = domainerr.NewField("event_id", "INVALID_EVENT_ID", "invalid event ID")
+	ErrInvalidOwnerID     = domainerr.NewField("owner_id", "INVALID_OWNER_ID", "invalid owner ID")
+	ErrInvalidTenantID    = domainerr.NewField("tenant_id", "INVALID_TENANT_ID", "invalid tenant ID")
+	ErrEmptyTitle         = domainerr.NewField("title", "EMPTY_TITLE", "empty title")
+	ErrMaxTitleLen        = domainerr.NewField("title", "TITLE_TOO_LONG", "title is too long")
+	ErrTimeEndBeforeStart = domainerr.NewField("end_at", "END_BEFORE_START", "the EndAt is before StartAt")
 )
 
 // ID - uuid строка, идентификатор события.
@@ -52,6 +55,31 @@ func NewOwnerIDFromString(ownerID string) (OwnerID, error) {
 	return OwnerID(ownerID), nil
 }
 
+// TenantID - идентификатор рабочего пространства (тенанта), в котором находятся события владельцев.
+//   - строчные латинские буквы, цифры, '-' и '_'
+//   - начинается с буквы или цифры
+//   - не превышает MaxTenantIDLen
+type TenantID string
+
+const (
+	// DefaultTenantID - рабочее пространство для клиентов, не указавших пространство явно.
+	DefaultTenantID = TenantID("default")
+
+	MaxTenantIDLen = 63
+)
+
+var tenantIDRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// NewTenantIDFromString проверяет, что строка tenantID - валидный идентификатор рабочего пространства.
+// Возвращает TenantID или ошибку валидации.
+func NewTenantIDFromString(tenantID string) (TenantID, error) {
+	if len(tenantID) > MaxTenantIDLen || !tenantIDRe.MatchString(tenantID) {
+		return TenantID(""), fmt.Errorf("%w: '%s'", ErrInvalidTenantID, tenantID)
+	}
+
+	return TenantID(tenantID), nil
+}
+
 // Title - заголовок события.
 //   - не пустая строка
 //   - не превышает MaxEventTitleLen
@@ -74,27 +102,43 @@ func NewTitle(title string) (Title, error) {
 
 // Event - событие, которое запланировано в промежуток [startAt, endAt).
 type Event struct {
-	eventID ID      // уникальный идентификатор события
-	ownerID OwnerID // уникальный идентификатор пользователя, для которого назначено событие
+	tenantID TenantID // рабочее пространство, в котором находится событие
+	eventID  ID       // уникальный идентификатор события
+	ownerID  OwnerID  // уникальный идентификатор пользователя, для которого назначено событие
 
 	startAt time.Time // дата и время события
 	endAt   time.Time // дата и время окончания события
 
-	Title        Title  // заголовок
-	Description  string // описание события, опционально
-	NotifyBefore uint   // за сколько дней уведомлять о событии, 0 - не уведомлять
+	Title        Title   // заголовок
+	Description  string  // описание события, опционально
+	NotifyBefore uint    // за сколько дней уведомлять о событии, 0 - не уведомлять
+	Labels       []Label // метки из каталога владельца без повторов, упорядочены по имени (см. NewLabels)
+	Color        Color   // цвет события, опционально
+
+	Location      Location      // место проведения события, опционально
+	ConferenceURL ConferenceURL // ссылка на видеоконференцию, опционально
+
+	Attachments []Attachment // вложения события: ссылки и файлы
 }
 
 // NewEvent создаёт экземпляр нового события исходя из переданных параметров.
 // В случае ошибки валидации параметров будет возвращена соответствующая ошибка.
-func NewEvent(eventID ID, ownerID OwnerID, title Title, startAt time.Time, endAt time.Time) (Event, error) {
+func NewEvent(
+	tenantID TenantID,
+	eventID ID,
+	ownerID OwnerID,
+	title Title,
+	startAt time.Time,
+	endAt time.Time,
+) (Event, error) {
 	if err := validateTime(startAt, endAt); err != nil {
 		return Event{}, err
 	}
 
 	return Event{
-		eventID: eventID,
-		ownerID: ownerID,
+		tenantID: tenantID,
+		eventID:  eventID,
+		ownerID:  ownerID,
 
 		startAt: startAt,
 		endAt:   endAt,
@@ -103,6 +147,10 @@ func NewEvent(eventID ID, ownerID OwnerID, title Title, startAt time.Time, endAt
 	}, nil
 }
 
+func (e *Event) TenantID() TenantID {
+	return e.tenantID
+}
+
 func (e *Event) EventID() ID {
 	return e.eventID
 }
@@ -20,11 +20,12 @@ func mkEventTitle(t *testing.T, title string) Title {
 
 func TestNewEvent(t *testing.T) {
 	type args struct {
-		eventID ID
-		ownerID OwnerID
-		title   Title
-		startAt time.Time
-		endAt   time.Time
+		tenantID TenantID
+		eventID  ID
+		ownerID  OwnerID
+		title    Title
+		startAt  time.Time
+		endAt    time.Time
 	}
 
 	now := time.Now()
@@ -36,33 +37,36 @@ func TestNewEvent(t *testing.T) {
 	}{
 		{
 			args: args{
-				eventID: NewID(),
-				ownerID: NewOwnerID(),
-				title:   mkEventTitle(t, "ok"),
-				startAt: now,
-				endAt:   now.Add(time.Second),
+				tenantID: DefaultTenantID,
+				eventID:  NewID(),
+				ownerID:  NewOwnerID(),
+				title:    mkEventTitle(t, "ok"),
+				startAt:  now,
+				endAt:    now.Add(time.Second),
 			},
 			err: nil,
 		},
 		{
 			name: "startAt = endAt",
 			args: args{
-				eventID: NewID(),
-				ownerID: NewOwnerID(),
-				title:   mkEventTitle(t, "ok"),
-				startAt: now,
-				endAt:   now,
+				tenantID: DefaultTenantID,
+				eventID:  NewID(),
+				ownerID:  NewOwnerID(),
+				title:    mkEventTitle(t, "ok"),
+				startAt:  now,
+				endAt:    now,
 			},
 			err: ErrTimeEndBeforeStart,
 		},
 		{
 			name: "endAt before startAt",
 			args: args{
-				eventID: NewID(),
-				ownerID: NewOwnerID(),
-				title:   mkEventTitle(t, "ok"),
-				startAt: now.Add(time.Second),
-				endAt:   now,
+				tenantID: DefaultTenantID,
+				eventID:  NewID(),
+				ownerID:  NewOwnerID(),
+				title:    mkEventTitle(t, "ok"),
+				startAt:  now.Add(time.Second),
+				endAt:    now,
 			},
 			err: ErrTimeEndBeforeStart,
 		},
@@ -73,11 +77,19 @@ func TestNewEvent(t *testing.T) {
 			testName = string(tt.args.title)
 		}
 		t.Run(testName, func(t *testing.T) {
-			got, err := NewEvent(tt.args.eventID, tt.args.ownerID, tt.args.title, tt.args.startAt, tt.args.endAt)
+			got, err := NewEvent(
+				tt.args.tenantID,
+				tt.args.eventID,
+				tt.args.ownerID,
+				tt.args.title,
+				tt.args.startAt,
+				tt.args.endAt,
+			)
 
 			if tt.err == nil {
 				require.NoError(t, err, "must not have error")
 
+				require.Equal(t, tt.args.tenantID, got.TenantID(), "TenantID must equal to tenantID")
 				require.Equal(t, tt.args.eventID, got.EventID(), "EventID must equal to ID")
 				require.Equal(t, tt.args.ownerID, got.OwnerID(), "UserID must equal to ownerID")
 
@@ -109,6 +121,18 @@ func TestNewOwnerIDFromString(t *testing.T) {
 	require.ErrorIs(t, err, ErrInvalidOwnerID, "must be ErrInvalidOwnerID error")
 }
 
+func TestNewTenantIDFromString(t *testing.T) {
+	for _, tenantID := range []string{"default", "hr", "dept-42", "sales_eu", strings.Repeat("x", MaxTenantIDLen)} {
+		_, err := NewTenantIDFromString(tenantID)
+		require.NoErrorf(t, err, "tenant id '%s' must be valid", tenantID)
+	}
+
+	for _, tenantID := range []string{"", "HR", "-hr", "hr/eu", "hr eu", strings.Repeat("x", MaxTenantIDLen+1)} {
+		_, err := NewTenantIDFromString(tenantID)
+		require.ErrorIsf(t, err, ErrInvalidTenantID, "tenant id '%s' must be invalid", tenantID)
+	}
+}
+
 func TestNewEventTitle(t *testing.T) {
 	_, err := NewTitle(strings.Repeat("x", MaxEventTitleLen))
 	require.NoError(t, err, "must not have error")
@@ -0,0 +1,140 @@
+package event
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+)
+
+var (
+	ErrInvalidFeedTokenID = domainerr.NewField("token_id", "INVALID_FEED_TOKEN_ID", "invalid feed token ID")
+	ErrMaxFeedTokenName   = domainerr.NewField("name", "NAME_TOO_LONG", "feed token name is too long")
+	ErrInvalidFeedSecret  = domainerr.New(domainerr.KindNotFound, "FEED_NOT_FOUND", "feed not found")
+	ErrTooManyFeedTokens  = domainerr.New(
+		domainerr.KindResourceExhausted,
+		"TOO_MANY_FEED_TOKENS",
+		"too many feed tokens",
+	)
+)
+
+const (
+	MaxFeedTokenNameLen = 64
+
+	// MaxFeedTokens - сколько ссылок на календарь может создать владелец.
+	MaxFeedTokens = 16
+
+	// feedSecretSize - размер секрета ссылки на календарь в байтах.
+	feedSecretSize = 32
+)
+
+// FeedTokenID - uuid строка, идентификатор ссылки на календарь.
+type FeedTokenID string
+
+// NewFeedTokenID возвращает FeedTokenID.
+func NewFeedTokenID() FeedTokenID {
+	return FeedTokenID(uuid.NewString())
+}
+
+// NewFeedTokenIDFromString проверяет, что строка tokenID - валидный uuid.
+// Возвращает FeedTokenID или ошибку валидации.
+func NewFeedTokenIDFromString(tokenID string) (FeedTokenID, error) {
+	if err := uuid.Validate(tokenID); err != nil {
+		return FeedTokenID(""), fmt.Errorf("%w: %w", ErrInvalidFeedTokenID, err)
+	}
+
+	return FeedTokenID(tokenID), nil
+}
+
+// FeedSecret - секрет ссылки на календарь: случайные байты в base64url без выравнивания.
+// Секрет передаётся владельцу один раз при создании ссылки и не хранится, хранится только его хеш.
+type FeedSecret string
+
+var feedSecretRe = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+// NewFeedSecret возвращает новый случайный секрет ссылки на календарь.
+func NewFeedSecret() (FeedSecret, error) {
+	b := make([]byte, feedSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return FeedSecret(""), fmt.Errorf("can't generate feed secret: %w", err)
+	}
+
+	return FeedSecret(base64.RawURLEncoding.EncodeToString(b)), nil
+}
+
+// NewFeedSecretFromString проверяет формат секрета secret.
+// Возвращает FeedSecret или ErrInvalidFeedSecret: неверный секрет неотличим от неизвестного.
+func NewFeedSecretFromString(secret string) (FeedSecret, error) {
+	if !feedSecretRe.MatchString(secret) {
+		return FeedSecret(""), ErrInvalidFeedSecret
+	}
+
+	return FeedSecret(secret), nil
+}
+
+// Hash возвращает хеш секрета, по которому ссылка на календарь находится в хранилище.
+func (s FeedSecret) Hash() FeedTokenHash {
+	sum := sha256.Sum256([]byte(s))
+
+	return FeedTokenHash(hex.EncodeToString(sum[:]))
+}
+
+// FeedTokenHash - хеш sha256 секрета ссылки на календарь в hex.
+type FeedTokenHash string
+
+// NewFeedTokenName проверяет название ссылки на календарь name, например "телефон".
+// Возвращает название или ошибку валидации ErrMaxFeedTokenName.
+func NewFeedTokenName(name string) (string, error) {
+	if utf8.RuneCountInString(name) > MaxFeedTokenNameLen {
+		return "", ErrMaxFeedTokenName
+	}
+
+	return name, nil
+}
+
+// FeedToken - ссылка на календарь владельца для подписки (webcal): доступ на чтение
+// к событиям владельца по секрету без аутентификации. Хранится хеш секрета.
+type FeedToken struct {
+	tenantID TenantID      // рабочее пространство владельца
+	ownerID  OwnerID       // владелец календаря
+	tokenID  FeedTokenID   // уникальный идентификатор ссылки
+	hash     FeedTokenHash // хеш секрета ссылки, уникален
+
+	Name      string    // название ссылки, опционально
+	CreatedAt time.Time // время создания ссылки
+}
+
+// NewFeedToken создаёт ссылку tokenID на календарь владельца ownerID в рабочем пространстве tenantID
+// с хешем секрета hash.
+func NewFeedToken(tenantID TenantID, ownerID OwnerID, tokenID FeedTokenID, hash FeedTokenHash) FeedToken {
+	return FeedToken{
+		tenantID: tenantID,
+		ownerID:  ownerID,
+		tokenID:  tokenID,
+		hash:     hash,
+	}
+}
+
+func (t *FeedToken) TenantID() TenantID {
+	return t.tenantID
+}
+
+func (t *FeedToken) OwnerID() OwnerID {
+	return t.ownerID
+}
+
+func (t *FeedToken) TokenID() FeedTokenID {
+	return t.tokenID
+}
+
+func (t *FeedToken) Hash() FeedTokenHash {
+	return t.hash
+}
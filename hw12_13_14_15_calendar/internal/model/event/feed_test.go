@@ -0,0 +1,53 @@
+package event
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFeedSecret(t *testing.T) {
+	secret, err := NewFeedSecret()
+	require.NoError(t, err, "must generate secret")
+
+	parsed, err := NewFeedSecretFromString(string(secret))
+	require.NoError(t, err, "generated secret must be valid")
+	require.Equal(t, secret, parsed, "must keep secret")
+
+	other, err := NewFeedSecret()
+	require.NoError(t, err, "must generate secret")
+	require.NotEqual(t, secret, other, "secrets must be random")
+
+	require.Equal(t, secret.Hash(), parsed.Hash(), "hash must be stable")
+	require.NotEqual(t, secret.Hash(), other.Hash(), "hashes of different secrets must differ")
+	require.Len(t, string(secret.Hash()), 64, "hash must be hex sha256")
+	require.NotContains(t, string(secret.Hash()), string(secret), "hash must not contain secret")
+
+	secrets := []string{"", "short", strings.Repeat("a", 42), strings.Repeat("a", 44), strings.Repeat("+", 43)}
+	for _, invalid := range secrets {
+		_, err := NewFeedSecretFromString(invalid)
+		require.ErrorIsf(t, err, ErrInvalidFeedSecret, "secret '%s' must be invalid", invalid)
+	}
+}
+
+func TestNewFeedTokenName(t *testing.T) {
+	for _, name := range []string{"", "телефон", strings.Repeat("я", MaxFeedTokenNameLen)} {
+		_, err := NewFeedTokenName(name)
+		require.NoErrorf(t, err, "name '%s' must be valid", name)
+	}
+
+	_, err := NewFeedTokenName(strings.Repeat("я", MaxFeedTokenNameLen+1))
+	require.ErrorIs(t, err, ErrMaxFeedTokenName, "must be ErrMaxFeedTokenName error")
+}
+
+func TestNewFeedTokenIDFromString(t *testing.T) {
+	tokenID := NewFeedTokenID()
+
+	parsed, err := NewFeedTokenIDFromString(string(tokenID))
+	require.NoError(t, err, "must be valid token ID")
+	require.Equal(t, tokenID, parsed, "must keep token ID")
+
+	_, err = NewFeedTokenIDFromString("token")
+	require.ErrorIs(t, err, ErrInvalidFeedTokenID, "must be ErrInvalidFeedTokenID error")
+}
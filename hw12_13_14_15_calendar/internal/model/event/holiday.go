@@ -0,0 +1,94 @@
+package event
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+)
+
+var (
+	ErrInvalidHolidayCalendar = domainerr.NewField(
+		"holiday_calendars",
+		"INVALID_HOLIDAY_CALENDAR",
+		"invalid holiday calendar name",
+	)
+	ErrTooManyHolidayCalendars = domainerr.NewField(
+		"holiday_calendars",
+		"TOO_MANY_HOLIDAY_CALENDARS",
+		"too many holiday calendars",
+	)
+	ErrHolidayCalendarNotFound = domainerr.New(
+		domainerr.KindNotFound,
+		"HOLIDAY_CALENDAR_NOT_FOUND",
+		"holiday calendar not found",
+	)
+)
+
+// HolidayCalendar - имя календаря праздников, например ru или de-by.
+//   - латинские буквы, цифры, '-' и '_'
+//   - начинается с буквы или цифры
+//   - не превышает MaxHolidayCalendarLen символов
+type HolidayCalendar string
+
+const (
+	MaxHolidayCalendarLen = 64
+
+	// MaxHolidayCalendars - на сколько календарей праздников может подписаться владелец.
+	MaxHolidayCalendars = 16
+)
+
+var holidayCalendarRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// NewHolidayCalendar проверяет, что строка name - валидное имя календаря праздников.
+// Возвращает HolidayCalendar или ошибку валидации ErrInvalidHolidayCalendar.
+func NewHolidayCalendar(name string) (HolidayCalendar, error) {
+	if len(name) > MaxHolidayCalendarLen || !holidayCalendarRe.MatchString(name) {
+		return HolidayCalendar(""), fmt.Errorf("%w: '%s'", ErrInvalidHolidayCalendar, name)
+	}
+
+	return HolidayCalendar(name), nil
+}
+
+// NewHolidayCalendars проверяет имена календарей names и возвращает их без повторов, упорядоченными,
+// или ошибку валидации (ErrInvalidHolidayCalendar, ErrTooManyHolidayCalendars).
+// Для пустого списка возвращает nil.
+func NewHolidayCalendars(names []string) ([]HolidayCalendar, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	result := make([]HolidayCalendar, 0, len(names))
+	for _, name := range names {
+		calendar, err := NewHolidayCalendar(name)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, calendar)
+	}
+
+	slices.Sort(result)
+	result = slices.Compact(result)
+
+	if len(result) > MaxHolidayCalendars {
+		return nil, ErrTooManyHolidayCalendars
+	}
+
+	return result, nil
+}
+
+// HolidayCalendarInfo - описание доступного календаря праздников.
+type HolidayCalendarInfo struct {
+	Name  HolidayCalendar
+	Title string // название календаря из файла, может быть пустым
+}
+
+// Holiday - праздник из календаря праздников: событие на весь день Date,
+// не занимающее время владельца.
+type Holiday struct {
+	Calendar HolidayCalendar
+	Date     Date
+	Title    string
+}
@@ -0,0 +1,43 @@
+package event
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHolidayCalendar(t *testing.T) {
+	for _, name := range []string{"ru", "de-by", "US_federal", "2030", strings.Repeat("x", MaxHolidayCalendarLen)} {
+		_, err := NewHolidayCalendar(name)
+		require.NoErrorf(t, err, "holiday calendar '%s' must be valid", name)
+	}
+
+	invalid := []string{"", "-ru", "ru.ics", "../ru", "de by", "рф", strings.Repeat("x", MaxHolidayCalendarLen+1)}
+	for _, name := range invalid {
+		_, err := NewHolidayCalendar(name)
+		require.ErrorIsf(t, err, ErrInvalidHolidayCalendar, "holiday calendar '%s' must be invalid", name)
+	}
+}
+
+func TestNewHolidayCalendars(t *testing.T) {
+	calendars, err := NewHolidayCalendars([]string{"ru", "de", "ru"})
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, []HolidayCalendar{"de", "ru"}, calendars, "must be sorted without duplicates")
+
+	calendars, err = NewHolidayCalendars(nil)
+	require.NoError(t, err, "must not have error")
+	require.Nil(t, calendars, "must be nil for empty calendars")
+
+	_, err = NewHolidayCalendars([]string{"ru", "r u"})
+	require.ErrorIs(t, err, ErrInvalidHolidayCalendar, "must be ErrInvalidHolidayCalendar error")
+
+	many := make([]string, MaxHolidayCalendars+1)
+	for i := range many {
+		many[i] = "c" + strconv.Itoa(i)
+	}
+
+	_, err = NewHolidayCalendars(many)
+	require.ErrorIs(t, err, ErrTooManyHolidayCalendars, "must be ErrTooManyHolidayCalendars error")
+}
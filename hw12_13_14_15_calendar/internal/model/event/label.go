@@ -0,0 +1,143 @@
+package event
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+)
+
+var (
+	ErrInvalidLabel  = domainerr.NewField("labels", "INVALID_LABEL", "invalid label")
+	ErrTooManyLabels = domainerr.NewField("labels", "TOO_MANY_LABELS", "too many labels")
+	ErrInvalidColor  = domainerr.NewField("color", "INVALID_COLOR", "invalid color")
+)
+
+// Label - метка (категория) события, например meeting, focus или personal.
+//   - строчные буквы, цифры, '-' и '_'
+//   - начинается с буквы или цифры
+//   - не превышает MaxLabelLen символов
+type Label string
+
+const (
+	MaxLabelLen = 32
+
+	// MaxEventLabels - сколько меток допустимо у одного события.
+	MaxEventLabels = 16
+)
+
+var labelRe = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}][\p{Ll}\p{Lo}\p{N}_-]*$`)
+
+// NewLabel проверяет, что строка label - валидная метка.
+// Возвращает Label или ошибку валидации ErrInvalidLabel.
+func NewLabel(label string) (Label, error) {
+	if utf8.RuneCountInString(label) > MaxLabelLen || !labelRe.MatchString(label) {
+		return Label(""), fmt.Errorf("%w: '%s'", ErrInvalidLabel, label)
+	}
+
+	return Label(label), nil
+}
+
+// NewLabels проверяет метки labels и возвращает их без повторов, упорядоченными по имени,
+// или ошибку валидации (ErrInvalidLabel, ErrTooManyLabels). Для пустого списка возвращает nil.
+func NewLabels(labels []string) ([]Label, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	result := make([]Label, 0, len(labels))
+	for _, l := range labels {
+		label, err := NewLabel(l)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, label)
+	}
+
+	slices.Sort(result)
+	result = slices.Compact(result)
+
+	if len(result) > MaxEventLabels {
+		return nil, ErrTooManyLabels
+	}
+
+	return result, nil
+}
+
+// Color - цвет в формате #rrggbb (в нижнем регистре), пустая строка - цвет не задан.
+type Color string
+
+var colorRe = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// NewColor проверяет, что строка color - цвет в формате #rrggbb или пустая строка.
+// Возвращает Color или ошибку валидации ErrInvalidColor.
+func NewColor(color string) (Color, error) {
+	if color == "" {
+		return Color(""), nil
+	}
+
+	color = strings.ToLower(color)
+	if !colorRe.MatchString(color) {
+		return Color(""), fmt.Errorf("%w: '%s'", ErrInvalidColor, color)
+	}
+
+	return Color(color), nil
+}
+
+// OwnerLabel - метка в каталоге меток владельца в рабочем пространстве.
+// События владельца могут быть отмечены только метками из его каталога.
+type OwnerLabel struct {
+	tenantID TenantID // рабочее пространство владельца
+	ownerID  OwnerID  // владелец каталога
+	name     Label    // имя метки, уникально в каталоге
+
+	Color Color // цвет метки, опционально
+}
+
+// NewOwnerLabel создаёт метку name в каталоге владельца ownerID в рабочем пространстве tenantID.
+func NewOwnerLabel(tenantID TenantID, ownerID OwnerID, name Label, color Color) OwnerLabel {
+	return OwnerLabel{
+		tenantID: tenantID,
+		ownerID:  ownerID,
+		name:     name,
+		Color:    color,
+	}
+}
+
+func (l *OwnerLabel) TenantID() TenantID {
+	return l.tenantID
+}
+
+func (l *OwnerLabel) OwnerID() OwnerID {
+	return l.ownerID
+}
+
+func (l *OwnerLabel) Name() Label {
+	return l.name
+}
+
+// Filter - отбор событий при запросе событий за промежуток времени.
+// Пустой Filter не ограничивает выборку.
+type Filter struct {
+	// Labels - события, отмеченные хотя бы одной из меток. Пусто - без отбора по меткам.
+	Labels []Label
+}
+
+// Match сообщает, подходит ли событие event под отбор.
+func (f Filter) Match(event Event) bool {
+	if len(f.Labels) == 0 {
+		return true
+	}
+
+	for _, label := range f.Labels {
+		if slices.Contains(event.Labels, label) {
+			return true
+		}
+	}
+
+	return false
+}
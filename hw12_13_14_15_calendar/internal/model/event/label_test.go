@@ -0,0 +1,67 @@
+package event
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLabel(t *testing.T) {
+	valid := []string{"meeting", "focus-time", "q3_review", "1on1", "встреча", strings.Repeat("x", MaxLabelLen)}
+	for _, label := range valid {
+		_, err := NewLabel(label)
+		require.NoErrorf(t, err, "label '%s' must be valid", label)
+	}
+
+	for _, label := range []string{"", "Meeting", "-focus", "a b", "a/b", strings.Repeat("x", MaxLabelLen+1)} {
+		_, err := NewLabel(label)
+		require.ErrorIsf(t, err, ErrInvalidLabel, "label '%s' must be invalid", label)
+	}
+}
+
+func TestNewLabels(t *testing.T) {
+	labels, err := NewLabels([]string{"personal", "meeting", "personal"})
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, []Label{"meeting", "personal"}, labels, "must be sorted without duplicates")
+
+	labels, err = NewLabels(nil)
+	require.NoError(t, err, "must not have error")
+	require.Nil(t, labels, "must be nil for empty labels")
+
+	_, err = NewLabels([]string{"meeting", "Bad"})
+	require.ErrorIs(t, err, ErrInvalidLabel, "must be ErrInvalidLabel error")
+
+	many := make([]string, MaxEventLabels+1)
+	for i := range many {
+		many[i] = strings.Repeat("x", i+1)
+	}
+
+	_, err = NewLabels(many)
+	require.ErrorIs(t, err, ErrTooManyLabels, "must be ErrTooManyLabels error")
+}
+
+func TestNewColor(t *testing.T) {
+	color, err := NewColor("#FFaa00")
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, Color("#ffaa00"), color, "must be lower case")
+
+	color, err = NewColor("")
+	require.NoError(t, err, "empty color must be valid")
+	require.Empty(t, color)
+
+	for _, color := range []string{"red", "#fff", "ffaa00", "#ffaa0g"} {
+		_, err := NewColor(color)
+		require.ErrorIsf(t, err, ErrInvalidColor, "color '%s' must be invalid", color)
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	event := Event{Labels: []Label{"focus", "meeting"}}
+
+	require.True(t, Filter{}.Match(event), "empty filter must match any event")
+	require.True(t, Filter{Labels: []Label{"meeting"}}.Match(event), "must match label")
+	require.True(t, Filter{Labels: []Label{"personal", "focus"}}.Match(event), "must match any of labels")
+	require.False(t, Filter{Labels: []Label{"personal"}}.Match(event), "must not match other labels")
+	require.False(t, Filter{Labels: []Label{"personal"}}.Match(Event{}), "must not match event without labels")
+}
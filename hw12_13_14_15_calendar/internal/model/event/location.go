@@ -0,0 +1,206 @@
+package event
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+)
+
+var (
+	ErrMaxLocationNameLen   = domainerr.NewField("location.name", "LOCATION_TOO_LONG", "location is too long")
+	ErrMaxAddressLen        = domainerr.NewField("location.address", "ADDRESS_TOO_LONG", "address is too long")
+	ErrInvalidLatitude      = domainerr.NewField("latitude", "INVALID_LATITUDE", "latitude must be in [-90, 90]")
+	ErrInvalidLongitude     = domainerr.NewField("longitude", "INVALID_LONGITUDE", "longitude must be in [-180, 180]")
+	ErrInvalidRadius        = domainerr.NewField("radius", "INVALID_RADIUS", "invalid radius")
+	ErrInvalidConferenceURL = domainerr.NewField(
+		"conference_url",
+		"INVALID_CONFERENCE_URL",
+		"invalid conference URL, must be absolute http(s) URL",
+	)
+)
+
+const (
+	MaxLocationNameLen = 256
+	MaxAddressLen      = 512
+
+	// MaxAreaRadius - наибольший радиус области поиска событий в метрах.
+	MaxAreaRadius = 1_000_000
+
+	// earthRadius - средний радиус Земли в метрах.
+	earthRadius = 6_371_008.8
+)
+
+// Point - географические координаты в градусах (WGS 84).
+type Point struct {
+	Latitude  float64 // широта, [-90, 90]
+	Longitude float64 // долгота, [-180, 180]
+}
+
+// NewPoint проверяет координаты latitude и longitude.
+// Возвращает Point или ошибку валидации (ErrInvalidLatitude, ErrInvalidLongitude).
+func NewPoint(latitude float64, longitude float64) (Point, error) {
+	if math.IsNaN(latitude) || latitude < -90 || latitude > 90 {
+		return Point{}, fmt.Errorf("%w: %v", ErrInvalidLatitude, latitude)
+	}
+
+	if math.IsNaN(longitude) || longitude < -180 || longitude > 180 {
+		return Point{}, fmt.Errorf("%w: %v", ErrInvalidLongitude, longitude)
+	}
+
+	return Point{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// DistanceTo возвращает расстояние в метрах по поверхности Земли от p до q (формула гаверсинусов).
+func (p Point) DistanceTo(q Point) float64 {
+	lat1 := radians(p.Latitude)
+	lat2 := radians(q.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(q.Longitude - p.Longitude)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// Location - место проведения события. Пустое значение - место не задано.
+type Location struct {
+	Name    string // место в свободной форме, например "переговорная 3"
+	Address string // адрес, опционально
+	Point   *Point // координаты места, nil - не заданы
+}
+
+// NewLocation проверяет место name, адрес address и координаты point (nil - не заданы).
+// Возвращает Location или ошибку валидации (ErrMaxLocationNameLen, ErrMaxAddressLen).
+func NewLocation(name string, address string, point *Point) (Location, error) {
+	if utf8.RuneCountInString(name) > MaxLocationNameLen {
+		return Location{}, ErrMaxLocationNameLen
+	}
+
+	if utf8.RuneCountInString(address) > MaxAddressLen {
+		return Location{}, ErrMaxAddressLen
+	}
+
+	return Location{
+		Name:    name,
+		Address: address,
+		Point:   point,
+	}, nil
+}
+
+// IsZero сообщает, что место не задано.
+func (l Location) IsZero() bool {
+	return l.Name == "" && l.Address == "" && l.Point == nil
+}
+
+// ConferenceURL - ссылка на видеоконференцию события, пустая строка - ссылка не задана.
+type ConferenceURL string
+
+// NewConferenceURL проверяет, что rawURL - абсолютный http(s) адрес или пустая строка.
+// Возвращает ConferenceURL или ошибку валидации ErrInvalidConferenceURL.
+func NewConferenceURL(rawURL string) (ConferenceURL, error) {
+	if rawURL != "" && !isHTTPURL(rawURL) {
+		return ConferenceURL(""), fmt.Errorf("%w: '%s'", ErrInvalidConferenceURL, rawURL)
+	}
+
+	return ConferenceURL(rawURL), nil
+}
+
+// Area - круглая область поиска событий по месту проведения.
+type Area struct {
+	Center Point   // центр области
+	Radius float64 // радиус области в метрах
+}
+
+// NewArea создаёт область радиусом radius метров с центром center.
+// Возвращает ошибку валидации ErrInvalidRadius, если радиус не в промежутке (0, MaxAreaRadius].
+func NewArea(center Point, radius float64) (Area, error) {
+	if math.IsNaN(radius) || radius <= 0 || radius > MaxAreaRadius {
+		return Area{}, fmt.Errorf("%w: must be in (0, %d]", ErrInvalidRadius, MaxAreaRadius)
+	}
+
+	return Area{Center: center, Radius: radius}, nil
+}
+
+// Contains сообщает, что точка p находится в области.
+func (a Area) Contains(p Point) bool {
+	return a.Center.DistanceTo(p) <= a.Radius
+}
+
+// BoundingBox возвращает прямоугольник (по широте и долготе), описанный вокруг области.
+// Прямоугольник используется для предварительного отбора по индексу, точный отбор - Contains.
+func (a Area) BoundingBox() BoundingBox {
+	dLat := degrees(a.Radius / earthRadius)
+
+	box := BoundingBox{
+		MinLat: a.Center.Latitude - dLat,
+		MaxLat: a.Center.Latitude + dLat,
+		MinLon: -180,
+		MaxLon: 180,
+	}
+
+	// область, включающая полюс, включает все долготы
+	if box.MinLat <= -90 || box.MaxLat >= 90 {
+		box.MinLat = math.Max(box.MinLat, -90)
+		box.MaxLat = math.Min(box.MaxLat, 90)
+
+		return box
+	}
+
+	dLon := degrees(math.Asin(math.Sin(a.Radius/earthRadius) / math.Cos(radians(a.Center.Latitude))))
+
+	box.MinLon = a.Center.Longitude - dLon
+	if box.MinLon < -180 {
+		box.MinLon += 360
+	}
+
+	box.MaxLon = a.Center.Longitude + dLon
+	if box.MaxLon > 180 {
+		box.MaxLon -= 360
+	}
+
+	return box
+}
+
+// BoundingBox - прямоугольник по широте и долготе в градусах.
+// Если MinLon > MaxLon, прямоугольник пересекает 180-й меридиан: долготы [MinLon, 180] и [-180, MaxLon].
+type BoundingBox struct {
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+}
+
+// Contains сообщает, что точка p находится в прямоугольнике.
+func (b BoundingBox) Contains(p Point) bool {
+	if p.Latitude < b.MinLat || p.Latitude > b.MaxLat {
+		return false
+	}
+
+	if b.MinLon > b.MaxLon {
+		return p.Longitude >= b.MinLon || p.Longitude <= b.MaxLon
+	}
+
+	return p.Longitude >= b.MinLon && p.Longitude <= b.MaxLon
+}
+
+// Split возвращает прямоугольник как два прямоугольника, не пересекающих 180-й меридиан.
+// Если прямоугольник не пересекает меридиан, оба прямоугольника совпадают с ним.
+func (b BoundingBox) Split() [2]BoundingBox {
+	if b.MinLon <= b.MaxLon {
+		return [2]BoundingBox{b, b}
+	}
+
+	east, west := b, b
+	east.MaxLon = 180
+	west.MinLon = -180
+
+	return [2]BoundingBox{east, west}
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,128 @@
+package event
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPoint(t *testing.T) {
+	for _, p := range [][2]float64{{0, 0}, {-90, -180}, {90, 180}, {55.7558, 37.6173}} {
+		_, err := NewPoint(p[0], p[1])
+		require.NoErrorf(t, err, "point %v must be valid", p)
+	}
+
+	for _, lat := range []float64{-90.1, 90.1, math.NaN()} {
+		_, err := NewPoint(lat, 0)
+		require.ErrorIsf(t, err, ErrInvalidLatitude, "latitude %v must be invalid", lat)
+	}
+
+	for _, lon := range []float64{-180.1, 180.1, math.Inf(1)} {
+		_, err := NewPoint(0, lon)
+		require.ErrorIsf(t, err, ErrInvalidLongitude, "longitude %v must be invalid", lon)
+	}
+}
+
+func TestPoint_DistanceTo(t *testing.T) {
+	moscow := Point{Latitude: 55.7558, Longitude: 37.6173}
+	petersburg := Point{Latitude: 59.9343, Longitude: 30.3351}
+
+	require.InDelta(t, 634_000, moscow.DistanceTo(petersburg), 2_000, "must calculate distance")
+	require.InDelta(t, moscow.DistanceTo(petersburg), petersburg.DistanceTo(moscow), 1e-6, "must be symmetric")
+	require.Zero(t, moscow.DistanceTo(moscow), "must be zero for same point")
+
+	// 1 градус долготы на экваторе
+	require.InDelta(t, 111_195, Point{}.DistanceTo(Point{Longitude: 1}), 1, "must calculate distance")
+}
+
+func TestNewLocation(t *testing.T) {
+	location, err := NewLocation("room 3", "", nil)
+	require.NoError(t, err, "must not have error")
+	require.False(t, location.IsZero(), "must not be zero")
+	require.True(t, Location{}.IsZero(), "must be zero")
+
+	_, err = NewLocation(strings.Repeat("x", MaxLocationNameLen+1), "", nil)
+	require.ErrorIs(t, err, ErrMaxLocationNameLen, "must be ErrMaxLocationNameLen error")
+
+	_, err = NewLocation("", strings.Repeat("x", MaxAddressLen+1), nil)
+	require.ErrorIs(t, err, ErrMaxAddressLen, "must be ErrMaxAddressLen error")
+}
+
+func TestNewConferenceURL(t *testing.T) {
+	for _, u := range []string{"", "https://meet.example.com/abc-def", "http://localhost:8080/room"} {
+		_, err := NewConferenceURL(u)
+		require.NoErrorf(t, err, "URL '%s' must be valid", u)
+	}
+
+	invalid := []string{"meet.example.com", "ftp://example.com", "https://", "https://" + strings.Repeat("x", MaxURLLen)}
+	for _, u := range invalid {
+		_, err := NewConferenceURL(u)
+		require.ErrorIsf(t, err, ErrInvalidConferenceURL, "URL '%s' must be invalid", u)
+	}
+}
+
+func TestArea(t *testing.T) {
+	for _, radius := range []float64{0, -1, MaxAreaRadius + 1, math.NaN()} {
+		_, err := NewArea(Point{}, radius)
+		require.ErrorIsf(t, err, ErrInvalidRadius, "radius %v must be invalid", radius)
+	}
+
+	tests := []struct {
+		name   string
+		center Point
+		radius float64
+		inside []Point
+		out    []Point
+	}{
+		{
+			name:   "city",
+			center: Point{Latitude: 55.7558, Longitude: 37.6173},
+			radius: 10_000,
+			inside: []Point{{Latitude: 55.7558, Longitude: 37.6173}, {Latitude: 55.8, Longitude: 37.6}},
+			out:    []Point{{Latitude: 55.9, Longitude: 37.6}, {Latitude: 59.9343, Longitude: 30.3351}},
+		},
+		{
+			name:   "antimeridian",
+			center: Point{Latitude: 65, Longitude: 179.9},
+			radius: 50_000,
+			inside: []Point{{Latitude: 65, Longitude: -179.9}, {Latitude: 65.1, Longitude: 179.5}},
+			out:    []Point{{Latitude: 65, Longitude: -178}, {Latitude: 65, Longitude: 0}},
+		},
+		{
+			name:   "pole",
+			center: Point{Latitude: 89.9, Longitude: 0},
+			radius: 50_000,
+			inside: []Point{{Latitude: 89.9, Longitude: 180}, {Latitude: 90, Longitude: -90}},
+			out:    []Point{{Latitude: 89, Longitude: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area, err := NewArea(tt.center, tt.radius)
+			require.NoError(t, err, "must not have error")
+
+			box := area.BoundingBox()
+
+			for _, p := range tt.inside {
+				require.Truef(t, area.Contains(p), "area must contain %v", p)
+				require.Truef(t, box.Contains(p), "bounding box must contain %v", p)
+			}
+
+			for _, p := range tt.out {
+				require.Falsef(t, area.Contains(p), "area must not contain %v", p)
+			}
+
+			for _, p := range tt.inside {
+				split := box.Split()
+				require.Truef(t, split[0].Contains(p) || split[1].Contains(p), "split box must contain %v", p)
+
+				for _, b := range split {
+					require.LessOrEqual(t, b.MinLon, b.MaxLon, "split box must not cross antimeridian")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package event
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+)
+
+var (
+	ErrInvalidTimeZone          = domainerr.NewField("time_zone", "INVALID_TIME_ZONE", "invalid time zone")
+	ErrInvalidTimeOfDay         = domainerr.NewField("working_days", "INVALID_TIME_OF_DAY", "time must be HH:MM")
+	ErrInvalidWorkingHours      = domainerr.NewField("working_days", "INVALID_WORKING_HOURS", "invalid working hours")
+	ErrInvalidHoliday           = domainerr.NewField("holidays", "INVALID_HOLIDAY", "invalid holiday date")
+	ErrTooManyHolidays          = domainerr.NewField("holidays", "TOO_MANY_HOLIDAYS", "too many holidays")
+	ErrInvalidAvailabilityCheck = domainerr.NewField(
+		"availability_check",
+		"INVALID_AVAILABILITY_CHECK",
+		"availability check must be off, warn or reject",
+	)
+
+	ErrOutsideWorkingHours = domainerr.New(
+		domainerr.KindFailedPrecondition,
+		"OUTSIDE_WORKING_HOURS",
+		"event is outside owner's working hours",
+	)
+	ErrOnHoliday = domainerr.New(domainerr.KindFailedPrecondition, "ON_HOLIDAY", "event is on owner's holiday")
+)
+
+// MaxHolidays - сколько выходных дней допустимо в профиле владельца.
+const MaxHolidays = 512
+
+// TimeOfDay - время суток в минутах от полуночи, от 00:00 до 24:00 включительно.
+type TimeOfDay int
+
+// endOfDay - полночь следующего дня.
+const endOfDay = TimeOfDay(24 * 60)
+
+var timeOfDayRe = regexp.MustCompile(`^[0-9]{2}:[0-9]{2}$`)
+
+// NewTimeOfDay разбирает время суток в формате HH:MM (24:00 - конец суток).
+// Возвращает TimeOfDay или ошибку валидации ErrInvalidTimeOfDay.
+func NewTimeOfDay(value string) (TimeOfDay, error) {
+	if !timeOfDayRe.MatchString(value) {
+		return 0, fmt.Errorf("%w: '%s'", ErrInvalidTimeOfDay, value)
+	}
+
+	hour := int(value[0]-'0')*10 + int(value[1]-'0')
+	minute := int(value[3]-'0')*10 + int(value[4]-'0')
+
+	t := TimeOfDay(hour*60 + minute)
+	if minute > 59 || t > endOfDay {
+		return 0, fmt.Errorf("%w: '%s'", ErrInvalidTimeOfDay, value)
+	}
+
+	return t, nil
+}
+
+// String возвращает время суток в формате HH:MM.
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", t/60, t%60)
+}
+
+// WorkingHours - рабочие часы дня [Start, End). Пустое значение - нерабочий день.
+type WorkingHours struct {
+	Start TimeOfDay
+	End   TimeOfDay
+}
+
+// NewWorkingHours проверяет, что рабочие часы начинаются раньше, чем заканчиваются.
+// Возвращает WorkingHours или ошибку валидации ErrInvalidWorkingHours.
+func NewWorkingHours(start TimeOfDay, end TimeOfDay) (WorkingHours, error) {
+	if start < 0 || end > endOfDay || start >= end {
+		return WorkingHours{}, fmt.Errorf("%w: %s-%s", ErrInvalidWorkingHours, start, end)
+	}
+
+	return WorkingHours{Start: start, End: end}, nil
+}
+
+// IsZero сообщает, что день нерабочий.
+func (h WorkingHours) IsZero() bool {
+	return h == WorkingHours{}
+}
+
+// Date - календарная дата без времени и часового пояса.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate проверяет, что year, month и day - существующая дата.
+// Возвращает Date или ошибку валидации ErrInvalidHoliday.
+func NewDate(year int, month int, day int) (Date, error) {
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day {
+		return Date{}, fmt.Errorf("%w: %04d-%02d-%02d", ErrInvalidHoliday, year, month, day)
+	}
+
+	return DateOf(t), nil
+}
+
+// DateOf возвращает дату времени t в его часовом поясе.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// String возвращает дату в формате YYYY-MM-DD.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Compare сравнивает даты: -1, если d раньше other, +1, если позже, иначе 0.
+func (d Date) Compare(other Date) int {
+	switch {
+	case d.Year != other.Year:
+		return compareInts(d.Year, other.Year)
+	case d.Month != other.Month:
+		return compareInts(int(d.Month), int(other.Month))
+	default:
+		return compareInts(d.Day, other.Day)
+	}
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NewHolidays возвращает выходные дни dates без повторов, упорядоченными по дате,
+// или ошибку валидации ErrTooManyHolidays. Для пустого списка возвращает nil.
+func NewHolidays(dates []Date) ([]Date, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	result := slices.Clone(dates)
+	slices.SortFunc(result, Date.Compare)
+	result = slices.Compact(result)
+
+	if len(result) > MaxHolidays {
+		return nil, ErrTooManyHolidays
+	}
+
+	return result, nil
+}
+
+// AvailabilityCheck - проверка, что новое событие владельца попадает в его рабочее время.
+type AvailabilityCheck string
+
+const (
+	// AvailabilityCheckOff - проверка отключена.
+	AvailabilityCheckOff AvailabilityCheck = "off"
+
+	// AvailabilityCheckWarn - событие создаётся, но с предупреждением.
+	AvailabilityCheckWarn AvailabilityCheck = "warn"
+
+	// AvailabilityCheckReject - событие не создаётся.
+	AvailabilityCheckReject AvailabilityCheck = "reject"
+)
+
+// NewAvailabilityCheck проверяет вид проверки check, пустая строка - AvailabilityCheckOff.
+// Возвращает AvailabilityCheck или ошибку валидации ErrInvalidAvailabilityCheck.
+func NewAvailabilityCheck(check string) (AvailabilityCheck, error) {
+	switch c := AvailabilityCheck(check); c {
+	case "":
+		return AvailabilityCheckOff, nil
+	case AvailabilityCheckOff, AvailabilityCheckWarn, AvailabilityCheckReject:
+		return c, nil
+	default:
+		return AvailabilityCheckOff, fmt.Errorf("%w: '%s'", ErrInvalidAvailabilityCheck, check)
+	}
+}
+
+// NewTimeZone загружает часовой пояс IANA name, пустая строка - UTC.
+// Возвращает часовой пояс или ошибку валидации ErrInvalidTimeZone.
+func NewTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("%w: '%s'", ErrInvalidTimeZone, name)
+	}
+
+	return loc, nil
+}
+
+// Profile - профиль доступности владельца в рабочем пространстве:
+// часовой пояс, рабочие часы по дням недели, выходные дни и календари праздников.
+type Profile struct {
+	tenantID TenantID // рабочее пространство владельца
+	ownerID  OwnerID  // владелец профиля
+
+	// TimeZone - часовой пояс рабочих часов и выходных дней.
+	TimeZone *time.Location
+
+	// WorkingHours - рабочие часы по дням недели (индекс - time.Weekday).
+	// Если рабочие часы не заданы ни для одного дня, рабочее время не ограничено.
+	WorkingHours [7]WorkingHours
+
+	// Holidays - выходные дни, упорядоченные по дате.
+	Holidays []Date
+
+	// Check - проверка новых событий владельца на попадание в рабочее время.
+	Check AvailabilityCheck
+
+	// HolidayCalendars - календари праздников, на которые подписан владелец, упорядоченные по имени.
+	// Праздники из них не влияют на проверку рабочего времени.
+	HolidayCalendars []HolidayCalendar
+}
+
+// NewProfile создаёт профиль владельца ownerID в рабочем пространстве tenantID:
+// часовой пояс UTC, рабочее время не ограничено, проверка отключена.
+func NewProfile(tenantID TenantID, ownerID OwnerID) Profile {
+	return Profile{
+		tenantID: tenantID,
+		ownerID:  ownerID,
+		TimeZone: time.UTC,
+		Check:    AvailabilityCheckOff,
+	}
+}
+
+func (p *Profile) TenantID() TenantID {
+	return p.tenantID
+}
+
+func (p *Profile) OwnerID() OwnerID {
+	return p.ownerID
+}
+
+// HasWorkingHours сообщает, что рабочие часы заданы хотя бы для одного дня недели.
+func (p *Profile) HasWorkingHours() bool {
+	for _, h := range p.WorkingHours {
+		if !h.IsZero() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsHoliday сообщает, что дата date - выходной день.
+func (p *Profile) IsHoliday(date Date) bool {
+	_, found := slices.BinarySearchFunc(p.Holidays, date, Date.Compare)
+
+	return found
+}
+
+// CheckAvailability проверяет, что промежуток [startAt, endAt) попадает в рабочее время владельца.
+// Возвращает ErrOnHoliday, если промежуток затрагивает выходной день,
+// и ErrOutsideWorkingHours, если промежуток выходит за рабочие часы своего дня.
+func (p *Profile) CheckAvailability(startAt time.Time, endAt time.Time) error {
+	loc := p.TimeZone
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	start := startAt.In(loc)
+	end := endAt.In(loc)
+
+	// последний момент промежутка: конец не входит в промежуток
+	last := start
+	if end.After(start) {
+		last = end.Add(-time.Nanosecond)
+	}
+
+	firstDay := DateOf(start)
+	lastDay := DateOf(last)
+
+	for day := start; DateOf(day).Compare(lastDay) <= 0; day = day.AddDate(0, 0, 1) {
+		if p.IsHoliday(DateOf(day)) {
+			return fmt.Errorf("%w: %s", ErrOnHoliday, DateOf(day))
+		}
+	}
+
+	if !p.HasWorkingHours() {
+		return nil
+	}
+
+	if firstDay != lastDay {
+		return ErrOutsideWorkingHours
+	}
+
+	hours := p.WorkingHours[start.Weekday()]
+	if hours.IsZero() {
+		return fmt.Errorf("%w: %s is a day off", ErrOutsideWorkingHours, start.Weekday())
+	}
+
+	from := sinceMidnight(start)
+	to := sinceMidnight(end)
+	if DateOf(end) != firstDay {
+		// промежуток заканчивается ровно в полночь следующего дня
+		to = time.Duration(endOfDay) * time.Minute
+	}
+
+	if from < time.Duration(hours.Start)*time.Minute || to > time.Duration(hours.End)*time.Minute {
+		return fmt.Errorf("%w: %s-%s", ErrOutsideWorkingHours, hours.Start, hours.End)
+	}
+
+	return nil
+}
+
+// sinceMidnight возвращает время, прошедшее с начала суток t по часам.
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second +
+		time.Duration(t.Nanosecond())
+}
@@ -0,0 +1,141 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTimeOfDay(t *testing.T) {
+	for value, expected := range map[string]TimeOfDay{"00:00": 0, "09:30": 570, "23:59": 1439, "24:00": 1440} {
+		tod, err := NewTimeOfDay(value)
+		require.NoErrorf(t, err, "time '%s' must be valid", value)
+		require.Equal(t, expected, tod)
+		require.Equal(t, value, tod.String(), "must format time")
+	}
+
+	for _, value := range []string{"", "9:00", "+9:00", "09:60", "24:01", "25:00", "0900", "09:00:00"} {
+		_, err := NewTimeOfDay(value)
+		require.ErrorIsf(t, err, ErrInvalidTimeOfDay, "time '%s' must be invalid", value)
+	}
+}
+
+func TestNewWorkingHours(t *testing.T) {
+	_, err := NewWorkingHours(9*60, 18*60)
+	require.NoError(t, err, "must not have error")
+
+	_, err = NewWorkingHours(0, endOfDay)
+	require.NoError(t, err, "must allow whole day")
+
+	_, err = NewWorkingHours(18*60, 9*60)
+	require.ErrorIs(t, err, ErrInvalidWorkingHours, "must be ErrInvalidWorkingHours error")
+
+	_, err = NewWorkingHours(9*60, 9*60)
+	require.ErrorIs(t, err, ErrInvalidWorkingHours, "must be ErrInvalidWorkingHours error")
+}
+
+func TestNewHolidays(t *testing.T) {
+	d1, err := NewDate(2030, 1, 1)
+	require.NoError(t, err)
+
+	d2, err := NewDate(2029, 12, 31)
+	require.NoError(t, err)
+
+	_, err = NewDate(2030, 2, 30)
+	require.ErrorIs(t, err, ErrInvalidHoliday, "must be ErrInvalidHoliday error")
+
+	holidays, err := NewHolidays([]Date{d1, d2, d1})
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, []Date{d2, d1}, holidays, "must be sorted without duplicates")
+
+	many := make([]Date, MaxHolidays+1)
+	for i := range many {
+		many[i] = DateOf(time.Date(2030, 1, 1+i, 0, 0, 0, 0, time.UTC))
+	}
+
+	_, err = NewHolidays(many)
+	require.ErrorIs(t, err, ErrTooManyHolidays, "must be ErrTooManyHolidays error")
+}
+
+func TestNewAvailabilityCheck(t *testing.T) {
+	check, err := NewAvailabilityCheck("")
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, AvailabilityCheckOff, check, "must be off by default")
+
+	check, err = NewAvailabilityCheck("reject")
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, AvailabilityCheckReject, check)
+
+	_, err = NewAvailabilityCheck("deny")
+	require.ErrorIs(t, err, ErrInvalidAvailabilityCheck, "must be ErrInvalidAvailabilityCheck error")
+}
+
+func TestNewTimeZone(t *testing.T) {
+	loc, err := NewTimeZone("")
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, time.UTC, loc, "must be UTC by default")
+
+	loc, err = NewTimeZone("Asia/Tokyo")
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, "Asia/Tokyo", loc.String())
+
+	for _, name := range []string{"Local", "Mars/Olympus", "../etc"} {
+		_, err = NewTimeZone(name)
+		require.ErrorIsf(t, err, ErrInvalidTimeZone, "time zone '%s' must be invalid", name)
+	}
+}
+
+func TestProfile_CheckAvailability(t *testing.T) {
+	tokyo, err := NewTimeZone("Asia/Tokyo")
+	require.NoError(t, err)
+
+	profile := NewProfile(DefaultTenantID, NewOwnerID())
+	profile.TimeZone = tokyo
+
+	// 2030-01-07 - понедельник
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2030, time.January, day, hour, minute, 0, 0, tokyo)
+	}
+
+	require.NoError(t, profile.CheckAvailability(at(6, 3, 0), at(6, 4, 0)), "must not limit without working hours")
+
+	for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday} {
+		profile.WorkingHours[day] = WorkingHours{Start: 9 * 60, End: 18 * 60}
+	}
+
+	profile.Holidays = []Date{{Year: 2030, Month: time.January, Day: 8}}
+
+	tests := []struct {
+		name    string
+		startAt time.Time
+		endAt   time.Time
+		err     error
+	}{
+		{name: "inside", startAt: at(7, 9, 0), endAt: at(7, 18, 0)},
+		{name: "inside in UTC", startAt: at(7, 10, 0).UTC(), endAt: at(7, 11, 0).UTC()},
+		{name: "zero duration", startAt: at(7, 12, 0), endAt: at(7, 12, 0)},
+		{name: "before start", startAt: at(7, 8, 30), endAt: at(7, 9, 30), err: ErrOutsideWorkingHours},
+		{name: "after end", startAt: at(7, 17, 30), endAt: at(7, 18, 1), err: ErrOutsideWorkingHours},
+		{name: "day off", startAt: at(6, 10, 0), endAt: at(6, 11, 0), err: ErrOutsideWorkingHours},
+		{name: "several days", startAt: at(9, 17, 0), endAt: at(10, 10, 0), err: ErrOutsideWorkingHours},
+		{name: "holiday", startAt: at(8, 10, 0), endAt: at(8, 11, 0), err: ErrOnHoliday},
+		{name: "through holiday", startAt: at(7, 10, 0), endAt: at(9, 11, 0), err: ErrOnHoliday},
+		{name: "ends at holiday", startAt: at(7, 23, 0), endAt: at(8, 0, 0), err: ErrOutsideWorkingHours},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := profile.CheckAvailability(tt.startAt, tt.endAt)
+			if tt.err == nil {
+				require.NoError(t, err, "must be available")
+				return
+			}
+
+			require.ErrorIs(t, err, tt.err, "must not be available")
+		})
+	}
+
+	profile.WorkingHours[time.Monday] = WorkingHours{Start: 20 * 60, End: endOfDay}
+	require.NoError(t, profile.CheckAvailability(at(7, 23, 0), at(8, 0, 0)), "must allow end at midnight")
+}
@@ -8,8 +8,9 @@ import (
 
 // Notification - уведомление о событии.
 type Notification struct {
-	EventID event.ID
-	OwnerID event.OwnerID
-	Title   event.Title
-	Date    time.Time
+	TenantID event.TenantID
+	EventID  event.ID
+	OwnerID  event.OwnerID
+	Title    event.Title
+	Date     time.Time
 }
@@ -0,0 +1,94 @@
+// memory - очередь уведомлений в памяти процесса.
+// Заменяет rabbitmq, когда планировщик и рассыльщик работают в одном процессе (например, в интеграционных тестах).
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/notification"
+)
+
+var ErrClosed = errors.New("queue is closed")
+
+// Notification - уведомление в очереди.
+type Notification struct {
+	notification model.Notification
+	ctx          context.Context
+}
+
+// Model возвращает модель уведомления.
+func (n *Notification) Model() (model.Notification, error) {
+	return n.notification, nil
+}
+
+// Done подтверждает обработку уведомления. Очередь в памяти не возвращает уведомления повторно.
+func (n *Notification) Done() error {
+	return nil
+}
+
+// Context возвращает контекст, с которым уведомление было отправлено в очередь.
+func (n *Notification) Context() context.Context {
+	return n.ctx
+}
+
+type NotifyQueue struct {
+	ch chan *Notification
+
+	closed bool
+	mx     sync.RWMutex
+}
+
+// NewNotifyQueue создаёт очередь уведомлений вместимостью size.
+func NewNotifyQueue(size int) *NotifyQueue {
+	return &NotifyQueue{
+		ch: make(chan *Notification, size),
+	}
+}
+
+// Notify отправляет уведомление по событию e в очередь.
+// Если очередь заполнена, ждёт освобождения места или отмены контекста ctx.
+func (q *NotifyQueue) Notify(ctx context.Context, e event.Event) error {
+	q.mx.RLock()
+	defer q.mx.RUnlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+
+	n := &Notification{
+		notification: model.Notification{
+			TenantID: e.TenantID(),
+			EventID:  e.EventID(),
+			OwnerID:  e.OwnerID(),
+			Title:    e.Title,
+			Date:     e.StartAt(),
+		},
+		ctx: context.WithoutCancel(ctx),
+	}
+
+	select {
+	case q.ch <- n:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Receive возвращает канал уведомлений из очереди. Канал закрывается после Close.
+func (q *NotifyQueue) Receive() <-chan *Notification {
+	return q.ch
+}
+
+// Close закрывает очередь: новые уведомления не принимаются, оставшиеся в очереди возможно дочитать.
+func (q *NotifyQueue) Close() {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+}
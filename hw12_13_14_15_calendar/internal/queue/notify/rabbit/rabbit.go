@@ -2,6 +2,7 @@ package rabbit
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,9 +12,13 @@ import (
 
 	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/notification"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
 )
 
 const (
@@ -22,24 +27,31 @@ const (
 	routeKey     = "notify"
 )
 
-var ErrNotInitialized = errors.New("not initialized")
+var (
+	ErrNotInitialized   = errors.New("not initialized")
+	ErrConnectionClosed = errors.New("connection is closed")
+)
 
 // Notification объект уведомления в очереди rabbitmq.
 type Notification struct {
-	EventID string    `json:"eventId"`
-	OwnerID string    `json:"ownerId"`
-	Title   string    `json:"title"`
-	Date    time.Time `json:"startAt"`
-
-	m *amqp.Delivery `json:"-"`
+	// TenantID - рабочее пространство события. Пусто в уведомлениях, отправленных до появления пространств.
+	TenantID string    `json:"tenantId,omitempty"`
+	EventID  string    `json:"eventId"`
+	OwnerID  string    `json:"ownerId"`
+	Title    string    `json:"title"`
+	Date     time.Time `json:"startAt"`
+
+	m   *amqp.Delivery  `json:"-"`
+	ctx context.Context `json:"-"`
 }
 
 func NewNotification(e event.Event) Notification {
 	return Notification{
-		EventID: string(e.EventID()),
-		OwnerID: string(e.OwnerID()),
-		Title:   string(e.Title),
-		Date:    e.StartAt(),
+		TenantID: string(e.TenantID()),
+		EventID:  string(e.EventID()),
+		OwnerID:  string(e.OwnerID()),
+		Title:    string(e.Title),
+		Date:     e.StartAt(),
 	}
 }
 
@@ -49,8 +61,26 @@ func (n *Notification) Done() error {
 	return n.m.Ack(false)
 }
 
+// Context возвращает контекст трассировки, переданный с уведомлением в очереди.
+func (n *Notification) Context() context.Context {
+	if n.ctx == nil {
+		return context.Background()
+	}
+
+	return n.ctx
+}
+
 // Model возвращает модель уведомления, если возможно.
 func (n *Notification) Model() (model.Notification, error) {
+	tenantID := event.DefaultTenantID
+	if n.TenantID != "" {
+		var err error
+		tenantID, err = event.NewTenantIDFromString(n.TenantID)
+		if err != nil {
+			return model.Notification{}, err
+		}
+	}
+
 	eventID, err := event.NewIDFromString(n.EventID)
 	if err != nil {
 		return model.Notification{}, err
@@ -67,10 +97,11 @@ func (n *Notification) Model() (model.Notification, error) {
 	}
 
 	return model.Notification{
-		EventID: eventID,
-		OwnerID: ownerID,
-		Title:   title,
-		Date:    n.Date,
+		TenantID: tenantID,
+		EventID:  eventID,
+		OwnerID:  ownerID,
+		Title:    title,
+		Date:     n.Date,
 	}, nil
 }
 
@@ -97,7 +128,24 @@ func (n *Notification) Unmarshal(data []byte) error {
 	return nil
 }
 
+// Metrics - метрики подключения к очереди.
+type Metrics interface {
+	// Reconnected учитывает переподключение к очереди.
+	Reconnected()
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) Reconnected() {}
+
 type NotifyQueue struct {
+	// Metrics - метрики подключения к очереди, по умолчанию не учитываются.
+	Metrics Metrics
+
+	// TLSConfig - настройки TLS для подключения amqps:// (например, собственный CA или клиентский сертификат).
+	// Если не задан, для amqps:// используются настройки по умолчанию.
+	TLSConfig *tls.Config
+
 	connectString string
 	logger        *slog.Logger
 
@@ -108,25 +156,32 @@ type NotifyQueue struct {
 
 func NewNotifyQueue(logger *slog.Logger, connect string) *NotifyQueue {
 	return &NotifyQueue{
+		Metrics: noopMetrics{},
+
 		connectString: connect,
 		logger:        logger,
 	}
 }
 
 // Init инициализирует очередь rabbitmq.
-func (q *NotifyQueue) Init() (err error) {
+func (q *NotifyQueue) Init() error {
 	q.mx.Lock()
+	defer q.mx.Unlock()
 
-	defer func() {
-		q.mx.Unlock()
+	return q.connect()
+}
 
+// connect подключается к rabbitmq и инициализирует очередь.
+// В случае ошибки соединение закрывается.
+func (q *NotifyQueue) connect() (err error) {
+	defer func() {
 		if err != nil {
-			q.Done()
+			q.close()
 		}
 	}()
 
 	// 1 подключаемся
-	conn, err := amqp.Dial(q.connectString)
+	conn, err := q.dial()
 	if err != nil {
 		return fmt.Errorf("can't create connection: %w", err)
 	}
@@ -181,6 +236,15 @@ func (q *NotifyQueue) Init() (err error) {
 	return nil
 }
 
+// dial подключается к rabbitmq.
+func (q *NotifyQueue) dial() (*amqp.Connection, error) {
+	if q.TLSConfig != nil {
+		return amqp.DialTLS(q.connectString, q.TLSConfig)
+	}
+
+	return amqp.Dial(q.connectString)
+}
+
 // RegisterReceiver регистрирует новый "приёмник" для сообщений из очереди уведомлений.
 // Возвращает канал с уведомлениями или ошибку.
 // Завершить работу приёмника возможно отменой контекста.
@@ -217,14 +281,24 @@ func (q *NotifyQueue) RegisterReceiver(ctx context.Context) (<-chan Notification
 				return
 			}
 
+			msgCtx, span := startSpan(
+				otel.GetTextMapPropagator().Extract(context.Background(), headersCarrier(m.Headers)),
+				"receive",
+				trace.SpanKindConsumer,
+			)
+
 			var n Notification
 			if err := n.Unmarshal(m.Body); err != nil {
-				q.logger.Error("can't decode notification from queue", slog.String("error", err.Error()))
+				q.logger.ErrorContext(msgCtx, "can't decode notification from queue", slog.String("error", err.Error()))
+				tracing.End(span, &err)
 				m.Ack(false)
 				continue
 			}
 
+			span.End()
+
 			n.m = &m
+			n.ctx = msgCtx
 
 			out <- n
 		}
@@ -234,8 +308,12 @@ func (q *NotifyQueue) RegisterReceiver(ctx context.Context) (<-chan Notification
 }
 
 // Notify отправляет уведомление по событию event в очередь.
+// Контекст трассировки ctx передаётся в заголовках сообщения.
 // Возвращает ошибку, если отправить не удалось.
-func (q *NotifyQueue) Notify(ctx context.Context, event event.Event) error {
+func (q *NotifyQueue) Notify(ctx context.Context, event event.Event) (err error) {
+	ctx, span := startSpan(ctx, "publish", trace.SpanKindProducer)
+	defer tracing.End(span, &err)
+
 	q.mx.Lock()
 	defer q.mx.Unlock()
 
@@ -249,29 +327,154 @@ func (q *NotifyQueue) Notify(ctx context.Context, event event.Event) error {
 		return fmt.Errorf("can't marshal notification: %w", err)
 	}
 
-	err = q.ch.PublishWithContext(
+	err = q.publish(ctx, data)
+	if errors.Is(err, amqp.ErrClosed) {
+		// соединение или канал закрыты - переподключаемся и пробуем ещё раз
+		q.logger.Warn("notification queue is closed, reconnect", slog.String("error", err.Error()))
+
+		q.close()
+		if err := q.connect(); err != nil {
+			return fmt.Errorf("can't reconnect: %w", err)
+		}
+		q.Metrics.Reconnected()
+
+		err = q.publish(ctx, data)
+	}
+
+	if err != nil {
+		return fmt.Errorf("can't publish notification: %w", err)
+	}
+
+	return nil
+}
+
+// publish отправляет данные уведомления data в очередь.
+func (q *NotifyQueue) publish(ctx context.Context, data []byte) error {
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, headersCarrier(headers))
+
+	return q.ch.PublishWithContext(
 		ctx,
 		exchangeName,
 		routeKey,
 		false,
 		false,
 		amqp.Publishing{
+			Headers:     headers,
 			ContentType: "application/json",
 			Body:        data,
 		},
 	)
-	if err != nil {
-		return fmt.Errorf("can't publish notification: %w", err)
+}
+
+// Ping проверяет состояние подключения к очереди.
+func (q *NotifyQueue) Ping(context.Context) error {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	if q.conn == nil || q.ch == nil {
+		return ErrNotInitialized
+	}
+
+	if q.conn.IsClosed() || q.ch.IsClosed() {
+		return ErrConnectionClosed
 	}
 
 	return nil
 }
 
+// Stats - состояние очереди уведомлений.
+type Stats struct {
+	Queue     string `json:"queue"`
+	Messages  int    `json:"messages"`
+	Consumers int    `json:"consumers"`
+}
+
+// Stats возвращает состояние очереди уведомлений: количество ожидающих сообщений и получателей.
+func (q *NotifyQueue) Stats() (Stats, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	if q.ch == nil {
+		return Stats{}, ErrNotInitialized
+	}
+
+	queue, err := q.ch.QueueDeclarePassive(
+		queueName,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return Stats{}, fmt.Errorf("can't inspect queue: %w", err)
+	}
+
+	return Stats{
+		Queue:     queue.Name,
+		Messages:  queue.Messages,
+		Consumers: queue.Consumers,
+	}, nil
+}
+
+// Peek возвращает до limit уведомлений из начала очереди, не удаляя их:
+// прочитанные сообщения сразу возвращаются обратно в очередь (с признаком повторной доставки).
+func (q *NotifyQueue) Peek(limit int) ([]Notification, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	if q.ch == nil {
+		return nil, ErrNotInitialized
+	}
+
+	var (
+		notifications []Notification
+		lastTag       uint64
+	)
+
+	for range limit {
+		m, ok, err := q.ch.Get(queueName, false)
+		if err != nil {
+			return nil, fmt.Errorf("can't get from the queue: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		lastTag = m.DeliveryTag
+
+		// уведомление показываем как есть, даже если оно не проходит проверку
+		var n Notification
+		if err := json.Unmarshal(m.Body, &n); err != nil {
+			q.logger.Warn("can't decode notification from queue", slog.String("error", err.Error()))
+			continue
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	if lastTag != 0 {
+		// возвращаем все прочитанные сообщения в очередь
+		if err := q.ch.Nack(lastTag, true, true); err != nil {
+			return nil, fmt.Errorf("can't requeue messages: %w", err)
+		}
+	}
+
+	return notifications, nil
+}
+
 // Done завершает работу с очередью и закрывает соединение.
 func (q *NotifyQueue) Done() {
 	q.mx.Lock()
 	defer q.mx.Unlock()
 
+	q.close()
+}
+
+// close закрывает канал и соединение.
+func (q *NotifyQueue) close() {
 	if q.ch != nil {
 		q.ch.Close()
 		q.ch = nil
@@ -282,3 +485,38 @@ func (q *NotifyQueue) Done() {
 		q.conn = nil
 	}
 }
+
+// startSpan начинает span операции operation с очередью уведомлений.
+func startSpan(ctx context.Context, operation string, kind trace.SpanKind) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(
+		ctx,
+		queueName+" "+operation,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.name", operation),
+			attribute.String("messaging.destination.name", exchangeName),
+		),
+	)
+}
+
+// headersCarrier - propagation.TextMapCarrier для заголовков AMQP-сообщения.
+type headersCarrier amqp.Table
+
+func (c headersCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+func (c headersCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+func (c headersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -0,0 +1,165 @@
+// ratelimit - ограничение частоты запросов по алгоритму token bucket.
+//
+// Запросы учитываются раздельно для каждого ключа: владельца событий (OwnerID), если он известен,
+// или удалённого адреса клиента. Ограничения задаются по умолчанию и для отдельных методов.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+)
+
+const (
+	// idleTTL - через сколько удалять неиспользуемые bucket'ы.
+	idleTTL = 10 * time.Minute
+
+	// sweepInterval - как часто искать неиспользуемые bucket'ы.
+	sweepInterval = time.Minute
+)
+
+// Rule - ограничение частоты запросов.
+type Rule struct {
+	// Rate - допустимое количество запросов в секунду, 0 - без ограничений.
+	Rate float64
+
+	// Burst - сколько запросов допустимо выполнить разом, не менее 1.
+	Burst int
+}
+
+// Unlimited сообщает, что ограничения нет.
+func (r Rule) Unlimited() bool {
+	return r.Rate <= 0
+}
+
+// Rules - ограничения частоты запросов.
+type Rules struct {
+	// Default - ограничение для методов, не указанных в Methods.
+	Default Rule
+
+	// Methods - ограничения для отдельных методов.
+	// Метод указывается полным именем grpc-метода ("/event.v1.EventService/GetMonthEvents") или только его именем.
+	Methods map[string]Rule
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter ограничивает частоту запросов по ключу.
+type Limiter struct {
+	mx      sync.Mutex
+	rules   Rules
+	buckets map[string]*bucket
+	sweptAt time.Time
+
+	clock clock.Clock
+}
+
+// New создаёт Limiter с ограничениями rules, время запросов берётся из clk.
+func New(clk clock.Clock, rules Rules) *Limiter {
+	return &Limiter{
+		rules:   rules,
+		buckets: map[string]*bucket{},
+		clock:   clk,
+	}
+}
+
+// SetRules заменяет ограничения на rules. Накопленная статистика запросов сбрасывается.
+func (l *Limiter) SetRules(rules Rules) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	l.rules = rules
+	l.buckets = map[string]*bucket{}
+}
+
+// Allow учитывает запрос к методу method по ключу key.
+// Возвращает false и время, через которое возможно повторить запрос, если ограничение превышено.
+func (l *Limiter) Allow(method string, key string) (bool, time.Duration) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	name, rule := l.rule(method)
+	if rule.Unlimited() {
+		return true, 0
+	}
+
+	now := l.clock.Now()
+	l.sweep(now)
+
+	bucketKey := name + "|" + key
+	b, ok := l.buckets[bucketKey]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(rule.Rate), max(rule.Burst, 1))}
+		l.buckets[bucketKey] = b
+	}
+	b.lastSeen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// rule находит ограничение для метода method.
+// Возвращает имя ограничения (метод из Rules.Methods или "" для ограничения по умолчанию) и само ограничение.
+func (l *Limiter) rule(method string) (string, Rule) {
+	if rule, ok := l.rules.Methods[method]; ok {
+		return method, rule
+	}
+
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		name := method[i+1:]
+		if rule, ok := l.rules.Methods[name]; ok {
+			return name, rule
+		}
+	}
+
+	return "", l.rules.Default
+}
+
+// sweep удаляет bucket'ы, которые не использовались дольше idleTTL.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.sweptAt) < sweepInterval {
+		return
+	}
+	l.sweptAt = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Key возвращает ключ учёта запросов: OwnerID из контекста ctx, если есть, иначе адрес клиента remoteAddr.
+func Key(ctx context.Context, remoteAddr string) string {
+	if ownerID, err := auth.OwnerIDFromContext(ctx); err == nil {
+		return "owner:" + string(ownerID)
+	}
+
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+
+	return "addr:" + remoteAddr
+}
+
+// RetryAfter возвращает значение заголовка Retry-After (в целых секундах, не менее 1) для задержки d.
+func RetryAfter(d time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(d.Seconds())), 1))
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/grpc/auth"
+)
+
+const getMonthEvents = "/event.v1.EventService/GetMonthEvents"
+
+// newTestLimiter создаёт Limiter с управляемым временем.
+func newTestLimiter(rules Rules) (*Limiter, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	return New(clk, rules), clk
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	l, clk := newTestLimiter(Rules{
+		Default: Rule{Rate: 10, Burst: 2},
+	})
+
+	for i := 0; i < 2; i++ {
+		ok, _ := l.Allow(getMonthEvents, "owner:1")
+		require.True(t, ok, "must allow burst")
+	}
+
+	ok, retryAfter := l.Allow(getMonthEvents, "owner:1")
+	require.False(t, ok, "must limit after burst")
+	require.Equal(t, 100*time.Millisecond, retryAfter, "must wait for one token")
+
+	ok, _ = l.Allow(getMonthEvents, "owner:2")
+	require.True(t, ok, "must count keys separately")
+
+	clk.Advance(100 * time.Millisecond)
+	ok, _ = l.Allow(getMonthEvents, "owner:1")
+	require.True(t, ok, "must allow after retry delay")
+}
+
+func TestLimiter_Methods(t *testing.T) {
+	l, _ := newTestLimiter(Rules{
+		Default: Rule{Rate: 100, Burst: 100},
+		Methods: map[string]Rule{
+			"GetMonthEvents":                     {Rate: 1, Burst: 1},
+			"/event.v1.EventService/DeleteEvent": {Rate: 0},
+		},
+	})
+
+	ok, _ := l.Allow(getMonthEvents, "owner:1")
+	require.True(t, ok, "must allow first request")
+
+	ok, retryAfter := l.Allow(getMonthEvents, "owner:1")
+	require.False(t, ok, "must use method rule by name")
+	require.Equal(t, time.Second, retryAfter, "must wait for one token")
+
+	ok, _ = l.Allow("/event.v1.EventService/GetDayEvents", "owner:1")
+	require.True(t, ok, "must use default rule")
+
+	for i := 0; i < 200; i++ {
+		ok, _ = l.Allow("/event.v1.EventService/DeleteEvent", "owner:1")
+		require.True(t, ok, "must not limit method with zero rate")
+	}
+}
+
+func TestLimiter_SetRules(t *testing.T) {
+	l, _ := newTestLimiter(Rules{})
+
+	for i := 0; i < 100; i++ {
+		ok, _ := l.Allow(getMonthEvents, "owner:1")
+		require.True(t, ok, "must not limit without rules")
+	}
+
+	l.SetRules(Rules{Default: Rule{Rate: 1}})
+
+	ok, _ := l.Allow(getMonthEvents, "owner:1")
+	require.True(t, ok, "must allow first request with zero burst")
+
+	ok, _ = l.Allow(getMonthEvents, "owner:1")
+	require.False(t, ok, "must apply new rules")
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	l, clk := newTestLimiter(Rules{Default: Rule{Rate: 1, Burst: 1}})
+
+	l.Allow(getMonthEvents, "owner:1")
+	require.Len(t, l.buckets, 1, "must create bucket")
+
+	clk.Advance(idleTTL + time.Second)
+	l.Allow(getMonthEvents, "owner:2")
+	require.Len(t, l.buckets, 1, "must remove idle bucket")
+}
+
+func TestKey(t *testing.T) {
+	ctx, err := auth.WithOwnerID(context.Background(), "d5a6b68e-1c6d-4b6e-9a43-97a5b9e06b0f")
+	require.NoError(t, err, "must set owner")
+
+	require.Equal(t, "owner:d5a6b68e-1c6d-4b6e-9a43-97a5b9e06b0f", Key(ctx, "127.0.0.1:1234"), "must use owner")
+	require.Equal(t, "addr:127.0.0.1", Key(context.Background(), "127.0.0.1:1234"), "must use remote host")
+	require.Equal(t, "addr:bufconn", Key(context.Background(), "bufconn"), "must use remote address")
+}
+
+func TestRetryAfter(t *testing.T) {
+	require.Equal(t, "1", RetryAfter(0), "must be at least one second")
+	require.Equal(t, "1", RetryAfter(100*time.Millisecond), "must round up")
+	require.Equal(t, "3", RetryAfter(2001*time.Millisecond), "must round up")
+}
@@ -0,0 +1,68 @@
+// requestid - идентификатор запроса для сквозной корреляции логов.
+//
+// Идентификатор берётся из заголовка X-Request-Id (метаданных x-request-id для grpc) или генерируется,
+// сохраняется в контексте запроса и возвращается клиенту в заголовке ответа.
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header - http-заголовок с идентификатором запроса.
+	Header = "X-Request-Id"
+
+	// MetadataKey - ключ метаданных grpc с идентификатором запроса.
+	MetadataKey = "x-request-id"
+
+	// maxLength - максимальная длина идентификатора, полученного от клиента.
+	maxLength = 128
+)
+
+type key int
+
+const requestIDKey = key(1)
+
+// New генерирует новый идентификатор запроса.
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid проверяет, что идентификатор id, полученный от клиента, допустимо использовать:
+// не пустой, не длиннее maxLength и состоит только из печатных ASCII-символов без пробелов.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Ensure возвращает id, если он допустим, иначе генерирует новый идентификатор.
+func Ensure(id string) string {
+	if Valid(id) {
+		return id
+	}
+
+	return New()
+}
+
+// WithID сохраняет идентификатор запроса id в контексте.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// FromContext возвращает идентификатор запроса из контекста, если есть.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+
+	return id, ok
+}
@@ -0,0 +1,46 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{id: "b3b4a1e2-5f0c-4f0e-9d4b-3c1b2a7e8f90", valid: true},
+		{id: "req_123", valid: true},
+		{id: "", valid: false},
+		{id: "with space", valid: false},
+		{id: "line\nbreak", valid: false},
+		{id: "юникод", valid: false},
+		{id: strings.Repeat("a", maxLength), valid: true},
+		{id: strings.Repeat("a", maxLength+1), valid: false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.valid, Valid(tt.id), "id %q", tt.id)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	require.Equal(t, "req_123", Ensure("req_123"), "must keep valid id")
+
+	id := Ensure("invalid id")
+	require.NotEqual(t, "invalid id", id, "must generate new id")
+	require.True(t, Valid(id), "generated id must be valid")
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok, "must not have id")
+
+	id, ok := FromContext(WithID(context.Background(), "req_123"))
+	require.True(t, ok, "must have id")
+	require.Equal(t, "req_123", id, "must be equal")
+}
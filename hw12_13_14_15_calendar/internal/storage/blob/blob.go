@@ -0,0 +1,86 @@
+// storage/blob - хранилище содержимого файловых вложений событий.
+//
+// Содержимое хранится по ключам вида "<tenant>/<owner>/<event>/<attachment>", поэтому все файлы
+// события или рабочего пространства удаляются одним вызовом DeletePrefix.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+var (
+	ErrBlobNotFound = domainerr.New(domainerr.KindNotFound, "FILE_NOT_FOUND", "file not found")
+	ErrBlobTooLarge = domainerr.NewField("content", "FILE_TOO_LARGE", "file is too large")
+)
+
+// Store - интерфейс хранилища содержимого файлов.
+type Store interface {
+	// Put сохраняет содержимое r по ключу key, перезаписывая существующее.
+	// Если содержимое больше maxSize байт, ничего не сохраняет и возвращает ErrBlobTooLarge.
+	// Возвращает размер сохранённого содержимого.
+	Put(ctx context.Context, key string, r io.Reader, maxSize int64) (int64, error)
+
+	// Open открывает содержимое по ключу key для чтения, либо возвращает ErrBlobNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete удаляет содержимое по ключу key. Отсутствие ключа не является ошибкой.
+	Delete(ctx context.Context, key string) error
+
+	// DeletePrefix удаляет содержимое всех ключей, начинающихся с prefix/.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// TenantPrefix возвращает префикс ключей всех файлов рабочего пространства tenantID.
+func TenantPrefix(tenantID model.TenantID) string {
+	return string(tenantID)
+}
+
+// EventPrefix возвращает префикс ключей всех файлов события.
+func EventPrefix(tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) string {
+	return path.Join(string(tenantID), string(ownerID), string(eventID))
+}
+
+// AttachmentKey возвращает ключ содержимого файлового вложения attachmentID события.
+func AttachmentKey(
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+	attachmentID model.AttachmentID,
+) string {
+	return path.Join(EventPrefix(tenantID, ownerID, eventID), string(attachmentID))
+}
+
+// DeleteEvents удаляет файлы вложений событий events.
+// Удаляет файлы всех событий, даже если для некоторых из них произошла ошибка.
+func DeleteEvents(ctx context.Context, store Store, events []model.Event) error {
+	var errs []error
+
+	for _, event := range events {
+		if !hasFiles(event) {
+			continue
+		}
+
+		err := store.DeletePrefix(ctx, EventPrefix(event.TenantID(), event.OwnerID(), event.EventID()))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func hasFiles(event model.Event) bool {
+	for _, a := range event.Attachments {
+		if a.IsFile() {
+			return true
+		}
+	}
+
+	return false
+}
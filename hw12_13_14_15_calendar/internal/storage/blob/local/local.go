@@ -0,0 +1,132 @@
+// storage/blob/local - хранилище содержимого файлов в локальной файловой системе.
+//
+// Содержимое ключа хранится в файле dir/<key>. Запись идёт во временный файл,
+// который переименовывается только после успешной записи всего содержимого.
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+type Store struct {
+	dir string
+}
+
+var _ blob.Store = (*Store)(nil)
+
+// NewStore создаёт хранилище в директории dir.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("can't create blob dir: %w", err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) Put(_ context.Context, key string, r io.Reader, maxSize int64) (_ int64, err error) {
+	name, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o700); err != nil {
+		return 0, fmt.Errorf("can't create blob dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("can't create blob file: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	n, err := io.Copy(tmp, io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return 0, fmt.Errorf("can't write blob: %w", err)
+	}
+
+	if n > maxSize {
+		return 0, blob.ErrBlobTooLarge
+	}
+
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("can't write blob: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return 0, fmt.Errorf("can't save blob: %w", err)
+	}
+
+	return n, nil
+}
+
+func (s *Store) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, blob.ErrBlobNotFound
+		}
+
+		return nil, fmt.Errorf("can't open blob: %w", err)
+	}
+
+	return f, nil
+}
+
+func (s *Store) Delete(_ context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("can't delete blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) DeletePrefix(_ context.Context, prefix string) error {
+	name, err := s.path(prefix)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(name); err != nil {
+		return fmt.Errorf("can't delete blobs: %w", err)
+	}
+
+	return nil
+}
+
+// path возвращает путь к файлу ключа key.
+// Ключ должен быть относительным путём без '.' и '..' элементов.
+func (s *Store) path(key string) (string, error) {
+	if key == "" || key == "." || path.Clean(key) != key || path.IsAbs(key) || strings.HasPrefix(key, "..") ||
+		strings.Contains(key, "\\") {
+		return "", fmt.Errorf("%w: '%s'", ErrInvalidKey, key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,82 @@
+package local
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/blob"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s, err := NewStore(dir)
+	require.NoError(t, err, "must create store")
+
+	read := func(t *testing.T, key string) string {
+		t.Helper()
+
+		r, err := s.Open(ctx, key)
+		require.NoError(t, err, "must open blob")
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err, "must read blob")
+
+		return string(data)
+	}
+
+	t.Run("put", func(t *testing.T) {
+		n, err := s.Put(ctx, "t/o/e1/a1", strings.NewReader("agenda"), 6)
+		require.NoError(t, err, "must put blob")
+		require.Equal(t, int64(6), n, "must return size")
+		require.Equal(t, "agenda", read(t, "t/o/e1/a1"))
+
+		_, err = s.Put(ctx, "t/o/e1/a1", strings.NewReader("new agenda"), 6)
+		require.ErrorIs(t, err, blob.ErrBlobTooLarge, "must not put too large blob")
+		require.Equal(t, "agenda", read(t, "t/o/e1/a1"), "must keep previous content")
+
+		entries, err := os.ReadDir(filepath.Join(dir, "t", "o", "e1"))
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "must remove temporary file")
+	})
+
+	t.Run("open", func(t *testing.T) {
+		_, err := s.Open(ctx, "t/o/e1/unknown")
+		require.ErrorIs(t, err, blob.ErrBlobNotFound, "must be ErrBlobNotFound")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		for _, key := range []string{"", ".", "..", "../x", "/x", "t/../x", "t//x", "t\\x"} {
+			_, err := s.Put(ctx, key, strings.NewReader(""), 1)
+			require.ErrorIsf(t, err, ErrInvalidKey, "key '%s' must be invalid", key)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := s.Put(ctx, "t/o/e1/a2", strings.NewReader("2"), 1)
+		require.NoError(t, err)
+		_, err = s.Put(ctx, "t/o/e2/a1", strings.NewReader("3"), 1)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Delete(ctx, "t/o/e1/a2"), "must delete blob")
+		require.NoError(t, s.Delete(ctx, "t/o/e1/a2"), "must not fail on unknown blob")
+
+		_, err = s.Open(ctx, "t/o/e1/a2")
+		require.ErrorIs(t, err, blob.ErrBlobNotFound, "must delete blob")
+
+		require.NoError(t, s.DeletePrefix(ctx, "t/o/e1"), "must delete blobs by prefix")
+		require.NoError(t, s.DeletePrefix(ctx, "t/o/e1"), "must not fail on unknown prefix")
+
+		_, err = s.Open(ctx, "t/o/e1/a1")
+		require.ErrorIs(t, err, blob.ErrBlobNotFound, "must delete blobs by prefix")
+		require.Equal(t, "3", read(t, "t/o/e2/a1"), "must keep blobs of other prefix")
+	})
+}
@@ -0,0 +1,62 @@
+package event
+
+import (
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// Attachment - вложение события в виде, в котором оно сохраняется хранилищами (JSON).
+type Attachment struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	URL         string `json:"url,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// ToAttachments возвращает вложения attachments для сохранения, nil для пустого списка.
+func ToAttachments(attachments []model.Attachment) []Attachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	result := make([]Attachment, len(attachments))
+	for i, a := range attachments {
+		result[i] = Attachment{
+			ID:          string(a.ID),
+			Title:       a.Title,
+			URL:         a.URL,
+			FileName:    a.FileName,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+		}
+	}
+
+	return result
+}
+
+// ToAttachmentModels возвращает сохранённые вложения attachments, nil для пустого списка.
+func ToAttachmentModels(attachments []Attachment) ([]model.Attachment, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+
+	result := make([]model.Attachment, len(attachments))
+	for i, a := range attachments {
+		id, err := model.NewAttachmentIDFromString(a.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = model.Attachment{
+			ID:          id,
+			Title:       a.Title,
+			URL:         a.URL,
+			FileName:    a.FileName,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package event
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type (
+	readYourWritesKey struct{}
+	primaryKey        struct{}
+)
+
+// writeTracker отмечает, была ли запись в хранилище в рамках контекста.
+type writeTracker struct {
+	written atomic.Bool
+}
+
+// WithReadYourWrites возвращает контекст, в рамках которого хранилище отслеживает запись:
+// после первой записи все последующие чтения с этим контекстом выполняются из основной базы,
+// а не из реплик, чтобы сразу увидеть результат записи.
+//
+// Обычно вызывается один раз на входящий запрос.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(readYourWritesKey{}).(*writeTracker); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, readYourWritesKey{}, &writeTracker{})
+}
+
+// WithPrimary возвращает контекст, чтение с которым всегда выполняется из основной базы.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// MarkWritten отмечает запись в хранилище в рамках контекста ctx, созданного WithReadYourWrites.
+// Вызывается хранилищем после успешной записи.
+func MarkWritten(ctx context.Context) {
+	if tracker, ok := ctx.Value(readYourWritesKey{}).(*writeTracker); ok {
+		tracker.written.Store(true)
+	}
+}
+
+// ReadFromPrimary сообщает, должно ли чтение с контекстом ctx выполняться из основной базы:
+// контекст создан WithPrimary или в рамках WithReadYourWrites уже была запись.
+func ReadFromPrimary(ctx context.Context) bool {
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return true
+	}
+
+	tracker, ok := ctx.Value(readYourWritesKey{}).(*writeTracker)
+
+	return ok && tracker.written.Load()
+}
@@ -0,0 +1,292 @@
+package file
+
+import (
+	"time"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// fileEvent - событие в журнале и снимке хранилища.
+type fileEvent struct {
+	// TenantID - рабочее пространство события, пусто в данных, записанных до появления пространств.
+	TenantID     string    `json:"tenantId,omitempty"`
+	EventID      string    `json:"eventId"`
+	OwnerID      string    `json:"ownerId"`
+	StartAt      time.Time `json:"startAt"`
+	EndAt        time.Time `json:"endAt"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	NotifyBefore uint      `json:"notifyBefore,omitempty"`
+	Labels       []string  `json:"labels,omitempty"`
+	Color        string    `json:"color,omitempty"`
+
+	Attachments []storage.Attachment `json:"attachments,omitempty"`
+
+	Location      *fileLocation `json:"location,omitempty"`
+	ConferenceURL string        `json:"conferenceUrl,omitempty"`
+}
+
+// fileLocation - место проведения события в журнале и снимке хранилища.
+type fileLocation struct {
+	Name    string     `json:"name,omitempty"`
+	Address string     `json:"address,omitempty"`
+	Point   *filePoint `json:"point,omitempty"`
+}
+
+type filePoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// fileLabel - метка каталога владельца в журнале и снимке хранилища.
+type fileLabel struct {
+	TenantID string `json:"tenantId"`
+	OwnerID  string `json:"ownerId"`
+	Name     string `json:"name"`
+	Color    string `json:"color,omitempty"`
+}
+
+// fileProfile - профиль доступности владельца в журнале и снимке хранилища.
+type fileProfile struct {
+	TenantID string `json:"tenantId"`
+	OwnerID  string `json:"ownerId"`
+
+	storage.Profile
+}
+
+// fileFeedToken - ссылка на календарь владельца в журнале и снимке хранилища.
+type fileFeedToken struct {
+	TenantID  string    `json:"tenantId"`
+	OwnerID   string    `json:"ownerId"`
+	TokenID   string    `json:"tokenId"`
+	Hash      string    `json:"hash"`
+	Name      string    `json:"name,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func toFileEvent(event model.Event) *fileEvent {
+	return &fileEvent{
+		TenantID:     string(event.TenantID()),
+		EventID:      string(event.EventID()),
+		OwnerID:      string(event.OwnerID()),
+		StartAt:      event.StartAt(),
+		EndAt:        event.EndAt(),
+		Title:        string(event.Title),
+		Description:  event.Description,
+		NotifyBefore: event.NotifyBefore,
+		Labels:       labelsToStrings(event.Labels),
+		Color:        string(event.Color),
+		Attachments:  storage.ToAttachments(event.Attachments),
+
+		Location:      toFileLocation(event.Location),
+		ConferenceURL: string(event.ConferenceURL),
+	}
+}
+
+// toFileLocation возвращает место location для сохранения, nil если место не задано.
+func toFileLocation(location model.Location) *fileLocation {
+	if location.IsZero() {
+		return nil
+	}
+
+	l := &fileLocation{
+		Name:    location.Name,
+		Address: location.Address,
+	}
+
+	if location.Point != nil {
+		l.Point = &filePoint{Latitude: location.Point.Latitude, Longitude: location.Point.Longitude}
+	}
+
+	return l
+}
+
+// toLocationModel возвращает сохранённое место l, пустое значение для nil.
+func toLocationModel(l *fileLocation) (model.Location, error) {
+	if l == nil {
+		return model.Location{}, nil
+	}
+
+	var point *model.Point
+	if l.Point != nil {
+		p, err := model.NewPoint(l.Point.Latitude, l.Point.Longitude)
+		if err != nil {
+			return model.Location{}, err
+		}
+
+		point = &p
+	}
+
+	return model.NewLocation(l.Name, l.Address, point)
+}
+
+// labelsToStrings возвращает метки labels как строки, nil для пустого списка.
+func labelsToStrings(labels []model.Label) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	result := make([]string, len(labels))
+	for i, label := range labels {
+		result[i] = string(label)
+	}
+
+	return result
+}
+
+func toModel(ev *fileEvent) (model.Event, error) {
+	tenantID, err := toTenantID(ev.TenantID)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	eventID, err := model.NewIDFromString(ev.EventID)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(ev.OwnerID)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	title, err := model.NewTitle(ev.Title)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event, err := model.NewEvent(tenantID, eventID, ownerID, title, ev.StartAt, ev.EndAt)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Labels, err = model.NewLabels(ev.Labels)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Color, err = model.NewColor(ev.Color)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Attachments, err = storage.ToAttachmentModels(ev.Attachments)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Location, err = toLocationModel(ev.Location)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.ConferenceURL, err = model.NewConferenceURL(ev.ConferenceURL)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Description = ev.Description
+	event.NotifyBefore = ev.NotifyBefore
+
+	return event, nil
+}
+
+func toFileLabel(label model.OwnerLabel) *fileLabel {
+	return &fileLabel{
+		TenantID: string(label.TenantID()),
+		OwnerID:  string(label.OwnerID()),
+		Name:     string(label.Name()),
+		Color:    string(label.Color),
+	}
+}
+
+func toLabelModel(l *fileLabel) (model.OwnerLabel, error) {
+	tenantID, err := model.NewTenantIDFromString(l.TenantID)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(l.OwnerID)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	name, err := model.NewLabel(l.Name)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	color, err := model.NewColor(l.Color)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	return model.NewOwnerLabel(tenantID, ownerID, name, color), nil
+}
+
+func toFileProfile(profile model.Profile) *fileProfile {
+	return &fileProfile{
+		TenantID: string(profile.TenantID()),
+		OwnerID:  string(profile.OwnerID()),
+		Profile:  storage.ToProfile(profile),
+	}
+}
+
+func toProfileModel(p *fileProfile) (model.Profile, error) {
+	tenantID, err := model.NewTenantIDFromString(p.TenantID)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(p.OwnerID)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	return storage.ToProfileModel(tenantID, ownerID, p.Profile)
+}
+
+func toFileFeedToken(token model.FeedToken) *fileFeedToken {
+	return &fileFeedToken{
+		TenantID:  string(token.TenantID()),
+		OwnerID:   string(token.OwnerID()),
+		TokenID:   string(token.TokenID()),
+		Hash:      string(token.Hash()),
+		Name:      token.Name,
+		CreatedAt: token.CreatedAt,
+	}
+}
+
+func toFeedTokenModel(t *fileFeedToken) (model.FeedToken, error) {
+	tenantID, err := model.NewTenantIDFromString(t.TenantID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(t.OwnerID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	tokenID, err := model.NewFeedTokenIDFromString(t.TokenID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	token := model.NewFeedToken(tenantID, ownerID, tokenID, model.FeedTokenHash(t.Hash))
+	token.Name = t.Name
+	token.CreatedAt = t.CreatedAt
+
+	return token, nil
+}
+
+// toTenantID возвращает рабочее пространство tenantID из журнала или снимка:
+// пустое значение (данные, записанные до появления пространств) - пространство по умолчанию.
+func toTenantID(tenantID string) (model.TenantID, error) {
+	if tenantID == "" {
+		return model.DefaultTenantID, nil
+	}
+
+	return model.NewTenantIDFromString(tenantID)
+}
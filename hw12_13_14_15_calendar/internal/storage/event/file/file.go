@@ -0,0 +1,874 @@
+// storage/event/file - хранилище событий в памяти с сохранением изменений на диск.
+//
+// Все изменения хранилища записываются в журнал (write-ahead log), периодически состояние хранилища
+// сохраняется в снимок, после чего журнал очищается.
+// При запуске состояние восстанавливается из последнего снимка и журнала.
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/memory"
+)
+
+const (
+	walFileName      = "events.wal"
+	snapshotFileName = "events.snapshot"
+	lockFileName     = "events.lock"
+)
+
+// SyncPolicy - политика сброса журнала на диск.
+type SyncPolicy string
+
+var (
+	// SyncAlways - журнал сбрасывается на диск после каждой записи.
+	SyncAlways SyncPolicy = "always"
+
+	// SyncInterval - журнал сбрасывается на диск периодически, раз в Options.SyncInterval.
+	SyncInterval SyncPolicy = "interval"
+
+	// SyncNever - сброс журнала на диск остаётся на усмотрение ОС.
+	SyncNever SyncPolicy = "never"
+)
+
+var ErrClosed = errors.New("storage is closed")
+
+// Options - параметры файлового хранилища.
+type Options struct {
+	// Dir - директория с файлами хранилища.
+	Dir string
+
+	// Sync - политика сброса журнала на диск, по умолчанию SyncAlways.
+	Sync SyncPolicy
+
+	// SyncInterval - период сброса журнала на диск для SyncInterval.
+	SyncInterval time.Duration
+
+	// SnapshotInterval - период создания снимка, 0 - не создавать периодически.
+	SnapshotInterval time.Duration
+
+	// SnapshotThreshold - количество записей в журнале, после которого создаётся снимок, 0 - без ограничения.
+	SnapshotThreshold int
+
+	// Logger - логгер для ошибок фоновых задач.
+	Logger *slog.Logger
+
+	// Clock - часы для периодических задач, по умолчанию системные.
+	Clock clock.Clock
+}
+
+type Storage struct {
+	opts Options
+	mem  *memory.Storage
+
+	lock *os.File
+	wal  *wal
+
+	// seq - номер последней записи журнала.
+	seq uint64
+
+	// walRecords - количество записей в журнале с момента последнего снимка.
+	walRecords int
+
+	// dirty - в журнале есть записи, не сброшенные на диск.
+	dirty bool
+
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+	mx     sync.Mutex
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// NewStorage открывает файловое хранилище в директории opts.Dir и восстанавливает его состояние.
+// Директория может использоваться только одним хранилищем одновременно.
+func NewStorage(opts Options) (_ *Storage, err error) {
+	if opts.Sync == "" {
+		opts.Sync = SyncAlways
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = clock.Real{}
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("can't create storage dir: %w", err)
+	}
+
+	s := &Storage{
+		opts: opts,
+		mem:  memory.NewStorage(),
+		done: make(chan struct{}),
+	}
+
+	defer func() {
+		if err != nil {
+			s.closeFiles()
+		}
+	}()
+
+	s.lock, err = os.OpenFile(filepath.Join(opts.Dir, lockFileName), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("can't open lock file: %w", err)
+	}
+
+	if err := lockFile(s.lock); err != nil {
+		return nil, fmt.Errorf("storage dir is used by another process: %w", err)
+	}
+
+	if err := s.recover(); err != nil {
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.background()
+
+	return s, nil
+}
+
+// recover восстанавливает состояние хранилища из снимка и журнала.
+func (s *Storage) recover() error {
+	ctx := context.Background()
+
+	snap, err := readSnapshot(filepath.Join(s.opts.Dir, snapshotFileName))
+	if err != nil {
+		return err
+	}
+
+	// метки восстанавливаются первыми: события ссылаются на метки каталога
+	for _, l := range snap.Labels {
+		label, err := toLabelModel(l)
+		if err != nil {
+			return fmt.Errorf("invalid label in snapshot: %w", err)
+		}
+
+		if err := s.mem.AddLabel(ctx, label); err != nil {
+			return fmt.Errorf("can't restore label from snapshot: %w", err)
+		}
+	}
+
+	for _, p := range snap.Profiles {
+		profile, err := toProfileModel(p)
+		if err != nil {
+			return fmt.Errorf("invalid profile in snapshot: %w", err)
+		}
+
+		if err := s.mem.SaveProfile(ctx, profile); err != nil {
+			return fmt.Errorf("can't restore profile from snapshot: %w", err)
+		}
+	}
+
+	for _, ev := range snap.Events {
+		event, err := toModel(ev)
+		if err != nil {
+			return fmt.Errorf("invalid event in snapshot: %w", err)
+		}
+
+		if err := s.mem.AddEvent(ctx, event); err != nil {
+			return fmt.Errorf("can't restore event from snapshot: %w", err)
+		}
+	}
+
+	for _, t := range snap.FeedTokens {
+		token, err := toFeedTokenModel(t)
+		if err != nil {
+			return fmt.Errorf("invalid feed token in snapshot: %w", err)
+		}
+
+		if err := s.mem.AddFeedToken(ctx, token, 0); err != nil {
+			return fmt.Errorf("can't restore feed token from snapshot: %w", err)
+		}
+	}
+
+	s.seq = snap.Seq
+
+	s.wal, err = openWAL(filepath.Join(s.opts.Dir, walFileName))
+	if err != nil {
+		return fmt.Errorf("can't open wal: %w", err)
+	}
+
+	n, err := s.wal.Replay(func(rec walRecord) error {
+		// запись уже учтена в снимке: сбой произошёл после создания снимка, но до очистки журнала
+		if rec.Seq <= snap.Seq {
+			return nil
+		}
+
+		s.seq = rec.Seq
+
+		return s.apply(ctx, rec)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.walRecords = n
+
+	return nil
+}
+
+// apply применяет запись журнала rec к хранилищу в памяти.
+func (s *Storage) apply(ctx context.Context, rec walRecord) error {
+	switch rec.Op {
+	case opAdd, opUpdate:
+		if rec.Event == nil {
+			return fmt.Errorf("no event for '%s' operation", rec.Op)
+		}
+
+		event, err := toModel(rec.Event)
+		if err != nil {
+			return err
+		}
+
+		if rec.Op == opAdd {
+			return s.mem.AddEvent(ctx, event)
+		}
+
+		return s.mem.UpdateEvent(ctx, event)
+	case opDelete:
+		tenantID, err := toTenantID(rec.TenantID)
+		if err != nil {
+			return err
+		}
+
+		return s.mem.DeleteEvent(ctx, tenantID, model.OwnerID(rec.OwnerID), model.ID(rec.EventID))
+	case opWipeTenant:
+		tenantID, err := toTenantID(rec.TenantID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.mem.DeleteTenantEvents(ctx, tenantID)
+
+		return err
+	case opPurge:
+		_, err := s.mem.PurgeOldEvents(ctx, rec.OlderThan)
+
+		return err
+	case opAddLabel, opUpdateLabel, opDeleteLabel:
+		if rec.Label == nil {
+			return fmt.Errorf("no label for '%s' operation", rec.Op)
+		}
+
+		label, err := toLabelModel(rec.Label)
+		if err != nil {
+			return err
+		}
+
+		switch rec.Op {
+		case opAddLabel:
+			return s.mem.AddLabel(ctx, label)
+		case opUpdateLabel:
+			return s.mem.UpdateLabel(ctx, label)
+		default:
+			return s.mem.DeleteLabel(ctx, label.TenantID(), label.OwnerID(), label.Name())
+		}
+	case opSaveProfile:
+		if rec.Profile == nil {
+			return fmt.Errorf("no profile for '%s' operation", rec.Op)
+		}
+
+		profile, err := toProfileModel(rec.Profile)
+		if err != nil {
+			return err
+		}
+
+		return s.mem.SaveProfile(ctx, profile)
+	case opAddFeedToken:
+		if rec.FeedToken == nil {
+			return fmt.Errorf("no feed token for '%s' operation", rec.Op)
+		}
+
+		token, err := toFeedTokenModel(rec.FeedToken)
+		if err != nil {
+			return err
+		}
+
+		return s.mem.AddFeedToken(ctx, token, 0)
+	case opDeleteFeedToken:
+		tenantID, err := toTenantID(rec.TenantID)
+		if err != nil {
+			return err
+		}
+
+		return s.mem.DeleteFeedToken(ctx, tenantID, model.OwnerID(rec.OwnerID), model.FeedTokenID(rec.TokenID))
+	default:
+		return fmt.Errorf("unknown operation '%s'", rec.Op)
+	}
+}
+
+func (s *Storage) AddEvent(ctx context.Context, event model.Event) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	if err := s.mem.AddEvent(ctx, event); err != nil {
+		return err
+	}
+
+	if err := s.log(walRecord{Op: opAdd, Event: toFileEvent(event)}); err != nil {
+		revertErr := s.mem.DeleteEvent(context.WithoutCancel(ctx), event.TenantID(), event.OwnerID(), event.EventID())
+		if revertErr != nil {
+			err = errors.Join(err, fmt.Errorf("can't revert added event: %w", revertErr))
+		}
+
+		return err
+	}
+
+	s.snapshotOnThreshold()
+
+	return nil
+}
+
+func (s *Storage) UpdateEvent(ctx context.Context, event model.Event) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	oldEvent, err := s.mem.FindEvent(ctx, event.TenantID(), event.OwnerID(), event.EventID())
+	if err != nil {
+		return err
+	}
+
+	if err := s.mem.UpdateEvent(ctx, event); err != nil {
+		return err
+	}
+
+	if err := s.log(walRecord{Op: opUpdate, Event: toFileEvent(event)}); err != nil {
+		if revertErr := s.mem.UpdateEvent(context.WithoutCancel(ctx), oldEvent); revertErr != nil {
+			err = errors.Join(err, fmt.Errorf("can't revert updated event: %w", revertErr))
+		}
+
+		return err
+	}
+
+	s.snapshotOnThreshold()
+
+	return nil
+}
+
+func (s *Storage) FindEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (model.Event, error) {
+	return s.mem.FindEvent(ctx, tenantID, ownerID, eventID)
+}
+
+func (s *Storage) DeleteEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	oldEvent, err := s.mem.FindEvent(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mem.DeleteEvent(ctx, tenantID, ownerID, eventID); err != nil {
+		return err
+	}
+
+	rec := walRecord{Op: opDelete, TenantID: string(tenantID), OwnerID: string(ownerID), EventID: string(eventID)}
+	if err := s.log(rec); err != nil {
+		if revertErr := s.mem.AddEvent(context.WithoutCancel(ctx), oldEvent); revertErr != nil {
+			err = errors.Join(err, fmt.Errorf("can't revert deleted event: %w", revertErr))
+		}
+
+		return err
+	}
+
+	s.snapshotOnThreshold()
+
+	return nil
+}
+
+func (s *Storage) QueryEvents(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	filter model.Filter,
+) ([]model.Event, error) {
+	return s.mem.QueryEvents(ctx, tenantID, ownerID, from, to, filter)
+}
+
+func (s *Storage) QueryEventsNear(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	area model.Area,
+) ([]model.Event, error) {
+	return s.mem.QueryEventsNear(ctx, tenantID, ownerID, from, to, area)
+}
+
+func (s *Storage) AddLabel(ctx context.Context, label model.OwnerLabel) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	if err := s.mem.AddLabel(ctx, label); err != nil {
+		return err
+	}
+
+	if err := s.log(walRecord{Op: opAddLabel, Label: toFileLabel(label)}); err != nil {
+		// новая метка ещё не может быть у событий, поэтому её удаление не затрагивает события
+		revertErr := s.mem.DeleteLabel(context.WithoutCancel(ctx), label.TenantID(), label.OwnerID(), label.Name())
+		if revertErr != nil {
+			err = errors.Join(err, fmt.Errorf("can't revert added label: %w", revertErr))
+		}
+
+		return err
+	}
+
+	s.snapshotOnThreshold()
+
+	return nil
+}
+
+func (s *Storage) UpdateLabel(ctx context.Context, label model.OwnerLabel) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	oldLabel, err := s.mem.FindLabel(ctx, label.TenantID(), label.OwnerID(), label.Name())
+	if err != nil {
+		return err
+	}
+
+	if err := s.mem.UpdateLabel(ctx, label); err != nil {
+		return err
+	}
+
+	if err := s.log(walRecord{Op: opUpdateLabel, Label: toFileLabel(label)}); err != nil {
+		if revertErr := s.mem.UpdateLabel(context.WithoutCancel(ctx), oldLabel); revertErr != nil {
+			err = errors.Join(err, fmt.Errorf("can't revert updated label: %w", revertErr))
+		}
+
+		return err
+	}
+
+	s.snapshotOnThreshold()
+
+	return nil
+}
+
+func (s *Storage) DeleteLabel(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name model.Label,
+) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	label, err := s.mem.FindLabel(ctx, tenantID, ownerID, name)
+	if err != nil {
+		return err
+	}
+
+	// удаление метки снимает её с событий, такое изменение сложно отменить,
+	// поэтому сначала записываем операцию в журнал (метка точно есть - удаление не завершится ошибкой)
+	if err := s.log(walRecord{Op: opDeleteLabel, Label: toFileLabel(label)}); err != nil {
+		return err
+	}
+
+	err = s.mem.DeleteLabel(context.WithoutCancel(ctx), tenantID, ownerID, name)
+	s.snapshotOnThreshold()
+
+	return err
+}
+
+func (s *Storage) QueryLabels(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) ([]model.OwnerLabel, error) {
+	return s.mem.QueryLabels(ctx, tenantID, ownerID)
+}
+
+func (s *Storage) FindProfile(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (model.Profile, error) {
+	return s.mem.FindProfile(ctx, tenantID, ownerID)
+}
+
+func (s *Storage) SaveProfile(ctx context.Context, profile model.Profile) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// сохранение профиля в памяти не может завершиться ошибкой,
+	// поэтому сначала записываем операцию в журнал
+	if err := s.log(walRecord{Op: opSaveProfile, Profile: toFileProfile(profile)}); err != nil {
+		return err
+	}
+
+	err := s.mem.SaveProfile(context.WithoutCancel(ctx), profile)
+	s.snapshotOnThreshold()
+
+	return err
+}
+
+func (s *Storage) AddFeedToken(ctx context.Context, token model.FeedToken, maxTokens int) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	if err := s.mem.AddFeedToken(ctx, token, maxTokens); err != nil {
+		return err
+	}
+
+	if err := s.log(walRecord{Op: opAddFeedToken, FeedToken: toFileFeedToken(token)}); err != nil {
+		revertErr := s.mem.DeleteFeedToken(
+			context.WithoutCancel(ctx),
+			token.TenantID(),
+			token.OwnerID(),
+			token.TokenID(),
+		)
+		if revertErr != nil {
+			err = errors.Join(err, fmt.Errorf("can't revert added feed token: %w", revertErr))
+		}
+
+		return err
+	}
+
+	s.snapshotOnThreshold()
+
+	return nil
+}
+
+func (s *Storage) QueryFeedTokens(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) ([]model.FeedToken, error) {
+	return s.mem.QueryFeedTokens(ctx, tenantID, ownerID)
+}
+
+func (s *Storage) DeleteFeedToken(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	tokenID model.FeedTokenID,
+) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	tokens, err := s.mem.QueryFeedTokens(ctx, tenantID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(tokens, func(t model.FeedToken) bool { return t.TokenID() == tokenID }) {
+		return storage.ErrFeedTokenNotFound
+	}
+
+	// ссылка точно есть - удаление в памяти не завершится ошибкой, поэтому сначала записываем операцию в журнал
+	rec := walRecord{
+		Op:       opDeleteFeedToken,
+		TenantID: string(tenantID),
+		OwnerID:  string(ownerID),
+		TokenID:  string(tokenID),
+	}
+	if err := s.log(rec); err != nil {
+		return err
+	}
+
+	err = s.mem.DeleteFeedToken(context.WithoutCancel(ctx), tenantID, ownerID, tokenID)
+	s.snapshotOnThreshold()
+
+	return err
+}
+
+func (s *Storage) FindFeedToken(ctx context.Context, hash model.FeedTokenHash) (model.FeedToken, error) {
+	return s.mem.FindFeedToken(ctx, hash)
+}
+
+func (s *Storage) CountTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error) {
+	return s.mem.CountTenantEvents(ctx, tenantID)
+}
+
+func (s *Storage) QueryTenantEvents(ctx context.Context, tenantID model.TenantID) ([]model.Event, error) {
+	return s.mem.QueryTenantEvents(ctx, tenantID)
+}
+
+func (s *Storage) DeleteTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return 0, ErrClosed
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	// удаление событий пространства в памяти не может завершиться ошибкой,
+	// поэтому сначала записываем операцию в журнал
+	if err := s.log(walRecord{Op: opWipeTenant, TenantID: string(tenantID)}); err != nil {
+		return 0, err
+	}
+
+	n, err := s.mem.DeleteTenantEvents(context.WithoutCancel(ctx), tenantID)
+	s.snapshotOnThreshold()
+
+	return n, err
+}
+
+func (s *Storage) PurgeOldEvents(ctx context.Context, olderThan time.Time) ([]model.Event, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return nil, ErrClosed
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// удаление старых событий в памяти не может завершиться ошибкой,
+	// поэтому сначала записываем операцию в журнал
+	if err := s.log(walRecord{Op: opPurge, OlderThan: olderThan}); err != nil {
+		return nil, err
+	}
+
+	events, err := s.mem.PurgeOldEvents(context.WithoutCancel(ctx), olderThan)
+	s.snapshotOnThreshold()
+
+	return events, err
+}
+
+func (s *Storage) QueryEventsToNotify(ctx context.Context, from time.Time, to time.Time) ([]model.Event, error) {
+	return s.mem.QueryEventsToNotify(ctx, from, to)
+}
+
+// Snapshot создаёт снимок состояния хранилища и очищает журнал.
+func (s *Storage) Snapshot() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	return s.snapshot()
+}
+
+// Close сохраняет состояние хранилища на диск и освобождает ресурсы.
+func (s *Storage) Close() error {
+	s.mx.Lock()
+	if s.closed {
+		s.mx.Unlock()
+		return nil
+	}
+
+	s.closed = true
+	close(s.done)
+	s.mx.Unlock()
+
+	s.wg.Wait()
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var err error
+	if s.walRecords > 0 {
+		err = s.snapshot()
+	} else if s.dirty {
+		err = s.wal.Sync()
+	}
+
+	return errors.Join(err, s.closeFiles())
+}
+
+// log записывает в журнал запись rec в соответствии с политикой сброса на диск.
+// Должна вызываться при захваченном s.mx.
+func (s *Storage) log(rec walRecord) error {
+	rec.Seq = s.seq + 1
+	size := s.wal.size
+
+	if err := s.wal.Append(rec); err != nil {
+		return err
+	}
+
+	if s.opts.Sync == SyncAlways {
+		if err := s.wal.Sync(); err != nil {
+			// операция будет отменена, поэтому запись не должна остаться в журнале
+			if truncErr := s.wal.Truncate(size); truncErr != nil {
+				err = errors.Join(err, truncErr)
+			}
+
+			return fmt.Errorf("can't sync wal: %w", err)
+		}
+	} else {
+		s.dirty = true
+	}
+
+	s.seq = rec.Seq
+	s.walRecords++
+
+	return nil
+}
+
+// snapshotOnThreshold создаёт снимок, если в журнале накопилось SnapshotThreshold записей.
+// Вызывается после применения операции в памяти, чтобы снимок содержал её результат.
+// Должна вызываться при захваченном s.mx.
+func (s *Storage) snapshotOnThreshold() {
+	if s.opts.SnapshotThreshold <= 0 || s.walRecords < s.opts.SnapshotThreshold {
+		return
+	}
+
+	// запись уже в журнале, ошибка создания снимка не должна отменять операцию
+	if err := s.snapshot(); err != nil {
+		s.opts.Logger.Error("can't create snapshot", slog.String("error", err.Error()))
+	}
+}
+
+// snapshot создаёт снимок состояния хранилища и очищает журнал.
+// Должна вызываться при захваченном s.mx.
+func (s *Storage) snapshot() error {
+	err := writeSnapshot(
+		filepath.Join(s.opts.Dir, snapshotFileName),
+		s.seq,
+		s.mem.Labels(),
+		s.mem.Profiles(),
+		s.mem.Events(),
+		s.mem.FeedTokens(),
+	)
+	if err != nil {
+		return fmt.Errorf("can't write snapshot: %w", err)
+	}
+
+	if err := s.wal.Reset(); err != nil {
+		return fmt.Errorf("can't reset wal: %w", err)
+	}
+
+	s.walRecords = 0
+	s.dirty = false
+
+	return nil
+}
+
+// background выполняет периодический сброс журнала на диск и создание снимков.
+func (s *Storage) background() {
+	defer s.wg.Done()
+
+	var syncCh, snapshotCh <-chan time.Time
+
+	if s.opts.Sync == SyncInterval && s.opts.SyncInterval > 0 {
+		t := s.opts.Clock.NewTicker(s.opts.SyncInterval)
+		defer t.Stop()
+
+		syncCh = t.Chan()
+	}
+
+	if s.opts.SnapshotInterval > 0 {
+		t := s.opts.Clock.NewTicker(s.opts.SnapshotInterval)
+		defer t.Stop()
+
+		snapshotCh = t.Chan()
+	}
+
+	for {
+		select {
+		case <-syncCh:
+			s.mx.Lock()
+			if s.dirty {
+				if err := s.wal.Sync(); err != nil {
+					s.opts.Logger.Error("can't sync wal", slog.String("error", err.Error()))
+				} else {
+					s.dirty = false
+				}
+			}
+			s.mx.Unlock()
+		case <-snapshotCh:
+			s.mx.Lock()
+			if s.walRecords > 0 {
+				if err := s.snapshot(); err != nil {
+					s.opts.Logger.Error("can't create snapshot", slog.String("error", err.Error()))
+				}
+			}
+			s.mx.Unlock()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// closeFiles закрывает файлы хранилища (вместе с этим снимается блокировка директории).
+func (s *Storage) closeFiles() error {
+	var err error
+
+	if s.wal != nil {
+		err = errors.Join(err, s.wal.Close())
+	}
+
+	if s.lock != nil {
+		err = errors.Join(err, s.lock.Close())
+	}
+
+	return err
+}
@@ -0,0 +1,326 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	modelStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/storagetest"
+)
+
+func openStorage(t *testing.T, opts Options) *Storage {
+	t.Helper()
+
+	storage, err := NewStorage(opts)
+	require.NoError(t, err, "must open storage")
+
+	return storage
+}
+
+func TestFile_Storage(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncAlways, SyncInterval, SyncNever} {
+		t.Run(string(policy), func(t *testing.T) {
+			storagetest.Run(t, func(t *testing.T) modelStorage.Storage {
+				storage := openStorage(t, Options{
+					Dir:          t.TempDir(),
+					Sync:         policy,
+					SyncInterval: 10 * time.Millisecond,
+				})
+				t.Cleanup(func() { storage.Close() })
+
+				return storage
+			})
+		})
+	}
+}
+
+func TestFile_Recover(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "wal only",
+			opts: Options{},
+		},
+		{
+			name: "snapshot on each record",
+			opts: Options{SnapshotThreshold: 1},
+		},
+		{
+			name: "snapshot and wal",
+			opts: Options{SnapshotThreshold: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Dir = t.TempDir()
+			ctx := context.Background()
+
+			storage := openStorage(t, tt.opts)
+			pargs := storagetest.Populate(t, storage)
+
+			// каталог меток владельца: метка home снимается с события при удалении
+			for _, name := range []model.Label{"work", "home"} {
+				label := model.NewOwnerLabel(model.DefaultTenantID, pargs.OwnerIDs[0], name, "")
+				require.NoError(t, storage.AddLabel(ctx, label), "must add label")
+			}
+
+			label := model.NewOwnerLabel(model.DefaultTenantID, pargs.OwnerIDs[0], "work", "#00ff00")
+			require.NoError(t, storage.UpdateLabel(ctx, label), "must update label")
+
+			event := storagetest.MkEvent(
+				t, pargs.EventIDs[1], pargs.OwnerIDs[0], "2", pargs.Times[2][0], pargs.Times[2][1], 1,
+			)
+			event.Labels = []model.Label{"home", "work"}
+			event.Color = "#ff0000"
+
+			attachment, err := model.NewLinkAttachment("Agenda", "https://docs.example.com/agenda")
+			require.NoError(t, err)
+			event.Attachments = []model.Attachment{attachment}
+			require.NoError(t, storage.UpdateEvent(ctx, event), "must update")
+
+			err = storage.DeleteLabel(ctx, model.DefaultTenantID, pargs.OwnerIDs[0], "home")
+			require.NoError(t, err, "must delete label")
+
+			profile := model.NewProfile(model.DefaultTenantID, pargs.OwnerIDs[0])
+			profile.WorkingHours[time.Monday] = model.WorkingHours{Start: 9 * 60, End: 18 * 60}
+			profile.Holidays = []model.Date{{Year: 2030, Month: time.January, Day: 1}}
+			profile.Check = model.AvailabilityCheckReject
+			require.NoError(t, storage.SaveProfile(ctx, profile), "must save profile")
+
+			feedToken := storagetest.MkFeedToken(t, model.DefaultTenantID, pargs.OwnerIDs[0], "phone", pargs.Times[0][0])
+			require.NoError(t, storage.AddFeedToken(ctx, feedToken, 0), "must add feed token")
+
+			revoked := storagetest.MkFeedToken(t, model.DefaultTenantID, pargs.OwnerIDs[0], "", pargs.Times[0][0])
+			require.NoError(t, storage.AddFeedToken(ctx, revoked, 0), "must add feed token")
+			require.NoError(
+				t,
+				storage.DeleteFeedToken(ctx, model.DefaultTenantID, pargs.OwnerIDs[0], revoked.TokenID()),
+				"must delete feed token",
+			)
+
+			err = storage.DeleteEvent(ctx, model.DefaultTenantID, pargs.OwnerIDs[2], pargs.EventIDs[1])
+			require.NoError(t, err, "must delete")
+
+			// события других рабочих пространств, одно из которых очищено
+			for _, tenantID := range []model.TenantID{"hr", "sales"} {
+				event := storagetest.MkTenantEvent(
+					t, tenantID, pargs.EventIDs[0], pargs.OwnerIDs[0], "1", pargs.Times[1][0], pargs.Times[1][1], 0,
+				)
+				require.NoError(t, storage.AddEvent(ctx, event), "must add event to tenant")
+			}
+
+			_, err = storage.DeleteTenantEvents(ctx, "sales")
+			require.NoError(t, err, "must wipe tenant")
+
+			// эмулируем аварийное завершение: файлы закрываются без создания снимка
+			close(storage.done)
+			storage.wg.Wait()
+			require.NoError(t, storage.closeFiles(), "must close files")
+
+			storage = openStorage(t, tt.opts)
+			defer storage.Close()
+
+			require.Equal(
+				t,
+				[]model.ID{pargs.EventIDs[0], pargs.EventIDs[1]},
+				storagetest.OwnerEventIDs(t, storage, pargs.OwnerIDs[0]),
+				"proper events for user #1",
+			)
+			require.Equal(
+				t,
+				[]model.ID{pargs.EventIDs[0]},
+				storagetest.OwnerEventIDs(t, storage, pargs.OwnerIDs[2]),
+				"proper events for user #3",
+			)
+
+			found, err := storage.FindEvent(ctx, model.DefaultTenantID, pargs.OwnerIDs[0], pargs.EventIDs[1])
+			require.NoError(t, err, "must find event")
+			require.Equal(t, uint(1), found.NotifyBefore, "proper notifyBefore")
+			require.True(t, found.StartAt().Equal(pargs.Times[2][0]), "proper startAt")
+			require.Equal(t, []model.Label{"work"}, found.Labels, "proper labels")
+			require.Equal(t, model.Color("#ff0000"), found.Color, "proper color")
+			require.Equal(t, []model.Attachment{attachment}, found.Attachments, "proper attachments")
+
+			labels, err := storage.QueryLabels(ctx, model.DefaultTenantID, pargs.OwnerIDs[0])
+			require.NoError(t, err, "must query labels")
+			require.Equal(t, []model.OwnerLabel{label}, labels, "proper labels catalog")
+
+			foundProfile, err := storage.FindProfile(ctx, model.DefaultTenantID, pargs.OwnerIDs[0])
+			require.NoError(t, err, "must find profile")
+			require.Equal(t, profile.WorkingHours, foundProfile.WorkingHours, "proper working hours")
+			require.Equal(t, profile.Holidays, foundProfile.Holidays, "proper holidays")
+			require.Equal(t, model.AvailabilityCheckReject, foundProfile.Check, "proper check")
+
+			foundToken, err := storage.FindFeedToken(ctx, feedToken.Hash())
+			require.NoError(t, err, "must find feed token")
+			require.Equal(t, feedToken.TokenID(), foundToken.TokenID(), "proper feed token")
+			require.Equal(t, "phone", foundToken.Name, "proper feed token name")
+
+			_, err = storage.FindFeedToken(ctx, revoked.Hash())
+			require.ErrorIs(t, err, modelStorage.ErrFeedTokenNotFound, "must not restore deleted feed token")
+
+			_, err = storage.FindEvent(ctx, "hr", pargs.OwnerIDs[0], pargs.EventIDs[0])
+			require.NoError(t, err, "must find event of tenant")
+
+			n, err := storage.CountTenantEvents(ctx, "sales")
+			require.NoError(t, err, "must count events")
+			require.Zero(t, n, "tenant must be wiped")
+		})
+	}
+}
+
+func TestFile_RecoverCorruptedWAL(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	storage := openStorage(t, Options{Dir: dir})
+	pargs := storagetest.Populate(t, storage)
+
+	err := storage.DeleteEvent(ctx, model.DefaultTenantID, pargs.OwnerIDs[0], pargs.EventIDs[1])
+	require.NoError(t, err, "must delete")
+
+	close(storage.done)
+	storage.wg.Wait()
+	require.NoError(t, storage.closeFiles(), "must close files")
+
+	// обрезаем последнюю запись (удаление события) - как при сбое во время записи
+	walPath := filepath.Join(dir, walFileName)
+	info, err := os.Stat(walPath)
+	require.NoError(t, err, "must stat wal")
+	require.NoError(t, os.Truncate(walPath, info.Size()-3), "must truncate wal")
+
+	storage = openStorage(t, Options{Dir: dir})
+
+	require.Equal(
+		t,
+		[]model.ID{pargs.EventIDs[1], pargs.EventIDs[0]},
+		storagetest.OwnerEventIDs(t, storage, pargs.OwnerIDs[0]),
+		"deletion must be lost",
+	)
+
+	// после восстановления журнал пригоден для записи
+	err = storage.DeleteEvent(ctx, model.DefaultTenantID, pargs.OwnerIDs[0], pargs.EventIDs[0])
+	require.NoError(t, err, "must delete")
+	require.NoError(t, storage.Close(), "must close")
+
+	storage = openStorage(t, Options{Dir: dir})
+	defer storage.Close()
+
+	require.Equal(
+		t,
+		[]model.ID{pargs.EventIDs[1]},
+		storagetest.OwnerEventIDs(t, storage, pargs.OwnerIDs[0]),
+		"proper events after reopen",
+	)
+}
+
+func TestFile_RecoverSnapshotBeforeWALReset(t *testing.T) {
+	dir := t.TempDir()
+
+	storage := openStorage(t, Options{Dir: dir})
+	pargs := storagetest.Populate(t, storage)
+
+	// эмулируем сбой после записи снимка, но до очистки журнала
+	walData, err := os.ReadFile(filepath.Join(dir, walFileName))
+	require.NoError(t, err, "must read wal")
+	require.NoError(t, storage.Close(), "must close")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, walFileName), walData, 0o600), "must restore wal")
+
+	storage = openStorage(t, Options{Dir: dir})
+	defer storage.Close()
+
+	require.Len(t, storagetest.OwnerEventIDs(t, storage, pargs.OwnerIDs[0]), 2, "must have 2 events for user #1")
+	require.Len(t, storagetest.OwnerEventIDs(t, storage, pargs.OwnerIDs[2]), 2, "must have 2 events for user #3")
+}
+
+func TestFile_RecoverWithoutTenant(t *testing.T) {
+	dir := t.TempDir()
+
+	// журнал, записанный до появления рабочих пространств
+	w, err := openWAL(filepath.Join(dir, walFileName))
+	require.NoError(t, err, "must open wal")
+
+	ownerID, eventID := model.NewOwnerID(), model.NewID()
+	startAt := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, w.Append(walRecord{
+		Seq: 1,
+		Op:  opAdd,
+		Event: &fileEvent{
+			EventID: string(eventID),
+			OwnerID: string(ownerID),
+			StartAt: startAt,
+			EndAt:   startAt.Add(time.Hour),
+			Title:   "legacy",
+		},
+	}), "must append")
+	require.NoError(t, w.Close(), "must close wal")
+
+	storage := openStorage(t, Options{Dir: dir})
+	defer storage.Close()
+
+	found, err := storage.FindEvent(context.Background(), model.DefaultTenantID, ownerID, eventID)
+	require.NoError(t, err, "must find event in default tenant")
+	require.Equal(t, model.DefaultTenantID, found.TenantID(), "must be in default tenant")
+}
+
+func TestFile_Lock(t *testing.T) {
+	dir := t.TempDir()
+
+	storage := openStorage(t, Options{Dir: dir})
+
+	_, err := NewStorage(Options{Dir: dir})
+	require.Error(t, err, "dir must be locked")
+
+	require.NoError(t, storage.Close(), "must close")
+
+	_, err = storage.FindEvent(context.Background(), model.DefaultTenantID, model.NewOwnerID(), model.NewID())
+	require.ErrorIs(t, err, modelStorage.ErrEventNotFound, "reads must work after close")
+
+	err = storage.AddEvent(context.Background(), storagetest.MkEvent(
+		t, model.NewID(), model.NewOwnerID(), "1", time.Now(), time.Now().Add(time.Hour), 0,
+	))
+	require.ErrorIs(t, err, ErrClosed, "writes must fail after close")
+
+	storage = openStorage(t, Options{Dir: dir})
+	require.NoError(t, storage.Close(), "must close")
+}
+
+func TestFile_SnapshotInterval(t *testing.T) {
+	dir := t.TempDir()
+	clock := clock.NewFake(time.Now())
+
+	storage := openStorage(t, Options{Dir: dir, SnapshotInterval: time.Minute, Clock: clock})
+	defer storage.Close()
+
+	storagetest.Populate(t, storage)
+
+	walSize := func() int64 {
+		info, err := os.Stat(filepath.Join(dir, walFileName))
+		require.NoError(t, err, "wal must exist")
+
+		return info.Size()
+	}
+
+	// фоновая задача ждёт срабатывания тикера снимков
+	clock.BlockUntil(1)
+	require.NotZero(t, walSize(), "wal must not be reset before snapshot interval")
+
+	clock.Advance(time.Minute)
+	require.Eventually(t, func() bool {
+		return walSize() == 0
+	}, time.Second, time.Millisecond, "wal must be reset after snapshot")
+
+	_, err := os.Stat(filepath.Join(dir, snapshotFileName))
+	require.NoError(t, err, "snapshot must exist")
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile захватывает эксклюзивную блокировку файла f без ожидания.
+// Блокировка снимается при закрытии файла, в том числе при аварийном завершении процесса.
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
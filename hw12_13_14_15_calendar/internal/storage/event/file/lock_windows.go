@@ -0,0 +1,12 @@
+//go:build windows
+
+package file
+
+import (
+	"os"
+)
+
+// lockFile на windows не поддерживается: эксклюзивный доступ к директории хранилища не проверяется.
+func lockFile(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,125 @@
+package file
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// snapshot - снимок состояния хранилища.
+type snapshot struct {
+	// Seq - номер последней записи журнала, учтённой в снимке.
+	Seq      uint64         `json:"seq"`
+	Labels   []*fileLabel   `json:"labels,omitempty"`
+	Profiles []*fileProfile `json:"profiles,omitempty"`
+	Events   []*fileEvent   `json:"events"`
+
+	FeedTokens []*fileFeedToken `json:"feedTokens,omitempty"`
+}
+
+// writeSnapshot атомарно записывает снимок меток labels, профилей profiles, событий events
+// и ссылок на календари feedTokens в файл path:
+// снимок пишется во временный файл, который после сброса на диск переименовывается в path.
+func writeSnapshot(
+	path string,
+	seq uint64,
+	labels []model.OwnerLabel,
+	profiles []model.Profile,
+	events []model.Event,
+	feedTokens []model.FeedToken,
+) (err error) {
+	snap := snapshot{
+		Seq:    seq,
+		Events: make([]*fileEvent, 0, len(events)),
+	}
+
+	for _, label := range labels {
+		snap.Labels = append(snap.Labels, toFileLabel(label))
+	}
+
+	for _, profile := range profiles {
+		snap.Profiles = append(snap.Profiles, toFileProfile(profile))
+	}
+
+	for _, event := range events {
+		snap.Events = append(snap.Events, toFileEvent(event))
+	}
+
+	for _, token := range feedTokens {
+		snap.FeedTokens = append(snap.FeedTokens, toFileFeedToken(token))
+	}
+
+	tmpPath := path + ".tmp"
+
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("can't encode snapshot: %w", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// readSnapshot читает снимок из файла path.
+// Если файла нет, возвращает пустой снимок.
+func readSnapshot(path string) (snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return snapshot{}, nil
+		}
+
+		return snapshot{}, err
+	}
+	defer f.Close()
+
+	var snap snapshot
+	if err := json.NewDecoder(bufio.NewReader(f)).Decode(&snap); err != nil {
+		return snapshot{}, fmt.Errorf("can't decode snapshot: %w", err)
+	}
+
+	return snap, nil
+}
+
+// syncDir сбрасывает на диск содержимое директории dir (например, после переименования файла).
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,239 @@
+package file
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+)
+
+// Операции в журнале.
+const (
+	opAdd    = "add"
+	opUpdate = "update"
+	opDelete = "delete"
+	opPurge  = "purge"
+
+	// opWipeTenant - удаление всех событий рабочего пространства.
+	opWipeTenant = "wipe_tenant"
+
+	// Операции с каталогом меток владельца.
+	opAddLabel    = "add_label"
+	opUpdateLabel = "update_label"
+	opDeleteLabel = "delete_label"
+
+	// opSaveProfile - сохранение профиля доступности владельца.
+	opSaveProfile = "save_profile"
+
+	// Операции со ссылками на календарь владельца.
+	opAddFeedToken    = "add_feed_token"
+	opDeleteFeedToken = "delete_feed_token"
+)
+
+// recordHeaderSize - размер заголовка записи журнала: длина данных (4 байта) и контрольная сумма crc32 (4 байта).
+const recordHeaderSize = 8
+
+// maxRecordSize - максимальный размер данных записи журнала.
+// Записи большего размера считаются повреждёнными.
+const maxRecordSize = 1 << 20
+
+var errCorruptedRecord = errors.New("corrupted record")
+
+// walRecord - запись журнала (write-ahead log) об изменении хранилища.
+type walRecord struct {
+	// Seq - порядковый номер записи. Возрастает монотонно, в том числе между снимками.
+	Seq uint64 `json:"seq"`
+	Op  string `json:"op"`
+
+	// Event - событие для операций opAdd и opUpdate.
+	Event *fileEvent `json:"event,omitempty"`
+
+	// TenantID, OwnerID и EventID - идентификаторы удаляемого события для операции opDelete.
+	// TenantID - также рабочее пространство для операции opWipeTenant.
+	// TenantID, OwnerID и TokenID - идентификаторы удаляемой ссылки для операции opDeleteFeedToken.
+	TenantID string `json:"tenantId,omitempty"`
+	OwnerID  string `json:"ownerId,omitempty"`
+	EventID  string `json:"eventId,omitempty"`
+	TokenID  string `json:"tokenId,omitempty"`
+
+	// Label - метка для операций opAddLabel, opUpdateLabel и opDeleteLabel.
+	Label *fileLabel `json:"label,omitempty"`
+
+	// Profile - профиль для операции opSaveProfile.
+	Profile *fileProfile `json:"profile,omitempty"`
+
+	// FeedToken - ссылка на календарь для операции opAddFeedToken.
+	FeedToken *fileFeedToken `json:"feedToken,omitempty"`
+
+	// OlderThan - параметр операции opPurge.
+	OlderThan time.Time `json:"olderThan,omitempty"`
+}
+
+// wal - журнал изменений хранилища.
+//
+// Журнал - последовательность записей вида:
+//
+//	[длина данных: uint32 BE][crc32 данных: uint32 BE][данные: json walRecord]
+type wal struct {
+	f    *os.File
+	size int64
+}
+
+// openWAL открывает (или создаёт) файл журнала path.
+func openWAL(path string) (*wal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wal{f: f}, nil
+}
+
+// Replay последовательно читает записи журнала с начала файла и вызывает для каждой fn.
+//
+// Чтение прекращается на первой неполной или повреждённой записи (например, после аварийного завершения
+// во время записи): журнал обрезается до последней целой записи.
+// Возвращает количество прочитанных записей.
+func (w *wal) Replay(fn func(rec walRecord) error) (int, error) {
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	r := bufio.NewReader(w.f)
+
+	var (
+		n      int
+		offset int64
+	)
+
+	for {
+		rec, size, err := readRecord(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errCorruptedRecord) {
+				// хвост журнала повреждён - отбрасываем его
+				if err := w.f.Truncate(offset); err != nil {
+					return n, fmt.Errorf("can't truncate corrupted wal: %w", err)
+				}
+
+				break
+			}
+
+			return n, err
+		}
+
+		if err := fn(rec); err != nil {
+			return n, fmt.Errorf("can't apply wal record #%d: %w", rec.Seq, err)
+		}
+
+		offset += size
+		n++
+	}
+
+	w.size = offset
+	if _, err := w.f.Seek(offset, io.SeekStart); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// Append добавляет запись в конец журнала.
+// В случае ошибки журнал обрезается до прежнего размера, чтобы не оставлять неполную запись.
+func (w *wal) Append(rec walRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("can't marshal wal record: %w", err)
+	}
+
+	buf := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(data))
+	copy(buf[recordHeaderSize:], data)
+
+	if _, err := w.f.WriteAt(buf, w.size); err != nil {
+		if truncErr := w.Truncate(w.size); truncErr != nil {
+			err = errors.Join(err, truncErr)
+		}
+
+		return fmt.Errorf("can't write wal record: %w", err)
+	}
+
+	w.size += int64(len(buf))
+
+	return nil
+}
+
+// Truncate обрезает журнал до размера size.
+func (w *wal) Truncate(size int64) error {
+	if err := w.f.Truncate(size); err != nil {
+		return err
+	}
+
+	w.size = size
+
+	return nil
+}
+
+// Sync сбрасывает журнал на диск.
+func (w *wal) Sync() error {
+	return w.f.Sync()
+}
+
+// Reset очищает журнал (после успешного создания снимка).
+func (w *wal) Reset() error {
+	if err := w.Truncate(0); err != nil {
+		return err
+	}
+
+	return w.f.Sync()
+}
+
+// Close закрывает файл журнала.
+func (w *wal) Close() error {
+	return w.f.Close()
+}
+
+// readRecord читает очередную запись журнала из r.
+// Возвращает запись и её размер в журнале.
+func readRecord(r io.Reader) (walRecord, int64, error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return walRecord{}, 0, err
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	sum := binary.BigEndian.Uint32(header[4:8])
+
+	if size > maxRecordSize {
+		return walRecord{}, 0, errCorruptedRecord
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return walRecord{}, 0, err
+	}
+
+	if crc32.ChecksumIEEE(data) != sum {
+		return walRecord{}, 0, errCorruptedRecord
+	}
+
+	var rec walRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return walRecord{}, 0, fmt.Errorf("%w: %w", errCorruptedRecord, err)
+	}
+
+	return rec, int64(recordHeaderSize) + int64(size), nil
+}
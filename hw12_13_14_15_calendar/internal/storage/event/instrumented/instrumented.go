@@ -0,0 +1,207 @@
+// instrumented - обёртка над хранилищем событий, учитывающая длительность операций.
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// OperationObserver учитывает выполненные операции хранилища, см. metrics.Storage.
+type OperationObserver interface {
+	ObserveOperation(operation string, d time.Duration, err error)
+}
+
+type Storage struct {
+	storage  storage.Storage
+	observer OperationObserver
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// New возвращает хранилище, выполняющее операции в s и учитывающее их в observer.
+func New(s storage.Storage, observer OperationObserver) *Storage {
+	return &Storage{
+		storage:  s,
+		observer: observer,
+	}
+}
+
+func (s *Storage) AddEvent(ctx context.Context, event model.Event) (err error) {
+	defer s.observe("AddEvent", time.Now(), &err)
+
+	return s.storage.AddEvent(ctx, event)
+}
+
+func (s *Storage) UpdateEvent(ctx context.Context, event model.Event) (err error) {
+	defer s.observe("UpdateEvent", time.Now(), &err)
+
+	return s.storage.UpdateEvent(ctx, event)
+}
+
+func (s *Storage) FindEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (_ model.Event, err error) {
+	defer s.observe("FindEvent", time.Now(), &err)
+
+	return s.storage.FindEvent(ctx, tenantID, ownerID, eventID)
+}
+
+func (s *Storage) DeleteEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (err error) {
+	defer s.observe("DeleteEvent", time.Now(), &err)
+
+	return s.storage.DeleteEvent(ctx, tenantID, ownerID, eventID)
+}
+
+func (s *Storage) QueryEvents(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	filter model.Filter,
+) (_ []model.Event, err error) {
+	defer s.observe("QueryEvents", time.Now(), &err)
+
+	return s.storage.QueryEvents(ctx, tenantID, ownerID, from, to, filter)
+}
+
+func (s *Storage) QueryEventsNear(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	area model.Area,
+) (_ []model.Event, err error) {
+	defer s.observe("QueryEventsNear", time.Now(), &err)
+
+	return s.storage.QueryEventsNear(ctx, tenantID, ownerID, from, to, area)
+}
+
+func (s *Storage) AddLabel(ctx context.Context, label model.OwnerLabel) (err error) {
+	defer s.observe("AddLabel", time.Now(), &err)
+
+	return s.storage.AddLabel(ctx, label)
+}
+
+func (s *Storage) UpdateLabel(ctx context.Context, label model.OwnerLabel) (err error) {
+	defer s.observe("UpdateLabel", time.Now(), &err)
+
+	return s.storage.UpdateLabel(ctx, label)
+}
+
+func (s *Storage) DeleteLabel(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name model.Label,
+) (err error) {
+	defer s.observe("DeleteLabel", time.Now(), &err)
+
+	return s.storage.DeleteLabel(ctx, tenantID, ownerID, name)
+}
+
+func (s *Storage) QueryLabels(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (_ []model.OwnerLabel, err error) {
+	defer s.observe("QueryLabels", time.Now(), &err)
+
+	return s.storage.QueryLabels(ctx, tenantID, ownerID)
+}
+
+func (s *Storage) FindProfile(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (_ model.Profile, err error) {
+	defer s.observe("FindProfile", time.Now(), &err)
+
+	return s.storage.FindProfile(ctx, tenantID, ownerID)
+}
+
+func (s *Storage) SaveProfile(ctx context.Context, profile model.Profile) (err error) {
+	defer s.observe("SaveProfile", time.Now(), &err)
+
+	return s.storage.SaveProfile(ctx, profile)
+}
+
+func (s *Storage) AddFeedToken(ctx context.Context, token model.FeedToken, maxTokens int) (err error) {
+	defer s.observe("AddFeedToken", time.Now(), &err)
+
+	return s.storage.AddFeedToken(ctx, token, maxTokens)
+}
+
+func (s *Storage) QueryFeedTokens(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (_ []model.FeedToken, err error) {
+	defer s.observe("QueryFeedTokens", time.Now(), &err)
+
+	return s.storage.QueryFeedTokens(ctx, tenantID, ownerID)
+}
+
+func (s *Storage) DeleteFeedToken(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	tokenID model.FeedTokenID,
+) (err error) {
+	defer s.observe("DeleteFeedToken", time.Now(), &err)
+
+	return s.storage.DeleteFeedToken(ctx, tenantID, ownerID, tokenID)
+}
+
+func (s *Storage) FindFeedToken(ctx context.Context, hash model.FeedTokenHash) (_ model.FeedToken, err error) {
+	defer s.observe("FindFeedToken", time.Now(), &err)
+
+	return s.storage.FindFeedToken(ctx, hash)
+}
+
+func (s *Storage) CountTenantEvents(ctx context.Context, tenantID model.TenantID) (_ int, err error) {
+	defer s.observe("CountTenantEvents", time.Now(), &err)
+
+	return s.storage.CountTenantEvents(ctx, tenantID)
+}
+
+func (s *Storage) QueryTenantEvents(ctx context.Context, tenantID model.TenantID) (_ []model.Event, err error) {
+	defer s.observe("QueryTenantEvents", time.Now(), &err)
+
+	return s.storage.QueryTenantEvents(ctx, tenantID)
+}
+
+func (s *Storage) DeleteTenantEvents(ctx context.Context, tenantID model.TenantID) (_ int, err error) {
+	defer s.observe("DeleteTenantEvents", time.Now(), &err)
+
+	return s.storage.DeleteTenantEvents(ctx, tenantID)
+}
+
+func (s *Storage) PurgeOldEvents(ctx context.Context, olderThan time.Time) (_ []model.Event, err error) {
+	defer s.observe("PurgeOldEvents", time.Now(), &err)
+
+	return s.storage.PurgeOldEvents(ctx, olderThan)
+}
+
+func (s *Storage) QueryEventsToNotify(ctx context.Context, from time.Time, to time.Time) (_ []model.Event, err error) {
+	defer s.observe("QueryEventsToNotify", time.Now(), &err)
+
+	return s.storage.QueryEventsToNotify(ctx, from, to)
+}
+
+// observe учитывает операцию operation, начатую в startAt и завершившуюся с ошибкой *err.
+func (s *Storage) observe(operation string, startAt time.Time, err *error) {
+	s.observer.ObserveOperation(operation, time.Since(startAt), *err)
+}
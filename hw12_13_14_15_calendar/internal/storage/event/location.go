@@ -0,0 +1,20 @@
+package event
+
+import (
+	"slices"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// FilterNear оставляет в events только события, координаты места которых находятся в области area.
+// Используется хранилищами, которые отбирают события по описанному прямоугольнику области (model.BoundingBox).
+func FilterNear(events []model.Event, area model.Area) []model.Event {
+	return slices.DeleteFunc(events, func(event model.Event) bool {
+		return !InArea(event, area)
+	})
+}
+
+// InArea сообщает, что координаты места события event находятся в области area.
+func InArea(event model.Event, area model.Area) bool {
+	return event.Location.Point != nil && area.Contains(*event.Location.Point)
+}
@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"time"
+)
+
+// intervalNode - узел интервального дерева.
+type intervalNode struct {
+	item *item
+
+	// maxEnd - максимальное значение EndAt среди всех событий поддерева.
+	maxEnd time.Time
+	height int
+
+	left  *intervalNode
+	right *intervalNode
+}
+
+// intervalTree - интервальное дерево событий (AVL-дерево, упорядоченное по StartAt события,
+// дополненное максимальным EndAt поддерева).
+//
+// Позволяет за O(log n + k) находить k событий, пересекающихся с промежутком [from, to),
+// а также добавлять и удалять события за O(log n).
+type intervalTree struct {
+	root *intervalNode
+	size int
+}
+
+// Len возвращает количество событий в дереве.
+func (t *intervalTree) Len() int {
+	return t.size
+}
+
+// Insert добавляет событие в дерево.
+func (t *intervalTree) Insert(it *item) {
+	t.root = insertNode(t.root, it)
+	t.size++
+}
+
+// Delete удаляет событие из дерева.
+// Возвращает false, если событие не было найдено.
+func (t *intervalTree) Delete(it *item) bool {
+	var deleted bool
+	t.root, deleted = deleteNode(t.root, it)
+	if deleted {
+		t.size--
+	}
+
+	return deleted
+}
+
+// Overlaps проверяет, есть ли в дереве события, пересекающиеся с промежутком [from, to).
+func (t *intervalTree) Overlaps(from time.Time, to time.Time) bool {
+	found := false
+	t.Query(from, to, func(*item) bool {
+		found = true
+		return false
+	})
+
+	return found
+}
+
+// Query вызывает fn для каждого события в дереве, пересекающегося с промежутком [from, to),
+// в порядке возрастания StartAt. Обход прекращается, если fn вернула false.
+func (t *intervalTree) Query(from time.Time, to time.Time, fn func(*item) bool) {
+	queryNode(t.root, from, to, fn)
+}
+
+// Ascend вызывает fn для каждого события в дереве в порядке возрастания StartAt.
+// Обход прекращается, если fn вернула false.
+func (t *intervalTree) Ascend(fn func(*item) bool) {
+	ascendNode(t.root, fn)
+}
+
+// itemLess задаёт порядок событий в дереве: по StartAt, при равенстве - по идентификатору события.
+func itemLess(a *item, b *item) bool {
+	if a.event.StartAt().Equal(b.event.StartAt()) {
+		return a.event.EventID() < b.event.EventID()
+	}
+
+	return a.event.StartAt().Before(b.event.StartAt())
+}
+
+func queryNode(n *intervalNode, from time.Time, to time.Time, fn func(*item) bool) bool {
+	// в поддереве нет событий, заканчивающихся после from
+	if n == nil || !n.maxEnd.After(from) {
+		return true
+	}
+
+	if !queryNode(n.left, from, to, fn) {
+		return false
+	}
+
+	// текущее и все события правого поддерева начинаются не раньше to
+	if !n.item.event.StartAt().Before(to) {
+		return true
+	}
+
+	if n.item.event.EndAt().After(from) {
+		if !fn(n.item) {
+			return false
+		}
+	}
+
+	return queryNode(n.right, from, to, fn)
+}
+
+func ascendNode(n *intervalNode, fn func(*item) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return ascendNode(n.left, fn) && fn(n.item) && ascendNode(n.right, fn)
+}
+
+func insertNode(n *intervalNode, it *item) *intervalNode {
+	if n == nil {
+		return newNode(it)
+	}
+
+	if itemLess(it, n.item) {
+		n.left = insertNode(n.left, it)
+	} else {
+		n.right = insertNode(n.right, it)
+	}
+
+	return balance(n)
+}
+
+func deleteNode(n *intervalNode, it *item) (*intervalNode, bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var deleted bool
+	switch {
+	case n.item == it:
+		if n.left == nil {
+			return n.right, true
+		}
+
+		if n.right == nil {
+			return n.left, true
+		}
+
+		// заменяем узел минимальным узлом правого поддерева
+		minNode := n.right
+		for minNode.left != nil {
+			minNode = minNode.left
+		}
+
+		n.item = minNode.item
+		n.right, _ = deleteNode(n.right, minNode.item)
+		deleted = true
+	case itemLess(it, n.item):
+		n.left, deleted = deleteNode(n.left, it)
+	default:
+		n.right, deleted = deleteNode(n.right, it)
+	}
+
+	return balance(n), deleted
+}
+
+func newNode(it *item) *intervalNode {
+	return &intervalNode{
+		item:   it,
+		maxEnd: it.event.EndAt(),
+		height: 1,
+	}
+}
+
+func height(n *intervalNode) int {
+	if n == nil {
+		return 0
+	}
+
+	return n.height
+}
+
+// update пересчитывает высоту и maxEnd узла по его потомкам.
+func update(n *intervalNode) {
+	n.height = max(height(n.left), height(n.right)) + 1
+
+	n.maxEnd = n.item.event.EndAt()
+	if n.left != nil && n.left.maxEnd.After(n.maxEnd) {
+		n.maxEnd = n.left.maxEnd
+	}
+
+	if n.right != nil && n.right.maxEnd.After(n.maxEnd) {
+		n.maxEnd = n.right.maxEnd
+	}
+}
+
+func rotateRight(n *intervalNode) *intervalNode {
+	l := n.left
+	n.left = l.right
+	l.right = n
+
+	update(n)
+	update(l)
+
+	return l
+}
+
+func rotateLeft(n *intervalNode) *intervalNode {
+	r := n.right
+	n.right = r.left
+	r.left = n
+
+	update(n)
+	update(r)
+
+	return r
+}
+
+// balance восстанавливает AVL-балансировку узла n.
+func balance(n *intervalNode) *intervalNode {
+	update(n)
+
+	switch bf := height(n.left) - height(n.right); {
+	case bf > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+
+		return rotateRight(n)
+	case bf < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+
+		return rotateLeft(n)
+	}
+
+	return n
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// checkNode проверяет инварианты поддерева: порядок, AVL-балансировку, высоту и maxEnd.
+// Возвращает высоту и maxEnd поддерева.
+func checkNode(t *testing.T, n *intervalNode) (int, time.Time) {
+	t.Helper()
+
+	if n == nil {
+		return 0, time.Time{}
+	}
+
+	lh, lEnd := checkNode(t, n.left)
+	rh, rEnd := checkNode(t, n.right)
+
+	if n.left != nil {
+		require.True(t, itemLess(n.left.item, n.item), "left must be less")
+	}
+
+	if n.right != nil {
+		require.True(t, itemLess(n.item, n.right.item), "right must be greater")
+	}
+
+	require.LessOrEqual(t, lh-rh, 1, "must be balanced")
+	require.LessOrEqual(t, rh-lh, 1, "must be balanced")
+	require.Equal(t, max(lh, rh)+1, n.height, "proper height")
+
+	maxEnd := n.item.event.EndAt()
+	for _, end := range []time.Time{lEnd, rEnd} {
+		if end.After(maxEnd) {
+			maxEnd = end
+		}
+	}
+	require.True(t, maxEnd.Equal(n.maxEnd), "proper maxEnd")
+
+	return n.height, maxEnd
+}
+
+func Test_intervalTree(t *testing.T) {
+	now := time.Now().Truncate(time.Hour)
+	ownerID := model.NewOwnerID()
+
+	// 1000 событий по 30 минут с интервалом в 1 час
+	items := make([]*item, 1000)
+	for i := range items {
+		startAt := now.Add(time.Duration(i) * time.Hour)
+		items[i] = newItem(mkEvent(t, model.NewID(), ownerID, "event", startAt, startAt.Add(30*time.Minute), 0))
+	}
+
+	tree := &intervalTree{}
+
+	r := rand.New(rand.NewSource(1))
+	for _, i := range r.Perm(len(items)) {
+		tree.Insert(items[i])
+	}
+
+	require.Equal(t, len(items), tree.Len(), "proper len")
+	checkNode(t, tree.root)
+
+	t.Run("ascend", func(t *testing.T) {
+		i := 0
+		tree.Ascend(func(it *item) bool {
+			require.Same(t, items[i], it, "proper order")
+			i++
+			return true
+		})
+		require.Equal(t, len(items), i, "all items")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		var found []*item
+		tree.Query(items[10].event.StartAt().Add(45*time.Minute), items[13].event.StartAt(), func(it *item) bool {
+			found = append(found, it)
+			return true
+		})
+		require.Equal(t, []*item{items[11], items[12]}, found, "proper result")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		for _, i := range r.Perm(len(items))[:len(items)/2] {
+			require.True(t, tree.Delete(items[i]), "must be deleted")
+			require.False(t, tree.Delete(items[i]), "must not be deleted twice")
+		}
+
+		require.Equal(t, len(items)/2, tree.Len(), "proper len")
+		checkNode(t, tree.root)
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,11 +15,47 @@ import (
 )
 
 type (
-	Events []model.Event
+	// item - событие в хранилище.
+	item struct {
+		event model.Event
+
+		// notifyAt - время отправки уведомления по событию (при event.NotifyBefore > 0).
+		notifyAt time.Time
+	}
+
+	// itemKey - ключ индекса событий: идентификатор события уникален в рамках владельца.
+	itemKey struct {
+		ownerID model.OwnerID
+		eventID model.ID
+	}
+
+	// tenantEvents - события рабочего пространства.
+	tenantEvents struct {
+		// owners - интервальное дерево событий для каждого владельца.
+		owners map[model.OwnerID]*intervalTree
+
+		// index - индекс событий по ownerID и eventID.
+		index map[itemKey]*item
+
+		// labels - каталог меток каждого владельца.
+		labels map[model.OwnerID]map[model.Label]model.OwnerLabel
+
+		// profiles - профиль доступности каждого владельца.
+		profiles map[model.OwnerID]model.Profile
+	}
 
 	Storage struct {
-		userMap map[model.OwnerID]Events
-		mx      sync.RWMutex
+		// tenants - события каждого рабочего пространства.
+		tenants map[model.TenantID]*tenantEvents
+
+		// notify - индекс событий всех рабочих пространств по времени отправки уведомления.
+		// События удаляются из индекса вместе с событием, в том числе при очистке старых событий (PurgeOldEvents).
+		notify notifyIndex
+
+		// feedTokens - ссылки на календари владельцев всех рабочих пространств по хешу секрета.
+		feedTokens map[model.FeedTokenHash]model.FeedToken
+
+		mx sync.RWMutex
 	}
 )
 
@@ -24,60 +63,152 @@ var _ storage.Storage = (*Storage)(nil)
 
 func NewStorage() *Storage {
 	return &Storage{
-		userMap: map[model.OwnerID]Events{},
+		tenants:    map[model.TenantID]*tenantEvents{},
+		feedTokens: map[model.FeedTokenHash]model.FeedToken{},
+	}
+}
+
+func newTenantEvents() *tenantEvents {
+	return &tenantEvents{
+		owners:   map[model.OwnerID]*intervalTree{},
+		index:    map[itemKey]*item{},
+		labels:   map[model.OwnerID]map[model.Label]model.OwnerLabel{},
+		profiles: map[model.OwnerID]model.Profile{},
+	}
+}
+
+// empty сообщает, что в рабочем пространстве нет ни событий, ни меток, ни профилей.
+func (t *tenantEvents) empty() bool {
+	return len(t.index) == 0 && len(t.labels) == 0 && len(t.profiles) == 0
+}
+
+// checkLabels проверяет, что все метки события есть в каталоге владельца.
+func (t *tenantEvents) checkLabels(event model.Event) error {
+	catalog := t.labels[event.OwnerID()]
+	for _, label := range event.Labels {
+		if _, exists := catalog[label]; !exists {
+			return fmt.Errorf("%w: '%s'", storage.ErrUnknownLabel, label)
+		}
+	}
+
+	return nil
+}
+
+// tenant возвращает рабочее пространство tenantID, создавая его при необходимости.
+func (m *Storage) tenant(tenantID model.TenantID) *tenantEvents {
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		tenant = newTenantEvents()
+		m.tenants[tenantID] = tenant
+	}
+
+	return tenant
+}
+
+// dropTenantIfEmpty удаляет рабочее пространство tenantID, если в нём не осталось ни событий, ни меток.
+func (m *Storage) dropTenantIfEmpty(tenantID model.TenantID) {
+	if tenant, exists := m.tenants[tenantID]; exists && tenant.empty() {
+		delete(m.tenants, tenantID)
 	}
 }
 
 func (m *Storage) AddEvent(ctx context.Context, event model.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mx.Lock()
 	defer m.mx.Unlock()
 
 	return m.addEvent(ctx, event)
 }
 
-func (m *Storage) addEvent(ctx context.Context, event model.Event) error {
-	if _, err := m.findEvent(ctx, event.OwnerID(), event.EventID()); err == nil {
+func (m *Storage) addEvent(_ context.Context, event model.Event) error {
+	tenant := m.tenant(event.TenantID())
+
+	key := itemKey{ownerID: event.OwnerID(), eventID: event.EventID()}
+	if _, exists := tenant.index[key]; exists {
 		return storage.ErrEventAlreadyExists
 	}
 
-	events, exists := m.userMap[event.OwnerID()]
+	tree, exists := tenant.owners[event.OwnerID()]
+	if exists && tree.Overlaps(event.StartAt(), event.EndAt()) {
+		return storage.ErrTimeIsBusy
+	}
+
+	if err := tenant.checkLabels(event); err != nil {
+		m.dropTenantIfEmpty(event.TenantID())
+		return err
+	}
+
 	if !exists {
-		events = Events{}
+		tree = &intervalTree{}
+		tenant.owners[event.OwnerID()] = tree
 	}
 
-	i := findNewEventIndex(events, event)
-	if i == -1 {
-		return storage.ErrTimeIsBusy
+	// метки, вложения и координаты копируются, чтобы их изменения вызывающей стороной не затрагивали хранилище
+	event.Labels = slices.Clone(event.Labels)
+	event.Attachments = slices.Clone(event.Attachments)
+	if point := event.Location.Point; point != nil {
+		event.Location.Point = &model.Point{Latitude: point.Latitude, Longitude: point.Longitude}
 	}
+	it := newItem(event)
 
-	events = append(events[:i], append(Events{event}, events[i:]...)...)
-	m.userMap[event.OwnerID()] = events
+	tree.Insert(it)
+	tenant.index[key] = it
+
+	if event.NotifyBefore > 0 {
+		m.notify.Insert(it)
+	}
 
 	return nil
 }
 
-func (m *Storage) FindEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) (model.Event, error) {
+func (m *Storage) FindEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Event{}, err
+	}
+
 	m.mx.RLock()
 	defer m.mx.RUnlock()
 
-	return m.findEvent(ctx, ownerID, eventID)
+	it, err := m.findItem(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	return it.event, nil
 }
 
-func (m *Storage) findEvent(_ context.Context, ownerID model.OwnerID, eventID model.ID) (model.Event, error) {
-	events, exists := m.userMap[ownerID]
+func (m *Storage) findItem(
+	_ context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (*item, error) {
+	tenant, exists := m.tenants[tenantID]
 	if !exists {
-		return model.Event{}, storage.ErrEventNotFound
+		return nil, storage.ErrEventNotFound
 	}
 
-	i := findEventIndex(events, eventID)
-	if i == -1 {
-		return model.Event{}, storage.ErrEventNotFound
+	it, exists := tenant.index[itemKey{ownerID: ownerID, eventID: eventID}]
+	if !exists {
+		return nil, storage.ErrEventNotFound
 	}
 
-	return events[i], nil
+	return it, nil
 }
 
 func (m *Storage) UpdateEvent(ctx context.Context, event model.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mx.Lock()
 	defer m.mx.Unlock()
 
@@ -85,20 +216,17 @@ func (m *Storage) UpdateEvent(ctx context.Context, event model.Event) error {
 }
 
 func (m *Storage) updateEvent(ctx context.Context, event model.Event) error {
-	oldEvent, err := m.findEvent(ctx, event.OwnerID(), event.EventID())
+	oldItem, err := m.findItem(ctx, event.TenantID(), event.OwnerID(), event.EventID())
 	if err != nil {
 		return err
 	}
 
-	err = m.deleteEvent(ctx, oldEvent.OwnerID(), oldEvent.EventID())
-	if err != nil {
-		return fmt.Errorf("can't delete old event while update: %w", err)
-	}
+	m.deleteItem(oldItem)
 
 	if err := m.addEvent(ctx, event); err != nil {
 		err = fmt.Errorf("can't add updated event: %w", err)
 
-		if revertErr := m.addEvent(ctx, oldEvent); revertErr != nil {
+		if revertErr := m.addEvent(ctx, oldItem.event); revertErr != nil {
 			revertErr = fmt.Errorf("can't revert updated event: %w", revertErr)
 			err = errors.Join(err, revertErr)
 		}
@@ -109,172 +237,669 @@ func (m *Storage) updateEvent(ctx context.Context, event model.Event) error {
 	return nil
 }
 
-func (m *Storage) DeleteEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) error {
+func (m *Storage) DeleteEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mx.Lock()
 	defer m.mx.Unlock()
 
-	return m.deleteEvent(ctx, ownerID, eventID)
+	it, err := m.findItem(ctx, tenantID, ownerID, eventID)
+	if err != nil {
+		return err
+	}
+
+	m.deleteItem(it)
+
+	return nil
 }
 
-func (m *Storage) deleteEvent(_ context.Context, ownerID model.OwnerID, eventID model.ID) error {
-	events, exists := m.userMap[ownerID]
-	if !exists {
-		return storage.ErrEventNotFound
-	}
+// deleteItem удаляет событие из всех индексов хранилища.
+func (m *Storage) deleteItem(it *item) {
+	m.unlinkItem(it)
 
-	i := findEventIndex(events, eventID)
-	if i == -1 {
-		return storage.ErrEventNotFound
+	if it.event.NotifyBefore > 0 {
+		m.notify.Delete(it)
 	}
+}
 
-	events = append(events[:i], events[i+1:]...)
-	m.userMap[ownerID] = events
+// unlinkItem удаляет событие из индексов рабочего пространства, но не из индекса уведомлений.
+func (m *Storage) unlinkItem(it *item) {
+	tenantID := it.event.TenantID()
+	ownerID := it.event.OwnerID()
 
-	return nil
+	if tenant, exists := m.tenants[tenantID]; exists {
+		if tree, exists := tenant.owners[ownerID]; exists {
+			tree.Delete(it)
+
+			if tree.Len() == 0 {
+				delete(tenant.owners, ownerID)
+			}
+		}
+
+		delete(tenant.index, itemKey{ownerID: ownerID, eventID: it.event.EventID()})
+		m.dropTenantIfEmpty(tenantID)
+	}
 }
 
 func (m *Storage) QueryEvents(
-	_ context.Context,
+	ctx context.Context,
+	tenantID model.TenantID,
 	ownerID model.OwnerID,
 	from time.Time,
 	to time.Time,
+	filter model.Filter,
 ) ([]model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// пустой промежуток [from, to) не пересекается ни с одним событием
+	if !from.Before(to) {
+		return nil, nil
+	}
+
 	m.mx.RLock()
 	defer m.mx.RUnlock()
 
-	events, exists := m.userMap[ownerID]
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		return nil, nil
+	}
 
+	tree, exists := tenant.owners[ownerID]
 	if !exists {
 		return nil, nil
 	}
 
-	k, l := -1, -1
-	for i := range len(events) {
-		if events[i].EndAt().Before(from) || events[i].EndAt().Equal(from) ||
-			events[i].StartAt().After(to) || events[i].StartAt().Equal(to) {
-			continue
+	var events []model.Event
+	tree.Query(from, to, func(it *item) bool {
+		if filter.Match(it.event) {
+			events = append(events, it.event)
 		}
 
-		if k == -1 {
-			k = i
-		} else {
-			l = i
-		}
+		return true
+	})
+
+	return events, nil
+}
+
+func (m *Storage) QueryEventsNear(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	area model.Area,
+) ([]model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	if k == -1 {
+	if !from.Before(to) {
 		return nil, nil
 	}
 
-	if l < k {
-		l = k
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		return nil, nil
 	}
 
-	return events[k : l+1], nil
+	tree, exists := tenant.owners[ownerID]
+	if !exists {
+		return nil, nil
+	}
+
+	// отдельного пространственного индекса нет: события промежутка проверяются по расстоянию до центра области
+	var events []model.Event
+	tree.Query(from, to, func(it *item) bool {
+		if storage.InArea(it.event, area) {
+			events = append(events, it.event)
+		}
+
+		return true
+	})
+
+	return events, nil
 }
 
-func (m *Storage) PurgeOldEvents(ctx context.Context, olderThan time.Time) error {
+func (m *Storage) AddLabel(ctx context.Context, label model.OwnerLabel) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mx.Lock()
 	defer m.mx.Unlock()
 
-	return m.purgeOldEvents(ctx, olderThan)
+	tenant := m.tenant(label.TenantID())
+
+	catalog, exists := tenant.labels[label.OwnerID()]
+	if !exists {
+		catalog = map[model.Label]model.OwnerLabel{}
+		tenant.labels[label.OwnerID()] = catalog
+	}
+
+	if _, exists := catalog[label.Name()]; exists {
+		return storage.ErrLabelAlreadyExists
+	}
+
+	catalog[label.Name()] = label
+
+	return nil
 }
 
-func (m *Storage) purgeOldEvents(_ context.Context, olderThan time.Time) error { //nolint:unparam
-	for ownerID, events := range m.userMap {
-		l := len(events)
-		for i := l - 1; i >= 0; i-- {
-			println(i, len(events))
-			if events[i].EndAt().Before(olderThan) {
-				events = append(events[:i], events[i+1:]...)
-				l--
+func (m *Storage) UpdateLabel(ctx context.Context, label model.OwnerLabel) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	catalog, err := m.findCatalog(label.TenantID(), label.OwnerID(), label.Name())
+	if err != nil {
+		return err
+	}
+
+	catalog[label.Name()] = label
+
+	return nil
+}
+
+func (m *Storage) DeleteLabel(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name model.Label,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	catalog, err := m.findCatalog(tenantID, ownerID, name)
+	if err != nil {
+		return err
+	}
+
+	tenant := m.tenants[tenantID]
+
+	delete(catalog, name)
+	if len(catalog) == 0 {
+		delete(tenant.labels, ownerID)
+	}
+
+	// события хранятся по значению и могут быть уже выданы вызывающей стороне,
+	// поэтому метки события заменяются новым срезом
+	if tree, exists := tenant.owners[ownerID]; exists {
+		tree.Ascend(func(it *item) bool {
+			if slices.Contains(it.event.Labels, name) {
+				labels := slices.DeleteFunc(slices.Clone(it.event.Labels), func(l model.Label) bool { return l == name })
+				if len(labels) == 0 {
+					labels = nil
+				}
+
+				it.event.Labels = labels
 			}
+
+			return true
+		})
+	}
+
+	m.dropTenantIfEmpty(tenantID)
+
+	return nil
+}
+
+// findCatalog возвращает каталог меток владельца, в котором есть метка name, или ErrLabelNotFound.
+func (m *Storage) findCatalog(
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name model.Label,
+) (map[model.Label]model.OwnerLabel, error) {
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		return nil, storage.ErrLabelNotFound
+	}
+
+	catalog := tenant.labels[ownerID]
+	if _, exists := catalog[name]; !exists {
+		return nil, storage.ErrLabelNotFound
+	}
+
+	return catalog, nil
+}
+
+// FindLabel находит метку name в каталоге владельца ownerID в рабочем пространстве tenantID.
+func (m *Storage) FindLabel(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name model.Label,
+) (model.OwnerLabel, error) {
+	if err := ctx.Err(); err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	catalog, err := m.findCatalog(tenantID, ownerID, name)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	return catalog[name], nil
+}
+
+func (m *Storage) QueryLabels(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) ([]model.OwnerLabel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		return nil, nil
+	}
+
+	return sortedLabels(tenant.labels[ownerID]), nil
+}
+
+// Labels возвращает все метки каталогов хранилища.
+func (m *Storage) Labels() []model.OwnerLabel {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	var labels []model.OwnerLabel
+	for _, tenant := range m.tenants {
+		for _, catalog := range tenant.labels {
+			labels = append(labels, sortedLabels(catalog)...)
 		}
+	}
+
+	return labels
+}
 
-		m.userMap[ownerID] = events[0:l:l]
+// sortedLabels возвращает метки каталога catalog, упорядоченные по имени.
+func sortedLabels(catalog map[model.Label]model.OwnerLabel) []model.OwnerLabel {
+	if len(catalog) == 0 {
+		return nil
+	}
+
+	labels := make([]model.OwnerLabel, 0, len(catalog))
+	for _, label := range catalog {
+		labels = append(labels, label)
+	}
+
+	slices.SortFunc(labels, func(a, b model.OwnerLabel) int {
+		return strings.Compare(string(a.Name()), string(b.Name()))
+	})
+
+	return labels
+}
+
+func (m *Storage) FindProfile(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (model.Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Profile{}, err
 	}
 
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		return model.Profile{}, storage.ErrProfileNotFound
+	}
+
+	profile, exists := tenant.profiles[ownerID]
+	if !exists {
+		return model.Profile{}, storage.ErrProfileNotFound
+	}
+
+	profile.Holidays = slices.Clone(profile.Holidays)
+	profile.HolidayCalendars = slices.Clone(profile.HolidayCalendars)
+
+	return profile, nil
+}
+
+func (m *Storage) SaveProfile(ctx context.Context, profile model.Profile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	profile.Holidays = slices.Clone(profile.Holidays)
+	profile.HolidayCalendars = slices.Clone(profile.HolidayCalendars)
+	m.tenant(profile.TenantID()).profiles[profile.OwnerID()] = profile
+
 	return nil
 }
 
-func (m *Storage) QueryEventsToNotify(
-	_ context.Context,
-	from time.Time,
-	to time.Time,
-) ([]model.Event, error) {
+// Profiles возвращает все профили владельцев хранилища.
+func (m *Storage) Profiles() []model.Profile {
 	m.mx.RLock()
 	defer m.mx.RUnlock()
 
-	var events []model.Event
+	var profiles []model.Profile
+	for _, tenant := range m.tenants {
+		for _, profile := range tenant.profiles {
+			profiles = append(profiles, profile)
+		}
+	}
 
-	for _, userEvents := range m.userMap {
-		for _, ev := range userEvents {
-			if ev.NotifyBefore == 0 {
-				continue
-			}
+	return profiles
+}
 
-			startAt := ev.StartAt().Add(time.Duration(-ev.NotifyBefore) * 24 * time.Hour)
-			if (from.Before(startAt) || from.Equal(startAt)) && startAt.Before(to) {
-				events = append(events, ev)
-			}
+func (m *Storage) AddFeedToken(ctx context.Context, token model.FeedToken, maxTokens int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	count := 0
+	for _, t := range m.feedTokens {
+		if t.Hash() == token.Hash() || t.TokenID() == token.TokenID() {
+			return storage.ErrFeedTokenAlreadyExists
+		}
+
+		if t.TenantID() == token.TenantID() && t.OwnerID() == token.OwnerID() {
+			count++
 		}
 	}
 
-	return events, nil
+	if maxTokens > 0 && count >= maxTokens {
+		return model.ErrTooManyFeedTokens
+	}
+
+	m.feedTokens[token.Hash()] = token
+
+	return nil
 }
 
-// findNewEventIndex пытается найти индекс в слайсе events для нового события event.
-// Все элементы слайса с найденным индексом и выше должны располагаться "правее" элемента event
-// после его добавления слайс.
-// Возвращает -1, если событие event не может быть добавлено в слайс events:
-// это происходит, когда время события event пересекается со временем событий в слайсе events.
-func findNewEventIndex(events Events, event model.Event) int {
-	if len(events) == 0 {
-		return 0
+func (m *Storage) QueryFeedTokens(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) ([]model.FeedToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	k := -1
-	for i := range len(events) {
-		if events[i].StartAt().After(event.StartAt()) {
-			k = i
-			break
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	var tokens []model.FeedToken
+	for _, token := range m.feedTokens {
+		if token.TenantID() == tenantID && token.OwnerID() == ownerID {
+			tokens = append(tokens, token)
 		}
 	}
 
-	if k == -1 {
-		k = len(events)
+	sortFeedTokens(tokens)
+
+	return tokens, nil
+}
+
+func (m *Storage) DeleteFeedToken(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	tokenID model.FeedTokenID,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
 
-		if events[k-1].EndAt().Before(event.StartAt()) || events[k-1].EndAt().Equal(event.StartAt()) {
-			return k
+	for hash, token := range m.feedTokens {
+		if token.TenantID() == tenantID && token.OwnerID() == ownerID && token.TokenID() == tokenID {
+			delete(m.feedTokens, hash)
+			return nil
 		}
+	}
+
+	return storage.ErrFeedTokenNotFound
+}
 
-		return -1
+func (m *Storage) FindFeedToken(ctx context.Context, hash model.FeedTokenHash) (model.FeedToken, error) {
+	if err := ctx.Err(); err != nil {
+		return model.FeedToken{}, err
 	}
 
-	if k == 0 {
-		if events[k].StartAt().After(event.EndAt()) || events[k].StartAt().Equal(event.EndAt()) {
-			return k
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	token, exists := m.feedTokens[hash]
+	if !exists {
+		return model.FeedToken{}, storage.ErrFeedTokenNotFound
+	}
+
+	return token, nil
+}
+
+// FeedTokens возвращает все ссылки на календари хранилища, упорядоченные по времени создания.
+func (m *Storage) FeedTokens() []model.FeedToken {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	tokens := make([]model.FeedToken, 0, len(m.feedTokens))
+	for _, token := range m.feedTokens {
+		tokens = append(tokens, token)
+	}
+
+	sortFeedTokens(tokens)
+
+	return tokens
+}
+
+// sortFeedTokens упорядочивает ссылки на календари tokens по времени создания и идентификатору.
+func sortFeedTokens(tokens []model.FeedToken) {
+	slices.SortFunc(tokens, func(a, b model.FeedToken) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
 		}
 
-		return -1
+		return strings.Compare(string(a.TokenID()), string(b.TokenID()))
+	})
+}
+
+// Events возвращает все события хранилища.
+func (m *Storage) Events() []model.Event {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	var events []model.Event
+	for _, tenant := range m.tenants {
+		for _, it := range tenant.index {
+			events = append(events, it.event)
+		}
 	}
 
-	if (events[k-1].EndAt().Before(event.StartAt()) || events[k-1].EndAt().Equal(event.StartAt())) &&
-		(events[k].StartAt().After(event.EndAt()) || events[k].StartAt().Equal(event.EndAt())) {
-		return k
+	return events
+}
+
+func (m *Storage) CountTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		return 0, nil
 	}
 
-	return -1
+	return len(tenant.index), nil
 }
 
-// findEventIndex пытается найти индекс элемента в слайсе events для указанного eventID.
-// Возращает -1, если событие с eventID не найдено в слайсе events.
-func findEventIndex(events Events, eventID model.ID) int {
-	for i := range len(events) {
-		if events[i].EventID() == eventID {
-			return i
+func (m *Storage) QueryTenantEvents(ctx context.Context, tenantID model.TenantID) ([]model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		return nil, nil
+	}
+
+	ownerIDs := make([]model.OwnerID, 0, len(tenant.owners))
+	for ownerID := range tenant.owners {
+		ownerIDs = append(ownerIDs, ownerID)
+	}
+	sort.Slice(ownerIDs, func(i, j int) bool { return ownerIDs[i] < ownerIDs[j] })
+
+	events := make([]model.Event, 0, len(tenant.index))
+	for _, ownerID := range ownerIDs {
+		tenant.owners[ownerID].Ascend(func(it *item) bool {
+			events = append(events, it.event)
+			return true
+		})
+	}
+
+	return events, nil
+}
+
+func (m *Storage) DeleteTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	for hash, token := range m.feedTokens {
+		if token.TenantID() == tenantID {
+			delete(m.feedTokens, hash)
 		}
 	}
 
-	return -1
+	tenant, exists := m.tenants[tenantID]
+	if !exists {
+		return 0, nil
+	}
+
+	// индексы пространства удаляются целиком, из общего индекса уведомлений - за один проход
+	m.notify.DeleteFunc(func(it *item) bool {
+		return it.event.TenantID() == tenantID
+	})
+
+	delete(m.tenants, tenantID)
+
+	return len(tenant.index), nil
+}
+
+func (m *Storage) PurgeOldEvents(ctx context.Context, olderThan time.Time) ([]model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	return m.purgeOldEvents(ctx, olderThan)
+}
+
+func (m *Storage) purgeOldEvents(_ context.Context, olderThan time.Time) ([]model.Event, error) { //nolint:unparam
+	var items []*item
+
+	for _, tenant := range m.tenants {
+		for _, tree := range tenant.owners {
+			// события владельца не пересекаются, поэтому упорядочены в дереве и по EndAt
+			tree.Ascend(func(it *item) bool {
+				if !it.event.EndAt().Before(olderThan) {
+					return false
+				}
+
+				items = append(items, it)
+				return true
+			})
+		}
+	}
+
+	events := make([]model.Event, 0, len(items))
+	purged := make(map[*item]struct{}, len(items))
+	for _, it := range items {
+		m.unlinkItem(it)
+		purged[it] = struct{}{}
+		events = append(events, it.event)
+	}
+
+	// из индекса уведомлений удалённые события удаляются за один проход
+	if len(purged) > 0 {
+		m.notify.DeleteFunc(func(it *item) bool {
+			_, ok := purged[it]
+			return ok
+		})
+	}
+
+	return events, nil
+}
+
+func (m *Storage) QueryEventsToNotify(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) ([]model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	var events []model.Event
+	m.notify.Query(from, to, func(it *item) {
+		events = append(events, it.event)
+	})
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].StartAt().Before(events[j].StartAt())
+	})
+
+	return events, nil
+}
+
+func newItem(event model.Event) *item {
+	it := &item{
+		event: event,
+	}
+
+	if event.NotifyBefore > 0 {
+		it.notifyAt = event.StartAt().Add(time.Duration(-event.NotifyBefore) * 24 * time.Hour)
+	}
+
+	return it
 }
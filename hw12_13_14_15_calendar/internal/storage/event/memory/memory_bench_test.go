@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// benchEventsPerOwner - количество событий у владельца в бенчмарках.
+const benchEventsPerOwner = 100_000
+
+// benchStorage создаёт хранилище с benchEventsPerOwner событиями для одного владельца:
+// события по 30 минут с интервалом в 1 час, у каждого десятого события есть уведомление.
+func benchStorage(b *testing.B) (*Storage, model.OwnerID, []model.Event, time.Time) {
+	b.Helper()
+
+	now := time.Now().Truncate(time.Hour)
+	ownerID := model.NewOwnerID()
+	events := make([]model.Event, benchEventsPerOwner)
+
+	for i := range events {
+		startAt := now.Add(time.Duration(i) * time.Hour)
+
+		event, err := model.NewEvent(
+			model.DefaultTenantID,
+			model.NewID(),
+			ownerID,
+			"event",
+			startAt,
+			startAt.Add(30*time.Minute),
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if i%10 == 0 {
+			event.NotifyBefore = 1
+		}
+
+		events[i] = event
+	}
+
+	storage := NewStorage()
+
+	// добавляем в случайном порядке
+	r := rand.New(rand.NewSource(1))
+	for _, i := range r.Perm(len(events)) {
+		if err := storage.AddEvent(context.Background(), events[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return storage, ownerID, events, now
+}
+
+// go test -run=^$ -bench=. -benchmem ./internal/storage/event/memory/
+func BenchmarkStorage_AddDeleteEvent(b *testing.B) {
+	storage, ownerID, _, now := benchStorage(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := range b.N {
+		// свободное время между событиями
+		startAt := now.Add(time.Duration(i%benchEventsPerOwner)*time.Hour + 45*time.Minute)
+
+		event, _ := model.NewEvent(
+			model.DefaultTenantID,
+			model.NewID(),
+			ownerID,
+			"event",
+			startAt,
+			startAt.Add(10*time.Minute),
+		)
+		event.NotifyBefore = 1
+
+		if err := storage.AddEvent(ctx, event); err != nil {
+			b.Fatal(err)
+		}
+
+		if err := storage.DeleteEvent(ctx, model.DefaultTenantID, ownerID, event.EventID()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStorage_AddEventTimeIsBusy(b *testing.B) {
+	storage, ownerID, events, _ := benchStorage(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := range b.N {
+		ev := events[i%len(events)]
+		event, _ := model.NewEvent(model.DefaultTenantID, model.NewID(), ownerID, "event", ev.StartAt(), ev.EndAt())
+
+		if err := storage.AddEvent(ctx, event); err == nil {
+			b.Fatal("must have error")
+		}
+	}
+}
+
+func BenchmarkStorage_FindEvent(b *testing.B) {
+	storage, ownerID, events, _ := benchStorage(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := storage.FindEvent(ctx, model.DefaultTenantID, ownerID, events[i%len(events)].EventID()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStorage_UpdateEvent(b *testing.B) {
+	storage, _, events, _ := benchStorage(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := range b.N {
+		event := events[i%len(events)]
+		event.NotifyBefore = uint(i % 3)
+
+		if err := storage.UpdateEvent(ctx, event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStorage_QueryEvents(b *testing.B) {
+	storage, ownerID, _, now := benchStorage(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := range b.N {
+		// неделя событий
+		from := now.Add(time.Duration(i%(benchEventsPerOwner-7*24)) * time.Hour)
+
+		to := from.Add(7 * 24 * time.Hour)
+
+		events, err := storage.QueryEvents(ctx, model.DefaultTenantID, ownerID, from, to, model.Filter{})
+		if err != nil || len(events) != 7*24 {
+			b.Fatal(len(events), err)
+		}
+	}
+}
+
+func BenchmarkStorage_QueryEventsToNotify(b *testing.B) {
+	storage, _, _, now := benchStorage(b)
+	ctx := context.Background()
+
+	// как в планировщике: каждый следующий запрос начинается с окончания предыдущего
+	from := now.Add(-24 * time.Hour)
+
+	b.ResetTimer()
+	for range b.N {
+		to := from.Add(time.Hour)
+
+		if _, err := storage.QueryEventsToNotify(ctx, from, to); err != nil {
+			b.Fatal(err)
+		}
+
+		from = to
+	}
+}
@@ -9,23 +9,9 @@ import (
 
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
 	modelStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/storagetest"
 )
 
-type populateArgs struct {
-	now      time.Time
-	times    [][2]time.Time
-	eventIDs [3]model.ID
-	ownerIDs [3]model.OwnerID
-}
-
-func mkTitle(t *testing.T, title string) model.Title {
-	t.Helper()
-
-	eventTitle, err := model.NewTitle(title)
-	require.NoErrorf(t, err, "must not have error while create an event title %s", title)
-	return eventTitle
-}
-
 func mkEvent(
 	t *testing.T,
 	eventID model.ID,
@@ -37,413 +23,152 @@ func mkEvent(
 ) model.Event {
 	t.Helper()
 
-	event, err := model.NewEvent(eventID, ownerID, title, startAt, endAt)
-	require.NoErrorf(t, err, "must not have error while create an event %s", title)
+	return storagetest.MkEvent(t, eventID, ownerID, title, startAt, endAt, notifyBefore)
+}
 
-	event.NotifyBefore = notifyBefore
+// tenantOf возвращает события рабочего пространства по умолчанию, в котором создаются события тестов.
+func tenantOf(storage *Storage) *tenantEvents {
+	if tenant, exists := storage.tenants[model.DefaultTenantID]; exists {
+		return tenant
+	}
 
-	return event
+	return newTenantEvents()
 }
 
-func populate(t *testing.T) (*Storage, populateArgs) {
+func populate(t *testing.T) (*Storage, storagetest.PopulateArgs) {
 	t.Helper()
 
-	/*
-	*    ...... 1h ...... ... 1h ... ...... 1h ...... ... 1h ... ...... 1h ......
-	*    [now+1h, now+2h) .......... [now+3h, now+4h) .......... [now+5h, now+6h)
-	 */
-	now := time.Now()
-	times := [][2]time.Time{
-		{now.Add(1 * time.Hour), now.Add(2 * time.Hour)},
-		{now.Add(3 * time.Hour), now.Add(4 * time.Hour)},
-		{now.Add(5 * time.Hour), now.Add(6 * time.Hour)},
-	}
-	eventIDs := [...]model.ID{model.NewID(), model.NewID(), model.NewID()}
-	ownerIDs := [...]model.OwnerID{model.NewOwnerID(), model.NewOwnerID(), model.NewOwnerID()}
-
-	tests := []struct {
-		name  string
-		event model.Event
-		err   error
-	}{
-		{
-			name:  "event#1 user#1",
-			event: mkEvent(t, eventIDs[0], ownerIDs[0], "1", times[1][0], times[1][1], 5),
-			err:   nil,
-		},
-		{
-			name:  "event#1 user#1 duplicate",
-			event: mkEvent(t, eventIDs[0], ownerIDs[0], "1", times[1][0], times[1][1], 5),
-			err:   modelStorage.ErrEventAlreadyExists,
-		},
-		{
-			name:  "event#2 user#1 time overlap",
-			event: mkEvent(t, eventIDs[1], ownerIDs[0], "2", times[1][0], times[1][1], 5),
-			err:   modelStorage.ErrTimeIsBusy,
-		},
-		{
-			name:  "event#2 user#1",
-			event: mkEvent(t, eventIDs[1], ownerIDs[0], "2", times[0][0], times[0][1], 0),
-			err:   nil,
-		},
-		{
-			name:  "event#1 user#3",
-			event: mkEvent(t, eventIDs[0], ownerIDs[2], "1", times[1][0], times[1][1], 5),
-			err:   nil,
-		},
-		{
-			name:  "event#2 user#3",
-			event: mkEvent(t, eventIDs[1], ownerIDs[2], "2", times[0][0], times[0][1], 0),
-			err:   nil,
-		},
-	}
-
 	storage := NewStorage()
+	pargs := storagetest.Populate(t, storage)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := storage.AddEvent(context.Background(), tt.event)
-			if tt.err == nil {
-				require.NoError(t, err, "must not have error")
-			} else {
-				require.Error(t, err, "must have error")
-				require.ErrorIsf(t, err, tt.err, "error must be %v", tt.err)
-			}
-		})
-	}
-
-	return storage, populateArgs{
-		now:      now,
-		times:    times,
-		eventIDs: eventIDs,
-		ownerIDs: ownerIDs,
-	}
+	return storage, pargs
 }
 
-func TestMemory_AddEvent(t *testing.T) {
-	storage, pargs := populate(t)
-
-	events := storage.userMap[pargs.ownerIDs[0]]
-	require.Len(t, events, 2, "must have 2 events for user #1")
-
-	t.Run("proper order", func(t *testing.T) {
-		require.Equal(t, 0, findEventIndex(events, pargs.eventIDs[1]), "event #2 must be at 0")
-		require.Equal(t, 1, findEventIndex(events, pargs.eventIDs[0]), "event #1 must be at 1")
+func TestMemory_Storage(t *testing.T) {
+	storagetest.Run(t, func(*testing.T) modelStorage.Storage {
+		return NewStorage()
 	})
-
-	events = storage.userMap[pargs.ownerIDs[2]]
-	require.Len(t, events, 2, "must have 2 events for user #3")
 }
 
-func TestMemory_FindEvent(t *testing.T) {
+func TestMemory_AddEvent(t *testing.T) {
 	storage, pargs := populate(t)
 
-	type args struct {
-		ownerID model.OwnerID
-		eventID model.ID
-	}
-	tests := []struct {
-		name string
-		args args
-		err  error
-	}{
-		{
-			name: "user#1 event#1",
-			args: args{pargs.ownerIDs[0], pargs.eventIDs[0]},
-			err:  nil,
-		},
-		{
-			name: "user#1 event#2",
-			args: args{pargs.ownerIDs[0], pargs.eventIDs[1]},
-			err:  nil,
-		},
-		{
-			name: "user#1 event#3",
-			args: args{pargs.ownerIDs[0], pargs.eventIDs[2]},
-			err:  modelStorage.ErrEventNotFound,
-		},
-		{
-			name: "user#2 event#1",
-			args: args{pargs.ownerIDs[1], pargs.eventIDs[0]},
-			err:  modelStorage.ErrEventNotFound,
-		},
-		{
-			name: "user#3 event#1",
-			args: args{pargs.ownerIDs[2], pargs.eventIDs[0]},
-			err:  nil,
-		},
-		{
-			name: "user#3 event#2",
-			args: args{pargs.ownerIDs[2], pargs.eventIDs[1]},
-			err:  nil,
-		},
-	}
+	require.Equal(t, 2, tenantOf(storage).owners[pargs.OwnerIDs[0]].Len(), "must have 2 events for user #1")
+	require.Equal(t, 2, tenantOf(storage).owners[pargs.OwnerIDs[2]].Len(), "must have 2 events for user #3")
+	require.NotContains(t, tenantOf(storage).owners, pargs.OwnerIDs[1], "must have no tree for user #2")
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			event, err := storage.FindEvent(
-				context.Background(),
-				tt.args.ownerID,
-				tt.args.eventID,
-			)
-
-			if tt.err == nil {
-				require.NoError(t, err, "must not have error")
-				require.EqualValues(t, tt.args.ownerID, event.OwnerID(), "ownerID must be equal")
-				require.EqualValues(t, tt.args.eventID, event.EventID(), "eventID must be equal")
-			} else {
-				require.Error(t, err, "must have error")
-				require.ErrorIsf(t, err, tt.err, "error must be %v", tt.err)
-			}
-		})
-	}
+	require.Len(t, tenantOf(storage).index, 4, "must have 4 events in index")
+	require.Equal(t, 2, storage.notify.Len(), "must have 2 events to notify")
 }
 
 func TestMemory_UpdateEvent(t *testing.T) {
 	storage, pargs := populate(t)
 
-	t.Run("success", func(t *testing.T) {
-		events := storage.userMap[pargs.ownerIDs[0]]
-		require.Len(t, events, 2, "must have 2 events for user #1")
-
-		event := mkEvent(t, pargs.eventIDs[1], pargs.ownerIDs[0], "2", pargs.times[2][0], pargs.times[2][1], 0)
+	t.Run("notify removed", func(t *testing.T) {
+		event := mkEvent(t, pargs.EventIDs[0], pargs.OwnerIDs[0], "1", pargs.Times[2][0], pargs.Times[2][1], 0)
 
 		err := storage.UpdateEvent(context.Background(), event)
 		require.NoError(t, err, "must not have error")
-
-		t.Run("proper order", func(t *testing.T) {
-			require.Equal(t, 0, findEventIndex(events, pargs.eventIDs[0]), "event #1 must be at 0")
-			require.Equal(t, 1, findEventIndex(events, pargs.eventIDs[1]), "event #2 must be at 1")
-		})
-	})
-
-	t.Run("not found", func(t *testing.T) {
-		event := mkEvent(t, pargs.eventIDs[2], pargs.ownerIDs[0], "2", pargs.times[2][0], pargs.times[2][1], 0)
-
-		err := storage.UpdateEvent(context.Background(), event)
-		require.Error(t, err, "must have error")
-		require.ErrorIs(t, err, modelStorage.ErrEventNotFound, "must be ErrEventNotFound error")
+		require.Equal(t, 1, storage.notify.Len(), "must have 1 event to notify")
 	})
 
-	t.Run("time is busy", func(t *testing.T) {
-		event := mkEvent(t, pargs.eventIDs[1], pargs.ownerIDs[0], "2", pargs.times[1][0], pargs.times[1][1], 0)
+	t.Run("time is busy reverted", func(t *testing.T) {
+		event := mkEvent(t, pargs.EventIDs[0], pargs.OwnerIDs[0], "1", pargs.Times[0][0], pargs.Times[0][1], 5)
 
 		err := storage.UpdateEvent(context.Background(), event)
-		require.Error(t, err, "must have error")
 		require.ErrorIs(t, err, modelStorage.ErrTimeIsBusy, "must be ErrTimeIsBusy error")
-	})
 
-	events := storage.userMap[pargs.ownerIDs[0]]
-	t.Run("order not changed", func(t *testing.T) {
-		require.Equal(t, 0, findEventIndex(events, pargs.eventIDs[0]), "event #1 must be at 0")
-		require.Equal(t, 1, findEventIndex(events, pargs.eventIDs[1]), "event #2 must be at 1")
+		require.Len(t, tenantOf(storage).index, 4, "must have 4 events in index")
+		require.Equal(t, 2, tenantOf(storage).owners[pargs.OwnerIDs[0]].Len(), "must have 2 events for user #1")
+		require.Equal(t, 1, storage.notify.Len(), "must have 1 event to notify")
 	})
 }
 
 func TestMemory_DeleteEvent(t *testing.T) {
 	storage, pargs := populate(t)
 
-	t.Run("no event for unknown user", func(t *testing.T) {
-		err := storage.DeleteEvent(context.Background(), model.NewOwnerID(), model.NewID())
-		require.Error(t, err, "must have error")
-		require.ErrorIs(t, err, modelStorage.ErrEventNotFound, "must be ErrEventNotFound error")
-	})
-
-	t.Run("no event for user", func(t *testing.T) {
-		err := storage.DeleteEvent(context.Background(), pargs.ownerIDs[0], model.NewID())
-		require.Error(t, err, "must have error")
-		require.ErrorIs(t, err, modelStorage.ErrEventNotFound, "must be ErrEventNotFound error")
-	})
+	err := storage.DeleteEvent(context.Background(), model.DefaultTenantID, pargs.OwnerIDs[0], pargs.EventIDs[0])
+	require.NoError(t, err, "must not have error")
 
-	t.Run("success", func(t *testing.T) {
-		err := storage.DeleteEvent(context.Background(), pargs.ownerIDs[0], pargs.eventIDs[0])
-		require.NoError(t, err, "must not have error")
+	require.Len(t, tenantOf(storage).index, 3, "must have 3 events in index")
+	require.Equal(t, 1, storage.notify.Len(), "must have 1 event to notify")
 
-		events := storage.userMap[pargs.ownerIDs[0]]
-		require.Len(t, events, 1, "proper len")
-		require.Equal(t, 0, findEventIndex(events, pargs.eventIDs[1]), "event #2 must be at 0")
-	})
+	err = storage.DeleteEvent(context.Background(), model.DefaultTenantID, pargs.OwnerIDs[0], pargs.EventIDs[1])
+	require.NoError(t, err, "must not have error")
+	require.NotContains(t, tenantOf(storage).owners, pargs.OwnerIDs[0], "empty tree must be removed")
 }
 
-func TestMemory_QueryEvents(t *testing.T) {
+func TestMemory_DeleteTenantEvents(t *testing.T) {
 	storage, pargs := populate(t)
 
-	/*
-	         [=============]
-	  [---]                   [---]    before-after
-	  [------]             [------]    before edge-after edge
-	  [----------]     [----------]    before overlap startAt-after overlap endAt
-	         [---]     [---]           startAt inside-inside entAt
-	         [-------------]           equal
-	         [--------------------]    startAt overlap endAt
-	  [--------------------]           overlap startAt endAt
-	*/
-
-	tests := []struct {
-		name     string
-		from     time.Time
-		to       time.Time
-		evendIDs []model.ID
-	}{
-		{
-			name:     "before 1",
-			from:     pargs.times[0][0].Add(-time.Minute),
-			to:       pargs.times[0][0].Add(-time.Second),
-			evendIDs: []model.ID{},
-		},
-		{
-			name:     "after 1",
-			from:     pargs.times[0][1].Add(time.Second),
-			to:       pargs.times[0][1].Add(time.Minute),
-			evendIDs: []model.ID{},
-		},
-		{
-			name:     "before edge 1",
-			from:     pargs.times[0][0].Add(-time.Second),
-			to:       pargs.times[0][0],
-			evendIDs: []model.ID{},
-		},
-		{
-			name:     "after edge 1",
-			from:     pargs.times[0][1],
-			to:       pargs.times[0][1].Add(time.Second),
-			evendIDs: []model.ID{},
-		},
-		{
-			name:     "before overlap startAt 1",
-			from:     pargs.times[0][0].Add(-time.Second),
-			to:       pargs.times[0][0].Add(time.Second),
-			evendIDs: []model.ID{pargs.eventIDs[1]},
-		},
-		{
-			name:     "after overlap endAt 1",
-			from:     pargs.times[0][1].Add(-time.Second),
-			to:       pargs.times[0][1].Add(time.Second),
-			evendIDs: []model.ID{pargs.eventIDs[1]},
-		},
-		{
-			name:     "startAt inside 1",
-			from:     pargs.times[0][0],
-			to:       pargs.times[0][0].Add(time.Second),
-			evendIDs: []model.ID{pargs.eventIDs[1]},
-		},
-		{
-			name:     "inside endAt 1",
-			from:     pargs.times[0][1].Add(-time.Second),
-			to:       pargs.times[0][1],
-			evendIDs: []model.ID{pargs.eventIDs[1]},
-		},
-		{
-			name:     "equal 1",
-			from:     pargs.times[0][0],
-			to:       pargs.times[0][1],
-			evendIDs: []model.ID{pargs.eventIDs[1]},
-		},
-		{
-			name:     "startAt overlap endAt 1",
-			from:     pargs.times[0][0],
-			to:       pargs.times[0][1].Add(time.Second),
-			evendIDs: []model.ID{pargs.eventIDs[1]},
-		},
-		{
-			name:     "overlap startAt endAt 1",
-			from:     pargs.times[0][0].Add(-time.Second),
-			to:       pargs.times[0][1],
-			evendIDs: []model.ID{pargs.eventIDs[1]},
-		},
-		{
-			name:     "first+second overlap",
-			from:     pargs.times[0][0].Add(-time.Second),
-			to:       pargs.times[1][0].Add(time.Second),
-			evendIDs: []model.ID{pargs.eventIDs[1], pargs.eventIDs[0]},
-		},
-		{
-			name:     "first+second edge",
-			from:     pargs.times[0][1],
-			to:       pargs.times[1][0],
-			evendIDs: []model.ID{},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			events, _ := storage.QueryEvents(context.Background(), pargs.ownerIDs[0], tt.from, tt.to)
-			eventIDs := []model.ID{}
+	other := storagetest.MkTenantEvent(
+		t, "other", pargs.EventIDs[0], pargs.OwnerIDs[0], "1", pargs.Times[1][0], pargs.Times[1][1], 5,
+	)
+	require.NoError(t, storage.AddEvent(context.Background(), other), "must add event to other tenant")
+	require.Equal(t, 3, storage.notify.Len(), "must have 3 events to notify")
 
-			for _, e := range events {
-				eventIDs = append(eventIDs, e.EventID())
-			}
+	n, err := storage.DeleteTenantEvents(context.Background(), model.DefaultTenantID)
+	require.NoError(t, err, "must not have error")
+	require.Equal(t, 4, n, "must delete all events of tenant")
 
-			require.Equal(t, tt.evendIDs, eventIDs, "proper result")
-		})
-	}
+	require.NotContains(t, storage.tenants, model.DefaultTenantID, "tenant must be removed")
+	require.Len(t, storage.tenants["other"].index, 1, "other tenant must be kept")
+	require.Equal(t, 1, storage.notify.Len(), "events of tenant must be removed from index")
 }
 
-func Test_findNewEventIndex(t *testing.T) {
+func Test_intervalTreeOverlaps(t *testing.T) {
 	storage, pargs := populate(t)
-	events := storage.userMap[pargs.ownerIDs[0]]
+	tree := tenantOf(storage).owners[pargs.OwnerIDs[0]]
 
-	require.Len(t, events, 2, "proper len")
+	require.Equal(t, 2, tree.Len(), "proper len")
 
 	tests := []struct {
-		name    string
-		startAt time.Time
-		endAt   time.Time
-		pos     int
+		name     string
+		startAt  time.Time
+		endAt    time.Time
+		overlaps bool
 	}{
 		{
-			name:    "add at pos 0",
-			startAt: pargs.times[0][0].Add(-time.Second),
-			endAt:   pargs.times[0][0],
-			pos:     0,
+			name:     "free before first",
+			startAt:  pargs.Times[0][0].Add(-time.Second),
+			endAt:    pargs.Times[0][0],
+			overlaps: false,
 		},
 		{
-			name:    "add at pos 1",
-			startAt: pargs.times[0][1],
-			endAt:   pargs.times[1][0],
-			pos:     1,
+			name:     "free in-between",
+			startAt:  pargs.Times[0][1],
+			endAt:    pargs.Times[1][0],
+			overlaps: false,
 		},
 		{
-			name:    "add at pos 2",
-			startAt: pargs.times[1][1],
-			endAt:   pargs.times[1][1].Add(time.Second),
-			pos:     2,
+			name:     "free after last",
+			startAt:  pargs.Times[1][1],
+			endAt:    pargs.Times[1][1].Add(time.Second),
+			overlaps: false,
 		},
 		{
-			name:    "can't add at start",
-			startAt: pargs.times[0][0].Add(-time.Second),
-			endAt:   pargs.times[0][0].Add(time.Second),
-			pos:     -1,
+			name:     "busy at start",
+			startAt:  pargs.Times[0][0].Add(-time.Second),
+			endAt:    pargs.Times[0][0].Add(time.Second),
+			overlaps: true,
 		},
 		{
-			name:    "can't add in-between",
-			startAt: pargs.times[0][1].Add(-time.Second),
-			endAt:   pargs.times[1][0],
-			pos:     -1,
+			name:     "busy in-between",
+			startAt:  pargs.Times[0][1].Add(-time.Second),
+			endAt:    pargs.Times[1][0],
+			overlaps: true,
 		},
 		{
-			name:    "add at end",
-			startAt: pargs.times[1][1].Add(-time.Second),
-			endAt:   pargs.times[1][1].Add(time.Second),
-			pos:     -1,
+			name:     "busy at end",
+			startAt:  pargs.Times[1][1].Add(-time.Second),
+			endAt:    pargs.Times[1][1].Add(time.Second),
+			overlaps: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			i := findNewEventIndex(
-				events,
-				mkEvent(
-					t,
-					model.NewID(),
-					model.NewOwnerID(),
-					mkTitle(t, tt.name),
-					tt.startAt,
-					tt.endAt,
-					0,
-				),
-			)
-			require.Equal(t, tt.pos, i, "proper value")
+			require.Equal(t, tt.overlaps, tree.Overlaps(tt.startAt, tt.endAt), "proper value")
 		})
 	}
 }
@@ -451,56 +176,58 @@ func Test_findNewEventIndex(t *testing.T) {
 func Test_PurgeOldEvents(t *testing.T) {
 	storage, pargs := populate(t)
 
-	err := storage.PurgeOldEvents(
+	_, err := storage.PurgeOldEvents(
 		context.Background(),
-		pargs.times[1][0].Truncate(time.Second),
+		pargs.Times[1][0].Truncate(time.Second),
 	)
 	require.NoError(t, err, "must not have arror")
 
 	count := 0
-	for _, userEvents := range storage.userMap {
-		count += len(userEvents)
+	for _, tree := range tenantOf(storage).owners {
+		count += tree.Len()
 	}
 	require.Equal(t, 2, count, "must be proper value")
+	require.Len(t, tenantOf(storage).index, 2, "must be proper value")
+	require.Equal(t, 2, storage.notify.Len(), "must be proper value")
 }
 
 func TestMemory_QueryEventsToNotify(t *testing.T) {
 	storage, pargs := populate(t)
 
-	tests := []struct {
-		name     string
-		from     time.Time
-		to       time.Time
-		evendIDs []model.ID
-	}{
-		{
-			name: "need notify",
-			from: pargs.times[1][0].Truncate(time.Second).Add(-5 * 24 * time.Hour),
-			to:   pargs.times[1][0].Truncate(time.Second).Add(-5*24*time.Hour + time.Hour),
-			evendIDs: []model.ID{
-				pargs.eventIDs[0],
-				pargs.eventIDs[0],
-			},
-		},
-		{
-			name:     "no need notify",
-			from:     pargs.times[0][0].Truncate(time.Second).Add(-5 * 24 * time.Hour),
-			to:       pargs.times[0][0].Truncate(time.Second).Add(-5*24*time.Hour + time.Hour),
-			evendIDs: []model.ID{},
-		},
-	}
+	notifyAt := pargs.Times[1][0].Add(-5 * 24 * time.Hour)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			events, err := storage.QueryEventsToNotify(context.Background(), tt.from, tt.to)
-			require.NoError(t, err, "must not have arror")
+	t.Run("query does not change index", func(t *testing.T) {
+		events, err := storage.QueryEventsToNotify(context.Background(), notifyAt, notifyAt.Add(time.Hour))
+		require.NoError(t, err, "must not have arror")
+		require.Len(t, events, 2, "proper result")
+
+		events, err = storage.QueryEventsToNotify(context.Background(), notifyAt.Add(time.Hour), notifyAt.Add(2*time.Hour))
+		require.NoError(t, err, "must not have arror")
+		require.Empty(t, events, "proper result")
+		require.Equal(t, 2, storage.notify.Len(), "queried events must be kept in index")
+	})
 
-			eventIDs := []model.ID{}
-			for _, e := range events {
-				eventIDs = append(eventIDs, e.EventID())
-			}
+	t.Run("query from the past", func(t *testing.T) {
+		events, err := storage.QueryEventsToNotify(context.Background(), notifyAt, notifyAt.Add(time.Hour))
+		require.NoError(t, err, "must not have arror")
+		require.Len(t, events, 2, "proper result")
+	})
 
-			require.Equal(t, tt.evendIDs, eventIDs, "proper result")
-		})
-	}
+	t.Run("purge removes events from index", func(t *testing.T) {
+		later := mkEvent(t, pargs.EventIDs[2], pargs.OwnerIDs[1], "3", pargs.Times[2][0], pargs.Times[2][1], 5)
+		require.NoError(t, storage.AddEvent(context.Background(), later), "must add event")
+
+		_, err := storage.PurgeOldEvents(context.Background(), pargs.Times[1][1].Add(time.Second))
+		require.NoError(t, err, "must not have arror")
+		require.Equal(t, 1, storage.notify.Len(), "purged events must be removed from index")
+
+		events, err := storage.QueryEventsToNotify(context.Background(), notifyAt, notifyAt.Add(time.Hour))
+		require.NoError(t, err, "must not have arror")
+		require.Empty(t, events, "must not return purged events")
+
+		laterNotifyAt := pargs.Times[2][0].Add(-5 * 24 * time.Hour)
+		events, err = storage.QueryEventsToNotify(context.Background(), laterNotifyAt, laterNotifyAt.Add(time.Hour))
+		require.NoError(t, err, "must not have arror")
+		require.Len(t, events, 1, "must return kept event")
+	})
 }
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"time"
+)
+
+// notifyIndex - события, упорядоченные по времени отправки уведомления
+// (при равенстве - по рабочему пространству, владельцу и идентификатору события).
+//
+// В индексе хранятся только события, по которым необходимо отправлять уведомление (NotifyBefore > 0).
+// Поиск не изменяет индекс и выполняется за O(log n + k), поэтому допускает параллельное чтение.
+// Добавление и удаление - бинарный поиск и сдвиг элементов за O(n).
+type notifyIndex []*item
+
+// notifyCompare задаёт порядок событий в индексе.
+func notifyCompare(a *item, b *item) int {
+	return cmp.Or(
+		a.notifyAt.Compare(b.notifyAt),
+		cmp.Compare(a.event.TenantID(), b.event.TenantID()),
+		cmp.Compare(a.event.OwnerID(), b.event.OwnerID()),
+		cmp.Compare(a.event.EventID(), b.event.EventID()),
+	)
+}
+
+func (idx notifyIndex) Len() int {
+	return len(idx)
+}
+
+// Insert добавляет событие в индекс.
+func (idx *notifyIndex) Insert(it *item) {
+	i, _ := slices.BinarySearchFunc(*idx, it, notifyCompare)
+	*idx = slices.Insert(*idx, i, it)
+}
+
+// Delete удаляет событие из индекса.
+// Возвращает false, если событие не было найдено.
+func (idx *notifyIndex) Delete(it *item) bool {
+	i, found := slices.BinarySearchFunc(*idx, it, notifyCompare)
+	if !found || (*idx)[i] != it {
+		return false
+	}
+
+	*idx = slices.Delete(*idx, i, i+1)
+
+	return true
+}
+
+// DeleteFunc удаляет из индекса все события, для которых del вернула true, за один проход.
+func (idx *notifyIndex) DeleteFunc(del func(*item) bool) {
+	*idx = slices.DeleteFunc(*idx, del)
+}
+
+// Query вызывает fn для каждого события индекса, уведомление по которому необходимо отправить
+// в промежуток [from, to), в порядке возрастания времени отправки.
+func (idx notifyIndex) Query(from time.Time, to time.Time, fn func(*item)) {
+	i := sort.Search(len(idx), func(i int) bool {
+		return !idx[i].notifyAt.Before(from)
+	})
+
+	for ; i < len(idx) && idx[i].notifyAt.Before(to); i++ {
+		fn(idx[i])
+	}
+}
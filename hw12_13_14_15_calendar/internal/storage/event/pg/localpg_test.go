@@ -0,0 +1,133 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/migrate"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/migrations"
+)
+
+// startLocalPostgres запускает временный экземпляр postgres во временной директории
+// (без docker, с помощью initdb и pg_ctl) и применяет к нему миграции.
+// Возвращает строку подключения и функцию остановки экземпляра.
+func startLocalPostgres() (_ string, _ func(), err error) {
+	binDir, err := findPostgresBinDir()
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "calendar-pg-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	dataDir := filepath.Join(dir, "data")
+
+	initdb := exec.Command(
+		filepath.Join(binDir, "initdb"),
+		"-D", dataDir,
+		"-U", "calendar",
+		"--auth=trust",
+		"-E", "UTF8",
+	)
+	if out, err := initdb.CombinedOutput(); err != nil {
+		return "", nil, fmt.Errorf("initdb: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return "", nil, err
+	}
+
+	pgCtl := filepath.Join(binDir, "pg_ctl")
+
+	start := exec.Command(
+		pgCtl,
+		"-D", dataDir,
+		"-l", filepath.Join(dir, "postgres.log"),
+		"-o", fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir),
+		"-w",
+		"start",
+	)
+	if out, err := start.CombinedOutput(); err != nil {
+		return "", nil, fmt.Errorf("pg_ctl start: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	stop := func() {
+		exec.Command(pgCtl, "-D", dataDir, "-m", "immediate", "-w", "stop").Run()
+		os.RemoveAll(dir)
+	}
+
+	dataSource := fmt.Sprintf("postgresql://calendar@127.0.0.1:%d/postgres?sslmode=disable", port)
+	if err := applyMigrations(dataSource); err != nil {
+		stop()
+		return "", nil, err
+	}
+
+	return dataSource, stop, nil
+}
+
+// findPostgresBinDir ищет директорию с initdb и pg_ctl: в PATH и в типичных директориях установки.
+func findPostgresBinDir() (string, error) {
+	if path, err := exec.LookPath("initdb"); err == nil {
+		return filepath.Dir(path), nil
+	}
+
+	candidates, _ := filepath.Glob("/usr/lib/postgresql/*/bin")
+	sort.Sort(sort.Reverse(sort.StringSlice(candidates)))
+	candidates = append(candidates, "/usr/local/pgsql/bin", "/opt/homebrew/bin", "/usr/local/bin")
+
+	for _, dir := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, "initdb")); err == nil {
+			return dir, nil
+		}
+	}
+
+	return "", errors.New("initdb not found")
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	addr, _ := l.Addr().(*net.TCPAddr)
+
+	return addr.Port, nil
+}
+
+// applyMigrations применяет к базе встроенные миграции postgres.
+func applyMigrations(dataSource string) error {
+	db, err := sqlx.Connect("pgx", dataSource)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db.DB, migrate.DialectPostgres, migrations.Pg, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.Up(context.Background())
+
+	return err
+}
@@ -3,19 +3,30 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib" // импорт pgx.stdlib для регистрации драйвера в database/sql
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
 	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/tracing"
 )
 
 type pgEvent struct {
 	ID           int            `db:"id"`
+	TenantID     string         `db:"tenant_id"`
 	EventID      string         `db:"event_id"`
 	OwnerID      string         `db:"owner_id"`
 	StartAt      time.Time      `db:"start_at"`
@@ -23,51 +34,1016 @@ type pgEvent struct {
 	Title        string         `db:"title"`
 	Description  sql.NullString `db:"description"`
 	NotifyBefore uint           `db:"notify_before"`
+	Color        sql.NullString `db:"color"`
+
+	// Attachments - вложения события в JSON, см. storage.Attachment.
+	Attachments sql.NullString `db:"attachments"`
+
+	// Место проведения события: координаты заданы либо обе, либо ни одной.
+	LocationName    sql.NullString  `db:"location_name"`
+	LocationAddress sql.NullString  `db:"location_address"`
+	Latitude        sql.NullFloat64 `db:"latitude"`
+	Longitude       sql.NullFloat64 `db:"longitude"`
+	ConferenceURL   sql.NullString  `db:"conference_url"`
+
+	// Labels - метки события через запятую (метки не содержат запятых), см. model.NewLabel.
+	Labels sql.NullString `db:"labels"`
+}
+
+type pgLabel struct {
+	TenantID string         `db:"tenant_id"`
+	OwnerID  string         `db:"owner_id"`
+	Name     string         `db:"name"`
+	Color    sql.NullString `db:"color"`
+}
+
+// pgProfile - профиль доступности владельца, Profile - профиль в JSON, см. storage.Profile.
+type pgProfile struct {
+	TenantID string `db:"tenant_id"`
+	OwnerID  string `db:"owner_id"`
+	Profile  string `db:"profile"`
+}
+
+// pgFeedToken - ссылка на календарь владельца.
+type pgFeedToken struct {
+	TokenID   string         `db:"token_id"`
+	TenantID  string         `db:"tenant_id"`
+	OwnerID   string         `db:"owner_id"`
+	Hash      string         `db:"hash"`
+	Name      sql.NullString `db:"name"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// Ограничения таблиц events, labels и feed_tokens, см. миграции.
+const (
+	constraintUniqOwnerEventID = "uniq_tenant_owner_event_id"
+	constraintNoTimeOverlap    = "no_tenant_time_overlap"
+	constraintUniqOwnerLabel   = "uniq_tenant_owner_label"
+	constraintUniqFeedTokenID  = "uniq_feed_token_id"
+	constraintUniqFeedHash     = "uniq_feed_token_hash"
+)
+
+// Options - параметры подключения к postgres.
+type Options struct {
+	// DataSource - строка подключения к основной базе, используется для записи и чтения.
+	DataSource string
+
+	// Replicas - строки подключения к репликам, используются для чтения (по очереди).
+	// Если реплик нет, чтение выполняется из основной базы.
+	Replicas []string
+
+	// Параметры пула соединений (для основной базы и каждой реплики), см. sql.DB.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// StatementTimeout - ограничение времени выполнения запроса (statement_timeout), 0 - без ограничения.
+	StatementTimeout time.Duration
 }
 
 type Storage struct {
+	// DB - основная база.
 	DB *sqlx.DB
+
+	replicas []*sqlx.DB
+	next     atomic.Uint64
 }
 
 var _ storage.Storage = (*Storage)(nil)
 
-func NewStorage(dataSource string) (*Storage, error) {
-	db, err := sqlx.Connect("pgx", dataSource)
+// NewStorage подключается к основной базе и репликам согласно opts.
+//
+// Чтение (FindEvent, QueryEvents, QueryEventsToNotify, CountTenantEvents, QueryTenantEvents, QueryLabels)
+// выполняется из реплик, кроме случаев, когда контекст требует чтения из основной базы
+// (см. storage.WithReadYourWrites и storage.WithPrimary).
+func NewStorage(opts Options) (_ *Storage, err error) {
+	db, err := openDB(opts.DataSource, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Storage{DB: db}
+
+	defer func() {
+		if err != nil {
+			s.Close()
+		}
+	}()
+
+	for _, dataSource := range opts.Replicas {
+		replica, err := openDB(dataSource, opts)
+		if err != nil {
+			return nil, fmt.Errorf("can't connect to replica: %w", err)
+		}
+
+		s.replicas = append(s.replicas, replica)
+	}
+
+	return s, nil
+}
+
+// openDB открывает пул соединений к базе dataSource с параметрами opts и проверяет подключение.
+func openDB(dataSource string, opts Options) (*sqlx.DB, error) {
+	db, err := newDB(dataSource, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// newDB создаёт пул соединений к базе dataSource с параметрами opts, не подключаясь к базе.
+func newDB(dataSource string, opts Options) (*sqlx.DB, error) {
+	cfg, err := pgx.ParseConfig(dataSource)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Storage{DB: db}, nil
+	if opts.StatementTimeout > 0 {
+		cfg.RuntimeParams["statement_timeout"] = strconv.FormatInt(opts.StatementTimeout.Milliseconds(), 10)
+	}
+
+	db := sqlx.NewDb(stdlib.OpenDB(*cfg), "pgx")
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+
+	return db, nil
 }
 
+// Close закрывает соединения с основной базой и репликами.
 func (s *Storage) Close() error {
-	return s.DB.Close()
+	errs := []error{s.DB.Close()}
+	for _, replica := range s.replicas {
+		errs = append(errs, replica.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Ping проверяет доступность основной базы и всех реплик.
+func (s *Storage) Ping(ctx context.Context) error {
+	if err := s.DB.PingContext(ctx); err != nil {
+		return fmt.Errorf("primary: %w", err)
+	}
+
+	for i, replica := range s.replicas {
+		if err := replica.PingContext(ctx); err != nil {
+			return fmt.Errorf("replica %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Pools возвращает пулы соединений основной базы (primary) и реплик (replica_0, replica_1, ...),
+// например, для экспорта их статистики в метрики.
+func (s *Storage) Pools() map[string]*sql.DB {
+	pools := make(map[string]*sql.DB, len(s.replicas)+1)
+	pools["primary"] = s.DB.DB
+
+	for i, replica := range s.replicas {
+		pools["replica_"+strconv.Itoa(i)] = replica.DB
+	}
+
+	return pools
+}
+
+// reader возвращает базу для чтения с контекстом ctx: очередную реплику,
+// либо основную базу, если реплик нет или контекст требует чтения из основной базы.
+func (s *Storage) reader(ctx context.Context) *sqlx.DB {
+	if len(s.replicas) == 0 || storage.ReadFromPrimary(ctx) {
+		return s.DB
+	}
+
+	n := s.next.Add(1)
+
+	return s.replicas[n%uint64(len(s.replicas))]
 }
 
 func (s *Storage) AddEvent(ctx context.Context, event model.Event) (err error) {
+	ctx, span := startSpan(ctx, "AddEvent")
+	defer tracing.End(span, &err)
+
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		ev, err := toPgEvent(event)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NamedExecContext(
+			ctx,
+			`
+INSERT INTO
+  events (
+      tenant_id
+    , event_id
+    , owner_id
+    , time
+    , title
+    , description
+    , notify_before
+    , color
+    , attachments
+    , location_name
+    , location_address
+    , latitude
+    , longitude
+    , conference_url
+  )
+VALUES (
+    :tenant_id
+  , :event_id
+  , :owner_id
+  , tsrange(:start_at, :end_at)
+  , :title
+  , :description
+  , :notify_before
+  , :color
+  , :attachments
+  , :location_name
+  , :location_address
+  , :latitude
+  , :longitude
+  , :conference_url
+)`,
+			ev,
+		)
+		if err != nil {
+			return handleModelError(err)
+		}
+
+		return setEventLabels(ctx, tx, event)
+	})
+}
+
+func (s *Storage) UpdateEvent(ctx context.Context, event model.Event) (err error) {
+	ctx, span := startSpan(ctx, "UpdateEvent")
+	defer tracing.End(span, &err)
+
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		ev, err := toPgEvent(event)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.NamedExecContext(
+			ctx,
+			`
+UPDATE events
+SET
+    time             = tsrange(:start_at, :end_at)
+  , title            = :title
+  , description      = :description
+  , notify_before    = :notify_before
+  , color            = :color
+  , attachments      = :attachments
+  , location_name    = :location_name
+  , location_address = :location_address
+  , latitude         = :latitude
+  , longitude        = :longitude
+  , conference_url   = :conference_url
+
+WHERE tenant_id = :tenant_id
+  AND owner_id  = :owner_id
+  AND event_id  = :event_id`,
+			ev,
+		)
+		if err != nil {
+			return handleModelError(err)
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return storage.ErrEventNotFound
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM event_labels
+
+WHERE event_id = (
+  SELECT id
+  FROM events
+  WHERE tenant_id = $1
+    AND owner_id  = $2
+    AND event_id  = $3
+)`,
+			event.TenantID(), event.OwnerID(), event.EventID(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return setEventLabels(ctx, tx, event)
+	})
+}
+
+// setEventLabels отмечает событие event метками event.Labels из каталога владельца.
+// Возвращает ErrUnknownLabel, если какой-то метки нет в каталоге.
+func setEventLabels(ctx context.Context, tx *sqlx.Tx, event model.Event) error {
+	if len(event.Labels) == 0 {
+		return nil
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		`
+INSERT INTO
+  event_labels (
+      event_id
+    , label_id
+  )
+SELECT
+    e.id
+  , l.id
+
+FROM events e
+JOIN labels l
+  ON  l.tenant_id = e.tenant_id
+  AND l.owner_id  = e.owner_id
+
+WHERE e.tenant_id = $1
+  AND e.owner_id  = $2
+  AND e.event_id  = $3
+  AND l.name      = ANY($4)`,
+		event.TenantID(), event.OwnerID(), event.EventID(), labelsParam(event.Labels),
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if int(n) != len(event.Labels) {
+		return storage.ErrUnknownLabel
+	}
+
+	return nil
+}
+
+func (s *Storage) FindEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (_ model.Event, err error) {
+	ctx, span := startSpan(ctx, "FindEvent")
+	defer tracing.End(span, &err)
+
+	ev := pgEvent{}
+	err = s.reader(ctx).GetContext(
+		ctx,
+		&ev,
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , lower(time) AS start_at
+  , upper(time) AS end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT string_agg(l.name, ',' ORDER BY l.name)
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE tenant_id=$1
+  AND owner_id=$2
+  AND event_id=$3`,
+		tenantID, ownerID, eventID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = storage.ErrEventNotFound
+		}
+
+		return model.Event{}, err
+	}
+
+	event, err := toModel(ev)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	return event, nil
+}
+
+func (s *Storage) DeleteEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (err error) {
+	ctx, span := startSpan(ctx, "DeleteEvent")
+	defer tracing.End(span, &err)
+
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM events
+
+WHERE tenant_id = $1
+  AND owner_id  = $2
+  AND event_id  = $3`,
+			tenantID, ownerID, eventID,
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return storage.ErrEventNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) QueryEvents(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	filter model.Filter,
+) (_ []model.Event, err error) {
+	ctx, span := startSpan(ctx, "QueryEvents")
+	defer tracing.End(span, &err)
+
+	// пустой промежуток [from, to) не пересекается ни с одним событием
+	// (tsrange с from > to приводит к ошибке)
+	if !from.Before(to) {
+		return nil, nil
+	}
+
+	rows, err := s.reader(ctx).QueryxContext(
+		ctx,
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , lower(time) AS start_at
+  , upper(time) AS end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT string_agg(l.name, ',' ORDER BY l.name)
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE tenant_id = $1
+  AND owner_id  = $2
+  AND time && tsrange($3, $4)
+  AND (
+       $5::text[] IS NULL
+    OR EXISTS (
+         SELECT 1
+         FROM event_labels el
+         JOIN labels l ON l.id = el.label_id
+         WHERE el.event_id = events.id
+           AND l.name = ANY($5)
+       )
+  )
+
+ORDER BY start_at`,
+		tenantID, ownerID, from, to, labelsParam(filter.Labels),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+func (s *Storage) QueryEventsNear(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	area model.Area,
+) (_ []model.Event, err error) {
+	ctx, span := startSpan(ctx, "QueryEventsNear")
+	defer tracing.End(span, &err)
+
+	if !from.Before(to) {
+		return nil, nil
+	}
+
+	// события отбираются по описанному вокруг области прямоугольнику с помощью индекса events_location_idx,
+	// затем точно - по расстоянию до центра области
+	boxes := boxesParams(area.BoundingBox())
+
+	rows, err := s.reader(ctx).QueryxContext(
+		ctx,
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , lower(time) AS start_at
+  , upper(time) AS end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT string_agg(l.name, ',' ORDER BY l.name)
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE tenant_id = $1
+  AND owner_id  = $2
+  AND time && tsrange($3, $4)
+  AND latitude IS NOT NULL
+  AND (
+       point(longitude, latitude) <@ box(point($5, $6), point($7, $8))
+    OR point(longitude, latitude) <@ box(point($9, $10), point($11, $12))
+  )
+
+ORDER BY start_at`,
+		append([]any{tenantID, ownerID, from, to}, boxes...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.FilterNear(events, area), nil
+}
+
+// boxesParams возвращает прямоугольник box как параметры запроса двух прямоугольников,
+// не пересекающих 180-й меридиан: долгота и широта левого нижнего и правого верхнего углов каждого.
+func boxesParams(box model.BoundingBox) []any {
+	params := make([]any, 0, 8)
+	for _, b := range box.Split() {
+		params = append(params, b.MinLon, b.MinLat, b.MaxLon, b.MaxLat)
+	}
+
+	return params
+}
+
+func (s *Storage) CountTenantEvents(ctx context.Context, tenantID model.TenantID) (_ int, err error) {
+	ctx, span := startSpan(ctx, "CountTenantEvents")
+	defer tracing.End(span, &err)
+
+	var n int
+	err = s.reader(ctx).GetContext(
+		ctx,
+		&n,
+		`
+SELECT count(*)
+
+FROM events
+
+WHERE tenant_id = $1`,
+		tenantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (s *Storage) QueryTenantEvents(ctx context.Context, tenantID model.TenantID) (_ []model.Event, err error) {
+	ctx, span := startSpan(ctx, "QueryTenantEvents")
+	defer tracing.End(span, &err)
+
+	rows, err := s.reader(ctx).QueryxContext(
+		ctx,
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , lower(time) AS start_at
+  , upper(time) AS end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT string_agg(l.name, ',' ORDER BY l.name)
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE tenant_id = $1
+
+ORDER BY owner_id, start_at`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+func (s *Storage) DeleteTenantEvents(ctx context.Context, tenantID model.TenantID) (n int, err error) {
+	ctx, span := startSpan(ctx, "DeleteTenantEvents")
+	defer tracing.End(span, &err)
+
+	err = s.withTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM events
+
+WHERE tenant_id = $1`,
+			tenantID,
+		)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		n = int(affected)
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM labels
+
+WHERE tenant_id = $1`,
+			tenantID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM profiles
+
+WHERE tenant_id = $1`,
+			tenantID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM feed_tokens
+
+WHERE tenant_id = $1`,
+			tenantID,
+		)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (s *Storage) AddLabel(ctx context.Context, label model.OwnerLabel) (err error) {
+	ctx, span := startSpan(ctx, "AddLabel")
+	defer tracing.End(span, &err)
+
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+INSERT INTO
+  labels (
+      tenant_id
+    , owner_id
+    , name
+    , color
+  )
+VALUES (
+    :tenant_id
+  , :owner_id
+  , :name
+  , :color
+)`,
+			toPgLabel(label),
+		)
+		if err != nil {
+			return handleModelError(err)
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) UpdateLabel(ctx context.Context, label model.OwnerLabel) (err error) {
+	ctx, span := startSpan(ctx, "UpdateLabel")
+	defer tracing.End(span, &err)
+
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.NamedExecContext(
+			ctx,
+			`
+UPDATE labels
+SET
+    color = :color
+
+WHERE tenant_id = :tenant_id
+  AND owner_id  = :owner_id
+  AND name      = :name`,
+			toPgLabel(label),
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return storage.ErrLabelNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) DeleteLabel(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name model.Label,
+) (err error) {
+	ctx, span := startSpan(ctx, "DeleteLabel")
+	defer tracing.End(span, &err)
+
+	// метка снимается с событий каскадным удалением из event_labels
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM labels
+
+WHERE tenant_id = $1
+  AND owner_id  = $2
+  AND name      = $3`,
+			tenantID, ownerID, name,
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return storage.ErrLabelNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) QueryLabels(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (_ []model.OwnerLabel, err error) {
+	ctx, span := startSpan(ctx, "QueryLabels")
+	defer tracing.End(span, &err)
+
+	var pgLabels []pgLabel
+	err = s.reader(ctx).SelectContext(
+		ctx,
+		&pgLabels,
+		`
+SELECT
+    tenant_id
+  , owner_id
+  , name
+  , color
+
+FROM labels
+
+WHERE tenant_id = $1
+  AND owner_id  = $2
+
+ORDER BY name`,
+		tenantID, ownerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []model.OwnerLabel
+	for _, l := range pgLabels {
+		label, err := toLabelModel(l)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
+
+func (s *Storage) FindProfile(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (_ model.Profile, err error) {
+	ctx, span := startSpan(ctx, "FindProfile")
+	defer tracing.End(span, &err)
+
+	var p pgProfile
+	err = s.reader(ctx).GetContext(
+		ctx,
+		&p,
+		`
+SELECT
+    tenant_id
+  , owner_id
+  , profile
+
+FROM profiles
+
+WHERE tenant_id = $1
+  AND owner_id  = $2`,
+		tenantID, ownerID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Profile{}, storage.ErrProfileNotFound
+		}
+
+		return model.Profile{}, err
+	}
+
+	return toProfileModel(p)
+}
+
+func (s *Storage) SaveProfile(ctx context.Context, profile model.Profile) (err error) {
+	ctx, span := startSpan(ctx, "SaveProfile")
+	defer tracing.End(span, &err)
+
+	p, err := toPgProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+INSERT INTO
+  profiles (
+      tenant_id
+    , owner_id
+    , profile
+  )
+VALUES (
+    :tenant_id
+  , :owner_id
+  , :profile
+)
+ON CONFLICT (tenant_id, owner_id) DO UPDATE
+SET
+    profile = EXCLUDED.profile`,
+			p,
+		)
+
+		return err
+	})
+}
+
+func (s *Storage) AddFeedToken(ctx context.Context, token model.FeedToken, maxTokens int) (err error) {
+	ctx, span := startSpan(ctx, "AddFeedToken")
+	defer tracing.End(span, &err)
+
 	return s.withTx(ctx, func(tx *sqlx.Tx) error {
-		ev := toPgEvent(event)
+		if maxTokens > 0 {
+			if err := checkFeedTokensLimit(ctx, tx, token, maxTokens); err != nil {
+				return err
+			}
+		}
+
 		_, err := tx.NamedExecContext(
 			ctx,
 			`
 INSERT INTO
-  events (
-      event_id
+  feed_tokens (
+      token_id
+    , tenant_id
     , owner_id
-    , time
-    , title
-    , description
-    , notify_before
+    , hash
+    , name
+    , created_at
   )
 VALUES (
-  :event_id
+    :token_id
+  , :tenant_id
   , :owner_id
-  , tsrange(:start_at, :end_at)
-  , :title
-  , :description
-  , :notify_before
+  , :hash
+  , :name
+  , :created_at
 )`,
-			ev,
+			toPgFeedToken(token),
 		)
 		if err != nil {
 			return handleModelError(err)
@@ -77,89 +1053,112 @@ VALUES (
 	})
 }
 
-func (s *Storage) UpdateEvent(ctx context.Context, event model.Event) error {
-	return s.withTx(ctx, func(tx *sqlx.Tx) error {
-		ev := toPgEvent(event)
-		result, err := tx.NamedExec(
-			`
-UPDATE events
-SET
-    time          = tsrange(:start_at, :end_at)
-  , title         = :title
-  , description   = :description
-  , notify_before = :notify_before
+// checkFeedTokensLimit проверяет в транзакции tx, что у владельца ссылки token меньше maxTokens ссылок.
+// Ссылки владельца добавляются последовательно под транзакционной advisory-блокировкой,
+// поэтому одновременное добавление не превышает ограничение.
+func checkFeedTokensLimit(ctx context.Context, tx *sqlx.Tx, token model.FeedToken, maxTokens int) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`SELECT pg_advisory_xact_lock(hashtextextended('feed_tokens:' || $1::text || ':' || $2::text, 0))`,
+		token.TenantID(),
+		token.OwnerID(),
+	)
+	if err != nil {
+		return err
+	}
 
-WHERE owner_id = :owner_id
-  AND event_id = :event_id`,
-			ev,
-		)
-		if err != nil {
-			return handleModelError(err)
-		}
+	var n int
+	err = tx.GetContext(
+		ctx,
+		&n,
+		`
+SELECT count(*)
 
-		n, err := result.RowsAffected()
-		if err != nil {
-			return err
-		}
+FROM feed_tokens
 
-		if n == 0 {
-			return storage.ErrEventNotFound
-		}
+WHERE tenant_id = $1
+  AND owner_id = $2`,
+		token.TenantID(),
+		token.OwnerID(),
+	)
+	if err != nil {
+		return err
+	}
 
-		return nil
-	})
+	if n >= maxTokens {
+		return model.ErrTooManyFeedTokens
+	}
+
+	return nil
 }
 
-func (s *Storage) FindEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) (model.Event, error) {
-	ev := pgEvent{}
-	err := s.DB.GetContext(
+func (s *Storage) QueryFeedTokens(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (_ []model.FeedToken, err error) {
+	ctx, span := startSpan(ctx, "QueryFeedTokens")
+	defer tracing.End(span, &err)
+
+	var pgTokens []pgFeedToken
+	err = s.reader(ctx).SelectContext(
 		ctx,
-		&ev,
+		&pgTokens,
 		`
 SELECT
-    id
-  , event_id
+    token_id
+  , tenant_id
   , owner_id
-  , lower(time) AS start_at
-  , upper(time) AS end_at
-  , title
-  , description
-  , notify_before
+  , hash
+  , name
+  , created_at
 
-FROM events
+FROM feed_tokens
 
-WHERE owner_id=$1
-  AND event_id=$2`,
-		ownerID, eventID,
+WHERE tenant_id = $1
+  AND owner_id  = $2
+
+ORDER BY created_at, token_id`,
+		tenantID, ownerID,
 	)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = storage.ErrEventNotFound
-		}
-
-		return model.Event{}, err
+		return nil, err
 	}
 
-	event, err := toModel(ev)
-	if err != nil {
-		return model.Event{}, err
+	var tokens []model.FeedToken
+	for _, t := range pgTokens {
+		token, err := toFeedTokenModel(t)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
 	}
 
-	return event, nil
+	return tokens, nil
 }
 
-func (s *Storage) DeleteEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) error {
+func (s *Storage) DeleteFeedToken(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	tokenID model.FeedTokenID,
+) (err error) {
+	ctx, span := startSpan(ctx, "DeleteFeedToken")
+	defer tracing.End(span, &err)
+
 	return s.withTx(ctx, func(tx *sqlx.Tx) error {
 		result, err := tx.ExecContext(
 			ctx,
 			`
 DELETE
 
-FROM events
+FROM feed_tokens
 
-WHERE owner_id = $1
-  AND event_id = $2`,
-			ownerID, eventID,
+WHERE tenant_id = $1
+  AND owner_id  = $2
+  AND token_id  = $3`,
+			tenantID, ownerID, tokenID,
 		)
 		if err != nil {
 			return err
@@ -171,97 +1170,121 @@ WHERE owner_id = $1
 		}
 
 		if n == 0 {
-			return storage.ErrEventNotFound
+			return storage.ErrFeedTokenNotFound
 		}
 
 		return nil
 	})
 }
 
-func (s *Storage) QueryEvents(
-	ctx context.Context,
-	ownerID model.OwnerID,
-	from time.Time,
-	to time.Time,
-) ([]model.Event, error) {
-	rows, err := s.DB.QueryxContext(
+func (s *Storage) FindFeedToken(ctx context.Context, hash model.FeedTokenHash) (_ model.FeedToken, err error) {
+	ctx, span := startSpan(ctx, "FindFeedToken")
+	defer tracing.End(span, &err)
+
+	var t pgFeedToken
+	err = s.reader(ctx).GetContext(
 		ctx,
+		&t,
 		`
 SELECT
-    id
-  , event_id
+    token_id
+  , tenant_id
   , owner_id
-  , lower(time) AS start_at
-  , upper(time) AS end_at
-  , title
-  , description
-  , notify_before
-
-FROM events
+  , hash
+  , name
+  , created_at
 
-WHERE owner_id = $1
-  AND time && tsrange($2, $3)
+FROM feed_tokens
 
-ORDER BY start_at`,
-		ownerID, from, to,
+WHERE hash = $1`,
+		hash,
 	)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var events []model.Event
-	for rows.Next() {
-		var ev pgEvent
-		if err = rows.StructScan(&ev); err != nil {
-			return nil, err
-		}
-
-		event, err := toModel(ev)
-		if err != nil {
-			return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.FeedToken{}, storage.ErrFeedTokenNotFound
 		}
 
-		events = append(events, event)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return model.FeedToken{}, err
 	}
 
-	return events, nil
+	return toFeedTokenModel(t)
 }
 
-func (s *Storage) PurgeOldEvents(ctx context.Context, olderThan time.Time) error {
-	return s.withTx(ctx, func(tx *sqlx.Tx) error {
-		_, err := tx.ExecContext(
+func (s *Storage) PurgeOldEvents(ctx context.Context, olderThan time.Time) (events []model.Event, err error) {
+	ctx, span := startSpan(ctx, "PurgeOldEvents")
+	defer tracing.End(span, &err)
+
+	err = s.withTx(ctx, func(tx *sqlx.Tx) error {
+		// все части запроса видят один снимок данных,
+		// поэтому метки удаляемых событий ещё доступны в event_labels
+		rows, err := tx.QueryxContext(
 			ctx,
 			`
-DELETE
+WITH purged AS (
+  DELETE
 
-FROM events
+  FROM events
 
-WHERE upper(time)<$1`,
+  WHERE upper(time)<$1
+
+  RETURNING *
+)
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , lower(time) AS start_at
+  , upper(time) AS end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT string_agg(l.name, ',' ORDER BY l.name)
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = purged.id
+    ) AS labels
+
+FROM purged`,
 			olderThan,
 		)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		events, err = scanEvents(rows)
+
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
 }
 
 func (s *Storage) QueryEventsToNotify(
 	ctx context.Context,
 	from time.Time,
 	to time.Time,
-) ([]model.Event, error) {
-	rows, err := s.DB.QueryxContext(
+) (_ []model.Event, err error) {
+	ctx, span := startSpan(ctx, "QueryEventsToNotify")
+	defer tracing.End(span, &err)
+
+	rows, err := s.reader(ctx).QueryxContext(
 		ctx,
 		`
 SELECT
     id
+  , tenant_id
   , event_id
   , owner_id
   , lower(time) AS start_at
@@ -269,6 +1292,19 @@ SELECT
   , title
   , description
   , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT string_agg(l.name, ',' ORDER BY l.name)
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
 
 FROM events
 
@@ -282,12 +1318,18 @@ ORDER BY start_at`,
 	if err != nil {
 		return nil, err
 	}
+
+	return scanEvents(rows)
+}
+
+// scanEvents читает события из результата запроса rows и закрывает его.
+func scanEvents(rows *sqlx.Rows) ([]model.Event, error) {
 	defer rows.Close()
 
 	var events []model.Event
 	for rows.Next() {
 		var ev pgEvent
-		if err = rows.StructScan(&ev); err != nil {
+		if err := rows.StructScan(&ev); err != nil {
 			return nil, err
 		}
 
@@ -306,6 +1348,19 @@ ORDER BY start_at`,
 	return events, nil
 }
 
+// startSpan начинает span операции operation с postgres.
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(
+		ctx,
+		"pg."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", operation),
+		),
+	)
+}
+
 // withTx выполняет функцию fn в транзакции.
 func (s *Storage) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) (err error) {
 	tx, err := s.DB.BeginTxx(ctx, nil)
@@ -315,6 +1370,9 @@ func (s *Storage) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) (err e
 
 	defer func() {
 		err = finishTx(err, tx)
+		if err == nil {
+			storage.MarkWritten(ctx)
+		}
 	}()
 
 	err = fn(tx)
@@ -344,8 +1402,14 @@ func finishTx(err error, tx *sqlx.Tx) error {
 	return nil
 }
 
-func toPgEvent(event model.Event) pgEvent {
+func toPgEvent(event model.Event) (pgEvent, error) {
+	attachments, err := attachmentsParam(event.Attachments)
+	if err != nil {
+		return pgEvent{}, err
+	}
+
 	ev := pgEvent{
+		TenantID:     string(event.TenantID()),
 		EventID:      string(event.EventID()),
 		OwnerID:      string(event.OwnerID()),
 		StartAt:      event.StartAt(),
@@ -353,6 +1417,17 @@ func toPgEvent(event model.Event) pgEvent {
 		Title:        string(event.Title),
 		Description:  sql.NullString{},
 		NotifyBefore: event.NotifyBefore,
+		Color:        nullString(string(event.Color)),
+		Attachments:  attachments,
+
+		LocationName:    nullString(event.Location.Name),
+		LocationAddress: nullString(event.Location.Address),
+		ConferenceURL:   nullString(string(event.ConferenceURL)),
+	}
+
+	if point := event.Location.Point; point != nil {
+		ev.Latitude = sql.NullFloat64{Float64: point.Latitude, Valid: true}
+		ev.Longitude = sql.NullFloat64{Float64: point.Longitude, Valid: true}
 	}
 
 	if event.Description != "" {
@@ -360,10 +1435,161 @@ func toPgEvent(event model.Event) pgEvent {
 		ev.Description.Valid = true
 	}
 
-	return ev
+	return ev, nil
+}
+
+func toPgLabel(label model.OwnerLabel) pgLabel {
+	return pgLabel{
+		TenantID: string(label.TenantID()),
+		OwnerID:  string(label.OwnerID()),
+		Name:     string(label.Name()),
+		Color:    nullString(string(label.Color)),
+	}
+}
+
+func toLabelModel(l pgLabel) (model.OwnerLabel, error) {
+	tenantID, err := model.NewTenantIDFromString(l.TenantID)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(l.OwnerID)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	name, err := model.NewLabel(l.Name)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	color, err := model.NewColor(l.Color.String)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	return model.NewOwnerLabel(tenantID, ownerID, name, color), nil
+}
+
+// nullString возвращает NULL для пустой строки s.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// labelsParam возвращает метки labels как параметр запроса text[], NULL для пустого списка.
+func labelsParam(labels []model.Label) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	params := make([]string, len(labels))
+	for i, label := range labels {
+		params[i] = string(label)
+	}
+
+	return params
+}
+
+func toPgProfile(profile model.Profile) (pgProfile, error) {
+	data, err := json.Marshal(storage.ToProfile(profile))
+	if err != nil {
+		return pgProfile{}, fmt.Errorf("can't encode profile: %w", err)
+	}
+
+	return pgProfile{
+		TenantID: string(profile.TenantID()),
+		OwnerID:  string(profile.OwnerID()),
+		Profile:  string(data),
+	}, nil
+}
+
+func toProfileModel(p pgProfile) (model.Profile, error) {
+	tenantID, err := model.NewTenantIDFromString(p.TenantID)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(p.OwnerID)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	var stored storage.Profile
+	if err := json.Unmarshal([]byte(p.Profile), &stored); err != nil {
+		return model.Profile{}, fmt.Errorf("can't decode profile: %w", err)
+	}
+
+	return storage.ToProfileModel(tenantID, ownerID, stored)
+}
+
+func toPgFeedToken(token model.FeedToken) pgFeedToken {
+	return pgFeedToken{
+		TokenID:   string(token.TokenID()),
+		TenantID:  string(token.TenantID()),
+		OwnerID:   string(token.OwnerID()),
+		Hash:      string(token.Hash()),
+		Name:      sql.NullString{String: token.Name, Valid: token.Name != ""},
+		CreatedAt: token.CreatedAt,
+	}
+}
+
+func toFeedTokenModel(t pgFeedToken) (model.FeedToken, error) {
+	tenantID, err := model.NewTenantIDFromString(t.TenantID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(t.OwnerID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	tokenID, err := model.NewFeedTokenIDFromString(t.TokenID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	token := model.NewFeedToken(tenantID, ownerID, tokenID, model.FeedTokenHash(t.Hash))
+	token.Name = t.Name.String
+	token.CreatedAt = t.CreatedAt.UTC()
+
+	return token, nil
+}
+
+// attachmentsParam возвращает вложения attachments как JSON, NULL для пустого списка.
+func attachmentsParam(attachments []model.Attachment) (sql.NullString, error) {
+	if len(attachments) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(storage.ToAttachments(attachments))
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("can't encode attachments: %w", err)
+	}
+
+	return nullString(string(data)), nil
+}
+
+// toAttachmentModels возвращает вложения из JSON attachments.
+func toAttachmentModels(attachments sql.NullString) ([]model.Attachment, error) {
+	if !attachments.Valid {
+		return nil, nil
+	}
+
+	var stored []storage.Attachment
+	if err := json.Unmarshal([]byte(attachments.String), &stored); err != nil {
+		return nil, fmt.Errorf("can't decode attachments: %w", err)
+	}
+
+	return storage.ToAttachmentModels(stored)
 }
 
 func toModel(ev pgEvent) (model.Event, error) {
+	tenantID, err := model.NewTenantIDFromString(ev.TenantID)
+	if err != nil {
+		return model.Event{}, err
+	}
+
 	eventID, err := model.NewIDFromString(ev.EventID)
 	if err != nil {
 		return model.Event{}, err
@@ -379,7 +1605,7 @@ func toModel(ev pgEvent) (model.Event, error) {
 		return model.Event{}, err
 	}
 
-	event, err := model.NewEvent(eventID, ownerID, title, ev.StartAt, ev.EndAt)
+	event, err := model.NewEvent(tenantID, eventID, ownerID, title, ev.StartAt, ev.EndAt)
 	if err != nil {
 		return model.Event{}, err
 	}
@@ -392,22 +1618,73 @@ func toModel(ev pgEvent) (model.Event, error) {
 		event.NotifyBefore = ev.NotifyBefore
 	}
 
-	return event, err
+	if ev.Labels.Valid {
+		event.Labels, err = model.NewLabels(strings.Split(ev.Labels.String, ","))
+		if err != nil {
+			return model.Event{}, err
+		}
+	}
+
+	event.Color, err = model.NewColor(ev.Color.String)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Attachments, err = toAttachmentModels(ev.Attachments)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Location, err = toLocationModel(ev)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.ConferenceURL, err = model.NewConferenceURL(ev.ConferenceURL.String)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	return event, nil
+}
+
+// toLocationModel возвращает место проведения события ev.
+func toLocationModel(ev pgEvent) (model.Location, error) {
+	var point *model.Point
+	if ev.Latitude.Valid && ev.Longitude.Valid {
+		p, err := model.NewPoint(ev.Latitude.Float64, ev.Longitude.Float64)
+		if err != nil {
+			return model.Location{}, err
+		}
+
+		point = &p
+	}
+
+	return model.NewLocation(ev.LocationName.String, ev.LocationAddress.String, point)
 }
 
-// handleModelError пытается по содержимому ошибки err вернуть ошибку модели, если возможно,
+// handleModelError по коду ошибки postgres и имени ограничения возвращает ошибку модели, если возможно,
 // в противном случае возвращает переданную ошибку err.
 func handleModelError(err error) error {
 	if err == nil {
 		return nil
 	}
 
-	errStr := err.Error()
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
 	switch {
-	case strings.Contains(errStr, "uniq_owner_event_id"):
+	case pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == constraintUniqOwnerEventID:
 		return storage.ErrEventAlreadyExists
-	case strings.Contains(errStr, "no_time_overlap"):
+	case pgErr.Code == pgerrcode.ExclusionViolation && pgErr.ConstraintName == constraintNoTimeOverlap:
 		return storage.ErrTimeIsBusy
+	case pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == constraintUniqOwnerLabel:
+		return storage.ErrLabelAlreadyExists
+	case pgErr.Code == pgerrcode.UniqueViolation &&
+		(pgErr.ConstraintName == constraintUniqFeedTokenID || pgErr.ConstraintName == constraintUniqFeedHash):
+		return storage.ErrFeedTokenAlreadyExists
 	}
 
 	return err
@@ -0,0 +1,115 @@
+package event
+
+import (
+	"fmt"
+	"time"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// Profile - профиль доступности владельца в виде, в котором он сохраняется хранилищами (JSON).
+type Profile struct {
+	TimeZone     string       `json:"timeZone,omitempty"`
+	WorkingHours []WorkingDay `json:"workingHours,omitempty"`
+	Holidays     []string     `json:"holidays,omitempty"` // YYYY-MM-DD
+	Check        string       `json:"check"`
+
+	HolidayCalendars []string `json:"holidayCalendars,omitempty"`
+}
+
+// WorkingDay - рабочие часы дня недели Weekday (как time.Weekday) в формате HH:MM.
+type WorkingDay struct {
+	Weekday int    `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// ToProfile возвращает профиль profile для сохранения.
+func ToProfile(profile model.Profile) Profile {
+	p := Profile{
+		TimeZone: profile.TimeZone.String(),
+		Check:    string(profile.Check),
+	}
+
+	for weekday, hours := range profile.WorkingHours {
+		if hours.IsZero() {
+			continue
+		}
+
+		p.WorkingHours = append(p.WorkingHours, WorkingDay{
+			Weekday: weekday,
+			Start:   hours.Start.String(),
+			End:     hours.End.String(),
+		})
+	}
+
+	for _, date := range profile.Holidays {
+		p.Holidays = append(p.Holidays, date.String())
+	}
+
+	for _, calendar := range profile.HolidayCalendars {
+		p.HolidayCalendars = append(p.HolidayCalendars, string(calendar))
+	}
+
+	return p
+}
+
+// ToProfileModel возвращает сохранённый профиль p владельца ownerID в рабочем пространстве tenantID.
+func ToProfileModel(tenantID model.TenantID, ownerID model.OwnerID, p Profile) (model.Profile, error) {
+	profile := model.NewProfile(tenantID, ownerID)
+
+	var err error
+
+	profile.TimeZone, err = model.NewTimeZone(p.TimeZone)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	profile.Check, err = model.NewAvailabilityCheck(p.Check)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	for _, day := range p.WorkingHours {
+		if day.Weekday < int(time.Sunday) || day.Weekday > int(time.Saturday) {
+			return model.Profile{}, fmt.Errorf("%w: weekday %d", model.ErrInvalidWorkingHours, day.Weekday)
+		}
+
+		start, err := model.NewTimeOfDay(day.Start)
+		if err != nil {
+			return model.Profile{}, err
+		}
+
+		end, err := model.NewTimeOfDay(day.End)
+		if err != nil {
+			return model.Profile{}, err
+		}
+
+		profile.WorkingHours[day.Weekday], err = model.NewWorkingHours(start, end)
+		if err != nil {
+			return model.Profile{}, err
+		}
+	}
+
+	dates := make([]model.Date, 0, len(p.Holidays))
+	for _, holiday := range p.Holidays {
+		t, err := time.Parse(time.DateOnly, holiday)
+		if err != nil {
+			return model.Profile{}, fmt.Errorf("%w: '%s'", model.ErrInvalidHoliday, holiday)
+		}
+
+		dates = append(dates, model.DateOf(t))
+	}
+
+	profile.Holidays, err = model.NewHolidays(dates)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	profile.HolidayCalendars, err = model.NewHolidayCalendars(p.HolidayCalendars)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,1620 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// defaultPragmas - параметры подключения по умолчанию, применяются если не заданы в dataSource:
+//   - ожидание снятия блокировки базы другим соединением до 5 секунд;
+//   - журнал в режиме WAL, чтобы чтение не блокировалось записью.
+//
+// Кроме того, транзакции по умолчанию начинаются с блокировки на запись (_txlock=immediate),
+// что исключает гонку между проверкой пересечения времени событий и записью.
+var defaultPragmas = []string{
+	"busy_timeout(5000)",
+	"journal_mode(WAL)",
+}
+
+type sqliteEvent struct {
+	ID           int            `db:"id"`
+	TenantID     string         `db:"tenant_id"`
+	EventID      string         `db:"event_id"`
+	OwnerID      string         `db:"owner_id"`
+	StartAt      int64          `db:"start_at"`
+	EndAt        int64          `db:"end_at"`
+	Title        string         `db:"title"`
+	Description  sql.NullString `db:"description"`
+	NotifyBefore uint           `db:"notify_before"`
+	Color        sql.NullString `db:"color"`
+
+	// Attachments - вложения события в JSON, см. storage.Attachment.
+	Attachments sql.NullString `db:"attachments"`
+
+	// Место проведения события: координаты заданы либо обе, либо ни одной.
+	LocationName    sql.NullString  `db:"location_name"`
+	LocationAddress sql.NullString  `db:"location_address"`
+	Latitude        sql.NullFloat64 `db:"latitude"`
+	Longitude       sql.NullFloat64 `db:"longitude"`
+	ConferenceURL   sql.NullString  `db:"conference_url"`
+
+	// Labels - метки события через запятую (метки не содержат запятых), см. model.NewLabel.
+	Labels sql.NullString `db:"labels"`
+}
+
+type sqliteLabel struct {
+	TenantID string         `db:"tenant_id"`
+	OwnerID  string         `db:"owner_id"`
+	Name     string         `db:"name"`
+	Color    sql.NullString `db:"color"`
+}
+
+// sqliteProfile - профиль доступности владельца, Profile - профиль в JSON, см. storage.Profile.
+type sqliteProfile struct {
+	TenantID string `db:"tenant_id"`
+	OwnerID  string `db:"owner_id"`
+	Profile  string `db:"profile"`
+}
+
+// sqliteFeedToken - ссылка на календарь владельца, CreatedAt - в микросекундах Unix.
+type sqliteFeedToken struct {
+	TokenID   string         `db:"token_id"`
+	TenantID  string         `db:"tenant_id"`
+	OwnerID   string         `db:"owner_id"`
+	Hash      string         `db:"hash"`
+	Name      sql.NullString `db:"name"`
+	CreatedAt int64          `db:"created_at"`
+}
+
+type Storage struct {
+	DB *sqlx.DB
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// NewStorage открывает базу SQLite dataSource (путь к файлу базы, возможно с параметрами подключения,
+// например "data/calendar.db?_pragma=synchronous(NORMAL)").
+//
+// Схема базы должна быть создана миграциями migrations/sqlite.
+func NewStorage(dataSource string) (*Storage, error) {
+	db, err := sqlx.Connect("sqlite", withDefaultParams(dataSource))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Storage{DB: db}, nil
+}
+
+func (s *Storage) Close() error {
+	return s.DB.Close()
+}
+
+// Ping проверяет доступность базы.
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.DB.PingContext(ctx)
+}
+
+func (s *Storage) AddEvent(ctx context.Context, event model.Event) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		ev, err := toSqliteEvent(event)
+		if err != nil {
+			return err
+		}
+
+		exists, err := eventExists(ctx, tx, ev)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return storage.ErrEventAlreadyExists
+		}
+
+		if err := checkTimeIsFree(ctx, tx, ev); err != nil {
+			return err
+		}
+
+		_, err = tx.NamedExecContext(
+			ctx,
+			`
+INSERT INTO
+  events (
+      tenant_id
+    , event_id
+    , owner_id
+    , start_at
+    , end_at
+    , title
+    , description
+    , notify_before
+    , color
+    , attachments
+    , location_name
+    , location_address
+    , latitude
+    , longitude
+    , conference_url
+  )
+VALUES (
+    :tenant_id
+  , :event_id
+  , :owner_id
+  , :start_at
+  , :end_at
+  , :title
+  , :description
+  , :notify_before
+  , :color
+  , :attachments
+  , :location_name
+  , :location_address
+  , :latitude
+  , :longitude
+  , :conference_url
+)`,
+			ev,
+		)
+		if err != nil {
+			return handleModelError(err)
+		}
+
+		return setEventLabels(ctx, tx, event)
+	})
+}
+
+func (s *Storage) UpdateEvent(ctx context.Context, event model.Event) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		ev, err := toSqliteEvent(event)
+		if err != nil {
+			return err
+		}
+
+		exists, err := eventExists(ctx, tx, ev)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return storage.ErrEventNotFound
+		}
+
+		if err := checkTimeIsFree(ctx, tx, ev); err != nil {
+			return err
+		}
+
+		_, err = tx.NamedExecContext(
+			ctx,
+			`
+UPDATE events
+SET
+    start_at         = :start_at
+  , end_at           = :end_at
+  , title            = :title
+  , description      = :description
+  , notify_before    = :notify_before
+  , color            = :color
+  , attachments      = :attachments
+  , location_name    = :location_name
+  , location_address = :location_address
+  , latitude         = :latitude
+  , longitude        = :longitude
+  , conference_url   = :conference_url
+
+WHERE tenant_id = :tenant_id
+  AND owner_id  = :owner_id
+  AND event_id  = :event_id`,
+			ev,
+		)
+		if err != nil {
+			return handleModelError(err)
+		}
+
+		return setEventLabels(ctx, tx, event)
+	})
+}
+
+func (s *Storage) FindEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) (model.Event, error) {
+	ev := sqliteEvent{}
+	err := s.DB.GetContext(
+		ctx,
+		&ev,
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , start_at
+  , end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT group_concat(l.name, ',')
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE tenant_id = ?
+  AND owner_id  = ?
+  AND event_id  = ?`,
+		tenantID, ownerID, eventID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = storage.ErrEventNotFound
+		}
+
+		return model.Event{}, err
+	}
+
+	return toModel(ev)
+}
+
+func (s *Storage) DeleteEvent(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	eventID model.ID,
+) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		err := deleteEventLabels(
+			ctx,
+			tx,
+			"tenant_id = ? AND owner_id = ? AND event_id = ?",
+			tenantID, ownerID, eventID,
+		)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM events
+
+WHERE tenant_id = ?
+  AND owner_id  = ?
+  AND event_id  = ?`,
+			tenantID, ownerID, eventID,
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return storage.ErrEventNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) QueryEvents(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	filter model.Filter,
+) ([]model.Event, error) {
+	// пустой промежуток [from, to) не пересекается ни с одним событием
+	if !from.Before(to) {
+		return nil, nil
+	}
+
+	args := []any{tenantID, ownerID, toMicro(to), toMicro(from)}
+
+	labelsCond := ""
+	if len(filter.Labels) > 0 {
+		labelsCond = `
+  AND EXISTS (
+        SELECT 1
+        FROM event_labels el
+        JOIN labels l ON l.id = el.label_id
+        WHERE el.event_id = events.id
+          AND l.name IN (?)
+      )`
+		args = append(args, labelsParam(filter.Labels))
+	}
+
+	query, args, err := sqlx.In(
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , start_at
+  , end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT group_concat(l.name, ',')
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE tenant_id = ?
+  AND owner_id  = ?
+  AND start_at  < ?
+  AND ? < end_at`+labelsCond+`
+
+ORDER BY start_at`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.queryEvents(ctx, query, args...)
+}
+
+func (s *Storage) QueryEventsNear(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	from time.Time,
+	to time.Time,
+	area model.Area,
+) ([]model.Event, error) {
+	if !from.Before(to) {
+		return nil, nil
+	}
+
+	// события отбираются по описанному вокруг области прямоугольнику, затем точно - по расстоянию до центра
+	args := []any{tenantID, ownerID, toMicro(to), toMicro(from)}
+	for _, b := range area.BoundingBox().Split() {
+		args = append(args, b.MinLat, b.MaxLat, b.MinLon, b.MaxLon)
+	}
+
+	events, err := s.queryEvents(
+		ctx,
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , start_at
+  , end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT group_concat(l.name, ',')
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE tenant_id = ?
+  AND owner_id  = ?
+  AND start_at  < ?
+  AND ? < end_at
+  AND latitude IS NOT NULL
+  AND (
+       (latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?)
+    OR (latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?)
+  )
+
+ORDER BY start_at`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.FilterNear(events, area), nil
+}
+
+func (s *Storage) CountTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error) {
+	var n int
+	err := s.DB.GetContext(
+		ctx,
+		&n,
+		`
+SELECT count(*)
+
+FROM events
+
+WHERE tenant_id = ?`,
+		tenantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (s *Storage) QueryTenantEvents(ctx context.Context, tenantID model.TenantID) ([]model.Event, error) {
+	return s.queryEvents(
+		ctx,
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , start_at
+  , end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT group_concat(l.name, ',')
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE tenant_id = ?
+
+ORDER BY owner_id, start_at`,
+		tenantID,
+	)
+}
+
+func (s *Storage) DeleteTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error) {
+	var n int64
+
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := deleteEventLabels(ctx, tx, "tenant_id = ?", tenantID); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM events
+
+WHERE tenant_id = ?`,
+			tenantID,
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err = result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM labels
+
+WHERE tenant_id = ?`,
+			tenantID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM profiles
+
+WHERE tenant_id = ?`,
+			tenantID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM feed_tokens
+
+WHERE tenant_id = ?`,
+			tenantID,
+		)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
+
+func (s *Storage) AddLabel(ctx context.Context, label model.OwnerLabel) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+INSERT INTO
+  labels (
+      tenant_id
+    , owner_id
+    , name
+    , color
+  )
+VALUES (
+    :tenant_id
+  , :owner_id
+  , :name
+  , :color
+)`,
+			toSqliteLabel(label),
+		)
+		if err != nil {
+			return handleModelError(err)
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) UpdateLabel(ctx context.Context, label model.OwnerLabel) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.NamedExecContext(
+			ctx,
+			`
+UPDATE labels
+SET
+    color = :color
+
+WHERE tenant_id = :tenant_id
+  AND owner_id  = :owner_id
+  AND name      = :name`,
+			toSqliteLabel(label),
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return storage.ErrLabelNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) DeleteLabel(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name model.Label,
+) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM event_labels
+
+WHERE label_id IN (
+  SELECT id
+  FROM labels
+  WHERE tenant_id = ?
+    AND owner_id  = ?
+    AND name      = ?
+)`,
+			tenantID, ownerID, name,
+		)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM labels
+
+WHERE tenant_id = ?
+  AND owner_id  = ?
+  AND name      = ?`,
+			tenantID, ownerID, name,
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return storage.ErrLabelNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) QueryLabels(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) ([]model.OwnerLabel, error) {
+	var sqliteLabels []sqliteLabel
+	err := s.DB.SelectContext(
+		ctx,
+		&sqliteLabels,
+		`
+SELECT
+    tenant_id
+  , owner_id
+  , name
+  , color
+
+FROM labels
+
+WHERE tenant_id = ?
+  AND owner_id  = ?
+
+ORDER BY name`,
+		tenantID, ownerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []model.OwnerLabel
+	for _, l := range sqliteLabels {
+		label, err := toLabelModel(l)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
+
+func (s *Storage) FindProfile(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) (model.Profile, error) {
+	var p sqliteProfile
+	err := s.DB.GetContext(
+		ctx,
+		&p,
+		`
+SELECT
+    tenant_id
+  , owner_id
+  , profile
+
+FROM profiles
+
+WHERE tenant_id = ?
+  AND owner_id  = ?`,
+		tenantID, ownerID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Profile{}, storage.ErrProfileNotFound
+		}
+
+		return model.Profile{}, err
+	}
+
+	return toProfileModel(p)
+}
+
+func (s *Storage) SaveProfile(ctx context.Context, profile model.Profile) error {
+	p, err := toSqliteProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+INSERT INTO
+  profiles (
+      tenant_id
+    , owner_id
+    , profile
+  )
+VALUES (
+    :tenant_id
+  , :owner_id
+  , :profile
+)
+ON CONFLICT (tenant_id, owner_id) DO UPDATE
+SET
+    profile = excluded.profile`,
+			p,
+		)
+
+		return err
+	})
+}
+
+func (s *Storage) AddFeedToken(ctx context.Context, token model.FeedToken, maxTokens int) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		// транзакция начинается с блокировки на запись, поэтому подсчёт атомарен с добавлением
+		if maxTokens > 0 {
+			var n int
+			err := tx.GetContext(
+				ctx,
+				&n,
+				`
+SELECT count(*)
+
+FROM feed_tokens
+
+WHERE tenant_id = ?
+  AND owner_id = ?`,
+				token.TenantID(),
+				token.OwnerID(),
+			)
+			if err != nil {
+				return err
+			}
+
+			if n >= maxTokens {
+				return model.ErrTooManyFeedTokens
+			}
+		}
+
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+INSERT INTO
+  feed_tokens (
+      token_id
+    , tenant_id
+    , owner_id
+    , hash
+    , name
+    , created_at
+  )
+VALUES (
+    :token_id
+  , :tenant_id
+  , :owner_id
+  , :hash
+  , :name
+  , :created_at
+)`,
+			toSqliteFeedToken(token),
+		)
+		if err != nil {
+			return handleModelError(err)
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) QueryFeedTokens(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+) ([]model.FeedToken, error) {
+	var sqliteTokens []sqliteFeedToken
+	err := s.DB.SelectContext(
+		ctx,
+		&sqliteTokens,
+		`
+SELECT
+    token_id
+  , tenant_id
+  , owner_id
+  , hash
+  , name
+  , created_at
+
+FROM feed_tokens
+
+WHERE tenant_id = ?
+  AND owner_id  = ?
+
+ORDER BY created_at, token_id`,
+		tenantID, ownerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []model.FeedToken
+	for _, t := range sqliteTokens {
+		token, err := toFeedTokenModel(t)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+func (s *Storage) DeleteFeedToken(
+	ctx context.Context,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	tokenID model.FeedTokenID,
+) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM feed_tokens
+
+WHERE tenant_id = ?
+  AND owner_id  = ?
+  AND token_id  = ?`,
+			tenantID, ownerID, tokenID,
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return storage.ErrFeedTokenNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) FindFeedToken(ctx context.Context, hash model.FeedTokenHash) (model.FeedToken, error) {
+	var t sqliteFeedToken
+	err := s.DB.GetContext(
+		ctx,
+		&t,
+		`
+SELECT
+    token_id
+  , tenant_id
+  , owner_id
+  , hash
+  , name
+  , created_at
+
+FROM feed_tokens
+
+WHERE hash = ?`,
+		hash,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.FeedToken{}, storage.ErrFeedTokenNotFound
+		}
+
+		return model.FeedToken{}, err
+	}
+
+	return toFeedTokenModel(t)
+}
+
+func (s *Storage) PurgeOldEvents(ctx context.Context, olderThan time.Time) (events []model.Event, err error) {
+	err = s.withTx(ctx, func(tx *sqlx.Tx) error {
+		rows, err := tx.QueryxContext(
+			ctx,
+			`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , start_at
+  , end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT group_concat(l.name, ',')
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE end_at < ?`,
+			toMicro(olderThan),
+		)
+		if err != nil {
+			return err
+		}
+
+		events, err = scanEvents(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := deleteEventLabels(ctx, tx, "end_at < ?", toMicro(olderThan)); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+DELETE
+
+FROM events
+
+WHERE end_at < ?`,
+			toMicro(olderThan),
+		)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *Storage) QueryEventsToNotify(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) ([]model.Event, error) {
+	// Выражение должно совпадать с выражением индекса need_notify.
+	return s.queryEvents(
+		ctx,
+		`
+SELECT
+    id
+  , tenant_id
+  , event_id
+  , owner_id
+  , start_at
+  , end_at
+  , title
+  , description
+  , notify_before
+  , color
+  , attachments
+  , location_name
+  , location_address
+  , latitude
+  , longitude
+  , conference_url
+  , (
+      SELECT group_concat(l.name, ',')
+      FROM event_labels el
+      JOIN labels l ON l.id = el.label_id
+      WHERE el.event_id = events.id
+    ) AS labels
+
+FROM events
+
+WHERE notify_before > 0
+  AND ? <= (start_at - notify_before * 86400000000)
+  AND (start_at - notify_before * 86400000000) < ?
+
+ORDER BY start_at`,
+		toMicro(from), toMicro(to),
+	)
+}
+
+// queryEvents выполняет запрос query, возвращающий события.
+func (s *Storage) queryEvents(ctx context.Context, query string, args ...any) ([]model.Event, error) {
+	rows, err := s.DB.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+// scanEvents читает события из результата запроса rows и закрывает его.
+func scanEvents(rows *sqlx.Rows) ([]model.Event, error) {
+	defer rows.Close()
+
+	var events []model.Event
+	for rows.Next() {
+		var ev sqliteEvent
+		if err := rows.StructScan(&ev); err != nil {
+			return nil, err
+		}
+
+		event, err := toModel(ev)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// withTx выполняет функцию fn в транзакции.
+func (s *Storage) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) (err error) {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = finishTx(err, tx)
+	}()
+
+	err = fn(tx)
+
+	return err
+}
+
+// finishTx завершает транзакцию tx:
+//   - откатом, если есть ошибка err
+//   - фиксацией, если ошибка отсутствует.
+//
+// Возвращает переданную ошибку err и обёртку (wrap) с возникшей ошибкой
+// во время завершения транзакции.
+func finishTx(err error, tx *sqlx.Tx) error {
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return commitErr
+	}
+
+	return nil
+}
+
+// setEventLabels заменяет метки события event на event.Labels из каталога владельца.
+// Возвращает ErrUnknownLabel, если какой-то метки нет в каталоге.
+func setEventLabels(ctx context.Context, tx *sqlx.Tx, event model.Event) error {
+	err := deleteEventLabels(
+		ctx,
+		tx,
+		"tenant_id = ? AND owner_id = ? AND event_id = ?",
+		event.TenantID(), event.OwnerID(), event.EventID(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(event.Labels) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(
+		`
+INSERT INTO
+  event_labels (
+      event_id
+    , label_id
+  )
+SELECT
+    e.id
+  , l.id
+
+FROM events e
+JOIN labels l
+  ON  l.tenant_id = e.tenant_id
+  AND l.owner_id  = e.owner_id
+
+WHERE e.tenant_id = ?
+  AND e.owner_id  = ?
+  AND e.event_id  = ?
+  AND l.name IN (?)`,
+		event.TenantID(), event.OwnerID(), event.EventID(), labelsParam(event.Labels),
+	)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if int(n) != len(event.Labels) {
+		return storage.ErrUnknownLabel
+	}
+
+	return nil
+}
+
+// deleteEventLabels снимает метки с событий, выбранных условием eventsCond с параметрами args.
+// Заменяет каскадное удаление по внешнему ключу, поэтому должна вызываться в той же транзакции,
+// что и удаление событий.
+func deleteEventLabels(ctx context.Context, tx *sqlx.Tx, eventsCond string, args ...any) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`
+DELETE
+
+FROM event_labels
+
+WHERE event_id IN (
+  SELECT id
+  FROM events
+  WHERE `+eventsCond+`
+)`,
+		args...,
+	)
+
+	return err
+}
+
+// eventExists проверяет, есть ли событие ev (с тем же идентификатором у владельца в рабочем пространстве).
+func eventExists(ctx context.Context, tx *sqlx.Tx, ev sqliteEvent) (bool, error) {
+	var exists bool
+	err := tx.GetContext(
+		ctx,
+		&exists,
+		`
+SELECT EXISTS (
+  SELECT 1
+
+  FROM events
+
+  WHERE tenant_id = ?
+    AND owner_id  = ?
+    AND event_id  = ?
+)`,
+		ev.TenantID, ev.OwnerID, ev.EventID,
+	)
+
+	return exists, err
+}
+
+// checkTimeIsFree проверяет, что время события ev не пересекается с другими событиями владельца
+// в рабочем пространстве.
+// Заменяет ограничение EXCLUDE USING GIST ("tenant_id" WITH =, "owner_id" WITH =, "time" WITH &&) из postgres,
+// поэтому должна вызываться в той же транзакции, что и запись события.
+func checkTimeIsFree(ctx context.Context, tx *sqlx.Tx, ev sqliteEvent) error {
+	var busy bool
+	err := tx.GetContext(
+		ctx,
+		&busy,
+		`
+SELECT EXISTS (
+  SELECT 1
+
+  FROM events
+
+  WHERE tenant_id = ?
+    AND owner_id  = ?
+    AND event_id <> ?
+    AND start_at  < ?
+    AND ? < end_at
+)`,
+		ev.TenantID, ev.OwnerID, ev.EventID, ev.EndAt, ev.StartAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if busy {
+		return storage.ErrTimeIsBusy
+	}
+
+	return nil
+}
+
+// withDefaultParams добавляет к dataSource параметры подключения по умолчанию,
+// если они не заданы явно.
+func withDefaultParams(dataSource string) string {
+	path, rawQuery, _ := strings.Cut(dataSource, "?")
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// пусть ошибку разбора вернёт драйвер
+		return dataSource
+	}
+
+	if !query.Has("_txlock") {
+		query.Set("_txlock", "immediate")
+	}
+
+	pragmas := strings.Join(query["_pragma"], ",")
+	for _, pragma := range defaultPragmas {
+		name, _, _ := strings.Cut(pragma, "(")
+		if !strings.Contains(pragmas, name) {
+			query.Add("_pragma", pragma)
+		}
+	}
+
+	return path + "?" + query.Encode()
+}
+
+// toMicro возвращает время t в микросекундах Unix.
+func toMicro(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// fromMicro возвращает время (UTC) по микросекундам Unix v.
+func fromMicro(v int64) time.Time {
+	return time.UnixMicro(v).UTC()
+}
+
+func toSqliteEvent(event model.Event) (sqliteEvent, error) {
+	attachments, err := attachmentsParam(event.Attachments)
+	if err != nil {
+		return sqliteEvent{}, err
+	}
+
+	ev := sqliteEvent{
+		TenantID:     string(event.TenantID()),
+		EventID:      string(event.EventID()),
+		OwnerID:      string(event.OwnerID()),
+		StartAt:      toMicro(event.StartAt()),
+		EndAt:        toMicro(event.EndAt()),
+		Title:        string(event.Title),
+		Description:  sql.NullString{},
+		NotifyBefore: event.NotifyBefore,
+		Color:        nullString(string(event.Color)),
+		Attachments:  attachments,
+
+		LocationName:    nullString(event.Location.Name),
+		LocationAddress: nullString(event.Location.Address),
+		ConferenceURL:   nullString(string(event.ConferenceURL)),
+	}
+
+	if point := event.Location.Point; point != nil {
+		ev.Latitude = sql.NullFloat64{Float64: point.Latitude, Valid: true}
+		ev.Longitude = sql.NullFloat64{Float64: point.Longitude, Valid: true}
+	}
+
+	if event.Description != "" {
+		ev.Description.String = event.Description
+		ev.Description.Valid = true
+	}
+
+	return ev, nil
+}
+
+func toSqliteLabel(label model.OwnerLabel) sqliteLabel {
+	return sqliteLabel{
+		TenantID: string(label.TenantID()),
+		OwnerID:  string(label.OwnerID()),
+		Name:     string(label.Name()),
+		Color:    nullString(string(label.Color)),
+	}
+}
+
+func toLabelModel(l sqliteLabel) (model.OwnerLabel, error) {
+	tenantID, err := model.NewTenantIDFromString(l.TenantID)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(l.OwnerID)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	name, err := model.NewLabel(l.Name)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	color, err := model.NewColor(l.Color.String)
+	if err != nil {
+		return model.OwnerLabel{}, err
+	}
+
+	return model.NewOwnerLabel(tenantID, ownerID, name, color), nil
+}
+
+// nullString возвращает NULL для пустой строки s.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// labelsParam возвращает метки labels как список параметров запроса.
+func labelsParam(labels []model.Label) []string {
+	params := make([]string, len(labels))
+	for i, label := range labels {
+		params[i] = string(label)
+	}
+
+	return params
+}
+
+func toSqliteProfile(profile model.Profile) (sqliteProfile, error) {
+	data, err := json.Marshal(storage.ToProfile(profile))
+	if err != nil {
+		return sqliteProfile{}, fmt.Errorf("can't encode profile: %w", err)
+	}
+
+	return sqliteProfile{
+		TenantID: string(profile.TenantID()),
+		OwnerID:  string(profile.OwnerID()),
+		Profile:  string(data),
+	}, nil
+}
+
+func toProfileModel(p sqliteProfile) (model.Profile, error) {
+	tenantID, err := model.NewTenantIDFromString(p.TenantID)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(p.OwnerID)
+	if err != nil {
+		return model.Profile{}, err
+	}
+
+	var stored storage.Profile
+	if err := json.Unmarshal([]byte(p.Profile), &stored); err != nil {
+		return model.Profile{}, fmt.Errorf("can't decode profile: %w", err)
+	}
+
+	return storage.ToProfileModel(tenantID, ownerID, stored)
+}
+
+func toSqliteFeedToken(token model.FeedToken) sqliteFeedToken {
+	return sqliteFeedToken{
+		TokenID:   string(token.TokenID()),
+		TenantID:  string(token.TenantID()),
+		OwnerID:   string(token.OwnerID()),
+		Hash:      string(token.Hash()),
+		Name:      sql.NullString{String: token.Name, Valid: token.Name != ""},
+		CreatedAt: toMicro(token.CreatedAt),
+	}
+}
+
+func toFeedTokenModel(t sqliteFeedToken) (model.FeedToken, error) {
+	tenantID, err := model.NewTenantIDFromString(t.TenantID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(t.OwnerID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	tokenID, err := model.NewFeedTokenIDFromString(t.TokenID)
+	if err != nil {
+		return model.FeedToken{}, err
+	}
+
+	token := model.NewFeedToken(tenantID, ownerID, tokenID, model.FeedTokenHash(t.Hash))
+	token.Name = t.Name.String
+	token.CreatedAt = fromMicro(t.CreatedAt)
+
+	return token, nil
+}
+
+// attachmentsParam возвращает вложения attachments как JSON, NULL для пустого списка.
+func attachmentsParam(attachments []model.Attachment) (sql.NullString, error) {
+	if len(attachments) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(storage.ToAttachments(attachments))
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("can't encode attachments: %w", err)
+	}
+
+	return nullString(string(data)), nil
+}
+
+// toAttachmentModels возвращает вложения из JSON attachments.
+func toAttachmentModels(attachments sql.NullString) ([]model.Attachment, error) {
+	if !attachments.Valid {
+		return nil, nil
+	}
+
+	var stored []storage.Attachment
+	if err := json.Unmarshal([]byte(attachments.String), &stored); err != nil {
+		return nil, fmt.Errorf("can't decode attachments: %w", err)
+	}
+
+	return storage.ToAttachmentModels(stored)
+}
+
+func toModel(ev sqliteEvent) (model.Event, error) {
+	tenantID, err := model.NewTenantIDFromString(ev.TenantID)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	eventID, err := model.NewIDFromString(ev.EventID)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	ownerID, err := model.NewOwnerIDFromString(ev.OwnerID)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	title, err := model.NewTitle(ev.Title)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event, err := model.NewEvent(tenantID, eventID, ownerID, title, fromMicro(ev.StartAt), fromMicro(ev.EndAt))
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	if ev.Description.Valid {
+		event.Description = ev.Description.String
+	}
+
+	event.NotifyBefore = ev.NotifyBefore
+
+	if ev.Labels.Valid {
+		event.Labels, err = model.NewLabels(strings.Split(ev.Labels.String, ","))
+		if err != nil {
+			return model.Event{}, err
+		}
+	}
+
+	event.Color, err = model.NewColor(ev.Color.String)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Attachments, err = toAttachmentModels(ev.Attachments)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.Location, err = toLocationModel(ev)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	event.ConferenceURL, err = model.NewConferenceURL(ev.ConferenceURL.String)
+	if err != nil {
+		return model.Event{}, err
+	}
+
+	return event, nil
+}
+
+// toLocationModel возвращает место проведения события ev.
+func toLocationModel(ev sqliteEvent) (model.Location, error) {
+	var point *model.Point
+	if ev.Latitude.Valid && ev.Longitude.Valid {
+		p, err := model.NewPoint(ev.Latitude.Float64, ev.Longitude.Float64)
+		if err != nil {
+			return model.Location{}, err
+		}
+
+		point = &p
+	}
+
+	return model.NewLocation(ev.LocationName.String, ev.LocationAddress.String, point)
+}
+
+// handleModelError по коду ошибки SQLite err возвращает ошибку модели, если возможно,
+// в противном случае возвращает переданную ошибку err.
+func handleModelError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	if sqliteErr.Code() != sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return err
+	}
+
+	switch {
+	case strings.Contains(sqliteErr.Error(), "events.tenant_id, events.owner_id, events.event_id"):
+		return storage.ErrEventAlreadyExists
+	case strings.Contains(sqliteErr.Error(), "labels.tenant_id, labels.owner_id, labels.name"):
+		return storage.ErrLabelAlreadyExists
+	case strings.Contains(sqliteErr.Error(), "feed_tokens.token_id"),
+		strings.Contains(sqliteErr.Error(), "feed_tokens.hash"):
+		return storage.ErrFeedTokenAlreadyExists
+	}
+
+	return err
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/migrate"
+	modelStorage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event/storagetest"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/migrations"
+)
+
+// applyMigrations применяет к базе встроенные миграции SQLite.
+func applyMigrations(t *testing.T, storage *Storage) {
+	t.Helper()
+
+	m, err := migrate.New(storage.DB.DB, migrate.DialectSqlite, migrations.Sqlite, nil)
+	require.NoError(t, err, "must create migrator")
+
+	_, err = m.Up(context.Background())
+	require.NoError(t, err, "must apply migrations")
+}
+
+func openStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	storage, err := NewStorage(filepath.Join(t.TempDir(), "calendar.db"))
+	require.NoError(t, err, "must open storage")
+	t.Cleanup(func() { storage.Close() })
+
+	applyMigrations(t, storage)
+
+	return storage
+}
+
+func TestSqlite_Storage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) modelStorage.Storage {
+		return openStorage(t)
+	})
+}
+
+func Test_withDefaultParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		dataSource string
+		want       string
+	}{
+		{
+			name:       "defaults",
+			dataSource: "data/calendar.db",
+			want:       "data/calendar.db?_pragma=busy_timeout%285000%29&_pragma=journal_mode%28WAL%29&_txlock=immediate",
+		},
+		{
+			name:       "keep explicit",
+			dataSource: "file:calendar.db?_txlock=deferred&_pragma=journal_mode(DELETE)",
+			want:       "file:calendar.db?_pragma=journal_mode%28DELETE%29&_pragma=busy_timeout%285000%29&_txlock=deferred",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, withDefaultParams(tt.dataSource))
+		})
+	}
+}
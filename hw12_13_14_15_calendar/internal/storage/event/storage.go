@@ -2,40 +2,141 @@ package event
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/domainerr"
 	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
 )
 
 var (
-	ErrTimeIsBusy         = errors.New("time is busy")
-	ErrEventAlreadyExists = errors.New("event already exists")
-	ErrEventNotFound      = errors.New("event not found")
+	ErrTimeIsBusy         = domainerr.New(domainerr.KindFailedPrecondition, "TIME_IS_BUSY", "time is busy")
+	ErrEventAlreadyExists = domainerr.New(domainerr.KindAlreadyExists, "EVENT_ALREADY_EXISTS", "event already exists")
+	ErrEventNotFound      = domainerr.New(domainerr.KindNotFound, "EVENT_NOT_FOUND", "event not found")
+
+	ErrLabelAlreadyExists = domainerr.New(domainerr.KindAlreadyExists, "LABEL_ALREADY_EXISTS", "label already exists")
+	ErrLabelNotFound      = domainerr.New(domainerr.KindNotFound, "LABEL_NOT_FOUND", "label not found")
+	ErrUnknownLabel       = domainerr.NewField("labels", "UNKNOWN_LABEL", "label is not in owner's catalog")
+
+	ErrProfileNotFound = domainerr.New(domainerr.KindNotFound, "PROFILE_NOT_FOUND", "profile not found")
+
+	ErrFeedTokenAlreadyExists = domainerr.New(
+		domainerr.KindAlreadyExists,
+		"FEED_TOKEN_ALREADY_EXISTS",
+		"feed token already exists",
+	)
+	ErrFeedTokenNotFound = domainerr.New(domainerr.KindNotFound, "FEED_TOKEN_NOT_FOUND", "feed token not found")
 )
 
 // Storage - интерфейс взаимодйствия с коллекцией событий.
 // Данный интерфейс должно поддерживать любое хранилище.
+//
+// События разделены по рабочим пространствам (тенантам): идентификатор события уникален
+// и время событий не пересекается в рамках владельца в рабочем пространстве.
+//
+// Метки событий - из каталога меток владельца в рабочем пространстве.
+// Вложения событий (метаданные ссылок и файлов) хранятся вместе с событием, содержимое файлов - отдельно.
+//
+// Ссылки на календарь владельца (webcal) хранятся с хешем секрета, сам секрет не хранится.
 type Storage interface {
-	// AddEvent добавляет событие в коллекцию.
+	// AddEvent добавляет событие в коллекцию рабочего пространства события.
+	// Метки события должны быть в каталоге владельца, иначе - ErrUnknownLabel.
 	AddEvent(ctx context.Context, event model.Event) error
 
-	// UpdateEvent обновляет событие в коллекции.
+	// UpdateEvent обновляет событие в коллекции рабочего пространства события.
+	// Метки события должны быть в каталоге владельца, иначе - ErrUnknownLabel.
 	UpdateEvent(ctx context.Context, event model.Event) error
 
-	// FindEvent находит собитие в коллекции по ownerID и eventID.
-	FindEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) (model.Event, error)
+	// FindEvent находит собитие в коллекции рабочего пространства tenantID по ownerID и eventID.
+	FindEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) (model.Event, error)
+
+	// DeleteEvent удаляет событие из коллекции рабочего пространства tenantID по ownerID и eventID.
+	DeleteEvent(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, eventID model.ID) error
+
+	// QueryEvents находит все события в коллекции рабочего пространства tenantID для ownerID,
+	// которые запланированы на указанный промежуток [from, to) и подходят под отбор filter.
+	QueryEvents(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		from time.Time,
+		to time.Time,
+		filter model.Filter,
+	) ([]model.Event, error)
+
+	// QueryEventsNear находит все события в коллекции рабочего пространства tenantID для ownerID,
+	// которые запланированы на указанный промежуток [from, to) и место проведения которых
+	// (координаты места) находится в области area. События упорядочены по времени начала.
+	QueryEventsNear(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		from time.Time,
+		to time.Time,
+		area model.Area,
+	) ([]model.Event, error)
+
+	// AddLabel добавляет метку в каталог владельца.
+	AddLabel(ctx context.Context, label model.OwnerLabel) error
+
+	// UpdateLabel обновляет метку в каталоге владельца.
+	UpdateLabel(ctx context.Context, label model.OwnerLabel) error
+
+	// DeleteLabel удаляет метку name из каталога владельца ownerID в рабочем пространстве tenantID
+	// и снимает её со всех событий владельца.
+	DeleteLabel(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID, name model.Label) error
+
+	// QueryLabels возвращает каталог меток владельца ownerID в рабочем пространстве tenantID,
+	// упорядоченный по имени метки.
+	QueryLabels(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) ([]model.OwnerLabel, error)
+
+	// FindProfile находит профиль доступности владельца ownerID в рабочем пространстве tenantID.
+	// Если профиль не сохранялся - ErrProfileNotFound.
+	FindProfile(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) (model.Profile, error)
+
+	// SaveProfile сохраняет профиль доступности владельца, заменяя ранее сохранённый.
+	SaveProfile(ctx context.Context, profile model.Profile) error
+
+	// AddFeedToken добавляет ссылку на календарь владельца.
+	// Если ссылка с таким идентификатором или хешем секрета уже есть - ErrFeedTokenAlreadyExists.
+	// Если у владельца в рабочем пространстве уже maxTokens ссылок - model.ErrTooManyFeedTokens,
+	// проверка атомарна с добавлением. 0 - без ограничения.
+	AddFeedToken(ctx context.Context, token model.FeedToken, maxTokens int) error
+
+	// QueryFeedTokens возвращает ссылки на календарь владельца ownerID в рабочем пространстве tenantID,
+	// упорядоченные по времени создания.
+	QueryFeedTokens(ctx context.Context, tenantID model.TenantID, ownerID model.OwnerID) ([]model.FeedToken, error)
+
+	// DeleteFeedToken удаляет ссылку tokenID на календарь владельца ownerID в рабочем пространстве tenantID.
+	// Если ссылки нет - ErrFeedTokenNotFound.
+	DeleteFeedToken(
+		ctx context.Context,
+		tenantID model.TenantID,
+		ownerID model.OwnerID,
+		tokenID model.FeedTokenID,
+	) error
+
+	// FindFeedToken находит ссылку на календарь (всех рабочих пространств) по хешу секрета hash.
+	// Если ссылки нет - ErrFeedTokenNotFound.
+	FindFeedToken(ctx context.Context, hash model.FeedTokenHash) (model.FeedToken, error)
+
+	// CountTenantEvents возвращает количество событий в рабочем пространстве tenantID.
+	CountTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error)
 
-	// DeleteEvent удаляет событие из коллекции по ownerID и eventID.
-	DeleteEvent(ctx context.Context, ownerID model.OwnerID, eventID model.ID) error
+	// QueryTenantEvents находит все события рабочего пространства tenantID,
+	// упорядоченные по владельцу и времени начала.
+	QueryTenantEvents(ctx context.Context, tenantID model.TenantID) ([]model.Event, error)
 
-	// QueryEvents находит все события в коллекции для ownerID, которые запланированы на указанный промежуток [from, to).
-	QueryEvents(ctx context.Context, ownerID model.OwnerID, from time.Time, to time.Time) ([]model.Event, error)
+	// DeleteTenantEvents удаляет все события, каталоги меток, профили и ссылки на календари
+	// рабочего пространства tenantID.
+	// Возвращает количество удалённых событий.
+	DeleteTenantEvents(ctx context.Context, tenantID model.TenantID) (int, error)
 
-	// PurgeOldEvents удаляет события из коллекции старше чем olderThan.
-	PurgeOldEvents(ctx context.Context, olderThan time.Time) error
+	// PurgeOldEvents удаляет события из коллекции (всех рабочих пространств) старше чем olderThan.
+	// Возвращает удалённые события, чтобы вызывающая сторона могла удалить связанные с ними данные
+	// (например, файлы вложений).
+	PurgeOldEvents(ctx context.Context, olderThan time.Time) ([]model.Event, error)
 
-	// QueryEventsToNotify находит все события в коллекции, по которым необходимо отправить уведомление
-	// в указанный промежуток времени [from, to).
+	// QueryEventsToNotify находит все события в коллекции (всех рабочих пространств),
+	// по которым необходимо отправить уведомление в указанный промежуток времени [from, to).
 	QueryEventsToNotify(ctx context.Context, from time.Time, to time.Time) ([]model.Event, error)
 }
@@ -0,0 +1,69 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// testAttachments проверяет сохранение вложений вместе с событием и возврат удалённых событий с вложениями.
+func testAttachments(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	ownerID := model.NewOwnerID()
+	startAt := baseTime().Add(-48 * time.Hour)
+
+	link, err := model.NewLinkAttachment("Agenda", "https://docs.example.com/agenda")
+	require.NoError(t, err)
+
+	file, err := model.NewFileAttachment("", "notes.txt", "text/plain")
+	require.NoError(t, err)
+	file.Size = 42
+
+	event := MkEvent(t, model.NewID(), ownerID, "meeting", startAt, startAt.Add(time.Hour), 0)
+	event.Attachments = []model.Attachment{link, file}
+	mustAddEvent(t, s, event)
+
+	t.Run("find", func(t *testing.T) {
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, event.EventID())
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, event, found, "must keep attachments")
+
+		event.Attachments[0].Title = "changed"
+		found, err = s.FindEvent(ctx, model.DefaultTenantID, ownerID, event.EventID())
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, "Agenda", found.Attachments[0].Title, "must not share attachments with caller")
+		event.Attachments[0].Title = "Agenda"
+	})
+
+	t.Run("update", func(t *testing.T) {
+		updated := event
+		updated.Attachments = []model.Attachment{file}
+		require.NoError(t, s.UpdateEvent(ctx, updated), "must update event")
+
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, event.EventID())
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.Attachment{file}, found.Attachments, "must update attachments")
+
+		updated.Attachments = nil
+		require.NoError(t, s.UpdateEvent(ctx, updated), "must update event")
+
+		found, err = s.FindEvent(ctx, model.DefaultTenantID, ownerID, event.EventID())
+		require.NoError(t, err, "must not have error")
+		require.Nil(t, found.Attachments, "must remove attachments")
+
+		require.NoError(t, s.UpdateEvent(ctx, event), "must update event")
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		events, err := s.PurgeOldEvents(ctx, startAt.Add(2*time.Hour))
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.Event{event}, events, "must return purged event with attachments")
+	})
+}
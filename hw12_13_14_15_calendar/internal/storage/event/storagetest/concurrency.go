@@ -0,0 +1,365 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// testConcurrency проверяет хранилище при одновременной работе нескольких горутин.
+// Имеет смысл запускать с флагом -race.
+func testConcurrency(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	t.Run("conflicting adds", func(t *testing.T) { testConflictingAdds(t, s) })
+	t.Run("mixed operations", func(t *testing.T) { testMixedOperations(t, s) })
+	t.Run("feed token limit", func(t *testing.T) { testFeedTokenLimit(t, s) })
+}
+
+// testConflictingAdds одновременно добавляет пересекающиеся события одного владельца:
+// добавлено должно быть ровно одно.
+func testConflictingAdds(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	const workers = 16
+
+	ownerID := model.NewOwnerID()
+	startAt := baseTime().Add(10 * time.Hour)
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, workers)
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			event := MkEvent(
+				t,
+				model.NewID(),
+				ownerID,
+				"conflict",
+				startAt.Add(time.Duration(i)*time.Minute),
+				startAt.Add(time.Hour+time.Duration(i)*time.Minute),
+				1,
+			)
+			errs[i] = s.AddEvent(context.Background(), event)
+		}(i)
+	}
+	wg.Wait()
+
+	added := 0
+	for _, err := range errs {
+		if err == nil {
+			added++
+			continue
+		}
+
+		require.ErrorIs(t, err, storage.ErrTimeIsBusy, "must be ErrTimeIsBusy error")
+	}
+
+	require.Equal(t, 1, added, "only one event must be added")
+	require.Len(t, OwnerEventIDs(t, s, ownerID), 1, "must have one event")
+}
+
+// testFeedTokenLimit одновременно добавляет ссылки на календарь одного владельца:
+// добавлено должно быть не больше ограничения.
+func testFeedTokenLimit(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	const (
+		workers   = 16
+		maxTokens = 3
+	)
+
+	ownerID := model.NewOwnerID()
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, workers)
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			token := MkFeedToken(t, model.DefaultTenantID, ownerID, "", baseTime())
+			errs[i] = s.AddFeedToken(context.Background(), token, maxTokens)
+		}(i)
+	}
+	wg.Wait()
+
+	added := 0
+	for _, err := range errs {
+		if err == nil {
+			added++
+			continue
+		}
+
+		require.ErrorIs(t, err, model.ErrTooManyFeedTokens, "must be ErrTooManyFeedTokens error")
+	}
+
+	require.Equal(t, maxTokens, added, "must add tokens up to limit")
+
+	tokens, err := s.QueryFeedTokens(context.Background(), model.DefaultTenantID, ownerID)
+	require.NoError(t, err, "must query feed tokens")
+	require.Len(t, tokens, maxTokens, "must not exceed limit")
+}
+
+// testMixedOperations одновременно выполняет все операции хранилища для нескольких владельцев
+// и проверяет, что после этого события каждого владельца не пересекаются.
+func testMixedOperations(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	const (
+		owners     = 4
+		workers    = 4
+		iterations = 25
+		slots      = 8
+	)
+
+	ctx := context.Background()
+	startAt := baseTime().Add(10 * time.Hour)
+
+	ownerIDs := make([]model.OwnerID, owners)
+	for i := range ownerIDs {
+		ownerIDs[i] = model.NewOwnerID()
+	}
+
+	// слоты по 1 часу, события занимают 1 или 2 слота и могут пересекаться
+	slotTime := func(n int) time.Time {
+		return startAt.Add(time.Duration(n%slots) * time.Hour)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		errsMx sync.Mutex
+		errs   []error
+	)
+
+	addErr := func(err error) {
+		errsMx.Lock()
+		defer errsMx.Unlock()
+
+		errs = append(errs, err)
+	}
+
+	// допустимые ошибки: событие пересекается с другим или уже удалено/обновлено другой горутиной
+	isExpected := func(err error) bool {
+		return err == nil ||
+			errors.Is(err, storage.ErrTimeIsBusy) ||
+			errors.Is(err, storage.ErrEventNotFound)
+	}
+
+	for o := 0; o < owners; o++ {
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(ownerID model.OwnerID, w int) {
+				defer wg.Done()
+
+				var own []model.ID
+
+				for i := 0; i < iterations; i++ {
+					n := w*iterations + i
+					duration := time.Duration(1+n%2) * time.Hour
+
+					switch {
+					case i%5 == 4 && len(own) > 0:
+						if err := s.DeleteEvent(ctx, model.DefaultTenantID, ownerID, own[0]); !isExpected(err) {
+							addErr(err)
+						}
+						own = own[1:]
+					case i%3 == 2 && len(own) > 0:
+						event := MkEvent(
+							t, own[len(own)-1], ownerID, "updated",
+							slotTime(n+1), slotTime(n+1).Add(duration), 1,
+						)
+						if err := s.UpdateEvent(ctx, event); !isExpected(err) {
+							addErr(err)
+						}
+					default:
+						event := MkEvent(
+							t, model.NewID(), ownerID, "added",
+							slotTime(n), slotTime(n).Add(duration), uint(n%2),
+						)
+						err := s.AddEvent(ctx, event)
+						if err == nil {
+							own = append(own, event.EventID())
+						} else if !isExpected(err) {
+							addErr(err)
+						}
+					}
+
+					_, err := s.QueryEvents(ctx, model.DefaultTenantID, ownerID, startAt, startAt.Add(slots*time.Hour), model.Filter{})
+					if err != nil {
+						addErr(err)
+					}
+
+					if _, err := s.QueryEventsToNotify(ctx, startAt.Add(-48*time.Hour), startAt); err != nil {
+						addErr(err)
+					}
+				}
+			}(ownerIDs[o], w)
+		}
+	}
+	wg.Wait()
+
+	require.Empty(t, errs, "must not have unexpected errors")
+
+	for _, ownerID := range ownerIDs {
+		events, err := s.QueryEvents(ctx, model.DefaultTenantID, ownerID, minTime, maxTime, model.Filter{})
+		require.NoError(t, err, "must not have error")
+
+		for i := 1; i < len(events); i++ {
+			require.False(
+				t,
+				events[i].StartAt().Before(events[i-1].EndAt()),
+				"events must not overlap",
+			)
+		}
+
+		for _, event := range events {
+			found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, event.EventID())
+			require.NoError(t, err, "must find event")
+			require.True(t, found.StartAt().Equal(event.StartAt()), "must be equal")
+		}
+	}
+}
+
+// testContextCanceled проверяет, что операции с отменённым контекстом завершаются ошибкой
+// и не изменяют хранилище.
+func testContextCanceled(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	args := Populate(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	event := MkEvent(t, model.NewID(), args.OwnerIDs[1], "canceled", args.Times[0][0], args.Times[0][1], 1)
+	updated := MkEvent(t, args.EventIDs[0], args.OwnerIDs[0], "canceled", args.Times[2][0], args.Times[2][1], 1)
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{
+			name: "AddEvent",
+			fn:   func() error { return s.AddEvent(ctx, event) },
+		},
+		{
+			name: "UpdateEvent",
+			fn:   func() error { return s.UpdateEvent(ctx, updated) },
+		},
+		{
+			name: "FindEvent",
+			fn: func() error {
+				_, err := s.FindEvent(ctx, model.DefaultTenantID, args.OwnerIDs[0], args.EventIDs[0])
+				return err
+			},
+		},
+		{
+			name: "DeleteEvent",
+			fn:   func() error { return s.DeleteEvent(ctx, model.DefaultTenantID, args.OwnerIDs[0], args.EventIDs[1]) },
+		},
+		{
+			name: "QueryEvents",
+			fn: func() error {
+				_, err := s.QueryEvents(ctx, model.DefaultTenantID, args.OwnerIDs[0], minTime, maxTime, model.Filter{})
+				return err
+			},
+		},
+		{
+			name: "QueryEventsNear",
+			fn: func() error {
+				area := model.Area{Center: model.Point{}, Radius: 1000}
+				_, err := s.QueryEventsNear(ctx, model.DefaultTenantID, args.OwnerIDs[0], minTime, maxTime, area)
+				return err
+			},
+		},
+		{
+			name: "FindProfile",
+			fn: func() error {
+				_, err := s.FindProfile(ctx, model.DefaultTenantID, args.OwnerIDs[0])
+				return err
+			},
+		},
+		{
+			name: "SaveProfile",
+			fn: func() error {
+				return s.SaveProfile(ctx, model.NewProfile(model.DefaultTenantID, args.OwnerIDs[0]))
+			},
+		},
+		{
+			name: "AddFeedToken",
+			fn: func() error {
+				return s.AddFeedToken(ctx, MkFeedToken(t, model.DefaultTenantID, args.OwnerIDs[0], "", minTime), 0)
+			},
+		},
+		{
+			name: "QueryFeedTokens",
+			fn: func() error {
+				_, err := s.QueryFeedTokens(ctx, model.DefaultTenantID, args.OwnerIDs[0])
+				return err
+			},
+		},
+		{
+			name: "DeleteFeedToken",
+			fn: func() error {
+				return s.DeleteFeedToken(ctx, model.DefaultTenantID, args.OwnerIDs[0], model.NewFeedTokenID())
+			},
+		},
+		{
+			name: "FindFeedToken",
+			fn: func() error {
+				_, err := s.FindFeedToken(ctx, model.FeedTokenHash("hash"))
+				return err
+			},
+		},
+		{
+			name: "PurgeOldEvents",
+			fn: func() error {
+				_, err := s.PurgeOldEvents(ctx, maxTime)
+				return err
+			},
+		},
+		{
+			name: "QueryEventsToNotify",
+			fn: func() error {
+				_, err := s.QueryEventsToNotify(ctx, minTime, maxTime)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, tt.fn(), context.Canceled, "must be context.Canceled error")
+		})
+	}
+
+	t.Run("not changed", func(t *testing.T) {
+		require.Equal(
+			t,
+			[]model.ID{args.EventIDs[1], args.EventIDs[0]},
+			OwnerEventIDs(t, s, args.OwnerIDs[0]),
+			"proper events for user #1",
+		)
+		require.Empty(t, OwnerEventIDs(t, s, args.OwnerIDs[1]), "must have no events for user #2")
+		require.Len(t, OwnerEventIDs(t, s, args.OwnerIDs[2]), 2, "must have 2 events for user #3")
+
+		found, err := s.FindEvent(context.Background(), model.DefaultTenantID, args.OwnerIDs[0], args.EventIDs[0])
+		require.NoError(t, err, "must find event")
+		require.Equal(t, model.Title("1"), found.Title, "title must not change")
+	})
+}
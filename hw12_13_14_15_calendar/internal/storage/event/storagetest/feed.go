@@ -0,0 +1,156 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// MkFeedToken создаёт ссылку на календарь владельца ownerID в рабочем пространстве tenantID
+// со случайным секретом, созданную в createdAt.
+func MkFeedToken(
+	t *testing.T,
+	tenantID model.TenantID,
+	ownerID model.OwnerID,
+	name string,
+	createdAt time.Time,
+) model.FeedToken {
+	t.Helper()
+
+	secret, err := model.NewFeedSecret()
+	require.NoError(t, err, "must generate feed secret")
+
+	token := model.NewFeedToken(tenantID, ownerID, model.NewFeedTokenID(), secret.Hash())
+	token.Name = name
+	token.CreatedAt = createdAt
+
+	return token
+}
+
+// testFeedTokens проверяет хранение ссылок на календари владельцев.
+func testFeedTokens(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	ownerID := model.NewOwnerID()
+	createdAt := time.Date(2030, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+	phone := MkFeedToken(t, model.DefaultTenantID, ownerID, "телефон", createdAt.Add(time.Hour))
+	laptop := MkFeedToken(t, model.DefaultTenantID, ownerID, "", createdAt)
+
+	tokenIDs := func(tokens []model.FeedToken) []model.FeedTokenID {
+		ids := make([]model.FeedTokenID, 0, len(tokens))
+		for _, token := range tokens {
+			ids = append(ids, token.TokenID())
+		}
+
+		return ids
+	}
+
+	t.Run("add", func(t *testing.T) {
+		require.NoError(t, s.AddFeedToken(ctx, phone, 0), "must add feed token")
+		require.NoError(t, s.AddFeedToken(ctx, laptop, 0), "must add feed token")
+
+		err := s.AddFeedToken(ctx, phone, 0)
+		require.ErrorIs(t, err, storage.ErrFeedTokenAlreadyExists, "must not add token twice")
+
+		sameHash := model.NewFeedToken(model.DefaultTenantID, model.NewOwnerID(), model.NewFeedTokenID(), phone.Hash())
+		err = s.AddFeedToken(ctx, sameHash, 0)
+		require.ErrorIs(t, err, storage.ErrFeedTokenAlreadyExists, "must not add token with the same hash")
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		const maxTokens = 2
+
+		limitedID := model.NewOwnerID()
+		for range maxTokens {
+			token := MkFeedToken(t, model.DefaultTenantID, limitedID, "", createdAt)
+			require.NoError(t, s.AddFeedToken(ctx, token, maxTokens), "must add feed token up to limit")
+		}
+
+		token := MkFeedToken(t, model.DefaultTenantID, limitedID, "", createdAt)
+		err := s.AddFeedToken(ctx, token, maxTokens)
+		require.ErrorIs(t, err, model.ErrTooManyFeedTokens, "must not add token over limit")
+
+		_, err = s.FindFeedToken(ctx, token.Hash())
+		require.ErrorIs(t, err, storage.ErrFeedTokenNotFound, "must not keep token over limit")
+
+		token = MkFeedToken(t, "other", limitedID, "", createdAt)
+		require.NoError(t, s.AddFeedToken(ctx, token, maxTokens), "must limit tokens per tenant")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		tokens, err := s.QueryFeedTokens(ctx, model.DefaultTenantID, ownerID)
+		require.NoError(t, err, "must query feed tokens")
+		require.Equal(
+			t,
+			[]model.FeedTokenID{laptop.TokenID(), phone.TokenID()},
+			tokenIDs(tokens),
+			"must be ordered by creation time",
+		)
+		require.Equal(t, "телефон", tokens[1].Name, "must keep name")
+		require.Equal(t, phone.Hash(), tokens[1].Hash(), "must keep hash")
+		require.True(t, phone.CreatedAt.Equal(tokens[1].CreatedAt), "must keep creation time")
+
+		tokens, err = s.QueryFeedTokens(ctx, model.DefaultTenantID, model.NewOwnerID())
+		require.NoError(t, err, "must query feed tokens")
+		require.Empty(t, tokens, "must not have tokens of other owner")
+
+		tokens, err = s.QueryFeedTokens(ctx, "other", ownerID)
+		require.NoError(t, err, "must query feed tokens")
+		require.Empty(t, tokens, "must not have tokens in other tenant")
+	})
+
+	t.Run("find", func(t *testing.T) {
+		found, err := s.FindFeedToken(ctx, phone.Hash())
+		require.NoError(t, err, "must find feed token")
+		require.Equal(t, phone.TokenID(), found.TokenID(), "must find proper token")
+		require.Equal(t, model.DefaultTenantID, found.TenantID(), "must keep tenant")
+		require.Equal(t, ownerID, found.OwnerID(), "must keep owner")
+
+		secret, err := model.NewFeedSecret()
+		require.NoError(t, err, "must generate feed secret")
+
+		_, err = s.FindFeedToken(ctx, secret.Hash())
+		require.ErrorIs(t, err, storage.ErrFeedTokenNotFound, "must be ErrFeedTokenNotFound error")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := s.DeleteFeedToken(ctx, model.DefaultTenantID, model.NewOwnerID(), phone.TokenID())
+		require.ErrorIs(t, err, storage.ErrFeedTokenNotFound, "must not delete token of other owner")
+
+		err = s.DeleteFeedToken(ctx, "other", ownerID, phone.TokenID())
+		require.ErrorIs(t, err, storage.ErrFeedTokenNotFound, "must not delete token in other tenant")
+
+		require.NoError(t, s.DeleteFeedToken(ctx, model.DefaultTenantID, ownerID, phone.TokenID()), "must delete")
+
+		err = s.DeleteFeedToken(ctx, model.DefaultTenantID, ownerID, phone.TokenID())
+		require.ErrorIs(t, err, storage.ErrFeedTokenNotFound, "must not delete token twice")
+
+		_, err = s.FindFeedToken(ctx, phone.Hash())
+		require.ErrorIs(t, err, storage.ErrFeedTokenNotFound, "must not find deleted token")
+
+		tokens, err := s.QueryFeedTokens(ctx, model.DefaultTenantID, ownerID)
+		require.NoError(t, err, "must query feed tokens")
+		require.Equal(t, []model.FeedTokenID{laptop.TokenID()}, tokenIDs(tokens), "must keep other tokens")
+	})
+
+	t.Run("wipe tenant", func(t *testing.T) {
+		wiped := MkFeedToken(t, "wiped", ownerID, "", createdAt)
+		require.NoError(t, s.AddFeedToken(ctx, wiped, 0), "must add feed token")
+
+		_, err := s.DeleteTenantEvents(ctx, "wiped")
+		require.NoError(t, err, "must wipe tenant")
+
+		_, err = s.FindFeedToken(ctx, wiped.Hash())
+		require.ErrorIs(t, err, storage.ErrFeedTokenNotFound, "must delete tokens of tenant")
+
+		_, err = s.FindFeedToken(ctx, laptop.Hash())
+		require.NoError(t, err, "must keep tokens of other tenants")
+	})
+}
@@ -0,0 +1,182 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// testLabels проверяет каталог меток владельца, метки и цвет событий и выборку событий по меткам.
+func testLabels(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	startAt := baseTime().Add(24 * time.Hour)
+
+	ownerID := model.NewOwnerID()
+	otherOwnerID := model.NewOwnerID()
+	ids := []model.ID{model.NewID(), model.NewID(), model.NewID()}
+
+	mkLabel := func(tenantID model.TenantID, ownerID model.OwnerID, name model.Label, color model.Color) model.OwnerLabel {
+		return model.NewOwnerLabel(tenantID, ownerID, name, color)
+	}
+
+	mkEvent := func(i int, labels ...model.Label) model.Event {
+		event := MkEvent(
+			t,
+			ids[i],
+			ownerID,
+			"event",
+			startAt.Add(time.Duration(i)*time.Hour),
+			startAt.Add(time.Duration(i)*time.Hour+time.Minute),
+			0,
+		)
+		if len(labels) > 0 {
+			event.Labels = labels
+		}
+
+		return event
+	}
+
+	queryIDs := func(t *testing.T, labels ...model.Label) []model.ID {
+		t.Helper()
+
+		events, err := s.QueryEvents(ctx, model.DefaultTenantID, ownerID, minTime, maxTime, model.Filter{Labels: labels})
+		require.NoError(t, err, "must not have error")
+
+		return eventIDs(events)
+	}
+
+	t.Run("catalog", func(t *testing.T) {
+		for _, label := range []model.OwnerLabel{
+			mkLabel(model.DefaultTenantID, ownerID, "work", "#ff0000"),
+			mkLabel(model.DefaultTenantID, ownerID, "home", ""),
+			mkLabel(model.DefaultTenantID, ownerID, "sport", "#00ff00"),
+			mkLabel(model.DefaultTenantID, otherOwnerID, "work", ""),
+			mkLabel("hr", ownerID, "work", ""),
+		} {
+			require.NoErrorf(t, s.AddLabel(ctx, label), "must add label %s", label.Name())
+		}
+
+		err := s.AddLabel(ctx, mkLabel(model.DefaultTenantID, ownerID, "work", ""))
+		require.ErrorIs(t, err, storage.ErrLabelAlreadyExists, "must not add duplicate label")
+
+		err = s.UpdateLabel(ctx, mkLabel(model.DefaultTenantID, ownerID, "home", "#0000ff"))
+		require.NoError(t, err, "must update label")
+
+		err = s.UpdateLabel(ctx, mkLabel(model.DefaultTenantID, ownerID, "unknown", ""))
+		require.ErrorIs(t, err, storage.ErrLabelNotFound, "must not update unknown label")
+
+		labels, err := s.QueryLabels(ctx, model.DefaultTenantID, ownerID)
+		require.NoError(t, err, "must not have error")
+		require.Equal(
+			t,
+			[]model.OwnerLabel{
+				mkLabel(model.DefaultTenantID, ownerID, "home", "#0000ff"),
+				mkLabel(model.DefaultTenantID, ownerID, "sport", "#00ff00"),
+				mkLabel(model.DefaultTenantID, ownerID, "work", "#ff0000"),
+			},
+			labels,
+			"must query labels of owner ordered by name",
+		)
+
+		labels, err = s.QueryLabels(ctx, model.DefaultTenantID, model.NewOwnerID())
+		require.NoError(t, err, "must not have error")
+		require.Empty(t, labels, "unknown owner has no labels")
+	})
+
+	t.Run("events", func(t *testing.T) {
+		event := mkEvent(0, "home", "work")
+		event.Color = "#abcdef"
+		mustAddEvent(t, s, event)
+		mustAddEvent(t, s, mkEvent(1, "sport"))
+		mustAddEvent(t, s, mkEvent(2))
+
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, ids[0])
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, event, found, "must keep labels and color")
+
+		err = s.AddEvent(ctx, MkEvent(t, model.NewID(), otherOwnerID, "other", startAt, startAt.Add(time.Minute), 0))
+		require.NoError(t, err, "must add event without labels")
+
+		err = s.AddEvent(ctx, mkEvent(2, "unknown"))
+		require.ErrorIs(t, err, storage.ErrEventAlreadyExists, "must check event before labels")
+
+		other := MkEvent(t, model.NewID(), otherOwnerID, "other", startAt.Add(time.Hour), startAt.Add(2*time.Hour), 0)
+		other.Labels = []model.Label{"sport"}
+		require.ErrorIs(t, s.AddEvent(ctx, other), storage.ErrUnknownLabel, "must not use label of other owner")
+
+		require.ErrorIs(
+			t,
+			s.UpdateEvent(ctx, mkEvent(1, "sport", "unknown")),
+			storage.ErrUnknownLabel,
+			"must not update event with unknown label",
+		)
+
+		found, err = s.FindEvent(ctx, model.DefaultTenantID, ownerID, ids[1])
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.Label{"sport"}, found.Labels, "must keep labels of event on failed update")
+
+		require.NoError(t, s.UpdateEvent(ctx, mkEvent(1, "home")), "must update labels of event")
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		require.Equal(t, ids, queryIDs(t), "empty filter matches all events")
+		require.Equal(t, []model.ID{ids[0], ids[1]}, queryIDs(t, "home"), "must filter by label")
+		require.Equal(t, []model.ID{ids[0]}, queryIDs(t, "work"), "must filter by label")
+		require.Equal(t, []model.ID{ids[0], ids[1]}, queryIDs(t, "home", "work"), "must match any of labels")
+		require.Equal(t, []model.ID{}, queryIDs(t, "sport"), "no events with label")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, s.DeleteLabel(ctx, model.DefaultTenantID, ownerID, "home"), "must delete label")
+
+		err := s.DeleteLabel(ctx, model.DefaultTenantID, ownerID, "home")
+		require.ErrorIs(t, err, storage.ErrLabelNotFound, "must not delete unknown label")
+
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, ids[0])
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.Label{"work"}, found.Labels, "must remove deleted label from event")
+
+		found, err = s.FindEvent(ctx, model.DefaultTenantID, ownerID, ids[1])
+		require.NoError(t, err, "must not have error")
+		require.Nil(t, found.Labels, "must remove deleted label from event")
+
+		require.Equal(t, []model.ID{}, queryIDs(t, "home"), "deleted label matches nothing")
+
+		labels, err := s.QueryLabels(ctx, model.DefaultTenantID, otherOwnerID)
+		require.NoError(t, err, "must not have error")
+		require.Len(t, labels, 1, "must keep labels of other owner")
+	})
+
+	t.Run("delete event", func(t *testing.T) {
+		require.NoError(t, s.DeleteEvent(ctx, model.DefaultTenantID, ownerID, ids[0]), "must delete event")
+
+		// новое событие с тем же идентификатором не наследует метки удалённого
+		mustAddEvent(t, s, mkEvent(0))
+
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, ids[0])
+		require.NoError(t, err, "must not have error")
+		require.Nil(t, found.Labels, "must not keep labels of deleted event")
+	})
+
+	t.Run("wipe", func(t *testing.T) {
+		_, err := s.DeleteTenantEvents(ctx, model.DefaultTenantID)
+		require.NoError(t, err, "must not have error")
+
+		labels, err := s.QueryLabels(ctx, model.DefaultTenantID, ownerID)
+		require.NoError(t, err, "must not have error")
+		require.Empty(t, labels, "must delete labels of tenant")
+
+		labels, err = s.QueryLabels(ctx, "hr", ownerID)
+		require.NoError(t, err, "must not have error")
+		require.Len(t, labels, 1, "must keep labels of other tenant")
+
+		require.NoError(t, s.AddLabel(ctx, mkLabel(model.DefaultTenantID, ownerID, "work", "")), "must add label again")
+	})
+}
@@ -0,0 +1,116 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// testLocation проверяет сохранение места проведения и ссылки на конференцию, а также поиск событий по области.
+func testLocation(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	ownerID := model.NewOwnerID()
+	startAt := baseTime()
+
+	point := func(lat, lon float64) *model.Point {
+		return &model.Point{Latitude: lat, Longitude: lon}
+	}
+
+	mk := func(i int, title model.Title, location model.Location) model.Event {
+		t.Helper()
+
+		from := startAt.Add(time.Duration(i) * time.Hour)
+		event := MkEvent(t, model.NewID(), ownerID, title, from, from.Add(time.Hour), 0)
+		event.Location = location
+		mustAddEvent(t, s, event)
+
+		return event
+	}
+
+	office := mk(0, "office", model.Location{Name: "room 3", Address: "Tverskaya 1", Point: point(55.7558, 37.6173)})
+	office.ConferenceURL = "https://meet.example.com/abc"
+	require.NoError(t, s.UpdateEvent(ctx, office), "must update event")
+
+	nearby := mk(1, "nearby", model.Location{Name: "cafe", Point: point(55.76, 37.62)})
+	mk(2, "far", model.Location{Point: point(59.9343, 30.3351)})
+	mk(3, "no point", model.Location{Name: "somewhere"})
+	mk(4, "no location", model.Location{})
+
+	// события рядом с 180-м меридианом
+	east := mk(5, "east", model.Location{Point: point(65, 179.95)})
+	west := mk(6, "west", model.Location{Point: point(65, -179.95)})
+
+	t.Run("find", func(t *testing.T) {
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, office.EventID())
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, office, found, "must keep location and conference URL")
+
+		office.Location.Point.Latitude = 0
+		found, err = s.FindEvent(ctx, model.DefaultTenantID, ownerID, office.EventID())
+		require.NoError(t, err, "must not have error")
+		require.InDelta(t, 55.7558, found.Location.Point.Latitude, 1e-9, "must not share point with caller")
+		office.Location.Point.Latitude = 55.7558
+	})
+
+	t.Run("update", func(t *testing.T) {
+		updated := nearby
+		updated.Location = model.Location{}
+		require.NoError(t, s.UpdateEvent(ctx, updated), "must update event")
+
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, nearby.EventID())
+		require.NoError(t, err, "must not have error")
+		require.True(t, found.Location.IsZero(), "must remove location")
+
+		require.NoError(t, s.UpdateEvent(ctx, nearby), "must update event")
+	})
+
+	t.Run("near", func(t *testing.T) {
+		query := func(center model.Point, radius float64, from, to time.Time) []model.Event {
+			t.Helper()
+
+			area, err := model.NewArea(center, radius)
+			require.NoError(t, err, "must create area")
+
+			events, err := s.QueryEventsNear(ctx, model.DefaultTenantID, ownerID, from, to, area)
+			require.NoError(t, err, "must not have error")
+
+			return events
+		}
+
+		end := startAt.Add(24 * time.Hour)
+
+		events := query(*office.Location.Point, 5_000, startAt, end)
+		require.Equal(t, []model.Event{office, nearby}, events, "must find events in area ordered by start")
+
+		events = query(*office.Location.Point, 1_000_000, startAt, end)
+		require.Equal(t, []model.ID{office.EventID(), nearby.EventID()}, eventIDs(events)[:2], "must find events")
+		require.Len(t, events, 3, "must find far event in large area")
+
+		events = query(*office.Location.Point, 5_000, startAt.Add(time.Hour), end)
+		require.Equal(t, []model.Event{nearby}, events, "must find events in time range")
+
+		events = query(*office.Location.Point, 5_000, end, end.Add(time.Hour))
+		require.Empty(t, events, "must not find events out of time range")
+
+		events = query(model.Point{Latitude: 65, Longitude: 180}, 10_000, startAt, end)
+		require.Equal(t, []model.Event{east, west}, events, "must find events across antimeridian")
+
+		area, err := model.NewArea(*office.Location.Point, 5_000)
+		require.NoError(t, err)
+
+		events, err = s.QueryEventsNear(ctx, model.DefaultTenantID, model.NewOwnerID(), startAt, end, area)
+		require.NoError(t, err, "must not have error")
+		require.Empty(t, events, "must not find events of other owner")
+
+		events, err = s.QueryEventsNear(ctx, "other", ownerID, startAt, end, area)
+		require.NoError(t, err, "must not have error")
+		require.Empty(t, events, "must not find events of other tenant")
+	})
+}
@@ -0,0 +1,141 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// testNotifyWindows проверяет окна уведомлений QueryEventsToNotify: уведомление о событии
+// необходимо отправить в момент startAt - notifyBefore дней, момент должен попадать в промежуток [from, to).
+func testNotifyWindows(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	day := 24 * time.Hour
+
+	startAt := baseTime().Add(10 * day)
+
+	owners := []model.OwnerID{model.NewOwnerID(), model.NewOwnerID()}
+	ids := []model.ID{model.NewID(), model.NewID(), model.NewID(), model.NewID()}
+
+	// notifyAt: ids[0], ids[1] - startAt-1d; ids[2] - startAt+1h-2d; ids[3] - без уведомления
+	mustAddEvent(t, s, MkEvent(t, ids[0], owners[0], "1", startAt, startAt.Add(time.Hour), 1))
+	mustAddEvent(t, s, MkEvent(t, ids[1], owners[1], "2", startAt.Add(time.Minute), startAt.Add(time.Hour), 1))
+	mustAddEvent(t, s, MkEvent(t, ids[2], owners[0], "3", startAt.Add(time.Hour), startAt.Add(2*time.Hour), 2))
+	mustAddEvent(t, s, MkEvent(t, ids[3], owners[1], "4", startAt.Add(2*time.Hour), startAt.Add(3*time.Hour), 0))
+
+	notifyAt := startAt.Add(-day)
+	notifyAt2 := startAt.Add(time.Hour - 2*day)
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		eventIDs []model.ID
+	}{
+		{
+			name:     "from equals notifyAt",
+			from:     notifyAt,
+			to:       notifyAt.Add(time.Microsecond),
+			eventIDs: []model.ID{ids[0]},
+		},
+		{
+			name:     "to equals notifyAt",
+			from:     notifyAt.Add(-time.Hour),
+			to:       notifyAt,
+			eventIDs: []model.ID{},
+		},
+		{
+			name:     "ordered by startAt",
+			from:     notifyAt,
+			to:       notifyAt.Add(time.Hour),
+			eventIDs: []model.ID{ids[0], ids[1]},
+		},
+		{
+			name:     "notify before several days",
+			from:     notifyAt2,
+			to:       notifyAt2.Add(time.Hour),
+			eventIDs: []model.ID{ids[2]},
+		},
+		{
+			name:     "all",
+			from:     minTime,
+			to:       maxTime,
+			eventIDs: []model.ID{ids[0], ids[1], ids[2]},
+		},
+		{
+			name:     "at startAt",
+			from:     startAt,
+			to:       startAt.Add(3 * time.Hour),
+			eventIDs: []model.ID{},
+		},
+		{
+			name:     "empty range",
+			from:     notifyAt,
+			to:       notifyAt,
+			eventIDs: []model.ID{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := s.QueryEventsToNotify(ctx, tt.from, tt.to)
+			require.NoError(t, err, "must not have error")
+			require.Equal(t, tt.eventIDs, eventIDs(events), "proper result")
+		})
+	}
+
+	// запросы не изменяют хранилище: повторный и более ранний запросы возвращают тот же результат
+	t.Run("repeatable", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			events, err := s.QueryEventsToNotify(ctx, notifyAt, notifyAt.Add(time.Hour))
+			require.NoError(t, err, "must not have error")
+			require.Equal(t, []model.ID{ids[0], ids[1]}, eventIDs(events), "proper result")
+		}
+
+		events, err := s.QueryEventsToNotify(ctx, notifyAt2, notifyAt2.Add(time.Hour))
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.ID{ids[2]}, eventIDs(events), "proper result for earlier window")
+	})
+
+	t.Run("added after query", func(t *testing.T) {
+		_, err := s.QueryEventsToNotify(ctx, notifyAt, notifyAt.Add(time.Hour))
+		require.NoError(t, err, "must not have error")
+
+		// событие, уведомление о котором раньше последнего запроса
+		id := model.NewID()
+		mustAddEvent(t, s, MkEvent(t, id, owners[1], "5", startAt.Add(-time.Hour), startAt, 1))
+
+		events, err := s.QueryEventsToNotify(ctx, notifyAt.Add(-time.Hour), notifyAt)
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.ID{id}, eventIDs(events), "proper result")
+
+		require.NoError(t, s.DeleteEvent(ctx, model.DefaultTenantID, owners[1], id), "must delete")
+	})
+
+	t.Run("update notifyBefore", func(t *testing.T) {
+		event := MkEvent(t, ids[1], owners[1], "2", startAt.Add(time.Minute), startAt.Add(time.Hour), 0)
+		require.NoError(t, s.UpdateEvent(ctx, event), "must update")
+
+		event = MkEvent(t, ids[3], owners[1], "4", startAt.Add(2*time.Hour), startAt.Add(3*time.Hour), 1)
+		require.NoError(t, s.UpdateEvent(ctx, event), "must update")
+
+		events, err := s.QueryEventsToNotify(ctx, notifyAt, notifyAt.Add(3*time.Hour))
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.ID{ids[0], ids[3]}, eventIDs(events), "proper result")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, s.DeleteEvent(ctx, model.DefaultTenantID, owners[0], ids[0]), "must delete")
+
+		events, err := s.QueryEventsToNotify(ctx, minTime, maxTime)
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.ID{ids[2], ids[3]}, eventIDs(events), "proper result")
+	})
+}
@@ -0,0 +1,332 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// testOverlap проверяет правила пересечения событий: время события - промежуток [startAt, endAt),
+// события одного владельца не могут пересекаться, события разных владельцев - независимы.
+func testOverlap(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	/*
+	*                 [startAt ========== endAt)
+	*    [-- before --)                        [-- after --)
+	 */
+	startAt := baseTime().Add(10 * time.Hour)
+	endAt := startAt.Add(2 * time.Hour)
+
+	ownerID := model.NewOwnerID()
+	eventID := model.NewID()
+	mustAddEvent(t, s, MkEvent(t, eventID, ownerID, "base", startAt, endAt, 0))
+
+	busyTests := []struct {
+		name    string
+		startAt time.Time
+		endAt   time.Time
+	}{
+		{name: "equal", startAt: startAt, endAt: endAt},
+		{name: "inside", startAt: startAt.Add(time.Minute), endAt: endAt.Add(-time.Minute)},
+		{name: "contains", startAt: startAt.Add(-time.Minute), endAt: endAt.Add(time.Minute)},
+		{name: "overlap startAt", startAt: startAt.Add(-time.Minute), endAt: startAt.Add(time.Minute)},
+		{name: "overlap endAt", startAt: endAt.Add(-time.Minute), endAt: endAt.Add(time.Minute)},
+		{name: "same startAt", startAt: startAt, endAt: startAt.Add(time.Minute)},
+		{name: "same endAt", startAt: endAt.Add(-time.Minute), endAt: endAt},
+		{name: "one microsecond", startAt: endAt.Add(-time.Microsecond), endAt: endAt.Add(time.Hour)},
+	}
+
+	for _, tt := range busyTests {
+		t.Run("busy "+tt.name, func(t *testing.T) {
+			err := s.AddEvent(ctx, MkEvent(t, model.NewID(), ownerID, "busy", tt.startAt, tt.endAt, 0))
+			require.ErrorIs(t, err, storage.ErrTimeIsBusy, "must be ErrTimeIsBusy error")
+		})
+	}
+
+	t.Run("busy does not change storage", func(t *testing.T) {
+		require.Equal(t, []model.ID{eventID}, OwnerEventIDs(t, s, ownerID), "must have only base event")
+	})
+
+	t.Run("other owner same time", func(t *testing.T) {
+		mustAddEvent(t, s, MkEvent(t, model.NewID(), model.NewOwnerID(), "other", startAt, endAt, 0))
+	})
+
+	t.Run("other owner same event id", func(t *testing.T) {
+		otherOwnerID := model.NewOwnerID()
+		mustAddEvent(t, s, MkEvent(t, eventID, otherOwnerID, "other", startAt, endAt, 0))
+
+		require.Equal(t, []model.ID{eventID}, OwnerEventIDs(t, s, otherOwnerID), "must have event")
+	})
+
+	t.Run("duplicate has priority over busy", func(t *testing.T) {
+		err := s.AddEvent(ctx, MkEvent(t, eventID, ownerID, "dup", startAt.Add(time.Minute), endAt, 0))
+		require.ErrorIs(t, err, storage.ErrEventAlreadyExists, "must be ErrEventAlreadyExists error")
+	})
+
+	beforeID := model.NewID()
+	afterID := model.NewID()
+
+	t.Run("adjacent before", func(t *testing.T) {
+		mustAddEvent(t, s, MkEvent(t, beforeID, ownerID, "before", startAt.Add(-time.Hour), startAt, 0))
+	})
+
+	t.Run("adjacent after", func(t *testing.T) {
+		mustAddEvent(t, s, MkEvent(t, afterID, ownerID, "after", endAt, endAt.Add(time.Hour), 0))
+	})
+
+	t.Run("adjacent order", func(t *testing.T) {
+		require.Equal(
+			t,
+			[]model.ID{beforeID, eventID, afterID},
+			OwnerEventIDs(t, s, ownerID),
+			"proper order",
+		)
+	})
+
+	t.Run("update overlaps own time", func(t *testing.T) {
+		event := MkEvent(t, eventID, ownerID, "shifted", startAt.Add(30*time.Minute), endAt, 0)
+		require.NoError(t, s.UpdateEvent(ctx, event), "must update")
+
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, eventID)
+		require.NoError(t, err, "must find")
+		require.True(t, found.StartAt().Equal(startAt.Add(30*time.Minute)), "proper startAt")
+		require.Equal(t, model.Title("shifted"), found.Title, "proper title")
+	})
+
+	t.Run("update adjacent to neighbours", func(t *testing.T) {
+		event := MkEvent(t, eventID, ownerID, "base", startAt, endAt, 0)
+		require.NoError(t, s.UpdateEvent(ctx, event), "must update")
+	})
+
+	t.Run("update busy does not change event", func(t *testing.T) {
+		event := MkEvent(t, eventID, ownerID, "busy", startAt.Add(-time.Minute), endAt, 3)
+		err := s.UpdateEvent(ctx, event)
+		require.ErrorIs(t, err, storage.ErrTimeIsBusy, "must be ErrTimeIsBusy error")
+
+		found, err := s.FindEvent(ctx, model.DefaultTenantID, ownerID, eventID)
+		require.NoError(t, err, "must find")
+		require.Equal(t, model.Title("base"), found.Title, "title must not change")
+		require.Equal(t, uint(0), found.NotifyBefore, "notifyBefore must not change")
+		require.True(t, found.StartAt().Equal(startAt), "startAt must not change")
+		require.True(t, found.EndAt().Equal(endAt), "endAt must not change")
+	})
+
+	t.Run("deleted time is free", func(t *testing.T) {
+		require.NoError(t, s.DeleteEvent(ctx, model.DefaultTenantID, ownerID, eventID), "must delete")
+		mustAddEvent(t, s, MkEvent(t, model.NewID(), ownerID, "new", startAt, endAt, 0))
+	})
+
+	t.Run("deleted event id is free", func(t *testing.T) {
+		mustAddEvent(t, s, MkEvent(
+			t, eventID, ownerID, "new",
+			endAt.Add(time.Hour), endAt.Add(2*time.Hour), 0,
+		))
+	})
+}
+
+// testQueryBoundaries проверяет границы промежутка [from, to) в QueryEvents.
+func testQueryBoundaries(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	startAt := baseTime().Add(10 * time.Hour)
+	endAt := startAt.Add(time.Hour)
+
+	ownerID := model.NewOwnerID()
+	ids := []model.ID{model.NewID(), model.NewID(), model.NewID()}
+
+	// три события подряд без промежутков: [startAt, endAt) [endAt, endAt+1h) [endAt+1h, endAt+2h)
+	mustAddEvent(t, s, MkEvent(t, ids[0], ownerID, "1", startAt, endAt, 0))
+	mustAddEvent(t, s, MkEvent(t, ids[1], ownerID, "2", endAt, endAt.Add(time.Hour), 0))
+	mustAddEvent(t, s, MkEvent(t, ids[2], ownerID, "3", endAt.Add(time.Hour), endAt.Add(2*time.Hour), 0))
+
+	tests := []struct {
+		name     string
+		ownerID  model.OwnerID
+		from     time.Time
+		to       time.Time
+		eventIDs []model.ID
+	}{
+		{
+			name:     "to equals startAt",
+			from:     startAt.Add(-time.Hour),
+			to:       startAt,
+			eventIDs: []model.ID{},
+		},
+		{
+			name:     "from equals endAt of last",
+			from:     endAt.Add(2 * time.Hour),
+			to:       endAt.Add(3 * time.Hour),
+			eventIDs: []model.ID{},
+		},
+		{
+			name:     "one microsecond at startAt",
+			from:     startAt,
+			to:       startAt.Add(time.Microsecond),
+			eventIDs: []model.ID{ids[0]},
+		},
+		{
+			name:     "one microsecond before endAt",
+			from:     endAt.Add(-time.Microsecond),
+			to:       endAt,
+			eventIDs: []model.ID{ids[0]},
+		},
+		{
+			name:     "adjacent edge",
+			from:     endAt,
+			to:       endAt.Add(time.Microsecond),
+			eventIDs: []model.ID{ids[1]},
+		},
+		{
+			name:     "across adjacent edge",
+			from:     endAt.Add(-time.Microsecond),
+			to:       endAt.Add(time.Microsecond),
+			eventIDs: []model.ID{ids[0], ids[1]},
+		},
+		{
+			name:     "all",
+			from:     startAt,
+			to:       endAt.Add(2 * time.Hour),
+			eventIDs: ids,
+		},
+		{
+			name:     "empty range inside event",
+			from:     startAt.Add(time.Minute),
+			to:       startAt.Add(time.Minute),
+			eventIDs: []model.ID{},
+		},
+		{
+			name:     "reversed range",
+			from:     endAt,
+			to:       startAt,
+			eventIDs: []model.ID{},
+		},
+		{
+			name:     "unknown owner",
+			ownerID:  model.NewOwnerID(),
+			from:     minTime,
+			to:       maxTime,
+			eventIDs: []model.ID{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryOwnerID := ownerID
+			if tt.ownerID != "" {
+				queryOwnerID = tt.ownerID
+			}
+
+			events, err := s.QueryEvents(ctx, model.DefaultTenantID, queryOwnerID, tt.from, tt.to, model.Filter{})
+			require.NoError(t, err, "must not have error")
+			require.Equal(t, tt.eventIDs, eventIDs(events), "proper result")
+		})
+	}
+}
+
+// testPurgeBoundaries проверяет, что PurgeOldEvents удаляет события всех владельцев,
+// которые закончились строго раньше olderThan.
+func testPurgeBoundaries(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	olderThan := baseTime().Add(10 * time.Hour)
+
+	owners := []model.OwnerID{model.NewOwnerID(), model.NewOwnerID()}
+
+	var (
+		purged []model.ID
+		kept   = map[model.OwnerID][]model.ID{}
+	)
+
+	for _, ownerID := range owners {
+		ended := model.NewID()
+		mustAddEvent(t, s, MkEvent(
+			t, ended, ownerID, "ended",
+			olderThan.Add(-2*time.Hour), olderThan.Add(-time.Hour), 1,
+		))
+		purged = append(purged, ended)
+
+		endedJustBefore := model.NewID()
+		mustAddEvent(t, s, MkEvent(
+			t, endedJustBefore, ownerID, "ended just before",
+			olderThan.Add(-time.Hour), olderThan.Add(-time.Microsecond), 0,
+		))
+		purged = append(purged, endedJustBefore)
+
+		endsAt := model.NewID()
+		mustAddEvent(t, s, MkEvent(t, endsAt, ownerID, "ends at", olderThan.Add(-time.Microsecond), olderThan, 0))
+
+		inProgress := model.NewID()
+		mustAddEvent(t, s, MkEvent(t, inProgress, ownerID, "in progress", olderThan, olderThan.Add(time.Hour), 1))
+
+		future := model.NewID()
+		mustAddEvent(t, s, MkEvent(
+			t, future, ownerID, "future",
+			olderThan.Add(2*time.Hour), olderThan.Add(3*time.Hour), 1,
+		))
+
+		kept[ownerID] = []model.ID{endsAt, inProgress, future}
+	}
+
+	events, err := s.PurgeOldEvents(ctx, olderThan)
+	require.NoError(t, err, "must purge")
+	require.ElementsMatch(t, purged, eventIDs(events), "must return purged events")
+
+	for i, ownerID := range owners {
+		require.Equal(t, kept[ownerID], OwnerEventIDs(t, s, ownerID), "proper events for user #%d", i+1)
+	}
+
+	t.Run("purged events not found", func(t *testing.T) {
+		for i, eventID := range purged {
+			_, err := s.FindEvent(ctx, model.DefaultTenantID, owners[i/2], eventID)
+			require.ErrorIs(t, err, storage.ErrEventNotFound, "must be ErrEventNotFound error")
+		}
+	})
+
+	t.Run("purged events not notified", func(t *testing.T) {
+		events, err := s.QueryEventsToNotify(ctx, minTime, maxTime)
+		require.NoError(t, err, "must not have error")
+		require.Len(t, events, 4, "must have 2 events for each user")
+
+		for _, event := range events {
+			require.NotContains(t, purged, event.EventID(), "purged event must not be notified")
+		}
+	})
+
+	t.Run("purge again", func(t *testing.T) {
+		events, err := s.PurgeOldEvents(ctx, olderThan)
+		require.NoError(t, err, "must purge")
+		require.Empty(t, events, "nothing to purge")
+		require.Equal(t, kept[owners[0]], OwnerEventIDs(t, s, owners[0]), "must not change")
+	})
+
+	t.Run("purged time is free", func(t *testing.T) {
+		mustAddEvent(t, s, MkEvent(
+			t, model.NewID(), owners[0], "new",
+			olderThan.Add(-2*time.Hour), olderThan.Add(-time.Hour), 0,
+		))
+	})
+}
+
+// baseTime возвращает опорное время для тестов: начало следующего часа с точностью до микросекунд,
+// которую поддерживают все хранилища.
+func baseTime() time.Time {
+	return time.Now().Truncate(time.Hour).Add(time.Hour).UTC()
+}
+
+func mustAddEvent(t *testing.T, s storage.Storage, event model.Event) {
+	t.Helper()
+
+	require.NoErrorf(t, s.AddEvent(context.Background(), event), "must add event %s", event.Title)
+}
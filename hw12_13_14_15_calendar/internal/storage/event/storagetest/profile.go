@@ -0,0 +1,92 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// testProfiles проверяет сохранение профилей доступности владельцев.
+func testProfiles(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	ownerID := model.NewOwnerID()
+
+	tokyo, err := model.NewTimeZone("Asia/Tokyo")
+	require.NoError(t, err, "must load time zone")
+
+	profile := model.NewProfile(model.DefaultTenantID, ownerID)
+	profile.TimeZone = tokyo
+	profile.WorkingHours[time.Monday] = model.WorkingHours{Start: 9 * 60, End: 18 * 60}
+	profile.WorkingHours[time.Saturday] = model.WorkingHours{Start: 10 * 60, End: 24 * 60}
+	profile.Holidays = []model.Date{{Year: 2030, Month: time.January, Day: 1}, {Year: 2030, Month: time.May, Day: 9}}
+	profile.Check = model.AvailabilityCheckWarn
+	profile.HolidayCalendars = []model.HolidayCalendar{"de-by", "ru"}
+
+	requireProfile := func(t *testing.T, expected model.Profile, tenantID model.TenantID, ownerID model.OwnerID) {
+		t.Helper()
+
+		found, err := s.FindProfile(ctx, tenantID, ownerID)
+		require.NoError(t, err, "must find profile")
+		require.Equal(t, expected.TenantID(), found.TenantID(), "must keep tenant")
+		require.Equal(t, expected.OwnerID(), found.OwnerID(), "must keep owner")
+		require.Equal(t, expected.TimeZone.String(), found.TimeZone.String(), "must keep time zone")
+		require.Equal(t, expected.WorkingHours, found.WorkingHours, "must keep working hours")
+		require.Equal(t, expected.Holidays, found.Holidays, "must keep holidays")
+		require.Equal(t, expected.Check, found.Check, "must keep check")
+		require.Equal(t, expected.HolidayCalendars, found.HolidayCalendars, "must keep holiday calendars")
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := s.FindProfile(ctx, model.DefaultTenantID, ownerID)
+		require.ErrorIs(t, err, storage.ErrProfileNotFound, "must be ErrProfileNotFound error")
+	})
+
+	t.Run("save", func(t *testing.T) {
+		require.NoError(t, s.SaveProfile(ctx, profile), "must save profile")
+		requireProfile(t, profile, model.DefaultTenantID, ownerID)
+
+		profile.Holidays[0].Day = 2
+		found, err := s.FindProfile(ctx, model.DefaultTenantID, ownerID)
+		require.NoError(t, err, "must find profile")
+		require.Equal(t, 1, found.Holidays[0].Day, "must not share holidays with caller")
+		profile.Holidays[0].Day = 1
+
+		_, err = s.FindProfile(ctx, model.DefaultTenantID, model.NewOwnerID())
+		require.ErrorIs(t, err, storage.ErrProfileNotFound, "must not find profile of other owner")
+
+		_, err = s.FindProfile(ctx, "other", ownerID)
+		require.ErrorIs(t, err, storage.ErrProfileNotFound, "must not find profile in other tenant")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		replaced := model.NewProfile(model.DefaultTenantID, ownerID)
+		replaced.Check = model.AvailabilityCheckReject
+
+		require.NoError(t, s.SaveProfile(ctx, replaced), "must save profile")
+		requireProfile(t, replaced, model.DefaultTenantID, ownerID)
+	})
+
+	t.Run("wipe tenant", func(t *testing.T) {
+		tenantProfile := model.NewProfile("wiped", ownerID)
+		tenantProfile.Check = model.AvailabilityCheckWarn
+
+		require.NoError(t, s.SaveProfile(ctx, tenantProfile), "must save profile")
+		requireProfile(t, tenantProfile, "wiped", ownerID)
+
+		_, err := s.DeleteTenantEvents(ctx, "wiped")
+		require.NoError(t, err, "must wipe tenant")
+
+		_, err = s.FindProfile(ctx, "wiped", ownerID)
+		require.ErrorIs(t, err, storage.ErrProfileNotFound, "must delete profiles of tenant")
+
+		_, err = s.FindProfile(ctx, model.DefaultTenantID, ownerID)
+		require.NoError(t, err, "must keep profiles of other tenants")
+	})
+}
@@ -0,0 +1,539 @@
+// storagetest - набор тестов поведения для реализаций хранилища событий storage.Storage.
+//
+// Каждое хранилище должно проходить данный набор тестов:
+//
+//	storagetest.Run(t, func(t *testing.T) storage.Storage {
+//		return memory.NewStorage()
+//	})
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// Factory создаёт новое пустое хранилище для теста.
+// Освобождение ресурсов хранилища следует регистрировать через t.Cleanup.
+type Factory func(t *testing.T) storage.Storage
+
+// PopulateArgs - параметры событий, добавленных в хранилище через Populate.
+type PopulateArgs struct {
+	Now      time.Time
+	Times    [][2]time.Time
+	EventIDs [3]model.ID
+	OwnerIDs [3]model.OwnerID
+}
+
+var (
+	// minTime и maxTime - границы промежутка, в который попадают все события.
+	minTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	maxTime = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+)
+
+// Run запускает набор тестов для хранилищ, создаваемых через factory.
+func Run(t *testing.T, factory Factory) {
+	t.Helper()
+
+	t.Run("AddEvent", func(t *testing.T) { testAddEvent(t, factory(t)) })
+	t.Run("FindEvent", func(t *testing.T) { testFindEvent(t, factory(t)) })
+	t.Run("UpdateEvent", func(t *testing.T) { testUpdateEvent(t, factory(t)) })
+	t.Run("DeleteEvent", func(t *testing.T) { testDeleteEvent(t, factory(t)) })
+	t.Run("QueryEvents", func(t *testing.T) { testQueryEvents(t, factory(t)) })
+	t.Run("PurgeOldEvents", func(t *testing.T) { testPurgeOldEvents(t, factory(t)) })
+	t.Run("QueryEventsToNotify", func(t *testing.T) { testQueryEventsToNotify(t, factory(t)) })
+
+	t.Run("Overlap", func(t *testing.T) { testOverlap(t, factory(t)) })
+	t.Run("QueryBoundaries", func(t *testing.T) { testQueryBoundaries(t, factory(t)) })
+	t.Run("PurgeBoundaries", func(t *testing.T) { testPurgeBoundaries(t, factory(t)) })
+	t.Run("NotifyWindows", func(t *testing.T) { testNotifyWindows(t, factory(t)) })
+	t.Run("Tenants", func(t *testing.T) { testTenants(t, factory(t)) })
+	t.Run("Labels", func(t *testing.T) { testLabels(t, factory(t)) })
+	t.Run("Attachments", func(t *testing.T) { testAttachments(t, factory(t)) })
+	t.Run("Location", func(t *testing.T) { testLocation(t, factory(t)) })
+	t.Run("Profiles", func(t *testing.T) { testProfiles(t, factory(t)) })
+	t.Run("FeedTokens", func(t *testing.T) { testFeedTokens(t, factory(t)) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, factory(t)) })
+	t.Run("ContextCanceled", func(t *testing.T) { testContextCanceled(t, factory(t)) })
+}
+
+// MkEvent создаёт событие для теста в рабочем пространстве по умолчанию.
+func MkEvent(
+	t *testing.T,
+	eventID model.ID,
+	ownerID model.OwnerID,
+	title model.Title,
+	startAt time.Time,
+	endAt time.Time,
+	notifyBefore uint,
+) model.Event {
+	t.Helper()
+
+	return MkTenantEvent(t, model.DefaultTenantID, eventID, ownerID, title, startAt, endAt, notifyBefore)
+}
+
+// MkTenantEvent создаёт событие для теста в рабочем пространстве tenantID.
+func MkTenantEvent(
+	t *testing.T,
+	tenantID model.TenantID,
+	eventID model.ID,
+	ownerID model.OwnerID,
+	title model.Title,
+	startAt time.Time,
+	endAt time.Time,
+	notifyBefore uint,
+) model.Event {
+	t.Helper()
+
+	event, err := model.NewEvent(tenantID, eventID, ownerID, title, startAt, endAt)
+	require.NoErrorf(t, err, "must not have error while create an event %s", title)
+
+	event.NotifyBefore = notifyBefore
+
+	return event
+}
+
+// Populate добавляет в хранилище s набор событий для тестов.
+func Populate(t *testing.T, s storage.Storage) PopulateArgs {
+	t.Helper()
+
+	/*
+	*    ...... 1h ...... ... 1h ... ...... 1h ...... ... 1h ... ...... 1h ......
+	*    [now+1h, now+2h) .......... [now+3h, now+4h) .......... [now+5h, now+6h)
+	 */
+	now := time.Now().Truncate(time.Microsecond)
+	times := [][2]time.Time{
+		{now.Add(1 * time.Hour), now.Add(2 * time.Hour)},
+		{now.Add(3 * time.Hour), now.Add(4 * time.Hour)},
+		{now.Add(5 * time.Hour), now.Add(6 * time.Hour)},
+	}
+	eventIDs := [...]model.ID{model.NewID(), model.NewID(), model.NewID()}
+	ownerIDs := [...]model.OwnerID{model.NewOwnerID(), model.NewOwnerID(), model.NewOwnerID()}
+
+	tests := []struct {
+		name  string
+		event model.Event
+		err   error
+	}{
+		{
+			name:  "populate event#1 user#1",
+			event: MkEvent(t, eventIDs[0], ownerIDs[0], "1", times[1][0], times[1][1], 5),
+			err:   nil,
+		},
+		{
+			name:  "populate event#1 user#1 duplicate",
+			event: MkEvent(t, eventIDs[0], ownerIDs[0], "1", times[1][0], times[1][1], 5),
+			err:   storage.ErrEventAlreadyExists,
+		},
+		{
+			name:  "populate event#2 user#1 time overlap",
+			event: MkEvent(t, eventIDs[1], ownerIDs[0], "2", times[1][0], times[1][1], 5),
+			err:   storage.ErrTimeIsBusy,
+		},
+		{
+			name:  "populate event#2 user#1",
+			event: MkEvent(t, eventIDs[1], ownerIDs[0], "2", times[0][0], times[0][1], 0),
+			err:   nil,
+		},
+		{
+			name:  "populate event#1 user#3",
+			event: MkEvent(t, eventIDs[0], ownerIDs[2], "1", times[1][0], times[1][1], 5),
+			err:   nil,
+		},
+		{
+			name:  "populate event#2 user#3",
+			event: MkEvent(t, eventIDs[1], ownerIDs[2], "2", times[0][0], times[0][1], 0),
+			err:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.AddEvent(context.Background(), tt.event)
+			if tt.err == nil {
+				require.NoError(t, err, "must not have error")
+			} else {
+				require.Error(t, err, "must have error")
+				require.ErrorIsf(t, err, tt.err, "error must be %v", tt.err)
+			}
+		})
+	}
+
+	return PopulateArgs{
+		Now:      now,
+		Times:    times,
+		EventIDs: eventIDs,
+		OwnerIDs: ownerIDs,
+	}
+}
+
+// OwnerEventIDs возвращает идентификаторы всех событий владельца ownerID в порядке их следования.
+func OwnerEventIDs(t *testing.T, s storage.Storage, ownerID model.OwnerID) []model.ID {
+	t.Helper()
+
+	events, err := s.QueryEvents(context.Background(), model.DefaultTenantID, ownerID, minTime, maxTime, model.Filter{})
+	require.NoError(t, err, "must not have error")
+
+	return eventIDs(events)
+}
+
+func eventIDs(events []model.Event) []model.ID {
+	ids := []model.ID{}
+	for _, e := range events {
+		ids = append(ids, e.EventID())
+	}
+
+	return ids
+}
+
+func testAddEvent(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	args := Populate(t, s)
+
+	t.Run("proper order", func(t *testing.T) {
+		require.Equal(
+			t,
+			[]model.ID{args.EventIDs[1], args.EventIDs[0]},
+			OwnerEventIDs(t, s, args.OwnerIDs[0]),
+			"event #2 must be at 0, event #1 must be at 1",
+		)
+	})
+
+	t.Run("other owner", func(t *testing.T) {
+		require.Len(t, OwnerEventIDs(t, s, args.OwnerIDs[1]), 0, "must have no events for user #2")
+		require.Len(t, OwnerEventIDs(t, s, args.OwnerIDs[2]), 2, "must have 2 events for user #3")
+	})
+
+	t.Run("stored as is", func(t *testing.T) {
+		event := MkEvent(t, model.NewID(), args.OwnerIDs[1], "title", args.Times[2][0], args.Times[2][1], 3)
+		event.Description = "description"
+
+		err := s.AddEvent(context.Background(), event)
+		require.NoError(t, err, "must not have error")
+
+		found, err := s.FindEvent(context.Background(), model.DefaultTenantID, event.OwnerID(), event.EventID())
+		require.NoError(t, err, "must not have error")
+
+		require.Equal(t, event.Title, found.Title, "title must be equal")
+		require.Equal(t, event.Description, found.Description, "description must be equal")
+		require.Equal(t, event.NotifyBefore, found.NotifyBefore, "notifyBefore must be equal")
+		require.True(t, event.StartAt().Equal(found.StartAt()), "startAt must be equal")
+		require.True(t, event.EndAt().Equal(found.EndAt()), "endAt must be equal")
+	})
+}
+
+func testFindEvent(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	args := Populate(t, s)
+
+	type findArgs struct {
+		ownerID model.OwnerID
+		eventID model.ID
+	}
+	tests := []struct {
+		name string
+		args findArgs
+		err  error
+	}{
+		{
+			name: "user#1 event#1",
+			args: findArgs{args.OwnerIDs[0], args.EventIDs[0]},
+			err:  nil,
+		},
+		{
+			name: "user#1 event#2",
+			args: findArgs{args.OwnerIDs[0], args.EventIDs[1]},
+			err:  nil,
+		},
+		{
+			name: "user#1 event#3",
+			args: findArgs{args.OwnerIDs[0], args.EventIDs[2]},
+			err:  storage.ErrEventNotFound,
+		},
+		{
+			name: "user#2 event#1",
+			args: findArgs{args.OwnerIDs[1], args.EventIDs[0]},
+			err:  storage.ErrEventNotFound,
+		},
+		{
+			name: "user#3 event#1",
+			args: findArgs{args.OwnerIDs[2], args.EventIDs[0]},
+			err:  nil,
+		},
+		{
+			name: "user#3 event#2",
+			args: findArgs{args.OwnerIDs[2], args.EventIDs[1]},
+			err:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := s.FindEvent(context.Background(), model.DefaultTenantID, tt.args.ownerID, tt.args.eventID)
+
+			if tt.err == nil {
+				require.NoError(t, err, "must not have error")
+				require.EqualValues(t, tt.args.ownerID, event.OwnerID(), "ownerID must be equal")
+				require.EqualValues(t, tt.args.eventID, event.EventID(), "eventID must be equal")
+			} else {
+				require.Error(t, err, "must have error")
+				require.ErrorIsf(t, err, tt.err, "error must be %v", tt.err)
+			}
+		})
+	}
+}
+
+func testUpdateEvent(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	args := Populate(t, s)
+
+	t.Run("success", func(t *testing.T) {
+		event := MkEvent(t, args.EventIDs[1], args.OwnerIDs[0], "2", args.Times[2][0], args.Times[2][1], 0)
+
+		err := s.UpdateEvent(context.Background(), event)
+		require.NoError(t, err, "must not have error")
+
+		require.Equal(
+			t,
+			[]model.ID{args.EventIDs[0], args.EventIDs[1]},
+			OwnerEventIDs(t, s, args.OwnerIDs[0]),
+			"event #1 must be at 0, event #2 must be at 1",
+		)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		event := MkEvent(t, args.EventIDs[2], args.OwnerIDs[0], "2", args.Times[2][0], args.Times[2][1], 0)
+
+		err := s.UpdateEvent(context.Background(), event)
+		require.Error(t, err, "must have error")
+		require.ErrorIs(t, err, storage.ErrEventNotFound, "must be ErrEventNotFound error")
+	})
+
+	t.Run("time is busy", func(t *testing.T) {
+		event := MkEvent(t, args.EventIDs[1], args.OwnerIDs[0], "2", args.Times[1][0], args.Times[1][1], 0)
+
+		err := s.UpdateEvent(context.Background(), event)
+		require.Error(t, err, "must have error")
+		require.ErrorIs(t, err, storage.ErrTimeIsBusy, "must be ErrTimeIsBusy error")
+	})
+
+	t.Run("order not changed", func(t *testing.T) {
+		require.Equal(
+			t,
+			[]model.ID{args.EventIDs[0], args.EventIDs[1]},
+			OwnerEventIDs(t, s, args.OwnerIDs[0]),
+			"event #1 must be at 0, event #2 must be at 1",
+		)
+	})
+}
+
+func testDeleteEvent(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	args := Populate(t, s)
+
+	t.Run("no event for unknown user", func(t *testing.T) {
+		err := s.DeleteEvent(context.Background(), model.DefaultTenantID, model.NewOwnerID(), model.NewID())
+		require.Error(t, err, "must have error")
+		require.ErrorIs(t, err, storage.ErrEventNotFound, "must be ErrEventNotFound error")
+	})
+
+	t.Run("no event for user", func(t *testing.T) {
+		err := s.DeleteEvent(context.Background(), model.DefaultTenantID, args.OwnerIDs[0], model.NewID())
+		require.Error(t, err, "must have error")
+		require.ErrorIs(t, err, storage.ErrEventNotFound, "must be ErrEventNotFound error")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		err := s.DeleteEvent(context.Background(), model.DefaultTenantID, args.OwnerIDs[0], args.EventIDs[0])
+		require.NoError(t, err, "must not have error")
+
+		require.Equal(
+			t,
+			[]model.ID{args.EventIDs[1]},
+			OwnerEventIDs(t, s, args.OwnerIDs[0]),
+			"event #2 must be at 0",
+		)
+	})
+}
+
+func testQueryEvents(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	args := Populate(t, s)
+
+	/*
+	         [=============]
+	  [---]                   [---]    before-after
+	  [------]             [------]    before edge-after edge
+	  [----------]     [----------]    before overlap startAt-after overlap endAt
+	         [---]     [---]           startAt inside-inside entAt
+	         [-------------]           equal
+	         [--------------------]    startAt overlap endAt
+	  [--------------------]           overlap startAt endAt
+	*/
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		evendIDs []model.ID
+	}{
+		{
+			name:     "before 1",
+			from:     args.Times[0][0].Add(-time.Minute),
+			to:       args.Times[0][0].Add(-time.Second),
+			evendIDs: []model.ID{},
+		},
+		{
+			name:     "after 1",
+			from:     args.Times[0][1].Add(time.Second),
+			to:       args.Times[0][1].Add(time.Minute),
+			evendIDs: []model.ID{},
+		},
+		{
+			name:     "before edge 1",
+			from:     args.Times[0][0].Add(-time.Second),
+			to:       args.Times[0][0],
+			evendIDs: []model.ID{},
+		},
+		{
+			name:     "after edge 1",
+			from:     args.Times[0][1],
+			to:       args.Times[0][1].Add(time.Second),
+			evendIDs: []model.ID{},
+		},
+		{
+			name:     "before overlap startAt 1",
+			from:     args.Times[0][0].Add(-time.Second),
+			to:       args.Times[0][0].Add(time.Second),
+			evendIDs: []model.ID{args.EventIDs[1]},
+		},
+		{
+			name:     "after overlap endAt 1",
+			from:     args.Times[0][1].Add(-time.Second),
+			to:       args.Times[0][1].Add(time.Second),
+			evendIDs: []model.ID{args.EventIDs[1]},
+		},
+		{
+			name:     "startAt inside 1",
+			from:     args.Times[0][0],
+			to:       args.Times[0][0].Add(time.Second),
+			evendIDs: []model.ID{args.EventIDs[1]},
+		},
+		{
+			name:     "inside endAt 1",
+			from:     args.Times[0][1].Add(-time.Second),
+			to:       args.Times[0][1],
+			evendIDs: []model.ID{args.EventIDs[1]},
+		},
+		{
+			name:     "equal 1",
+			from:     args.Times[0][0],
+			to:       args.Times[0][1],
+			evendIDs: []model.ID{args.EventIDs[1]},
+		},
+		{
+			name:     "startAt overlap endAt 1",
+			from:     args.Times[0][0],
+			to:       args.Times[0][1].Add(time.Second),
+			evendIDs: []model.ID{args.EventIDs[1]},
+		},
+		{
+			name:     "overlap startAt endAt 1",
+			from:     args.Times[0][0].Add(-time.Second),
+			to:       args.Times[0][1],
+			evendIDs: []model.ID{args.EventIDs[1]},
+		},
+		{
+			name:     "first+second overlap",
+			from:     args.Times[0][0].Add(-time.Second),
+			to:       args.Times[1][0].Add(time.Second),
+			evendIDs: []model.ID{args.EventIDs[1], args.EventIDs[0]},
+		},
+		{
+			name:     "first+second edge",
+			from:     args.Times[0][1],
+			to:       args.Times[1][0],
+			evendIDs: []model.ID{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := s.QueryEvents(
+				context.Background(),
+				model.DefaultTenantID,
+				args.OwnerIDs[0],
+				tt.from,
+				tt.to,
+				model.Filter{},
+			)
+			require.NoError(t, err, "must not have error")
+
+			require.Equal(t, tt.evendIDs, eventIDs(events), "proper result")
+		})
+	}
+}
+
+func testPurgeOldEvents(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	args := Populate(t, s)
+
+	events, err := s.PurgeOldEvents(context.Background(), args.Times[1][0].Truncate(time.Second))
+	require.NoError(t, err, "must not have arror")
+	require.Len(t, events, 2, "must return purged events")
+
+	count := 0
+	for _, ownerID := range args.OwnerIDs {
+		count += len(OwnerEventIDs(t, s, ownerID))
+	}
+	require.Equal(t, 2, count, "must be proper value")
+}
+
+func testQueryEventsToNotify(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	args := Populate(t, s)
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		evendIDs []model.ID
+	}{
+		{
+			name: "need notify",
+			from: args.Times[1][0].Truncate(time.Second).Add(-5 * 24 * time.Hour),
+			to:   args.Times[1][0].Truncate(time.Second).Add(-5*24*time.Hour + time.Hour),
+			evendIDs: []model.ID{
+				args.EventIDs[0],
+				args.EventIDs[0],
+			},
+		},
+		{
+			name:     "no need notify",
+			from:     args.Times[0][0].Truncate(time.Second).Add(-5 * 24 * time.Hour),
+			to:       args.Times[0][0].Truncate(time.Second).Add(-5*24*time.Hour + time.Hour),
+			evendIDs: []model.ID{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := s.QueryEventsToNotify(context.Background(), tt.from, tt.to)
+			require.NoError(t, err, "must not have arror")
+
+			require.Equal(t, tt.evendIDs, eventIDs(events), "proper result")
+		})
+	}
+}
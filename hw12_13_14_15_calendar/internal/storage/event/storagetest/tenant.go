@@ -0,0 +1,136 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+	storage "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/storage/event"
+)
+
+// testTenants проверяет изоляцию рабочих пространств: одинаковые владельцы и идентификаторы событий
+// в разных пространствах не пересекаются, а операции над пространством не затрагивают другие пространства.
+func testTenants(t *testing.T, s storage.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	day := 24 * time.Hour
+
+	startAt := baseTime().Add(10 * day)
+	tenants := []model.TenantID{"hr", "sales"}
+
+	owners := []model.OwnerID{model.NewOwnerID(), model.NewOwnerID()}
+	if owners[1] < owners[0] {
+		owners[0], owners[1] = owners[1], owners[0]
+	}
+
+	ids := []model.ID{model.NewID(), model.NewID()}
+
+	// одно и то же событие владельца в обоих пространствах: ни дубликата, ни пересечения времени
+	for _, tenantID := range tenants {
+		mustAddEvent(t, s, MkTenantEvent(t, tenantID, ids[0], owners[0], "1", startAt, startAt.Add(time.Hour), 1))
+	}
+
+	mustAddEvent(t, s, MkTenantEvent(t, "hr", ids[1], owners[0], "2", startAt.Add(-day), startAt.Add(-day+time.Hour), 0))
+	mustAddEvent(t, s, MkTenantEvent(t, "hr", ids[1], owners[1], "3", startAt, startAt.Add(time.Hour), 0))
+
+	t.Run("find", func(t *testing.T) {
+		found, err := s.FindEvent(ctx, "sales", owners[0], ids[0])
+		require.NoError(t, err, "must find event in tenant")
+		require.Equal(t, model.TenantID("sales"), found.TenantID(), "must keep tenant")
+
+		_, err = s.FindEvent(ctx, "sales", owners[0], ids[1])
+		require.ErrorIs(t, err, storage.ErrEventNotFound, "must not find event of other tenant")
+
+		_, err = s.FindEvent(ctx, model.DefaultTenantID, owners[0], ids[0])
+		require.ErrorIs(t, err, storage.ErrEventNotFound, "must not find event in unknown tenant")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		events, err := s.QueryEvents(ctx, "hr", owners[0], minTime, maxTime, model.Filter{})
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.ID{ids[1], ids[0]}, eventIDs(events), "must query events of tenant")
+
+		events, err = s.QueryEvents(ctx, "sales", owners[0], minTime, maxTime, model.Filter{})
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.ID{ids[0]}, eventIDs(events), "must query events of tenant")
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		event := MkTenantEvent(t, "sales", ids[0], owners[0], "updated", startAt, startAt.Add(time.Hour), 1)
+		require.NoError(t, s.UpdateEvent(ctx, event), "must update event in tenant")
+
+		found, err := s.FindEvent(ctx, "hr", owners[0], ids[0])
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, model.Title("1"), found.Title, "must not update event of other tenant")
+
+		event = MkTenantEvent(t, "sales", ids[1], owners[0], "2", startAt.Add(-day), startAt.Add(-day+time.Hour), 0)
+		require.ErrorIs(t, s.UpdateEvent(ctx, event), storage.ErrEventNotFound, "must not update event of other tenant")
+
+		err = s.DeleteEvent(ctx, "sales", owners[1], ids[1])
+		require.ErrorIs(t, err, storage.ErrEventNotFound, "must not delete event of other tenant")
+	})
+
+	t.Run("count", func(t *testing.T) {
+		for tenantID, count := range map[model.TenantID]int{"hr": 3, "sales": 1, model.DefaultTenantID: 0} {
+			n, err := s.CountTenantEvents(ctx, tenantID)
+			require.NoError(t, err, "must not have error")
+			require.Equalf(t, count, n, "must count events of tenant %s", tenantID)
+		}
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		events, err := s.QueryEventsToNotify(ctx, startAt.Add(-day), startAt.Add(-day+time.Hour))
+		require.NoError(t, err, "must not have error")
+		require.Len(t, events, 2, "must notify about events of all tenants")
+		require.ElementsMatch(
+			t,
+			[]model.TenantID{"hr", "sales"},
+			[]model.TenantID{events[0].TenantID(), events[1].TenantID()},
+			"must keep tenant of events",
+		)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		events, err := s.QueryTenantEvents(ctx, "hr")
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, []model.ID{ids[1], ids[0], ids[1]}, eventIDs(events), "ordered by owner and startAt")
+		require.Equal(
+			t,
+			[]model.OwnerID{owners[0], owners[0], owners[1]},
+			[]model.OwnerID{events[0].OwnerID(), events[1].OwnerID(), events[2].OwnerID()},
+			"ordered by owner",
+		)
+
+		events, err = s.QueryTenantEvents(ctx, model.DefaultTenantID)
+		require.NoError(t, err, "must not have error")
+		require.Empty(t, events, "unknown tenant has no events")
+	})
+
+	t.Run("wipe", func(t *testing.T) {
+		n, err := s.DeleteTenantEvents(ctx, "hr")
+		require.NoError(t, err, "must not have error")
+		require.Equal(t, 3, n, "must delete all events of tenant")
+
+		n, err = s.CountTenantEvents(ctx, "hr")
+		require.NoError(t, err, "must not have error")
+		require.Zero(t, n, "tenant must be empty")
+
+		_, err = s.FindEvent(ctx, "sales", owners[0], ids[0])
+		require.NoError(t, err, "must keep events of other tenant")
+
+		events, err := s.QueryEventsToNotify(ctx, startAt.Add(-day), startAt.Add(-day+time.Hour))
+		require.NoError(t, err, "must not have error")
+		require.Len(t, events, 1, "must not notify about wiped events")
+
+		n, err = s.DeleteTenantEvents(ctx, "hr")
+		require.NoError(t, err, "must not have error")
+		require.Zero(t, n, "nothing to delete")
+
+		// пространство снова можно наполнять
+		mustAddEvent(t, s, MkTenantEvent(t, "hr", ids[0], owners[0], "1", startAt, startAt.Add(time.Hour), 1))
+	})
+}
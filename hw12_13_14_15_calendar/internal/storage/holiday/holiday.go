@@ -0,0 +1,432 @@
+// storage/holiday - календари праздников, загруженные из файлов iCalendar (*.ics) директории.
+//
+// Каждый файл <name>.ics - отдельный календарь праздников с именем name, праздники - события VEVENT
+// на весь день (DTSTART;VALUE=DATE), в том числе ежегодные (RRULE:FREQ=YEARLY).
+// Store периодически проверяет время изменения файлов (Watch) и перечитывает директорию
+// без перезапуска сервиса.
+package holiday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/filewatch"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/ical"
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// DefaultWatchInterval - как часто проверять изменение файлов календарей по умолчанию.
+const DefaultWatchInterval = time.Minute
+
+// FileExt - расширение файлов календарей праздников.
+const FileExt = ".ics"
+
+// maxDays - допустимая продолжительность одного праздника в днях.
+const maxDays = 366
+
+var (
+	ErrNoStart         = errors.New("holiday has no DTSTART")
+	ErrInvalidDuration = errors.New("invalid holiday duration")
+	ErrUnsupportedRule = errors.New("unsupported RRULE, only FREQ=YEARLY with COUNT or UNTIL is supported")
+)
+
+// Store хранит календари праздников, прочитанные из директории dir.
+type Store struct {
+	dir    string
+	logger *slog.Logger
+
+	mx        sync.RWMutex
+	calendars map[model.HolidayCalendar]calendar
+	modTimes  filewatch.ModTimes
+}
+
+// calendar - календарь праздников.
+type calendar struct {
+	title   string
+	entries []entry
+}
+
+// entry - праздник календаря: days дней, начиная со start (полночь в UTC).
+type entry struct {
+	start time.Time
+	days  int
+	title string
+
+	// yearly - праздник повторяется ежегодно, не более count раз (0 - без ограничения)
+	// и не позже until (нулевое значение - без ограничения).
+	yearly bool
+	count  int
+	until  time.Time
+}
+
+// NewStore создаёт Store и читает календари праздников из директории dir.
+// Возвращает ошибку, если директорию или один из файлов не удалось прочитать.
+func NewStore(logger *slog.Logger, dir string) (*Store, error) {
+	s := &Store{
+		dir:    dir,
+		logger: logger,
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Calendars возвращает доступные календари праздников, упорядоченные по имени.
+func (s *Store) Calendars() []model.HolidayCalendarInfo {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	result := make([]model.HolidayCalendarInfo, 0, len(s.calendars))
+	for name, c := range s.calendars {
+		result = append(result, model.HolidayCalendarInfo{Name: name, Title: c.title})
+	}
+
+	slices.SortFunc(result, func(a, b model.HolidayCalendarInfo) int {
+		return strings.Compare(string(a.Name), string(b.Name))
+	})
+
+	return result
+}
+
+// HasCalendar сообщает, что календарь праздников name доступен.
+func (s *Store) HasCalendar(name model.HolidayCalendar) bool {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	_, ok := s.calendars[name]
+
+	return ok
+}
+
+// Holidays возвращает праздники календарей calendars, приходящиеся на дни промежутка [from, to),
+// упорядоченные по дате и имени календаря. Недоступные календари пропускаются.
+func (s *Store) Holidays(calendars []model.HolidayCalendar, from time.Time, to time.Time) []model.Holiday {
+	from = midnight(from)
+
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	var result []model.Holiday
+	for _, name := range calendars {
+		c, ok := s.calendars[name]
+		if !ok {
+			continue
+		}
+
+		for _, e := range c.entries {
+			result = e.appendHolidays(result, name, from, to)
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b model.Holiday) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+
+		return strings.Compare(string(a.Calendar), string(b.Calendar))
+	})
+
+	return result
+}
+
+// Reload перечитывает директорию, если файлы календарей изменились, появились или удалены.
+// При ошибке остаются прежние календари.
+func (s *Store) Reload() (bool, error) {
+	modTimes, err := s.readModTimes(false)
+	if err != nil {
+		return false, err
+	}
+
+	s.mx.RLock()
+	changed := !s.modTimes.Equal(modTimes)
+	s.mx.RUnlock()
+
+	if !changed {
+		return false, nil
+	}
+
+	if err := s.load(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Watch проверяет изменение файлов раз в interval по часам clk и перечитывает их.
+// Завершается при отмене контекста ctx.
+func (s *Store) Watch(ctx context.Context, clk clock.Clock, interval time.Duration) {
+	filewatch.Watch(ctx, clk, interval, s.Reload, func(reloaded bool, err error) {
+		if err != nil {
+			s.logger.Error("can't reload holiday calendars", slog.String("error", err.Error()))
+			return
+		}
+
+		if reloaded {
+			s.logger.Info(
+				"holiday calendars reloaded",
+				slog.String("dir", s.dir),
+				slog.Int("calendars", len(s.Calendars())),
+			)
+		}
+	})
+}
+
+// load читает все календари праздников директории.
+func (s *Store) load() error {
+	modTimes, err := s.readModTimes(true)
+	if err != nil {
+		return err
+	}
+
+	calendars := make(map[model.HolidayCalendar]calendar, len(modTimes))
+	for file := range modTimes {
+		name := model.HolidayCalendar(strings.TrimSuffix(filepath.Base(file), FileExt))
+
+		c, err := readCalendar(file)
+		if err != nil {
+			return fmt.Errorf("can't read holiday calendar '%s': %w", name, err)
+		}
+
+		calendars[name] = c
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.calendars = calendars
+	s.modTimes = modTimes
+
+	return nil
+}
+
+// readModTimes возвращает время изменения файлов календарей директории.
+// Файлы, имена которых не подходят для календаря праздников, пропускаются (с предупреждением, если warn).
+func (s *Store) readModTimes(warn bool) (filewatch.ModTimes, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't read holiday calendars dir: %w", err)
+	}
+
+	var files []string
+	for _, de := range dirEntries {
+		name, ok := strings.CutSuffix(de.Name(), FileExt)
+		if !ok || de.IsDir() {
+			continue
+		}
+
+		if _, err := model.NewHolidayCalendar(name); err != nil {
+			if warn {
+				s.logger.Warn(
+					"skip holiday calendar file",
+					slog.String("file", de.Name()),
+					slog.String("error", err.Error()),
+				)
+			}
+
+			continue
+		}
+
+		files = append(files, filepath.Join(s.dir, de.Name()))
+	}
+
+	return filewatch.Stat(files...)
+}
+
+// readCalendar читает календарь праздников из файла file.
+func readCalendar(file string) (calendar, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return calendar{}, err
+	}
+	defer f.Close()
+
+	root, err := ical.Parse(f)
+	if err != nil {
+		return calendar{}, err
+	}
+
+	c := calendar{title: root.Text("X-WR-CALNAME")}
+	for _, vevent := range root.Components("VEVENT") {
+		if strings.EqualFold(vevent.Text("STATUS"), "CANCELLED") {
+			continue
+		}
+
+		e, err := parseEntry(vevent)
+		if err != nil {
+			return calendar{}, fmt.Errorf("VEVENT '%s': %w", vevent.Text("UID"), err)
+		}
+
+		c.entries = append(c.entries, e)
+	}
+
+	return c, nil
+}
+
+// parseEntry возвращает праздник события vevent.
+// Для DTSTART и DTEND со временем учитывается только дата.
+func parseEntry(vevent *ical.Component) (entry, error) {
+	e := entry{title: vevent.Text("SUMMARY"), days: 1}
+
+	start, ok := vevent.Prop("DTSTART")
+	if !ok {
+		return entry{}, ErrNoStart
+	}
+
+	var err error
+
+	e.start, err = parseDay(start.Value)
+	if err != nil {
+		return entry{}, err
+	}
+
+	if end, ok := vevent.Prop("DTEND"); ok {
+		endDay, err := parseDay(end.Value)
+		if err != nil {
+			return entry{}, err
+		}
+
+		// DTEND не входит в праздник, если это дата или полночь, иначе праздник идёт и в день DTEND
+		e.days = int(endDay.Sub(e.start).Hours() / 24)
+		if !isMidnight(end.Value) {
+			e.days++
+		}
+
+		if e.days < 0 || e.days > maxDays {
+			return entry{}, fmt.Errorf("%w: %d days", ErrInvalidDuration, e.days)
+		}
+
+		e.days = max(e.days, 1)
+	}
+
+	if rrule, ok := vevent.Prop("RRULE"); ok {
+		err = e.parseRule(rrule.Value)
+		if err != nil {
+			return entry{}, err
+		}
+	}
+
+	return e, nil
+}
+
+// parseRule разбирает правило повторения RRULE: поддерживается только FREQ=YEARLY с COUNT или UNTIL.
+func (e *entry) parseRule(rule string) error {
+	for _, part := range strings.Split(rule, ";") {
+		name, value, _ := strings.Cut(part, "=")
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			if !strings.EqualFold(value, "YEARLY") {
+				return fmt.Errorf("%w: '%s'", ErrUnsupportedRule, rule)
+			}
+
+			e.yearly = true
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count <= 0 {
+				return fmt.Errorf("%w: '%s'", ErrUnsupportedRule, rule)
+			}
+
+			e.count = count
+		case "UNTIL":
+			until, err := parseDay(value)
+			if err != nil {
+				return err
+			}
+
+			e.until = until
+		case "INTERVAL":
+			if value != "1" {
+				return fmt.Errorf("%w: '%s'", ErrUnsupportedRule, rule)
+			}
+		default:
+			return fmt.Errorf("%w: '%s'", ErrUnsupportedRule, rule)
+		}
+	}
+
+	if !e.yearly {
+		return fmt.Errorf("%w: '%s'", ErrUnsupportedRule, rule)
+	}
+
+	return nil
+}
+
+// appendHolidays добавляет к holidays дни праздника календаря name, приходящиеся на промежуток [from, to).
+func (e entry) appendHolidays(
+	holidays []model.Holiday,
+	name model.HolidayCalendar,
+	from time.Time,
+	to time.Time,
+) []model.Holiday {
+	appendDays := func(start time.Time) {
+		for i := range e.days {
+			day := start.AddDate(0, 0, i)
+			if !day.Before(from) && day.Before(to) {
+				holidays = append(holidays, model.Holiday{Calendar: name, Date: model.DateOf(day), Title: e.title})
+			}
+		}
+	}
+
+	if !e.yearly {
+		appendDays(e.start)
+		return holidays
+	}
+
+	// праздник, начавшийся в прошлом году, может продолжаться в промежутке
+	for year := max(e.start.Year(), from.Year()-1); year <= to.Year(); year++ {
+		if e.count > 0 && year-e.start.Year() >= e.count {
+			break
+		}
+
+		start := time.Date(year, e.start.Month(), e.start.Day(), 0, 0, 0, 0, time.UTC)
+		if start.Day() != e.start.Day() {
+			// 29 февраля в невисокосный год
+			continue
+		}
+
+		if !e.until.IsZero() && start.After(e.until) {
+			break
+		}
+
+		appendDays(start)
+	}
+
+	return holidays
+}
+
+// isMidnight сообщает, что значение DATE или DATE-TIME приходится на начало суток.
+func isMidnight(value string) bool {
+	return len(value) == len("20060102") || strings.HasPrefix(value[len("20060102"):], "T000000")
+}
+
+// parseDay разбирает значение DATE (YYYYMMDD) или DATE-TIME (YYYYMMDDTHHMMSS[Z]), возвращает полночь даты в UTC.
+func parseDay(value string) (time.Time, error) {
+	if len(value) > len("20060102") {
+		if value[len("20060102")] != 'T' {
+			return time.Time{}, fmt.Errorf("%w: '%s'", ical.ErrInvalidDate, value)
+		}
+
+		value = value[:len("20060102")]
+	}
+
+	return ical.ParseDate(value)
+}
+
+// midnight возвращает полночь даты t в UTC.
+func midnight(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
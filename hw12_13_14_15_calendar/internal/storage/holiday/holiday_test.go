@@ -0,0 +1,170 @@
+package holiday
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/model/event"
+)
+
+// calendarData возвращает календарь с названием title и событиями vevents (строки свойств через '\n').
+func calendarData(title string, vevents ...string) []byte {
+	var b strings.Builder
+
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n")
+	if title != "" {
+		b.WriteString("X-WR-CALNAME:" + title + "\r\n")
+	}
+
+	for _, vevent := range vevents {
+		b.WriteString("BEGIN:VEVENT\r\n")
+		b.WriteString(strings.ReplaceAll(vevent, "\n", "\r\n") + "\r\n")
+		b.WriteString("END:VEVENT\r\n")
+	}
+
+	b.WriteString("END:VCALENDAR\r\n")
+
+	return []byte(b.String())
+}
+
+// writeFile записывает data в файл name и сдвигает время изменения на modTime.
+func writeFile(t *testing.T, name string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(name, data, 0o600), "must write file")
+	require.NoError(t, os.Chtimes(name, modTime, modTime), "must set mod time")
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStore_Holidays(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Hour)
+
+	writeFile(t, filepath.Join(dir, "ru.ics"), calendarData(
+		"Russia",
+		"UID:1\nDTSTART;VALUE=DATE:20300101\nDTEND;VALUE=DATE:20300104\nSUMMARY:Новогодние каникулы",
+		"UID:2\nDTSTART;VALUE=DATE:20290223\nRRULE:FREQ=YEARLY\nSUMMARY:День защитника Отечества",
+		"UID:3\nDTSTART;VALUE=DATE:20300308\nSUMMARY:Cancelled\nSTATUS:CANCELLED",
+	), modTime)
+	writeFile(t, filepath.Join(dir, "de-by.ics"), calendarData(
+		"",
+		"UID:1\nDTSTART:20300101T000000\nSUMMARY:Neujahr",
+		"UID:2\nDTSTART;VALUE=DATE:20280229\nRRULE:FREQ=YEARLY;UNTIL=20320301\nSUMMARY:Leap",
+	), modTime)
+	writeFile(t, filepath.Join(dir, "bad name.ics"), calendarData(""), modTime)
+	writeFile(t, filepath.Join(dir, "notes.txt"), []byte("not a calendar"), modTime)
+
+	s, err := NewStore(discardLogger(), dir)
+	require.NoError(t, err, "must create store")
+
+	require.Equal(t, []model.HolidayCalendarInfo{
+		{Name: "de-by"},
+		{Name: "ru", Title: "Russia"},
+	}, s.Calendars(), "must list calendars by name")
+	require.True(t, s.HasCalendar("ru"))
+	require.False(t, s.HasCalendar("bad name"))
+
+	holidays := s.Holidays([]model.HolidayCalendar{"ru", "de-by", "fr"}, date(2030, 1, 1), date(2030, 1, 3))
+	require.Equal(t, []model.Holiday{
+		{Calendar: "de-by", Date: model.Date{Year: 2030, Month: time.January, Day: 1}, Title: "Neujahr"},
+		{Calendar: "ru", Date: model.Date{Year: 2030, Month: time.January, Day: 1}, Title: "Новогодние каникулы"},
+		{Calendar: "ru", Date: model.Date{Year: 2030, Month: time.January, Day: 2}, Title: "Новогодние каникулы"},
+	}, holidays, "must return holidays of period ordered by date and calendar")
+
+	holidays = s.Holidays([]model.HolidayCalendar{"ru"}, date(2030, 2, 1), date(2030, 4, 1))
+	require.Len(t, holidays, 1, "must repeat yearly holiday and skip cancelled")
+	require.Equal(t, model.Date{Year: 2030, Month: time.February, Day: 23}, holidays[0].Date)
+
+	require.Empty(t, s.Holidays([]model.HolidayCalendar{"ru"}, date(2028, 2, 1), date(2028, 3, 1)),
+		"must not repeat before start")
+
+	leap := func(year int) []model.Holiday {
+		return s.Holidays([]model.HolidayCalendar{"de-by"}, date(year, 2, 1), date(year, 3, 1))
+	}
+	require.Len(t, leap(2028), 1)
+	require.Empty(t, leap(2030), "must skip February 29 in non-leap year")
+	require.Len(t, leap(2032), 1)
+	require.Empty(t, leap(2036), "must stop after UNTIL")
+
+	require.Empty(t, s.Holidays(nil, date(2030, 1, 1), date(2031, 1, 1)), "must not have holidays without calendars")
+}
+
+func TestStore_Reload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "ru.ics")
+	modTime := time.Now().Add(-time.Hour)
+
+	writeFile(t, file, calendarData("", "DTSTART;VALUE=DATE:20300101\nSUMMARY:Old"), modTime)
+
+	s, err := NewStore(discardLogger(), dir)
+	require.NoError(t, err, "must create store")
+
+	reloaded, err := s.Reload()
+	require.NoError(t, err, "must not have error")
+	require.False(t, reloaded, "must not reload unchanged files")
+
+	writeFile(t, file, calendarData("", "DTSTART;VALUE=DATE:20300101\nSUMMARY:New"), modTime.Add(time.Minute))
+
+	reloaded, err = s.Reload()
+	require.NoError(t, err, "must not have error")
+	require.True(t, reloaded, "must reload changed file")
+
+	holidays := s.Holidays([]model.HolidayCalendar{"ru"}, date(2030, 1, 1), date(2030, 1, 2))
+	require.Len(t, holidays, 1)
+	require.Equal(t, "New", holidays[0].Title, "must read new holidays")
+
+	writeFile(t, file, []byte("BEGIN:VCALENDAR\r\n"), modTime.Add(2*time.Minute))
+
+	_, err = s.Reload()
+	require.Error(t, err, "must fail on broken file")
+	require.Equal(t, "New", s.Holidays([]model.HolidayCalendar{"ru"}, date(2030, 1, 1), date(2030, 1, 2))[0].Title,
+		"must keep previous holidays on error")
+
+	require.NoError(t, os.Remove(file))
+	writeFile(t, filepath.Join(dir, "de.ics"), calendarData(""), modTime)
+
+	reloaded, err = s.Reload()
+	require.NoError(t, err, "must not have error")
+	require.True(t, reloaded, "must reload removed and added files")
+	require.Equal(t, []model.HolidayCalendarInfo{{Name: "de"}}, s.Calendars())
+}
+
+func TestNewStore_Errors(t *testing.T) {
+	_, err := NewStore(discardLogger(), filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err, "must fail on missing dir")
+
+	tests := map[string]struct {
+		vevent string
+		err    error
+	}{
+		"no start":     {"SUMMARY:x", ErrNoStart},
+		"end <= start": {"DTSTART;VALUE=DATE:20300102\nDTEND;VALUE=DATE:20290101", ErrInvalidDuration},
+		"monthly":      {"DTSTART;VALUE=DATE:20300102\nRRULE:FREQ=MONTHLY", ErrUnsupportedRule},
+		"by month":     {"DTSTART;VALUE=DATE:20300102\nRRULE:FREQ=YEARLY;BYMONTH=1", ErrUnsupportedRule},
+		"no freq":      {"DTSTART;VALUE=DATE:20300102\nRRULE:COUNT=2", ErrUnsupportedRule},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "ru.ics"), calendarData("", tt.vevent), time.Now())
+
+			_, err := NewStore(discardLogger(), dir)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,290 @@
+// tlsconfig - настройки TLS для серверов и клиентов с перечитыванием сертификатов при изменении файлов.
+//
+// Сертификаты и CA хранятся в Store. Store периодически проверяет время изменения файлов (Watch)
+// и перечитывает их без перезапуска сервиса. tls.Config, созданные через NewServer и NewClient,
+// при каждом TLS-рукопожатии используют актуальные сертификаты из Store.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/clock"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/filewatch"
+)
+
+// DefaultWatchInterval - как часто проверять изменение файлов сертификатов по умолчанию.
+const DefaultWatchInterval = 10 * time.Second
+
+var (
+	ErrNoCertificates = errors.New("no certificates found")
+	ErrNoCertificate  = errors.New("certificate is not configured")
+)
+
+// Files - файлы сертификатов в формате PEM.
+type Files struct {
+	// CertFile и KeyFile - сертификат и ключ.
+	CertFile string
+	KeyFile  string
+
+	// CAFile - сертификаты CA.
+	CAFile string
+}
+
+// Store хранит сертификат и пул CA, прочитанные из файлов Files.
+type Store struct {
+	files  Files
+	logger *slog.Logger
+
+	mx       sync.RWMutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	modTimes filewatch.ModTimes
+}
+
+// NewStore создаёт Store и читает файлы files.
+// Возвращает ошибку, если файлы не удалось прочитать.
+func NewStore(logger *slog.Logger, files Files) (*Store, error) {
+	s := &Store{
+		files:  files,
+		logger: logger,
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Certificate возвращает текущий сертификат, если задан.
+func (s *Store) Certificate() (*tls.Certificate, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	if s.cert == nil {
+		return nil, ErrNoCertificate
+	}
+
+	return s.cert, nil
+}
+
+// CAPool возвращает текущий пул CA или nil, если CA не задан.
+func (s *Store) CAPool() *x509.CertPool {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	return s.pool
+}
+
+// Reload перечитывает файлы, если они изменились.
+// При ошибке остаются прежние сертификаты.
+func (s *Store) Reload() (bool, error) {
+	modTimes, err := s.readModTimes()
+	if err != nil {
+		return false, err
+	}
+
+	s.mx.RLock()
+	changed := !s.modTimes.Equal(modTimes)
+	s.mx.RUnlock()
+
+	if !changed {
+		return false, nil
+	}
+
+	if err := s.load(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Watch проверяет изменение файлов раз в interval по часам clk и перечитывает их.
+// Завершается при отмене контекста ctx.
+func (s *Store) Watch(ctx context.Context, clk clock.Clock, interval time.Duration) {
+	filewatch.Watch(ctx, clk, interval, s.Reload, func(reloaded bool, err error) {
+		if err != nil {
+			s.logger.Error("can't reload certificates", slog.String("error", err.Error()))
+			return
+		}
+
+		if reloaded {
+			s.logger.Info(
+				"certificates reloaded",
+				slog.String("cert", s.files.CertFile),
+				slog.String("ca", s.files.CAFile),
+			)
+		}
+	})
+}
+
+// load читает сертификат, ключ и CA.
+func (s *Store) load() error {
+	modTimes, err := s.readModTimes()
+	if err != nil {
+		return err
+	}
+
+	var cert *tls.Certificate
+	if s.files.CertFile != "" || s.files.KeyFile != "" {
+		c, err := tls.LoadX509KeyPair(s.files.CertFile, s.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("can't load certificate: %w", err)
+		}
+
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if s.files.CAFile != "" {
+		pool, err = loadCAPool(s.files.CAFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.cert = cert
+	s.pool = pool
+	s.modTimes = modTimes
+
+	return nil
+}
+
+// readModTimes возвращает время изменения заданных файлов.
+func (s *Store) readModTimes() (filewatch.ModTimes, error) {
+	return filewatch.Stat(s.files.CertFile, s.files.KeyFile, s.files.CAFile)
+}
+
+// loadCAPool читает сертификаты CA из файла file.
+func loadCAPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't read CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("can't load CA %s: %w", file, ErrNoCertificates)
+	}
+
+	return pool, nil
+}
+
+// NewServer создаёт tls.Config сервера по настройкам cfg.
+// Если задан cfg.ClientCAFile, клиентские сертификаты проверяются (mTLS).
+//
+// Возвращает Store для отслеживания изменения сертификатов (см. Store.Watch).
+func NewServer(logger *slog.Logger, cfg config.TLSServer) (*tls.Config, *Store, error) {
+	store, err := NewStore(logger, Files{
+		CertFile: cfg.CertFile,
+		KeyFile:  cfg.KeyFile,
+		CAFile:   cfg.ClientCAFile,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if store.cert == nil {
+		return nil, nil, fmt.Errorf("server %w", ErrNoCertificate)
+	}
+
+	clientAuth := tls.NoClientCert
+	if cfg.ClientCAFile != "" {
+		clientAuth = tls.VerifyClientCertIfGiven
+		if cfg.RequireClientCert {
+			clientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+
+		// Для каждого рукопожатия используются актуальные сертификат и CA
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, err := store.Certificate()
+			if err != nil {
+				return nil, err
+			}
+
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    store.CAPool(),
+				NextProtos:   []string{"h2", "http/1.1"},
+			}, nil
+		},
+	}
+
+	return tlsConfig, store, nil
+}
+
+// NewClient создаёт tls.Config клиента по настройкам cfg.
+// Если задан cfg.CAFile, сертификат сервера проверяется по нему, иначе - по системным CA.
+// Если заданы cfg.CertFile и cfg.KeyFile, серверу передаётся клиентский сертификат (mTLS).
+//
+// Возвращает Store для отслеживания изменения сертификатов (см. Store.Watch).
+func NewClient(logger *slog.Logger, cfg config.TLSClient) (*tls.Config, *Store, error) {
+	store, err := NewStore(logger, Files{
+		CertFile: cfg.CertFile,
+		KeyFile:  cfg.KeyFile,
+		CAFile:   cfg.CAFile,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+
+	if cfg.CertFile != "" {
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return store.Certificate()
+		}
+	}
+
+	if cfg.CAFile != "" {
+		// Стандартная проверка использует неизменяемый RootCAs, поэтому сертификат сервера
+		// проверяется в VerifyConnection по актуальному пулу CA.
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyServer(cs, store.CAPool())
+		}
+	}
+
+	return tlsConfig, store, nil
+}
+
+// verifyServer проверяет цепочку сертификатов сервера и его имя по пулу CA pool.
+func verifyServer(cs tls.ConnectionState, pool *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return ErrNoCertificates
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         pool,
+		Intermediates: intermediates,
+	})
+
+	return err
+}
@@ -0,0 +1,280 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+)
+
+const ownerID = "d5a6b68e-1c6d-4b6e-9a43-97a5b9e06b0f"
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "must generate key")
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err, "must create CA")
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, "must parse CA")
+
+	return testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue выпускает сертификат commonName и возвращает сертификат и ключ в PEM.
+func (ca testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "must generate key")
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err, "must create certificate")
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err, "must marshal key")
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile записывает data в файл name и сдвигает время изменения на modTime.
+func writeFile(t *testing.T, name string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(name, data, 0o600), "must write file")
+	require.NoError(t, os.Chtimes(name, modTime, modTime), "must set mod time")
+}
+
+type testFiles struct {
+	ca, serverCert, serverKey, clientCert, clientKey string
+}
+
+// writeCerts выпускает сертификаты сервера и клиента от ca и записывает их в dir.
+func writeCerts(t *testing.T, dir string, ca testCA, modTime time.Time) testFiles {
+	t.Helper()
+
+	files := testFiles{
+		ca:         filepath.Join(dir, "ca.crt"),
+		serverCert: filepath.Join(dir, "server.crt"),
+		serverKey:  filepath.Join(dir, "server.key"),
+		clientCert: filepath.Join(dir, "client.crt"),
+		clientKey:  filepath.Join(dir, "client.key"),
+	}
+
+	serverCert, serverKey := ca.issue(t, "calendar", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, ownerID, x509.ExtKeyUsageClientAuth)
+
+	writeFile(t, files.ca, ca.pem, modTime)
+	writeFile(t, files.serverCert, serverCert, modTime)
+	writeFile(t, files.serverKey, serverKey, modTime)
+	writeFile(t, files.clientCert, clientCert, modTime)
+	writeFile(t, files.clientKey, clientKey, modTime)
+
+	return files
+}
+
+// handshake выполняет TLS-рукопожатие и возвращает состояние соединения на стороне сервера.
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) (tls.ConnectionState, error) {
+	t.Helper()
+
+	lstn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "must listen")
+	defer lstn.Close()
+
+	clientErr := make(chan error, 1)
+	go func() {
+		conn, err := net.Dial("tcp", lstn.Addr().String())
+		if err != nil {
+			clientErr <- err
+			return
+		}
+		defer conn.Close()
+
+		client := tls.Client(conn, clientConfig)
+		clientErr <- client.Handshake()
+	}()
+
+	conn, err := lstn.Accept()
+	require.NoError(t, err, "must accept")
+	defer conn.Close()
+
+	server := tls.Server(conn, serverConfig)
+	serverErr := server.Handshake()
+
+	if err := <-clientErr; err != nil {
+		return tls.ConnectionState{}, err
+	}
+
+	return server.ConnectionState(), serverErr
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestMutualTLS(t *testing.T) {
+	files := writeCerts(t, t.TempDir(), newTestCA(t, "ca"), time.Now())
+
+	serverConfig, _, err := NewServer(discardLogger(), config.TLSServer{
+		Enabled:           true,
+		CertFile:          files.serverCert,
+		KeyFile:           files.serverKey,
+		ClientCAFile:      files.ca,
+		RequireClientCert: true,
+	})
+	require.NoError(t, err, "must create server config")
+
+	clientConfig, _, err := NewClient(discardLogger(), config.TLSClient{
+		CAFile:     files.ca,
+		CertFile:   files.clientCert,
+		KeyFile:    files.clientKey,
+		ServerName: "localhost",
+	})
+	require.NoError(t, err, "must create client config")
+
+	state, err := handshake(t, serverConfig, clientConfig)
+	require.NoError(t, err, "must handshake")
+	require.NotEmpty(t, state.VerifiedChains, "client certificate must be verified")
+	require.Equal(t, ownerID, state.VerifiedChains[0][0].Subject.CommonName, "must identify client")
+
+	t.Run("no client cert", func(t *testing.T) {
+		clientConfig, _, err := NewClient(discardLogger(), config.TLSClient{CAFile: files.ca, ServerName: "localhost"})
+		require.NoError(t, err, "must create client config")
+
+		_, err = handshake(t, serverConfig, clientConfig)
+		require.Error(t, err, "must require client certificate")
+	})
+
+	t.Run("wrong server name", func(t *testing.T) {
+		clientConfig, _, err := NewClient(discardLogger(), config.TLSClient{CAFile: files.ca, ServerName: "example.com"})
+		require.NoError(t, err, "must create client config")
+
+		_, err = handshake(t, serverConfig, clientConfig)
+		require.Error(t, err, "must verify server name")
+	})
+}
+
+func TestStore_Reload(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Minute)
+	files := writeCerts(t, dir, newTestCA(t, "ca"), modTime)
+
+	serverConfig, serverStore, err := NewServer(discardLogger(), config.TLSServer{
+		Enabled:      true,
+		CertFile:     files.serverCert,
+		KeyFile:      files.serverKey,
+		ClientCAFile: files.ca,
+	})
+	require.NoError(t, err, "must create server config")
+
+	clientConfig, clientStore, err := NewClient(discardLogger(), config.TLSClient{
+		CAFile:     files.ca,
+		CertFile:   files.clientCert,
+		KeyFile:    files.clientKey,
+		ServerName: "localhost",
+	})
+	require.NoError(t, err, "must create client config")
+
+	reloaded, err := serverStore.Reload()
+	require.NoError(t, err, "must check files")
+	require.False(t, reloaded, "must not reload unchanged files")
+
+	// Новый CA и новые сертификаты
+	writeCerts(t, dir, newTestCA(t, "new ca"), modTime.Add(time.Second))
+
+	reloaded, err = serverStore.Reload()
+	require.NoError(t, err, "must reload server")
+	require.True(t, reloaded, "must reload changed files")
+
+	_, err = handshake(t, serverConfig, clientConfig)
+	require.Error(t, err, "client must not trust new server certificate before reload")
+
+	reloaded, err = clientStore.Reload()
+	require.NoError(t, err, "must reload client")
+	require.True(t, reloaded, "must reload changed files")
+
+	state, err := handshake(t, serverConfig, clientConfig)
+	require.NoError(t, err, "must handshake with reloaded certificates")
+	require.NotEmpty(t, state.VerifiedChains, "client certificate must be verified")
+
+	t.Run("invalid files", func(t *testing.T) {
+		cert, err := serverStore.Certificate()
+		require.NoError(t, err, "must have certificate")
+
+		writeFile(t, files.serverCert, []byte("invalid"), modTime.Add(2*time.Second))
+
+		_, err = serverStore.Reload()
+		require.Error(t, err, "must fail on invalid certificate")
+
+		current, err := serverStore.Certificate()
+		require.NoError(t, err, "must have certificate")
+		require.Same(t, cert, current, "must keep previous certificate")
+	})
+}
+
+func TestNewServer_Errors(t *testing.T) {
+	files := writeCerts(t, t.TempDir(), newTestCA(t, "ca"), time.Now())
+
+	_, _, err := NewServer(discardLogger(), config.TLSServer{Enabled: true})
+	require.ErrorIs(t, err, ErrNoCertificate, "must require server certificate")
+
+	_, _, err = NewServer(discardLogger(), config.TLSServer{
+		Enabled:      true,
+		CertFile:     files.serverCert,
+		KeyFile:      files.serverKey,
+		ClientCAFile: files.serverKey,
+	})
+	require.ErrorIs(t, err, ErrNoCertificates, "must fail on invalid CA")
+
+	_, _, err = NewClient(discardLogger(), config.TLSClient{CAFile: filepath.Join(t.TempDir(), "missing.crt")})
+	require.Error(t, err, "must fail on missing CA")
+}
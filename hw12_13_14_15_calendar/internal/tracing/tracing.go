@@ -0,0 +1,100 @@
+// tracing - трассировка запросов с помощью OpenTelemetry.
+//
+// Контекст трассировки передаётся между сервисами в формате W3C trace-context
+// (http-заголовки, grpc-метаданные, заголовки AMQP-сообщений).
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+)
+
+const instrumentationName = "github.com/dima-study/otus2405/hw12_13_14_15_calendar"
+
+// Init настраивает глобальные TracerProvider и пропагатор W3C trace-context для сервиса serviceName.
+// Возвращает функцию завершения, выгружающую оставшиеся span.
+func Init(cfg config.Tracing, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(Propagator())
+
+	if cfg.Exporter == config.TracingExporterNone || cfg.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var (
+		w       io.Writer
+		closeFn = func() error { return nil }
+	)
+
+	switch cfg.Exporter {
+	case config.TracingExporterStdout:
+		w = os.Stdout
+	case config.TracingExporterFile:
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("can't open traces file: %w", err)
+		}
+
+		w, closeFn = f, f.Close
+	default:
+		return nil, fmt.Errorf("tracing exporter '%s' is not supported", cfg.Exporter)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, errors.Join(err, closeFn())
+	}
+
+	provider := NewProvider(exporter, serviceName, cfg.SampleRatio)
+	otel.SetTracerProvider(provider)
+
+	shutdown := func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeFn())
+	}
+
+	return shutdown, nil
+}
+
+// NewProvider создаёт TracerProvider сервиса serviceName, выгружающий span в exporter.
+// Трейсы без родительского span записываются с долей sampleRatio.
+func NewProvider(exporter sdktrace.SpanExporter, serviceName string, sampleRatio float64) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+}
+
+// Propagator возвращает пропагатор контекста трассировки: W3C trace-context и baggage.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Tracer возвращает tracer сервисов календаря из глобального TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// End завершает span, отмечая в нём ошибку *err, если она есть.
+// Удобно использовать с именованной возвращаемой ошибкой: defer tracing.End(span, &err).
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/dima-study/otus2405/hw12_13_14_15_calendar/internal/config"
+)
+
+func TestInit(t *testing.T) {
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	t.Run("none", func(t *testing.T) {
+		shutdown, err := Init(config.Tracing{Exporter: config.TracingExporterNone}, "test")
+		require.NoError(t, err, "must init")
+		require.NoError(t, shutdown(context.Background()), "must shutdown")
+	})
+
+	t.Run("file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "traces.json")
+
+		shutdown, err := Init(
+			config.Tracing{Exporter: config.TracingExporterFile, File: file, SampleRatio: 1},
+			"test",
+		)
+		require.NoError(t, err, "must init")
+
+		_, span := Tracer().Start(context.Background(), "test span")
+		span.End()
+
+		require.NoError(t, shutdown(context.Background()), "must shutdown")
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err, "must read traces file")
+		require.Contains(t, string(data), `"Name":"test span"`, "must export span")
+		require.Contains(t, string(data), `"Value":"test"`, "must have service name")
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Init(config.Tracing{Exporter: "unknown"}, "test")
+		require.Error(t, err, "must have error")
+	})
+}
+
+func TestEnd(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(exporter, "test", 1)
+	tracer := provider.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "ok")
+	var err error
+	End(span, &err)
+
+	_, span = tracer.Start(context.Background(), "failed")
+	err = errors.New("failed")
+	End(span, &err)
+
+	require.NoError(t, provider.ForceFlush(context.Background()), "must flush")
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2, "must have spans")
+	require.Equal(t, codes.Unset, spans[0].Status.Code, "must not have error")
+	require.Equal(t, codes.Error, spans[1].Status.Code, "must have error")
+	require.Equal(t, "failed", spans[1].Status.Description, "must have error description")
+}
+
+func TestPropagator(t *testing.T) {
+	provider := NewProvider(tracetest.NewInMemoryExporter(), "test", 1)
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "parent")
+	defer span.End()
+
+	header := http.Header{}
+	Propagator().Inject(ctx, propagation.HeaderCarrier(header))
+	require.NotEmpty(t, header.Get("traceparent"), "must have traceparent header")
+
+	remote := trace.SpanContextFromContext(Propagator().Extract(context.Background(), propagation.HeaderCarrier(header)))
+	require.True(t, remote.IsRemote(), "must be remote span context")
+	require.Equal(t, span.SpanContext().TraceID(), remote.TraceID(), "must have same trace id")
+}
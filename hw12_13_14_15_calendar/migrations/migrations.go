@@ -0,0 +1,28 @@
+// Package migrations содержит миграции баз данных для хранилищ событий в формате goose.
+// Миграции встраиваются в бинарный файл и применяются пакетом internal/migrate.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed pg/*.sql sqlite/*.sql
+var files embed.FS
+
+var (
+	// Pg - миграции postgres.
+	Pg = sub("pg")
+
+	// Sqlite - миграции SQLite.
+	Sqlite = sub("sqlite")
+)
+
+func sub(dir string) fs.FS {
+	fsys, err := fs.Sub(files, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return fsys
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- События разделены по рабочим пространствам (тенантам): уникальность идентификатора события
+-- и непересечение времени событий владельца проверяются в рамках пространства.
+-- Существующие события переносятся в пространство по умолчанию.
+ALTER TABLE "events" ADD COLUMN "tenant_id" varchar(63) NOT NULL DEFAULT 'default';
+ALTER TABLE "events" ALTER COLUMN "tenant_id" DROP DEFAULT;
+
+ALTER TABLE "events" DROP CONSTRAINT "uniq_owner_event_id";
+ALTER TABLE "events" DROP CONSTRAINT "no_time_overlap";
+
+ALTER TABLE "events" ADD CONSTRAINT "uniq_tenant_owner_event_id" UNIQUE ("tenant_id", "owner_id", "event_id");
+ALTER TABLE "events" ADD CONSTRAINT "no_tenant_time_overlap" EXCLUDE USING GIST ("tenant_id" WITH =, "owner_id" WITH =, "time" WITH &&);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Откат возможен, только если события владельцев в разных пространствах не конфликтуют друг с другом.
+ALTER TABLE "events" DROP CONSTRAINT "no_tenant_time_overlap";
+ALTER TABLE "events" DROP CONSTRAINT "uniq_tenant_owner_event_id";
+
+ALTER TABLE "events" ADD CONSTRAINT "uniq_owner_event_id" UNIQUE ("owner_id", "event_id");
+ALTER TABLE "events" ADD CONSTRAINT "no_time_overlap" EXCLUDE USING GIST ("owner_id" WITH =, "time" WITH &&);
+
+ALTER TABLE "events" DROP COLUMN "tenant_id";
+-- +goose StatementEnd
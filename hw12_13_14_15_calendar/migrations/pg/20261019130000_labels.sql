@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Каталог меток владельца в рамках рабочего пространства и метки событий.
+-- Метки событий удаляются каскадно вместе с событием или меткой каталога.
+CREATE TABLE "labels" (
+  "id"        bigserial       NOT NULL PRIMARY KEY,
+  "tenant_id" varchar(63)     NOT NULL,
+  "owner_id"  uuid            NOT NULL,
+  "name"      varchar(32)     NOT NULL,
+  "color"     char(7)             NULL,
+
+  CONSTRAINT "uniq_tenant_owner_label" UNIQUE ("tenant_id", "owner_id", "name")
+);
+
+CREATE TABLE "event_labels" (
+  "event_id" bigint NOT NULL REFERENCES "events" ("id") ON DELETE CASCADE,
+  "label_id" bigint NOT NULL REFERENCES "labels" ("id") ON DELETE CASCADE,
+
+  PRIMARY KEY ("event_id", "label_id")
+);
+
+CREATE INDEX "event_labels_label_id" ON "event_labels" ("label_id");
+
+ALTER TABLE "events" ADD COLUMN "color" char(7) NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "events" DROP COLUMN "color";
+
+DROP TABLE "event_labels";
+DROP TABLE "labels";
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Вложения события (ссылки и метаданные файлов) в JSON, содержимое файлов хранится отдельно.
+ALTER TABLE "events" ADD COLUMN "attachments" jsonb NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "events" DROP COLUMN "attachments";
+-- +goose StatementEnd
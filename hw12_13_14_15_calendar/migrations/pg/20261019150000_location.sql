@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Место проведения события и ссылка на видеоконференцию.
+-- Координаты заданы либо обе, либо ни одной; поиск по области - по описанному прямоугольнику (без PostGIS).
+ALTER TABLE "events"
+  ADD COLUMN "location_name"    varchar(256)     NULL,
+  ADD COLUMN "location_address" varchar(512)     NULL,
+  ADD COLUMN "latitude"         double precision NULL,
+  ADD COLUMN "longitude"        double precision NULL,
+  ADD COLUMN "conference_url"   varchar(2048)    NULL,
+
+  ADD CONSTRAINT "location_point" CHECK (("latitude" IS NULL) = ("longitude" IS NULL));
+
+CREATE INDEX "events_location_idx" ON "events" USING gist (point("longitude", "latitude"))
+  WHERE "latitude" IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX "events_location_idx";
+
+ALTER TABLE "events"
+  DROP CONSTRAINT "location_point",
+
+  DROP COLUMN "location_name",
+  DROP COLUMN "location_address",
+  DROP COLUMN "latitude",
+  DROP COLUMN "longitude",
+  DROP COLUMN "conference_url";
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Профиль доступности владельца в рамках рабочего пространства:
+-- часовой пояс, рабочие часы и выходные дни в JSON.
+CREATE TABLE "profiles" (
+  "tenant_id" varchar(63)     NOT NULL,
+  "owner_id"  uuid            NOT NULL,
+  "profile"   jsonb           NOT NULL,
+
+  PRIMARY KEY ("tenant_id", "owner_id")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "profiles";
+-- +goose StatementEnd
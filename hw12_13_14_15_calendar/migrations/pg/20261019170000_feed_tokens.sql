@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ссылки на календарь владельца для подписки (webcal).
+-- Секрет ссылки не хранится, хранится только его хеш sha256 (hex), по которому ищется ссылка.
+CREATE TABLE "feed_tokens" (
+  "id"         bigserial       NOT NULL PRIMARY KEY,
+  "token_id"   uuid            NOT NULL,
+  "tenant_id"  varchar(63)     NOT NULL,
+  "owner_id"   uuid            NOT NULL,
+  "hash"       char(64)        NOT NULL,
+  "name"       varchar(64)         NULL,
+  "created_at" timestamptz     NOT NULL,
+
+  CONSTRAINT "uniq_feed_token_id"   UNIQUE ("token_id"),
+  CONSTRAINT "uniq_feed_token_hash" UNIQUE ("hash")
+);
+
+CREATE INDEX "feed_tokens_tenant_owner" ON "feed_tokens" ("tenant_id", "owner_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "feed_tokens";
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Индексы времени уведомления и окончания события - составные с рабочим пространством.
+-- Время остаётся первым столбцом: планировщик и очистка старых событий ищут по всем пространствам.
+DROP INDEX "need_notify";
+CREATE INDEX "need_notify" ON "events" ((lower(time) - notify_before * '1 day'::interval), "tenant_id")
+  WHERE notify_before>0;
+
+DROP INDEX "end_at";
+CREATE INDEX "end_at" ON "events" (upper(time), "tenant_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX "end_at";
+CREATE INDEX "end_at" ON "events" (upper(time));
+
+DROP INDEX "need_notify";
+CREATE INDEX "need_notify" ON "events" ((lower(time) - notify_before * '1 day'::interval)) WHERE notify_before>0;
+-- +goose StatementEnd
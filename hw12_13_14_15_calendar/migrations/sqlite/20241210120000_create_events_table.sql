@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- "start_at" и "end_at" - время в микросекундах Unix (UTC), промежуток [start_at, end_at).
+-- Пересечение промежутков событий одного владельца проверяется в транзакции при добавлении/изменении события.
+CREATE TABLE "events" (
+  "id"            integer         NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "event_id"      text            NOT NULL,
+  "owner_id"      text            NOT NULL,
+  "start_at"      integer         NOT NULL,
+  "end_at"        integer         NOT NULL,
+  "title"         text            NOT NULL,
+  "description"   text                NULL,
+  "notify_before" integer         NOT NULL DEFAULT 0,
+
+  CONSTRAINT "uniq_owner_event_id" UNIQUE ("owner_id", "event_id"),
+  CONSTRAINT "positive_notify_before" CHECK ("notify_before" >= 0),
+  CONSTRAINT "valid_time" CHECK ("start_at" < "end_at"),
+  CONSTRAINT "title_length" CHECK (length("title") <= 128)
+);
+
+CREATE INDEX "owner_time" ON "events" ("owner_id", "start_at", "end_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX "owner_time";
+
+DROP TABLE "events";
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX "need_notify" ON "events" (("start_at" - "notify_before" * 86400000000)) WHERE "notify_before">0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX "need_notify";
+-- +goose StatementEnd
@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+-- События разделены по рабочим пространствам (тенантам): уникальность идентификатора события
+-- и непересечение времени событий владельца проверяются в рамках пространства.
+-- SQLite не изменяет ограничения таблицы, поэтому таблица пересоздаётся;
+-- существующие события переносятся в пространство по умолчанию.
+CREATE TABLE "events_tenant" (
+  "id"            integer         NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "tenant_id"     text            NOT NULL,
+  "event_id"      text            NOT NULL,
+  "owner_id"      text            NOT NULL,
+  "start_at"      integer         NOT NULL,
+  "end_at"        integer         NOT NULL,
+  "title"         text            NOT NULL,
+  "description"   text                NULL,
+  "notify_before" integer         NOT NULL DEFAULT 0,
+
+  CONSTRAINT "uniq_tenant_owner_event_id" UNIQUE ("tenant_id", "owner_id", "event_id"),
+  CONSTRAINT "positive_notify_before" CHECK ("notify_before" >= 0),
+  CONSTRAINT "valid_time" CHECK ("start_at" < "end_at"),
+  CONSTRAINT "title_length" CHECK (length("title") <= 128),
+  CONSTRAINT "tenant_id_length" CHECK (length("tenant_id") <= 63)
+);
+
+INSERT INTO "events_tenant" (
+    "id", "tenant_id", "event_id", "owner_id", "start_at", "end_at", "title", "description", "notify_before"
+)
+SELECT
+    "id", 'default', "event_id", "owner_id", "start_at", "end_at", "title", "description", "notify_before"
+FROM "events";
+
+DROP TABLE "events";
+
+ALTER TABLE "events_tenant" RENAME TO "events";
+
+CREATE INDEX "tenant_owner_time" ON "events" ("tenant_id", "owner_id", "start_at", "end_at");
+CREATE INDEX "need_notify" ON "events" (("start_at" - "notify_before" * 86400000000)) WHERE "notify_before">0;
+CREATE INDEX "end_at" ON "events" ("end_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Откат возможен, только если события владельцев в разных пространствах не конфликтуют друг с другом.
+CREATE TABLE "events_owner" (
+  "id"            integer         NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "event_id"      text            NOT NULL,
+  "owner_id"      text            NOT NULL,
+  "start_at"      integer         NOT NULL,
+  "end_at"        integer         NOT NULL,
+  "title"         text            NOT NULL,
+  "description"   text                NULL,
+  "notify_before" integer         NOT NULL DEFAULT 0,
+
+  CONSTRAINT "uniq_owner_event_id" UNIQUE ("owner_id", "event_id"),
+  CONSTRAINT "positive_notify_before" CHECK ("notify_before" >= 0),
+  CONSTRAINT "valid_time" CHECK ("start_at" < "end_at"),
+  CONSTRAINT "title_length" CHECK (length("title") <= 128)
+);
+
+INSERT INTO "events_owner" (
+    "id", "event_id", "owner_id", "start_at", "end_at", "title", "description", "notify_before"
+)
+SELECT
+    "id", "event_id", "owner_id", "start_at", "end_at", "title", "description", "notify_before"
+FROM "events";
+
+DROP TABLE "events";
+
+ALTER TABLE "events_owner" RENAME TO "events";
+
+CREATE INDEX "owner_time" ON "events" ("owner_id", "start_at", "end_at");
+CREATE INDEX "need_notify" ON "events" (("start_at" - "notify_before" * 86400000000)) WHERE "notify_before">0;
+CREATE INDEX "end_at" ON "events" ("end_at");
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Каталог меток владельца в рамках рабочего пространства и метки событий.
+-- Внешние ключи SQLite не включены, поэтому метки событий удаляются вместе с событием
+-- или меткой каталога в той же транзакции, что и само удаление.
+CREATE TABLE "labels" (
+  "id"        integer         NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "tenant_id" text            NOT NULL,
+  "owner_id"  text            NOT NULL,
+  "name"      text            NOT NULL,
+  "color"     text                NULL,
+
+  CONSTRAINT "uniq_tenant_owner_label" UNIQUE ("tenant_id", "owner_id", "name")
+);
+
+CREATE TABLE "event_labels" (
+  "event_id" integer NOT NULL,
+  "label_id" integer NOT NULL,
+
+  PRIMARY KEY ("event_id", "label_id")
+);
+
+CREATE INDEX "event_labels_label_id" ON "event_labels" ("label_id");
+
+ALTER TABLE "events" ADD COLUMN "color" text NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "events" DROP COLUMN "color";
+
+DROP TABLE "event_labels";
+DROP TABLE "labels";
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Место проведения события и ссылка на видеоконференцию.
+-- Координаты заданы либо обе, либо ни одной; поиск по области - по описанному прямоугольнику.
+ALTER TABLE "events" ADD COLUMN "location_name" text NULL;
+ALTER TABLE "events" ADD COLUMN "location_address" text NULL;
+ALTER TABLE "events" ADD COLUMN "latitude" real NULL;
+ALTER TABLE "events" ADD COLUMN "longitude" real NULL;
+ALTER TABLE "events" ADD COLUMN "conference_url" text NULL;
+
+CREATE INDEX "tenant_owner_location" ON "events" ("tenant_id", "owner_id", "latitude", "longitude")
+  WHERE "latitude" IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX "tenant_owner_location";
+
+ALTER TABLE "events" DROP COLUMN "conference_url";
+ALTER TABLE "events" DROP COLUMN "longitude";
+ALTER TABLE "events" DROP COLUMN "latitude";
+ALTER TABLE "events" DROP COLUMN "location_address";
+ALTER TABLE "events" DROP COLUMN "location_name";
+-- +goose StatementEnd
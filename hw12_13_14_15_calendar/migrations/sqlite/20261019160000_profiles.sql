@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Профиль доступности владельца в рамках рабочего пространства:
+-- часовой пояс, рабочие часы и выходные дни в JSON.
+CREATE TABLE "profiles" (
+  "tenant_id" text            NOT NULL,
+  "owner_id"  text            NOT NULL,
+  "profile"   text            NOT NULL,
+
+  PRIMARY KEY ("tenant_id", "owner_id")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "profiles";
+-- +goose StatementEnd
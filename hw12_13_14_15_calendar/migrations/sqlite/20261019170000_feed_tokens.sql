@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ссылки на календарь владельца для подписки (webcal).
+-- Секрет ссылки не хранится, хранится только его хеш sha256 (hex), по которому ищется ссылка.
+CREATE TABLE "feed_tokens" (
+  "id"         integer         NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "token_id"   text            NOT NULL,
+  "tenant_id"  text            NOT NULL,
+  "owner_id"   text            NOT NULL,
+  "hash"       text            NOT NULL,
+  "name"       text                NULL,
+  "created_at" integer         NOT NULL,
+
+  CONSTRAINT "uniq_feed_token_id"   UNIQUE ("token_id"),
+  CONSTRAINT "uniq_feed_token_hash" UNIQUE ("hash")
+);
+
+CREATE INDEX "feed_tokens_tenant_owner" ON "feed_tokens" ("tenant_id", "owner_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "feed_tokens";
+-- +goose StatementEnd